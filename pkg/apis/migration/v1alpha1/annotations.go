@@ -27,4 +27,18 @@ const (
 const (
 	// Disables the internal image copy
 	DisableImageCopy = "migration.openshift.io/disable-image-copy"
+	// Requests every DirectVolumeMigration owned by the migration to be canceled
+	CancelDirectVolumeMigrationsAnnotation = "migration.openshift.io/cancel-direct-volume-migrations"
+	// Requests the workloads which quiesce a PodDisruptionBudget prevents to be left running, their PVs are migrated live
+	QuiesceLiveSourceAnnotation = "migration.openshift.io/quiesce-live-source"
+)
+
+// DirectVolumeMigration Annotations
+const (
+	// Requests the rsync transfer endpoint to be torn down and recreated
+	RecreateRsyncEndpointAnnotation = "migration.openshift.io/recreate-rsync-endpoint"
+	// Requests the rsync transfer to be stopped, keeping the rsync transfer endpoint, until removed
+	StopRsyncTransferAnnotation = "migration.openshift.io/stop-rsync-transfer"
+	// Requests the controller to leave the DirectVolumeMigration and its transfer as they are, until removed
+	MaintenanceAnnotation = "migration.openshift.io/maintenance"
 )
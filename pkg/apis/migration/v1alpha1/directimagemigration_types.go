@@ -110,6 +110,11 @@ func (r *DirectImageMigration) GetMigrationForDIM(client k8sclient.Client) (*Mig
 	return nil, nil
 }
 
+// GetMigrationUID get the UID of the MigMigration owning the DIM
+func (r *DirectImageMigration) GetMigrationUID() string {
+	return GetMigrationUID(r.OwnerReferences)
+}
+
 // GetSourceNamespaces get source namespaces without mapping
 func (r *DirectImageMigration) GetSourceNamespaces() []string {
 	includedNamespaces := []string{}
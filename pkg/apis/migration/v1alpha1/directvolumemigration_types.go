@@ -20,6 +20,7 @@ import (
 	"fmt"
 
 	kapi "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -30,8 +31,82 @@ type PVCToMigrate struct {
 	TargetAccessModes     []kapi.PersistentVolumeAccessMode `json:"targetAccessModes"`
 	TargetNamespace       string                            `json:"targetNamespace,omitempty"`
 	Verify                bool                              `json:"verify,omitempty"`
+	// MaxSize skip files larger than this size, equivalent to rsync --max-size
+	MaxSize string `json:"maxSize,omitempty"`
+	// MinSize skip files smaller than this size, equivalent to rsync --min-size
+	MinSize string `json:"minSize,omitempty"`
+	// Shards number of concurrent Rsync processes the top-level directories of the PVC are split among, a single process is used when not set
+	Shards int `json:"shards,omitempty"`
+	// LargeFileStreams number of parallel Rsync streams the files of the PVC larger than 1GiB are split among by byte range, a single stream is used when not set
+	LargeFileStreams int `json:"largeFileStreams,omitempty"`
+	// Sparse handling of sparse files by Rsync, one of auto, always or never. auto is used when not set
+	Sparse string `json:"sparse,omitempty"`
 }
 
+// Modes of the handling of sparse files by Rsync
+const (
+	// SparseAuto let the controller decide whether Rsync handles sparse files
+	SparseAuto = "auto"
+	// SparseAlways always pass --sparse to Rsync
+	SparseAlways = "always"
+	// SparseNever always pass --no-sparse to Rsync
+	SparseNever = "never"
+)
+
+// Policies applied when a destination PVC already exists
+const (
+	// ExistingPVCAdopt reuse the destination PVC when it is compatible with the source PVC
+	ExistingPVCAdopt = "adopt"
+	// ExistingPVCFail fail the migration
+	ExistingPVCFail = "fail"
+	// ExistingPVCRecreate delete the destination PVC and create it again
+	ExistingPVCRecreate = "recreate"
+)
+
+// Modes of the transfer of whole files by Rsync
+const (
+	// WholeFileAuto keep the Rsync defaults, whole files for local copies and deltas otherwise
+	WholeFileAuto = "auto"
+	// WholeFileOn always pass --whole-file to Rsync
+	WholeFileOn = "on"
+	// WholeFileOff always pass --no-whole-file to Rsync
+	WholeFileOff = "off"
+)
+
+// Modes of the transfer of the symlinks pointing outside of the volume by Rsync
+const (
+	// UnsafeLinksDrop skip the unsafe symlinks, pass --safe-links to Rsync
+	UnsafeLinksDrop = "drop"
+	// UnsafeLinksCopy transfer the files the unsafe symlinks point to, pass --copy-unsafe-links to Rsync
+	UnsafeLinksCopy = "copy"
+	// UnsafeLinksKeep transfer the unsafe symlinks as symlinks
+	UnsafeLinksKeep = "keep"
+)
+
+// Handling of the source files vanished during the Rsync transfer, rsync exiting with code 24
+const (
+	// VanishedFilesIgnore complete the transfer of the PVC
+	VanishedFilesIgnore = "ignore"
+	// VanishedFilesWarn complete the transfer of the PVC with a warning
+	VanishedFilesWarn = "warn"
+	// VanishedFilesFail fail the transfer of the PVC without retrying it
+	VanishedFilesFail = "fail"
+)
+
+// Strategies scheduling the transfers of the PVCs when their concurrency is limited
+const (
+	// TransferSchedulingFIFO start the transfers in the order of the spec
+	TransferSchedulingFIFO = "fifo"
+	// TransferSchedulingSizeBalanced mix the transfers of large and small PVCs
+	TransferSchedulingSizeBalanced = "size-balanced"
+)
+
+// Engines transferring the data of the PVCs
+const (
+	// TransferEngineRsync transfer the PVCs with Rsync through Stunnel
+	TransferEngineRsync = "rsync"
+)
+
 // DirectVolumeMigrationSpec defines the desired state of DirectVolumeMigration
 type DirectVolumeMigrationSpec struct {
 	SrcMigClusterRef  *kapi.ObjectReference `json:"srcMigClusterRef,omitempty"`
@@ -48,6 +123,191 @@ type DirectVolumeMigrationSpec struct {
 
 	// Specifies if progress reporting CRs needs to be deleted or not
 	DeleteProgressReportingCRs bool `json:"deleteProgressReportingCRs,omitempty"`
+
+	// Deadline maximum duration of the migration counted from its start, the migration is failed once exceeded
+	Deadline *metav1.Duration `json:"deadline,omitempty"`
+
+	// Holds references to MigHooks run before (PreTransfer) and after (PostTransfer) the Rsync transfer
+	Hooks []MigPlanHook `json:"hooks,omitempty"`
+
+	// RequireHookConsistency the transfer only starts once the PreTransfer hook confirmed the source data is consistent, e.g. once it snapshotted or dumped a database, by terminating its container with the 'consistent' termination message. A PreTransfer hook is required, the migration fails when it completes without confirming
+	RequireHookConsistency bool `json:"requireHookConsistency,omitempty"`
+
+	// RsyncUID UID owning the files written on the destination PVCs, files keep the source owner when not set
+	RsyncUID *int64 `json:"rsyncUID,omitempty"`
+
+	// RsyncGID GID owning the files written on the destination PVCs, defaults to DestinationFSGroup or RsyncUID when not set
+	RsyncGID *int64 `json:"rsyncGID,omitempty"`
+
+	// DestinationFSGroup fsGroup of the destination workload, the files written on the destination PVCs are owned by this group and made group readable and writable
+	DestinationFSGroup *int64 `json:"destinationFSGroup,omitempty"`
+
+	// RsyncPodActiveDeadlineSeconds duration in seconds an Rsync Pod may run before it is terminated and retried, defaults to the RSYNC_POD_ACTIVE_DEADLINE_SECONDS of the destination cluster or the time left before the Deadline
+	RsyncPodActiveDeadlineSeconds *int64 `json:"rsyncPodActiveDeadlineSeconds,omitempty"`
+
+	// RsyncBwLimit bandwidth limit of the Rsync transfer in KiB/s, 0 for no limit, defaults to the RSYNC_BWLIMIT of the destination cluster or the controller setting
+	RsyncBwLimit *int `json:"rsyncBwLimit,omitempty"`
+
+	// RsyncBwLimitRampUp duration over which the bandwidth limit of each Rsync transfer is raised in steps from a quarter to the full limit, smoothing the burst of the transfers starting together. Each step restarts Rsync with a higher limit, resuming the partially transferred files. Not ramped up when not set or without bandwidth limit
+	RsyncBwLimitRampUp *metav1.Duration `json:"rsyncBwLimitRampUp,omitempty"`
+
+	// RsyncCompress whether Rsync compresses the transferred data, defaults to the RSYNC_COMPRESS of the destination cluster
+	RsyncCompress *bool `json:"rsyncCompress,omitempty"`
+
+	// RsyncCompressLevel compression level of the compressed Rsync transfer, from 0 to 9 of the zlib compression of Rsync, higher levels trade CPU for bandwidth. Rsync chooses the level when not set
+	RsyncCompressLevel *int `json:"rsyncCompressLevel,omitempty"`
+
+	// RsyncErrorGraceWindow duration from the first failed Rsync attempt of a PVC during which the failed attempts are retried without counting against the BackOffLimit, only the failures persisting beyond it count. Every failed attempt counts when not set
+	RsyncErrorGraceWindow *metav1.Duration `json:"rsyncErrorGraceWindow,omitempty"`
+
+	// RsyncTimeout I/O timeout of the Rsync transfer in seconds, 0 for no timeout, defaults to the RSYNC_TIMEOUT of the destination cluster
+	RsyncTimeout *int `json:"rsyncTimeout,omitempty"`
+
+	// RsyncOpenFilesLimit limit of open files, the nofile ulimit, of the Rsync Pods. Raised for the PVCs with many files reported by the MigAnalytic of the plan when not set
+	RsyncOpenFilesLimit *int64 `json:"rsyncOpenFilesLimit,omitempty"`
+
+	// RsyncModifyWindow tolerance in seconds of the comparison of the modification times of the files, for destination filesystems with coarse timestamps. Rsync compares them exactly when not set
+	RsyncModifyWindow *int `json:"rsyncModifyWindow,omitempty"`
+
+	// RsyncTempDir directory of the destination volumes Rsync writes its temporary files to, relative to the root of each destination volume and created when missing. Rsync writes them next to the transferred files when not set
+	RsyncTempDir string `json:"rsyncTempDir,omitempty"`
+
+	// PreservePartialFiles keeps the partially transferred files of the interrupted Rsync attempts in a .dvm-partial directory next to them on the destination PVCs, passing --partial-dir to Rsync, for the next attempt to resume them after the Rsync Pods or the controller restarted
+	PreservePartialFiles bool `json:"preservePartialFiles,omitempty"`
+
+	// WholeFile whether Rsync transfers whole files rather than deltas, one of auto, on or off. auto keeps the Rsync defaults and is used when not set
+	WholeFile string `json:"wholeFile,omitempty"`
+
+	// BlockDelta transfers only the changed blocks of the raw block volumes, Rsync comparing the checksums of the blocks of the source and destination devices and writing the blocks which differ in place. The whole devices are copied when not set or when the destination devices hold no previous copy of the data
+	BlockDelta bool `json:"blockDelta,omitempty"`
+
+	// UnsafeLinks handling of the symlinks pointing outside of the source volume, one of drop, copy or keep. drop skips them, copy transfers the files they point to in the Rsync client Pod, keep transfers them as symlinks and is used when not set
+	UnsafeLinks string `json:"unsafeLinks,omitempty"`
+
+	// PruneEmptyDirs skips the empty directories of the source volumes, passing --prune-empty-dirs to Rsync. The empty directories are created on the destination when not set
+	PruneEmptyDirs bool `json:"pruneEmptyDirs,omitempty"`
+
+	// SkipUnchangedPVCs compares each source PVC with its destination by an Rsync dry run before the transfer, the PVCs without any difference are completed without transferring them
+	SkipUnchangedPVCs bool `json:"skipUnchangedPVCs,omitempty"`
+
+	// VanishedFiles handling of the source files deleted while Rsync transfers them, one of ignore, warn or fail. Exiting with code 24 is handled as the RSYNC_EXIT_CODE_OUTCOMES setting, a warning by default, when not set
+	VanishedFiles string `json:"vanishedFiles,omitempty"`
+
+	// VerifyConfigReferences checks that the ConfigMaps and Secrets required by the source Pods and workloads mounting the PVCs exist in the destination namespaces once the PVCs are transferred, the missing ones are reported with a warning
+	VerifyConfigReferences bool `json:"verifyConfigReferences,omitempty"`
+
+	// BaselineThroughput expected transfer rate of the migration in MB/s, the ThroughputBelowBaseline warning is reported while the measured transfer rate falls below half of it
+	BaselineThroughput *int `json:"baselineThroughput,omitempty"`
+
+	// NodeLocalReads schedules the Rsync client Pods of the PVCs not mounted by a running Pod on the node hosting their volume, from the node affinity of their PV or the node it is attached to
+	NodeLocalReads bool `json:"nodeLocalReads,omitempty"`
+
+	// MaxConcurrentTransfers maximum number of PVCs transferred concurrently, all PVCs are transferred concurrently when not set
+	MaxConcurrentTransfers *int `json:"maxConcurrentTransfers,omitempty"`
+
+	// TransferScheduling order the PVCs start their transfer in when MaxConcurrentTransfers is set, one of fifo or size-balanced. fifo starts them in the order of the spec and is used when not set, size-balanced mixes large and small PVCs from their size reported by MigAnalytic
+	TransferScheduling string `json:"transferScheduling,omitempty"`
+
+	// TransferEngine engine transferring the data of the PVCs, one of the engines registered in the controller. rsync is used when not set
+	TransferEngine string `json:"transferEngine,omitempty"`
+
+	// TunnelEndpoints tunnels provided by the user, e.g. Submariner or a VPN, forwarding to the Rsync transfer Service of destination namespaces. No Route is created for these namespaces, the source cluster connects to the tunnel instead
+	TunnelEndpoints []TunnelEndpoint `json:"tunnelEndpoints,omitempty"`
+
+	// ProgressCallback endpoint notified of the phase transitions and progress of the migration
+	ProgressCallback *ProgressCallback `json:"progressCallback,omitempty"`
+
+	// PrewarmDestinationPVCs writes over the capacity of the destination PVCs before the transfer, forcing the allocation of storage with a first write penalty
+	PrewarmDestinationPVCs bool `json:"prewarmDestinationPVCs,omitempty"`
+
+	// ExpandDestinationPVCs expands the destination PVCs smaller than the data of their source PVC reported by MigAnalytic before the transfer, the storage class of the destination PVCs must allow volume expansion
+	ExpandDestinationPVCs bool `json:"expandDestinationPVCs,omitempty"`
+
+	// VerifyOnly compares the source PVCs with the existing destination PVCs by checksum without transferring or modifying any data, the differing files are reported in the Rsync operations
+	VerifyOnly bool `json:"verifyOnly,omitempty"`
+
+	// RsyncFilter filter rules selecting the files transferred by Rsync
+	RsyncFilter *RsyncFilter `json:"rsyncFilter,omitempty"`
+
+	// Preview resolves the transfer plan of the migration in the status without creating any resource or transferring any data
+	Preview bool `json:"preview,omitempty"`
+
+	// SpeedTest measures the throughput and latency of the Rsync transfer through the endpoint of each destination namespace with generated data, instead of transferring the PVCs
+	SpeedTest *SpeedTest `json:"speedTest,omitempty"`
+
+	// Itinerary name of the itinerary the migration runs, one of VolumeMigration, VerifyOnlyMigration, PreviewMigration or SpeedTestMigration. When not set, it is selected by verifyOnly, preview and speedTest. The itinerary of a transfer engine other than rsync follows from the selected itinerary
+	Itinerary string `json:"itinerary,omitempty"`
+
+	// TTLAfterCompleted duration the DVM is kept once completed before it is deleted, defaults to the DVM_COMPLETED_TTL setting, kept indefinitely when 0
+	TTLAfterCompleted *metav1.Duration `json:"ttlAfterCompleted,omitempty"`
+
+	// TTLAfterFailed duration the DVM is kept once failed or canceled before it is deleted, defaults to the DVM_FAILED_TTL setting or TTLAfterCompleted
+	TTLAfterFailed *metav1.Duration `json:"ttlAfterFailed,omitempty"`
+
+	// RequeueIntervals intervals the running DVM is reconciled at, default to the DVM_FAST_REQUEUE and DVM_POLL_REQUEUE settings
+	RequeueIntervals *RequeueIntervals `json:"requeueIntervals,omitempty"`
+
+	// PVCAnnotations annotations of the destination PVCs created by the migration, the destination PVCs are created without annotations when not set
+	PVCAnnotations *PVCAnnotations `json:"pvcAnnotations,omitempty"`
+
+	// ExistingPVCPolicy what happens when a destination PVC already exists before the migration creates it, one of adopt, fail or recreate. adopt reuses the destination PVC once its size, access modes and storage class are verified compatible, and is used when not set
+	ExistingPVCPolicy string `json:"existingPVCPolicy,omitempty"`
+
+	// Checkpoint records a checkpoint on each destination PVC once its data is transferred, for a later DVM to transfer the delta onto it with DeltaSinceCheckpoint
+	Checkpoint bool `json:"checkpoint,omitempty"`
+
+	// DeltaSinceCheckpoint transfers the delta onto the destination PVCs holding the checkpoint recorded by a previous DVM, Rsync only sends the files differing from the destination, the checkpoint doesn't select them. The migration fails when a destination PVC is missing or holds no checkpoint. A new checkpoint is recorded once the delta is transferred
+	DeltaSinceCheckpoint bool `json:"deltaSinceCheckpoint,omitempty"`
+}
+
+// PVCAnnotations annotations of the destination PVCs created by a DVM. The
+// annotations of the source PVCs are only copied when allowed by Propagate.
+type PVCAnnotations struct {
+	// Propagate keys of the source PVC annotations copied onto the destination PVCs, a key ending with '*' matches the keys starting with the preceding prefix
+	Propagate []string `json:"propagate,omitempty"`
+	// Set annotations set on the destination PVCs, taking precedence over the propagated annotations
+	Set map[string]string `json:"set,omitempty"`
+}
+
+// RequeueIntervals intervals a running DVM is reconciled at.
+type RequeueIntervals struct {
+	// Fast interval once a phase progressed without waiting, between 10ms and 10s
+	Fast *metav1.Duration `json:"fast,omitempty"`
+	// Poll interval while a phase waits for a resource or for the transfer, between 500ms and 5m
+	Poll *metav1.Duration `json:"poll,omitempty"`
+}
+
+// RsyncFilter filter rules of the Rsync transfer. Inline excludes are evaluated
+// before the rules of the filter file, the first matching rule wins.
+type RsyncFilter struct {
+	// ConfigMapRef ConfigMap on the host cluster holding an Rsync filter file in the merge-file format of rsync(1)
+	ConfigMapRef *kapi.ObjectReference `json:"configMapRef,omitempty"`
+	// Key key of the ConfigMap holding the filter file, defaults to 'filter'
+	Key string `json:"key,omitempty"`
+	// Excludes inline exclude patterns, one pattern per item
+	Excludes []string `json:"excludes,omitempty"`
+}
+
+// TunnelEndpoint address of a tunnel reachable from the source cluster forwarding
+// to port 2222 of the Rsync transfer Service of a destination namespace.
+type TunnelEndpoint struct {
+	// Namespace destination namespace reached through the tunnel
+	Namespace string `json:"namespace"`
+	// Host host name or IP address of the tunnel
+	Host string `json:"host"`
+	// Port port of the tunnel
+	Port int32 `json:"port"`
+}
+
+// ProgressCallback endpoint the controller POSTs the progress events of a DVM to.
+// Events are delivered on a best effort basis, failed deliveries never block the migration.
+type ProgressCallback struct {
+	// URL endpoint the progress events are POSTed to
+	URL string `json:"url"`
+	// SecretRef Secret holding the HMAC-SHA256 key signing the events in its 'hmacKey' key, events are not signed when not set
+	SecretRef *kapi.ObjectReference `json:"secretRef,omitempty"`
+	// Timeout of each delivery attempt, defaults to 10s
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
 }
 
 // DirectVolumeMigrationStatus defines the observed state of DirectVolumeMigration
@@ -64,6 +324,257 @@ type DirectVolumeMigrationStatus struct {
 	RunningPods      []*PodProgress    `json:"runningPods,omitempty"`
 	PendingPods      []*PodProgress    `json:"pendingPods,omitempty"`
 	RsyncOperations  []*RsyncOperation `json:"rsyncOperations,omitempty"`
+	// ItinerarySteps phases of the itinerary the migration runs, in the order they run
+	ItinerarySteps []string `json:"itinerarySteps,omitempty"`
+	// PrewarmElapsedTime time taken to prewarm the destination PVCs
+	PrewarmElapsedTime *metav1.Duration `json:"prewarmElapsedTime,omitempty"`
+	// EstimatedCompletionTimestamp estimated completion of the Rsync transfer, omitted when the transfer rate or the size of the PVCs are unknown
+	EstimatedCompletionTimestamp *metav1.Time `json:"estimatedCompletionTimestamp,omitempty"`
+	// SmoothedTransferRate transfer rate of the running Rsync Pods in bytes per second smoothed over time, used to estimate the completion
+	SmoothedTransferRate *resource.Quantity `json:"smoothedTransferRate,omitempty"`
+	// TransferPlan transfer plan resolved by a preview migration
+	TransferPlan *TransferPlan `json:"transferPlan,omitempty"`
+	// CompletionTimestamp time the migration completed, failed or was canceled
+	CompletionTimestamp *metav1.Time `json:"completionTimestamp,omitempty"`
+	// PersistentVolumeClaims state of the transfer of each PVC, updated as the migration progresses
+	PersistentVolumeClaims []PVCTransferState `json:"persistentVolumeClaims,omitempty"`
+	// SpeedTestResults throughput and latency measured by a speed test for each destination namespace
+	SpeedTestResults []SpeedTestResult `json:"speedTestResults,omitempty"`
+	// ClockSkew clock skew between the source and destination clusters measured when the migration started
+	ClockSkew *metav1.Duration `json:"clockSkew,omitempty"`
+	// TransferSummary summary of the transfer reported once the migration completed, failed or was canceled
+	TransferSummary *TransferSummary `json:"transferSummary,omitempty"`
+	// Requeue requeue decision of the last reconcile of the running migration, cleared once the migration completed
+	Requeue *RequeueStatus `json:"requeue,omitempty"`
+	// ProgressEventSequence sequence number of the last event sent to the progress callback
+	ProgressEventSequence int64 `json:"progressEventSequence,omitempty"`
+	// AutoEndpointType endpoint type selected for the auto endpoint type of the destination cluster, kept for the rest of the migration
+	AutoEndpointType *AutoEndpointType `json:"autoEndpointType,omitempty"`
+	// PeakResourceUsage peak CPU and memory usage of each container of the Rsync Pods sampled from the metrics API during the transfer
+	PeakResourceUsage []ContainerResourceUsage `json:"peakResourceUsage,omitempty"`
+	// Hooks outcome of the hooks run by the migration
+	Hooks []HookStatus `json:"hooks,omitempty"`
+	// LastCheckpointTime time of the latest checkpoint recorded on the destination PVCs by the migration
+	LastCheckpointTime *metav1.Time `json:"lastCheckpointTime,omitempty"`
+}
+
+// HookStatus outcome of a hook run by a DVM.
+type HookStatus struct {
+	// Phase hook phase, PreTransfer or PostTransfer
+	Phase string `json:"phase"`
+	// JobReference Job running the hook
+	JobReference *kapi.ObjectReference `json:"jobReference,omitempty"`
+	// State one of Running, Succeeded or Failed
+	State string `json:"state,omitempty"`
+	// Consistent whether the PreTransfer hook confirmed the source data is consistent
+	Consistent bool `json:"consistent,omitempty"`
+	// Message termination message of the hook, or why it failed
+	Message string `json:"message,omitempty"`
+	// StartTimestamp time the Job of the hook was created
+	StartTimestamp *metav1.Time `json:"startTimestamp,omitempty"`
+	// CompletionTimestamp time the hook succeeded or failed
+	CompletionTimestamp *metav1.Time `json:"completionTimestamp,omitempty"`
+}
+
+// Hook states
+const (
+	HookRunning   = "Running"
+	HookSucceeded = "Succeeded"
+	HookFailed    = "Failed"
+)
+
+// ContainerResourceUsage peak resource usage of a container across the Rsync Pods of a cluster.
+type ContainerResourceUsage struct {
+	// Cluster cluster the Rsync Pods run on, source or destination
+	Cluster string `json:"cluster"`
+	// Container name of the container in the Rsync Pods
+	Container string `json:"container"`
+	// CPU peak CPU usage sampled
+	CPU *resource.Quantity `json:"cpu,omitempty"`
+	// Memory peak memory usage sampled
+	Memory *resource.Quantity `json:"memory,omitempty"`
+}
+
+// AutoEndpointType endpoint type selected by probing the capabilities of the destination cluster.
+type AutoEndpointType struct {
+	// Type selected endpoint type, Route or ClusterIP
+	Type string `json:"type"`
+	// Reason why the endpoint type was selected
+	Reason string `json:"reason,omitempty"`
+}
+
+// RequeueStatus requeue decision of a reconcile, why the migration is reconciled again.
+type RequeueStatus struct {
+	// Phase phase the decision was made in
+	Phase string `json:"phase,omitempty"`
+	// Reason code of the decision, e.g. WaitingForPVCsBound, TransferInProgress or ConflictRetry
+	Reason string `json:"reason,omitempty"`
+	// Message description of the decision
+	Message string `json:"message,omitempty"`
+	// After interval before the next reconcile, omitted when the migration is reconciled once its status is updated
+	After *metav1.Duration `json:"after,omitempty"`
+	// Since time the same decision was first made in the phase
+	Since *metav1.Time `json:"since,omitempty"`
+}
+
+// TransferSummary summary of the transfer of a migration, partial when the migration failed or was canceled.
+type TransferSummary struct {
+	// Succeeded whether the migration succeeded
+	Succeeded bool `json:"succeeded"`
+	// Duration time from the start to the completion of the migration
+	Duration *metav1.Duration `json:"duration,omitempty"`
+	// TransferredBytes total size of the files transferred, of the PVCs which transfer Rsync reported
+	TransferredBytes *resource.Quantity `json:"transferredBytes,omitempty"`
+	// TransferredFiles total number of regular files transferred
+	TransferredFiles int64 `json:"transferredFiles,omitempty"`
+	// EndpointTypes types of the endpoints the PVCs were transferred through
+	EndpointTypes []string `json:"endpointTypes,omitempty"`
+	// PersistentVolumeClaims outcome of the transfer of each PVC
+	PersistentVolumeClaims []PVCTransferSummary `json:"persistentVolumeClaims,omitempty"`
+}
+
+// PVCTransferSummary outcome of the transfer of a PVC.
+type PVCTransferSummary struct {
+	// PVCReference source PVC
+	PVCReference *kapi.ObjectReference `json:"pvcReference,omitempty"`
+	// State one of Completed, Failed or Pending when the PVC was not transferred
+	State string `json:"state,omitempty"`
+	// Skipped whether the PVC was transferred before the Rsync transfer was restarted
+	Skipped bool `json:"skipped,omitempty"`
+	// Unchanged whether the destination PVC already matched the source PVC, the PVC was not transferred
+	Unchanged bool `json:"unchanged,omitempty"`
+	// EndpointType type of the endpoint the PVC was transferred through
+	EndpointType string `json:"endpointType,omitempty"`
+	// Attempts number of Rsync attempts
+	Attempts int `json:"attempts,omitempty"`
+	// TransferredBytes size of the files transferred, reported by Rsync once the transfer succeeded
+	TransferredBytes *resource.Quantity `json:"transferredBytes,omitempty"`
+	// TransferredFiles number of regular files transferred, reported by Rsync once the transfer succeeded
+	TransferredFiles int64 `json:"transferredFiles,omitempty"`
+	// ChangedBytes size of the data found changed and sent by Rsync, the changed blocks of a block delta transfer, reported by Rsync once the transfer succeeded
+	ChangedBytes *resource.Quantity `json:"changedBytes,omitempty"`
+	// TotalBytes size of the source data Rsync compared, the size of the device of a block PVC, reported by Rsync once the transfer succeeded
+	TotalBytes *resource.Quantity `json:"totalBytes,omitempty"`
+	// ElapsedTime total time taken by the Rsync attempts
+	ElapsedTime *metav1.Duration `json:"elapsedTime,omitempty"`
+}
+
+// States of the transfer of a PVC
+const (
+	PVCTransferPending      = "Pending"
+	PVCTransferTransferring = "Transferring"
+	PVCTransferCompleted    = "Completed"
+	PVCTransferFailed       = "Failed"
+)
+
+// Decisions on the destination PVC of a PVC
+const (
+	DestinationPVCCreated   = "Created"
+	DestinationPVCAdopted   = "Adopted"
+	DestinationPVCRecreated = "Recreated"
+)
+
+// PVCTransferState state of the transfer of a PVC.
+type PVCTransferState struct {
+	// PVCReference source PVC
+	PVCReference *kapi.ObjectReference `json:"pvcReference,omitempty"`
+	// State one of Pending, Transferring, Completed or Failed
+	State string `json:"state,omitempty"`
+	// TransferredBytes bytes transferred so far estimated from the progress of the Rsync Pod, only set while transferring and when the size of the PVC is known
+	TransferredBytes *resource.Quantity `json:"transferredBytes,omitempty"`
+	// RemainingBytes bytes left to transfer estimated from the progress of the Rsync Pod, only set while transferring and when the size of the PVC is known
+	RemainingBytes *resource.Quantity `json:"remainingBytes,omitempty"`
+	// ProgressPercent percentage of the transfer of the PVC, from the progress of the Rsync Pod while transferring and 100 once completed
+	ProgressPercent *int `json:"progressPercent,omitempty"`
+	// TransferredFiles number of files transferred so far by the Rsync Pod, only set while transferring
+	TransferredFiles *int64 `json:"transferredFiles,omitempty"`
+	// RemainingFiles estimated number of files, directories and links Rsync has left to check out of TotalFiles, only set while transferring
+	RemainingFiles *int64 `json:"remainingFiles,omitempty"`
+	// TotalFiles number of files, directories and links of the PVC reported by the MigAnalytic of the plan, or found by Rsync so far when not reported, only set while transferring
+	TotalFiles *int64 `json:"totalFiles,omitempty"`
+	// FilesProgressPercent percentage of the files of the PVC checked by Rsync while transferring and 100 once completed, more meaningful than ProgressPercent for the PVCs with many small files
+	FilesProgressPercent *int `json:"filesProgressPercent,omitempty"`
+	// LastTransitionTime time the PVC entered its current state
+	LastTransitionTime *metav1.Time `json:"lastTransitionTime,omitempty"`
+	// AccessModes access modes of the destination PVC, recorded once the destination PVC is created
+	AccessModes []kapi.PersistentVolumeAccessMode `json:"accessModes,omitempty"`
+	// DestinationPVC one of Created, Adopted or Recreated, how the destination PVC was provided following the existingPVCPolicy, recorded once the destination PVC is created
+	DestinationPVC string `json:"destinationPVC,omitempty"`
+	// BaseCheckpointTime time of the checkpoint held by the destination PVC the delta is transferred onto, only set by the migrations transferring the delta since a checkpoint
+	BaseCheckpointTime *metav1.Time `json:"baseCheckpointTime,omitempty"`
+	// CheckpointTime time of the checkpoint recorded on the destination PVC by the migration once the PVC is transferred
+	CheckpointTime *metav1.Time `json:"checkpointTime,omitempty"`
+}
+
+// GetRemainingPVCs returns the PVCs which transfer is pending or in progress.
+func (ds *DirectVolumeMigrationStatus) GetRemainingPVCs() []PVCTransferState {
+	remaining := []PVCTransferState{}
+	for _, pvc := range ds.PersistentVolumeClaims {
+		if pvc.State == PVCTransferPending || pvc.State == PVCTransferTransferring {
+			remaining = append(remaining, pvc)
+		}
+	}
+	return remaining
+}
+
+// SpeedTest synthetic Rsync transfer benchmarking the clusters with generated data.
+type SpeedTest struct {
+	// Size size of the data generated and transferred for each destination namespace, defaults to 1Gi
+	Size *resource.Quantity `json:"size,omitempty"`
+}
+
+// SpeedTestResult throughput and latency measured by a speed test.
+type SpeedTestResult struct {
+	// Namespace destination namespace the generated data was transferred to
+	Namespace string `json:"namespace,omitempty"`
+	// EndpointType type of the endpoint the generated data was transferred through
+	EndpointType string `json:"endpointType,omitempty"`
+	// TransferredBytes size of the generated data
+	TransferredBytes *resource.Quantity `json:"transferredBytes,omitempty"`
+	// Throughput measured transfer rate in bytes per second
+	Throughput *resource.Quantity `json:"throughput,omitempty"`
+	// Latency time taken to connect to the Rsync transfer Pod and list its content before the transfer
+	Latency *metav1.Duration `json:"latency,omitempty"`
+	// ElapsedTime time taken to transfer the generated data
+	ElapsedTime *metav1.Duration `json:"elapsedTime,omitempty"`
+	// Error failure of the speed test
+	Error string `json:"error,omitempty"`
+}
+
+// TransferPlan transfer plan of a DVM resolved without executing it.
+type TransferPlan struct {
+	// Itinerary itinerary the migration runs
+	Itinerary string `json:"itinerary,omitempty"`
+	// Steps phases of the itinerary, in the order they run
+	Steps []string `json:"steps,omitempty"`
+	// PersistentVolumeClaims PVCs transferred in the order of the spec, their Rsync operations run concurrently
+	PersistentVolumeClaims []TransferPlanPVC `json:"persistentVolumeClaims,omitempty"`
+	// EstimatedSize bytes to transfer, omitted when the size of a PVC is unknown
+	EstimatedSize *resource.Quantity `json:"estimatedSize,omitempty"`
+}
+
+// TransferPlanPVC transfer of a PVC planned by a preview migration.
+type TransferPlanPVC struct {
+	// PVCReference source PVC
+	PVCReference *kapi.ObjectReference `json:"pvcReference,omitempty"`
+	// TargetNamespace namespace of the destination PVC
+	TargetNamespace string `json:"targetNamespace,omitempty"`
+	// TargetStorageClass StorageClass of the destination PVC
+	TargetStorageClass string `json:"targetStorageClass,omitempty"`
+	// EndpointType type of the endpoint exposing the Rsync transfer Pod of the destination namespace
+	EndpointType string `json:"endpointType,omitempty"`
+	// Shards number of concurrent Rsync processes transferring the PVC
+	Shards int `json:"shards,omitempty"`
+	// LargeFileStreams number of parallel Rsync streams the large files of the PVC are split among
+	LargeFileStreams int `json:"largeFileStreams,omitempty"`
+	// Verify whether the transferred files are verified by checksum
+	Verify bool `json:"verify,omitempty"`
+	// Sparse mode the sparse files of the PVC are handled with by Rsync, one of auto, always or never
+	Sparse string `json:"sparse,omitempty"`
+	// Capacity provisioned capacity of the source PVC reported by the MigAnalytic of the plan
+	Capacity *resource.Quantity `json:"capacity,omitempty"`
+	// UsedCapacity used capacity of the source PVC reported by the MigAnalytic of the plan
+	UsedCapacity *resource.Quantity `json:"usedCapacity,omitempty"`
 }
 
 // GetRsyncOperationStatusForPVC returns RsyncOperation from status for matching PVC, creates new one if doesn't exist already
@@ -94,6 +605,12 @@ func (ds *DirectVolumeMigrationStatus) AddRsyncOperation(podStatus *RsyncOperati
 			existing.CurrentAttempt = podStatus.CurrentAttempt
 			existing.Failed = podStatus.Failed
 			existing.Succeeded = podStatus.Succeeded
+			existing.CompletionTimestamp = podStatus.CompletionTimestamp
+			existing.FirstFailureTimestamp = podStatus.FirstFailureTimestamp
+			existing.ToleratedAttempts = podStatus.ToleratedAttempts
+			existing.Unchanged = podStatus.Unchanged
+			existing.VanishedFiles = podStatus.VanishedFiles
+			existing.RsyncProtocol = podStatus.RsyncProtocol
 			return
 		}
 	}
@@ -127,11 +644,15 @@ type DirectVolumeMigrationList struct {
 }
 
 type PodProgress struct {
-	*kapi.ObjectReference       `json:",inline"`
-	PVCReference                *kapi.ObjectReference `json:"pvcRef,omitempty"`
-	LastObservedProgressPercent string                `json:"lastObservedProgressPercent,omitempty"`
-	LastObservedTransferRate    string                `json:"lastObservedTransferRate,omitempty"`
-	TotalElapsedTime            *metav1.Duration      `json:"totalElapsedTime,omitempty"`
+	*kapi.ObjectReference        `json:",inline"`
+	PVCReference                 *kapi.ObjectReference `json:"pvcRef,omitempty"`
+	LastObservedProgressPercent  string                `json:"lastObservedProgressPercent,omitempty"`
+	LastObservedTransferRate     string                `json:"lastObservedTransferRate,omitempty"`
+	LastObservedTransferredFiles int64                 `json:"lastObservedTransferredFiles,omitempty"`
+	LastObservedRemainingFiles   int64                 `json:"lastObservedRemainingFiles,omitempty"`
+	LastObservedTotalFiles       int64                 `json:"lastObservedTotalFiles,omitempty"`
+	TotalElapsedTime             *metav1.Duration      `json:"totalElapsedTime,omitempty"`
+	RsyncStats                   *RsyncStats           `json:"rsyncStats,omitempty"`
 }
 
 // RsyncOperation defines observed state of an Rsync Operation
@@ -144,6 +665,42 @@ type RsyncOperation struct {
 	Succeeded bool `json:"succeeded,omitempty"`
 	// Failed whether operation as a whole failed
 	Failed bool `json:"failed,omitempty"`
+	// CompletionTimestamp time the operation succeeded
+	CompletionTimestamp *metav1.Time `json:"completionTimestamp,omitempty"`
+	// FirstFailureTimestamp time the first failed Rsync attempt of the operation was observed, the RsyncErrorGraceWindow starts then
+	FirstFailureTimestamp *metav1.Time `json:"firstFailureTimestamp,omitempty"`
+	// ToleratedAttempts number of failed attempts retried within the RsyncErrorGraceWindow, not counted against the BackOffLimit
+	ToleratedAttempts int `json:"toleratedAttempts,omitempty"`
+	// Skipped whether the PVC is skipped by the current Rsync transfer, the operation having succeeded before the transfer was restarted
+	Skipped bool `json:"skipped,omitempty"`
+	// Unchanged whether the destination PVC already matched the source PVC when skipUnchangedPVCs is set, the PVC was not transferred
+	Unchanged bool `json:"unchanged,omitempty"`
+	// VanishedFiles number of source files which vanished during the last Rsync attempt
+	VanishedFiles int `json:"vanishedFiles,omitempty"`
+	// Capacity provisioned capacity of the source PVC reported by the MigAnalytic of the plan
+	Capacity *resource.Quantity `json:"capacity,omitempty"`
+	// UsedCapacity used capacity of the source PVC reported by the MigAnalytic of the plan
+	UsedCapacity *resource.Quantity `json:"usedCapacity,omitempty"`
+	// UsedInodes number of files, directories and links of the source PVC reported by the MigAnalytic of the plan
+	UsedInodes int64 `json:"usedInodes,omitempty"`
+	// Differences files differing between the source and destination PVC found by a verify-only migration, or which failed the checksum verification of a verified PVC, limited to the first 100 files
+	Differences []string `json:"differences,omitempty"`
+	// DifferencesCount total number of files differing between the source and destination PVC found by a verify-only migration, or which failed the checksum verification of a verified PVC
+	DifferencesCount int `json:"differencesCount,omitempty"`
+	// RsyncProtocol Rsync versions and protocol version negotiated by the last Rsync attempt, informational
+	RsyncProtocol *RsyncProtocol `json:"rsyncProtocol,omitempty"`
+}
+
+// RsyncProtocol Rsync protocol versions of the Rsync client and daemon of a transfer.
+type RsyncProtocol struct {
+	// ClientVersion version of Rsync in the Rsync client Pod
+	ClientVersion string `json:"clientVersion,omitempty"`
+	// ClientProtocol newest protocol version supported by the Rsync client
+	ClientProtocol int `json:"clientProtocol,omitempty"`
+	// ServerProtocol newest protocol version supported by the Rsync daemon of the Rsync transfer Pod
+	ServerProtocol int `json:"serverProtocol,omitempty"`
+	// NegotiatedProtocol protocol version the Rsync client and daemon transferred with
+	NegotiatedProtocol int `json:"negotiatedProtocol,omitempty"`
 }
 
 func (x *RsyncOperation) Equal(y *RsyncOperation) bool {
@@ -188,6 +745,11 @@ func (r *DirectVolumeMigration) GetMigrationForDVM(client k8sclient.Client) (*Mi
 	return GetMigrationForDVM(client, r.OwnerReferences)
 }
 
+// GetMigrationUID get the UID of the MigMigration owning the DVM
+func (r *DirectVolumeMigration) GetMigrationUID() string {
+	return GetMigrationUID(r.OwnerReferences)
+}
+
 // Add (de-duplicated) errors.
 func (r *DirectVolumeMigration) AddErrors(errors []string) {
 	m := map[string]bool{}
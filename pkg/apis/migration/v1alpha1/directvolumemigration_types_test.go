@@ -21,6 +21,7 @@ import (
 
 	"github.com/onsi/gomega"
 	"golang.org/x/net/context"
+	kapi "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 )
@@ -61,3 +62,26 @@ func TestStorageDirectVolumeMigration(t *testing.T) {
 	g.Expect(c.Delete(context.TODO(), fetched)).NotTo(gomega.HaveOccurred())
 	g.Expect(c.Get(context.TODO(), key, fetched)).To(gomega.HaveOccurred())
 }
+
+func TestDirectVolumeMigrationStatus_AddRsyncOperation(t *testing.T) {
+	completed := metav1.Now()
+	pvc := &kapi.ObjectReference{Namespace: "ns", Name: "pvc"}
+	status := &DirectVolumeMigrationStatus{}
+	status.AddRsyncOperation(&RsyncOperation{PVCReference: pvc, CurrentAttempt: 1})
+	status.AddRsyncOperation(&RsyncOperation{
+		PVCReference:        pvc,
+		CurrentAttempt:      1,
+		Succeeded:           true,
+		CompletionTimestamp: &completed,
+		Unchanged:           true,
+		VanishedFiles:       3,
+	})
+	if len(status.RsyncOperations) != 1 {
+		t.Fatalf("AddRsyncOperation() operations = %v, want a single operation", status.RsyncOperations)
+	}
+	got := status.RsyncOperations[0]
+	if !got.Succeeded || got.CompletionTimestamp == nil || !got.CompletionTimestamp.Equal(&completed) ||
+		!got.Unchanged || got.VanishedFiles != 3 {
+		t.Errorf("AddRsyncOperation() = %v, want the operation succeeded unchanged at %v with 3 vanished files", got, completed)
+	}
+}
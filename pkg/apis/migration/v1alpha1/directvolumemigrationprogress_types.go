@@ -53,7 +53,9 @@ type DirectVolumeMigrationProgressStatus struct {
 	RsyncElapsedTime *metav1.Duration `json:"rsyncElapsedTime,omitempty"`
 	// TotalProgressPercentage cumulative percentage of all Rsync attempts
 	TotalProgressPercentage string `json:"totalProgressPercentage,omitempty"`
-	ObservedDigest          string `json:"observedDigest,omitempty"`
+	// RsyncStats transfer summary of the successful Rsync attempt
+	RsyncStats     *RsyncStats `json:"rsyncStats,omitempty"`
+	ObservedDigest string      `json:"observedDigest,omitempty"`
 }
 
 // RsyncPodStatus defines observed state of an Rsync attempt
@@ -72,10 +74,34 @@ type RsyncPodStatus struct {
 	LastObservedProgressPercent string `json:"lastObservedProgressPercent,omitempty"`
 	// LastObservedTransferRate rate of transfer of Rsync
 	LastObservedTransferRate string `json:"lastObservedTransferRate,omitempty"`
+	// LastObservedTransferredFiles number of files transferred by the Rsync attempt so far
+	LastObservedTransferredFiles int64 `json:"lastObservedTransferredFiles,omitempty"`
+	// LastObservedRemainingFiles number of files Rsync has left to check out of LastObservedTotalFiles
+	LastObservedRemainingFiles int64 `json:"lastObservedRemainingFiles,omitempty"`
+	// LastObservedTotalFiles number of files, directories and links Rsync found so far, growing while Rsync scans the source volume
+	LastObservedTotalFiles int64 `json:"lastObservedTotalFiles,omitempty"`
 	// CreationTimestamp pod creation time
 	CreationTimestamp *metav1.Time `json:"creationTimestamp,omitempty"`
 }
 
+// RsyncStats summary of a completed Rsync transfer reported by rsync --stats
+type RsyncStats struct {
+	// NumberOfFiles number of files, directories and links in the source volume
+	NumberOfFiles int64 `json:"numberOfFiles,omitempty"`
+	// NumberOfFilesTransferred number of regular files transferred
+	NumberOfFilesTransferred int64 `json:"numberOfFilesTransferred,omitempty"`
+	// TotalFileSize total size of all files in the source volume
+	TotalFileSize string `json:"totalFileSize,omitempty"`
+	// TotalTransferredFileSize total size of the transferred files
+	TotalTransferredFileSize string `json:"totalTransferredFileSize,omitempty"`
+	// LiteralData size of the data sent as is, the changed data of a delta transfer
+	LiteralData string `json:"literalData,omitempty"`
+	// MatchedData size of the data found unchanged on the destination and not sent
+	MatchedData string `json:"matchedData,omitempty"`
+	// Speedup ratio of the total file size to the amount of data sent
+	Speedup string `json:"speedup,omitempty"`
+}
+
 // RsyncPodExistsInHistory checks whether Rsync pod status is already part of the history
 func (ds *DirectVolumeMigrationProgressStatus) RsyncPodExistsInHistory(podName string) bool {
 	for _, podStatus := range ds.RsyncPodStatuses {
@@ -129,6 +129,8 @@ type MigAnalyticPersistentVolumeClaim struct {
 	ActualCapacity resource.Quantity `json:"actualCapacity,omitempty"`
 	// Usage of volume in percentage
 	UsagePercentage int `json:"usagePercentage,omitempty"`
+	// Number of inodes used on the volume, i.e. its files, directories and links
+	UsedInodes int64 `json:"usedInodes,omitempty"`
 	// Adjusted capacity of the volume
 	ProposedCapacity resource.Quantity `json:"proposedCapacity,omitempty"`
 	// Human readable reason for proposed adjustment
@@ -60,6 +60,16 @@ const (
 	RegistryImageKey              = "REGISTRY_IMAGE"
 	StagePodImageKey              = "STAGE_IMAGE"
 	RsyncTransferImageKey         = "RSYNC_TRANSFER_IMAGE"
+	RsyncTransferImageOverrideKey = "RSYNC_TRANSFER_IMAGE_OVERRIDE"
+	RsyncEndpointTypeKey          = "RSYNC_ENDPOINT_TYPE"
+	RsyncBwLimitKey               = "RSYNC_BWLIMIT"
+	RsyncCompressKey              = "RSYNC_COMPRESS"
+	RsyncTimeoutKey               = "RSYNC_TIMEOUT"
+	RsyncPodActiveDeadlineKey     = "RSYNC_POD_ACTIVE_DEADLINE_SECONDS"
+	RsyncRouteTimeoutKey          = "RSYNC_ROUTE_TIMEOUT"
+	RsyncRouteConTimeoutKey       = "RSYNC_ROUTE_CONTIMEOUT"
+	RsyncClusterIPTimeoutKey      = "RSYNC_CLUSTERIP_TIMEOUT"
+	RsyncClusterIPConTimeoutKey   = "RSYNC_CLUSTERIP_CONTIMEOUT"
 	ClusterSubdomainKey           = "CLUSTER_SUBDOMAIN"
 	OperatorVersionKey            = "OPERATOR_VERSION"
 	RegistryReadinessProbeTimeout = "REGISTRY_READINESS_TIMEOUT"
@@ -291,7 +301,8 @@ func (m *MigCluster) GetClusterConfigMap(c k8sclient.Client) (*corev1.ConfigMap,
 	return clusterConfig, nil
 }
 
-// GetRegistryImage gets a MigCluster specific registry image from ConfigMap
+// GetRegistryImage gets a MigCluster specific registry image from ConfigMap,
+// rewritten to its mirrored location when a mirror policy of the cluster applies.
 func (m *MigCluster) GetRegistryImage(c k8sclient.Client) (string, error) {
 	clusterConfig, err := m.GetClusterConfigMap(c)
 	if err != nil {
@@ -301,7 +312,7 @@ func (m *MigCluster) GetRegistryImage(c k8sclient.Client) (string, error) {
 	if !ok {
 		return "", liberr.Wrap(errors.Errorf("configmap key not found: %v", RegistryImageKey))
 	}
-	return registryImage, nil
+	return m.GetMirroredImage(c, registryImage)
 }
 
 // GetRegistryLivenessTimeout returns liveness timeout value for migration registry
@@ -350,7 +361,10 @@ func (m *MigCluster) GetRegistryReadinessTimeout(c k8sclient.Client) (int32, err
 	}
 }
 
-// GetRsyncTransferImage gets a MigCluster specific rsync transfer image from ConfigMap
+// GetRsyncTransferImage gets a MigCluster specific rsync transfer image from ConfigMap.
+// An image override set in the ConfigMap is used as is. Otherwise, the default image
+// is rewritten to its mirrored location when a mirror policy of the cluster applies.
+// The image is run by both the Rsync and the stunnel containers of the transfer.
 func (m *MigCluster) GetRsyncTransferImage(c k8sclient.Client) (string, error) {
 	client, err := m.GetClient(c)
 	if err != nil {
@@ -360,11 +374,14 @@ func (m *MigCluster) GetRsyncTransferImage(c k8sclient.Client) (string, error) {
 	if err != nil {
 		return "", liberr.Wrap(err)
 	}
+	if override := clusterConfig.Data[RsyncTransferImageOverrideKey]; override != "" {
+		return override, nil
+	}
 	rsyncImage, ok := clusterConfig.Data[RsyncTransferImageKey]
 	if !ok {
 		return "", liberr.Wrap(errors.Errorf("configmap key not found: %v", RsyncTransferImageKey))
 	}
-	return rsyncImage, nil
+	return m.GetMirroredImage(client, rsyncImage)
 }
 
 // GetClusterSubdomain gets a MigCluster specific subdomain value to be used for DVM routes
@@ -384,6 +401,50 @@ func (m *MigCluster) GetClusterSubdomain(c k8sclient.Client) (string, error) {
 	return clusterSubdomain, nil
 }
 
+// GetRsyncEndpointType gets the MigCluster specific endpoint type of the rsync transfer Pods from ConfigMap.
+// Returns an empty string when not configured.
+func (m *MigCluster) GetRsyncEndpointType(c k8sclient.Client) (string, error) {
+	client, err := m.GetClient(c)
+	if err != nil {
+		return "", err
+	}
+	clusterConfig, err := m.GetClusterConfigMap(client)
+	if err != nil {
+		return "", liberr.Wrap(err)
+	}
+	return clusterConfig.Data[RsyncEndpointTypeKey], nil
+}
+
+// GetRsyncTransferTuning gets the MigCluster specific default tuning of the rsync transfer from ConfigMap.
+// Returns the values of the tuning keys set in the ConfigMap, keyed by ConfigMap key.
+func (m *MigCluster) GetRsyncTransferTuning(c k8sclient.Client) (map[string]string, error) {
+	client, err := m.GetClient(c)
+	if err != nil {
+		return nil, err
+	}
+	clusterConfig, err := m.GetClusterConfigMap(client)
+	if err != nil {
+		return nil, liberr.Wrap(err)
+	}
+	tuning := map[string]string{}
+	keys := []string{
+		RsyncBwLimitKey,
+		RsyncCompressKey,
+		RsyncTimeoutKey,
+		RsyncPodActiveDeadlineKey,
+		RsyncRouteTimeoutKey,
+		RsyncRouteConTimeoutKey,
+		RsyncClusterIPTimeoutKey,
+		RsyncClusterIPConTimeoutKey,
+	}
+	for _, key := range keys {
+		if value, found := clusterConfig.Data[key]; found {
+			tuning[key] = value
+		}
+	}
+	return tuning, nil
+}
+
 // GetOperatorVersion retrieves the operator version from the respective controllers ConfigMap
 func (m *MigCluster) GetOperatorVersion(c k8sclient.Client) (string, error) {
 	clusterConfig, err := m.GetClusterConfigMap(c)
@@ -630,24 +691,39 @@ func (r *MigCluster) GetStorageClasses(client k8sclient.Client) ([]StorageClass,
 // Gets the list of supported access modes for a provisioner
 // TODO: allow the in-file mapping to be overridden by a configmap
 func (r *MigCluster) accessModesForProvisioner(provisioner string) []kapi.PersistentVolumeAccessMode {
+	if accessModes, found := findProvisionerAccessModes(provisioner); found {
+		return accessModes
+	}
+
+	// default value
+	return []kapi.PersistentVolumeAccessMode{kapi.ReadWriteOnce}
+}
+
+// HasKnownAccessModes whether the access modes supported by a provisioner are
+// known, the access modes of the other provisioners default to ReadWriteOnce.
+func (r *MigCluster) HasKnownAccessModes(provisioner string) bool {
+	_, found := findProvisionerAccessModes(provisioner)
+	return found
+}
+
+// Find the access modes of a provisioner in the in-file mapping.
+func findProvisionerAccessModes(provisioner string) ([]kapi.PersistentVolumeAccessMode, bool) {
 	for _, pModes := range accessModeList {
 		if pModes.MatchBySuffix {
 			if strings.HasSuffix(provisioner, pModes.Provisioner) {
-				return pModes.AccessModes
+				return pModes.AccessModes, true
 			}
 		} else if pModes.MatchByPrefix {
 			if strings.HasPrefix(provisioner, pModes.Provisioner) {
-				return pModes.AccessModes
+				return pModes.AccessModes, true
 			}
 		} else {
 			if pModes.Provisioner == provisioner {
-				return pModes.AccessModes
+				return pModes.AccessModes, true
 			}
 		}
 	}
-
-	// default value
-	return []kapi.PersistentVolumeAccessMode{kapi.ReadWriteOnce}
+	return nil, false
 }
 
 type provisionerAccessModes struct {
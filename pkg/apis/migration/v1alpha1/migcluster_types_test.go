@@ -19,10 +19,14 @@ package v1alpha1
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -250,3 +254,171 @@ func TestMigCluster_GetRegistryLivenessTimeout(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveMirroredImage(t *testing.T) {
+	mirrors := []ImageMirror{
+		{Source: "quay.io/konveyor", Mirrors: []string{"mirror.local/konveyor"}, Digest: true},
+		{Source: "quay.io/konveyor/rsync-transfer", Mirrors: []string{"mirror.local/rsync", "backup.local/rsync"}, Digest: true},
+		{Source: "registry.redhat.io/rhmtc", Mirrors: []string{"mirror.local/rhmtc"}, Digest: false},
+	}
+	tests := []struct {
+		name  string
+		image string
+		want  string
+	}{
+		{
+			name:  "when the most specific digest mirror applies, should use its first mirror",
+			image: "quay.io/konveyor/rsync-transfer@sha256:abc",
+			want:  "mirror.local/rsync@sha256:abc",
+		},
+		{
+			name:  "when a parent repository digest mirror applies, should rewrite the repository prefix",
+			image: "quay.io/konveyor/registry@sha256:abc",
+			want:  "mirror.local/konveyor/registry@sha256:abc",
+		},
+		{
+			name:  "when only digest mirrors match a tagged image, should not rewrite",
+			image: "quay.io/konveyor/rsync-transfer:latest",
+			want:  "quay.io/konveyor/rsync-transfer:latest",
+		},
+		{
+			name:  "when a tag mirror applies to a tagged image, should rewrite",
+			image: "registry.redhat.io/rhmtc/rsync-transfer:v1.5",
+			want:  "mirror.local/rhmtc/rsync-transfer:v1.5",
+		},
+		{
+			name:  "when a source only partially matches the repository name, should use the parent repository mirror",
+			image: "quay.io/konveyor/rsync-transfer-test@sha256:abc",
+			want:  "mirror.local/konveyor/rsync-transfer-test@sha256:abc",
+		},
+		{
+			name:  "when no mirror applies, should return the image unchanged",
+			image: "docker.io/library/rsync@sha256:abc",
+			want:  "docker.io/library/rsync@sha256:abc",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ResolveMirroredImage(tt.image, mirrors); got != tt.want {
+				t.Errorf("ResolveMirroredImage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigCluster_GetRegistryImage(t *testing.T) {
+	policyGVK := schema.GroupVersionKind{Group: "operator.openshift.io", Version: "v1alpha1", Kind: "ImageContentSourcePolicy"}
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	scheme.AddKnownTypeWithName(policyGVK, &unstructured.Unstructured{})
+	scheme.AddKnownTypeWithName(policyGVK.GroupVersion().WithKind("ImageContentSourcePolicyList"), &unstructured.UnstructuredList{})
+	policy := &unstructured.Unstructured{}
+	policy.SetGroupVersionKind(policyGVK)
+	policy.SetName("konveyor")
+	_ = unstructured.SetNestedSlice(policy.Object, []interface{}{
+		map[string]interface{}{
+			"source":  "quay.io/konveyor",
+			"mirrors": []interface{}{"mirror.local/konveyor"},
+		},
+	}, "spec", "repositoryDigestMirrors")
+	tests := []struct {
+		name    string
+		objects []runtime.Object
+		want    string
+	}{
+		{
+			name:    "when no mirror policy applies, should return the configured image",
+			objects: []runtime.Object{getClusterConfigMapWithData(map[string]string{RegistryImageKey: "quay.io/konveyor/registry@sha256:abc"})},
+			want:    "quay.io/konveyor/registry@sha256:abc",
+		},
+		{
+			name: "when a mirror policy applies, should return the mirrored image",
+			objects: []runtime.Object{
+				getClusterConfigMapWithData(map[string]string{RegistryImageKey: "quay.io/konveyor/registry@sha256:abc"}),
+				policy,
+			},
+			want: "mirror.local/konveyor/registry@sha256:abc",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the mirrors are cached per cluster
+			m := &MigCluster{ObjectMeta: metav1.ObjectMeta{UID: types.UID(tt.name)}}
+			got, err := m.GetRegistryImage(fake.NewFakeClientWithScheme(scheme, tt.objects...))
+			if err != nil {
+				t.Fatalf("GetRegistryImage() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("GetRegistryImage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigCluster_GetMirroredImage_cached(t *testing.T) {
+	policyGVK := schema.GroupVersionKind{Group: "operator.openshift.io", Version: "v1alpha1", Kind: "ImageContentSourcePolicy"}
+	scheme := runtime.NewScheme()
+	scheme.AddKnownTypeWithName(policyGVK, &unstructured.Unstructured{})
+	scheme.AddKnownTypeWithName(policyGVK.GroupVersion().WithKind("ImageContentSourcePolicyList"), &unstructured.UnstructuredList{})
+	policy := &unstructured.Unstructured{}
+	policy.SetGroupVersionKind(policyGVK)
+	policy.SetName("konveyor")
+	_ = unstructured.SetNestedSlice(policy.Object, []interface{}{
+		map[string]interface{}{
+			"source":  "quay.io/konveyor",
+			"mirrors": []interface{}{"mirror.local/konveyor"},
+		},
+	}, "spec", "repositoryDigestMirrors")
+	image := "quay.io/konveyor/rsync-transfer@sha256:abc"
+	mirrored := "mirror.local/konveyor/rsync-transfer@sha256:abc"
+	m := &MigCluster{ObjectMeta: metav1.ObjectMeta{UID: "cached-mirrors"}}
+
+	got, err := m.GetMirroredImage(fake.NewFakeClientWithScheme(scheme, policy), image)
+	if err != nil || got != mirrored {
+		t.Fatalf("GetMirroredImage() = %v, %v, want %v", got, err, mirrored)
+	}
+	// the policy removed from the cluster is still applied until the cache expires
+	got, err = m.GetMirroredImage(fake.NewFakeClientWithScheme(scheme), image)
+	if err != nil || got != mirrored {
+		t.Errorf("GetMirroredImage() of the cached mirrors = %v, %v, want %v", got, err, mirrored)
+	}
+	imageMirrorsCache.mutex.Lock()
+	imageMirrorsCache.cMap[m.UID] = cachedImageMirrors{mirrors: imageMirrorsCache.cMap[m.UID].mirrors, expires: time.Now()}
+	imageMirrorsCache.mutex.Unlock()
+	got, err = m.GetMirroredImage(fake.NewFakeClientWithScheme(scheme), image)
+	if err != nil || got != image {
+		t.Errorf("GetMirroredImage() once the cache expired = %v, %v, want %v", got, err, image)
+	}
+}
+
+func TestMigCluster_HasKnownAccessModes(t *testing.T) {
+	tests := []struct {
+		name        string
+		provisioner string
+		want        bool
+	}{
+		{
+			name:        "when the provisioner is mapped, should be known",
+			provisioner: "kubernetes.io/glusterfs",
+			want:        true,
+		},
+		{
+			name:        "when the provisioner matches a mapped prefix, should be known",
+			provisioner: "gluster.org/glusterblock-app",
+			want:        true,
+		},
+		{
+			name:        "when the provisioner isn't mapped, should not be known",
+			provisioner: "example.com/custom-csi",
+			want:        false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := &MigCluster{}
+			if got := cluster.HasKnownAccessModes(tt.provisioner); got != tt.want {
+				t.Errorf("MigCluster.HasKnownAccessModes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -18,9 +18,12 @@ package v1alpha1
 
 import (
 	"context"
+	"encoding/base64"
+	"strings"
 
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -32,6 +35,13 @@ const (
 	PostBackupHookPhase  = "PostBackup"
 	PreRestoreHookPhase  = "PreRestore"
 	PostRestoreHookPhase = "PostRestore"
+	// DirectVolumeMigration hook phases
+	PreTransferHookPhase  = "PreTransfer"
+	PostTransferHookPhase = "PostTransfer"
+	// HookJobFailedLimit number of failed Pods of a hook Job after which the hook failed
+	HookJobFailedLimit = 6
+	// BackoffLimitExceededError reason of the condition of a hook Job which exceeded its backoff limit
+	BackoffLimitExceededError = "BackoffLimitExceeded"
 )
 
 // MigHookSpec defines the desired state of MigHook
@@ -91,13 +101,10 @@ func init() {
 // Get an existing hook job.
 func (r *MigHook) GetPhaseJob(client k8sclient.Client, phase string, owner string) (*batchv1.Job, error) {
 	list := batchv1.JobList{}
-	labels := r.GetCorrelationLabels()
-	labels[HookPhaseLabel] = phase
-	labels[HookOwnerLabel] = owner
 	err := client.List(
 		context.TODO(),
 		&list,
-		k8sclient.MatchingLabels(labels))
+		k8sclient.MatchingLabels(r.getPhaseLabels(phase, owner)))
 	if err != nil {
 		return nil, err
 	}
@@ -110,13 +117,10 @@ func (r *MigHook) GetPhaseJob(client k8sclient.Client, phase string, owner strin
 // Get an existing configMap job.
 func (r *MigHook) GetPhaseConfigMap(client k8sclient.Client, phase string, owner string) (*corev1.ConfigMap, error) {
 	list := corev1.ConfigMapList{}
-	labels := r.GetCorrelationLabels()
-	labels[HookPhaseLabel] = phase
-	labels[HookOwnerLabel] = owner
 	err := client.List(
 		context.TODO(),
 		&list,
-		k8sclient.MatchingLabels(labels))
+		k8sclient.MatchingLabels(r.getPhaseLabels(phase, owner)))
 	if err != nil {
 		return nil, err
 	}
@@ -125,3 +129,125 @@ func (r *MigHook) GetPhaseConfigMap(client k8sclient.Client, phase string, owner
 	}
 	return nil, nil
 }
+
+// Delete the hook job of the phase run for the owner, if any.
+// Returns the deleted job, nil when not found.
+func (r *MigHook) DeletePhaseJob(client k8sclient.Client, phase string, owner string) (*batchv1.Job, error) {
+	job, err := r.GetPhaseJob(client, phase, owner)
+	if err != nil || job == nil {
+		return nil, err
+	}
+	err = client.Delete(context.TODO(), job,
+		k8sclient.PropagationPolicy(metav1.DeletePropagationForeground))
+	if err != nil && !k8serror.IsNotFound(err) {
+		return nil, err
+	}
+	return job, nil
+}
+
+// Prepare the job running the hook of the phase for the owner. The playbook
+// configMap of a non-custom hook is created when not found. The names of the
+// job and of the configMap are generated from the prefix.
+func (r *MigHook) PreparePhaseJob(client k8sclient.Client, hook MigPlanHook, owner string, prefix string, container corev1.Container) (*batchv1.Job, error) {
+	if r.Spec.Custom {
+		return r.PhaseJobTemplate(hook, owner, prefix, container, ""), nil
+	}
+	configMap, err := r.GetPhaseConfigMap(client, hook.Phase, owner)
+	if err != nil {
+		return nil, err
+	}
+	if configMap == nil {
+		configMap, err = r.PhaseConfigMapTemplate(hook, owner, prefix)
+		if err != nil {
+			return nil, err
+		}
+		err = client.Create(context.TODO(), configMap)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return r.PhaseJobTemplate(hook, owner, prefix, container, configMap.Name), nil
+}
+
+// Get the configMap holding the playbook of the hook of the phase for the owner.
+func (r *MigHook) PhaseConfigMapTemplate(hook MigPlanHook, owner string, prefix string) (*corev1.ConfigMap, error) {
+	playbookData, err := base64.StdEncoding.DecodeString(r.Spec.Playbook)
+	if err != nil {
+		return nil, err
+	}
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:    hook.ExecutionNamespace,
+			GenerateName: strings.ToLower(prefix + "-" + hook.Phase + "-"),
+			Labels:       r.getPhaseLabels(hook.Phase, owner),
+		},
+		Data: map[string]string{
+			"playbook.yml": string(playbookData),
+		},
+	}, nil
+}
+
+// Get the job running the hook of the phase for the owner in the container,
+// the image, the command and the mounts of the container being set from the
+// hook. The playbook of a non-custom hook is mounted from the configMap.
+func (r *MigHook) PhaseJobTemplate(hook MigPlanHook, owner string, prefix string, container corev1.Container, configMap string) *batchv1.Job {
+	deadlineSeconds := int64(1800)
+	if r.Spec.ActiveDeadlineSeconds != 0 {
+		deadlineSeconds = r.Spec.ActiveDeadlineSeconds
+	}
+	container.Image = r.Spec.Image
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:    hook.ExecutionNamespace,
+			GenerateName: strings.ToLower(prefix + "-" + hook.Phase + "-"),
+			Labels:       r.getPhaseLabels(hook.Phase, owner),
+		},
+		Spec: batchv1.JobSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy:         "OnFailure",
+					ServiceAccountName:    hook.ServiceAccount,
+					ActiveDeadlineSeconds: &deadlineSeconds,
+				},
+			},
+		},
+	}
+	if configMap != "" {
+		container.Command = []string{
+			"/bin/entrypoint",
+			"ansible-runner",
+			"-p",
+			"/tmp/playbook/playbook.yml",
+			"run",
+			"/tmp/runner",
+		}
+		container.VolumeMounts = []corev1.VolumeMount{
+			{
+				Name:      "playbook",
+				MountPath: "/tmp/playbook",
+			},
+		}
+		job.Spec.Template.Spec.Volumes = []corev1.Volume{
+			{
+				Name: "playbook",
+				VolumeSource: corev1.VolumeSource{
+					ConfigMap: &corev1.ConfigMapVolumeSource{
+						LocalObjectReference: corev1.LocalObjectReference{
+							Name: configMap,
+						},
+					},
+				},
+			},
+		}
+	}
+	job.Spec.Template.Spec.Containers = []corev1.Container{container}
+	return job
+}
+
+// Get the labels of the resources of the hook of the phase run for the owner.
+func (r *MigHook) getPhaseLabels(phase string, owner string) map[string]string {
+	labels := r.GetCorrelationLabels()
+	labels[HookPhaseLabel] = phase
+	labels[HookOwnerLabel] = owner
+	return labels
+}
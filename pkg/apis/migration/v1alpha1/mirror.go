@@ -0,0 +1,181 @@
+package v1alpha1
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	liberr "github.com/konveyor/controller/pkg/error"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ImageMirror maps a source repository to its mirrored repositories.
+// Digest mirrors only apply to images referenced by digest, tag mirrors
+// only apply to images referenced by tag.
+type ImageMirror struct {
+	Source  string
+	Mirrors []string
+	Digest  bool
+}
+
+// Mirror policies of OpenShift clusters, listed using unstructured
+// objects as the APIs aren't available on every cluster.
+var imageMirrorPolicies = []struct {
+	gvk    schema.GroupVersionKind
+	field  string
+	digest bool
+}{
+	{
+		gvk:    schema.GroupVersionKind{Group: "operator.openshift.io", Version: "v1alpha1", Kind: "ImageContentSourcePolicyList"},
+		field:  "repositoryDigestMirrors",
+		digest: true,
+	},
+	{
+		gvk:    schema.GroupVersionKind{Group: "config.openshift.io", Version: "v1", Kind: "ImageDigestMirrorSetList"},
+		field:  "imageDigestMirrors",
+		digest: true,
+	},
+	{
+		gvk:    schema.GroupVersionKind{Group: "config.openshift.io", Version: "v1", Kind: "ImageTagMirrorSetList"},
+		field:  "imageTagMirrors",
+		digest: false,
+	},
+}
+
+// ImageMirrorsTTL duration the image mirrors of a cluster are cached for, the
+// images of the migration resources being resolved on every reconcile.
+const ImageMirrorsTTL = 5 * time.Minute
+
+var imageMirrorsCache imageMirrorsMap
+
+// Maps MigCluster UID to the image mirrors listed on that cluster.
+type imageMirrorsMap struct {
+	cMap  map[types.UID]cachedImageMirrors
+	mutex sync.RWMutex
+}
+
+type cachedImageMirrors struct {
+	mirrors []ImageMirror
+	expires time.Time
+}
+
+func (cm *imageMirrorsMap) Get(key types.UID) ([]ImageMirror, bool) {
+	cm.mutex.RLock()
+	defer cm.mutex.RUnlock()
+	val, found := cm.cMap[key]
+	if !found || time.Now().After(val.expires) {
+		return nil, false
+	}
+	return val.mirrors, true
+}
+
+func (cm *imageMirrorsMap) Set(key types.UID, mirrors []ImageMirror) {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+	if cm.cMap == nil {
+		cm.cMap = make(map[types.UID]cachedImageMirrors)
+	}
+	cm.cMap[key] = cachedImageMirrors{mirrors: mirrors, expires: time.Now().Add(ImageMirrorsTTL)}
+}
+
+// ListImageMirrors lists the image mirrors defined by the ImageContentSourcePolicy,
+// ImageDigestMirrorSet and ImageTagMirrorSet resources of the cluster.
+// Policies which aren't served or can't be read on the cluster are skipped.
+func ListImageMirrors(client k8sclient.Client) ([]ImageMirror, error) {
+	mirrors := []ImageMirror{}
+	for _, policy := range imageMirrorPolicies {
+		list := unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(policy.gvk)
+		err := client.List(context.TODO(), &list)
+		if err != nil {
+			if meta.IsNoMatchError(err) || runtime.IsNotRegisteredError(err) ||
+				k8serror.IsNotFound(err) || k8serror.IsForbidden(err) {
+				continue
+			}
+			return nil, liberr.Wrap(err)
+		}
+		for _, item := range list.Items {
+			entries, _, err := unstructured.NestedSlice(item.Object, "spec", policy.field)
+			if err != nil {
+				continue
+			}
+			for _, e := range entries {
+				entry, ok := e.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				source, _, _ := unstructured.NestedString(entry, "source")
+				mirrorList, _, _ := unstructured.NestedStringSlice(entry, "mirrors")
+				if source == "" || len(mirrorList) == 0 {
+					continue
+				}
+				mirrors = append(mirrors, ImageMirror{
+					Source:  source,
+					Mirrors: mirrorList,
+					Digest:  policy.digest,
+				})
+			}
+		}
+	}
+	return mirrors, nil
+}
+
+// GetImageMirrors returns the image mirrors of the cluster, listed with the
+// client of the cluster at most once per ImageMirrorsTTL.
+func (m *MigCluster) GetImageMirrors(client k8sclient.Client) ([]ImageMirror, error) {
+	if mirrors, found := imageMirrorsCache.Get(m.UID); found {
+		return mirrors, nil
+	}
+	mirrors, err := ListImageMirrors(client)
+	if err != nil {
+		return nil, liberr.Wrap(err)
+	}
+	imageMirrorsCache.Set(m.UID, mirrors)
+	return mirrors, nil
+}
+
+// GetMirroredImage rewrites the image to its mirrored location using the
+// mirror policies of the cluster, see ResolveMirroredImage.
+func (m *MigCluster) GetMirroredImage(client k8sclient.Client, image string) (string, error) {
+	mirrors, err := m.GetImageMirrors(client)
+	if err != nil {
+		return "", liberr.Wrap(err)
+	}
+	return ResolveMirroredImage(image, mirrors), nil
+}
+
+// ResolveMirroredImage rewrites the image to its mirrored location using the
+// most specific source matching the image repository. The image is returned
+// unchanged when no mirror applies. Only the first mirror of the source is
+// used: unlike the node runtime, which tries the mirrors in order, the image
+// isn't pulled from the next mirrors when the first one can't serve it.
+func ResolveMirroredImage(image string, mirrors []ImageMirror) string {
+	digest := strings.Contains(image, "@")
+	sorted := make([]ImageMirror, len(mirrors))
+	copy(sorted, mirrors)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return len(sorted[i].Source) > len(sorted[j].Source)
+	})
+	for _, mirror := range sorted {
+		if mirror.Digest != digest {
+			continue
+		}
+		if !strings.HasPrefix(image, mirror.Source) {
+			continue
+		}
+		remainder := strings.TrimPrefix(image, mirror.Source)
+		if remainder != "" && !strings.ContainsAny(remainder[:1], "/:@") {
+			continue
+		}
+		return mirror.Mirrors[0] + remainder
+	}
+	return image
+}
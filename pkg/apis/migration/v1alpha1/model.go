@@ -162,6 +162,18 @@ func GetMigrationForDVM(client k8sclient.Client, owners []metav1.OwnerReference)
 	return &migrationObject, nil
 }
 
+// Get the UID of the MigMigration owning a resource.
+// Returns an empty string when the resource is not owned by a MigMigration.
+func GetMigrationUID(owners []metav1.OwnerReference) string {
+	for _, ownerRef := range owners {
+		if ownerRef.Kind != "MigMigration" {
+			continue
+		}
+		return string(ownerRef.UID)
+	}
+	return ""
+}
+
 // List MigStorage
 // Returns and empty list when none found.
 func ListStorage(client k8sclient.Client) ([]MigStorage, error) {
@@ -223,6 +235,31 @@ func GetSecret(client k8sclient.Client, ref *kapi.ObjectReference) (*kapi.Secret
 	return &object, err
 }
 
+// Get a referenced ConfigMap.
+// Returns `nil` when the reference cannot be resolved.
+func GetConfigMap(client k8sclient.Client, ref *kapi.ObjectReference) (*kapi.ConfigMap, error) {
+	if ref == nil {
+		return nil, nil
+	}
+	object := kapi.ConfigMap{}
+	err := client.Get(
+		context.TODO(),
+		types.NamespacedName{
+			Namespace: ref.Namespace,
+			Name:      ref.Name,
+		},
+		&object)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		} else {
+			return nil, err
+		}
+	}
+
+	return &object, err
+}
+
 // Get a referenced ImageStream.
 // Returns `nil` when the reference cannot be resolved.
 func GetImageStream(client k8sclient.Client, ref *kapi.ObjectReference) (*imagev1.ImageStream, error) {
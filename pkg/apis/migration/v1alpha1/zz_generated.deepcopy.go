@@ -26,6 +26,21 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutoEndpointType) DeepCopyInto(out *AutoEndpointType) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoEndpointType.
+func (in *AutoEndpointType) DeepCopy() *AutoEndpointType {
+	if in == nil {
+		return nil
+	}
+	out := new(AutoEndpointType)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BackupStorageConfig) DeepCopyInto(out *BackupStorageConfig) {
 	*out = *in
@@ -94,6 +109,31 @@ func (in *Conditions) DeepCopy() *Conditions {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerResourceUsage) DeepCopyInto(out *ContainerResourceUsage) {
+	*out = *in
+	if in.CPU != nil {
+		in, out := &in.CPU, &out.CPU
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Memory != nil {
+		in, out := &in.Memory, &out.Memory
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerResourceUsage.
+func (in *ContainerResourceUsage) DeepCopy() *ContainerResourceUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerResourceUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DirectImageMigration) DeepCopyInto(out *DirectImageMigration) {
 	*out = *in
@@ -533,6 +573,11 @@ func (in *DirectVolumeMigrationProgressStatus) DeepCopyInto(out *DirectVolumeMig
 		*out = new(metav1.Duration)
 		**out = **in
 	}
+	if in.RsyncStats != nil {
+		in, out := &in.RsyncStats, &out.RsyncStats
+		*out = new(RsyncStats)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DirectVolumeMigrationProgressStatus.
@@ -565,6 +610,128 @@ func (in *DirectVolumeMigrationSpec) DeepCopyInto(out *DirectVolumeMigrationSpec
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Deadline != nil {
+		in, out := &in.Deadline, &out.Deadline
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = make([]MigPlanHook, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RsyncUID != nil {
+		in, out := &in.RsyncUID, &out.RsyncUID
+		*out = new(int64)
+		**out = **in
+	}
+	if in.RsyncGID != nil {
+		in, out := &in.RsyncGID, &out.RsyncGID
+		*out = new(int64)
+		**out = **in
+	}
+	if in.DestinationFSGroup != nil {
+		in, out := &in.DestinationFSGroup, &out.DestinationFSGroup
+		*out = new(int64)
+		**out = **in
+	}
+	if in.RsyncPodActiveDeadlineSeconds != nil {
+		in, out := &in.RsyncPodActiveDeadlineSeconds, &out.RsyncPodActiveDeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.RsyncBwLimit != nil {
+		in, out := &in.RsyncBwLimit, &out.RsyncBwLimit
+		*out = new(int)
+		**out = **in
+	}
+	if in.RsyncBwLimitRampUp != nil {
+		in, out := &in.RsyncBwLimitRampUp, &out.RsyncBwLimitRampUp
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.RsyncCompress != nil {
+		in, out := &in.RsyncCompress, &out.RsyncCompress
+		*out = new(bool)
+		**out = **in
+	}
+	if in.RsyncCompressLevel != nil {
+		in, out := &in.RsyncCompressLevel, &out.RsyncCompressLevel
+		*out = new(int)
+		**out = **in
+	}
+	if in.RsyncErrorGraceWindow != nil {
+		in, out := &in.RsyncErrorGraceWindow, &out.RsyncErrorGraceWindow
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.RsyncTimeout != nil {
+		in, out := &in.RsyncTimeout, &out.RsyncTimeout
+		*out = new(int)
+		**out = **in
+	}
+	if in.RsyncOpenFilesLimit != nil {
+		in, out := &in.RsyncOpenFilesLimit, &out.RsyncOpenFilesLimit
+		*out = new(int64)
+		**out = **in
+	}
+	if in.RsyncModifyWindow != nil {
+		in, out := &in.RsyncModifyWindow, &out.RsyncModifyWindow
+		*out = new(int)
+		**out = **in
+	}
+	if in.ProgressCallback != nil {
+		in, out := &in.ProgressCallback, &out.ProgressCallback
+		*out = new(ProgressCallback)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RsyncFilter != nil {
+		in, out := &in.RsyncFilter, &out.RsyncFilter
+		*out = new(RsyncFilter)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TTLAfterCompleted != nil {
+		in, out := &in.TTLAfterCompleted, &out.TTLAfterCompleted
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.TTLAfterFailed != nil {
+		in, out := &in.TTLAfterFailed, &out.TTLAfterFailed
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.BaselineThroughput != nil {
+		in, out := &in.BaselineThroughput, &out.BaselineThroughput
+		*out = new(int)
+		**out = **in
+	}
+	if in.SpeedTest != nil {
+		in, out := &in.SpeedTest, &out.SpeedTest
+		*out = new(SpeedTest)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaxConcurrentTransfers != nil {
+		in, out := &in.MaxConcurrentTransfers, &out.MaxConcurrentTransfers
+		*out = new(int)
+		**out = **in
+	}
+	if in.TunnelEndpoints != nil {
+		in, out := &in.TunnelEndpoints, &out.TunnelEndpoints
+		*out = make([]TunnelEndpoint, len(*in))
+		copy(*out, *in)
+	}
+	if in.RequeueIntervals != nil {
+		in, out := &in.RequeueIntervals, &out.RequeueIntervals
+		*out = new(RequeueIntervals)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PVCAnnotations != nil {
+		in, out := &in.PVCAnnotations, &out.PVCAnnotations
+		*out = new(PVCAnnotations)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DirectVolumeMigrationSpec.
@@ -645,6 +812,86 @@ func (in *DirectVolumeMigrationStatus) DeepCopyInto(out *DirectVolumeMigrationSt
 			}
 		}
 	}
+	if in.ItinerarySteps != nil {
+		in, out := &in.ItinerarySteps, &out.ItinerarySteps
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PrewarmElapsedTime != nil {
+		in, out := &in.PrewarmElapsedTime, &out.PrewarmElapsedTime
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.EstimatedCompletionTimestamp != nil {
+		in, out := &in.EstimatedCompletionTimestamp, &out.EstimatedCompletionTimestamp
+		*out = (*in).DeepCopy()
+	}
+	if in.SmoothedTransferRate != nil {
+		in, out := &in.SmoothedTransferRate, &out.SmoothedTransferRate
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.TransferPlan != nil {
+		in, out := &in.TransferPlan, &out.TransferPlan
+		*out = new(TransferPlan)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CompletionTimestamp != nil {
+		in, out := &in.CompletionTimestamp, &out.CompletionTimestamp
+		*out = (*in).DeepCopy()
+	}
+	if in.PersistentVolumeClaims != nil {
+		in, out := &in.PersistentVolumeClaims, &out.PersistentVolumeClaims
+		*out = make([]PVCTransferState, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SpeedTestResults != nil {
+		in, out := &in.SpeedTestResults, &out.SpeedTestResults
+		*out = make([]SpeedTestResult, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ClockSkew != nil {
+		in, out := &in.ClockSkew, &out.ClockSkew
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.TransferSummary != nil {
+		in, out := &in.TransferSummary, &out.TransferSummary
+		*out = new(TransferSummary)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Requeue != nil {
+		in, out := &in.Requeue, &out.Requeue
+		*out = new(RequeueStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AutoEndpointType != nil {
+		in, out := &in.AutoEndpointType, &out.AutoEndpointType
+		*out = new(AutoEndpointType)
+		**out = **in
+	}
+	if in.PeakResourceUsage != nil {
+		in, out := &in.PeakResourceUsage, &out.PeakResourceUsage
+		*out = make([]ContainerResourceUsage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = make([]HookStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastCheckpointTime != nil {
+		in, out := &in.LastCheckpointTime, &out.LastCheckpointTime
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DirectVolumeMigrationStatus.
@@ -657,6 +904,34 @@ func (in *DirectVolumeMigrationStatus) DeepCopy() *DirectVolumeMigrationStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HookStatus) DeepCopyInto(out *HookStatus) {
+	*out = *in
+	if in.JobReference != nil {
+		in, out := &in.JobReference, &out.JobReference
+		*out = new(v1.ObjectReference)
+		**out = **in
+	}
+	if in.StartTimestamp != nil {
+		in, out := &in.StartTimestamp, &out.StartTimestamp
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTimestamp != nil {
+		in, out := &in.CompletionTimestamp, &out.CompletionTimestamp
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HookStatus.
+func (in *HookStatus) DeepCopy() *HookStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HookStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ImageStreamListItem) DeepCopyInto(out *ImageStreamListItem) {
 	*out = *in
@@ -1566,6 +1841,33 @@ func (in *PVC) DeepCopy() *PVC {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PVCAnnotations) DeepCopyInto(out *PVCAnnotations) {
+	*out = *in
+	if in.Propagate != nil {
+		in, out := &in.Propagate, &out.Propagate
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Set != nil {
+		in, out := &in.Set, &out.Set
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PVCAnnotations.
+func (in *PVCAnnotations) DeepCopy() *PVCAnnotations {
+	if in == nil {
+		return nil
+	}
+	out := new(PVCAnnotations)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PVCToMigrate) DeepCopyInto(out *PVCToMigrate) {
 	*out = *in
@@ -1591,6 +1893,118 @@ func (in *PVCToMigrate) DeepCopy() *PVCToMigrate {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PVCTransferState) DeepCopyInto(out *PVCTransferState) {
+	*out = *in
+	if in.PVCReference != nil {
+		in, out := &in.PVCReference, &out.PVCReference
+		*out = new(v1.ObjectReference)
+		**out = **in
+	}
+	if in.TransferredBytes != nil {
+		in, out := &in.TransferredBytes, &out.TransferredBytes
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.RemainingBytes != nil {
+		in, out := &in.RemainingBytes, &out.RemainingBytes
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.ProgressPercent != nil {
+		in, out := &in.ProgressPercent, &out.ProgressPercent
+		*out = new(int)
+		**out = **in
+	}
+	if in.TransferredFiles != nil {
+		in, out := &in.TransferredFiles, &out.TransferredFiles
+		*out = new(int64)
+		**out = **in
+	}
+	if in.RemainingFiles != nil {
+		in, out := &in.RemainingFiles, &out.RemainingFiles
+		*out = new(int64)
+		**out = **in
+	}
+	if in.TotalFiles != nil {
+		in, out := &in.TotalFiles, &out.TotalFiles
+		*out = new(int64)
+		**out = **in
+	}
+	if in.FilesProgressPercent != nil {
+		in, out := &in.FilesProgressPercent, &out.FilesProgressPercent
+		*out = new(int)
+		**out = **in
+	}
+	if in.LastTransitionTime != nil {
+		in, out := &in.LastTransitionTime, &out.LastTransitionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.AccessModes != nil {
+		in, out := &in.AccessModes, &out.AccessModes
+		*out = make([]v1.PersistentVolumeAccessMode, len(*in))
+		copy(*out, *in)
+	}
+	if in.BaseCheckpointTime != nil {
+		in, out := &in.BaseCheckpointTime, &out.BaseCheckpointTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CheckpointTime != nil {
+		in, out := &in.CheckpointTime, &out.CheckpointTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PVCTransferState.
+func (in *PVCTransferState) DeepCopy() *PVCTransferState {
+	if in == nil {
+		return nil
+	}
+	out := new(PVCTransferState)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PVCTransferSummary) DeepCopyInto(out *PVCTransferSummary) {
+	*out = *in
+	if in.PVCReference != nil {
+		in, out := &in.PVCReference, &out.PVCReference
+		*out = new(v1.ObjectReference)
+		**out = **in
+	}
+	if in.TransferredBytes != nil {
+		in, out := &in.TransferredBytes, &out.TransferredBytes
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.ChangedBytes != nil {
+		in, out := &in.ChangedBytes, &out.ChangedBytes
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.TotalBytes != nil {
+		in, out := &in.TotalBytes, &out.TotalBytes
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.ElapsedTime != nil {
+		in, out := &in.ElapsedTime, &out.ElapsedTime
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PVCTransferSummary.
+func (in *PVCTransferSummary) DeepCopy() *PVCTransferSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(PVCTransferSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PersistentVolumes) DeepCopyInto(out *PersistentVolumes) {
 	*out = *in
@@ -1673,6 +2087,11 @@ func (in *PodProgress) DeepCopyInto(out *PodProgress) {
 		*out = new(metav1.Duration)
 		**out = **in
 	}
+	if in.RsyncStats != nil {
+		in, out := &in.RsyncStats, &out.RsyncStats
+		*out = new(RsyncStats)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodProgress.
@@ -1685,6 +2104,105 @@ func (in *PodProgress) DeepCopy() *PodProgress {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProgressCallback) DeepCopyInto(out *ProgressCallback) {
+	*out = *in
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(v1.ObjectReference)
+		**out = **in
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProgressCallback.
+func (in *ProgressCallback) DeepCopy() *ProgressCallback {
+	if in == nil {
+		return nil
+	}
+	out := new(ProgressCallback)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RequeueIntervals) DeepCopyInto(out *RequeueIntervals) {
+	*out = *in
+	if in.Fast != nil {
+		in, out := &in.Fast, &out.Fast
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Poll != nil {
+		in, out := &in.Poll, &out.Poll
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RequeueIntervals.
+func (in *RequeueIntervals) DeepCopy() *RequeueIntervals {
+	if in == nil {
+		return nil
+	}
+	out := new(RequeueIntervals)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RequeueStatus) DeepCopyInto(out *RequeueStatus) {
+	*out = *in
+	if in.After != nil {
+		in, out := &in.After, &out.After
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Since != nil {
+		in, out := &in.Since, &out.Since
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RequeueStatus.
+func (in *RequeueStatus) DeepCopy() *RequeueStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RequeueStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RsyncFilter) DeepCopyInto(out *RsyncFilter) {
+	*out = *in
+	if in.ConfigMapRef != nil {
+		in, out := &in.ConfigMapRef, &out.ConfigMapRef
+		*out = new(v1.ObjectReference)
+		**out = **in
+	}
+	if in.Excludes != nil {
+		in, out := &in.Excludes, &out.Excludes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RsyncFilter.
+func (in *RsyncFilter) DeepCopy() *RsyncFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(RsyncFilter)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RsyncOperation) DeepCopyInto(out *RsyncOperation) {
 	*out = *in
@@ -1693,6 +2211,34 @@ func (in *RsyncOperation) DeepCopyInto(out *RsyncOperation) {
 		*out = new(v1.ObjectReference)
 		**out = **in
 	}
+	if in.CompletionTimestamp != nil {
+		in, out := &in.CompletionTimestamp, &out.CompletionTimestamp
+		*out = (*in).DeepCopy()
+	}
+	if in.FirstFailureTimestamp != nil {
+		in, out := &in.FirstFailureTimestamp, &out.FirstFailureTimestamp
+		*out = (*in).DeepCopy()
+	}
+	if in.Capacity != nil {
+		in, out := &in.Capacity, &out.Capacity
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.UsedCapacity != nil {
+		in, out := &in.UsedCapacity, &out.UsedCapacity
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Differences != nil {
+		in, out := &in.Differences, &out.Differences
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RsyncProtocol != nil {
+		in, out := &in.RsyncProtocol, &out.RsyncProtocol
+		*out = new(RsyncProtocol)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RsyncOperation.
@@ -1734,6 +2280,36 @@ func (in *RsyncPodStatus) DeepCopy() *RsyncPodStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RsyncProtocol) DeepCopyInto(out *RsyncProtocol) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RsyncProtocol.
+func (in *RsyncProtocol) DeepCopy() *RsyncProtocol {
+	if in == nil {
+		return nil
+	}
+	out := new(RsyncProtocol)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RsyncStats) DeepCopyInto(out *RsyncStats) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RsyncStats.
+func (in *RsyncStats) DeepCopy() *RsyncStats {
+	if in == nil {
+		return nil
+	}
+	out := new(RsyncStats)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Selection) DeepCopyInto(out *Selection) {
 	*out = *in
@@ -1749,6 +2325,61 @@ func (in *Selection) DeepCopy() *Selection {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SpeedTest) DeepCopyInto(out *SpeedTest) {
+	*out = *in
+	if in.Size != nil {
+		in, out := &in.Size, &out.Size
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpeedTest.
+func (in *SpeedTest) DeepCopy() *SpeedTest {
+	if in == nil {
+		return nil
+	}
+	out := new(SpeedTest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SpeedTestResult) DeepCopyInto(out *SpeedTestResult) {
+	*out = *in
+	if in.TransferredBytes != nil {
+		in, out := &in.TransferredBytes, &out.TransferredBytes
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Throughput != nil {
+		in, out := &in.Throughput, &out.Throughput
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Latency != nil {
+		in, out := &in.Latency, &out.Latency
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.ElapsedTime != nil {
+		in, out := &in.ElapsedTime, &out.ElapsedTime
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpeedTestResult.
+func (in *SpeedTestResult) DeepCopy() *SpeedTestResult {
+	if in == nil {
+		return nil
+	}
+	out := new(SpeedTestResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Step) DeepCopyInto(out *Step) {
 	*out = *in
@@ -1838,6 +2469,120 @@ func (in *Timed) DeepCopy() *Timed {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TransferPlan) DeepCopyInto(out *TransferPlan) {
+	*out = *in
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PersistentVolumeClaims != nil {
+		in, out := &in.PersistentVolumeClaims, &out.PersistentVolumeClaims
+		*out = make([]TransferPlanPVC, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.EstimatedSize != nil {
+		in, out := &in.EstimatedSize, &out.EstimatedSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransferPlan.
+func (in *TransferPlan) DeepCopy() *TransferPlan {
+	if in == nil {
+		return nil
+	}
+	out := new(TransferPlan)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TransferPlanPVC) DeepCopyInto(out *TransferPlanPVC) {
+	*out = *in
+	if in.PVCReference != nil {
+		in, out := &in.PVCReference, &out.PVCReference
+		*out = new(v1.ObjectReference)
+		**out = **in
+	}
+	if in.Capacity != nil {
+		in, out := &in.Capacity, &out.Capacity
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.UsedCapacity != nil {
+		in, out := &in.UsedCapacity, &out.UsedCapacity
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransferPlanPVC.
+func (in *TransferPlanPVC) DeepCopy() *TransferPlanPVC {
+	if in == nil {
+		return nil
+	}
+	out := new(TransferPlanPVC)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TransferSummary) DeepCopyInto(out *TransferSummary) {
+	*out = *in
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.TransferredBytes != nil {
+		in, out := &in.TransferredBytes, &out.TransferredBytes
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.EndpointTypes != nil {
+		in, out := &in.EndpointTypes, &out.EndpointTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PersistentVolumeClaims != nil {
+		in, out := &in.PersistentVolumeClaims, &out.PersistentVolumeClaims
+		*out = make([]PVCTransferSummary, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransferSummary.
+func (in *TransferSummary) DeepCopy() *TransferSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(TransferSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TunnelEndpoint) DeepCopyInto(out *TunnelEndpoint) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TunnelEndpoint.
+func (in *TunnelEndpoint) DeepCopy() *TunnelEndpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(TunnelEndpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UnhealthyNamespace) DeepCopyInto(out *UnhealthyNamespace) {
 	*out = *in
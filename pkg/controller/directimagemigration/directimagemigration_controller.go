@@ -127,6 +127,11 @@ func (r *ReconcileDirectImageMigration) Reconcile(ctx context.Context, request r
 		log.Real = log.WithValues("migMigration", migration.Name)
 	}
 
+	// Set MigMigration UID key on logger to correlate logs across controllers
+	if migrationUID := imageMigration.GetMigrationUID(); migrationUID != "" {
+		log.Real = log.WithValues("migrationUID", migrationUID)
+	}
+
 	// Set up jaeger tracing, add to ctx
 	reconcileSpan := r.initTracer(imageMigration)
 	if reconcileSpan != nil {
@@ -31,17 +31,12 @@ func (r *ReconcileDirectImageMigration) initTracer(dim *migapi.DirectImageMigrat
 	// Set tracer on reconciler if it's not already present.
 	// We will close spans, but not the tracer, so the 'closer' is discarded.
 	if r.tracer == nil {
-		r.tracer, _ = migtrace.InitJaeger("DirectImageMigration")
+		r.tracer, _ = migtrace.InitTracer("DirectImageMigration")
 	}
 
 	// Get overall migration span
 	var migrationSpan opentracing.Span
-	ownerRefs := dim.GetOwnerReferences()
-	for _, ownerRef := range ownerRefs {
-		if ownerRef.Kind != "MigMigration" {
-			continue
-		}
-		migrationUID := string(ownerRef.UID)
+	if migrationUID := dim.GetMigrationUID(); migrationUID != "" {
 		migrationSpan = migtrace.GetSpanForMigrationUID(migrationUID)
 	}
 	if migrationSpan == nil {
@@ -35,7 +35,7 @@ func (r *ReconcileDirectImageStreamMigration) initTracer(dism migapi.DirectImage
 	// Set tracer on reconciler if it's not already present.
 	// We will never close this, so the 'closer' is discarded.
 	if r.tracer == nil {
-		r.tracer, _ = migtrace.InitJaeger("DirectImageStreamMigration")
+		r.tracer, _ = migtrace.InitTracer("DirectImageStreamMigration")
 	}
 
 	// Go from dism -> dim -> migration, use migration UID to get span
@@ -0,0 +1,118 @@
+package directvolumemigration
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// ReservedPVCAnnotationPrefixes prefixes of the annotations managed by
+// Kubernetes for the binding and provisioning of a PVC. They are never
+// propagated nor set on the destination PVCs, which are bound and provisioned
+// on the destination cluster.
+var ReservedPVCAnnotationPrefixes = []string{
+	"pv.kubernetes.io/",
+	"volume.beta.kubernetes.io/",
+	"volume.kubernetes.io/",
+}
+
+// Get whether an annotation key is reserved for the binding and provisioning of PVCs.
+func isReservedPVCAnnotation(key string) bool {
+	for _, prefix := range ReservedPVCAnnotationPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Get whether a source PVC annotation key is allowed by the propagate keys,
+// a key ending with '*' matches the keys starting with its prefix.
+func isPropagatedPVCAnnotation(propagate []string, key string) bool {
+	for _, allowed := range propagate {
+		if prefix := strings.TrimSuffix(allowed, "*"); prefix != allowed {
+			if strings.HasPrefix(key, prefix) {
+				return true
+			}
+		} else if key == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// Get the annotations of a destination PVC from the annotations of its source
+// PVC. The source annotations allowed by Propagate are copied, then the Set
+// annotations are applied over them. The reserved annotations are never
+// copied. Returns nil when the destination PVC has no annotations.
+func getDestinationPVCAnnotations(annotations *migapi.PVCAnnotations, source map[string]string) map[string]string {
+	if annotations == nil {
+		return nil
+	}
+	destination := map[string]string{}
+	for key, value := range source {
+		if isReservedPVCAnnotation(key) || !isPropagatedPVCAnnotation(annotations.Propagate, key) {
+			continue
+		}
+		destination[key] = value
+	}
+	for key, value := range annotations.Set {
+		destination[key] = value
+	}
+	if len(destination) == 0 {
+		return nil
+	}
+	return destination
+}
+
+// Get the problems of the annotations of the destination PVCs, the propagate
+// keys must be qualified names or prefixes of them ending with '*', the set
+// annotations must be valid annotations. Neither may be reserved.
+func getPVCAnnotationsProblems(annotations *migapi.PVCAnnotations) []string {
+	problems := []string{}
+	if annotations == nil {
+		return problems
+	}
+	for _, key := range annotations.Propagate {
+		if key == "" {
+			problems = append(problems, "propagate: keys must not be empty")
+			continue
+		}
+		name := strings.TrimSuffix(key, "*")
+		if name == "" {
+			// '*' propagates every annotation but the reserved ones
+			continue
+		}
+		if isReservedPVCAnnotation(name) {
+			problems = append(problems, fmt.Sprintf("propagate %q: annotation is reserved", key))
+			continue
+		}
+		if name != key {
+			// a prefix is valid when completing it makes a qualified name
+			name += "x"
+		}
+		for _, msg := range validation.IsQualifiedName(strings.ToLower(name)) {
+			problems = append(problems, fmt.Sprintf("propagate %q: %s", key, msg))
+		}
+	}
+	keys := []string{}
+	for key := range annotations.Set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if isReservedPVCAnnotation(key) {
+			problems = append(problems, fmt.Sprintf("set %q: annotation is reserved", key))
+		}
+	}
+	errs := apivalidation.ValidateAnnotations(annotations.Set, field.NewPath("spec", "pvcAnnotations", "set"))
+	for _, err := range errs {
+		problems = append(problems, err.Error())
+	}
+	return problems
+}
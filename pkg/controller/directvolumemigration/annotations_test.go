@@ -0,0 +1,112 @@
+package directvolumemigration
+
+import (
+	"reflect"
+	"testing"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+)
+
+func Test_getDestinationPVCAnnotations(t *testing.T) {
+	source := map[string]string{
+		"backup.example.com/policy":               "daily",
+		"app.example.com/owner":                   "team-a",
+		"app.example.com/tier":                    "gold",
+		"internal.example.com/id":                 "42",
+		"pv.kubernetes.io/bind-completed":         "yes",
+		"volume.beta.kubernetes.io/storage-class": "gp2",
+	}
+	tests := []struct {
+		name        string
+		annotations *migapi.PVCAnnotations
+		want        map[string]string
+	}{
+		{
+			name: "when not set, should not annotate",
+			want: nil,
+		},
+		{
+			name: "when propagating keys and prefixes, should copy the allowed annotations only",
+			annotations: &migapi.PVCAnnotations{
+				Propagate: []string{"backup.example.com/policy", "app.example.com/*"},
+			},
+			want: map[string]string{
+				"backup.example.com/policy": "daily",
+				"app.example.com/owner":     "team-a",
+				"app.example.com/tier":      "gold",
+			},
+		},
+		{
+			name: "when propagating everything, should not copy the reserved annotations",
+			annotations: &migapi.PVCAnnotations{
+				Propagate: []string{"*"},
+			},
+			want: map[string]string{
+				"backup.example.com/policy": "daily",
+				"app.example.com/owner":     "team-a",
+				"app.example.com/tier":      "gold",
+				"internal.example.com/id":   "42",
+			},
+		},
+		{
+			name: "when set, should take precedence over the propagated annotations",
+			annotations: &migapi.PVCAnnotations{
+				Propagate: []string{"app.example.com/*"},
+				Set:       map[string]string{"app.example.com/tier": "silver", "cost-center": "storage"},
+			},
+			want: map[string]string{
+				"app.example.com/owner": "team-a",
+				"app.example.com/tier":  "silver",
+				"cost-center":           "storage",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getDestinationPVCAnnotations(tt.annotations, source); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getDestinationPVCAnnotations() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_getPVCAnnotationsProblems(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations *migapi.PVCAnnotations
+		want        int
+	}{
+		{name: "when not set, should be valid", want: 0},
+		{
+			name: "when valid, should be valid",
+			annotations: &migapi.PVCAnnotations{
+				Propagate: []string{"backup.example.com/policy", "app.example.com/*", "*"},
+				Set:       map[string]string{"cost-center": "storage"},
+			},
+			want: 0,
+		},
+		{
+			name: "when malformed, should report each key",
+			annotations: &migapi.PVCAnnotations{
+				Propagate: []string{"", "bad key", "app.example.com/a/b"},
+				Set:       map[string]string{"-bad": "value"},
+			},
+			want: 4,
+		},
+		{
+			name: "when reserved, should report each key",
+			annotations: &migapi.PVCAnnotations{
+				Propagate: []string{"pv.kubernetes.io/*"},
+				Set:       map[string]string{"volume.kubernetes.io/selected-node": "node-1"},
+			},
+			want: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getPVCAnnotationsProblems(tt.annotations); len(got) != tt.want {
+				t.Errorf("getPVCAnnotationsProblems() = %v, want %d problems", got, tt.want)
+			}
+		})
+	}
+}
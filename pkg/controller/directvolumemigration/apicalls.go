@@ -0,0 +1,153 @@
+package directvolumemigration
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	"github.com/konveyor/mig-controller/pkg/compat"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"k8s.io/apimachinery/pkg/runtime"
+	dapi "k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Clusters the API calls of a DVM are made against
+const (
+	HostCluster        = "host"
+	SourceCluster      = "source"
+	DestinationCluster = "destination"
+)
+
+var (
+	// 'phase', 'cluster' - [ host, source, destination ], 'verb', 'kind'
+	directVolumeMigrationAPICallCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cam_app_workload_direct_volume_migration_api_calls_total",
+		Help: "Count of the API calls made by the DirectVolumeMigration phases, counted when DVM_API_CALL_METRICS is set",
+	},
+		[]string{"phase", "cluster", "verb", "kind"},
+	)
+)
+
+// apiCallCounter counts the API calls made by a phase against a cluster.
+type apiCallCounter struct {
+	phase   string
+	cluster string
+}
+
+func newAPICallCounter(phase string, cluster string) apiCallCounter {
+	// the phase of a new DVM is empty
+	if phase == Created {
+		phase = "Created"
+	}
+	return apiCallCounter{phase: phase, cluster: cluster}
+}
+
+// count the API call, the kind is the Go type of the object, of its items for a list.
+func (c apiCallCounter) count(verb string, obj runtime.Object) {
+	kind := ""
+	if t := reflect.TypeOf(obj); t != nil {
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		kind = strings.TrimSuffix(t.Name(), "List")
+	}
+	directVolumeMigrationAPICallCounter.WithLabelValues(c.phase, c.cluster, verb, kind).Inc()
+}
+
+// countingClient a client counting the API calls it makes.
+type countingClient struct {
+	k8sclient.Client
+	counter apiCallCounter
+}
+
+func (c countingClient) Get(ctx context.Context, key k8sclient.ObjectKey, obj k8sclient.Object) error {
+	c.counter.count("get", obj)
+	return c.Client.Get(ctx, key, obj)
+}
+
+func (c countingClient) List(ctx context.Context, list k8sclient.ObjectList, opts ...k8sclient.ListOption) error {
+	c.counter.count("list", list)
+	return c.Client.List(ctx, list, opts...)
+}
+
+func (c countingClient) Create(ctx context.Context, obj k8sclient.Object, opts ...k8sclient.CreateOption) error {
+	c.counter.count("create", obj)
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func (c countingClient) Update(ctx context.Context, obj k8sclient.Object, opts ...k8sclient.UpdateOption) error {
+	c.counter.count("update", obj)
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func (c countingClient) Patch(ctx context.Context, obj k8sclient.Object, patch k8sclient.Patch, opts ...k8sclient.PatchOption) error {
+	c.counter.count("patch", obj)
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func (c countingClient) Delete(ctx context.Context, obj k8sclient.Object, opts ...k8sclient.DeleteOption) error {
+	c.counter.count("delete", obj)
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+func (c countingClient) DeleteAllOf(ctx context.Context, obj k8sclient.Object, opts ...k8sclient.DeleteAllOfOption) error {
+	c.counter.count("deletecollection", obj)
+	return c.Client.DeleteAllOf(ctx, obj, opts...)
+}
+
+func (c countingClient) Status() k8sclient.StatusWriter {
+	return countingStatusWriter{StatusWriter: c.Client.Status(), counter: c.counter}
+}
+
+// countingStatusWriter a status writer counting the API calls it makes.
+type countingStatusWriter struct {
+	k8sclient.StatusWriter
+	counter apiCallCounter
+}
+
+func (w countingStatusWriter) Update(ctx context.Context, obj k8sclient.Object, opts ...k8sclient.UpdateOption) error {
+	w.counter.count("update", obj)
+	return w.StatusWriter.Update(ctx, obj, opts...)
+}
+
+func (w countingStatusWriter) Patch(ctx context.Context, obj k8sclient.Object, patch k8sclient.Patch, opts ...k8sclient.PatchOption) error {
+	w.counter.count("patch", obj)
+	return w.StatusWriter.Patch(ctx, obj, patch, opts...)
+}
+
+// countingCompatClient a remote cluster client counting the API calls it makes,
+// the discovery calls are not counted.
+type countingCompatClient struct {
+	countingClient
+	dapi.DiscoveryInterface
+	client compat.Client
+}
+
+func (c countingCompatClient) RestConfig() *rest.Config {
+	return c.client.RestConfig()
+}
+
+func (c countingCompatClient) MajorVersion() int {
+	return c.client.MajorVersion()
+}
+
+func (c countingCompatClient) MinorVersion() int {
+	return c.client.MinorVersion()
+}
+
+// Get a client of the host cluster counting the API calls made by the phase.
+func countHostAPICalls(client k8sclient.Client, phase string) k8sclient.Client {
+	return countingClient{Client: client, counter: newAPICallCounter(phase, HostCluster)}
+}
+
+// Get a client of a remote cluster counting the API calls made by the phase.
+func countRemoteAPICalls(client compat.Client, phase string, cluster string) compat.Client {
+	return countingCompatClient{
+		countingClient:     countingClient{Client: client, counter: newAPICallCounter(phase, cluster)},
+		DiscoveryInterface: client,
+		client:             client,
+	}
+}
@@ -0,0 +1,37 @@
+package directvolumemigration
+
+import (
+	"context"
+	"testing"
+
+	fakecompat "github.com/konveyor/mig-controller/pkg/compat/fake"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func Test_countRemoteAPICalls(t *testing.T) {
+	pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "src"}}
+	client := countRemoteAPICalls(fakecompat.NewFakeClient(pvc), WaitForDestinationPVCsBound, SourceCluster)
+	counter := func(verb string) float64 {
+		return testutil.ToFloat64(directVolumeMigrationAPICallCounter.WithLabelValues(
+			WaitForDestinationPVCsBound, SourceCluster, verb, "PersistentVolumeClaim"))
+	}
+	gets, lists := counter("get"), counter("list")
+	err := client.Get(context.TODO(), types.NamespacedName{Namespace: "src", Name: "data"}, &corev1.PersistentVolumeClaim{})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	err = client.List(context.TODO(), &corev1.PersistentVolumeClaimList{}, k8sclient.InNamespace("src"))
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if got := counter("get") - gets; got != 1 {
+		t.Errorf("get calls = %v, want 1", got)
+	}
+	if got := counter("list") - lists; got != 1 {
+		t.Errorf("list calls = %v, want 1", got)
+	}
+}
@@ -0,0 +1,93 @@
+package directvolumemigration
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"time"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"github.com/konveyor/mig-controller/pkg/compat"
+	migevent "github.com/konveyor/mig-controller/pkg/event"
+	corev1 "k8s.io/api/core/v1"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// SourceVolumeAttachTimeLimit duration a pending Rsync client Pod may fail
+// attaching or mounting its source volume before its transfer fails. Attach
+// and mount errors are retried by the kubelet and may be transient.
+var SourceVolumeAttachTimeLimit = time.Duration(time.Minute * 5)
+
+// Reasons of the warning events of a Pod which volume cannot be attached or mounted.
+var sourceVolumeAttachFailureReasons = map[string]bool{
+	"FailedAttachVolume": true,
+	"FailedMount":        true,
+}
+
+// Get the attach or mount error of the source volume of a pending Rsync client
+// Pod from its warning events, with the PV bound to the source PVC, once the
+// Pod is pending for longer than SourceVolumeAttachTimeLimit. Empty when the
+// volume isn't failing.
+func (t *Task) getSourceVolumeAttachError(client compat.Client, pod *corev1.Pod, pvcName string) (string, error) {
+	if pod.Status.Phase != corev1.PodPending ||
+		time.Since(pod.CreationTimestamp.Time) < SourceVolumeAttachTimeLimit {
+		return "", nil
+	}
+	events, err := migevent.GetAbnormalEventsForResource(client,
+		types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}, pod.UID)
+	if err != nil {
+		return "", err
+	}
+	failures := []string{}
+	for _, event := range events {
+		if sourceVolumeAttachFailureReasons[event.Reason] {
+			failures = append(failures, fmt.Sprintf("%s: %s", event.Reason, event.Message))
+		}
+	}
+	sort.Strings(failures)
+	if len(failures) == 0 {
+		return "", nil
+	}
+	pvc := corev1.PersistentVolumeClaim{}
+	err = client.Get(context.TODO(), types.NamespacedName{Namespace: pod.Namespace, Name: pvcName}, &pvc)
+	if k8serror.IsNotFound(err) || (err == nil && pvc.Spec.VolumeName == "") {
+		return failures[0], nil
+	} else if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("PV %s: %s", pvc.Spec.VolumeName, failures[0]), nil
+}
+
+// Report the source volumes which could not be attached or mounted in their
+// Rsync client Pod. Operations complete over several reconciles, the volumes
+// reported earlier are kept.
+func (t *Task) setSourceVolumeAttachFailed(status rsyncClientOperationStatusList) {
+	failures := []string{}
+	if existing := t.Owner.Status.FindCondition(SourceVolumeAttachFailed); existing != nil {
+		failures = append(failures, existing.Items...)
+	}
+	reported := len(failures)
+	for _, op := range status.ops {
+		if op.attachError == "" || op.operation == nil {
+			continue
+		}
+		ns, name := op.operation.GetPVDetails()
+		failures = append(failures, fmt.Sprintf("%s: %s", path.Join(ns, name), op.attachError))
+	}
+	if len(failures) == reported {
+		return
+	}
+	t.Log.Info("Source volumes could not be attached or mounted in the Rsync client Pods.",
+		"persistentVolumeClaims", failures)
+	t.Owner.Status.SetCondition(migapi.Condition{
+		Type:     SourceVolumeAttachFailed,
+		Status:   True,
+		Reason:   AttachFailed,
+		Category: Warn,
+		Message:  SourceVolumeAttachFailedMessage,
+		Items:    failures,
+		Durable:  true,
+	})
+}
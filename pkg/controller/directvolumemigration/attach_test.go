@@ -0,0 +1,73 @@
+package directvolumemigration
+
+import (
+	"testing"
+	"time"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	fakecompat "github.com/konveyor/mig-controller/pkg/compat/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTask_getSourceVolumeAttachError(t *testing.T) {
+	tests := []struct {
+		name    string
+		phase   corev1.PodPhase
+		created time.Time
+	}{
+		{
+			name:    "when the Pod is pending within the time limit, should not fail",
+			phase:   corev1.PodPending,
+			created: time.Now(),
+		},
+		{
+			name:    "when the Pod is running, should not fail",
+			phase:   corev1.PodRunning,
+			created: time.Now().Add(-2 * SourceVolumeAttachTimeLimit),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &Task{
+				Log:   log.WithName("test-logger"),
+				Owner: &migapi.DirectVolumeMigration{},
+			}
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "rsync", Namespace: "ns", CreationTimestamp: metav1.NewTime(tt.created)},
+				Status:     corev1.PodStatus{Phase: tt.phase},
+			}
+			got, err := task.getSourceVolumeAttachError(fakecompat.NewFakeClient(), pod, "pvc-1")
+			if err != nil || got != "" {
+				t.Errorf("Task.getSourceVolumeAttachError() = %v, %v, want no attach error", got, err)
+			}
+		})
+	}
+}
+
+func TestTask_setSourceVolumeAttachFailed(t *testing.T) {
+	task := &Task{
+		Log:   log.WithName("test-logger"),
+		Owner: &migapi.DirectVolumeMigration{},
+	}
+	newStatus := func(name string, attachError string) rsyncClientOperationStatusList {
+		status := rsyncClientOperationStatusList{}
+		status.Add(rsyncClientOperationStatus{
+			operation:   &migapi.RsyncOperation{PVCReference: &corev1.ObjectReference{Namespace: "ns", Name: name}},
+			failed:      attachError != "",
+			attachError: attachError,
+		})
+		return status
+	}
+	task.setSourceVolumeAttachFailed(newStatus("pvc-1", ""))
+	if task.Owner.Status.HasCondition(SourceVolumeAttachFailed) {
+		t.Errorf("Task.setSourceVolumeAttachFailed() set the condition without attach errors")
+	}
+	task.setSourceVolumeAttachFailed(newStatus("pvc-1", "PV pv-1: FailedMount: volume not found"))
+	task.setSourceVolumeAttachFailed(newStatus("pvc-2", "PV pv-2: FailedAttachVolume: disk not found"))
+	condition := task.Owner.Status.FindCondition(SourceVolumeAttachFailed)
+	want := []string{"ns/pvc-1: PV pv-1: FailedMount: volume not found", "ns/pvc-2: PV pv-2: FailedAttachVolume: disk not found"}
+	if condition == nil || len(condition.Items) != len(want) || condition.Items[0] != want[0] || condition.Items[1] != want[1] {
+		t.Errorf("Task.setSourceVolumeAttachFailed() condition = %v, want items %v", condition, want)
+	}
+}
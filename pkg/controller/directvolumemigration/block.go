@@ -0,0 +1,73 @@
+package directvolumemigration
+
+import (
+	"context"
+	"path"
+
+	"github.com/konveyor/mig-controller/pkg/compat"
+	corev1 "k8s.io/api/core/v1"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// BlockDeviceFile name of the raw block device of a block mode PVC in the Rsync
+// Pods. Rsync transfers the content of the device as the content of this file.
+const BlockDeviceFile = "block"
+
+// Get whether the PVC is a raw block volume.
+func isBlockPVC(pvc *corev1.PersistentVolumeClaim) bool {
+	return pvc.Spec.VolumeMode != nil && *pvc.Spec.VolumeMode == corev1.PersistentVolumeBlock
+}
+
+// Get the block mode PVCs of a namespace.
+func getBlockPVCs(client compat.Client, namespace string) (map[string]bool, error) {
+	block := map[string]bool{}
+	pvcList := corev1.PersistentVolumeClaimList{}
+	err := client.List(context.TODO(), &pvcList, k8sclient.InNamespace(namespace))
+	if err != nil {
+		return nil, err
+	}
+	for i := range pvcList.Items {
+		if isBlockPVC(&pvcList.Items[i]) {
+			block[pvcList.Items[i].Name] = true
+		}
+	}
+	return block, nil
+}
+
+// Get the path of the raw block device of a PVC in the Rsync Pods. The device
+// is placed in the directory served by the Rsync module of the PVC.
+func getBlockDevicePath(namespace string, pvcHash string) string {
+	return path.Join("/mnt", namespace, pvcHash, BlockDeviceFile)
+}
+
+// Get the Rsync options transferring the content of a raw block device rather
+// than the device node. Rsync reads the source device as a regular file and
+// writes in place into the destination device, the metadata of the device
+// nodes are not transferred.
+func getRsyncBlockOptions() []string {
+	return []string{
+		"--copy-devices",
+		"--write-devices",
+		"--inplace",
+		"--no-perms",
+		"--no-owner",
+		"--no-group",
+		"--no-times",
+	}
+}
+
+// Get the Rsync options transferring only the changed blocks of a raw block
+// device. Rsync reads the destination device as the basis of the delta
+// algorithm, compares the checksums of its blocks with the ones of the source
+// device and only sends the blocks which differ, written in place by the block
+// options. A destination device holding no previous copy of the data gets all
+// the blocks, as a full copy would.
+func getRsyncBlockDeltaOptions(rsyncOptions []string) []string {
+	options := []string{}
+	for _, option := range rsyncOptions {
+		if option != "--whole-file" && option != "-W" {
+			options = append(options, option)
+		}
+	}
+	return append(options, "--no-whole-file")
+}
@@ -0,0 +1,183 @@
+package directvolumemigration
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	fakecompat "github.com/konveyor/mig-controller/pkg/compat/fake"
+	dvmp "github.com/konveyor/mig-controller/pkg/controller/directvolumemigrationprogress"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_getBlockPVCs(t *testing.T) {
+	block, filesystem := corev1.PersistentVolumeBlock, corev1.PersistentVolumeFilesystem
+	getPVC := func(name string, mode *corev1.PersistentVolumeMode) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns"},
+			Spec:       corev1.PersistentVolumeClaimSpec{VolumeMode: mode},
+		}
+	}
+	client := fakecompat.NewFakeClient(
+		getPVC("vm-disk", &block),
+		getPVC("data", &filesystem),
+		getPVC("default", nil),
+	)
+	got, err := getBlockPVCs(client, "ns")
+	if err != nil {
+		t.Fatalf("getBlockPVCs() unexpected error = %v", err)
+	}
+	if len(got) != 1 || !got["vm-disk"] {
+		t.Errorf("getBlockPVCs() = %v, want only the block mode PVC", got)
+	}
+}
+
+func Test_getRsyncClientPodTemplateBlock(t *testing.T) {
+	req := getRsyncClientPodRequirements("vm-disk", "ns-1")
+	req.pvInfo.pvcHash = getMD5Hash("vm-disk")
+	req.pvInfo.block = true
+	req.pvInfo.shards = 4
+	req.destIP = "10.0.0.1"
+	pod := req.getRsyncClientPodTemplate()
+	container := pod.Spec.Containers[0]
+	devicePath := getBlockDevicePath("ns-1", req.pvInfo.pvcHash)
+	if len(container.VolumeDevices) != 1 || container.VolumeDevices[0].DevicePath != devicePath {
+		t.Errorf("getRsyncClientPodTemplate() volumeDevices = %v, want the raw block device at %s", container.VolumeDevices, devicePath)
+	}
+	for _, mount := range container.VolumeMounts {
+		if mount.Name == req.pvInfo.pvcHash {
+			t.Errorf("getRsyncClientPodTemplate() must not mount a raw block volume, mounts = %v", container.VolumeMounts)
+		}
+	}
+	command := container.Command[2]
+	if !strings.Contains(command, devicePath+" rsync://root@10.0.0.1/"+req.pvInfo.pvcHash+"/") {
+		t.Errorf("getRsyncClientPodTemplate() must transfer the device as a file in the module, command = %s", command)
+	}
+	if strings.Contains(command, "shard-") {
+		t.Errorf("getRsyncClientPodTemplate() must not shard the transfer of a raw block device, command = %s", command)
+	}
+}
+
+func Test_getRsyncBlockOptionsContent(t *testing.T) {
+	if _, err := exec.LookPath("rsync"); err != nil {
+		t.Skip("rsync not found")
+	}
+	dir, err := ioutil.TempDir("", "dvm-block")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	// a device fixture, the destination device holds stale data of the same size
+	content := make([]byte, 4<<20)
+	rand.New(rand.NewSource(1)).Read(content[:1<<20])
+	rand.New(rand.NewSource(2)).Read(content[3<<20:])
+	stale := make([]byte, len(content))
+	rand.New(rand.NewSource(3)).Read(stale)
+	source, destination := filepath.Join(dir, "src", BlockDeviceFile), filepath.Join(dir, "dest", BlockDeviceFile)
+	for file, data := range map[string][]byte{source: content, destination: stale} {
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(file, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	args := append([]string{"--archive"}, getRsyncBlockOptions()...)
+	args = append(args, source, filepath.Dir(destination)+"/")
+	out, err := exec.Command("rsync", args...).CombinedOutput()
+	if err != nil && strings.Contains(string(out), "unknown option") {
+		t.Skipf("rsync does not support the block device options: %s", out)
+	}
+	if err != nil {
+		t.Fatalf("rsync failed: %v: %s", err, out)
+	}
+	got, err := ioutil.ReadFile(destination)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Errorf("rsync with the block device options did not transfer the content byte for byte")
+	}
+}
+
+func Test_getRsyncBlockDeltaOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []string
+		want    []string
+	}{
+		{
+			name:    "when whole files are transferred, should transfer the changed blocks instead",
+			options: []string{"--archive", "--whole-file", "--inplace"},
+			want:    []string{"--archive", "--inplace", "--no-whole-file"},
+		},
+		{
+			name:    "when rsync defaults are kept, should transfer the changed blocks",
+			options: []string{"--archive", "-W"},
+			want:    []string{"--archive", "--no-whole-file"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getRsyncBlockDeltaOptions(tt.options); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getRsyncBlockDeltaOptions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_getRsyncBlockDeltaOptions_transfer(t *testing.T) {
+	if _, err := exec.LookPath("rsync"); err != nil {
+		t.Skip("rsync not found")
+	}
+	dir, err := ioutil.TempDir("", "dvm-block-delta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	// the destination device holds a previous copy of the source device, 1MiB of which changed since
+	content := make([]byte, 8<<20)
+	rand.New(rand.NewSource(1)).Read(content)
+	previous := make([]byte, len(content))
+	copy(previous, content)
+	rand.New(rand.NewSource(2)).Read(previous[2<<20 : 3<<20])
+	source, destination := filepath.Join(dir, "src", BlockDeviceFile), filepath.Join(dir, "dest", BlockDeviceFile)
+	for file, data := range map[string][]byte{source: content, destination: previous} {
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(file, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	args := getRsyncBlockDeltaOptions(append([]string{"--archive", "--stats"}, getRsyncBlockOptions()...))
+	args = append(args, source, filepath.Dir(destination)+"/")
+	out, err := exec.Command("rsync", args...).CombinedOutput()
+	if err != nil && strings.Contains(string(out), "unknown option") {
+		t.Skipf("rsync does not support the block device options: %s", out)
+	}
+	if err != nil {
+		t.Fatalf("rsync failed: %v: %s", err, out)
+	}
+	got, err := ioutil.ReadFile(destination)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Errorf("rsync with the block delta options did not transfer the content byte for byte")
+	}
+	stats := dvmp.ParseRsyncStats(string(out))
+	if stats == nil {
+		t.Fatalf("rsync did not report its stats: %s", out)
+	}
+	if changed, ok := parseRsyncSize(stats.LiteralData); !ok || changed >= int64(len(content))/2 {
+		t.Errorf("rsync with the block delta options sent %s bytes of %d, want the changed blocks only", stats.LiteralData, len(content))
+	}
+}
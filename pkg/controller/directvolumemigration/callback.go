@@ -0,0 +1,219 @@
+package directvolumemigration
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	liberr "github.com/konveyor/controller/pkg/error"
+	"github.com/konveyor/controller/pkg/logging"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+)
+
+// Delivery of the progress events to the progress callback of a DVM
+const (
+	ProgressCallbackTimeout       = 10 * time.Second
+	ProgressCallbackRetries       = 3
+	ProgressCallbackRetryInterval = 5 * time.Second
+	// Secret key holding the HMAC key signing the events
+	ProgressCallbackHMACKey = "hmacKey"
+	// Header holding the hex encoded HMAC-SHA256 signature of the event body
+	ProgressCallbackSignatureHeader = "X-Migration-Signature"
+)
+
+// progressEvent event POSTed to the progress callback on phase transitions and progress
+type progressEvent struct {
+	Name             string    `json:"name"`
+	Namespace        string    `json:"namespace"`
+	Phase            string    `json:"phase"`
+	PhaseDescription string    `json:"phaseDescription,omitempty"`
+	Percentage       int       `json:"percentage"`
+	Timestamp        time.Time `json:"timestamp"`
+	// Sequence increases with each event of the DVM, events delivered late are
+	// told apart by their lower sequence
+	Sequence int64 `json:"sequence"`
+	// ObservedAt time the reported status of the DVM was observed
+	ObservedAt time.Time `json:"observedAt"`
+	// Summary transfer summary, sent once the migration completed, failed or was canceled
+	Summary *migapi.TransferSummary `json:"summary,omitempty"`
+}
+
+// getProgressPercentage returns the overall Rsync transfer progress of the DVM in percent.
+// Successful PVCs count as fully transferred. The progress of each PVC is weighted by
+// its used capacity when the used capacity of all PVCs is known.
+func getProgressPercentage(direct *migapi.DirectVolumeMigration) int {
+	total := len(direct.Spec.PersistentVolumeClaims)
+	if total == 0 {
+		return 0
+	}
+	weights := map[string]int64{}
+	for _, operation := range direct.Status.RsyncOperations {
+		if operation.UsedCapacity != nil && operation.UsedCapacity.Value() > 0 {
+			weights[operation.String()] = operation.UsedCapacity.Value()
+		}
+	}
+	weighted := len(weights) == total
+	getWeight := func(pod *migapi.PodProgress) int64 {
+		if !weighted || pod.PVCReference == nil {
+			return 1
+		}
+		return weights[path.Join(pod.PVCReference.Namespace, pod.PVCReference.Name)]
+	}
+	totalWeight := int64(total)
+	if weighted {
+		totalWeight = 0
+		for _, weight := range weights {
+			totalWeight += weight
+		}
+	}
+	sum := int64(0)
+	for _, pod := range direct.Status.SuccessfulPods {
+		sum += 100 * getWeight(pod)
+	}
+	for _, pods := range [][]*migapi.PodProgress{
+		direct.Status.RunningPods,
+		direct.Status.FailedPods,
+		direct.Status.PendingPods} {
+		for _, pod := range pods {
+			percent, err := strconv.Atoi(strings.TrimSuffix(pod.LastObservedProgressPercent, "%"))
+			if err == nil && percent > 0 && percent <= 100 {
+				sum += int64(percent) * getWeight(pod)
+			}
+		}
+	}
+	if totalWeight == 0 || sum > 100*totalWeight {
+		return 100
+	}
+	return int(sum / totalWeight)
+}
+
+// newProgressEvent returns the progress event reporting the status of the DVM when
+// its phase or progress changed since the given values, nil otherwise. The sequence
+// of the events is kept in the status of the DVM, the event must only be sent once
+// the status is updated.
+func newProgressEvent(direct *migapi.DirectVolumeMigration, phase string, percentage int) *progressEvent {
+	callback := direct.Spec.ProgressCallback
+	if callback == nil || callback.URL == "" {
+		return nil
+	}
+	event := &progressEvent{
+		Name:             direct.Name,
+		Namespace:        direct.Namespace,
+		Phase:            direct.Status.Phase,
+		PhaseDescription: direct.Status.PhaseDescription,
+		Percentage:       getProgressPercentage(direct),
+		ObservedAt:       time.Now().UTC(),
+		Summary:          direct.Status.TransferSummary,
+	}
+	if event.Phase == phase && event.Percentage == percentage {
+		return nil
+	}
+	direct.Status.ProgressEventSequence++
+	event.Sequence = direct.Status.ProgressEventSequence
+	return event
+}
+
+// notifyProgress sends the progress event to the progress callback of the DVM.
+// Events are delivered in the background, delivery failures are logged with the
+// log of the reconcile and never block the migration.
+func (r *ReconcileDirectVolumeMigration) notifyProgress(log *logging.Logger, direct *migapi.DirectVolumeMigration, event *progressEvent) {
+	if event == nil {
+		return
+	}
+	callback := direct.Spec.ProgressCallback
+	event.Timestamp = time.Now().UTC()
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Info("Failed to encode the progress event.", "error", err.Error())
+		return
+	}
+	signature := ""
+	if callback.SecretRef != nil {
+		key, err := r.getProgressCallbackKey(callback)
+		if err != nil {
+			log.Info("Failed to get the key signing the progress event, event not sent.", "error", err.Error())
+			return
+		}
+		signature = signProgressEvent(body, key)
+	}
+	timeout := ProgressCallbackTimeout
+	if callback.Timeout != nil && callback.Timeout.Duration > 0 {
+		timeout = callback.Timeout.Duration
+	}
+	go func() {
+		err := deliverProgressEvent(callback.URL, body, signature, timeout, ProgressCallbackRetries, ProgressCallbackRetryInterval)
+		if err != nil {
+			log.Info("Failed to deliver the progress event.",
+				"phase", event.Phase, "sequence", event.Sequence, "url", callback.URL, "error", err.Error())
+		}
+	}()
+}
+
+// getProgressCallbackKey returns the HMAC key of the progress callback
+func (r *ReconcileDirectVolumeMigration) getProgressCallbackKey(callback *migapi.ProgressCallback) ([]byte, error) {
+	secret, err := migapi.GetSecret(r, callback.SecretRef)
+	if err != nil {
+		return nil, liberr.Wrap(err)
+	}
+	if secret == nil {
+		return nil, liberr.Wrap(fmt.Errorf("secret %s/%s not found",
+			callback.SecretRef.Namespace, callback.SecretRef.Name))
+	}
+	key, found := secret.Data[ProgressCallbackHMACKey]
+	if !found || len(key) == 0 {
+		return nil, liberr.Wrap(fmt.Errorf("key %s not found in secret %s/%s",
+			ProgressCallbackHMACKey, secret.Namespace, secret.Name))
+	}
+	return key, nil
+}
+
+// signProgressEvent returns the hex encoded HMAC-SHA256 signature of the event body
+func signProgressEvent(body []byte, key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// deliverProgressEvent POSTs the event body to the URL, retrying failed attempts
+func deliverProgressEvent(url string, body []byte, signature string, timeout time.Duration, retries int, interval time.Duration) error {
+	client := &http.Client{Timeout: timeout}
+	var err error
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(interval)
+		}
+		err = postProgressEvent(client, url, body, signature)
+		if err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+func postProgressEvent(client *http.Client, url string, body []byte, signature string) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if signature != "" {
+		req.Header.Set(ProgressCallbackSignatureHeader, signature)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package directvolumemigration
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_getProgressPercentage(t *testing.T) {
+	tests := []struct {
+		name   string
+		direct *migapi.DirectVolumeMigration
+		want   int
+	}{
+		{
+			name:   "when there are no PVCs, should be 0",
+			direct: &migapi.DirectVolumeMigration{},
+			want:   0,
+		},
+		{
+			name: "when PVCs are partially transferred, should average their progress",
+			direct: &migapi.DirectVolumeMigration{
+				Spec: migapi.DirectVolumeMigrationSpec{
+					PersistentVolumeClaims: []migapi.PVCToMigrate{{}, {}, {}, {}},
+				},
+				Status: migapi.DirectVolumeMigrationStatus{
+					SuccessfulPods: []*migapi.PodProgress{{}},
+					RunningPods:    []*migapi.PodProgress{{LastObservedProgressPercent: "50%"}},
+					PendingPods:    []*migapi.PodProgress{{LastObservedProgressPercent: ""}},
+					FailedPods:     []*migapi.PodProgress{{LastObservedProgressPercent: "10%"}},
+				},
+			},
+			want: 40,
+		},
+		{
+			name: "when the used capacity of all PVCs is known, should weight their progress",
+			direct: &migapi.DirectVolumeMigration{
+				Spec: migapi.DirectVolumeMigrationSpec{
+					PersistentVolumeClaims: []migapi.PVCToMigrate{{}, {}},
+				},
+				Status: migapi.DirectVolumeMigrationStatus{
+					RsyncOperations: []*migapi.RsyncOperation{
+						{PVCReference: &corev1.ObjectReference{Namespace: "ns", Name: "pvc-0"}, UsedCapacity: resource.NewQuantity(300, resource.BinarySI)},
+						{PVCReference: &corev1.ObjectReference{Namespace: "ns", Name: "pvc-1"}, UsedCapacity: resource.NewQuantity(100, resource.BinarySI)},
+					},
+					SuccessfulPods: []*migapi.PodProgress{{PVCReference: &corev1.ObjectReference{Namespace: "ns", Name: "pvc-1"}}},
+					RunningPods: []*migapi.PodProgress{
+						{PVCReference: &corev1.ObjectReference{Namespace: "ns", Name: "pvc-0"}, LastObservedProgressPercent: "50%"},
+					},
+				},
+			},
+			want: 62,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getProgressPercentage(tt.direct); got != tt.want {
+				t.Errorf("getProgressPercentage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_deliverProgressEvent(t *testing.T) {
+	body := []byte(`{"name":"dvm","phase":"Completed","percentage":100}`)
+	signature := signProgressEvent(body, []byte("key"))
+	tests := []struct {
+		name         string
+		failuresLeft int
+		wantErr      bool
+	}{
+		{
+			name:         "when the endpoint accepts the event, should deliver it",
+			failuresLeft: 0,
+			wantErr:      false,
+		},
+		{
+			name:         "when the endpoint fails less times than the retries, should deliver it",
+			failuresLeft: 2,
+			wantErr:      false,
+		},
+		{
+			name:         "when the endpoint keeps failing, should return an error",
+			failuresLeft: 10,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failuresLeft := tt.failuresLeft
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got, _ := ioutil.ReadAll(r.Body)
+				if string(got) != string(body) || r.Header.Get(ProgressCallbackSignatureHeader) != signature {
+					t.Errorf("deliverProgressEvent() sent body %s signature %s", got, r.Header.Get(ProgressCallbackSignatureHeader))
+				}
+				if failuresLeft > 0 {
+					failuresLeft--
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+			err := deliverProgressEvent(server.URL, body, signature, time.Second, 2, time.Millisecond)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("deliverProgressEvent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_newProgressEvent(t *testing.T) {
+	direct := &migapi.DirectVolumeMigration{
+		ObjectMeta: metav1.ObjectMeta{Name: "dvm", Namespace: migapi.OpenshiftMigrationNamespace},
+		Spec: migapi.DirectVolumeMigrationSpec{
+			ProgressCallback: &migapi.ProgressCallback{URL: "https://callback.example.com"},
+		},
+		Status: migapi.DirectVolumeMigrationStatus{Phase: CreateRsyncRoute, ProgressEventSequence: 4},
+	}
+	if event := newProgressEvent(direct, CreateRsyncRoute, 0); event != nil {
+		t.Errorf("newProgressEvent() = %v, want no event when neither the phase nor the progress changed", event)
+	}
+	event := newProgressEvent(direct, Started, 0)
+	if event == nil || event.Sequence != 5 || event.ObservedAt.IsZero() {
+		t.Fatalf("newProgressEvent() = %v, want an event with the sequence 5", event)
+	}
+	direct.Status.Phase = RunRsyncOperations
+	if next := newProgressEvent(direct, CreateRsyncRoute, 0); next == nil || next.Sequence <= event.Sequence {
+		t.Errorf("newProgressEvent() = %v, want a sequence greater than %d", next, event.Sequence)
+	}
+	if direct.Status.ProgressEventSequence != 6 {
+		t.Errorf("newProgressEvent() kept the sequence %d in the status, want 6", direct.Status.ProgressEventSequence)
+	}
+	direct.Spec.ProgressCallback = nil
+	if event := newProgressEvent(direct, Started, 0); event != nil {
+		t.Errorf("newProgressEvent() = %v, want no event without a progress callback", event)
+	}
+}
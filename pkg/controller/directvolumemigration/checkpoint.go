@@ -0,0 +1,127 @@
+package directvolumemigration
+
+import (
+	"context"
+	"path"
+	"time"
+
+	liberr "github.com/konveyor/controller/pkg/error"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Annotations of the checkpoint recorded on a destination PVC
+const (
+	// CheckpointAnnotation time the data of the destination PVC was transferred, RFC3339
+	CheckpointAnnotation = "migration.openshift.io/dvm-checkpoint"
+	// CheckpointDVMAnnotation namespace/name of the DVM which recorded the checkpoint
+	CheckpointDVMAnnotation = "migration.openshift.io/dvm-checkpoint-dvm"
+)
+
+// Get whether the migration records a checkpoint on the destination PVCs once
+// transferred, a delta transfer records the checkpoint of the next one.
+func (t *Task) recordsCheckpoints() bool {
+	return t.Owner.Spec.Checkpoint || t.Owner.Spec.DeltaSinceCheckpoint
+}
+
+// getCheckpoint parses the checkpoint recorded on a destination PVC. Returns
+// nil when the PVC holds no valid checkpoint.
+func getCheckpoint(pvc *corev1.PersistentVolumeClaim) *metav1.Time {
+	value, found := pvc.Annotations[CheckpointAnnotation]
+	if !found {
+		return nil
+	}
+	checkpoint, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil
+	}
+	return &metav1.Time{Time: checkpoint}
+}
+
+// Set the checkpoint held by the destination PVC the delta of a PVC is
+// transferred onto, which must exist and hold a checkpoint. The checkpoint is
+// reported, the files transferred are selected by Rsync comparing the source
+// and destination files.
+func (t *Task) setBaseCheckpoint(destClient k8sclient.Client, pvc migapi.PVCToMigrate, destNs string) error {
+	name := path.Join(destNs, pvc.Name)
+	destPVC := corev1.PersistentVolumeClaim{}
+	err := destClient.Get(context.TODO(), types.NamespacedName{Namespace: destNs, Name: pvc.Name}, &destPVC)
+	if k8serror.IsNotFound(err) {
+		return &CheckpointMissingError{PVC: name, Reason: "the destination PVC does not exist"}
+	}
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	checkpoint := getCheckpoint(&destPVC)
+	if checkpoint == nil {
+		return &CheckpointMissingError{PVC: name, Reason: "the destination PVC holds no checkpoint"}
+	}
+	t.getPVCTransferState(pvc).BaseCheckpointTime = checkpoint
+	return nil
+}
+
+// Record a checkpoint on the destination PVCs which transfer succeeded, the
+// time their Rsync operation completed. The checkpoints already recorded by
+// the migration are kept.
+func (t *Task) recordCheckpoints() error {
+	if !t.recordsCheckpoints() {
+		return nil
+	}
+	destClient, err := t.getDestinationClient()
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	return t.writeCheckpoints(destClient)
+}
+
+// Write the checkpoints on the destination PVCs with the client of the destination cluster.
+func (t *Task) writeCheckpoints(destClient k8sclient.Client) error {
+	operations := map[string]*migapi.RsyncOperation{}
+	for _, operation := range t.Owner.Status.RsyncOperations {
+		if operation.PVCReference != nil {
+			operations[operation.String()] = operation
+		}
+	}
+	for _, pvc := range t.Owner.Spec.PersistentVolumeClaims {
+		operation := operations[path.Join(pvc.Namespace, pvc.Name)]
+		state := t.getPVCTransferState(pvc)
+		if operation == nil || !operation.Succeeded || state.CheckpointTime != nil {
+			continue
+		}
+		checkpoint := metav1.Now()
+		if operation.CompletionTimestamp != nil {
+			checkpoint = *operation.CompletionTimestamp
+		}
+		checkpoint = metav1.NewTime(checkpoint.UTC().Truncate(time.Second))
+		destNs := pvc.Namespace
+		if pvc.TargetNamespace != "" {
+			destNs = pvc.TargetNamespace
+		}
+		destPVC := corev1.PersistentVolumeClaim{}
+		err := destClient.Get(context.TODO(), types.NamespacedName{Namespace: destNs, Name: pvc.Name}, &destPVC)
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		if destPVC.Annotations == nil {
+			destPVC.Annotations = map[string]string{}
+		}
+		destPVC.Annotations[CheckpointAnnotation] = checkpoint.Format(time.RFC3339)
+		destPVC.Annotations[CheckpointDVMAnnotation] = path.Join(t.Owner.Namespace, t.Owner.Name)
+		err = destClient.Update(context.TODO(), &destPVC)
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		t.Log.Info("Recorded checkpoint on destination PVC.",
+			"destPersistentVolumeClaim", path.Join(destNs, pvc.Name),
+			"checkpoint", checkpoint.Format(time.RFC3339))
+		state.CheckpointTime = &checkpoint
+		if last := t.Owner.Status.LastCheckpointTime; last == nil || last.Before(&checkpoint) {
+			t.Owner.Status.LastCheckpointTime = &checkpoint
+		}
+	}
+	return nil
+}
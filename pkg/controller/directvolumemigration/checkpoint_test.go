@@ -0,0 +1,106 @@
+package directvolumemigration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestTask_writeCheckpoints(t *testing.T) {
+	pvc := migapi.PVCToMigrate{ObjectReference: &corev1.ObjectReference{Name: "data", Namespace: "ns"}}
+	pending := migapi.PVCToMigrate{ObjectReference: &corev1.ObjectReference{Name: "logs", Namespace: "ns"}}
+	completed := metav1.NewTime(time.Date(2021, 6, 2, 10, 42, 17, 500, time.UTC))
+	client := fake.NewFakeClient(
+		getDestinationPVC("10Gi", "gp2", corev1.ReadWriteOnce),
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "logs", Namespace: "ns"}})
+	task := &Task{
+		Log: log.WithName("test-logger"),
+		Owner: &migapi.DirectVolumeMigration{
+			ObjectMeta: metav1.ObjectMeta{Name: "dvm", Namespace: migapi.OpenshiftMigrationNamespace},
+			Spec: migapi.DirectVolumeMigrationSpec{
+				Checkpoint:             true,
+				PersistentVolumeClaims: []migapi.PVCToMigrate{pvc, pending},
+			},
+			Status: migapi.DirectVolumeMigrationStatus{
+				RsyncOperations: []*migapi.RsyncOperation{
+					{PVCReference: pvc.ObjectReference, Succeeded: true, CompletionTimestamp: &completed},
+					{PVCReference: pending.ObjectReference},
+				},
+			},
+		},
+	}
+	if err := task.writeCheckpoints(client); err != nil {
+		t.Fatalf("Task.writeCheckpoints() unexpected error = %v", err)
+	}
+	want := metav1.NewTime(completed.Truncate(time.Second))
+	if got := task.getPVCTransferState(pvc).CheckpointTime; got == nil || !got.Equal(&want) {
+		t.Errorf("Task.writeCheckpoints() checkpointTime = %v, want %v", got, want)
+	}
+	if got := task.Owner.Status.LastCheckpointTime; got == nil || !got.Equal(&want) {
+		t.Errorf("Task.writeCheckpoints() lastCheckpointTime = %v, want %v", got, want)
+	}
+	if got := task.getPVCTransferState(pending).CheckpointTime; got != nil {
+		t.Errorf("Task.writeCheckpoints() recorded checkpoint %v of pending PVC", got)
+	}
+	destPVC := corev1.PersistentVolumeClaim{}
+	err := client.Get(context.TODO(), types.NamespacedName{Namespace: "ns", Name: "data"}, &destPVC)
+	if err != nil {
+		t.Fatalf("Get() unexpected error = %v", err)
+	}
+	if got := destPVC.Annotations[CheckpointAnnotation]; got != "2021-06-02T10:42:17Z" {
+		t.Errorf("Task.writeCheckpoints() annotation = %v, want %v", got, "2021-06-02T10:42:17Z")
+	}
+	if got := destPVC.Annotations[CheckpointDVMAnnotation]; got != migapi.OpenshiftMigrationNamespace+"/dvm" {
+		t.Errorf("Task.writeCheckpoints() dvm annotation = %v", got)
+	}
+}
+
+func TestTask_setBaseCheckpoint(t *testing.T) {
+	pvc := migapi.PVCToMigrate{ObjectReference: &corev1.ObjectReference{Name: "data", Namespace: "ns"}}
+	checkpointed := getDestinationPVC("10Gi", "gp2", corev1.ReadWriteOnce)
+	checkpointed.Annotations = map[string]string{CheckpointAnnotation: "2021-06-02T10:42:17Z"}
+	tests := []struct {
+		name     string
+		existing *corev1.PersistentVolumeClaim
+		wantErr  bool
+	}{
+		{
+			name:     "when the destination PVC holds a checkpoint, should set it",
+			existing: checkpointed,
+		},
+		{
+			name:     "when the destination PVC holds no checkpoint, should fail",
+			existing: getDestinationPVC("10Gi", "gp2", corev1.ReadWriteOnce),
+			wantErr:  true,
+		},
+		{
+			name:    "when the destination PVC is missing, should fail",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewFakeClient()
+			if tt.existing != nil {
+				client = fake.NewFakeClient(tt.existing.DeepCopy())
+			}
+			task := &Task{
+				Log:   log.WithName("test-logger"),
+				Owner: &migapi.DirectVolumeMigration{},
+			}
+			err := task.setBaseCheckpoint(client, pvc, "ns")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Task.setBaseCheckpoint() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := task.getPVCTransferState(pvc).BaseCheckpointTime; (got != nil) == tt.wantErr {
+				t.Errorf("Task.setBaseCheckpoint() baseCheckpointTime = %v, wantErr %v", got, tt.wantErr)
+			}
+		})
+	}
+}
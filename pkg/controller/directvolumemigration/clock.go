@@ -0,0 +1,107 @@
+package directvolumemigration
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	liberr "github.com/konveyor/controller/pkg/error"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+// ClockSkewThreshold clock skew between the source and destination clusters
+// past which the Rsync transfer compares the files by checksum.
+const ClockSkewThreshold = 10 * time.Second
+
+// ClockProbeTimeout timeout of the request reading the clock of a cluster.
+const ClockProbeTimeout = 10 * time.Second
+
+// Get the offset of the clock of the API server from the clock of the
+// controller, read from the Date header of a request to the /version endpoint.
+// The header has a resolution of a second, the offset is measured from the
+// middle of both the second and the round trip of the request.
+func getClusterClockOffset(restConfig *rest.Config) (time.Duration, error) {
+	transport, err := rest.TransportFor(restConfig)
+	if err != nil {
+		return 0, liberr.Wrap(err)
+	}
+	client := &http.Client{Transport: transport, Timeout: ClockProbeTimeout}
+	start := time.Now()
+	res, err := client.Get(strings.TrimSuffix(restConfig.Host, "/") + "/version")
+	if err != nil {
+		return 0, liberr.Wrap(err)
+	}
+	defer res.Body.Close()
+	end := time.Now()
+	date, err := http.ParseTime(res.Header.Get("Date"))
+	if err != nil {
+		return 0, liberr.Wrap(fmt.Errorf("cannot parse the Date header of the API server: %v", err))
+	}
+	local := start.Add(end.Sub(start) / 2)
+	return date.Add(500 * time.Millisecond).Sub(local), nil
+}
+
+// Get the offset of the clock of the MigCluster from the clock of the controller.
+func (t *Task) getMigClusterClockOffset(cluster *migapi.MigCluster) (time.Duration, error) {
+	restConfig, err := cluster.BuildRestConfig(t.Client)
+	if err != nil {
+		return 0, liberr.Wrap(err)
+	}
+	return getClusterClockOffset(restConfig)
+}
+
+// Measure the clock skew between the source and destination clusters and warn
+// with the durable ClockSkewDetected condition when it exceeds the threshold.
+// The Rsync transfer then compares the files by checksum rather than by size
+// and modification time. A cluster which clock can't be read is not reported,
+// the migration proceeds.
+func (t *Task) checkClockSkew() error {
+	srcCluster, err := t.Owner.GetSourceCluster(t.Client)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	destCluster, err := t.Owner.GetDestinationCluster(t.Client)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	if srcCluster == nil || destCluster == nil {
+		return nil
+	}
+	srcOffset, err := t.getMigClusterClockOffset(srcCluster)
+	if err != nil {
+		t.Log.Info("Cannot read the clock of the source cluster, skipping the clock skew check.", "error", err.Error())
+		return nil
+	}
+	destOffset, err := t.getMigClusterClockOffset(destCluster)
+	if err != nil {
+		t.Log.Info("Cannot read the clock of the destination cluster, skipping the clock skew check.", "error", err.Error())
+		return nil
+	}
+	skew := (destOffset - srcOffset).Round(time.Second)
+	if skew < 0 {
+		skew = -skew
+	}
+	t.Owner.Status.ClockSkew = &metav1.Duration{Duration: skew}
+	if skew <= ClockSkewThreshold {
+		return nil
+	}
+	t.Log.Info("Clock skew between the source and destination clusters exceeds the threshold, Rsync will compare files by checksum.",
+		"skew", skew, "threshold", ClockSkewThreshold)
+	t.Owner.Status.SetCondition(migapi.Condition{
+		Type:     ClockSkewDetected,
+		Status:   True,
+		Reason:   Skewed,
+		Category: Warn,
+		Message:  fmt.Sprintf(ClockSkewDetectedMessage, skew, ClockSkewThreshold),
+		Durable:  true,
+	})
+	return nil
+}
+
+// Get whether clock skew was detected between the source and destination clusters.
+func (t *Task) hasClockSkew() bool {
+	return t.Owner.Status.HasCondition(ClockSkewDetected)
+}
@@ -0,0 +1,40 @@
+package directvolumemigration
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/rest"
+)
+
+func Test_getClusterClockOffset(t *testing.T) {
+	tests := []struct {
+		name   string
+		offset time.Duration
+	}{
+		{name: "when the clocks are synced, should measure no offset", offset: 0},
+		{name: "when the cluster clock is ahead, should measure a positive offset", offset: 2 * time.Minute},
+		{name: "when the cluster clock is behind, should measure a negative offset", offset: -time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Date", time.Now().Add(tt.offset).UTC().Format(http.TimeFormat))
+				if r.URL.Path != "/version" {
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+			got, err := getClusterClockOffset(&rest.Config{Host: server.URL + "/"})
+			if err != nil {
+				t.Fatalf("getClusterClockOffset() error = %v", err)
+			}
+			// the Date header has a resolution of a second
+			if diff := got - tt.offset; diff < -time.Second || diff > time.Second {
+				t.Errorf("getClusterClockOffset() = %v, want %v", got, tt.offset)
+			}
+		})
+	}
+}
@@ -0,0 +1,258 @@
+package directvolumemigration
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+
+	liberr "github.com/konveyor/controller/pkg/error"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"github.com/konveyor/mig-controller/pkg/compat"
+	ocappsv1 "github.com/openshift/api/apps/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Kinds of the configuration referenced by a Pod
+const (
+	ConfigMapReference = "ConfigMap"
+	SecretReference    = "Secret"
+)
+
+// A ConfigMap or a Secret referenced by a Pod.
+type configReference struct {
+	kind string
+	name string
+}
+
+// Get the ConfigMaps and Secrets the Pod requires to start, mounted as volumes,
+// projected or read by the environment of its containers. The optional
+// references and the image pull Secrets are not required.
+func getPodConfigReferences(spec *corev1.PodSpec) []configReference {
+	refs := []configReference{}
+	for _, volume := range spec.Volumes {
+		if cm := volume.ConfigMap; cm != nil && !isOptional(cm.Optional) {
+			refs = append(refs, configReference{kind: ConfigMapReference, name: cm.Name})
+		}
+		if secret := volume.Secret; secret != nil && !isOptional(secret.Optional) {
+			refs = append(refs, configReference{kind: SecretReference, name: secret.SecretName})
+		}
+		if volume.Projected == nil {
+			continue
+		}
+		for _, source := range volume.Projected.Sources {
+			if cm := source.ConfigMap; cm != nil && !isOptional(cm.Optional) {
+				refs = append(refs, configReference{kind: ConfigMapReference, name: cm.Name})
+			}
+			if secret := source.Secret; secret != nil && !isOptional(secret.Optional) {
+				refs = append(refs, configReference{kind: SecretReference, name: secret.Name})
+			}
+		}
+	}
+	containers := append([]corev1.Container{}, spec.InitContainers...)
+	containers = append(containers, spec.Containers...)
+	for _, container := range containers {
+		for _, envFrom := range container.EnvFrom {
+			if cm := envFrom.ConfigMapRef; cm != nil && !isOptional(cm.Optional) {
+				refs = append(refs, configReference{kind: ConfigMapReference, name: cm.Name})
+			}
+			if secret := envFrom.SecretRef; secret != nil && !isOptional(secret.Optional) {
+				refs = append(refs, configReference{kind: SecretReference, name: secret.Name})
+			}
+		}
+		for _, env := range container.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			if cm := env.ValueFrom.ConfigMapKeyRef; cm != nil && !isOptional(cm.Optional) {
+				refs = append(refs, configReference{kind: ConfigMapReference, name: cm.Name})
+			}
+			if secret := env.ValueFrom.SecretKeyRef; secret != nil && !isOptional(secret.Optional) {
+				refs = append(refs, configReference{kind: SecretReference, name: secret.Name})
+			}
+		}
+	}
+	return refs
+}
+
+func isOptional(optional *bool) bool {
+	return optional != nil && *optional
+}
+
+// Get whether the Pod template mounts one of the PVCs.
+func isMountingPVC(spec *corev1.PodSpec, pvcs map[string]bool) bool {
+	for _, volume := range spec.Volumes {
+		if volume.PersistentVolumeClaim != nil && pvcs[volume.PersistentVolumeClaim.ClaimName] {
+			return true
+		}
+	}
+	return false
+}
+
+// Get the Pod specs of the source namespace which mount one of the PVCs, keyed
+// by the Pods and workloads they belong to. The Pod templates of the workloads
+// are included as their Pods may be quiesced, the Pods created by the migration
+// are not.
+func getSourcePodSpecsMountingPVCs(srcClient compat.Client, ns string, pvcs map[string]bool) (map[string]*corev1.PodSpec, error) {
+	specs := map[string]*corev1.PodSpec{}
+	podList := corev1.PodList{}
+	err := srcClient.List(context.TODO(), &podList, k8sclient.InNamespace(ns))
+	if err != nil {
+		return nil, liberr.Wrap(err)
+	}
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if pod.Labels["app"] == DirectVolumeMigrationRsyncTransfer || pod.Labels[migapi.StagePodLabel] == migapi.True {
+			continue
+		}
+		if isMountingPVC(&pod.Spec, pvcs) {
+			specs[path.Join(ns, "Pod", pod.Name)] = &pod.Spec
+		}
+	}
+	deploymentList := appsv1.DeploymentList{}
+	err = srcClient.List(context.TODO(), &deploymentList, k8sclient.InNamespace(ns))
+	if err != nil {
+		return nil, liberr.Wrap(err)
+	}
+	for i := range deploymentList.Items {
+		deployment := &deploymentList.Items[i]
+		if isMountingPVC(&deployment.Spec.Template.Spec, pvcs) {
+			specs[path.Join(ns, "Deployment", deployment.Name)] = &deployment.Spec.Template.Spec
+		}
+	}
+	statefulSetList := appsv1.StatefulSetList{}
+	err = srcClient.List(context.TODO(), &statefulSetList, k8sclient.InNamespace(ns))
+	if err != nil {
+		return nil, liberr.Wrap(err)
+	}
+	for i := range statefulSetList.Items {
+		statefulSet := &statefulSetList.Items[i]
+		if isMountingPVC(&statefulSet.Spec.Template.Spec, pvcs) {
+			specs[path.Join(ns, "StatefulSet", statefulSet.Name)] = &statefulSet.Spec.Template.Spec
+		}
+	}
+	// DeploymentConfigs only exist on OpenShift
+	dcList := ocappsv1.DeploymentConfigList{}
+	err = srcClient.List(context.TODO(), &dcList, k8sclient.InNamespace(ns))
+	if err != nil && !meta.IsNoMatchError(err) && !runtime.IsNotRegisteredError(err) {
+		return nil, liberr.Wrap(err)
+	}
+	for i := range dcList.Items {
+		dc := &dcList.Items[i]
+		if dc.Spec.Template != nil && isMountingPVC(&dc.Spec.Template.Spec, pvcs) {
+			specs[path.Join(ns, "DeploymentConfig", dc.Name)] = &dc.Spec.Template.Spec
+		}
+	}
+	return specs, nil
+}
+
+// Get whether the configuration exists in the namespace, and whether it is a
+// Secret holding a service account token, created by each cluster.
+func getConfigReference(client compat.Client, ns string, ref configReference) (bool, bool, error) {
+	key := types.NamespacedName{Namespace: ns, Name: ref.name}
+	var err error
+	serviceAccountToken := false
+	if ref.kind == ConfigMapReference {
+		err = client.Get(context.TODO(), key, &corev1.ConfigMap{})
+	} else {
+		secret := corev1.Secret{}
+		err = client.Get(context.TODO(), key, &secret)
+		serviceAccountToken = secret.Type == corev1.SecretTypeServiceAccountToken
+	}
+	if k8serror.IsNotFound(err) {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, liberr.Wrap(err)
+	}
+	return true, serviceAccountToken, nil
+}
+
+// Get the ConfigMaps and Secrets referenced by the source Pods and workloads
+// mounting the migrated PVCs which don't exist in the destination namespaces,
+// as "<Kind> <destination namespace>/<name>: <namespace>/<kind>/<referrer>".
+// The service account token Secrets are created by the destination cluster.
+func (t *Task) getMissingDestinationConfigReferences(srcClient compat.Client, destClient compat.Client) ([]string, error) {
+	missing := []string{}
+	for bothNs, vols := range t.getPVCNamespaceMap() {
+		srcNs, destNs := getSourceNs(bothNs), getDestNs(bothNs)
+		pvcs := map[string]bool{}
+		for _, vol := range vols {
+			pvcs[vol.Name] = true
+		}
+		specs, err := getSourcePodSpecsMountingPVCs(srcClient, srcNs, pvcs)
+		if err != nil {
+			return nil, liberr.Wrap(err)
+		}
+		referrers := []string{}
+		for referrer := range specs {
+			referrers = append(referrers, referrer)
+		}
+		sort.Strings(referrers)
+		checked := map[configReference]bool{}
+		for _, referrer := range referrers {
+			for _, ref := range getPodConfigReferences(specs[referrer]) {
+				if checked[ref] {
+					continue
+				}
+				checked[ref] = true
+				found, _, err := getConfigReference(destClient, destNs, ref)
+				if err != nil {
+					return nil, liberr.Wrap(err)
+				}
+				if found {
+					continue
+				}
+				_, serviceAccountToken, err := getConfigReference(srcClient, srcNs, ref)
+				if err != nil {
+					return nil, liberr.Wrap(err)
+				}
+				if serviceAccountToken {
+					continue
+				}
+				missing = append(missing, fmt.Sprintf("%s %s/%s: %s", ref.kind, destNs, ref.name, referrer))
+			}
+		}
+	}
+	sort.Strings(missing)
+	return missing, nil
+}
+
+// Warn with the DestinationConfigMissing condition when the
+// ConfigMaps or Secrets the workloads of the migrated PVCs require don't exist
+// on the destination cluster, the workloads wouldn't start there.
+func (t *Task) setDestinationConfigMissing() error {
+	srcClient, err := t.getSourceClient()
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	destClient, err := t.getDestinationClient()
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	missing, err := t.getMissingDestinationConfigReferences(srcClient, destClient)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	t.Log.Info("ConfigMaps and Secrets referenced by the workloads of the PVCs are missing on the destination cluster.",
+		"missing", missing)
+	t.Owner.Status.SetCondition(migapi.Condition{
+		Type:     DestinationConfigMissing,
+		Status:   True,
+		Reason:   NotFound,
+		Category: Warn,
+		Message:  DestinationConfigMissingMessage,
+		Items:    missing,
+		Durable:  true,
+	})
+	return nil
+}
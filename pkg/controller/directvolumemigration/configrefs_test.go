@@ -0,0 +1,82 @@
+package directvolumemigration
+
+import (
+	"reflect"
+	"testing"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	fakecompat "github.com/konveyor/mig-controller/pkg/compat/fake"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTask_getMissingDestinationConfigReferences(t *testing.T) {
+	optional := true
+	podSpec := corev1.PodSpec{
+		Volumes: []corev1.Volume{
+			{Name: "data", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data"}}},
+			{Name: "config", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "app-config"}}}},
+			{Name: "cache", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "cache-config"}, Optional: &optional}}},
+			{Name: "token", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "default-token"}}},
+			{Name: "projected", VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{
+				Sources: []corev1.VolumeProjection{{Secret: &corev1.SecretProjection{LocalObjectReference: corev1.LocalObjectReference{Name: "tls"}}}},
+			}}},
+		},
+		Containers: []corev1.Container{
+			{
+				Name: "app",
+				Env: []corev1.EnvVar{
+					{Name: "PASSWORD", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "db-credentials"}, Key: "password"}}},
+				},
+			},
+		},
+	}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "src"},
+		Spec:       appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: podSpec}},
+	}
+	other := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "src"},
+		Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Volumes: []corev1.Volume{
+				{Name: "config", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "other-config"}}}},
+			},
+		}}},
+	}
+	rsyncPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "rsync", Namespace: "src", Labels: map[string]string{"app": DirectVolumeMigrationRsyncTransfer}},
+		Spec: corev1.PodSpec{
+			Volumes: []corev1.Volume{
+				{Name: "data", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data"}}},
+				{Name: "config", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "rsync-config"}}}},
+			},
+		},
+	}
+	token := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "default-token", Namespace: "src"},
+		Type:       corev1.SecretTypeServiceAccountToken,
+	}
+	task := &Task{
+		Owner: &migapi.DirectVolumeMigration{
+			Spec: migapi.DirectVolumeMigrationSpec{
+				PersistentVolumeClaims: []migapi.PVCToMigrate{
+					{ObjectReference: &corev1.ObjectReference{Name: "data", Namespace: "src"}, TargetNamespace: "dest"},
+				},
+			},
+		},
+	}
+	srcClient := fakecompat.NewFakeClient(deployment, other, rsyncPod, token)
+	destClient := fakecompat.NewFakeClient(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "app-config", Namespace: "dest"}})
+	got, err := task.getMissingDestinationConfigReferences(srcClient, destClient)
+	if err != nil {
+		t.Fatalf("Task.getMissingDestinationConfigReferences() error = %v", err)
+	}
+	want := []string{
+		"Secret dest/db-credentials: src/Deployment/app",
+		"Secret dest/tls: src/Deployment/app",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Task.getMissingDestinationConfigReferences() = %v, want %v", got, want)
+	}
+}
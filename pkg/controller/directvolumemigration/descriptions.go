@@ -26,19 +26,38 @@ var phaseDescriptions = map[string]string{
 	DestinationNamespacesCreated:         "Checking if the target namespaces have been created.",
 	CreateDestinationPVCs:                "Creating PVCs in the target namespaces",
 	DestinationPVCsCreated:               "Checking whether the created PVCs are bound",
+	WaitForDestinationPVCsBound:          "Waiting for the created PVCs to be bound",
+	EnsureDestinationPVCsExist:           "Checking that the PVCs to verify exist on the target cluster",
+	ExpandDestinationPVCs:                "Expanding the created PVCs smaller than the source data, if enabled",
+	PrewarmDestinationPVCs:               "Prewarming the storage of the created PVCs, if enabled",
 	CreateRsyncRoute:                     "Creating one route for each namespace for Rsync on the target cluster",
 	CreateRsyncConfig:                    "Creating a config map and secrets on both the source and target clusters for Rsync configuration",
 	CreateStunnelConfig:                  "Creating a config map and secrets for Stunnel to connect to Rsync on the source and target clusters",
 	CreatePVProgressCRs:                  "Creating a Direct Volume Migration Progress CR to get progress percentage and transfer rate",
+	EnsureDestinationPVCsReleased:        "Checking that no pod on the target cluster holds the ReadWriteOnce target PVCs",
 	CreateRsyncTransferPods:              "Creating Rsync daemon pods on the target cluster",
+	EnsureRsyncSecretsExist:              "Checking that the secrets to be mounted by the Rsync pods exist on both the source and target clusters",
 	WaitForRsyncTransferPodsRunning:      "Waiting for the Rsync daemon pod to run",
+	RunPreTransferHooks:                  "Running the PreTransfer hook, if any, before the volume transfer starts",
 	EnsureRsyncRouteAdmitted:             "Waiting for Rsync route to be admitted.",
 	CreateRsyncClientPods:                "Creating Rsync client pods",
 	WaitForRsyncClientPodsCompleted:      "Waiting for the Rsync client pods to be completed",
 	DeleteRsyncResources:                 "Deleting Rsync resources created by this migration",
 	WaitForRsyncResourcesTerminated:      "Waiting for Rsync resources to terminate",
+	DeleteHookJobs:                       "Deleting the hook Jobs run by this migration",
+	RunPostTransferHooks:                 "Running the PostTransfer hook, if any, after the volume transfer completed",
+	VerifyDestinationConfigReferences:    "Checking that the ConfigMaps and Secrets required by the workloads of the PVCs exist in the target namespaces, if requested",
+	RecordCheckpoints:                    "Recording a checkpoint on the transferred destination PVCs, if requested",
 	RunRsyncOperations:                   "Running Rsync Pods to migrate Persistent Volume data",
+	VerifyData:                           "Verifying the data of the PVCs verified by checksum once transferred, if requested",
+	CollectVerificationResults:           "Collecting the files differing between the source and target PVCs",
+	PrepareTransfer:                      "Waiting for the resources of the transfer engine to be ready",
+	RunTransfer:                          "Running the transfer engine to migrate Persistent Volume data",
+	VerifyTransfer:                       "Collecting the files differing between the source and target PVCs from the transfer engine",
+	PlanTransfer:                         "Resolving the transfer plan of the migration without executing it",
+	RunSpeedTest:                         "Transferring generated data to measure the throughput and latency between the clusters",
 	Verification:                         "Verifying migration was successful",
 	MigrationFailed:                      "The migration attempt failed, please see errors for more details",
 	Completed:                            "Complete",
+	Canceled:                             "The migration was canceled because the MigMigration owning it was deleted or requested it",
 }
@@ -18,13 +18,15 @@ package directvolumemigration
 
 import (
 	"context"
-	"time"
+	"sync"
 
 	"github.com/konveyor/controller/pkg/logging"
 	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"github.com/konveyor/mig-controller/pkg/settings"
 	"github.com/opentracing/opentracing-go"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -43,13 +45,16 @@ func Add(mgr manager.Manager) error {
 
 // newReconciler returns a new reconcile.Reconciler
 func newReconciler(mgr manager.Manager) reconcile.Reconciler {
-	return &ReconcileDirectVolumeMigration{Client: mgr.GetClient(), scheme: mgr.GetScheme()}
+	return &ReconcileDirectVolumeMigration{Client: mgr.GetClient(), scheme: mgr.GetScheme(), tracerOnce: &sync.Once{}}
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
 func add(mgr manager.Manager, r reconcile.Reconciler) error {
 	// Create a new controller
-	c, err := controller.New("directvolumemigration-controller", mgr, controller.Options{Reconciler: r})
+	c, err := controller.New("directvolumemigration-controller", mgr, controller.Options{
+		Reconciler:              r,
+		MaxConcurrentReconciles: settings.Settings.DvmOpts.MaxConcurrentReconciles,
+	})
 	if err != nil {
 		return err
 	}
@@ -69,18 +74,72 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
+	// Watch for the DVMPs managed by labels only, not owner-referenced by their DVM
+	err = c.Watch(
+		&source.Kind{Type: &migapi.DirectVolumeMigrationProgress{}},
+		handler.EnqueueRequestsFromMapFunc(func(a client.Object) []reconcile.Request {
+			return getProgressRequests(mgr.GetClient(), a)
+		}),
+	)
+	if err != nil {
+		return err
+	}
+
+	// Watch for MigMigrations requesting the DVMs they own to be canceled
+	err = c.Watch(
+		&source.Kind{Type: &migapi.MigMigration{}},
+		handler.EnqueueRequestsFromMapFunc(func(a client.Object) []reconcile.Request {
+			return getCancelRequests(mgr.GetClient(), a)
+		}),
+	)
+	if err != nil {
+		return err
+	}
+
 	// TODO: Modify this to watch the proper list of resources
 
+	// Gather direct volume migration metrics every 10 seconds
+	recordMetrics(mgr.GetClient())
+
 	return nil
 }
 
+// getCancelRequests returns the requests of the DVMs owned by a MigMigration
+// annotated with the cancel-direct-volume-migrations annotation.
+func getCancelRequests(c client.Client, a client.Object) []reconcile.Request {
+	requests := []reconcile.Request{}
+	if _, found := a.GetAnnotations()[migapi.CancelDirectVolumeMigrationsAnnotation]; !found {
+		return requests
+	}
+	list := migapi.DirectVolumeMigrationList{}
+	err := c.List(context.TODO(), &list, client.InNamespace(a.GetNamespace()))
+	if err != nil {
+		log.Trace(err)
+		return requests
+	}
+	for _, dvm := range list.Items {
+		if dvm.GetMigrationUID() != string(a.GetUID()) {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Namespace: dvm.Namespace,
+				Name:      dvm.Name,
+			},
+		})
+	}
+	return requests
+}
+
 var _ reconcile.Reconciler = &ReconcileDirectVolumeMigration{}
 
 // ReconcileDirectVolumeMigration reconciles a DirectVolumeMigration object
 type ReconcileDirectVolumeMigration struct {
 	client.Client
 	scheme *runtime.Scheme
-	tracer opentracing.Tracer
+	// tracer shared by concurrent reconciles, initialized once
+	tracer     opentracing.Tracer
+	tracerOnce *sync.Once
 }
 
 // Reconcile reads that state of the cluster for a DirectVolumeMigration object and makes changes based on the state read
@@ -93,8 +152,9 @@ type ReconcileDirectVolumeMigration struct {
 // +kubebuilder:rbac:groups=migration.openshift.io,resources=directvolumemigrations/status,verbs=get;update;patch
 func (r *ReconcileDirectVolumeMigration) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
 
-	// Set values
-	log = logging.WithName("directvolume", "dvm", request.Name)
+	// Set values, the logger is local to the reconcile as DVMs may be
+	// reconciled concurrently
+	log := logging.WithName("directvolume", "dvm", request.Name)
 
 	// Fetch the DirectVolumeMigration instance
 	direct := &migapi.DirectVolumeMigration{}
@@ -103,18 +163,45 @@ func (r *ReconcileDirectVolumeMigration) Reconcile(ctx context.Context, request
 		if errors.IsNotFound(err) {
 			// Object not found, return.  Created objects are automatically garbage collected.
 			// For additional cleanup logic use finalizers.
+			if !settings.Settings.DvmOpts.OwnerReferences {
+				// the resources managed by labels only are not garbage collected
+				err = deleteOrphanedProgressCRs(r, request.Namespace)
+				if err != nil {
+					log.Trace(err)
+					return reconcile.Result{Requeue: true}, nil
+				}
+			}
 			return reconcile.Result{Requeue: false}, nil
 		}
 		// Error reading the object - requeue the request.
 		return reconcile.Result{Requeue: true}, err
 	}
 
-	// Set MigMigration name key on logger
+	// Maintenance, the DVM is neither validated nor updated, the phase and the
+	// resources created by the migration are left as they are, the transfer Pods
+	// keep running
+	if _, found := direct.Annotations[migapi.MaintenanceAnnotation]; found {
+		log.Info("DirectVolumeMigration is in maintenance mode, skipping.",
+			"phase", direct.Status.Phase)
+		return reconcile.Result{RequeueAfter: getRequeueAfter(direct, PollReQ)}, nil
+	}
+
+	// Set MigMigration name key on logger. The MigMigration is fetched once per
+	// reconcile, the migration is requeued when it cannot be fetched.
 	migration, err := direct.GetMigrationForDVM(r)
+	if err != nil {
+		log.Trace(err)
+		return reconcile.Result{Requeue: true}, nil
+	}
 	if migration != nil {
 		log.Real = log.WithValues("migMigration", migration.Name)
 	}
 
+	// Set MigMigration UID key on logger to correlate logs across controllers
+	if migrationUID := direct.GetMigrationUID(); migrationUID != "" {
+		log.Real = log.WithValues("migrationUID", migrationUID)
+	}
+
 	// Set up jaeger tracing, add to ctx
 	reconcileSpan := r.initTracer(direct)
 	if reconcileSpan != nil {
@@ -123,7 +210,16 @@ func (r *ReconcileDirectVolumeMigration) Reconcile(ctx context.Context, request
 	}
 
 	// Check if completed
-	if direct.Status.Phase == Completed {
+	if direct.Status.Phase == Completed || direct.Status.Phase == Canceled {
+		// Delete once the TTL of the finished DVM expired
+		requeueAfter, err := r.deleteExpired(log, direct)
+		if err != nil {
+			log.Trace(err)
+			return reconcile.Result{Requeue: true}, nil
+		}
+		if requeueAfter > 0 {
+			return reconcile.Result{RequeueAfter: requeueAfter}, nil
+		}
 		return reconcile.Result{Requeue: false}, nil
 	}
 
@@ -138,19 +234,25 @@ func (r *ReconcileDirectVolumeMigration) Reconcile(ctx context.Context, request
 	}
 
 	// Default to PollReQ, can be overridden by r.migrate phase-specific ReQ interval
-	requeueAfter := time.Duration(PollReQ)
+	requeueAfter := getRequeueAfter(direct, PollReQ)
 
+	var event *progressEvent
 	if !direct.Status.HasBlockerCondition() {
-		requeueAfter, err = r.migrate(ctx, direct)
+		phase, percentage := direct.Status.Phase, getProgressPercentage(direct)
+		requeueAfter, err = r.migrate(ctx, log, direct, migration)
 		if err != nil {
 			log.Trace(err)
 			return reconcile.Result{Requeue: true}, nil
 		}
+		event = newProgressEvent(direct, phase, percentage)
+	} else {
+		setRequeueStatus(direct, direct.Status.Phase, RequeueBlocked, RequeueBlockedMessage, requeueAfter)
 	}
 
 	// Set to ready
 	direct.Status.SetReady(
 		direct.Status.Phase != Completed &&
+			direct.Status.Phase != Canceled &&
 			!direct.Status.HasBlockerCondition(),
 		ReadyMessage)
 
@@ -165,6 +267,9 @@ func (r *ReconcileDirectVolumeMigration) Reconcile(ctx context.Context, request
 		return reconcile.Result{Requeue: true}, nil
 	}
 
+	// Progress events, only sent once the status they report is updated
+	r.notifyProgress(log, direct, event)
+
 	// Requeue
 	if requeueAfter > 0 {
 		return reconcile.Result{RequeueAfter: requeueAfter}, nil
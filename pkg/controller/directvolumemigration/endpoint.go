@@ -0,0 +1,541 @@
+package directvolumemigration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	liberr "github.com/konveyor/controller/pkg/error"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"github.com/konveyor/mig-controller/pkg/compat"
+	"github.com/konveyor/mig-controller/pkg/settings"
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Types of the endpoint exposing the Rsync transfer Pods to the source cluster
+const (
+	EndpointTypeRoute     = "Route"
+	EndpointTypeClusterIP = "ClusterIP"
+	EndpointTypeTunnel    = "Tunnel"
+	EndpointTypeAuto      = "auto"
+)
+
+// Ports on which the source Stunnel client connects to the endpoint
+const (
+	RouteEndpointPort     = int32(443)
+	ClusterIPEndpointPort = int32(2222)
+)
+
+// TunnelDialTimeout timeout of the connection checking a tunnel endpoint is reachable.
+var TunnelDialTimeout = time.Duration(time.Second * 5)
+
+// dialTunnelEndpoint checks the tunnel endpoint at the address accepts connections.
+var dialTunnelEndpoint = func(address string) error {
+	conn, err := net.DialTimeout("tcp", address, TunnelDialTimeout)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// MaxDestinationClientBackOff longest delay between attempts while the client
+// of the destination cluster cannot be built.
+var MaxDestinationClientBackOff = time.Duration(time.Minute * 5)
+
+// destinationClientError the client of the destination cluster cannot be built,
+// e.g. when the credentials of the destination MigCluster expired or were rotated.
+type destinationClientError struct {
+	cluster string
+	err     error
+}
+
+func (e *destinationClientError) Error() string {
+	return fmt.Sprintf("failed to build the client of destination cluster %s: %v", e.cluster, e.err)
+}
+
+// Get the destinationClientError wrapped by the error, nil when the error isn't one.
+func getDestinationClientError(err error) *destinationClientError {
+	clientErr := &destinationClientError{}
+	if errors.As(err, &clientErr) {
+		return clientErr
+	}
+	return nil
+}
+
+// Set the DestinationClusterUnreachable condition reporting why the client of the destination cluster cannot be built.
+func setDestinationClusterUnreachable(direct *migapi.DirectVolumeMigration, err *destinationClientError) {
+	direct.Status.SetCondition(migapi.Condition{
+		Type:     DestinationClusterUnreachable,
+		Status:   True,
+		Reason:   NotReady,
+		Category: Warn,
+		Message:  fmt.Sprintf(DestinationClusterUnreachableMessage, err.cluster, err.err.Error()),
+	})
+}
+
+// Report the destination cluster unreachable and get the delay before the next
+// attempt. The delay doubles while the client of the destination cluster cannot
+// be built, up to MaxDestinationClientBackOff.
+func (t *Task) backOffDestinationClientError(err *destinationClientError) time.Duration {
+	delay := PollReQ
+	if condition := t.Owner.Status.FindCondition(DestinationClusterUnreachable); condition != nil {
+		if elapsed := time.Since(condition.LastTransitionTime.Time); elapsed > delay {
+			delay = elapsed
+		}
+	}
+	if delay > MaxDestinationClientBackOff {
+		delay = MaxDestinationClientBackOff
+	}
+	setDestinationClusterUnreachable(t.Owner, err)
+	return delay
+}
+
+// Endpoint type rules of a destination cluster, set in the RSYNC_ENDPOINT_TYPE
+// key of its cluster ConfigMap. The key holds either a single endpoint type or
+// a JSON object with per-namespace and per-StorageClass rules, for instance:
+// {"default": "Route", "namespaces": {"ns-1": "ClusterIP"}, "storageClasses": {"gp2": "ClusterIP"}}
+// Namespace rules take precedence over StorageClass rules, the default applies
+// when no rule matches. Namespaces are destination namespaces, StorageClasses
+// are the target StorageClasses of the PVCs migrated into the namespace.
+type endpointTypeRules struct {
+	Default        string            `json:"default,omitempty"`
+	Namespaces     map[string]string `json:"namespaces,omitempty"`
+	StorageClasses map[string]string `json:"storageClasses,omitempty"`
+}
+
+// Parse the endpoint type rules of a destination cluster.
+// Unknown endpoint types and unknown fields are rejected.
+func parseEndpointTypeRules(value string) (*endpointTypeRules, error) {
+	rules := &endpointTypeRules{}
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "{") {
+		decoder := json.NewDecoder(bytes.NewReader([]byte(value)))
+		decoder.DisallowUnknownFields()
+		err := decoder.Decode(rules)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse endpoint type rules: %v", err)
+		}
+	} else {
+		rules.Default = value
+	}
+	invalid := []string{}
+	if rules.Default != "" && !isValidEndpointType(rules.Default) {
+		invalid = append(invalid, fmt.Sprintf("default: %s", rules.Default))
+	}
+	for ns, endpointType := range rules.Namespaces {
+		if !isValidEndpointType(endpointType) {
+			invalid = append(invalid, fmt.Sprintf("namespace %s: %s", ns, endpointType))
+		}
+	}
+	for sc, endpointType := range rules.StorageClasses {
+		if !isValidEndpointType(endpointType) {
+			invalid = append(invalid, fmt.Sprintf("storageClass %s: %s", sc, endpointType))
+		}
+	}
+	if len(invalid) > 0 {
+		sort.Strings(invalid)
+		return nil, fmt.Errorf("unknown endpoint types [%s], must be one of: %s, %s, %s",
+			strings.Join(invalid, ", "), EndpointTypeRoute, EndpointTypeClusterIP, EndpointTypeAuto)
+	}
+	return rules, nil
+}
+
+func isValidEndpointType(endpointType string) bool {
+	return endpointType == EndpointTypeRoute || endpointType == EndpointTypeClusterIP || endpointType == EndpointTypeAuto
+}
+
+// Get the endpoint type of the destination namespace, StorageClass rules only
+// apply when all matching StorageClasses of the namespace agree.
+func (r *endpointTypeRules) getEndpointType(namespace string, storageClasses []string) string {
+	if endpointType, found := r.Namespaces[namespace]; found {
+		return endpointType
+	}
+	matched := map[string]bool{}
+	for _, sc := range storageClasses {
+		if endpointType, found := r.StorageClasses[sc]; found {
+			matched[endpointType] = true
+		}
+	}
+	if len(matched) == 1 {
+		for endpointType := range matched {
+			return endpointType
+		}
+	}
+	if r.Default != "" {
+		return r.Default
+	}
+	return EndpointTypeRoute
+}
+
+// Get the endpoint type rules of the destination cluster. The DVM_ENDPOINT_TYPE
+// setting is the default when the cluster ConfigMap doesn't set one. The rules
+// are read once per reconcile, the Task lives for a single reconcile.
+func (t *Task) getEndpointTypeRules() (*endpointTypeRules, error) {
+	if t.endpointTypeRules != nil {
+		return t.endpointTypeRules, nil
+	}
+	cluster, err := t.Owner.GetDestinationCluster(t.Client)
+	if err != nil {
+		return nil, liberr.Wrap(err)
+	}
+	value := ""
+	if cluster != nil {
+		client, err := cluster.GetClient(t.Client)
+		if err != nil {
+			return nil, liberr.Wrap(&destinationClientError{
+				cluster: path.Join(cluster.Namespace, cluster.Name),
+				err:     err,
+			})
+		}
+		clusterConfig, err := cluster.GetClusterConfigMap(client)
+		if err != nil {
+			return nil, liberr.Wrap(err)
+		}
+		value = clusterConfig.Data[migapi.RsyncEndpointTypeKey]
+	}
+	rules, err := parseEndpointTypeRules(value)
+	if err != nil {
+		return nil, liberr.Wrap(err)
+	}
+	if rules.Default == "" && isValidEndpointType(settings.Settings.DvmOpts.EndpointType) {
+		rules.Default = settings.Settings.DvmOpts.EndpointType
+	}
+	t.endpointTypeRules = rules
+	return rules, nil
+}
+
+// Select the endpoint type of the auto endpoint type from the capabilities of
+// the destination cluster, with the reason. A Route is preferred when Routes
+// are admitted under the default domain of the cluster, a ClusterIP endpoint
+// when the clusters share a flat network. Empty when no endpoint type is usable,
+// the cluster serving neither the Route API nor a flat network.
+func selectEndpointType(routeAPI bool, domain string, flatNetwork bool) (string, string) {
+	switch {
+	case routeAPI && domain != "":
+		return EndpointTypeRoute, fmt.Sprintf("the Route API is available and Routes are admitted under the default domain %s", domain)
+	case flatNetwork:
+		return EndpointTypeClusterIP, "the default domain of the Routes is not set and the clusters share a flat network"
+	case routeAPI:
+		return EndpointTypeRoute, "the Route API is available, the default domain of the Routes is not set"
+	default:
+		return "", "the Route API is not available and the clusters don't share a flat network"
+	}
+}
+
+// Get whether the Route API is served by the cluster.
+func hasRouteAPI(client compat.Client) (bool, error) {
+	_, err := client.ServerResourcesForGroupVersion(routev1.SchemeGroupVersion.String())
+	if k8serror.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	return true, nil
+}
+
+// Get the default domain of the Routes of the cluster, set by the cluster
+// Ingress configuration of OpenShift 4. Empty when the configuration cannot
+// be read.
+func getIngressDomain(client compat.Client) (string, error) {
+	ingress := &unstructured.Unstructured{}
+	ingress.SetGroupVersionKind(schema.GroupVersionKind{Group: "config.openshift.io", Version: "v1", Kind: "Ingress"})
+	err := client.Get(context.TODO(), types.NamespacedName{Name: "cluster"}, ingress)
+	if k8serror.IsNotFound(err) || k8serror.IsForbidden(err) || meta.IsNoMatchError(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", liberr.Wrap(err)
+	}
+	domain, _, err := unstructured.NestedString(ingress.Object, "spec", "domain")
+	if err != nil {
+		return "", liberr.Wrap(err)
+	}
+	return domain, nil
+}
+
+// Get the endpoint type selected for the auto endpoint type. The destination
+// cluster is probed once, the selection is kept in the status of the DVM as
+// the endpoint type must not change during the migration.
+func (t *Task) getAutoEndpointType() (string, error) {
+	if selected := t.Owner.Status.AutoEndpointType; selected != nil {
+		return selected.Type, nil
+	}
+	destClient, err := t.getDestinationClient()
+	if err != nil {
+		return "", liberr.Wrap(err)
+	}
+	routeAPI, err := hasRouteAPI(destClient)
+	if err != nil {
+		return "", liberr.Wrap(err)
+	}
+	domain := ""
+	if routeAPI {
+		domain, err = getIngressDomain(destClient)
+		if err != nil {
+			return "", liberr.Wrap(err)
+		}
+	}
+	endpointType, reason := selectEndpointType(routeAPI, domain, settings.Settings.DvmOpts.FlatNetwork)
+	if endpointType == "" {
+		t.Owner.Status.SetCondition(migapi.Condition{
+			Type:     NoEndpointTypeAvailable,
+			Status:   True,
+			Reason:   NotSupported,
+			Category: Critical,
+			Message:  NoEndpointTypeAvailableMessage,
+			Durable:  true,
+		})
+		return "", liberr.Wrap(&NoEndpointTypeError{Reason: reason})
+	}
+	t.Log.Info("Selected the endpoint type of the destination cluster.",
+		"endpointType", endpointType,
+		"reason", reason)
+	t.Owner.Status.AutoEndpointType = &migapi.AutoEndpointType{
+		Type:   endpointType,
+		Reason: reason,
+	}
+	return endpointType, nil
+}
+
+// Get the tunnel endpoint provided by the user for the destination namespace.
+// Returns nil when the namespace has none.
+func (t *Task) getTunnelEndpoint(namespace string) *migapi.TunnelEndpoint {
+	for i := range t.Owner.Spec.TunnelEndpoints {
+		if t.Owner.Spec.TunnelEndpoints[i].Namespace == namespace {
+			return &t.Owner.Spec.TunnelEndpoints[i]
+		}
+	}
+	return nil
+}
+
+// Get the type of the endpoint exposing the Rsync transfer Pods of the destination namespace.
+// The tunnel endpoint of the namespace provided by the user takes precedence.
+// The source and destination clusters of a DVM are always distinct, a
+// ClusterIP endpoint is therefore only reachable from the source cluster
+// when the clusters share a flat network. Route is used otherwise. The auto
+// endpoint type is resolved from the capabilities of the destination cluster.
+func (t *Task) getEndpointType(namespace string) (string, error) {
+	if t.getTunnelEndpoint(namespace) != nil {
+		return EndpointTypeTunnel, nil
+	}
+	rules, err := t.getEndpointTypeRules()
+	if err != nil {
+		return "", liberr.Wrap(err)
+	}
+	endpointType := rules.getEndpointType(namespace, t.getTargetStorageClasses(namespace))
+	if endpointType == EndpointTypeAuto {
+		endpointType, err = t.getAutoEndpointType()
+		if err != nil {
+			return "", liberr.Wrap(err)
+		}
+	}
+	switch endpointType {
+	case EndpointTypeClusterIP:
+		if settings.Settings.DvmOpts.FlatNetwork {
+			return EndpointTypeClusterIP, nil
+		}
+		t.Log.Info("ClusterIP endpoint requires a flat network between clusters, using Route endpoint.",
+			"namespace", namespace,
+			"flatNetworkSetting", settings.DvmFlatNetwork)
+		return EndpointTypeRoute, nil
+	default:
+		return EndpointTypeRoute, nil
+	}
+}
+
+// Get the port on which the source Stunnel client connects to the endpoint of the destination namespace.
+func (t *Task) getEndpointPort(namespace string) (int32, error) {
+	endpointType, err := t.getEndpointType(namespace)
+	if err != nil {
+		return 0, liberr.Wrap(err)
+	}
+	switch endpointType {
+	case EndpointTypeClusterIP:
+		return ClusterIPEndpointPort, nil
+	case EndpointTypeTunnel:
+		return t.getTunnelEndpoint(namespace).Port, nil
+	}
+	return RouteEndpointPort, nil
+}
+
+// Get the target StorageClasses of the PVCs migrated into the destination namespace.
+func (t *Task) getTargetStorageClasses(namespace string) []string {
+	storageClasses := []string{}
+	for _, pvc := range t.Owner.Spec.PersistentVolumeClaims {
+		destNs := pvc.Namespace
+		if pvc.TargetNamespace != "" {
+			destNs = pvc.TargetNamespace
+		}
+		if destNs == namespace && pvc.TargetStorageClass != "" {
+			storageClasses = append(storageClasses, pvc.TargetStorageClass)
+		}
+	}
+	return storageClasses
+}
+
+// Get the cluster IP of the Rsync transfer Service in the given destination namespace.
+// On a flat network the cluster IP is routable from the source cluster. A headless
+// Service never gets a cluster IP and is not retried.
+func (t *Task) getRsyncTransferServiceIP(namespace string) (string, error) {
+	destClient, err := t.getDestinationClient()
+	if err != nil {
+		return "", err
+	}
+	svc := corev1.Service{}
+	key := types.NamespacedName{Name: DirectVolumeMigrationRsyncTransferSvc, Namespace: namespace}
+	err = destClient.Get(context.TODO(), key, &svc)
+	if err != nil {
+		return "", err
+	}
+	if svc.Spec.ClusterIP == corev1.ClusterIPNone {
+		return "", &EndpointUnreachableError{
+			Namespace:    namespace,
+			EndpointType: EndpointTypeClusterIP,
+			Reason:       fmt.Sprintf("service %s is headless", path.Join(svc.Namespace, svc.Name)),
+			Permanent:    true,
+		}
+	}
+	if svc.Spec.ClusterIP == "" {
+		return "", &EndpointUnreachableError{
+			Namespace:    namespace,
+			EndpointType: EndpointTypeClusterIP,
+			Reason:       fmt.Sprintf("cluster IP not assigned to service %s", path.Join(svc.Namespace, svc.Name)),
+		}
+	}
+	return svc.Spec.ClusterIP, nil
+}
+
+// Get whether the endpoint of the given type is fully provisioned: the Rsync
+// transfer Service has a cluster IP and ready endpoint addresses, and for a Route
+// endpoint the Route is admitted. A tunnel endpoint forwards to the Service. Returns the reason when it is not ready.
+func isEndpointReady(endpointType string, svc *corev1.Service, endpoints *corev1.Endpoints, route *routev1.Route) (bool, string) {
+	if svc == nil {
+		return false, "service not found"
+	}
+	if svc.Spec.ClusterIP == "" || svc.Spec.ClusterIP == corev1.ClusterIPNone {
+		return false, "cluster IP not assigned to service"
+	}
+	ready := false
+	if endpoints != nil {
+		for _, subset := range endpoints.Subsets {
+			if len(subset.Addresses) > 0 {
+				ready = true
+				break
+			}
+		}
+	}
+	if !ready {
+		return false, "service has no ready endpoints"
+	}
+	if endpointType != EndpointTypeRoute {
+		return true, ""
+	}
+	if route == nil {
+		return false, "route not found"
+	}
+	for _, ingress := range route.Status.Ingress {
+		for _, condition := range ingress.Conditions {
+			if condition.Type == routev1.RouteAdmitted && condition.Status == corev1.ConditionTrue {
+				return true, ""
+			}
+		}
+	}
+	return false, "route not admitted"
+}
+
+// Get the destination namespaces which endpoint is not fully provisioned yet,
+// with the reason.
+func (t *Task) getEndpointsNotReady() ([]string, error) {
+	notReady := []string{}
+	destClient, err := t.getDestinationClient()
+	if err != nil {
+		return nil, liberr.Wrap(err)
+	}
+	for bothNs := range t.getPVCNamespaceMap() {
+		namespace := getDestNs(bothNs)
+		endpointType, err := t.getEndpointType(namespace)
+		if err != nil {
+			return nil, liberr.Wrap(err)
+		}
+		svc := &corev1.Service{}
+		key := types.NamespacedName{Name: DirectVolumeMigrationRsyncTransferSvc, Namespace: namespace}
+		err = destClient.Get(context.TODO(), key, svc)
+		if k8serror.IsNotFound(err) {
+			svc = nil
+		} else if err != nil {
+			return nil, liberr.Wrap(err)
+		}
+		endpoints := &corev1.Endpoints{}
+		err = destClient.Get(context.TODO(), key, endpoints)
+		if k8serror.IsNotFound(err) {
+			endpoints = nil
+		} else if err != nil {
+			return nil, liberr.Wrap(err)
+		}
+		var route *routev1.Route
+		if endpointType == EndpointTypeRoute {
+			route = &routev1.Route{}
+			key = types.NamespacedName{Name: DirectVolumeMigrationRsyncTransferRoute, Namespace: namespace}
+			err = destClient.Get(context.TODO(), key, route)
+			if k8serror.IsNotFound(err) {
+				route = nil
+			} else if err != nil {
+				return nil, liberr.Wrap(err)
+			}
+		}
+		ready, reason := isEndpointReady(endpointType, svc, endpoints, route)
+		if !ready {
+			notReady = append(notReady, fmt.Sprintf("%s: %s %s", namespace, endpointType, reason))
+		}
+	}
+	sort.Strings(notReady)
+	return notReady, nil
+}
+
+// Check the readiness of the endpoints on the first reconcile of a phase, the
+// endpoints are not read on every reconcile of a phase polling for the transfer.
+// The EndpointReady condition set when the phase was entered is kept until the
+// next phase.
+func (t *Task) ensureEndpointReady() error {
+	if requeue := t.Owner.Status.Requeue; requeue != nil && requeue.Phase == t.Phase {
+		t.Owner.Status.StageCondition(EndpointReady)
+		return nil
+	}
+	return t.setEndpointReady()
+}
+
+// Set the EndpointReady condition once the endpoints of all destination
+// namespaces are fully provisioned. The readiness of the endpoint is reported
+// apart from the readiness of the Rsync transfer Pods backing it.
+func (t *Task) setEndpointReady() error {
+	notReady, err := t.getEndpointsNotReady()
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	if len(notReady) > 0 {
+		t.Log.V(4).Info("Rsync transfer endpoints not ready yet.", "endpoints", notReady)
+		return nil
+	}
+	t.Owner.Status.SetCondition(migapi.Condition{
+		Type:     EndpointReady,
+		Status:   True,
+		Reason:   t.Phase,
+		Category: Advisory,
+		Message:  EndpointReadyMessage,
+	})
+	return nil
+}
@@ -0,0 +1,517 @@
+package directvolumemigration
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	liberr "github.com/konveyor/controller/pkg/error"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"github.com/konveyor/mig-controller/pkg/settings"
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestTask_getEndpointType(t *testing.T) {
+	tests := []struct {
+		name         string
+		endpointType string
+		flatNetwork  bool
+		want         string
+		wantPort     int32
+	}{
+		{
+			name:         "when endpoint type is not set, should use Route",
+			endpointType: "",
+			flatNetwork:  false,
+			want:         EndpointTypeRoute,
+			wantPort:     RouteEndpointPort,
+		},
+		{
+			name:         "when endpoint type is Route on a flat network, should use Route",
+			endpointType: EndpointTypeRoute,
+			flatNetwork:  true,
+			want:         EndpointTypeRoute,
+			wantPort:     RouteEndpointPort,
+		},
+		{
+			name:         "when endpoint type is ClusterIP without a flat network, should fall back to Route",
+			endpointType: EndpointTypeClusterIP,
+			flatNetwork:  false,
+			want:         EndpointTypeRoute,
+			wantPort:     RouteEndpointPort,
+		},
+		{
+			name:         "when endpoint type is ClusterIP on a flat network, should use ClusterIP",
+			endpointType: EndpointTypeClusterIP,
+			flatNetwork:  true,
+			want:         EndpointTypeClusterIP,
+			wantPort:     ClusterIPEndpointPort,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &Task{
+				Log:   log.WithName("test-logger"),
+				Owner: &migapi.DirectVolumeMigration{},
+			}
+			settings.Settings.DvmOpts.EndpointType = tt.endpointType
+			settings.Settings.DvmOpts.FlatNetwork = tt.flatNetwork
+			defer func() {
+				settings.Settings.DvmOpts.EndpointType = ""
+				settings.Settings.DvmOpts.FlatNetwork = false
+			}()
+			got, err := task.getEndpointType("ns")
+			if err != nil || got != tt.want {
+				t.Errorf("Task.getEndpointType() = %v, %v, want %v", got, err, tt.want)
+			}
+			gotPort, err := task.getEndpointPort("ns")
+			if err != nil || gotPort != tt.wantPort {
+				t.Errorf("Task.getEndpointPort() = %v, %v, want %v", gotPort, err, tt.wantPort)
+			}
+		})
+	}
+}
+
+func TestTask_getEndpointType_auto(t *testing.T) {
+	settings.Settings.DvmOpts.EndpointType = EndpointTypeAuto
+	settings.Settings.DvmOpts.FlatNetwork = true
+	defer func() {
+		settings.Settings.DvmOpts.EndpointType = ""
+		settings.Settings.DvmOpts.FlatNetwork = false
+	}()
+	// the endpoint type selected when the migration started is kept
+	task := &Task{
+		Log: log.WithName("test-logger"),
+		Owner: &migapi.DirectVolumeMigration{
+			Status: migapi.DirectVolumeMigrationStatus{
+				AutoEndpointType: &migapi.AutoEndpointType{Type: EndpointTypeClusterIP},
+			},
+		},
+	}
+	got, err := task.getEndpointType("ns")
+	if err != nil || got != EndpointTypeClusterIP {
+		t.Errorf("Task.getEndpointType() = %v, %v, want %v", got, err, EndpointTypeClusterIP)
+	}
+}
+
+func Test_selectEndpointType(t *testing.T) {
+	tests := []struct {
+		name        string
+		routeAPI    bool
+		domain      string
+		flatNetwork bool
+		want        string
+	}{
+		{name: "when Routes are admitted under a default domain, should use Route", routeAPI: true, domain: "apps.example.com", flatNetwork: true, want: EndpointTypeRoute},
+		{name: "when the default domain is not set on a flat network, should use ClusterIP", routeAPI: true, flatNetwork: true, want: EndpointTypeClusterIP},
+		{name: "when the Route API is not available on a flat network, should use ClusterIP", flatNetwork: true, want: EndpointTypeClusterIP},
+		{name: "when the default domain is not set without a flat network, should use Route", routeAPI: true, want: EndpointTypeRoute},
+		{name: "when nothing is available, should find no endpoint type", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, reason := selectEndpointType(tt.routeAPI, tt.domain, tt.flatNetwork)
+			if got != tt.want || reason == "" {
+				t.Errorf("selectEndpointType() = %v, %q, want %v", got, reason, tt.want)
+			}
+		})
+	}
+}
+
+func TestTask_getEndpointType_tunnel(t *testing.T) {
+	task := &Task{
+		Log: log.WithName("test-logger"),
+		Owner: &migapi.DirectVolumeMigration{
+			Spec: migapi.DirectVolumeMigrationSpec{
+				TunnelEndpoints: []migapi.TunnelEndpoint{
+					{Namespace: "tunneled", Host: "rsync.tunneled.svc.clusterset.local", Port: 2222},
+				},
+			},
+		},
+	}
+	got, err := task.getEndpointType("tunneled")
+	if err != nil || got != EndpointTypeTunnel {
+		t.Errorf("Task.getEndpointType() = %v, %v, want %v", got, err, EndpointTypeTunnel)
+	}
+	gotPort, err := task.getEndpointPort("tunneled")
+	if err != nil || gotPort != 2222 {
+		t.Errorf("Task.getEndpointPort() = %v, %v, want %v", gotPort, err, 2222)
+	}
+	gotHost, err := task.getRsyncRoute("tunneled")
+	if err != nil || gotHost != "rsync.tunneled.svc.clusterset.local" {
+		t.Errorf("Task.getRsyncRoute() = %v, %v, want the host of the tunnel", gotHost, err)
+	}
+	got, err = task.getEndpointType("ns")
+	if err != nil || got != EndpointTypeRoute {
+		t.Errorf("Task.getEndpointType() of a namespace without a tunnel = %v, %v, want %v", got, err, EndpointTypeRoute)
+	}
+}
+
+func TestTask_getEndpointTypeRules_cached(t *testing.T) {
+	settings.Settings.DvmOpts.EndpointType = EndpointTypeClusterIP
+	defer func() {
+		settings.Settings.DvmOpts.EndpointType = ""
+	}()
+	task := &Task{
+		Log:   log.WithName("test-logger"),
+		Owner: &migapi.DirectVolumeMigration{},
+	}
+	rules, err := task.getEndpointTypeRules()
+	if err != nil || rules.Default != EndpointTypeClusterIP {
+		t.Fatalf("Task.getEndpointTypeRules() = %v, %v, want the %s default", rules, err, EndpointTypeClusterIP)
+	}
+	// the rules are read once per reconcile
+	settings.Settings.DvmOpts.EndpointType = EndpointTypeRoute
+	cached, err := task.getEndpointTypeRules()
+	if err != nil || cached != rules || cached.Default != EndpointTypeClusterIP {
+		t.Errorf("Task.getEndpointTypeRules() = %v, %v, want the rules read by the reconcile", cached, err)
+	}
+}
+
+func TestTask_ensureEndpointReady_oncePerPhase(t *testing.T) {
+	owner := &migapi.DirectVolumeMigration{}
+	owner.Status.Requeue = &migapi.RequeueStatus{Phase: WaitForRsyncTransferPodsRunning}
+	owner.Status.SetCondition(migapi.Condition{
+		Type:     EndpointReady,
+		Status:   True,
+		Reason:   WaitForRsyncTransferPodsRunning,
+		Category: Advisory,
+		Message:  EndpointReadyMessage,
+	})
+	// the endpoints are not read again within the phase, the task has no client
+	task := &Task{
+		Log:   log.WithName("test-logger"),
+		Owner: owner,
+		Phase: WaitForRsyncTransferPodsRunning,
+	}
+	owner.Status.BeginStagingConditions()
+	if err := task.ensureEndpointReady(); err != nil {
+		t.Fatalf("Task.ensureEndpointReady() error = %v", err)
+	}
+	owner.Status.EndStagingConditions()
+	if !owner.Status.HasCondition(EndpointReady) {
+		t.Errorf("Task.ensureEndpointReady() dropped the %s condition set when the phase was entered", EndpointReady)
+	}
+}
+
+func TestTask_getEndpointType_destinationClientError(t *testing.T) {
+	cluster := &migapi.MigCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "destination", Namespace: migapi.OpenshiftMigrationNamespace},
+		Spec: migapi.MigClusterSpec{
+			URL: "https://destination:6443",
+			ServiceAccountSecretRef: &corev1.ObjectReference{
+				Namespace: "openshift-config",
+				Name:      "expired-token",
+			},
+		},
+	}
+	task := &Task{
+		Log:    log.WithName("test-logger"),
+		Client: fake.NewFakeClient(cluster),
+		Owner: &migapi.DirectVolumeMigration{
+			Spec: migapi.DirectVolumeMigrationSpec{
+				DestMigClusterRef: &corev1.ObjectReference{Namespace: cluster.Namespace, Name: cluster.Name},
+			},
+		},
+	}
+	_, err := task.getEndpointType("ns")
+	clientErr := getDestinationClientError(err)
+	if clientErr == nil {
+		t.Fatalf("Task.getEndpointType() error = %v, want a destination client error", err)
+	}
+	if clientErr.cluster != "openshift-migration/destination" {
+		t.Errorf("destinationClientError.cluster = %v, want openshift-migration/destination", clientErr.cluster)
+	}
+
+	if got := task.backOffDestinationClientError(clientErr); got != PollReQ {
+		t.Errorf("Task.backOffDestinationClientError() first delay = %v, want %v", got, PollReQ)
+	}
+	condition := task.Owner.Status.FindCondition(DestinationClusterUnreachable)
+	if condition == nil || condition.Category != Warn {
+		t.Fatalf("Task.backOffDestinationClientError() condition = %v, want a %s warning", condition, DestinationClusterUnreachable)
+	}
+	condition.LastTransitionTime = metav1.NewTime(time.Now().Add(-time.Minute))
+	if got := task.backOffDestinationClientError(clientErr); got < time.Minute || got > MaxDestinationClientBackOff {
+		t.Errorf("Task.backOffDestinationClientError() delay = %v, want about %v", got, time.Minute)
+	}
+	condition.LastTransitionTime = metav1.NewTime(time.Now().Add(-time.Hour))
+	if got := task.backOffDestinationClientError(clientErr); got != MaxDestinationClientBackOff {
+		t.Errorf("Task.backOffDestinationClientError() delay = %v, want %v", got, MaxDestinationClientBackOff)
+	}
+}
+
+func Test_getDestinationClientError(t *testing.T) {
+	clientErr := &destinationClientError{cluster: "ns/cluster", err: errors.New("unauthorized")}
+	if got := getDestinationClientError(liberr.Wrap(clientErr)); got != clientErr {
+		t.Errorf("getDestinationClientError() = %v, want %v", got, clientErr)
+	}
+	if got := getDestinationClientError(liberr.Wrap(errors.New("conflict"))); got != nil {
+		t.Errorf("getDestinationClientError() = %v, want nil", got)
+	}
+}
+
+func Test_parseEndpointTypeRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    *endpointTypeRules
+		wantErr bool
+	}{
+		{
+			name:  "when value is empty, should return empty rules",
+			value: "",
+			want:  &endpointTypeRules{},
+		},
+		{
+			name:  "when value is a single endpoint type, should use it as default",
+			value: "ClusterIP",
+			want:  &endpointTypeRules{Default: EndpointTypeClusterIP},
+		},
+		{
+			name:  "when value is a JSON object, should parse the rules",
+			value: `{"default": "Route", "namespaces": {"ns-1": "ClusterIP"}, "storageClasses": {"gp2": "ClusterIP"}}`,
+			want: &endpointTypeRules{
+				Default:        EndpointTypeRoute,
+				Namespaces:     map[string]string{"ns-1": EndpointTypeClusterIP},
+				StorageClasses: map[string]string{"gp2": EndpointTypeClusterIP},
+			},
+		},
+		{
+			name:  "when value is auto, should use it as default",
+			value: "auto",
+			want:  &endpointTypeRules{Default: EndpointTypeAuto},
+		},
+		{
+			name:    "when value is an unknown endpoint type, should fail",
+			value:   "NodePort",
+			wantErr: true,
+		},
+		{
+			name:    "when a rule uses an unknown endpoint type, should fail",
+			value:   `{"namespaces": {"ns-1": "NodePort"}}`,
+			wantErr: true,
+		},
+		{
+			name:    "when the JSON object has unknown fields, should fail",
+			value:   `{"default": "Route", "nodes": {"node-1": "ClusterIP"}}`,
+			wantErr: true,
+		},
+		{
+			name:    "when the JSON object is malformed, should fail",
+			value:   `{"default": "Route"`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseEndpointTypeRules(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseEndpointTypeRules() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseEndpointTypeRules() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_endpointTypeRules_getEndpointType(t *testing.T) {
+	rules := &endpointTypeRules{
+		Default:        EndpointTypeRoute,
+		Namespaces:     map[string]string{"ns-1": EndpointTypeClusterIP},
+		StorageClasses: map[string]string{"gp2": EndpointTypeClusterIP, "gp3": EndpointTypeRoute},
+	}
+	tests := []struct {
+		name           string
+		rules          *endpointTypeRules
+		namespace      string
+		storageClasses []string
+		want           string
+	}{
+		{
+			name:           "when a namespace rule matches, should take precedence over storage class rules",
+			rules:          rules,
+			namespace:      "ns-1",
+			storageClasses: []string{"gp3"},
+			want:           EndpointTypeClusterIP,
+		},
+		{
+			name:           "when a storage class rule matches, should use it",
+			rules:          rules,
+			namespace:      "ns-2",
+			storageClasses: []string{"gp2", "standard"},
+			want:           EndpointTypeClusterIP,
+		},
+		{
+			name:           "when storage class rules conflict, should use the default",
+			rules:          rules,
+			namespace:      "ns-2",
+			storageClasses: []string{"gp2", "gp3"},
+			want:           EndpointTypeRoute,
+		},
+		{
+			name:           "when no rule matches and no default is set, should use Route",
+			rules:          &endpointTypeRules{},
+			namespace:      "ns-2",
+			storageClasses: []string{"standard"},
+			want:           EndpointTypeRoute,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rules.getEndpointType(tt.namespace, tt.storageClasses); got != tt.want {
+				t.Errorf("endpointTypeRules.getEndpointType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_isEndpointReady(t *testing.T) {
+	svc := &corev1.Service{Spec: corev1.ServiceSpec{ClusterIP: "172.30.0.10"}}
+	headless := &corev1.Service{Spec: corev1.ServiceSpec{ClusterIP: corev1.ClusterIPNone}}
+	endpoints := &corev1.Endpoints{
+		Subsets: []corev1.EndpointSubset{{Addresses: []corev1.EndpointAddress{{IP: "10.128.0.5"}}}},
+	}
+	notReadyEndpoints := &corev1.Endpoints{
+		Subsets: []corev1.EndpointSubset{{NotReadyAddresses: []corev1.EndpointAddress{{IP: "10.128.0.5"}}}},
+	}
+	route := func(status corev1.ConditionStatus) *routev1.Route {
+		return &routev1.Route{
+			Status: routev1.RouteStatus{
+				Ingress: []routev1.RouteIngress{{
+					Conditions: []routev1.RouteIngressCondition{{Type: routev1.RouteAdmitted, Status: status}},
+				}},
+			},
+		}
+	}
+	tests := []struct {
+		name         string
+		endpointType string
+		svc          *corev1.Service
+		endpoints    *corev1.Endpoints
+		route        *routev1.Route
+		want         bool
+	}{
+		{
+			name:         "when the Route is admitted and the Service has ready endpoints, should be ready",
+			endpointType: EndpointTypeRoute,
+			svc:          svc,
+			endpoints:    endpoints,
+			route:        route(corev1.ConditionTrue),
+			want:         true,
+		},
+		{
+			name:         "when the Route is not admitted, should not be ready",
+			endpointType: EndpointTypeRoute,
+			svc:          svc,
+			endpoints:    endpoints,
+			route:        route(corev1.ConditionFalse),
+			want:         false,
+		},
+		{
+			name:         "when the Route is not created yet, should not be ready",
+			endpointType: EndpointTypeRoute,
+			svc:          svc,
+			endpoints:    endpoints,
+			want:         false,
+		},
+		{
+			name:         "when the Route is admitted but the Service has no ready endpoints, should not be ready",
+			endpointType: EndpointTypeRoute,
+			svc:          svc,
+			endpoints:    notReadyEndpoints,
+			route:        route(corev1.ConditionTrue),
+			want:         false,
+		},
+		{
+			name:         "when the ClusterIP Service has ready endpoints, should be ready without a Route",
+			endpointType: EndpointTypeClusterIP,
+			svc:          svc,
+			endpoints:    endpoints,
+			want:         true,
+		},
+		{
+			name:         "when the ClusterIP Service has no endpoints, should not be ready",
+			endpointType: EndpointTypeClusterIP,
+			svc:          svc,
+			want:         false,
+		},
+		{
+			name:         "when the Service behind the tunnel has ready endpoints, should be ready without a Route",
+			endpointType: EndpointTypeTunnel,
+			svc:          svc,
+			endpoints:    endpoints,
+			want:         true,
+		},
+		{
+			name:         "when the ClusterIP Service has no cluster IP, should not be ready",
+			endpointType: EndpointTypeClusterIP,
+			svc:          headless,
+			endpoints:    endpoints,
+			want:         false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, reason := isEndpointReady(tt.endpointType, tt.svc, tt.endpoints, tt.route)
+			if got != tt.want {
+				t.Errorf("isEndpointReady() = %v, want %v", got, tt.want)
+			}
+			if !got && reason == "" {
+				t.Errorf("isEndpointReady() returned no reason while not ready")
+			}
+		})
+	}
+}
+
+func Test_getRouteRejection(t *testing.T) {
+	getRoute := func(conditions ...routev1.RouteIngressCondition) *routev1.Route {
+		route := &routev1.Route{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: DirectVolumeMigrationRsyncTransferRoute},
+			Spec:       routev1.RouteSpec{Host: "dvm-ns.apps.example.com"},
+		}
+		for _, condition := range conditions {
+			route.Status.Ingress = append(route.Status.Ingress, routev1.RouteIngress{
+				RouterName: "default",
+				Conditions: []routev1.RouteIngressCondition{condition},
+			})
+		}
+		return route
+	}
+	admitted := routev1.RouteIngressCondition{Type: routev1.RouteAdmitted, Status: corev1.ConditionTrue}
+	rejected := routev1.RouteIngressCondition{Type: routev1.RouteAdmitted, Status: corev1.ConditionFalse, Reason: "HostAlreadyClaimed"}
+	tests := []struct {
+		name      string
+		route     *routev1.Route
+		want      string
+		wantFound bool
+	}{
+		{
+			name:  "when no router reported on the route, should not be rejected",
+			route: getRoute(),
+		},
+		{
+			name:  "when a router admitted the route, should not be rejected",
+			route: getRoute(rejected, admitted),
+		},
+		{
+			name:      "when routers only rejected the route, should report the host and reasons",
+			route:     getRoute(rejected),
+			want:      "ns/" + DirectVolumeMigrationRsyncTransferRoute + " host dvm-ns.apps.example.com: HostAlreadyClaimed by router default",
+			wantFound: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := getRouteRejection(tt.route)
+			if got != tt.want || found != tt.wantFound {
+				t.Errorf("getRouteRejection() = %v, %v, want %v, %v", got, found, tt.want, tt.wantFound)
+			}
+		})
+	}
+}
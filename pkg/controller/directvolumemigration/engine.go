@@ -0,0 +1,139 @@
+package directvolumemigration
+
+import (
+	"sort"
+
+	liberr "github.com/konveyor/controller/pkg/error"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+)
+
+// TransferEngine transfers the data of the PVCs of a DVM once the destination
+// PVCs are bound, e.g. with Rsync, restic or rclone. Each method is called on
+// every reconcile of its phase until it reports completion, it must therefore
+// be idempotent and return without blocking.
+type TransferEngine interface {
+	// Prepare creates the resources of the transfer.
+	// Returns true once they are ready.
+	Prepare() (bool, error)
+	// Run transfers the PVCs.
+	// Returns whether all transfers completed, whether any of them failed,
+	// and the reasons of the failures.
+	Run() (bool, bool, []string, error)
+	// Verify reports the differences between the source and destination PVCs
+	// in the status of the DVM, called once the transfers of a verifyOnly
+	// migration completed.
+	Verify() error
+	// Cleanup deletes the resources of the transfer.
+	// Returns true once they are gone.
+	Cleanup() (bool, error)
+}
+
+// TransferEngineFactory builds the engine transferring the PVCs of the task.
+type TransferEngineFactory func(t *Task) TransferEngine
+
+// Transfer engines selected by the transferEngine of the DVM spec, keyed by name.
+var transferEngines = map[string]TransferEngineFactory{
+	migapi.TransferEngineRsync: newRsyncTransferEngine,
+}
+
+// RegisterTransferEngine registers a transfer engine under the name selecting it
+// in the DVM spec. Engines must be registered before the controller starts.
+func RegisterTransferEngine(name string, factory TransferEngineFactory) {
+	transferEngines[name] = factory
+}
+
+// Get the names of the registered transfer engines, sorted.
+func getTransferEngineNames() []string {
+	names := []string{}
+	for name := range transferEngines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get the name of the transfer engine of the DVM, rsync when not set.
+func (t *Task) getTransferEngineName() string {
+	if t.Owner.Spec.TransferEngine == "" {
+		return migapi.TransferEngineRsync
+	}
+	return t.Owner.Spec.TransferEngine
+}
+
+// Get whether the PVCs are transferred by the rsync engine, which runs in the
+// dedicated phases of the rsync itineraries.
+func (t *Task) isRsyncTransferEngine() bool {
+	return t.getTransferEngineName() == migapi.TransferEngineRsync
+}
+
+// Get the transfer engine of the DVM.
+func (t *Task) getTransferEngine() (TransferEngine, error) {
+	name := t.getTransferEngineName()
+	factory, found := transferEngines[name]
+	if !found {
+		return nil, liberr.Wrap(&UnknownTransferEngineError{Name: name})
+	}
+	return factory(t), nil
+}
+
+// Delete the resources of a transfer engine other than rsync, along with the
+// Rsync resources, so that the failed and canceled itineraries clean up every
+// engine. Returns true once they are gone.
+func (t *Task) cleanupTransferEngine() (bool, error) {
+	if t.isRsyncTransferEngine() {
+		return true, nil
+	}
+	engine, err := t.getTransferEngine()
+	if err != nil {
+		t.Log.Info("Transfer engine is not registered, its resources are not cleaned up.",
+			"transferEngine", t.getTransferEngineName())
+		return true, nil
+	}
+	deleted, err := engine.Cleanup()
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	return deleted, nil
+}
+
+// The rsync transfer engine, Rsync client Pods on the source cluster connect
+// through Stunnel to Rsync transfer Pods on the destination cluster. Its
+// resources are created by the dedicated phases of the rsync itineraries,
+// from CreateRsyncRoute to WaitForRsyncTransferPodsRunning, which report the
+// state of the endpoint and of the Pods with their own conditions.
+type rsyncTransferEngine struct {
+	task *Task
+}
+
+func newRsyncTransferEngine(t *Task) TransferEngine {
+	return &rsyncTransferEngine{task: t}
+}
+
+// Prepare reports whether the Rsync transfer Pods are running.
+func (r *rsyncTransferEngine) Prepare() (bool, error) {
+	running, _, err := r.task.areRsyncTransferPodsRunning()
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	return running, nil
+}
+
+func (r *rsyncTransferEngine) Run() (bool, bool, []string, error) {
+	return r.task.runRsyncOperations()
+}
+
+func (r *rsyncTransferEngine) Verify() error {
+	return r.task.collectVerificationResults()
+}
+
+func (r *rsyncTransferEngine) Cleanup() (bool, error) {
+	err := r.task.deleteRsyncResources()
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	err, deleted := r.task.waitForRsyncResourcesDeleted()
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,90 @@
+package directvolumemigration
+
+import (
+	"testing"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+)
+
+type fakeTransferEngine struct {
+	cleanedUp bool
+}
+
+func (f *fakeTransferEngine) Prepare() (bool, error) {
+	return true, nil
+}
+
+func (f *fakeTransferEngine) Run() (bool, bool, []string, error) {
+	return true, false, nil, nil
+}
+
+func (f *fakeTransferEngine) Verify() error {
+	return nil
+}
+
+func (f *fakeTransferEngine) Cleanup() (bool, error) {
+	f.cleanedUp = true
+	return true, nil
+}
+
+func TestTask_getTransferItinerary(t *testing.T) {
+	engine := &fakeTransferEngine{}
+	RegisterTransferEngine("fake", func(t *Task) TransferEngine { return engine })
+	defer delete(transferEngines, "fake")
+	tests := []struct {
+		name       string
+		engine     string
+		verifyOnly bool
+		itinerary  string
+		want       string
+	}{
+		{name: "when not set, should use the rsync itinerary", want: VolumeMigration.Name},
+		{name: "when rsync, should use the rsync itinerary", engine: migapi.TransferEngineRsync, want: VolumeMigration.Name},
+		{name: "when rsync and verifyOnly, should use the rsync verify itinerary", engine: migapi.TransferEngineRsync, verifyOnly: true, want: VerifyOnlyMigration.Name},
+		{name: "when another engine, should use the engine itinerary", engine: "fake", want: EngineMigration.Name},
+		{name: "when another engine and verifyOnly, should use the engine verify itinerary", engine: "fake", verifyOnly: true, want: EngineVerifyOnlyMigration.Name},
+		{name: "when the verify itinerary is selected, should use the rsync verify itinerary", itinerary: VerifyOnlyMigration.Name, want: VerifyOnlyMigration.Name},
+		{name: "when the verify itinerary is selected with another engine, should use the engine verify itinerary", engine: "fake", itinerary: VerifyOnlyMigration.Name, want: EngineVerifyOnlyMigration.Name},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &Task{
+				Owner: &migapi.DirectVolumeMigration{
+					Spec: migapi.DirectVolumeMigrationSpec{TransferEngine: tt.engine, VerifyOnly: tt.verifyOnly, Itinerary: tt.itinerary},
+				},
+			}
+			if got := task.getTransferItinerary(); got.Name != tt.want {
+				t.Errorf("Task.getTransferItinerary() = %v, want %v", got.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestTask_getTransferEngine(t *testing.T) {
+	engine := &fakeTransferEngine{}
+	RegisterTransferEngine("fake", func(t *Task) TransferEngine { return engine })
+	defer delete(transferEngines, "fake")
+	task := &Task{
+		Log:   log.WithName("test-logger"),
+		Owner: &migapi.DirectVolumeMigration{},
+	}
+	if got, err := task.getTransferEngine(); err != nil {
+		t.Errorf("Task.getTransferEngine() error = %v, want the rsync engine", err)
+	} else if _, isRsync := got.(*rsyncTransferEngine); !isRsync {
+		t.Errorf("Task.getTransferEngine() = %T, want the rsync engine", got)
+	}
+	task.Owner.Spec.TransferEngine = "fake"
+	if got, err := task.getTransferEngine(); err != nil || got != engine {
+		t.Errorf("Task.getTransferEngine() = %v, %v, want the fake engine", got, err)
+	}
+	if deleted, err := task.cleanupTransferEngine(); err != nil || !deleted || !engine.cleanedUp {
+		t.Errorf("Task.cleanupTransferEngine() = %v, %v, want the fake engine cleaned up", deleted, err)
+	}
+	task.Owner.Spec.TransferEngine = "unknown"
+	if _, err := task.getTransferEngine(); err == nil || isRetryableError(err) {
+		t.Errorf("Task.getTransferEngine() error = %v, want a non retryable error", err)
+	}
+	if deleted, err := task.cleanupTransferEngine(); err != nil || !deleted {
+		t.Errorf("Task.cleanupTransferEngine() = %v, %v, want the unknown engine skipped", deleted, err)
+	}
+}
@@ -0,0 +1,177 @@
+package directvolumemigration
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// retryableError an error which tells whether the phase may succeed when run again.
+type retryableError interface {
+	error
+	Retryable() bool
+}
+
+// Get whether the phase which failed with the error may succeed when run again.
+// Errors which don't tell are not retryable, the migration fails.
+func isRetryableError(err error) bool {
+	var retryable retryableError
+	if errors.As(err, &retryable) {
+		return retryable.Retryable()
+	}
+	return false
+}
+
+// EndpointUnreachableError the Rsync transfer endpoint of a destination
+// namespace cannot be reached yet, e.g. its Service has no cluster IP. Permanent
+// when the endpoint can never be reached, e.g. its Service is headless.
+type EndpointUnreachableError struct {
+	Namespace    string
+	EndpointType string
+	Reason       string
+	Permanent    bool
+}
+
+func (e *EndpointUnreachableError) Error() string {
+	return fmt.Sprintf("%s endpoint of destination namespace %s is unreachable: %s", e.EndpointType, e.Namespace, e.Reason)
+}
+
+// Retryable the endpoint may become reachable once fully provisioned, unless permanent.
+func (e *EndpointUnreachableError) Retryable() bool {
+	return !e.Permanent
+}
+
+// DestinationFullError Rsync failed writing to full destination volumes.
+type DestinationFullError struct {
+	PVCs []string
+}
+
+func (e *DestinationFullError) Error() string {
+	return fmt.Sprintf("destination volume of PVC(s) [%s] is full", strings.Join(e.PVCs, ", "))
+}
+
+// Retryable the capacity of the destination PVCs must be increased first.
+func (e *DestinationFullError) Retryable() bool {
+	return false
+}
+
+// StorageClassMissingError the target StorageClass of a PVC doesn't exist on the destination cluster.
+type StorageClassMissingError struct {
+	StorageClass string
+	PVC          string
+}
+
+func (e *StorageClassMissingError) Error() string {
+	return fmt.Sprintf("target storage class %s of PVC %s not found on destination cluster", e.StorageClass, e.PVC)
+}
+
+// Retryable the destination PVC would never be bound.
+func (e *StorageClassMissingError) Retryable() bool {
+	return false
+}
+
+// DestinationPVCExistsError a destination PVC already exists and the
+// existingPVCPolicy is fail.
+type DestinationPVCExistsError struct {
+	PVC string
+}
+
+func (e *DestinationPVCExistsError) Error() string {
+	return fmt.Sprintf("destination PVC %s already exists", e.PVC)
+}
+
+// Retryable the destination PVC must be deleted or the policy changed first.
+func (e *DestinationPVCExistsError) Retryable() bool {
+	return false
+}
+
+// DestinationPVCIncompatibleError a destination PVC already exists and cannot
+// be adopted, it doesn't fit the source PVC.
+type DestinationPVCIncompatibleError struct {
+	PVC      string
+	Problems []string
+}
+
+func (e *DestinationPVCIncompatibleError) Error() string {
+	return fmt.Sprintf("existing destination PVC %s is not compatible: %s", e.PVC, strings.Join(e.Problems, ", "))
+}
+
+// Retryable the destination PVC must be fixed or deleted first.
+func (e *DestinationPVCIncompatibleError) Retryable() bool {
+	return false
+}
+
+// CheckpointMissingError the changes of a PVC cannot be transferred since a
+// checkpoint, its destination PVC holds none.
+type CheckpointMissingError struct {
+	PVC    string
+	Reason string
+}
+
+func (e *CheckpointMissingError) Error() string {
+	return fmt.Sprintf("no checkpoint to transfer the changes of destination PVC %s since: %s", e.PVC, e.Reason)
+}
+
+// Retryable a full transfer recording a checkpoint must run first.
+func (e *CheckpointMissingError) Retryable() bool {
+	return false
+}
+
+// SourcePVCTerminatingError a source PVC to migrate is being deleted.
+type SourcePVCTerminatingError struct {
+	PVC string
+}
+
+func (e *SourcePVCTerminatingError) Error() string {
+	return fmt.Sprintf("source PVC %s is being terminated", e.PVC)
+}
+
+// Retryable the data of the source PVC is going away.
+func (e *SourcePVCTerminatingError) Retryable() bool {
+	return false
+}
+
+// UnknownTransferEngineError no transfer engine is registered with the name
+// selected by the DVM spec.
+type UnknownTransferEngineError struct {
+	Name string
+}
+
+func (e *UnknownTransferEngineError) Error() string {
+	return fmt.Sprintf("transfer engine %s is not registered", e.Name)
+}
+
+// Retryable the engines are registered before the controller starts.
+func (e *UnknownTransferEngineError) Retryable() bool {
+	return false
+}
+
+// OwnerMigrationUnavailableError the MigMigration owning the migration cannot
+// be fetched, e.g. the API server is unavailable.
+type OwnerMigrationUnavailableError struct {
+	Reason string
+}
+
+func (e *OwnerMigrationUnavailableError) Error() string {
+	return fmt.Sprintf("MigMigration owning the migration cannot be fetched: %s", e.Reason)
+}
+
+// Retryable the MigMigration may be fetched on the next reconcile.
+func (e *OwnerMigrationUnavailableError) Retryable() bool {
+	return true
+}
+
+// NoEndpointTypeError no endpoint type of the auto endpoint type can expose the
+// Rsync transfer Pods of the destination cluster.
+type NoEndpointTypeError struct {
+	Reason string
+}
+
+func (e *NoEndpointTypeError) Error() string {
+	return fmt.Sprintf("no usable endpoint type was found for the auto endpoint type: %s", e.Reason)
+}
+
+// Retryable the capabilities of the destination cluster don't change during the migration.
+func (e *NoEndpointTypeError) Retryable() bool {
+	return false
+}
@@ -0,0 +1,59 @@
+package directvolumemigration
+
+import (
+	"fmt"
+	"testing"
+
+	liberr "github.com/konveyor/controller/pkg/error"
+)
+
+func Test_isRetryableError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "when the endpoint is unreachable, should retry",
+			err:  liberr.Wrap(&EndpointUnreachableError{Namespace: "ns", EndpointType: EndpointTypeClusterIP, Reason: "no cluster IP"}),
+			want: true,
+		},
+		{
+			name: "when the endpoint is permanently unreachable, should not retry",
+			err:  liberr.Wrap(&EndpointUnreachableError{Namespace: "ns", EndpointType: EndpointTypeClusterIP, Reason: "headless", Permanent: true}),
+			want: false,
+		},
+		{
+			name: "when the destination is full, should not retry",
+			err:  liberr.Wrap(&DestinationFullError{PVCs: []string{"ns/pvc-1"}}),
+			want: false,
+		},
+		{
+			name: "when the storage class is missing, should not retry",
+			err:  liberr.Wrap(&StorageClassMissingError{StorageClass: "gp2", PVC: "ns/pvc-1"}),
+			want: false,
+		},
+		{
+			name: "when the source PVC is terminating, should not retry",
+			err:  liberr.Wrap(&SourcePVCTerminatingError{PVC: "ns/pvc-1"}),
+			want: false,
+		},
+		{
+			name: "when no endpoint type is available, should not retry",
+			err:  liberr.Wrap(&NoEndpointTypeError{Reason: "the Route API is not available"}),
+			want: false,
+		},
+		{
+			name: "when the error is not typed, should not retry",
+			err:  liberr.Wrap(fmt.Errorf("unexpected error")),
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetryableError(tt.err); got != tt.want {
+				t.Errorf("isRetryableError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package directvolumemigration
+
+import (
+	"math"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TransferRateSmoothingFactor weight of the latest observed transfer rate in the
+// smoothed transfer rate estimating the completion of the migration.
+const TransferRateSmoothingFactor = 0.2
+
+// transfer rate printed by rsync --info=progress2, units are powers of 1024
+var transferRateRegex = regexp.MustCompile(`^([\d.]+)([kMGT]?)B/s$`)
+
+var transferRateUnits = map[string]float64{
+	"":  1,
+	"k": 1 << 10,
+	"M": 1 << 20,
+	"G": 1 << 30,
+	"T": 1 << 40,
+}
+
+// parseTransferRate returns the transfer rate in bytes per second
+func parseTransferRate(rate string) (float64, bool) {
+	matched := transferRateRegex.FindStringSubmatch(strings.TrimSpace(rate))
+	if len(matched) != 3 {
+		return 0, false
+	}
+	value, err := strconv.ParseFloat(matched[1], 64)
+	if err != nil {
+		return 0, false
+	}
+	return value * transferRateUnits[matched[2]], true
+}
+
+// updateEstimatedCompletion estimates the completion of the Rsync transfer from
+// the bytes left to transfer and the transfer rate of the running Rsync Pods. The
+// rate is smoothed over reconciles for the estimate not to swing with each rate
+// observed. The estimate is omitted when the size of a PVC left to transfer or
+// the transfer rate are unknown.
+func (t *Task) updateEstimatedCompletion(now time.Time) {
+	status := &t.Owner.Status
+	remaining, known := t.getRemainingTransferBytes()
+	if !known || remaining == 0 {
+		status.EstimatedCompletionTimestamp = nil
+		return
+	}
+	observed, found := float64(0), false
+	for _, pod := range status.RunningPods {
+		if rate, ok := parseTransferRate(pod.LastObservedTransferRate); ok {
+			observed += rate
+			found = true
+		}
+	}
+	smoothed := float64(0)
+	if status.SmoothedTransferRate != nil {
+		smoothed = float64(status.SmoothedTransferRate.Value())
+	}
+	if found && observed > 0 {
+		if smoothed > 0 {
+			smoothed = TransferRateSmoothingFactor*observed + (1-TransferRateSmoothingFactor)*smoothed
+		} else {
+			smoothed = observed
+		}
+		status.SmoothedTransferRate = resource.NewQuantity(int64(math.Round(smoothed)), resource.BinarySI)
+	}
+	if smoothed < 1 {
+		status.EstimatedCompletionTimestamp = nil
+		return
+	}
+	eta := now.Add(time.Duration(float64(remaining) / smoothed * float64(time.Second))).Truncate(time.Second)
+	status.EstimatedCompletionTimestamp = &metav1.Time{Time: eta}
+}
+
+// getRemainingTransferBytes returns the bytes left to transfer, estimated from the
+// used capacity of the PVCs and the progress of their Rsync Pods. Returns false
+// when the size of a PVC not transferred yet is unknown.
+func (t *Task) getRemainingTransferBytes() (int64, bool) {
+	status := t.Owner.Status
+	progress := map[string]int64{}
+	for _, pods := range [][]*migapi.PodProgress{status.RunningPods, status.PendingPods, status.FailedPods} {
+		for _, pod := range pods {
+			if pod.PVCReference == nil {
+				continue
+			}
+			percent, err := strconv.Atoi(strings.TrimSuffix(pod.LastObservedProgressPercent, "%"))
+			if err == nil && percent > 0 && percent <= 100 {
+				progress[path.Join(pod.PVCReference.Namespace, pod.PVCReference.Name)] = int64(percent)
+			}
+		}
+	}
+	remaining := int64(0)
+	for _, operation := range status.RsyncOperations {
+		if operation.Succeeded {
+			continue
+		}
+		size := operation.UsedCapacity
+		if size == nil {
+			size = operation.Capacity
+		}
+		if size == nil {
+			return 0, false
+		}
+		remaining += size.Value() * (100 - progress[operation.String()]) / 100
+	}
+	return remaining, true
+}
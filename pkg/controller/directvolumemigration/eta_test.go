@@ -0,0 +1,111 @@
+package directvolumemigration
+
+import (
+	"testing"
+	"time"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func Test_parseTransferRate(t *testing.T) {
+	tests := []struct {
+		rate   string
+		want   float64
+		wantOk bool
+	}{
+		{rate: "512.00kB/s", want: 512 * 1024, wantOk: true},
+		{rate: "10.50MB/s", want: 10.5 * 1024 * 1024, wantOk: true},
+		{rate: "1.00GB/s", want: 1024 * 1024 * 1024, wantOk: true},
+		{rate: "", want: 0, wantOk: false},
+		{rate: "fast", want: 0, wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rate, func(t *testing.T) {
+			got, ok := parseTransferRate(tt.rate)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("parseTransferRate() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestTask_updateEstimatedCompletion(t *testing.T) {
+	now := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+	gi := resource.MustParse("1Gi")
+	pvc := func(name string) *corev1.ObjectReference {
+		return &corev1.ObjectReference{Namespace: "ns", Name: name}
+	}
+	operations := func(size *resource.Quantity) []*migapi.RsyncOperation {
+		return []*migapi.RsyncOperation{
+			{PVCReference: pvc("pvc-1"), UsedCapacity: size},
+			{PVCReference: pvc("pvc-2"), UsedCapacity: size, Succeeded: true},
+		}
+	}
+	running := func(percent string, rate string) []*migapi.PodProgress {
+		return []*migapi.PodProgress{
+			{PVCReference: pvc("pvc-1"), LastObservedProgressPercent: percent, LastObservedTransferRate: rate},
+		}
+	}
+	tests := []struct {
+		name        string
+		status      migapi.DirectVolumeMigrationStatus
+		wantETA     *time.Time
+		wantRateMiB int64
+	}{
+		{
+			name: "when the size of the PVCs is unknown, should omit the estimate",
+			status: migapi.DirectVolumeMigrationStatus{
+				RsyncOperations: operations(nil),
+				RunningPods:     running("50%", "1.00MB/s"),
+			},
+		},
+		{
+			name: "when no transfer rate was observed, should omit the estimate",
+			status: migapi.DirectVolumeMigrationStatus{
+				RsyncOperations: operations(&gi),
+				RunningPods:     running("50%", ""),
+			},
+		},
+		{
+			name: "when a first transfer rate is observed, should estimate from it",
+			status: migapi.DirectVolumeMigrationStatus{
+				RsyncOperations: operations(&gi),
+				RunningPods:     running("50%", "1.00MB/s"),
+			},
+			wantETA:     timePtr(now.Add(512 * time.Second)),
+			wantRateMiB: 1,
+		},
+		{
+			name: "when a transfer rate was observed before, should smooth the rate",
+			status: migapi.DirectVolumeMigrationStatus{
+				RsyncOperations:      operations(&gi),
+				RunningPods:          running("50%", "6.00MB/s"),
+				SmoothedTransferRate: resource.NewQuantity(1024*1024, resource.BinarySI),
+			},
+			wantETA:     timePtr(now.Add(256 * time.Second)),
+			wantRateMiB: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &Task{Owner: &migapi.DirectVolumeMigration{Status: tt.status}}
+			task.updateEstimatedCompletion(now)
+			got := task.Owner.Status.EstimatedCompletionTimestamp
+			if (got == nil) != (tt.wantETA == nil) || (got != nil && !got.Time.Equal(*tt.wantETA)) {
+				t.Errorf("Task.updateEstimatedCompletion() estimate = %v, want %v", got, tt.wantETA)
+			}
+			if tt.wantRateMiB > 0 {
+				rate := task.Owner.Status.SmoothedTransferRate
+				if rate == nil || rate.Value() != tt.wantRateMiB*1024*1024 {
+					t.Errorf("Task.updateEstimatedCompletion() rate = %v, want %vMi", rate, tt.wantRateMiB)
+				}
+			}
+		})
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,147 @@
+package directvolumemigration
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"time"
+
+	liberr "github.com/konveyor/controller/pkg/error"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// DestinationPVCExpansionTimeout time allowed for destination PVCs to be expanded
+const DestinationPVCExpansionTimeout = 10 * time.Minute
+
+// DestinationPVCFilesystemOverhead percentage added to the used capacity of the
+// source PVC for the metadata and reserved blocks of the destination file system
+const DestinationPVCFilesystemOverhead = 10
+
+// States of the expansion of a destination PVC
+const (
+	// PVCExpansionRequired the requested size of the PVC is smaller than the source data
+	PVCExpansionRequired = "Required"
+	// PVCExpanding the expansion of the PVC was requested and is in progress
+	PVCExpanding = "Expanding"
+	// PVCExpanded the PVC fits the source data
+	PVCExpanded = "Expanded"
+)
+
+// getPVCExpansionState returns the state of the expansion of a destination PVC to
+// the required capacity. The expansion of the file system of the volume is
+// completed by the kubelet once mounted by the Rsync transfer Pod, a PVC pending
+// the resize of its file system is considered expanded.
+func getPVCExpansionState(pvc *corev1.PersistentVolumeClaim, required resource.Quantity) string {
+	request := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	if request.Cmp(required) < 0 {
+		return PVCExpansionRequired
+	}
+	capacity := pvc.Status.Capacity[corev1.ResourceStorage]
+	if capacity.Cmp(required) >= 0 {
+		return PVCExpanded
+	}
+	for _, condition := range pvc.Status.Conditions {
+		if condition.Type == corev1.PersistentVolumeClaimFileSystemResizePending &&
+			condition.Status == corev1.ConditionTrue {
+			return PVCExpanded
+		}
+	}
+	return PVCExpanding
+}
+
+// getRequiredDestinationCapacity returns the capacity the destination PVC needs to
+// fit the data of the source PVC, the used capacity reported by MigAnalytic with
+// the margin of the file system overhead, or the provisioned capacity of the
+// source PVC when the usage is not analyzed. The margin never raises the capacity
+// above the provisioned capacity of the source PVC the data already fits in.
+// Returns nil when the capacity of the source PVC is unknown.
+func (t *Task) getRequiredDestinationCapacity(namespace string, name string) *resource.Quantity {
+	for _, operation := range t.Owner.Status.RsyncOperations {
+		if operation.PVCReference == nil ||
+			operation.PVCReference.Namespace != namespace || operation.PVCReference.Name != name {
+			continue
+		}
+		if operation.UsedCapacity == nil {
+			return operation.Capacity
+		}
+		used := operation.UsedCapacity.Value()
+		required := resource.NewQuantity(used+used*DestinationPVCFilesystemOverhead/100, resource.BinarySI)
+		if operation.Capacity != nil && required.Cmp(*operation.Capacity) > 0 {
+			return operation.Capacity
+		}
+		return required
+	}
+	return nil
+}
+
+// expandDestinationPVCs requests the expansion of the destination PVCs smaller than
+// the data of their source PVC. PVCs which storage class doesn't allow volume
+// expansion are not updated.
+// Returns the PVCs still expanding and the PVCs which cannot be expanded.
+func (t *Task) expandDestinationPVCs() ([]string, []string, error) {
+	expanding, notExpandable := []string{}, []string{}
+	destClient, err := t.getDestinationClient()
+	if err != nil {
+		return expanding, notExpandable, liberr.Wrap(err)
+	}
+	allowExpansion := map[string]bool{}
+	for _, pvc := range t.Owner.Spec.PersistentVolumeClaims {
+		required := t.getRequiredDestinationCapacity(pvc.Namespace, pvc.Name)
+		if required == nil {
+			t.Log.Info("Capacity of source PVC unknown, not expanding destination PVC",
+				"persistentVolumeClaim", path.Join(pvc.Namespace, pvc.Name))
+			continue
+		}
+		destNs := pvc.Namespace
+		if pvc.TargetNamespace != "" {
+			destNs = pvc.TargetNamespace
+		}
+		destPVC := corev1.PersistentVolumeClaim{}
+		err := destClient.Get(context.TODO(),
+			types.NamespacedName{Namespace: destNs, Name: pvc.Name}, &destPVC)
+		if err != nil {
+			return expanding, notExpandable, liberr.Wrap(err)
+		}
+		switch getPVCExpansionState(&destPVC, *required) {
+		case PVCExpanded:
+			continue
+		case PVCExpanding:
+			expanding = append(expanding, path.Join(destNs, pvc.Name))
+			continue
+		}
+		scName := ""
+		if destPVC.Spec.StorageClassName != nil {
+			scName = *destPVC.Spec.StorageClassName
+		}
+		if _, found := allowExpansion[scName]; !found && scName != "" {
+			sc := storagev1.StorageClass{}
+			err := destClient.Get(context.TODO(), types.NamespacedName{Name: scName}, &sc)
+			if err != nil && !k8serror.IsNotFound(err) {
+				return expanding, notExpandable, liberr.Wrap(err)
+			}
+			allowExpansion[scName] = sc.AllowVolumeExpansion != nil && *sc.AllowVolumeExpansion
+		}
+		if !allowExpansion[scName] {
+			notExpandable = append(notExpandable,
+				fmt.Sprintf("%s: storage class [%s] does not allow volume expansion", path.Join(destNs, pvc.Name), scName))
+			continue
+		}
+		t.Log.Info("Requesting expansion of destination PVC to fit the source data",
+			"destPersistentVolumeClaim", path.Join(destNs, pvc.Name),
+			"requestedCapacity", required.String())
+		if destPVC.Spec.Resources.Requests == nil {
+			destPVC.Spec.Resources.Requests = corev1.ResourceList{}
+		}
+		destPVC.Spec.Resources.Requests[corev1.ResourceStorage] = required.DeepCopy()
+		err = destClient.Update(context.TODO(), &destPVC)
+		if err != nil {
+			return expanding, notExpandable, liberr.Wrap(err)
+		}
+		expanding = append(expanding, path.Join(destNs, pvc.Name))
+	}
+	return expanding, notExpandable, nil
+}
@@ -0,0 +1,95 @@
+package directvolumemigration
+
+import (
+	"testing"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func Test_getPVCExpansionState(t *testing.T) {
+	getPVC := func(request string, capacity string, conditions ...corev1.PersistentVolumeClaimConditionType) *corev1.PersistentVolumeClaim {
+		pvc := &corev1.PersistentVolumeClaim{
+			Spec: corev1.PersistentVolumeClaimSpec{
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(request)},
+				},
+			},
+			Status: corev1.PersistentVolumeClaimStatus{
+				Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(capacity)},
+			},
+		}
+		for _, condition := range conditions {
+			pvc.Status.Conditions = append(pvc.Status.Conditions,
+				corev1.PersistentVolumeClaimCondition{Type: condition, Status: corev1.ConditionTrue})
+		}
+		return pvc
+	}
+	tests := []struct {
+		name string
+		pvc  *corev1.PersistentVolumeClaim
+		want string
+	}{
+		{
+			name: "when the requested size is smaller than the source data, should require expansion",
+			pvc:  getPVC("1Gi", "1Gi"),
+			want: PVCExpansionRequired,
+		},
+		{
+			name: "when the capacity fits the source data, should be expanded",
+			pvc:  getPVC("3Gi", "3Gi"),
+			want: PVCExpanded,
+		},
+		{
+			name: "when the expansion was requested and the volume is resizing, should be expanding",
+			pvc:  getPVC("2Gi", "1Gi", corev1.PersistentVolumeClaimResizing),
+			want: PVCExpanding,
+		},
+		{
+			name: "when only the file system resize is pending, should be expanded",
+			pvc:  getPVC("2Gi", "1Gi", corev1.PersistentVolumeClaimFileSystemResizePending),
+			want: PVCExpanded,
+		},
+		{
+			name: "when the expansion completed, should be expanded",
+			pvc:  getPVC("2Gi", "2Gi"),
+			want: PVCExpanded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getPVCExpansionState(tt.pvc, resource.MustParse("2Gi")); got != tt.want {
+				t.Errorf("getPVCExpansionState() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTask_getRequiredDestinationCapacity(t *testing.T) {
+	capacity, used, full := resource.MustParse("10Gi"), resource.MustParse("4Gi"), resource.MustParse("9800Mi")
+	task := &Task{
+		Owner: &migapi.DirectVolumeMigration{
+			Status: migapi.DirectVolumeMigrationStatus{
+				RsyncOperations: []*migapi.RsyncOperation{
+					{PVCReference: &corev1.ObjectReference{Namespace: "ns", Name: "analyzed"}, Capacity: &capacity, UsedCapacity: &used},
+					{PVCReference: &corev1.ObjectReference{Namespace: "ns", Name: "provisioned"}, Capacity: &capacity},
+					{PVCReference: &corev1.ObjectReference{Namespace: "ns", Name: "full"}, Capacity: &capacity, UsedCapacity: &full},
+				},
+			},
+		},
+	}
+	withOverhead := resource.MustParse("4724464025")
+	if got := task.getRequiredDestinationCapacity("ns", "analyzed"); got == nil || got.Cmp(withOverhead) != 0 {
+		t.Errorf("Task.getRequiredDestinationCapacity() = %v, want the used capacity with the file system overhead %v", got, withOverhead.String())
+	}
+	if got := task.getRequiredDestinationCapacity("ns", "full"); got == nil || got.Cmp(capacity) != 0 {
+		t.Errorf("Task.getRequiredDestinationCapacity() = %v, want at most the provisioned capacity %v", got, capacity.String())
+	}
+	if got := task.getRequiredDestinationCapacity("ns", "provisioned"); got == nil || got.Cmp(capacity) != 0 {
+		t.Errorf("Task.getRequiredDestinationCapacity() = %v, want the provisioned capacity %v", got, capacity.String())
+	}
+	if got := task.getRequiredDestinationCapacity("ns", "unknown"); got != nil {
+		t.Errorf("Task.getRequiredDestinationCapacity() = %v, want nil for an unknown PVC", got)
+	}
+}
@@ -0,0 +1,132 @@
+package directvolumemigration
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	liberr "github.com/konveyor/controller/pkg/error"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Rsync filter files mounted in the Rsync client Pods.
+const (
+	DefaultRsyncFilterKey  = "filter"
+	RsyncFilterMountPath   = "/etc/rsync-filter"
+	RsyncFilterFileKey     = "filter"
+	RsyncFilterExcludesKey = "excludes"
+)
+
+// Get whether the DVM sets filter rules for the Rsync transfer.
+func (t *Task) hasRsyncFilter() bool {
+	filter := t.Owner.Spec.RsyncFilter
+	return filter != nil && (filter.ConfigMapRef != nil || len(filter.Excludes) > 0)
+}
+
+// Get the key of the referenced ConfigMap holding the filter file.
+func getRsyncFilterKey(filter *migapi.RsyncFilter) string {
+	if filter.Key != "" {
+		return filter.Key
+	}
+	return DefaultRsyncFilterKey
+}
+
+// Get the filter files of the Rsync transfer, the filter file read from the
+// referenced ConfigMap and the inline excludes, one pattern per line.
+func (t *Task) getRsyncFilterData() (map[string]string, error) {
+	data := map[string]string{}
+	filter := t.Owner.Spec.RsyncFilter
+	if filter == nil {
+		return data, nil
+	}
+	if filter.ConfigMapRef != nil {
+		configMap, err := migapi.GetConfigMap(t.Client, filter.ConfigMapRef)
+		if err != nil {
+			return nil, liberr.Wrap(err)
+		}
+		if configMap == nil {
+			return nil, liberr.Wrap(fmt.Errorf("rsync filter ConfigMap %s not found",
+				path.Join(filter.ConfigMapRef.Namespace, filter.ConfigMapRef.Name)))
+		}
+		rules, found := configMap.Data[getRsyncFilterKey(filter)]
+		if !found {
+			return nil, liberr.Wrap(fmt.Errorf("rsync filter ConfigMap %s has no key %s",
+				path.Join(configMap.Namespace, configMap.Name), getRsyncFilterKey(filter)))
+		}
+		data[RsyncFilterFileKey] = rules
+	}
+	if len(filter.Excludes) > 0 {
+		data[RsyncFilterExcludesKey] = strings.Join(filter.Excludes, "\n") + "\n"
+	}
+	return data, nil
+}
+
+// Get the Rsync options applying the filter rules of the DVM. The inline
+// excludes come first so that they take precedence over the rules of the
+// filter file, Rsync acting on the first matching rule.
+func getRsyncFilterOptions(filter *migapi.RsyncFilter) []string {
+	options := []string{}
+	if filter == nil {
+		return options
+	}
+	if len(filter.Excludes) > 0 {
+		options = append(options,
+			fmt.Sprintf("--exclude-from=%s", path.Join(RsyncFilterMountPath, RsyncFilterExcludesKey)))
+	}
+	if filter.ConfigMapRef != nil {
+		// Rsync accepts an underscore in place of the space after the rule
+		// keyword, the Rsync command being joined by spaces.
+		options = append(options,
+			fmt.Sprintf("--filter=merge_%s", path.Join(RsyncFilterMountPath, RsyncFilterFileKey)))
+	}
+	return options
+}
+
+// Create the ConfigMap holding the filter files of the Rsync transfer in each
+// source namespace, mounted in the Rsync client Pods.
+func (t *Task) createRsyncFilterConfig() error {
+	if !t.hasRsyncFilter() {
+		return nil
+	}
+	data, err := t.getRsyncFilterData()
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	srcClient, err := t.getSourceClient()
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	for bothNs := range t.getPVCNamespaceMap() {
+		configMap := corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: getSourceNs(bothNs),
+				Name:      DirectVolumeMigrationRsyncFilter,
+				Labels:    t.buildDVMLabels(),
+			},
+			Data: data,
+		}
+		t.Log.Info("Creating Rsync filter ConfigMap on source cluster",
+			"configMap", path.Join(configMap.Namespace, configMap.Name))
+		err = srcClient.Create(context.TODO(), &configMap)
+		if k8serror.IsAlreadyExists(err) {
+			existing := corev1.ConfigMap{}
+			err = srcClient.Get(context.TODO(),
+				types.NamespacedName{Namespace: configMap.Namespace, Name: configMap.Name}, &existing)
+			if err != nil {
+				return liberr.Wrap(err)
+			}
+			existing.Labels = configMap.Labels
+			existing.Data = data
+			err = srcClient.Update(context.TODO(), &existing)
+		}
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package directvolumemigration
+
+import (
+	"reflect"
+	"testing"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func Test_getRsyncFilterOptions(t *testing.T) {
+	ref := &corev1.ObjectReference{Namespace: "openshift-migration", Name: "rules"}
+	tests := []struct {
+		name   string
+		filter *migapi.RsyncFilter
+		want   []string
+	}{
+		{
+			name:   "when no filter is set, should return no options",
+			filter: nil,
+			want:   []string{},
+		},
+		{
+			name:   "when only a ConfigMap is referenced, should merge the filter file",
+			filter: &migapi.RsyncFilter{ConfigMapRef: ref},
+			want:   []string{"--filter=merge_/etc/rsync-filter/filter"},
+		},
+		{
+			name:   "when only inline excludes are set, should exclude from the excludes file",
+			filter: &migapi.RsyncFilter{Excludes: []string{"*.tmp"}},
+			want:   []string{"--exclude-from=/etc/rsync-filter/excludes"},
+		},
+		{
+			name:   "when both are set, should evaluate the inline excludes first",
+			filter: &migapi.RsyncFilter{ConfigMapRef: ref, Excludes: []string{"*.tmp", "cache/"}},
+			want: []string{
+				"--exclude-from=/etc/rsync-filter/excludes",
+				"--filter=merge_/etc/rsync-filter/filter",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getRsyncFilterOptions(tt.filter); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getRsyncFilterOptions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package directvolumemigration
+
+import (
+	"time"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Get whether a failed Rsync attempt of an operation is tolerated by the
+// RsyncErrorGraceWindow of the DVM, it is then retried without counting
+// against the BackOffLimit. The window starts with the first failed attempt of
+// the operation, which is recorded. No failure is tolerated when the window
+// isn't set, failing as soon as the BackOffLimit is reached.
+func (t *Task) isRsyncFailureTolerated(operation *migapi.RsyncOperation) bool {
+	now := time.Now()
+	if operation.FirstFailureTimestamp == nil {
+		operation.FirstFailureTimestamp = &metav1.Time{Time: now}
+	}
+	window := t.Owner.Spec.RsyncErrorGraceWindow
+	if window == nil || window.Duration <= 0 {
+		return false
+	}
+	return now.Sub(operation.FirstFailureTimestamp.Time) <= window.Duration
+}
+
+// getCountedRsyncAttempts returns the attempts of an Rsync operation counted
+// against the BackOffLimit, the attempts retried within the grace window are not.
+func getCountedRsyncAttempts(operation migapi.RsyncOperation) int {
+	return operation.CurrentAttempt - operation.ToleratedAttempts
+}
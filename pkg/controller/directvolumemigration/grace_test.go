@@ -0,0 +1,51 @@
+package directvolumemigration
+
+import (
+	"testing"
+	"time"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTask_isRsyncFailureTolerated(t *testing.T) {
+	minute := &metav1.Duration{Duration: time.Minute}
+	recent := metav1.NewTime(time.Now().Add(-30 * time.Second))
+	past := metav1.NewTime(time.Now().Add(-2 * time.Minute))
+	tests := []struct {
+		name         string
+		window       *metav1.Duration
+		firstFailure *metav1.Time
+		want         bool
+	}{
+		{name: "when not set, should count the first failure", want: false},
+		{name: "when set, should tolerate the first failure", window: minute, want: true},
+		{name: "when within the window, should tolerate the failure", window: minute, firstFailure: &recent, want: true},
+		{name: "when beyond the window, should count the failure", window: minute, firstFailure: &past, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &Task{
+				Owner: &migapi.DirectVolumeMigration{
+					Spec: migapi.DirectVolumeMigrationSpec{RsyncErrorGraceWindow: tt.window},
+				},
+			}
+			operation := &migapi.RsyncOperation{FirstFailureTimestamp: tt.firstFailure}
+			if got := task.isRsyncFailureTolerated(operation); got != tt.want {
+				t.Errorf("isRsyncFailureTolerated() = %v, want %v", got, tt.want)
+			}
+			if operation.FirstFailureTimestamp == nil {
+				t.Errorf("isRsyncFailureTolerated() didn't record the first failure")
+			} else if tt.firstFailure != nil && !operation.FirstFailureTimestamp.Equal(tt.firstFailure) {
+				t.Errorf("isRsyncFailureTolerated() first failure = %v, want %v kept", operation.FirstFailureTimestamp, tt.firstFailure)
+			}
+		})
+	}
+}
+
+func Test_getCountedRsyncAttempts(t *testing.T) {
+	operation := migapi.RsyncOperation{CurrentAttempt: 5, ToleratedAttempts: 3}
+	if got := getCountedRsyncAttempts(operation); got != 2 {
+		t.Errorf("getCountedRsyncAttempts() = %v, want 2", got)
+	}
+}
@@ -0,0 +1,321 @@
+package directvolumemigration
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	liberr "github.com/konveyor/controller/pkg/error"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	migevent "github.com/konveyor/mig-controller/pkg/event"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// HookConsistentTerminationMessage termination message of the container of a
+// PreTransfer hook confirming the source data is consistent.
+const HookConsistentTerminationMessage = "consistent"
+
+// Run the MigHook attached to the DVM for the given hook phase.
+// Returns whether the hook has completed and the failure reason when the hook Job failed.
+func (t *Task) runHooks(hookPhase string) (bool, string, error) {
+	hook := migapi.MigPlanHook{}
+	for _, h := range t.Owner.Spec.Hooks {
+		if h.Phase == hookPhase {
+			hook = h
+		}
+	}
+	if hook.Reference == nil {
+		t.Log.Info("No hook attached to DVM for HookPhase, continuing.",
+			"hookPhase", hookPhase)
+		return true, "", nil
+	}
+
+	t.Log.Info("Found MigHook ref attached for phase, starting hook job.",
+		"migHook", path.Join(hook.Reference.Namespace, hook.Reference.Name),
+		"migHookPhase", hookPhase)
+	migHook := migapi.MigHook{}
+	err := t.Client.Get(
+		context.TODO(),
+		types.NamespacedName{
+			Name:      hook.Reference.Name,
+			Namespace: hook.Reference.Namespace,
+		},
+		&migHook)
+	if err != nil {
+		return false, "", liberr.Wrap(err)
+	}
+
+	client, err := t.getHookClient(migHook)
+	if err != nil {
+		return false, "", liberr.Wrap(err)
+	}
+
+	svc := corev1.ServiceAccount{}
+	ref := types.NamespacedName{
+		Namespace: hook.ExecutionNamespace,
+		Name:      hook.ServiceAccount,
+	}
+	err = client.Get(context.TODO(), ref, &svc)
+	if err != nil {
+		return false, "", liberr.Wrap(err)
+	}
+
+	job, err := t.prepareHookJob(hook, migHook, client)
+	if err != nil {
+		return false, "", liberr.Wrap(err)
+	}
+
+	return t.ensureHookJob(job, hook, migHook, client)
+}
+
+// Create the hook Job when not found and report its state.
+// Returns whether the Job has succeeded and the failure reason when it failed.
+// When RequireHookConsistency is set, the PreTransfer hook only succeeds once
+// its container terminated with the HookConsistentTerminationMessage.
+func (t *Task) ensureHookJob(job *batchv1.Job, hook migapi.MigPlanHook, migHook migapi.MigHook, client k8sclient.Client) (bool, string, error) {
+	runningJob, err := migHook.GetPhaseJob(client, hook.Phase, string(t.Owner.UID))
+	if err != nil {
+		return false, "", liberr.Wrap(err)
+	}
+	if runningJob == nil {
+		t.Log.Info("Creating Job for MigHook",
+			"migHook", path.Join(migHook.Namespace, migHook.Name),
+			"migHookPhase", hook.Phase)
+		err = client.Create(context.TODO(), job)
+		if err != nil {
+			return false, "", liberr.Wrap(err)
+		}
+		t.setHookStatus(hook.Phase, job, migapi.HookRunning, "", false)
+		return false, "", nil
+	}
+
+	// Logs abnormal events for Hook Jobs if any are found
+	migevent.LogAbnormalEventsForResource(
+		client, t.Log,
+		"Found abnormal event for Hook Job",
+		types.NamespacedName{Namespace: runningJob.Namespace, Name: runningJob.Name},
+		runningJob.UID, "Job")
+
+	switch {
+	case runningJob.Status.Failed >= migapi.HookJobFailedLimit,
+		len(runningJob.Status.Conditions) > 0 && runningJob.Status.Conditions[0].Reason == migapi.BackoffLimitExceededError:
+		reason := fmt.Sprintf("Hook job %s/%s failed.", runningJob.Namespace, runningJob.Name)
+		t.setHookStatus(hook.Phase, runningJob, migapi.HookFailed, reason, false)
+		return false, reason, nil
+	case runningJob.Status.Succeeded == 1:
+		message, err := getHookTerminationMessage(client, runningJob)
+		if err != nil {
+			return false, "", liberr.Wrap(err)
+		}
+		consistent := message == HookConsistentTerminationMessage
+		if hook.Phase == migapi.PreTransferHookPhase && t.Owner.Spec.RequireHookConsistency && !consistent {
+			reason := fmt.Sprintf("Hook job %s/%s completed without confirming the source data is consistent, the transfer was not started.",
+				runningJob.Namespace, runningJob.Name)
+			t.setHookStatus(hook.Phase, runningJob, migapi.HookFailed, reason, false)
+			return false, reason, nil
+		}
+		t.Log.Info("Hook Job succeeded.",
+			"job", path.Join(runningJob.Namespace, runningJob.Name),
+			"consistent", consistent)
+		t.setHookStatus(hook.Phase, runningJob, migapi.HookSucceeded, message, consistent)
+		return true, "", nil
+	default:
+		t.Log.Info("Hook Job is running. Waiting.",
+			"job", path.Join(runningJob.Namespace, runningJob.Name))
+		t.setHookStatus(hook.Phase, runningJob, migapi.HookRunning, "", false)
+		return false, "", nil
+	}
+}
+
+// Get the termination message of the container of the succeeded Pod of a hook
+// Job, empty when the hook didn't write any.
+func getHookTerminationMessage(client k8sclient.Client, job *batchv1.Job) (string, error) {
+	podList := corev1.PodList{}
+	err := client.List(
+		context.TODO(),
+		&podList,
+		k8sclient.InNamespace(job.Namespace),
+		k8sclient.MatchingLabels{"job-name": job.Name})
+	if err != nil {
+		return "", liberr.Wrap(err)
+	}
+	for _, pod := range podList.Items {
+		if pod.Status.Phase != corev1.PodSucceeded {
+			continue
+		}
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.State.Terminated != nil {
+				return strings.TrimSpace(status.State.Terminated.Message), nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// Record the outcome of a hook in the status. The time the hook completed is
+// recorded once it succeeded or failed.
+func (t *Task) setHookStatus(hookPhase string, job *batchv1.Job, state string, message string, consistent bool) {
+	status := &t.Owner.Status
+	var hookStatus *migapi.HookStatus
+	for i := range status.Hooks {
+		if status.Hooks[i].Phase == hookPhase {
+			hookStatus = &status.Hooks[i]
+		}
+	}
+	if hookStatus == nil {
+		status.Hooks = append(status.Hooks, migapi.HookStatus{Phase: hookPhase})
+		hookStatus = &status.Hooks[len(status.Hooks)-1]
+	}
+	hookStatus.JobReference = &corev1.ObjectReference{Namespace: job.Namespace, Name: job.Name}
+	hookStatus.State = state
+	hookStatus.Message = message
+	hookStatus.Consistent = consistent
+	if hookStatus.StartTimestamp == nil {
+		start := job.CreationTimestamp
+		if start.IsZero() {
+			start = metav1.Now()
+		}
+		hookStatus.StartTimestamp = &start
+	}
+	if state != migapi.HookRunning && hookStatus.CompletionTimestamp == nil {
+		hookStatus.CompletionTimestamp = &metav1.Time{Time: time.Now()}
+	}
+}
+
+// Fail the migration because of a failed hook. The Rsync resources
+// are cleaned up by the failed itinerary.
+func (t *Task) failHook(hookPhase string, reason string) {
+	msg := fmt.Sprintf("The %s hook failed. %s", hookPhase, reason)
+	t.Log.Info(msg)
+	t.Owner.Status.SetCondition(migapi.Condition{
+		Type:     TransferHookFailed,
+		Status:   True,
+		Reason:   hookPhase,
+		Category: Warn,
+		Message:  msg,
+		Durable:  true,
+	})
+	t.fail(MigrationFailed, []string{msg})
+	t.Itinerary = FailedCleanupItinerary
+	t.PhaseDescription = phaseDescriptions[t.Phase]
+	t.Requeue = NoReQ
+}
+
+func (t *Task) prepareHookJob(hook migapi.MigPlanHook, migHook migapi.MigHook, client k8sclient.Client) (*batchv1.Job, error) {
+	return migHook.PreparePhaseJob(client, hook, string(t.Owner.UID), t.Owner.Name, t.getHookContainer(hook))
+}
+
+// Delete the hook Jobs run for the DVM, stopping the running ones. A hook
+// Job which could not be deleted is logged, not blocking the failed or
+// canceled migration.
+func (t *Task) deleteHookJobs() error {
+	for _, hook := range t.Owner.Spec.Hooks {
+		if hook.Reference == nil {
+			continue
+		}
+		migHook := migapi.MigHook{}
+		err := t.Client.Get(
+			context.TODO(),
+			types.NamespacedName{
+				Name:      hook.Reference.Name,
+				Namespace: hook.Reference.Namespace,
+			},
+			&migHook)
+		if err != nil {
+			if k8serror.IsNotFound(err) {
+				continue
+			}
+			return liberr.Wrap(err)
+		}
+		client, err := t.getHookClient(migHook)
+		if err != nil {
+			t.Log.Error(err, "Hook Jobs could not be deleted.",
+				"migHook", path.Join(migHook.Namespace, migHook.Name),
+				"migHookPhase", hook.Phase)
+			continue
+		}
+		job, err := migHook.DeletePhaseJob(client, hook.Phase, string(t.Owner.UID))
+		if err != nil {
+			t.Log.Error(err, "Hook Job could not be deleted.",
+				"migHook", path.Join(migHook.Namespace, migHook.Name),
+				"migHookPhase", hook.Phase)
+			continue
+		}
+		if job != nil {
+			t.Log.Info("Deleted hook Job.",
+				"job", path.Join(job.Namespace, job.Name),
+				"migHookPhase", hook.Phase)
+		}
+	}
+	return nil
+}
+
+func (t *Task) getHookClient(migHook migapi.MigHook) (k8sclient.Client, error) {
+	switch migHook.Spec.TargetCluster {
+	case "destination":
+		client, err := t.getDestinationClient()
+		if err != nil {
+			return nil, liberr.Wrap(err)
+		}
+		return client, nil
+	case "source":
+		client, err := t.getSourceClient()
+		if err != nil {
+			return nil, liberr.Wrap(err)
+		}
+		return client, nil
+	default:
+		err := fmt.Errorf("targetCluster must be 'source' or 'destination'. %s unknown", migHook.Spec.TargetCluster)
+		return nil, liberr.Wrap(err)
+	}
+}
+
+// Get the container of the hook Job, the image and the command being set from the MigHook.
+func (t *Task) getHookContainer(hook migapi.MigPlanHook) corev1.Container {
+	return corev1.Container{
+		Name: strings.ToLower(hook.Phase),
+		Env: []corev1.EnvVar{
+			{
+				Name:  "MIGRATION_NAMESPACES",
+				Value: strings.Join(t.getPVCNamespaces(), ","),
+			},
+			{
+				Name:  "DIRECT_VOLUME_MIGRATION_NAME",
+				Value: t.Owner.Name,
+			},
+		},
+	}
+}
+
+// Get whether a hook is attached to the given hook phase.
+func hasHookPhase(hooks []migapi.MigPlanHook, hookPhase string) bool {
+	for _, hook := range hooks {
+		if hook.Phase == hookPhase && hook.Reference != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Get the sorted list of source namespaces of the migrated PVCs.
+func (t *Task) getPVCNamespaces() []string {
+	found := map[string]bool{}
+	namespaces := []string{}
+	for _, pvc := range t.Owner.Spec.PersistentVolumeClaims {
+		if found[pvc.Namespace] {
+			continue
+		}
+		found[pvc.Namespace] = true
+		namespaces = append(namespaces, pvc.Namespace)
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
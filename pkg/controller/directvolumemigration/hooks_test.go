@@ -0,0 +1,259 @@
+package directvolumemigration
+
+import (
+	"testing"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestTask_ensureHookJob(t *testing.T) {
+	migHook := migapi.MigHook{
+		ObjectMeta: metav1.ObjectMeta{Name: "hook", Namespace: migapi.OpenshiftMigrationNamespace, UID: "hook-uid"},
+		Spec:       migapi.MigHookSpec{Custom: true, Image: "hook-image"},
+	}
+	hook := migapi.MigPlanHook{
+		Reference:          &corev1.ObjectReference{Name: "hook", Namespace: migapi.OpenshiftMigrationNamespace},
+		Phase:              migapi.PreTransferHookPhase,
+		ExecutionNamespace: "ns",
+		ServiceAccount:     "sa",
+	}
+	owner := &migapi.DirectVolumeMigration{
+		ObjectMeta: metav1.ObjectMeta{Name: "dvm", Namespace: migapi.OpenshiftMigrationNamespace, UID: "dvm-uid"},
+	}
+	getJob := func(status batchv1.JobStatus) *batchv1.Job {
+		labels := migHook.GetCorrelationLabels()
+		labels[migapi.HookPhaseLabel] = hook.Phase
+		labels[migapi.HookOwnerLabel] = string(owner.UID)
+		return &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "dvm-pretransfer-abc", Namespace: "ns", Labels: labels},
+			Status:     status,
+		}
+	}
+	tests := []struct {
+		name          string
+		objects       []runtime.Object
+		wantCompleted bool
+		wantFailed    bool
+	}{
+		{
+			name:          "when the hook job doesn't exist, should create it and wait",
+			objects:       []runtime.Object{},
+			wantCompleted: false,
+			wantFailed:    false,
+		},
+		{
+			name:          "when the hook job is running, should wait",
+			objects:       []runtime.Object{getJob(batchv1.JobStatus{Active: 1})},
+			wantCompleted: false,
+			wantFailed:    false,
+		},
+		{
+			name:          "when the hook job succeeded, should be completed",
+			objects:       []runtime.Object{getJob(batchv1.JobStatus{Succeeded: 1})},
+			wantCompleted: true,
+			wantFailed:    false,
+		},
+		{
+			name:          "when the hook job failed too many times, should report failure",
+			objects:       []runtime.Object{getJob(batchv1.JobStatus{Failed: migapi.HookJobFailedLimit})},
+			wantCompleted: false,
+			wantFailed:    true,
+		},
+		{
+			name: "when the hook job exceeded its backoff limit, should report failure",
+			objects: []runtime.Object{getJob(batchv1.JobStatus{
+				Conditions: []batchv1.JobCondition{{Type: batchv1.JobFailed, Reason: migapi.BackoffLimitExceededError}},
+			})},
+			wantCompleted: false,
+			wantFailed:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewFakeClient(tt.objects...)
+			task := &Task{
+				Log:   log,
+				Owner: owner,
+			}
+			job, err := task.prepareHookJob(hook, migHook, client)
+			if err != nil {
+				t.Fatalf("prepareHookJob() unexpected error = %v", err)
+			}
+			completed, failureReason, err := task.ensureHookJob(job, hook, migHook, client)
+			if err != nil {
+				t.Errorf("ensureHookJob() unexpected error = %v", err)
+				return
+			}
+			if completed != tt.wantCompleted {
+				t.Errorf("ensureHookJob() completed = %v, want %v", completed, tt.wantCompleted)
+			}
+			if (failureReason != "") != tt.wantFailed {
+				t.Errorf("ensureHookJob() failureReason = %v, wantFailed %v", failureReason, tt.wantFailed)
+			}
+		})
+	}
+}
+
+func TestTask_ensureHookJob_consistency(t *testing.T) {
+	migHook := migapi.MigHook{
+		ObjectMeta: metav1.ObjectMeta{Name: "hook", Namespace: migapi.OpenshiftMigrationNamespace, UID: "hook-uid"},
+		Spec:       migapi.MigHookSpec{Custom: true, Image: "hook-image"},
+	}
+	hook := migapi.MigPlanHook{
+		Reference:          &corev1.ObjectReference{Name: "hook", Namespace: migapi.OpenshiftMigrationNamespace},
+		Phase:              migapi.PreTransferHookPhase,
+		ExecutionNamespace: "ns",
+		ServiceAccount:     "sa",
+	}
+	labels := migHook.GetCorrelationLabels()
+	labels[migapi.HookPhaseLabel] = hook.Phase
+	labels[migapi.HookOwnerLabel] = "dvm-uid"
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "dvm-pretransfer-abc", Namespace: "ns", Labels: labels},
+		Status:     batchv1.JobStatus{Succeeded: 1},
+	}
+	getPod := func(message string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "dvm-pretransfer-abc-xyz", Namespace: "ns", Labels: map[string]string{"job-name": job.Name}},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodSucceeded,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:  "pretransfer",
+					State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Message: message}},
+				}},
+			},
+		}
+	}
+	tests := []struct {
+		name           string
+		require        bool
+		message        string
+		wantCompleted  bool
+		wantConsistent bool
+	}{
+		{name: "when consistency is confirmed, should be completed", require: true, message: "consistent\n", wantCompleted: true, wantConsistent: true},
+		{name: "when consistency isn't confirmed, should fail", require: true, message: "done", wantCompleted: false},
+		{name: "when consistency isn't required, should be completed", require: false, message: "", wantCompleted: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewFakeClient(job.DeepCopy(), getPod(tt.message))
+			owner := &migapi.DirectVolumeMigration{
+				ObjectMeta: metav1.ObjectMeta{Name: "dvm", Namespace: migapi.OpenshiftMigrationNamespace, UID: "dvm-uid"},
+				Spec:       migapi.DirectVolumeMigrationSpec{RequireHookConsistency: tt.require},
+			}
+			task := &Task{Log: log, Owner: owner}
+			hookJob, err := task.prepareHookJob(hook, migHook, client)
+			if err != nil {
+				t.Fatalf("prepareHookJob() unexpected error = %v", err)
+			}
+			completed, failureReason, err := task.ensureHookJob(hookJob, hook, migHook, client)
+			if err != nil {
+				t.Fatalf("ensureHookJob() unexpected error = %v", err)
+			}
+			if completed != tt.wantCompleted || (failureReason != "") == tt.wantCompleted {
+				t.Errorf("ensureHookJob() = %v, %v, want completed %v", completed, failureReason, tt.wantCompleted)
+			}
+			hooks := owner.Status.Hooks
+			if len(hooks) != 1 || hooks[0].Consistent != tt.wantConsistent || hooks[0].CompletionTimestamp == nil {
+				t.Fatalf("ensureHookJob() hook status = %v, want a completed hook with consistent %v", hooks, tt.wantConsistent)
+			}
+			wantState := migapi.HookSucceeded
+			if !tt.wantCompleted {
+				wantState = migapi.HookFailed
+			}
+			if hooks[0].State != wantState {
+				t.Errorf("ensureHookJob() hook state = %v, want %v", hooks[0].State, wantState)
+			}
+		})
+	}
+}
+
+func TestTask_prepareHookJob(t *testing.T) {
+	migHook := migapi.MigHook{
+		ObjectMeta: metav1.ObjectMeta{Name: "hook", Namespace: migapi.OpenshiftMigrationNamespace, UID: "hook-uid"},
+		Spec:       migapi.MigHookSpec{Image: "hook-image", Playbook: "LSBob3N0czogbG9jYWxob3N0Cg=="},
+	}
+	hook := migapi.MigPlanHook{
+		Reference:          &corev1.ObjectReference{Name: "hook", Namespace: migapi.OpenshiftMigrationNamespace},
+		Phase:              migapi.PreTransferHookPhase,
+		ExecutionNamespace: "ns",
+		ServiceAccount:     "sa",
+	}
+	owner := &migapi.DirectVolumeMigration{
+		ObjectMeta: metav1.ObjectMeta{Name: "dvm", Namespace: migapi.OpenshiftMigrationNamespace, UID: "dvm-uid"},
+	}
+	labels := migHook.GetCorrelationLabels()
+	labels[migapi.HookPhaseLabel] = hook.Phase
+	labels[migapi.HookOwnerLabel] = string(owner.UID)
+	tests := []struct {
+		name          string
+		objects       []runtime.Object
+		wantConfigMap string
+	}{
+		{
+			name: "when the playbook configMap exists, should mount it",
+			objects: []runtime.Object{&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "dvm-pretransfer-abc", Namespace: "ns", Labels: labels},
+			}},
+			wantConfigMap: "dvm-pretransfer-abc",
+		},
+		{
+			name:    "when the playbook configMap doesn't exist, should create and mount it",
+			objects: []runtime.Object{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewFakeClient(tt.objects...)
+			task := &Task{Log: log, Owner: owner}
+			job, err := task.prepareHookJob(hook, migHook, client)
+			if err != nil {
+				t.Fatalf("prepareHookJob() unexpected error = %v", err)
+			}
+			configMap, err := migHook.GetPhaseConfigMap(client, hook.Phase, string(owner.UID))
+			if err != nil || configMap == nil {
+				t.Fatalf("prepareHookJob() playbook configMap = %v, %v, want it to exist", configMap, err)
+			}
+			if tt.wantConfigMap != "" && configMap.Name != tt.wantConfigMap {
+				t.Errorf("prepareHookJob() playbook configMap = %v, want %v", configMap.Name, tt.wantConfigMap)
+			}
+			volumes := job.Spec.Template.Spec.Volumes
+			if len(volumes) != 1 || volumes[0].ConfigMap == nil || volumes[0].ConfigMap.Name != configMap.Name {
+				t.Errorf("prepareHookJob() job volumes = %v, want the playbook configMap %v", volumes, configMap.Name)
+			}
+			container := job.Spec.Template.Spec.Containers[0]
+			if container.Name != "pretransfer" || container.Image != migHook.Spec.Image || len(container.Command) == 0 {
+				t.Errorf("prepareHookJob() job container = %v, want the playbook run by the hook image", container)
+			}
+		})
+	}
+}
+
+func TestMigHook_DeletePhaseJob(t *testing.T) {
+	migHook := migapi.MigHook{
+		ObjectMeta: metav1.ObjectMeta{Name: "hook", Namespace: migapi.OpenshiftMigrationNamespace, UID: "hook-uid"},
+	}
+	labels := migHook.GetCorrelationLabels()
+	labels[migapi.HookPhaseLabel] = migapi.PostTransferHookPhase
+	labels[migapi.HookOwnerLabel] = "dvm-uid"
+	client := fake.NewFakeClient(&batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "dvm-posttransfer-abc", Namespace: "ns", Labels: labels},
+		Status:     batchv1.JobStatus{Active: 1},
+	})
+	job, err := migHook.DeletePhaseJob(client, migapi.PostTransferHookPhase, "dvm-uid")
+	if err != nil || job == nil || job.Name != "dvm-posttransfer-abc" {
+		t.Fatalf("DeletePhaseJob() = %v, %v, want the running job deleted", job, err)
+	}
+	if job, err = migHook.GetPhaseJob(client, migapi.PostTransferHookPhase, "dvm-uid"); err != nil || job != nil {
+		t.Errorf("DeletePhaseJob() remaining job = %v, %v, want none", job, err)
+	}
+	if job, err = migHook.DeletePhaseJob(client, migapi.PostTransferHookPhase, "dvm-uid"); err != nil || job != nil {
+		t.Errorf("DeletePhaseJob() without job = %v, %v, want nothing deleted", job, err)
+	}
+}
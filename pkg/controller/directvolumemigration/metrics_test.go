@@ -0,0 +1,38 @@
+package directvolumemigration
+
+import (
+	"reflect"
+	"testing"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+)
+
+func Test_countDirectVolumeMigrations(t *testing.T) {
+	withCondition := func(conditionType string) migapi.DirectVolumeMigration {
+		dvm := migapi.DirectVolumeMigration{}
+		dvm.Status.SetCondition(migapi.Condition{Type: conditionType, Status: True})
+		return dvm
+	}
+	withConditions := withCondition(Running)
+	withConditions.Status.SetCondition(migapi.Condition{Type: Failed, Status: True})
+	canceled := migapi.DirectVolumeMigration{}
+	canceled.Status.Phase = Canceled
+	dvms := []migapi.DirectVolumeMigration{
+		{},
+		withCondition(Running),
+		withCondition(Running),
+		withCondition(Succeeded),
+		withConditions,
+		canceled,
+	}
+	want := map[string]float64{
+		dvmPending:   1,
+		dvmRunning:   2,
+		dvmCompleted: 1,
+		dvmFailed:    1,
+		dvmCanceled:  1,
+	}
+	if got := countDirectVolumeMigrations(dvms); !reflect.DeepEqual(got, want) {
+		t.Errorf("countDirectVolumeMigrations() = %v, want %v", got, want)
+	}
+}
@@ -6,16 +6,18 @@ import (
 	"time"
 
 	liberr "github.com/konveyor/controller/pkg/error"
+	"github.com/konveyor/controller/pkg/logging"
 	"github.com/konveyor/mig-controller/pkg/errorutil"
+	"github.com/konveyor/mig-controller/pkg/settings"
 
 	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-func (r *ReconcileDirectVolumeMigration) migrate(ctx context.Context, direct *migapi.DirectVolumeMigration) (time.Duration, error) {
+func (r *ReconcileDirectVolumeMigration) migrate(ctx context.Context, log *logging.Logger, direct *migapi.DirectVolumeMigration, migration *migapi.MigMigration) (time.Duration, error) {
 
-	planResources, err := r.getDVMPlanResources(direct)
+	planResources, err := r.getDVMPlanResources(log, direct, migration)
 	if err != nil {
 		return 0, liberr.Wrap(err)
 	}
@@ -26,6 +28,9 @@ func (r *ReconcileDirectVolumeMigration) migrate(ctx context.Context, direct *mi
 		direct.Status.StartTimestamp = &metav1.Time{Time: time.Now()}
 	}
 
+	// Phase the reconcile started in, reported with the requeue decision
+	phase := direct.Status.Phase
+
 	// Run
 	task := Task{
 		Log:              log,
@@ -35,12 +40,36 @@ func (r *ReconcileDirectVolumeMigration) migrate(ctx context.Context, direct *mi
 		PhaseDescription: direct.Status.PhaseDescription,
 		PlanResources:    planResources,
 		Tracer:           r.tracer,
+
+		ownerMigration:        migration,
+		ownerMigrationFetched: true,
+	}
+	if settings.Settings.DvmOpts.APICallMetrics {
+		task.Client = countHostAPICalls(task.Client, task.Phase)
 	}
 	err = task.Run(ctx)
 	if err != nil {
 		if k8serrors.IsConflict(errorutil.Unwrap(err)) {
 			log.V(4).Info("Conflict error during task.Run, requeueing.")
-			return FastReQ, nil
+			requeueAfter := getRequeueAfter(direct, FastReQ)
+			setRequeueStatus(direct, phase, RequeueConflictRetry, RequeueConflictRetryMessage, requeueAfter)
+			return requeueAfter, nil
+		}
+		if clientErr := getDestinationClientError(err); clientErr != nil {
+			log.Info("Destination cluster client cannot be built, backing off.",
+				"phase", task.Phase,
+				"error", clientErr.Error())
+			requeueAfter := task.backOffDestinationClientError(clientErr)
+			setRequeueStatus(direct, phase, RequeueDestinationUnreachable, clientErr.Error(), requeueAfter)
+			return requeueAfter, nil
+		}
+		if isRetryableError(err) {
+			log.Info("Phase execution failed with a retryable error, retrying.",
+				"phase", task.Phase,
+				"error", errorutil.Unwrap(err).Error())
+			requeueAfter := getRequeueAfter(direct, PollReQ)
+			setRequeueStatus(direct, phase, RequeueRetryableError, errorutil.Unwrap(err).Error(), requeueAfter)
+			return requeueAfter, nil
 		}
 		log.Info("Phase execution failed.",
 			"phase", task.Phase,
@@ -48,18 +77,34 @@ func (r *ReconcileDirectVolumeMigration) migrate(ctx context.Context, direct *mi
 			"error", errorutil.Unwrap(err).Error())
 		log.Trace(err)
 		task.fail(MigrationFailed, []string{err.Error()})
-		return task.Requeue, nil
+		requeueAfter := getRequeueAfter(direct, task.Requeue)
+		setRequeueStatus(direct, phase, RequeuePhaseFailed, errorutil.Unwrap(err).Error(), requeueAfter)
+		return requeueAfter, nil
 	}
 
 	// Result
 	direct.Status.PhaseDescription = task.PhaseDescription
 	direct.Status.Phase = task.Phase
 	direct.Status.Itinerary = task.Itinerary.Name
+	direct.Status.ItinerarySteps = task.Itinerary.stepPhases()
 
 	// Completed
 	if task.Phase == Completed {
+		direct.Status.CompletionTimestamp = &metav1.Time{Time: time.Now()}
 		direct.Status.DeleteCondition(Running)
+		direct.Status.TransferSummary = task.getTransferSummary()
+		direct.Status.Requeue = nil
 		failed := task.Owner.Status.FindCondition(Failed)
+		if failed == nil && task.Itinerary.Name == NoVolumesMigration.Name {
+			direct.Status.SetCondition(migapi.Condition{
+				Type:     NoVolumesToMigrate,
+				Status:   True,
+				Reason:   NotSet,
+				Category: Advisory,
+				Message:  NoVolumesToMigrateMessage,
+				Durable:  true,
+			})
+		}
 		if failed == nil {
 			direct.Status.SetCondition(migapi.Condition{
 				Type:     Succeeded,
@@ -73,37 +118,73 @@ func (r *ReconcileDirectVolumeMigration) migrate(ctx context.Context, direct *mi
 		return NoReQ, nil
 	}
 
+	// Canceled
+	if task.Phase == Canceled {
+		direct.Status.CompletionTimestamp = &metav1.Time{Time: time.Now()}
+		direct.Status.DeleteCondition(Running)
+		direct.Status.TransferSummary = task.getTransferSummary()
+		direct.Status.Requeue = nil
+		return NoReQ, nil
+	}
+
 	// Running
 	step, n, total := task.Itinerary.progressReport(task.Phase)
 	message := fmt.Sprintf(RunningMessage, n, total)
+	completed, items := task.getPVCTransferProgress()
+	if len(items) > 0 {
+		message = fmt.Sprintf(RunningPVCsMessage, n, total, completed, len(items))
+	}
+	// the phases time themselves from the Running condition, the progress
+	// of the PVCs must not restart it
+	direct.Status.StageCondition(Running)
+	var stepStarted *metav1.Time
+	if running := direct.Status.FindCondition(Running); running != nil && running.Reason == step {
+		stepStarted = running.LastTransitionTime.DeepCopy()
+	}
 	direct.Status.SetCondition(migapi.Condition{
 		Type:     Running,
 		Status:   True,
 		Reason:   step,
 		Category: Advisory,
 		Message:  message,
+		Items:    items,
 	})
+	if stepStarted != nil {
+		direct.Status.FindCondition(Running).LastTransitionTime = *stepStarted
+	}
 
-	return task.Requeue, nil
+	// Verifying, the data is transferred but isn't verified yet
+	if task.Phase == VerifyData {
+		if verified := task.getVerifiedPVCs(); len(verified) > 0 {
+			direct.Status.SetCondition(migapi.Condition{
+				Type:     VerifyingData,
+				Status:   True,
+				Reason:   step,
+				Category: Advisory,
+				Message:  VerifyingDataMessage,
+				Items:    verified,
+			})
+		}
+	}
+
+	// Requeue decision
+	reason, requeueMessage := task.getRequeueReason(phase)
+	requeueAfter := getRequeueAfter(direct, task.Requeue)
+	setRequeueStatus(direct, phase, reason, requeueMessage, requeueAfter)
+
+	return requeueAfter, nil
 }
 
-// fetches DVM Migration object and Migplan resources if DVM has an owner reference
-func (r *ReconcileDirectVolumeMigration) getDVMPlanResources(direct *migapi.DirectVolumeMigration) (*migapi.PlanResources, error) {
+// fetches Migplan resources of the DVM Migration object if DVM has an owner reference
+func (r *ReconcileDirectVolumeMigration) getDVMPlanResources(log *logging.Logger, direct *migapi.DirectVolumeMigration, migration *migapi.MigMigration) (*migapi.PlanResources, error) {
 
 	if len(direct.OwnerReferences) > 0 {
 
-		migration := &migapi.MigMigration{}
 		planResources := &migapi.PlanResources{}
 
-		// Ready
-		migration, err := direct.GetMigrationForDVM(r)
-		if err != nil {
-			return planResources, liberr.Wrap(err)
-		}
-
 		if migration == nil {
-			log.Info("Migration not found for DVM", "name", direct.Name)
-			return planResources, liberr.Wrap(err)
+			log.Info("Migration not found for DVM, the DVM will be canceled.", "name", direct.Name)
+			return planResources, nil
 		}
 
 		plan, err := migration.GetPlan(r)
@@ -0,0 +1,113 @@
+package directvolumemigration
+
+import (
+	"context"
+	"path"
+
+	liberr "github.com/konveyor/controller/pkg/error"
+	"github.com/konveyor/mig-controller/pkg/compat"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Get the node affinity scheduling a Pod on the node.
+func getNodeNameAffinity(nodeName string) *corev1.NodeAffinity {
+	return &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{
+				{
+					MatchFields: []corev1.NodeSelectorRequirement{
+						{
+							Key:      "metadata.name",
+							Operator: corev1.NodeSelectorOpIn,
+							Values:   []string{nodeName},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// Get the node affinity of the Rsync client Pod reading the PV locally. The node
+// affinity of a node-local PV, e.g. a local or hostPath volume, is used as is,
+// otherwise the Pod runs on the node the PV is attached to. Returns nil when the
+// node hosting the PV is unknown.
+func getPVNodeAffinity(pv *corev1.PersistentVolume, attachedNodes map[string]string) *corev1.NodeAffinity {
+	if pv.Spec.NodeAffinity != nil && pv.Spec.NodeAffinity.Required != nil &&
+		len(pv.Spec.NodeAffinity.Required.NodeSelectorTerms) > 0 {
+		return &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: pv.Spec.NodeAffinity.Required.DeepCopy(),
+		}
+	}
+	if nodeName, found := attachedNodes[pv.Name]; found {
+		return getNodeNameAffinity(nodeName)
+	}
+	return nil
+}
+
+// Get the nodes the PVs are attached to, keyed by PV name. The VolumeAttachments
+// are only listed by the clusters using CSI or attachable volumes, an empty map
+// is returned when they can't be listed.
+func (t *Task) getPVAttachedNodes(srcClient compat.Client) map[string]string {
+	nodes := map[string]string{}
+	attachments := storagev1.VolumeAttachmentList{}
+	err := srcClient.List(context.TODO(), &attachments)
+	if err != nil {
+		t.Log.Info("Cannot list the VolumeAttachments of the source cluster, the nodes the PVs are attached to are unknown.",
+			"error", err.Error())
+		return nodes
+	}
+	for _, attachment := range attachments.Items {
+		pvName := attachment.Spec.Source.PersistentVolumeName
+		if pvName != nil && attachment.Status.Attached {
+			nodes[*pvName] = attachment.Spec.NodeName
+		}
+	}
+	return nodes
+}
+
+// Get the node affinities of the Rsync client Pods reading the source PVCs on
+// the node hosting their volume, keyed by the namespaced name of the PVCs. The
+// PVCs mounted by a running Pod are left out, their Rsync client Pod runs on the
+// node of that Pod. The PVCs which node is unknown are scheduled as usual.
+func (t *Task) getPVCNodeAffinities(srcClient compat.Client, pvcMap map[string][]PVCWithSecurityContext, pvcNodeMap map[string]string) (map[string]*corev1.NodeAffinity, error) {
+	affinities := map[string]*corev1.NodeAffinity{}
+	var attachedNodes map[string]string
+	for ns, vols := range pvcMap {
+		for _, vol := range vols {
+			key := path.Join(ns, vol.name)
+			if pvcNodeMap[key] != "" {
+				continue
+			}
+			pvc := corev1.PersistentVolumeClaim{}
+			err := srcClient.Get(context.TODO(), types.NamespacedName{Namespace: ns, Name: vol.name}, &pvc)
+			if err != nil && !k8serror.IsNotFound(err) {
+				return nil, liberr.Wrap(err)
+			}
+			if err != nil || pvc.Spec.VolumeName == "" {
+				continue
+			}
+			pv := corev1.PersistentVolume{}
+			err = srcClient.Get(context.TODO(), types.NamespacedName{Name: pvc.Spec.VolumeName}, &pv)
+			if err != nil && !k8serror.IsNotFound(err) {
+				return nil, liberr.Wrap(err)
+			}
+			if err != nil {
+				continue
+			}
+			if attachedNodes == nil {
+				attachedNodes = t.getPVAttachedNodes(srcClient)
+			}
+			if affinity := getPVNodeAffinity(&pv, attachedNodes); affinity != nil {
+				affinities[key] = affinity
+			} else {
+				t.Log.Info("Node hosting the source PVC is unknown, its Rsync client Pod is scheduled as usual.",
+					"persistentVolumeClaim", key)
+			}
+		}
+	}
+	return affinities, nil
+}
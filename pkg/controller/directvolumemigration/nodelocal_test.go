@@ -0,0 +1,96 @@
+package directvolumemigration
+
+import (
+	"reflect"
+	"testing"
+
+	fakecompat "github.com/konveyor/mig-controller/pkg/compat/fake"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_getPVNodeAffinity(t *testing.T) {
+	localAffinity := &corev1.NodeSelector{
+		NodeSelectorTerms: []corev1.NodeSelectorTerm{
+			{
+				MatchExpressions: []corev1.NodeSelectorRequirement{
+					{Key: "kubernetes.io/hostname", Operator: corev1.NodeSelectorOpIn, Values: []string{"node-1"}},
+				},
+			},
+		},
+	}
+	tests := []struct {
+		name          string
+		pv            *corev1.PersistentVolume
+		attachedNodes map[string]string
+		want          *corev1.NodeAffinity
+	}{
+		{
+			name: "when the PV is node-local, should use its node affinity",
+			pv: &corev1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{Name: "pv-1"},
+				Spec: corev1.PersistentVolumeSpec{
+					NodeAffinity: &corev1.VolumeNodeAffinity{Required: localAffinity},
+				},
+			},
+			attachedNodes: map[string]string{"pv-1": "node-2"},
+			want:          &corev1.NodeAffinity{RequiredDuringSchedulingIgnoredDuringExecution: localAffinity},
+		},
+		{
+			name:          "when the PV is attached, should run on the node it is attached to",
+			pv:            &corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pv-1"}},
+			attachedNodes: map[string]string{"pv-1": "node-2"},
+			want:          getNodeNameAffinity("node-2"),
+		},
+		{
+			name:          "when the node hosting the PV is unknown, should return nil",
+			pv:            &corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pv-1"}},
+			attachedNodes: map[string]string{"pv-2": "node-2"},
+			want:          nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getPVNodeAffinity(tt.pv, tt.attachedNodes); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getPVNodeAffinity() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTask_getPVCNodeAffinities(t *testing.T) {
+	pvName := "pv-attached"
+	client := fakecompat.NewFakeClient(
+		&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "attached", Namespace: "ns"},
+			Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: pvName},
+		},
+		&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "mounted", Namespace: "ns"},
+			Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: "pv-mounted"},
+		},
+		&corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: pvName}},
+		&corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pv-mounted"}},
+		&storagev1.VolumeAttachment{
+			ObjectMeta: metav1.ObjectMeta{Name: "attachment"},
+			Spec: storagev1.VolumeAttachmentSpec{
+				NodeName: "node-1",
+				Source:   storagev1.VolumeAttachmentSource{PersistentVolumeName: &pvName},
+			},
+			Status: storagev1.VolumeAttachmentStatus{Attached: true},
+		},
+	)
+	task := &Task{Log: log.WithName("test-logger")}
+	pvcMap := map[string][]PVCWithSecurityContext{
+		"ns": {{name: "attached"}, {name: "mounted"}, {name: "missing"}},
+	}
+	got, err := task.getPVCNodeAffinities(client, pvcMap, map[string]string{"ns/mounted": "node-2"})
+	if err != nil {
+		t.Fatalf("getPVCNodeAffinities() unexpected error = %v", err)
+	}
+	want := map[string]*corev1.NodeAffinity{"ns/attached": getNodeNameAffinity("node-1")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("getPVCNodeAffinities() = %v, want %v", got, want)
+	}
+}
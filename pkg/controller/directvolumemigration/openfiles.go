@@ -0,0 +1,103 @@
+package directvolumemigration
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// FileHeavyInodesThreshold number of inodes used on a source volume from which
+// the open files limit of its Rsync Pods is raised when not set in the spec.
+const FileHeavyInodesThreshold = int64(1000000)
+
+// FileHeavyOpenFilesLimit open files limit of the Rsync Pods of the volumes with
+// many files when not set in the spec.
+const FileHeavyOpenFilesLimit = int64(1048576)
+
+// Get the shell command raising the open files limit before running Rsync. The
+// hard limit can only be raised by a privileged container, the soft limit is
+// raised up to the hard limit otherwise. Empty when the limit isn't raised.
+func getOpenFilesLimitCommand(limit int64) string {
+	if limit <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("ulimit -n %d 2>/dev/null || ulimit -n $(ulimit -Hn); ", limit)
+}
+
+// Get the open files limit of the Rsync Pods transferring the source PVC, 0
+// when the default limit of the container runtime applies. The limit of the
+// spec applies to every PVC, otherwise it is raised for the PVCs which volume
+// the MigAnalytic of the plan reported with many files.
+func (t *Task) getPVCOpenFilesLimit(namespace string, name string) int64 {
+	if t.Owner.Spec.RsyncOpenFilesLimit != nil {
+		return *t.Owner.Spec.RsyncOpenFilesLimit
+	}
+	for _, operation := range t.Owner.Status.RsyncOperations {
+		if operation.PVCReference == nil ||
+			operation.PVCReference.Namespace != namespace || operation.PVCReference.Name != name {
+			continue
+		}
+		if operation.UsedInodes >= FileHeavyInodesThreshold {
+			return FileHeavyOpenFilesLimit
+		}
+	}
+	return 0
+}
+
+// Get the open files limit of the Rsync transfer Pod receiving the PVCs of the
+// source namespace, the largest limit of the PVCs.
+func (t *Task) getTransferPodOpenFilesLimit(srcNamespace string, vols []pvcMapElement) int64 {
+	limit := int64(0)
+	for _, vol := range vols {
+		if pvcLimit := t.getPVCOpenFilesLimit(srcNamespace, vol.Name); pvcLimit > limit {
+			limit = pvcLimit
+		}
+	}
+	return limit
+}
+
+// Get the command of the Rsync daemon of the transfer Pod, run through a shell
+// raising the open files limit when set.
+func getRsyncDaemonCommand(openFilesLimit int64) []string {
+	command := []string{"/usr/bin/rsync", "--daemon", "--no-detach", "--port=22", "-vvv"}
+	if openFilesLimit <= 0 {
+		return command
+	}
+	return []string{"/bin/bash", "-c", getOpenFilesLimitCommand(openFilesLimit) + "exec " + strings.Join(command, " ")}
+}
+
+// Report the PVCs which Rsync Pods run with a raised open files limit because
+// the MigAnalytic of the plan reported many files on their volume.
+func (t *Task) setOpenFilesLimitRaised() {
+	if t.Owner.Spec.RsyncOpenFilesLimit != nil {
+		return
+	}
+	raised := []string{}
+	for _, pvc := range t.Owner.Spec.PersistentVolumeClaims {
+		if limit := t.getPVCOpenFilesLimit(pvc.Namespace, pvc.Name); limit > 0 {
+			operation := t.Owner.Status.GetRsyncOperationStatusForPVC(&corev1.ObjectReference{
+				Namespace: pvc.Namespace,
+				Name:      pvc.Name,
+			})
+			raised = append(raised, fmt.Sprintf("%s: %d inodes, %d open files",
+				path.Join(pvc.Namespace, pvc.Name), operation.UsedInodes, limit))
+		}
+	}
+	if len(raised) == 0 {
+		return
+	}
+	t.Log.Info("Raising the open files limit of the Rsync Pods of the PVCs with many files.",
+		"persistentVolumeClaims", raised)
+	t.Owner.Status.SetCondition(migapi.Condition{
+		Type:     OpenFilesLimitRaised,
+		Status:   True,
+		Reason:   ManyFiles,
+		Category: Advisory,
+		Message:  OpenFilesLimitRaisedMessage,
+		Items:    raised,
+		Durable:  true,
+	})
+}
@@ -0,0 +1,73 @@
+package directvolumemigration
+
+import (
+	"strings"
+	"testing"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestTask_getPVCOpenFilesLimit(t *testing.T) {
+	specLimit := int64(65536)
+	operations := []*migapi.RsyncOperation{
+		{PVCReference: &corev1.ObjectReference{Namespace: "ns", Name: "many-files"}, UsedInodes: 2000000},
+		{PVCReference: &corev1.ObjectReference{Namespace: "ns", Name: "few-files"}, UsedInodes: 1000},
+	}
+	tests := []struct {
+		name      string
+		specLimit *int64
+		pvc       string
+		want      int64
+	}{
+		{
+			name: "when the volume has many files, should raise the limit",
+			pvc:  "many-files",
+			want: FileHeavyOpenFilesLimit,
+		},
+		{
+			name: "when the volume has few files, should keep the default limit",
+			pvc:  "few-files",
+			want: 0,
+		},
+		{
+			name: "when the volume wasn't analyzed, should keep the default limit",
+			pvc:  "unknown",
+			want: 0,
+		},
+		{
+			name:      "when the limit is set in the spec, should use it",
+			specLimit: &specLimit,
+			pvc:       "few-files",
+			want:      specLimit,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &Task{
+				Owner: &migapi.DirectVolumeMigration{
+					Spec: migapi.DirectVolumeMigrationSpec{
+						RsyncOpenFilesLimit: tt.specLimit,
+					},
+					Status: migapi.DirectVolumeMigrationStatus{
+						RsyncOperations: operations,
+					},
+				},
+			}
+			if got := task.getPVCOpenFilesLimit("ns", tt.pvc); got != tt.want {
+				t.Errorf("getPVCOpenFilesLimit() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_getRsyncDaemonCommand(t *testing.T) {
+	if got := getRsyncDaemonCommand(0); got[0] != "/usr/bin/rsync" {
+		t.Errorf("getRsyncDaemonCommand() = %v, want rsync run directly", got)
+	}
+	got := getRsyncDaemonCommand(FileHeavyOpenFilesLimit)
+	if len(got) != 3 || !strings.HasPrefix(got[2], "ulimit -n 1048576 ") ||
+		!strings.HasSuffix(got[2], "exec /usr/bin/rsync --daemon --no-detach --port=22 -vvv") {
+		t.Errorf("getRsyncDaemonCommand() = %v, want rsync run after raising the limit", got)
+	}
+}
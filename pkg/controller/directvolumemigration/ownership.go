@@ -0,0 +1,92 @@
+package directvolumemigration
+
+import (
+	"context"
+	"path"
+
+	liberr "github.com/konveyor/controller/pkg/error"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"github.com/konveyor/mig-controller/pkg/settings"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Get whether the DVMPs of the DVM are deleted with its Rsync resources. The
+// DVMPs managed by labels only are always deleted, they are not garbage
+// collected with the DVM.
+func (t *Task) deletesProgressReportingCRs() bool {
+	return t.Owner.Spec.DeleteProgressReportingCRs || !settings.Settings.DvmOpts.OwnerReferences
+}
+
+// Get the UID of the DVM a DVMP is labeled with, empty when the DVMP is
+// owner-referenced by its DVM or isn't labeled.
+func getProgressDVMUID(dvmp client.Object) string {
+	if len(dvmp.GetOwnerReferences()) > 0 {
+		return ""
+	}
+	key, _ := (&migapi.DirectVolumeMigration{}).GetCorrelationLabel()
+	return dvmp.GetLabels()[key]
+}
+
+// getProgressRequests returns the request of the DVM a DVMP managed by labels
+// only belongs to. The owner-referenced DVMPs are mapped by their owner.
+func getProgressRequests(c client.Client, a client.Object) []reconcile.Request {
+	requests := []reconcile.Request{}
+	uid := getProgressDVMUID(a)
+	if uid == "" {
+		return requests
+	}
+	list := migapi.DirectVolumeMigrationList{}
+	err := c.List(context.TODO(), &list, client.InNamespace(a.GetNamespace()))
+	if err != nil {
+		log.Trace(err)
+		return requests
+	}
+	for _, dvm := range list.Items {
+		if string(dvm.UID) != uid {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Namespace: dvm.Namespace,
+				Name:      dvm.Name,
+			},
+		})
+	}
+	return requests
+}
+
+// Delete the DVMPs managed by labels only which DVM was deleted, the DVMPs
+// which are not owner-referenced are never garbage collected.
+func deleteOrphanedProgressCRs(c client.Client, namespace string) error {
+	dvmList := migapi.DirectVolumeMigrationList{}
+	err := c.List(context.TODO(), &dvmList, client.InNamespace(namespace))
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	uids := map[string]bool{}
+	for _, dvm := range dvmList.Items {
+		uids[string(dvm.UID)] = true
+	}
+	dvmpList := migapi.DirectVolumeMigrationProgressList{}
+	err = c.List(context.TODO(), &dvmpList, client.InNamespace(namespace))
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	for i := range dvmpList.Items {
+		dvmp := &dvmpList.Items[i]
+		uid := getProgressDVMUID(dvmp)
+		if uid == "" || uids[uid] {
+			continue
+		}
+		log.Info("Deleting DVMP of a deleted DVM.",
+			"dvmp", path.Join(dvmp.Namespace, dvmp.Name))
+		err = c.Delete(context.TODO(), dvmp)
+		if err != nil && !k8serror.IsNotFound(err) {
+			return liberr.Wrap(err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package directvolumemigration
+
+import (
+	"context"
+	"testing"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func getLabeledProgress(name string, uid types.UID, owned bool) *migapi.DirectVolumeMigrationProgress {
+	dvmp := &migapi.DirectVolumeMigrationProgress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: migapi.OpenshiftMigrationNamespace,
+			Labels:    (&migapi.DirectVolumeMigration{ObjectMeta: metav1.ObjectMeta{UID: uid}}).GetCorrelationLabels(),
+		},
+	}
+	if owned {
+		dvmp.OwnerReferences = []metav1.OwnerReference{{Kind: "DirectVolumeMigration", Name: "dvm", UID: uid}}
+	}
+	return dvmp
+}
+
+func Test_deleteOrphanedProgressCRs(t *testing.T) {
+	dvm := &migapi.DirectVolumeMigration{
+		ObjectMeta: metav1.ObjectMeta{Name: "dvm", Namespace: migapi.OpenshiftMigrationNamespace, UID: "live"},
+	}
+	client := fake.NewFakeClient(dvm,
+		getLabeledProgress("live", "live", false),
+		getLabeledProgress("orphan", "deleted", false),
+		getLabeledProgress("owned", "deleted", true),
+	)
+	err := deleteOrphanedProgressCRs(client, migapi.OpenshiftMigrationNamespace)
+	if err != nil {
+		t.Fatalf("deleteOrphanedProgressCRs() unexpected error = %v", err)
+	}
+	list := migapi.DirectVolumeMigrationProgressList{}
+	err = client.List(context.TODO(), &list)
+	if err != nil {
+		t.Fatalf("List() unexpected error = %v", err)
+	}
+	kept := map[string]bool{}
+	for _, dvmp := range list.Items {
+		kept[dvmp.Name] = true
+	}
+	if !kept["live"] || !kept["owned"] || kept["orphan"] {
+		t.Errorf("deleteOrphanedProgressCRs() kept = %v, want [live owned]", kept)
+	}
+}
+
+func Test_getProgressRequests(t *testing.T) {
+	dvm := &migapi.DirectVolumeMigration{
+		ObjectMeta: metav1.ObjectMeta{Name: "dvm", Namespace: migapi.OpenshiftMigrationNamespace, UID: "live"},
+	}
+	client := fake.NewFakeClient(dvm)
+	if got := getProgressRequests(client, getLabeledProgress("live", "live", false)); len(got) != 1 || got[0].Name != dvm.Name {
+		t.Errorf("getProgressRequests() of a labeled DVMP = %v, want the request of %v", got, dvm.Name)
+	}
+	if got := getProgressRequests(client, getLabeledProgress("owned", "live", true)); len(got) != 0 {
+		t.Errorf("getProgressRequests() of an owner-referenced DVMP = %v, want none", got)
+	}
+}
@@ -0,0 +1,80 @@
+package directvolumemigration
+
+import (
+	"path"
+
+	liberr "github.com/konveyor/controller/pkg/error"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// planTransfer resolves the transfer plan of the migration in the status: the
+// itinerary it runs, the PVCs it transfers with the endpoint type of their
+// destination namespace, and the size of the transfer. Nothing is created on
+// the clusters, the capacities of the PVCs are those set in the Prepare phase.
+func (t *Task) planTransfer() error {
+	itinerary := t.getTransferItinerary()
+	plan := &migapi.TransferPlan{
+		Itinerary: itinerary.Name,
+		Steps:     itinerary.stepPhases(),
+	}
+	endpointTypes := map[string]string{}
+	for _, pvc := range t.Owner.Spec.PersistentVolumeClaims {
+		if pvc.ObjectReference == nil {
+			continue
+		}
+		destNs := pvc.Namespace
+		if pvc.TargetNamespace != "" {
+			destNs = pvc.TargetNamespace
+		}
+		endpointType, found := endpointTypes[destNs]
+		if !found {
+			var err error
+			endpointType, err = t.getEndpointType(destNs)
+			if err != nil {
+				return liberr.Wrap(err)
+			}
+			endpointTypes[destNs] = endpointType
+		}
+		sparse, _ := getRsyncSparseOptions(pvc.Sparse)
+		plannedPVC := migapi.TransferPlanPVC{
+			PVCReference:       &corev1.ObjectReference{Namespace: pvc.Namespace, Name: pvc.Name},
+			TargetNamespace:    destNs,
+			TargetStorageClass: pvc.TargetStorageClass,
+			EndpointType:       endpointType,
+			Shards:             pvc.Shards,
+			LargeFileStreams:   pvc.LargeFileStreams,
+			Verify:             pvc.Verify,
+			Sparse:             sparse,
+		}
+		for _, operation := range t.Owner.Status.RsyncOperations {
+			if operation.String() == path.Join(pvc.Namespace, pvc.Name) {
+				plannedPVC.Capacity = operation.Capacity
+				plannedPVC.UsedCapacity = operation.UsedCapacity
+			}
+		}
+		plan.PersistentVolumeClaims = append(plan.PersistentVolumeClaims, plannedPVC)
+	}
+	plan.EstimatedSize = getTransferPlanSize(plan.PersistentVolumeClaims)
+	t.Owner.Status.TransferPlan = plan
+	return nil
+}
+
+// getTransferPlanSize returns the bytes to transfer, the used capacity of the
+// PVCs or their provisioned capacity when the usage isn't reported. Returns nil
+// when the size of a PVC is unknown.
+func getTransferPlanSize(pvcs []migapi.TransferPlanPVC) *resource.Quantity {
+	total := resource.NewQuantity(0, resource.BinarySI)
+	for _, pvc := range pvcs {
+		size := pvc.UsedCapacity
+		if size == nil {
+			size = pvc.Capacity
+		}
+		if size == nil {
+			return nil
+		}
+		total.Add(*size)
+	}
+	return total
+}
@@ -0,0 +1,101 @@
+package directvolumemigration
+
+import (
+	"reflect"
+	"testing"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestTask_planTransfer(t *testing.T) {
+	gi, mi := resource.MustParse("1Gi"), resource.MustParse("512Mi")
+	pvc := func(name string) *corev1.ObjectReference {
+		return &corev1.ObjectReference{Namespace: "ns-1", Name: name}
+	}
+	task := &Task{
+		Log: log.WithName("test-logger"),
+		Owner: &migapi.DirectVolumeMigration{
+			Spec: migapi.DirectVolumeMigrationSpec{
+				Preview: true,
+				PersistentVolumeClaims: []migapi.PVCToMigrate{
+					{ObjectReference: pvc("pvc-1"), TargetNamespace: "ns-2", TargetStorageClass: "gp2", Shards: 4},
+					{ObjectReference: pvc("pvc-2"), Verify: true, Sparse: migapi.SparseAlways},
+				},
+			},
+			Status: migapi.DirectVolumeMigrationStatus{
+				RsyncOperations: []*migapi.RsyncOperation{
+					{PVCReference: pvc("pvc-1"), Capacity: &gi, UsedCapacity: &mi},
+					{PVCReference: pvc("pvc-2"), Capacity: &gi},
+				},
+			},
+		},
+	}
+	err := task.planTransfer()
+	if err != nil {
+		t.Fatalf("Task.planTransfer() unexpected error = %v", err)
+	}
+	plan := task.Owner.Status.TransferPlan
+	if plan == nil || plan.Itinerary != VolumeMigration.Name || len(plan.Steps) != len(VolumeMigration.Steps)-1 {
+		t.Fatalf("Task.planTransfer() plan = %v, want the %s itinerary", plan, VolumeMigration.Name)
+	}
+	want := []migapi.TransferPlanPVC{
+		{
+			PVCReference:       pvc("pvc-1"),
+			TargetNamespace:    "ns-2",
+			TargetStorageClass: "gp2",
+			EndpointType:       EndpointTypeRoute,
+			Shards:             4,
+			Sparse:             migapi.SparseAuto,
+			Capacity:           &gi,
+			UsedCapacity:       &mi,
+		},
+		{
+			PVCReference:    pvc("pvc-2"),
+			TargetNamespace: "ns-1",
+			EndpointType:    EndpointTypeRoute,
+			Verify:          true,
+			Sparse:          migapi.SparseAlways,
+			Capacity:        &gi,
+		},
+	}
+	if !reflect.DeepEqual(plan.PersistentVolumeClaims, want) {
+		t.Errorf("Task.planTransfer() PVCs = %v, want %v", plan.PersistentVolumeClaims, want)
+	}
+	if plan.EstimatedSize == nil || plan.EstimatedSize.Value() != mi.Value()+gi.Value() {
+		t.Errorf("Task.planTransfer() estimated size = %v, want 1536Mi", plan.EstimatedSize)
+	}
+}
+
+func Test_getTransferPlanSize(t *testing.T) {
+	gi := resource.MustParse("1Gi")
+	tests := []struct {
+		name string
+		pvcs []migapi.TransferPlanPVC
+		want *int64
+	}{
+		{
+			name: "when all sizes are known, should sum them",
+			pvcs: []migapi.TransferPlanPVC{{UsedCapacity: &gi}, {Capacity: &gi}},
+			want: int64Ptr(2 * 1024 * 1024 * 1024),
+		},
+		{
+			name: "when the size of a PVC is unknown, should omit the size",
+			pvcs: []migapi.TransferPlanPVC{{UsedCapacity: &gi}, {}},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := getTransferPlanSize(tt.pvcs)
+			if (got == nil) != (tt.want == nil) || (got != nil && got.Value() != *tt.want) {
+				t.Errorf("getTransferPlanSize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func int64Ptr(i int64) *int64 {
+	return &i
+}
@@ -0,0 +1,197 @@
+package directvolumemigration
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"time"
+
+	liberr "github.com/konveyor/controller/pkg/error"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// DirectVolumeMigrationPrewarm purpose of the Pods prewarming the destination PVCs
+	DirectVolumeMigrationPrewarm = "prewarm"
+	// prewarmFile file written over the capacity of the destination PVC, removed once written
+	prewarmFile = ".dvm-prewarm"
+)
+
+// prewarmDestinationPVCs runs a Pod per destination PVC writing zeros over the
+// capacity of the PVC, forcing the allocation of the storage on backends with a
+// first write penalty before the transfer starts. The written file is removed
+// once done and the Pods are deleted once all of them completed. PVCs adopted
+// following the existingPVCPolicy already hold data and are not prewarmed.
+// Returns whether all Pods completed and the PVCs which could not be prewarmed.
+func (t *Task) prewarmDestinationPVCs() (bool, []string, error) {
+	failed := []string{}
+	destClient, err := t.getDestinationClient()
+	if err != nil {
+		return false, failed, liberr.Wrap(err)
+	}
+	cluster, err := t.Owner.GetDestinationCluster(t.Client)
+	if err != nil {
+		return false, failed, liberr.Wrap(err)
+	}
+	image, err := cluster.GetRsyncTransferImage(t.Client)
+	if err != nil {
+		return false, failed, liberr.Wrap(err)
+	}
+	privileged, err := isRsyncPrivileged(destClient)
+	if err != nil {
+		return false, failed, liberr.Wrap(err)
+	}
+	completed := true
+	pods := []corev1.Pod{}
+	for _, pvc := range t.Owner.Spec.PersistentVolumeClaims {
+		destNs := pvc.Namespace
+		if pvc.TargetNamespace != "" {
+			destNs = pvc.TargetNamespace
+		}
+		if t.getPVCTransferState(pvc).DestinationPVC == migapi.DestinationPVCAdopted {
+			t.Log.Info("Not prewarming adopted destination PVC",
+				"persistentVolumeClaim", path.Join(destNs, pvc.Name))
+			continue
+		}
+		pod := corev1.Pod{}
+		key := types.NamespacedName{Namespace: destNs, Name: getPrewarmPodName(pvc.Name)}
+		err := destClient.Get(context.TODO(), key, &pod)
+		if k8serror.IsNotFound(err) {
+			destPVC := corev1.PersistentVolumeClaim{}
+			err = destClient.Get(context.TODO(), types.NamespacedName{Namespace: destNs, Name: pvc.Name}, &destPVC)
+			if err != nil {
+				return false, failed, liberr.Wrap(err)
+			}
+			// no file system to write over on a raw block volume
+			if isBlockPVC(&destPVC) {
+				t.Log.Info("Not prewarming raw block destination PVC",
+					"persistentVolumeClaim", path.Join(destNs, pvc.Name))
+				continue
+			}
+			pod = t.getPrewarmPodTemplate(destPVC, image, privileged)
+			t.Log.Info("Creating Pod prewarming the destination PVC",
+				"pod", path.Join(pod.Namespace, pod.Name),
+				"persistentVolumeClaim", path.Join(destNs, pvc.Name))
+			err = destClient.Create(context.TODO(), &pod)
+			if err != nil && !k8serror.IsAlreadyExists(err) {
+				return false, failed, liberr.Wrap(err)
+			}
+			completed = false
+			continue
+		}
+		if err != nil {
+			return false, failed, liberr.Wrap(err)
+		}
+		switch pod.Status.Phase {
+		case corev1.PodSucceeded:
+		case corev1.PodFailed:
+			failed = append(failed, path.Join(destNs, pvc.Name))
+		default:
+			completed = false
+		}
+		pods = append(pods, pod)
+	}
+	if !completed {
+		return false, failed, nil
+	}
+	t.Owner.Status.PrewarmElapsedTime = getPrewarmElapsedTime(pods)
+	for i := range pods {
+		err := destClient.Delete(context.TODO(), &pods[i])
+		if err != nil && !k8serror.IsNotFound(err) {
+			return false, failed, liberr.Wrap(err)
+		}
+	}
+	return true, failed, nil
+}
+
+// getPrewarmPodName returns the name of the Pod prewarming the destination PVC
+func getPrewarmPodName(pvcName string) string {
+	return fmt.Sprintf("dvm-prewarm-%s", getMD5Hash(pvcName))
+}
+
+// getPrewarmPodTemplate returns the Pod writing zeros over the capacity of the destination PVC.
+// Writing stops early when the filesystem is full, which isn't a failure, any
+// other failure of dd fails the Pod.
+func (t *Task) getPrewarmPodTemplate(pvc corev1.PersistentVolumeClaim, image string, privileged bool) corev1.Pod {
+	runAsUser := int64(0)
+	trueBool := true
+	capacity := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	if bound, found := pvc.Status.Capacity[corev1.ResourceStorage]; found {
+		capacity = bound
+	}
+	file := path.Join("/mnt", pvc.Name, prewarmFile)
+	command := fmt.Sprintf("set -o pipefail; "+
+		"out=$(dd if=/dev/zero of=%s bs=1M count=%d conv=fsync 2>&1 | tail -n 4); rc=$?; "+
+		"echo \"$out\" | tail -n 1; rm -f %s; "+
+		"if [ $rc -ne 0 ] && echo \"$out\" | grep -q 'No space left on device'; then rc=0; fi; "+
+		"exit $rc",
+		file, capacity.Value()/(1024*1024), file)
+	labels := t.buildDVMLabels()
+	labels["purpose"] = DirectVolumeMigrationPrewarm
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      getPrewarmPodName(pvc.Name),
+			Namespace: pvc.Namespace,
+			Labels:    labels,
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			Volumes: []corev1.Volume{
+				{
+					Name: pvc.Name,
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+							ClaimName: pvc.Name,
+						},
+					},
+				},
+			},
+			Containers: []corev1.Container{
+				{
+					Name:    DirectVolumeMigrationPrewarm,
+					Image:   image,
+					Command: []string{"/bin/bash", "-c", command},
+					VolumeMounts: []corev1.VolumeMount{
+						{
+							Name:      pvc.Name,
+							MountPath: path.Join("/mnt", pvc.Name),
+						},
+					},
+					SecurityContext: &corev1.SecurityContext{
+						Privileged:             &privileged,
+						RunAsUser:              &runAsUser,
+						ReadOnlyRootFilesystem: &trueBool,
+						Capabilities: &corev1.Capabilities{
+							Drop: []corev1.Capability{"MKNOD", "SETPCAP"},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// getPrewarmElapsedTime returns the time elapsed from the creation of the first
+// prewarm Pod to the completion of the last one.
+func getPrewarmElapsedTime(pods []corev1.Pod) *metav1.Duration {
+	var started, finished time.Time
+	for _, pod := range pods {
+		if started.IsZero() || pod.CreationTimestamp.Time.Before(started) {
+			started = pod.CreationTimestamp.Time
+		}
+		for _, containerStatus := range pod.Status.ContainerStatuses {
+			terminated := containerStatus.State.Terminated
+			if terminated != nil && terminated.FinishedAt.Time.After(finished) {
+				finished = terminated.FinishedAt.Time
+			}
+		}
+	}
+	if started.IsZero() || finished.Before(started) {
+		return nil
+	}
+	return &metav1.Duration{Duration: finished.Sub(started).Round(time.Second)}
+}
@@ -0,0 +1,84 @@
+package directvolumemigration
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTask_getPrewarmPodTemplate(t *testing.T) {
+	task := &Task{
+		Owner: &migapi.DirectVolumeMigration{ObjectMeta: metav1.ObjectMeta{Name: "dvm", Namespace: migapi.OpenshiftMigrationNamespace}},
+	}
+	pvc := corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "pvc-0", Namespace: "ns"},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+			},
+		},
+		Status: corev1.PersistentVolumeClaimStatus{
+			Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("2Gi")},
+		},
+	}
+	pod := task.getPrewarmPodTemplate(pvc, "image", false)
+	if pod.Namespace != "ns" || pod.Name != getPrewarmPodName("pvc-0") {
+		t.Errorf("getPrewarmPodTemplate() pod = %s/%s", pod.Namespace, pod.Name)
+	}
+	if pod.Labels["app"] != DirectVolumeMigrationRsyncTransfer {
+		t.Errorf("getPrewarmPodTemplate() pod must be cleaned up with the Rsync resources, labels = %v", pod.Labels)
+	}
+	command := pod.Spec.Containers[0].Command[2]
+	if !strings.Contains(command, "count=2048") || !strings.Contains(command, "rm -f /mnt/pvc-0/"+prewarmFile) {
+		t.Errorf("getPrewarmPodTemplate() must write the bound capacity and remove the file, command = %s", command)
+	}
+	if !strings.HasPrefix(command, "set -o pipefail;") || !strings.HasSuffix(command, "exit $rc") {
+		t.Errorf("getPrewarmPodTemplate() must keep the return code of dd, command = %s", command)
+	}
+}
+
+func Test_getPrewarmElapsedTime(t *testing.T) {
+	start := time.Now().Add(-time.Hour)
+	getPod := func(created time.Time, finished time.Time) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.Time{Time: created}},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{
+					{State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{FinishedAt: metav1.Time{Time: finished}}}},
+				},
+			},
+		}
+	}
+	tests := []struct {
+		name string
+		pods []corev1.Pod
+		want *metav1.Duration
+	}{
+		{
+			name: "when there are no pods, should not report an elapsed time",
+			pods: []corev1.Pod{},
+			want: nil,
+		},
+		{
+			name: "when pods completed, should report the time from the first creation to the last completion",
+			pods: []corev1.Pod{
+				getPod(start.Add(time.Minute), start.Add(5*time.Minute)),
+				getPod(start, start.Add(10*time.Minute)),
+			},
+			want: &metav1.Duration{Duration: 10 * time.Minute},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := getPrewarmElapsedTime(tt.pods)
+			if (got == nil) != (tt.want == nil) || (got != nil && got.Duration != tt.want.Duration) {
+				t.Errorf("getPrewarmElapsedTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package directvolumemigration
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"path"
+	"regexp"
+	"strconv"
+
+	liberr "github.com/konveyor/controller/pkg/error"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// RsyncProtocolDebugOption makes the Rsync client print the protocol versions negotiated with the Rsync daemon
+	RsyncProtocolDebugOption = "--debug=PROTO"
+	// RsyncVersionCommand prints the version of the Rsync client before the transfer
+	RsyncVersionCommand = "rsync --version | head -n 1; "
+)
+
+var (
+	// RsyncVersionPattern matches the version and protocol version printed by rsync --version.
+	RsyncVersionPattern = regexp.MustCompile(`rsync\s+version\s+(\S+)\s+protocol version (\d+)`)
+	// RsyncProtocolPattern matches the protocol versions printed by the Rsync client
+	// once negotiated, the remote protocol is the one of the Rsync daemon.
+	RsyncProtocolPattern = regexp.MustCompile(`\(Client\) Protocol versions: remote=(\d+), negotiated=(\d+)`)
+)
+
+// parseRsyncProtocol returns the versions printed in the Rsync client logs, nil
+// when the protocol was not negotiated.
+func parseRsyncProtocol(logs io.Reader) (*migapi.RsyncProtocol, error) {
+	protocol := migapi.RsyncProtocol{}
+	negotiated := false
+	scanner := bufio.NewScanner(logs)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	scanner.Split(scanLogLines)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if match := RsyncVersionPattern.FindStringSubmatch(line); match != nil && protocol.ClientVersion == "" {
+			protocol.ClientVersion = match[1]
+			protocol.ClientProtocol, _ = strconv.Atoi(match[2])
+			continue
+		}
+		if match := RsyncProtocolPattern.FindStringSubmatch(line); match != nil {
+			// the protocol is negotiated again by each rsync process of the attempt
+			protocol.ServerProtocol, _ = strconv.Atoi(match[1])
+			protocol.NegotiatedProtocol, _ = strconv.Atoi(match[2])
+			negotiated = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !negotiated {
+		return nil, nil
+	}
+	return &protocol, nil
+}
+
+// getRsyncProtocol returns the Rsync versions and the protocol version
+// negotiated by the Rsync client Pod, read from its logs.
+func (t *Task) getRsyncProtocol(pod *corev1.Pod) (*migapi.RsyncProtocol, error) {
+	clientset, err := t.getSourceClientset()
+	if err != nil {
+		return nil, liberr.Wrap(err)
+	}
+	req := clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+		Container: DirectVolumeMigrationRsyncClient,
+	})
+	readCloser, err := req.Stream(context.TODO())
+	if err != nil {
+		return nil, liberr.Wrap(err)
+	}
+	defer readCloser.Close()
+	protocol, err := parseRsyncProtocol(readCloser)
+	if err != nil {
+		return nil, liberr.Wrap(err)
+	}
+	if protocol != nil {
+		t.Log.Info("Read the Rsync protocol version negotiated by the Rsync attempt.",
+			"pod", path.Join(pod.Namespace, pod.Name),
+			"clientVersion", protocol.ClientVersion,
+			"clientProtocol", protocol.ClientProtocol,
+			"serverProtocol", protocol.ServerProtocol,
+			"negotiatedProtocol", protocol.NegotiatedProtocol)
+	}
+	return protocol, nil
+}
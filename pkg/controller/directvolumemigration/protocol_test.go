@@ -0,0 +1,46 @@
+package directvolumemigration
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+)
+
+func Test_parseRsyncProtocol(t *testing.T) {
+	tests := []struct {
+		name string
+		logs []string
+		want *migapi.RsyncProtocol
+	}{
+		{
+			name: "when the protocol is negotiated, should return the versions",
+			logs: []string{
+				"rsync  version 3.1.3  protocol version 31",
+				"2021/06/01 10:00:00 [12] (Client) Protocol versions: remote=30, negotiated=30",
+				"        4.00K 100%    3.91MB/s    0:00:00 (xfr#1, to-chk=2/5)",
+			},
+			want: &migapi.RsyncProtocol{ClientVersion: "3.1.3", ClientProtocol: 31, ServerProtocol: 30, NegotiatedProtocol: 30},
+		},
+		{
+			name: "when the connection failed, should return nil",
+			logs: []string{
+				"rsync  version 3.1.3  protocol version 31",
+				"rsync: failed to connect to localhost (::1): Connection refused (111)",
+			},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseRsyncProtocol(strings.NewReader(strings.Join(tt.logs, "\n")))
+			if err != nil {
+				t.Fatalf("parseRsyncProtocol() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseRsyncProtocol() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package directvolumemigration
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+
+	liberr "github.com/konveyor/controller/pkg/error"
+	"github.com/konveyor/mig-controller/pkg/compat"
+	corev1 "k8s.io/api/core/v1"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Get whether the PVC can only be attached to a single node, a Pod mounting it
+// on another node would fail with a multi-attach error.
+func isSingleNodePVC(pvc *corev1.PersistentVolumeClaim) bool {
+	singleNode := false
+	for _, mode := range pvc.Spec.AccessModes {
+		switch mode {
+		case corev1.ReadWriteMany, corev1.ReadOnlyMany:
+			return false
+		case corev1.ReadWriteOnce:
+			singleNode = true
+		}
+	}
+	return singleNode
+}
+
+// Get the Pods of the destination cluster which are not completed and mount a
+// destination PVC that can only be attached to a single node, keyed by the
+// namespaced name of the Pods and valued by the PVC they mount.
+func (t *Task) getDestinationPVCHolders(destClient compat.Client) (map[string]*corev1.Pod, map[string]string, error) {
+	pods := map[string]*corev1.Pod{}
+	pvcs := map[string]string{}
+	for bothNs, vols := range t.getPVCNamespaceMap() {
+		destNs := getDestNs(bothNs)
+		singleNode := map[string]bool{}
+		for _, vol := range vols {
+			pvc := corev1.PersistentVolumeClaim{}
+			err := destClient.Get(context.TODO(), types.NamespacedName{Namespace: destNs, Name: vol.Name}, &pvc)
+			if k8serror.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return nil, nil, liberr.Wrap(err)
+			}
+			if isSingleNodePVC(&pvc) {
+				singleNode[vol.Name] = true
+			}
+		}
+		if len(singleNode) == 0 {
+			continue
+		}
+		podList := corev1.PodList{}
+		err := destClient.List(context.TODO(), &podList, k8sclient.InNamespace(destNs))
+		if err != nil {
+			return nil, nil, liberr.Wrap(err)
+		}
+		for i := range podList.Items {
+			pod := &podList.Items[i]
+			if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+				continue
+			}
+			for _, volume := range pod.Spec.Volumes {
+				if volume.PersistentVolumeClaim == nil || !singleNode[volume.PersistentVolumeClaim.ClaimName] {
+					continue
+				}
+				key := path.Join(pod.Namespace, pod.Name)
+				pods[key] = pod
+				pvcs[key] = volume.PersistentVolumeClaim.ClaimName
+				break
+			}
+		}
+	}
+	return pods, pvcs, nil
+}
+
+// Release the destination PVCs mounted by a Pod before the Rsync transfer Pods
+// mount them. The holders left by a prior failed transfer of this DVM, and the
+// stale Rsync Pods of the DVMs which are no longer running, are deleted. The
+// other holders, e.g. the Pods of an application or of a running DVM, conflict
+// with the transfer and are returned as "<namespace>/<pod>: <pvc>".
+// Returns true once no Pod holds the destination PVCs.
+func (t *Task) releaseDestinationPVCs() (bool, []string, error) {
+	destClient, err := t.getDestinationClient()
+	if err != nil {
+		return false, nil, liberr.Wrap(err)
+	}
+	return t.releaseDestinationPVCHolders(destClient)
+}
+
+// Delete the stale Pods of the destination cluster holding the destination PVCs.
+// Returns true once no Pod holds them, and the conflicting holders.
+func (t *Task) releaseDestinationPVCHolders(destClient compat.Client) (bool, []string, error) {
+	holders, pvcs, err := t.getDestinationPVCHolders(destClient)
+	if err != nil {
+		return false, nil, liberr.Wrap(err)
+	}
+	if len(holders) == 0 {
+		return true, nil, nil
+	}
+	active, err := t.getActiveRsyncTransferGenerations()
+	if err != nil {
+		return false, nil, liberr.Wrap(err)
+	}
+	conflicts := []string{}
+	for key, pod := range holders {
+		if pod.DeletionTimestamp != nil {
+			t.Log.Info("Waiting for the Pod holding a destination PVC to terminate.",
+				"pod", key, "persistentVolumeClaim", pvcs[key])
+			continue
+		}
+		if pod.Labels[RsyncTransferGenerationLabel] != string(t.Owner.UID) &&
+			!isStaleRsyncResource(pod.Labels, string(t.Owner.UID), active) {
+			conflicts = append(conflicts, fmt.Sprintf("%s: %s", key, pvcs[key]))
+			continue
+		}
+		t.Log.Info("Deleting the stale Pod holding a destination PVC.",
+			"pod", key, "persistentVolumeClaim", pvcs[key])
+		err := destClient.Delete(context.TODO(), pod, k8sclient.PropagationPolicy(metav1.DeletePropagationBackground))
+		if err != nil && !k8serror.IsNotFound(err) {
+			return false, nil, liberr.Wrap(err)
+		}
+	}
+	sort.Strings(conflicts)
+	return false, conflicts, nil
+}
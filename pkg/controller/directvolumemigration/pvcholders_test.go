@@ -0,0 +1,120 @@
+package directvolumemigration
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	fakecompat "github.com/konveyor/mig-controller/pkg/compat/fake"
+	corev1 "k8s.io/api/core/v1"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestTask_releaseDestinationPVCHolders(t *testing.T) {
+	pvc := func(name string, mode corev1.PersistentVolumeAccessMode) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "dest"},
+			Spec:       corev1.PersistentVolumeClaimSpec{AccessModes: []corev1.PersistentVolumeAccessMode{mode}},
+		}
+	}
+	pod := func(name string, generation string, claim string) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "dest"},
+			Spec: corev1.PodSpec{
+				Volumes: []corev1.Volume{
+					{
+						Name: claim,
+						VolumeSource: corev1.VolumeSource{
+							PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claim},
+						},
+					},
+				},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+		if generation != "" {
+			pod.Labels = map[string]string{
+				"app":                        DirectVolumeMigrationRsyncTransfer,
+				RsyncTransferGenerationLabel: generation,
+			}
+		}
+		return pod
+	}
+	running := &migapi.DirectVolumeMigration{
+		ObjectMeta: metav1.ObjectMeta{Name: "running", Namespace: migapi.OpenshiftMigrationNamespace, UID: "running-dvm"},
+	}
+	owner := &migapi.DirectVolumeMigration{
+		ObjectMeta: metav1.ObjectMeta{Name: "dvm", Namespace: migapi.OpenshiftMigrationNamespace, UID: "dvm"},
+		Spec: migapi.DirectVolumeMigrationSpec{
+			PersistentVolumeClaims: []migapi.PVCToMigrate{
+				{ObjectReference: &corev1.ObjectReference{Name: "rwo", Namespace: "src"}, TargetNamespace: "dest"},
+				{ObjectReference: &corev1.ObjectReference{Name: "rwx", Namespace: "src"}, TargetNamespace: "dest"},
+			},
+		},
+	}
+	tests := []struct {
+		name          string
+		holder        *corev1.Pod
+		wantReleased  bool
+		wantConflicts []string
+		wantDeleted   bool
+	}{
+		{
+			name:         "when no Pod holds the PVCs, should be released",
+			holder:       pod("other", "", "other-pvc"),
+			wantReleased: true,
+		},
+		{
+			name:         "when a ReadWriteMany PVC is held, should be released",
+			holder:       pod("app", "", "rwx"),
+			wantReleased: true,
+		},
+		{
+			name:        "when a stale receiver of the DVM holds a ReadWriteOnce PVC, should delete it",
+			holder:      pod("rsync-server", "dvm", "rwo"),
+			wantDeleted: true,
+		},
+		{
+			name:        "when a receiver of a finished DVM holds a ReadWriteOnce PVC, should delete it",
+			holder:      pod("rsync-server", "finished-dvm", "rwo"),
+			wantDeleted: true,
+		},
+		{
+			name:          "when a receiver of a running DVM holds a ReadWriteOnce PVC, should conflict",
+			holder:        pod("rsync-server", "running-dvm", "rwo"),
+			wantConflicts: []string{"dest/rsync-server: rwo"},
+		},
+		{
+			name:          "when an application Pod holds a ReadWriteOnce PVC, should conflict",
+			holder:        pod("app", "", "rwo"),
+			wantConflicts: []string{"dest/app: rwo"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &Task{
+				Log:    log.WithName("test-logger"),
+				Client: fake.NewFakeClient(running),
+				Owner:  owner,
+			}
+			destClient := fakecompat.NewFakeClient(pvc("rwo", corev1.ReadWriteOnce), pvc("rwx", corev1.ReadWriteMany), tt.holder)
+			released, conflicts, err := task.releaseDestinationPVCHolders(destClient)
+			if err != nil {
+				t.Fatalf("Task.releaseDestinationPVCHolders() error = %v", err)
+			}
+			if released != tt.wantReleased || len(conflicts) != len(tt.wantConflicts) ||
+				(len(conflicts) > 0 && !reflect.DeepEqual(conflicts, tt.wantConflicts)) {
+				t.Errorf("Task.releaseDestinationPVCHolders() = %v, %v, want %v, %v",
+					released, conflicts, tt.wantReleased, tt.wantConflicts)
+			}
+			err = destClient.Get(context.TODO(), types.NamespacedName{Namespace: "dest", Name: tt.holder.Name}, &corev1.Pod{})
+			if deleted := k8serror.IsNotFound(err); deleted != tt.wantDeleted {
+				t.Errorf("Task.releaseDestinationPVCHolders() deleted the holder = %v, want %v", deleted, tt.wantDeleted)
+			}
+		})
+	}
+}
@@ -2,40 +2,56 @@ package directvolumemigration
 
 import (
 	"context"
+	"fmt"
 	"path"
+	"time"
 
 	liberr "github.com/konveyor/controller/pkg/error"
 	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
 	"github.com/konveyor/mig-controller/pkg/settings"
 	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// DestinationPVCBindTimeout time allowed for destination PVCs to become bound
+const DestinationPVCBindTimeout = 10 * time.Minute
+
+// DestinationPVCRecreateTimeout time allowed for the existing destination PVCs
+// recreated by the existingPVCPolicy to be deleted
+const DestinationPVCRecreateTimeout = 5 * time.Minute
+
 func (t *Task) areSourcePVCsUnattached() error {
 	// This function provides state checking on source PVCs, make sure app is
 	// quiesced
 	return nil
 }
 
-func (t *Task) createDestinationPVCs() error {
+// Create the destination PVCs, the destination PVCs which already exist are
+// handled following the existingPVCPolicy. Returns whether all the destination
+// PVCs are created, false while recreated PVCs are being deleted.
+func (t *Task) createDestinationPVCs() (bool, error) {
 	// Get client for destination
 	destClient, err := t.getDestinationClient()
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	// Get client for source
 	srcClient, err := t.getSourceClient()
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	migration, err := t.Owner.GetMigrationForDVM(t.Client)
+	migration, err := t.getOwnerMigration()
 	if err != nil {
-		return liberr.Wrap(err)
+		return false, liberr.Wrap(err)
 	}
+	created := true
 	migrationUID := ""
 	if migration != nil {
 		migrationUID = string(migration.UID)
@@ -47,16 +63,36 @@ func (t *Task) createDestinationPVCs() error {
 		key := types.NamespacedName{Name: pvc.Name, Namespace: pvc.Namespace}
 		err = srcClient.Get(context.TODO(), key, &srcPVC)
 		if err != nil {
-			return err
+			return false, err
+		}
+		if srcPVC.DeletionTimestamp != nil {
+			return false, liberr.Wrap(&SourcePVCTerminatingError{PVC: path.Join(pvc.Namespace, pvc.Name)})
 		}
 
 		plan := t.PlanResources.MigPlan
 		matchingMigPlanPV := t.findMatchingPV(plan, pvc.Name, pvc.Namespace)
 		pvcRequestedCapacity := srcPVC.Spec.Resources.Requests[corev1.ResourceStorage]
 
+		if pvc.TargetStorageClass != "" {
+			sc := storagev1.StorageClass{}
+			err = destClient.Get(context.TODO(), types.NamespacedName{Name: pvc.TargetStorageClass}, &sc)
+			if k8serror.IsNotFound(err) {
+				return false, liberr.Wrap(&StorageClassMissingError{
+					StorageClass: pvc.TargetStorageClass,
+					PVC:          path.Join(pvc.Namespace, pvc.Name),
+				})
+			}
+			if err != nil {
+				return false, liberr.Wrap(err)
+			}
+		}
+
 		newSpec := srcPVC.Spec
 		newSpec.StorageClassName = &pvc.TargetStorageClass
-		newSpec.AccessModes = pvc.TargetAccessModes
+		// the access modes of the source PVC are kept when not set
+		if len(pvc.TargetAccessModes) > 0 {
+			newSpec.AccessModes = pvc.TargetAccessModes
+		}
 		newSpec.VolumeName = ""
 
 		// Adjusting destination PVC storage size request
@@ -100,9 +136,10 @@ func (t *Task) createDestinationPVCs() error {
 		// Create pvc on destination with same metadata + spec
 		destPVC := corev1.PersistentVolumeClaim{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      pvc.Name,
-				Namespace: destNs,
-				Labels:    pvcLabels,
+				Name:        pvc.Name,
+				Namespace:   destNs,
+				Labels:      pvcLabels,
+				Annotations: getDestinationPVCAnnotations(t.Owner.Spec.PVCAnnotations, srcPVC.Annotations),
 			},
 			Spec: newSpec,
 		}
@@ -112,14 +149,121 @@ func (t *Task) createDestinationPVCs() error {
 			"pvcStorageClassName", destPVC.Spec.StorageClassName,
 			"pvcAccessModes", destPVC.Spec.AccessModes,
 			"pvcRequests", destPVC.Spec.Resources.Requests)
+		if t.Owner.Spec.DeltaSinceCheckpoint {
+			err = t.setBaseCheckpoint(destClient, pvc, destNs)
+			if err != nil {
+				return false, liberr.Wrap(err)
+			}
+		}
+		decision := getCreatedPVCDecision(t.getPVCTransferState(pvc).DestinationPVC)
 		err = destClient.Create(context.TODO(), &destPVC)
 		if k8serror.IsAlreadyExists(err) {
 			t.Log.Info("PVC already exists on destination", "name", pvc.Name)
+			existing := corev1.PersistentVolumeClaim{}
+			err = destClient.Get(context.TODO(), types.NamespacedName{Namespace: destNs, Name: pvc.Name}, &existing)
+			if err != nil {
+				return false, err
+			}
+			decision, err = t.handleExistingPVC(destClient, pvc, &existing, &destPVC)
+			if err != nil {
+				return false, liberr.Wrap(err)
+			}
+			if decision == "" {
+				created = false
+				continue
+			}
+			destPVC = existing
 		} else if err != nil {
-			return err
+			return false, err
 		}
+		t.setPVCAccessModes(pvc, destPVC.Spec.AccessModes)
+		t.getPVCTransferState(pvc).DestinationPVC = decision
 	}
-	return nil
+	return created, nil
+}
+
+// getCreatedPVCDecision returns the decision recorded for a destination
+// PVC created by the migration, Recreated when the existing destination PVC it
+// replaces was deleted.
+func getCreatedPVCDecision(previous string) string {
+	if previous == migapi.DestinationPVCRecreated {
+		return migapi.DestinationPVCRecreated
+	}
+	return migapi.DestinationPVCCreated
+}
+
+// Handle a destination PVC which already exists following the existingPVCPolicy.
+// The destination PVCs created by this DVM in a previous reconcile are kept as
+// they are. Returns the decision recorded for the destination PVC, empty while
+// a recreated destination PVC is being deleted.
+func (t *Task) handleExistingPVC(destClient k8sclient.Client, pvc migapi.PVCToMigrate, existing, desired *corev1.PersistentVolumeClaim) (string, error) {
+	name := path.Join(existing.Namespace, existing.Name)
+	state := t.getPVCTransferState(pvc)
+	key, value := t.Owner.GetCorrelationLabel()
+	if existing.Labels[key] == value && existing.DeletionTimestamp == nil {
+		return getCreatedPVCDecision(state.DestinationPVC), nil
+	}
+	switch t.Owner.Spec.ExistingPVCPolicy {
+	case migapi.ExistingPVCFail:
+		return "", &DestinationPVCExistsError{PVC: name}
+	case migapi.ExistingPVCRecreate:
+		state.DestinationPVC = migapi.DestinationPVCRecreated
+		if existing.DeletionTimestamp != nil {
+			t.Log.Info("Waiting for existing destination PVC to be deleted before recreating it.",
+				"destPersistentVolumeClaim", name)
+			return "", nil
+		}
+		t.Log.Info("Deleting existing destination PVC to recreate it.",
+			"destPersistentVolumeClaim", name)
+		err := destClient.Delete(context.TODO(), existing)
+		if err != nil && !k8serror.IsNotFound(err) {
+			return "", liberr.Wrap(err)
+		}
+		return "", nil
+	default:
+		problems := getExistingPVCProblems(existing, desired)
+		if len(problems) > 0 {
+			return "", &DestinationPVCIncompatibleError{PVC: name, Problems: problems}
+		}
+		t.Log.Info("Adopting existing destination PVC.",
+			"destPersistentVolumeClaim", name)
+		return migapi.DestinationPVCAdopted, nil
+	}
+}
+
+// Get the problems preventing the adoption of an existing destination PVC in
+// place of the desired one: it must request at least the desired storage,
+// provide the desired access modes and, when a target storage class is set,
+// be of that storage class.
+func getExistingPVCProblems(existing, desired *corev1.PersistentVolumeClaim) []string {
+	problems := []string{}
+	if existing.DeletionTimestamp != nil {
+		problems = append(problems, "it is being deleted")
+	}
+	existingSize := existing.Spec.Resources.Requests[corev1.ResourceStorage]
+	desiredSize := desired.Spec.Resources.Requests[corev1.ResourceStorage]
+	if existingSize.Cmp(desiredSize) < 0 {
+		problems = append(problems, fmt.Sprintf("requests %s, less than %s", existingSize.String(), desiredSize.String()))
+	}
+	modes := map[corev1.PersistentVolumeAccessMode]bool{}
+	for _, mode := range existing.Spec.AccessModes {
+		modes[mode] = true
+	}
+	for _, mode := range desired.Spec.AccessModes {
+		if !modes[mode] {
+			problems = append(problems, fmt.Sprintf("access mode %s not provided", mode))
+		}
+	}
+	if desired.Spec.StorageClassName != nil && *desired.Spec.StorageClassName != "" {
+		existingClass := ""
+		if existing.Spec.StorageClassName != nil {
+			existingClass = *existing.Spec.StorageClassName
+		}
+		if existingClass != *desired.Spec.StorageClassName {
+			problems = append(problems, fmt.Sprintf("storage class %q, expected %q", existingClass, *desired.Spec.StorageClassName))
+		}
+	}
+	return problems
 }
 
 func (t *Task) getDestinationPVCs() error {
@@ -127,6 +271,169 @@ func (t *Task) getDestinationPVCs() error {
 	return nil
 }
 
+// getMissingDestinationPVCs returns the destination PVCs which don't exist.
+func (t *Task) getMissingDestinationPVCs() ([]string, error) {
+	missing := []string{}
+	destClient, err := t.getDestinationClient()
+	if err != nil {
+		return missing, liberr.Wrap(err)
+	}
+	for _, pvc := range t.Owner.Spec.PersistentVolumeClaims {
+		destNs := pvc.Namespace
+		if pvc.TargetNamespace != "" {
+			destNs = pvc.TargetNamespace
+		}
+		destPVC := corev1.PersistentVolumeClaim{}
+		err := destClient.Get(context.TODO(),
+			types.NamespacedName{Namespace: destNs, Name: pvc.Name}, &destPVC)
+		if k8serror.IsNotFound(err) {
+			missing = append(missing, path.Join(destNs, pvc.Name))
+			continue
+		}
+		if err != nil {
+			return missing, liberr.Wrap(err)
+		}
+	}
+	return missing, nil
+}
+
+// areDestinationPVCsBound checks whether all destination PVCs are bound.
+// PVCs of storage classes with WaitForFirstConsumer binding mode are only bound
+// once the Rsync transfer Pod is scheduled, those are not waited for.
+// Returns whether all PVCs are bound and the list of PVCs which are not.
+func (t *Task) areDestinationPVCsBound() (bool, []string, error) {
+	unbound := []string{}
+	destClient, err := t.getDestinationClient()
+	if err != nil {
+		return false, unbound, liberr.Wrap(err)
+	}
+	waitForFirstConsumer := map[string]bool{}
+	for _, pvc := range t.Owner.Spec.PersistentVolumeClaims {
+		destNs := pvc.Namespace
+		if pvc.TargetNamespace != "" {
+			destNs = pvc.TargetNamespace
+		}
+		destPVC := corev1.PersistentVolumeClaim{}
+		err := destClient.Get(context.TODO(),
+			types.NamespacedName{Namespace: destNs, Name: pvc.Name}, &destPVC)
+		if err != nil {
+			return false, unbound, liberr.Wrap(err)
+		}
+		if destPVC.Status.Phase == corev1.ClaimBound {
+			continue
+		}
+		if destPVC.Spec.StorageClassName != nil && *destPVC.Spec.StorageClassName != "" {
+			scName := *destPVC.Spec.StorageClassName
+			if _, found := waitForFirstConsumer[scName]; !found {
+				sc := storagev1.StorageClass{}
+				err := destClient.Get(context.TODO(), types.NamespacedName{Name: scName}, &sc)
+				if err != nil && !k8serror.IsNotFound(err) {
+					return false, unbound, liberr.Wrap(err)
+				}
+				waitForFirstConsumer[scName] = sc.VolumeBindingMode != nil &&
+					*sc.VolumeBindingMode == storagev1.VolumeBindingWaitForFirstConsumer
+			}
+			if waitForFirstConsumer[scName] {
+				continue
+			}
+		}
+		unbound = append(unbound, path.Join(destNs, pvc.Name))
+	}
+	return len(unbound) == 0, unbound, nil
+}
+
+// setSourcePVCCapacities reports the provisioned and used capacity of the source
+// PVCs in their Rsync operation status. The capacities are read from the ready
+// MigAnalytics of the plan, preferring those with an extended PV capacity analysis
+// which reports the usage. The capacities of PVCs not found in any analytic are not set.
+func (t *Task) setSourcePVCCapacities() error {
+	if t.PlanResources == nil || t.PlanResources.MigPlan == nil {
+		return nil
+	}
+	plan := t.PlanResources.MigPlan
+	analyticList := migapi.MigAnalyticList{}
+	err := t.Client.List(context.TODO(), &analyticList, k8sclient.InNamespace(plan.Namespace))
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	analytics := []migapi.MigAnalytic{}
+	for _, analytic := range analyticList.Items {
+		ref := analytic.Spec.MigPlanRef
+		if ref == nil || ref.Name != plan.Name || ref.Namespace != plan.Namespace || !analytic.Status.IsReady() {
+			continue
+		}
+		if analytic.Spec.AnalyzeExtendedPVCapacity {
+			analytics = append([]migapi.MigAnalytic{analytic}, analytics...)
+		} else {
+			analytics = append(analytics, analytic)
+		}
+	}
+	for _, pvc := range t.Owner.Spec.PersistentVolumeClaims {
+		capacity, used := findAnalyticPVCCapacity(analytics, pvc.Namespace, pvc.Name)
+		if capacity == nil {
+			continue
+		}
+		operation := t.Owner.Status.GetRsyncOperationStatusForPVC(&corev1.ObjectReference{
+			Namespace: pvc.Namespace,
+			Name:      pvc.Name,
+		})
+		operation.Capacity = capacity
+		operation.UsedCapacity = used
+		operation.UsedInodes = findAnalyticPVCUsedInodes(analytics, pvc.Namespace, pvc.Name)
+	}
+	return nil
+}
+
+// findAnalyticPVCCapacity returns the provisioned and used capacity of the PVC
+// reported by the first analytic listing it. The used capacity is only reported
+// by extended PV capacity analyses.
+func findAnalyticPVCCapacity(analytics []migapi.MigAnalytic, namespace string, name string) (*resource.Quantity, *resource.Quantity) {
+	for _, analytic := range analytics {
+		for _, ns := range analytic.Status.Analytics.Namespaces {
+			if ns.Namespace != namespace {
+				continue
+			}
+			for _, pv := range ns.PersistentVolumes {
+				if pv.Name != name {
+					continue
+				}
+				capacity := pv.ActualCapacity.DeepCopy()
+				if capacity.IsZero() {
+					capacity = pv.RequestedCapacity.DeepCopy()
+				}
+				if !analytic.Spec.AnalyzeExtendedPVCapacity {
+					return &capacity, nil
+				}
+				used := resource.NewQuantity(capacity.Value()*int64(pv.UsagePercentage)/100, resource.BinarySI)
+				return &capacity, used
+			}
+		}
+	}
+	return nil, nil
+}
+
+// findAnalyticPVCUsedInodes returns the number of inodes used on the volume of
+// the PVC reported by the first extended PV capacity analysis listing it, 0
+// when unknown.
+func findAnalyticPVCUsedInodes(analytics []migapi.MigAnalytic, namespace string, name string) int64 {
+	for _, analytic := range analytics {
+		if !analytic.Spec.AnalyzeExtendedPVCapacity {
+			continue
+		}
+		for _, ns := range analytic.Status.Analytics.Namespaces {
+			if ns.Namespace != namespace {
+				continue
+			}
+			for _, pv := range ns.PersistentVolumes {
+				if pv.Name == name {
+					return pv.UsedInodes
+				}
+			}
+		}
+	}
+	return 0
+}
+
 func (t *Task) findMatchingPV(plan *migapi.MigPlan, pvcName string, pvcNamespace string) *migapi.PV {
 	if plan != nil {
 		for i := range plan.Spec.PersistentVolumes.List {
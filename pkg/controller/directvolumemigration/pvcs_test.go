@@ -0,0 +1,125 @@
+package directvolumemigration
+
+import (
+	"context"
+	"testing"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func getDestinationPVC(size string, class string, modes ...corev1.PersistentVolumeAccessMode) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "ns"},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      modes,
+			StorageClassName: &class,
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(size)},
+			},
+		},
+	}
+}
+
+func Test_getExistingPVCProblems(t *testing.T) {
+	desired := getDestinationPVC("10Gi", "gp2", corev1.ReadWriteOnce)
+	tests := []struct {
+		name     string
+		existing *corev1.PersistentVolumeClaim
+		want     int
+	}{
+		{
+			name:     "when compatible, should have no problems",
+			existing: getDestinationPVC("20Gi", "gp2", corev1.ReadWriteOnce, corev1.ReadWriteMany),
+			want:     0,
+		},
+		{
+			name:     "when smaller, of another storage class and access mode, should report each",
+			existing: getDestinationPVC("5Gi", "standard", corev1.ReadWriteMany),
+			want:     3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getExistingPVCProblems(tt.existing, desired); len(got) != tt.want {
+				t.Errorf("getExistingPVCProblems() = %v, want %d problems", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTask_handleExistingPVC(t *testing.T) {
+	pvc := migapi.PVCToMigrate{ObjectReference: &corev1.ObjectReference{Name: "data", Namespace: "ns"}}
+	desired := getDestinationPVC("10Gi", "gp2", corev1.ReadWriteOnce)
+	owned := getDestinationPVC("10Gi", "gp2", corev1.ReadWriteOnce)
+	owned.Labels = (&migapi.DirectVolumeMigration{ObjectMeta: metav1.ObjectMeta{UID: "dvm"}}).GetCorrelationLabels()
+	tests := []struct {
+		name         string
+		policy       string
+		existing     *corev1.PersistentVolumeClaim
+		wantDecision string
+		wantErr      bool
+		wantDeleted  bool
+	}{
+		{
+			name:         "when compatible, should adopt",
+			existing:     getDestinationPVC("10Gi", "gp2", corev1.ReadWriteOnce),
+			wantDecision: migapi.DestinationPVCAdopted,
+		},
+		{
+			name:     "when incompatible, should not adopt",
+			policy:   migapi.ExistingPVCAdopt,
+			existing: getDestinationPVC("1Gi", "gp2", corev1.ReadWriteOnce),
+			wantErr:  true,
+		},
+		{
+			name:     "when failing, should fail",
+			policy:   migapi.ExistingPVCFail,
+			existing: getDestinationPVC("10Gi", "gp2", corev1.ReadWriteOnce),
+			wantErr:  true,
+		},
+		{
+			name:        "when recreating, should delete",
+			policy:      migapi.ExistingPVCRecreate,
+			existing:    getDestinationPVC("1Gi", "standard", corev1.ReadWriteMany),
+			wantDeleted: true,
+		},
+		{
+			name:         "when created by the DVM, should keep it whatever the policy",
+			policy:       migapi.ExistingPVCFail,
+			existing:     owned,
+			wantDecision: migapi.DestinationPVCCreated,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewFakeClient(tt.existing.DeepCopy())
+			task := &Task{
+				Log: log.WithName("test-logger"),
+				Owner: &migapi.DirectVolumeMigration{
+					ObjectMeta: metav1.ObjectMeta{UID: "dvm"},
+					Spec:       migapi.DirectVolumeMigrationSpec{ExistingPVCPolicy: tt.policy},
+				},
+			}
+			decision, err := task.handleExistingPVC(client, pvc, tt.existing, desired)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Task.handleExistingPVC() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if decision != tt.wantDecision {
+				t.Errorf("Task.handleExistingPVC() = %v, want %v", decision, tt.wantDecision)
+			}
+			err = client.Get(context.TODO(), types.NamespacedName{Namespace: "ns", Name: "data"}, &corev1.PersistentVolumeClaim{})
+			if deleted := k8serror.IsNotFound(err); deleted != tt.wantDeleted {
+				t.Errorf("Task.handleExistingPVC() deleted = %v, want %v", deleted, tt.wantDeleted)
+			}
+			if tt.wantDeleted && task.getPVCTransferState(pvc).DestinationPVC != migapi.DestinationPVCRecreated {
+				t.Errorf("Task.handleExistingPVC() recorded = %v, want %v", task.getPVCTransferState(pvc).DestinationPVC, migapi.DestinationPVCRecreated)
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+package directvolumemigration
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// updatePVCTransferStates reports the state of the transfer of each PVC of the
+// spec in the status, for tools to follow which PVCs remain to be migrated. A PVC
+// is transferring while an Rsync client Pod is running for it, the bytes it
+// transferred are estimated from the progress of that Pod and the size of the PVC,
+// the files it transferred from the file counts logged by that Pod.
+// The access modes and the decision recorded once the destination PVC is
+// created are kept, as are the checkpoints and the time a PVC entered its state
+// while it stays in that state.
+func (t *Task) updatePVCTransferStates() {
+	status := &t.Owner.Status
+	previous := map[string]migapi.PVCTransferState{}
+	for _, state := range status.PersistentVolumeClaims {
+		if state.PVCReference != nil {
+			previous[path.Join(state.PVCReference.Namespace, state.PVCReference.Name)] = state
+		}
+	}
+	now := metav1.Now()
+	running := map[string]*migapi.PodProgress{}
+	for _, pod := range status.RunningPods {
+		if pod.PVCReference != nil {
+			running[path.Join(pod.PVCReference.Namespace, pod.PVCReference.Name)] = pod
+		}
+	}
+	operations := map[string]*migapi.RsyncOperation{}
+	for _, operation := range status.RsyncOperations {
+		if operation.PVCReference != nil {
+			operations[operation.String()] = operation
+		}
+	}
+	states := []migapi.PVCTransferState{}
+	for _, pvc := range t.Owner.Spec.PersistentVolumeClaims {
+		key := path.Join(pvc.Namespace, pvc.Name)
+		state := migapi.PVCTransferState{
+			PVCReference:       &corev1.ObjectReference{Namespace: pvc.Namespace, Name: pvc.Name},
+			State:              migapi.PVCTransferPending,
+			AccessModes:        previous[key].AccessModes,
+			DestinationPVC:     previous[key].DestinationPVC,
+			BaseCheckpointTime: previous[key].BaseCheckpointTime,
+			CheckpointTime:     previous[key].CheckpointTime,
+		}
+		operation := operations[key]
+		switch {
+		case operation != nil && operation.Succeeded:
+			state.State = migapi.PVCTransferCompleted
+			complete := 100
+			state.ProgressPercent = &complete
+			state.FilesProgressPercent = &complete
+		case operation != nil && operation.Failed:
+			state.State = migapi.PVCTransferFailed
+		case running[key] != nil:
+			state.State = migapi.PVCTransferTransferring
+			state.TransferredBytes = getTransferredBytes(operation, running[key])
+			state.RemainingBytes = getRemainingBytes(operation, state.TransferredBytes)
+			if percent, found := getProgressPercent(running[key]); found {
+				state.ProgressPercent = &percent
+			}
+			setFilesProgress(&state, operation, running[key])
+		}
+		state.LastTransitionTime = &now
+		if last, found := previous[key]; found && last.State == state.State && last.LastTransitionTime != nil {
+			state.LastTransitionTime = last.LastTransitionTime
+		}
+		states = append(states, state)
+	}
+	status.PersistentVolumeClaims = states
+}
+
+// getTransferredBytes estimates the bytes transferred by a running Rsync Pod from
+// its progress and the size of the PVC. Returns nil when either is unknown.
+func getTransferredBytes(operation *migapi.RsyncOperation, pod *migapi.PodProgress) *resource.Quantity {
+	size := getPVCSize(operation)
+	if size == nil {
+		return nil
+	}
+	percent, found := getProgressPercent(pod)
+	if !found {
+		return nil
+	}
+	return resource.NewQuantity(size.Value()*int64(percent)/100, resource.BinarySI)
+}
+
+// getRemainingBytes estimates the bytes a running Rsync Pod has left to transfer
+// from the bytes it transferred and the size of the PVC. Returns nil when either
+// is unknown.
+func getRemainingBytes(operation *migapi.RsyncOperation, transferred *resource.Quantity) *resource.Quantity {
+	size := getPVCSize(operation)
+	if size == nil || transferred == nil {
+		return nil
+	}
+	remaining := size.Value() - transferred.Value()
+	if remaining < 0 {
+		remaining = 0
+	}
+	return resource.NewQuantity(remaining, resource.BinarySI)
+}
+
+// getPVCSize returns the used capacity of the volume of the PVC of an Rsync
+// operation, or its capacity when the usage isn't known.
+func getPVCSize(operation *migapi.RsyncOperation) *resource.Quantity {
+	if operation == nil {
+		return nil
+	}
+	if operation.UsedCapacity != nil {
+		return operation.UsedCapacity
+	}
+	return operation.Capacity
+}
+
+// setFilesProgress sets the files transferred and remaining of a transferring
+// PVC from the file counts logged by its running Rsync Pod. The files of the
+// PVC reported by the MigAnalytic of the plan are preferred to the files Rsync
+// found so far, which grow until Rsync completes the scan of the volume.
+func setFilesProgress(state *migapi.PVCTransferState, operation *migapi.RsyncOperation, pod *migapi.PodProgress) {
+	if pod.LastObservedTotalFiles <= 0 {
+		return
+	}
+	checked := pod.LastObservedTotalFiles - pod.LastObservedRemainingFiles
+	total := pod.LastObservedTotalFiles
+	if operation != nil && operation.UsedInodes > 0 {
+		total = operation.UsedInodes
+	}
+	remaining := total - checked
+	if remaining < 0 {
+		remaining = 0
+	}
+	percent := 100
+	if checked < total {
+		percent = int(checked * 100 / total)
+	}
+	transferred := pod.LastObservedTransferredFiles
+	state.TransferredFiles = &transferred
+	state.RemainingFiles = &remaining
+	state.TotalFiles = &total
+	state.FilesProgressPercent = &percent
+}
+
+// getProgressPercent parses the progress percentage of a running Rsync Pod.
+// Returns false when the Pod didn't report a valid percentage yet.
+func getProgressPercent(pod *migapi.PodProgress) (int, bool) {
+	percent, err := strconv.Atoi(strings.TrimSuffix(pod.LastObservedProgressPercent, "%"))
+	if err != nil || percent < 0 || percent > 100 {
+		return 0, false
+	}
+	return percent, true
+}
+
+// getPVCTransferProgress summarizes the transfer states of the PVCs for the
+// Running condition: the number of completed PVCs and an item describing the
+// state and progress of each PVC.
+func (t *Task) getPVCTransferProgress() (int, []string) {
+	completed := 0
+	items := []string{}
+	for _, state := range t.Owner.Status.PersistentVolumeClaims {
+		if state.PVCReference == nil {
+			continue
+		}
+		if state.State == migapi.PVCTransferCompleted {
+			completed++
+		}
+		item := fmt.Sprintf("%s: %s", path.Join(state.PVCReference.Namespace, state.PVCReference.Name), state.State)
+		if state.ProgressPercent != nil && state.State == migapi.PVCTransferTransferring {
+			item = fmt.Sprintf("%s %d%%", item, *state.ProgressPercent)
+		}
+		if state.RemainingFiles != nil && state.TotalFiles != nil && state.State == migapi.PVCTransferTransferring {
+			item = fmt.Sprintf("%s, %d/%d files remaining", item, *state.RemainingFiles, *state.TotalFiles)
+		}
+		items = append(items, item)
+	}
+	return completed, items
+}
+
+// setPVCAccessModes records the access modes of the destination PVC of a PVC
+// in its transfer state.
+func (t *Task) setPVCAccessModes(pvc migapi.PVCToMigrate, modes []corev1.PersistentVolumeAccessMode) {
+	t.getPVCTransferState(pvc).AccessModes = modes
+}
+
+// getPVCTransferState returns the transfer state of a PVC in the status, a
+// pending state is added when the PVC has none yet.
+func (t *Task) getPVCTransferState(pvc migapi.PVCToMigrate) *migapi.PVCTransferState {
+	status := &t.Owner.Status
+	for i := range status.PersistentVolumeClaims {
+		ref := status.PersistentVolumeClaims[i].PVCReference
+		if ref != nil && ref.Namespace == pvc.Namespace && ref.Name == pvc.Name {
+			return &status.PersistentVolumeClaims[i]
+		}
+	}
+	status.PersistentVolumeClaims = append(status.PersistentVolumeClaims, migapi.PVCTransferState{
+		PVCReference: &corev1.ObjectReference{Namespace: pvc.Namespace, Name: pvc.Name},
+		State:        migapi.PVCTransferPending,
+	})
+	return &status.PersistentVolumeClaims[len(status.PersistentVolumeClaims)-1]
+}
+
+// skipSucceededRsyncOperations marks the Rsync operations which succeeded as
+// skipped when the Rsync transfer is restarted, their PVCs are not transferred
+// again.
+func (t *Task) skipSucceededRsyncOperations() {
+	for _, operation := range t.Owner.Status.RsyncOperations {
+		if operation.Succeeded && !operation.Skipped {
+			operation.Skipped = true
+			t.Log.Info("Skipping PVC already transferred by a previous Rsync transfer.",
+				"persistentVolumeClaim", operation.String())
+		}
+	}
+}
+
+// setPVCsSkipped reports the PVCs skipped by a restarted Rsync transfer with
+// the time they were transferred.
+func (t *Task) setPVCsSkipped() {
+	skipped := []string{}
+	for _, operation := range t.Owner.Status.RsyncOperations {
+		if !operation.Skipped {
+			continue
+		}
+		item := operation.String()
+		if operation.CompletionTimestamp != nil {
+			item = fmt.Sprintf("%s completed at %s", item, operation.CompletionTimestamp.UTC().Format(time.RFC3339))
+		}
+		skipped = append(skipped, item)
+	}
+	if len(skipped) == 0 {
+		return
+	}
+	t.Owner.Status.SetCondition(migapi.Condition{
+		Type:     PVCsSkipped,
+		Status:   True,
+		Reason:   AlreadyTransferred,
+		Category: Advisory,
+		Message:  PVCsSkippedMessage,
+		Items:    skipped,
+	})
+}
@@ -0,0 +1,223 @@
+package directvolumemigration
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTask_updatePVCTransferStates(t *testing.T) {
+	gi := resource.MustParse("1Gi")
+	pvc := func(name string) *corev1.ObjectReference {
+		return &corev1.ObjectReference{Namespace: "ns", Name: name}
+	}
+	task := &Task{
+		Owner: &migapi.DirectVolumeMigration{
+			Spec: migapi.DirectVolumeMigrationSpec{
+				PersistentVolumeClaims: []migapi.PVCToMigrate{
+					{ObjectReference: pvc("completed")},
+					{ObjectReference: pvc("failed")},
+					{ObjectReference: pvc("transferring")},
+					{ObjectReference: pvc("unknown-size")},
+					{ObjectReference: pvc("pending")},
+				},
+			},
+			Status: migapi.DirectVolumeMigrationStatus{
+				RsyncOperations: []*migapi.RsyncOperation{
+					{PVCReference: pvc("completed"), Succeeded: true},
+					{PVCReference: pvc("failed"), Failed: true},
+					{PVCReference: pvc("transferring"), UsedCapacity: &gi},
+					{PVCReference: pvc("unknown-size")},
+				},
+				RunningPods: []*migapi.PodProgress{
+					{PVCReference: pvc("transferring"), LastObservedProgressPercent: "25%"},
+					{PVCReference: pvc("unknown-size"), LastObservedProgressPercent: "50%"},
+				},
+			},
+		},
+	}
+	task.updatePVCTransferStates()
+	states := map[string]string{}
+	for _, state := range task.Owner.Status.PersistentVolumeClaims {
+		states[state.PVCReference.Name] = state.State
+		switch state.PVCReference.Name {
+		case "transferring":
+			if state.TransferredBytes == nil || state.TransferredBytes.Value() != gi.Value()/4 {
+				t.Errorf("Task.updatePVCTransferStates() transferred bytes = %v, want %v", state.TransferredBytes, gi.Value()/4)
+			}
+		default:
+			if state.TransferredBytes != nil {
+				t.Errorf("Task.updatePVCTransferStates() transferred bytes of %s = %v, want nil", state.PVCReference.Name, state.TransferredBytes)
+			}
+		}
+	}
+	want := map[string]string{
+		"completed":    migapi.PVCTransferCompleted,
+		"failed":       migapi.PVCTransferFailed,
+		"transferring": migapi.PVCTransferTransferring,
+		"unknown-size": migapi.PVCTransferTransferring,
+		"pending":      migapi.PVCTransferPending,
+	}
+	if !reflect.DeepEqual(states, want) {
+		t.Errorf("Task.updatePVCTransferStates() = %v, want %v", states, want)
+	}
+	if remaining := task.Owner.Status.GetRemainingPVCs(); len(remaining) != 3 {
+		t.Errorf("GetRemainingPVCs() = %v, want the transferring and pending PVCs", remaining)
+	}
+}
+
+func TestTask_setPVCAccessModes(t *testing.T) {
+	rwx := []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}
+	pvc := migapi.PVCToMigrate{ObjectReference: &corev1.ObjectReference{Namespace: "ns", Name: "pvc-1"}}
+	task := &Task{
+		Owner: &migapi.DirectVolumeMigration{
+			Spec: migapi.DirectVolumeMigrationSpec{PersistentVolumeClaims: []migapi.PVCToMigrate{pvc}},
+		},
+	}
+	task.setPVCAccessModes(pvc, rwx)
+	task.updatePVCTransferStates()
+	states := task.Owner.Status.PersistentVolumeClaims
+	if len(states) != 1 || !reflect.DeepEqual(states[0].AccessModes, rwx) {
+		t.Errorf("Task.updatePVCTransferStates() = %v, want the recorded access modes kept", states)
+	}
+}
+
+func TestTask_setPVCsSkipped(t *testing.T) {
+	completed := metav1.NewTime(time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC))
+	pvc := func(name string) *corev1.ObjectReference {
+		return &corev1.ObjectReference{Namespace: "ns", Name: name}
+	}
+	task := &Task{
+		Log: log.WithName("test-logger"),
+		Owner: &migapi.DirectVolumeMigration{
+			Status: migapi.DirectVolumeMigrationStatus{
+				RsyncOperations: []*migapi.RsyncOperation{
+					{PVCReference: pvc("completed"), Succeeded: true, CompletionTimestamp: &completed},
+					{PVCReference: pvc("failed"), Failed: true},
+				},
+			},
+		},
+	}
+	task.setPVCsSkipped()
+	if task.Owner.Status.HasCondition(PVCsSkipped) {
+		t.Errorf("Task.setPVCsSkipped() must not report PVCs before the transfer is restarted")
+	}
+	task.skipSucceededRsyncOperations()
+	task.setPVCsSkipped()
+	condition := task.Owner.Status.FindCondition(PVCsSkipped)
+	want := []string{"ns/completed completed at 2021-06-01T10:00:00Z"}
+	if condition == nil || !reflect.DeepEqual(condition.Items, want) {
+		t.Errorf("Task.setPVCsSkipped() condition = %v, want items %v", condition, want)
+	}
+}
+
+func TestTask_getPVCTransferProgress(t *testing.T) {
+	since := metav1.NewTime(time.Now().Add(-time.Hour))
+	pvc := func(name string) *corev1.ObjectReference {
+		return &corev1.ObjectReference{Namespace: "ns", Name: name}
+	}
+	task := &Task{
+		Owner: &migapi.DirectVolumeMigration{
+			Spec: migapi.DirectVolumeMigrationSpec{
+				PersistentVolumeClaims: []migapi.PVCToMigrate{
+					{ObjectReference: pvc("completed")},
+					{ObjectReference: pvc("transferring")},
+					{ObjectReference: pvc("pending")},
+				},
+			},
+			Status: migapi.DirectVolumeMigrationStatus{
+				PersistentVolumeClaims: []migapi.PVCTransferState{
+					{PVCReference: pvc("transferring"), State: migapi.PVCTransferTransferring, LastTransitionTime: &since},
+					{PVCReference: pvc("pending"), State: migapi.PVCTransferPending, LastTransitionTime: &since},
+				},
+				RsyncOperations: []*migapi.RsyncOperation{
+					{PVCReference: pvc("completed"), Succeeded: true},
+					{PVCReference: pvc("transferring")},
+				},
+				RunningPods: []*migapi.PodProgress{
+					{PVCReference: pvc("transferring"), LastObservedProgressPercent: "90%"},
+				},
+			},
+		},
+	}
+	task.updatePVCTransferStates()
+	for _, state := range task.Owner.Status.PersistentVolumeClaims {
+		if state.LastTransitionTime == nil {
+			t.Fatalf("Task.updatePVCTransferStates() transition time of %s not set", state.PVCReference.Name)
+		}
+		kept := state.LastTransitionTime.Equal(&since)
+		if kept != (state.PVCReference.Name != "completed") {
+			t.Errorf("Task.updatePVCTransferStates() transition time of %s = %v, want it kept only while in the same state", state.PVCReference.Name, state.LastTransitionTime)
+		}
+	}
+	completed, items := task.getPVCTransferProgress()
+	want := []string{"ns/completed: Completed", "ns/transferring: Transferring 90%", "ns/pending: Pending"}
+	if completed != 1 || !reflect.DeepEqual(items, want) {
+		t.Errorf("Task.getPVCTransferProgress() = %v, %v, want 1, %v", completed, items, want)
+	}
+}
+
+func Test_setFilesProgress(t *testing.T) {
+	pod := &migapi.PodProgress{LastObservedTransferredFiles: 150, LastObservedRemainingFiles: 600, LastObservedTotalFiles: 1000}
+	tests := []struct {
+		name          string
+		operation     *migapi.RsyncOperation
+		pod           *migapi.PodProgress
+		wantRemaining int64
+		wantTotal     int64
+		wantPercent   int
+	}{
+		{
+			name:          "when the files of the PVC are not reported, should use the files found by Rsync",
+			operation:     &migapi.RsyncOperation{},
+			pod:           pod,
+			wantRemaining: 600,
+			wantTotal:     1000,
+			wantPercent:   40,
+		},
+		{
+			name:          "when the files of the PVC are reported, should use them",
+			operation:     &migapi.RsyncOperation{UsedInodes: 4000},
+			pod:           pod,
+			wantRemaining: 3600,
+			wantTotal:     4000,
+			wantPercent:   10,
+		},
+		{
+			name:          "when Rsync checked more files than reported, should not go below zero",
+			operation:     &migapi.RsyncOperation{UsedInodes: 300},
+			pod:           pod,
+			wantRemaining: 0,
+			wantTotal:     300,
+			wantPercent:   100,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := migapi.PVCTransferState{}
+			setFilesProgress(&state, tt.operation, tt.pod)
+			if state.TransferredFiles == nil || *state.TransferredFiles != tt.pod.LastObservedTransferredFiles {
+				t.Errorf("setFilesProgress() transferred files = %v, want %v", state.TransferredFiles, tt.pod.LastObservedTransferredFiles)
+			}
+			if state.RemainingFiles == nil || *state.RemainingFiles != tt.wantRemaining {
+				t.Errorf("setFilesProgress() remaining files = %v, want %v", state.RemainingFiles, tt.wantRemaining)
+			}
+			if state.TotalFiles == nil || *state.TotalFiles != tt.wantTotal {
+				t.Errorf("setFilesProgress() total files = %v, want %v", state.TotalFiles, tt.wantTotal)
+			}
+			if state.FilesProgressPercent == nil || *state.FilesProgressPercent != tt.wantPercent {
+				t.Errorf("setFilesProgress() files progress = %v, want %v", state.FilesProgressPercent, tt.wantPercent)
+			}
+		})
+	}
+	state := migapi.PVCTransferState{}
+	setFilesProgress(&state, &migapi.RsyncOperation{UsedInodes: 4000}, &migapi.PodProgress{})
+	if state.TotalFiles != nil || state.FilesProgressPercent != nil {
+		t.Errorf("setFilesProgress() without file counts = %v, %v, want nil", state.TotalFiles, state.FilesProgressPercent)
+	}
+}
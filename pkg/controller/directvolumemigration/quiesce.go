@@ -0,0 +1,107 @@
+package directvolumemigration
+
+import (
+	"context"
+	"path"
+	"sort"
+
+	liberr "github.com/konveyor/controller/pkg/error"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Get whether a running Pod mounts one of the PVCs read-write. Pods created by
+// the migration, Rsync client Pods and stage Pods, are not workloads.
+func isMountingPVCReadWrite(pod *corev1.Pod, pvcs map[string]bool) bool {
+	if pod.Status.Phase != corev1.PodRunning {
+		return false
+	}
+	if pod.Labels["app"] == DirectVolumeMigrationRsyncTransfer || pod.Labels[migapi.StagePodLabel] == migapi.True {
+		return false
+	}
+	volumes := map[string]bool{}
+	for _, volume := range pod.Spec.Volumes {
+		claim := volume.PersistentVolumeClaim
+		if claim != nil && !claim.ReadOnly && pvcs[claim.ClaimName] {
+			volumes[volume.Name] = true
+		}
+	}
+	for _, container := range pod.Spec.Containers {
+		for _, mount := range container.VolumeMounts {
+			if volumes[mount.Name] && !mount.ReadOnly {
+				return true
+			}
+		}
+		for _, device := range container.VolumeDevices {
+			if volumes[device.Name] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Get the running Pods of the source cluster mounting a migrated PVC
+// read-write, the source data keeps changing while it is copied.
+func (t *Task) getSourcePodsNotQuiesced() ([]string, error) {
+	srcClient, err := t.getSourceClient()
+	if err != nil {
+		return nil, liberr.Wrap(err)
+	}
+	pvcs := map[string]map[string]bool{}
+	for _, pvc := range t.Owner.Spec.PersistentVolumeClaims {
+		if pvcs[pvc.Namespace] == nil {
+			pvcs[pvc.Namespace] = map[string]bool{}
+		}
+		pvcs[pvc.Namespace][pvc.Name] = true
+	}
+	pods := []string{}
+	for ns, names := range pvcs {
+		podList := corev1.PodList{}
+		err = srcClient.List(context.TODO(), &podList, k8sclient.InNamespace(ns))
+		if err != nil {
+			return nil, liberr.Wrap(err)
+		}
+		for i := range podList.Items {
+			if isMountingPVCReadWrite(&podList.Items[i], names) {
+				pods = append(pods, path.Join(ns, podList.Items[i].Name))
+			}
+		}
+	}
+	sort.Strings(pods)
+	return pods, nil
+}
+
+// Warn with the SourceNotQuiesced condition while PVCs are copied from a live
+// source. The migration proceeds, the point-in-time consistency of the data
+// is only guaranteed when no Pod writes to the PVCs. When the MigMigration
+// requested the Pods to be quiesced, the condition reports that the quiesce
+// was not honored.
+func (t *Task) setSourceNotQuiesced() error {
+	pods, err := t.getSourcePodsNotQuiesced()
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	if len(pods) == 0 {
+		return nil
+	}
+	reason, message := NotQuiesced, SourceNotQuiescedMessage
+	migration, err := t.getOwnerMigration()
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	if migration != nil && migration.Spec.QuiescePods {
+		reason, message = QuiesceNotHonored, QuiesceNotHonoredMessage
+	}
+	t.Log.Info("Source PVCs are mounted read-write by running Pods during the transfer.", "pods", pods)
+	t.Owner.Status.SetCondition(migapi.Condition{
+		Type:     SourceNotQuiesced,
+		Status:   True,
+		Reason:   reason,
+		Category: Warn,
+		Message:  message,
+		Items:    pods,
+	})
+	return nil
+}
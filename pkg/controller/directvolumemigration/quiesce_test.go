@@ -0,0 +1,88 @@
+package directvolumemigration
+
+import (
+	"testing"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_isMountingPVCReadWrite(t *testing.T) {
+	getPod := func(phase corev1.PodPhase, labels map[string]string, claimReadOnly bool, mountReadOnly bool) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "ns", Labels: labels},
+			Spec: corev1.PodSpec{
+				Volumes: []corev1.Volume{
+					{
+						Name: "data",
+						VolumeSource: corev1.VolumeSource{
+							PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "pvc-1", ReadOnly: claimReadOnly},
+						},
+					},
+				},
+				Containers: []corev1.Container{
+					{Name: "app", VolumeMounts: []corev1.VolumeMount{{Name: "data", MountPath: "/data", ReadOnly: mountReadOnly}}},
+				},
+			},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+	}
+	pvcs := map[string]bool{"pvc-1": true}
+	tests := []struct {
+		name string
+		pod  *corev1.Pod
+		pvcs map[string]bool
+		want bool
+	}{
+		{
+			name: "when a running Pod mounts the PVC read-write, should be true",
+			pod:  getPod(corev1.PodRunning, nil, false, false),
+			pvcs: pvcs,
+			want: true,
+		},
+		{
+			name: "when the Pod is not running, should be false",
+			pod:  getPod(corev1.PodSucceeded, nil, false, false),
+			pvcs: pvcs,
+			want: false,
+		},
+		{
+			name: "when the claim is read-only, should be false",
+			pod:  getPod(corev1.PodRunning, nil, true, false),
+			pvcs: pvcs,
+			want: false,
+		},
+		{
+			name: "when the mount is read-only, should be false",
+			pod:  getPod(corev1.PodRunning, nil, false, true),
+			pvcs: pvcs,
+			want: false,
+		},
+		{
+			name: "when the PVC is not migrated, should be false",
+			pod:  getPod(corev1.PodRunning, nil, false, false),
+			pvcs: map[string]bool{"pvc-2": true},
+			want: false,
+		},
+		{
+			name: "when the Pod is an Rsync client Pod, should be false",
+			pod:  getPod(corev1.PodRunning, map[string]string{"app": DirectVolumeMigrationRsyncTransfer}, false, false),
+			pvcs: pvcs,
+			want: false,
+		},
+		{
+			name: "when the Pod is a stage Pod, should be false",
+			pod:  getPod(corev1.PodRunning, map[string]string{migapi.StagePodLabel: migapi.True}, false, false),
+			pvcs: pvcs,
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isMountingPVCReadWrite(tt.pod, tt.pvcs); got != tt.want {
+				t.Errorf("isMountingPVCReadWrite() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
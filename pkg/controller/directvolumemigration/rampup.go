@@ -0,0 +1,65 @@
+package directvolumemigration
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// BwLimitRampUpSteps number of steps the bandwidth limit is raised in, the
+	// transfer runs at the full limit from the last step
+	BwLimitRampUpSteps = 4
+	// TimeoutExitCode exit code of the timeout command when the command timed out
+	TimeoutExitCode = 124
+)
+
+// Get the duration over which the bandwidth limit of the Rsync transfer is
+// raised to the limit, 0 when not ramped up. A transfer without bandwidth
+// limit isn't ramped up.
+func (t *Task) getRsyncBwLimitRampUp() time.Duration {
+	if t.Owner.Spec.RsyncBwLimitRampUp == nil || t.getRsyncBwLimit() <= 0 {
+		return 0
+	}
+	return t.Owner.Spec.RsyncBwLimitRampUp.Duration
+}
+
+// getBwLimitRampUpCommand returns the bash commands running the transfer at a
+// quarter, half and three quarters of the bandwidth limit, each for an equal
+// part of the ramp up, before the transfer at the full limit. Each step is
+// interrupted by the timeout command and the next one resumes the partially
+// transferred files. The exit code of a step completing the transfer is kept.
+func getBwLimitRampUpCommand(rsyncOptions []string, source string, destination string, rampUp time.Duration, transfer string) string {
+	bwLimit := 0
+	for _, option := range rsyncOptions {
+		if strings.HasPrefix(option, "--bwlimit=") {
+			bwLimit, _ = strconv.Atoi(strings.TrimPrefix(option, "--bwlimit="))
+		}
+	}
+	stepSeconds := int(rampUp.Seconds()) / BwLimitRampUpSteps
+	if bwLimit <= 0 || stepSeconds < 1 {
+		return transfer
+	}
+	steps := []string{}
+	for i := 1; i < BwLimitRampUpSteps; i++ {
+		limit := bwLimit * i / BwLimitRampUpSteps
+		if limit < 1 {
+			limit = 1
+		}
+		step := []string{"timeout", fmt.Sprintf("%ds", stepSeconds), "rsync"}
+		for _, option := range rsyncOptions {
+			if strings.HasPrefix(option, "--bwlimit=") {
+				continue
+			}
+			step = append(step, option)
+		}
+		step = append(step, fmt.Sprintf("--bwlimit=%d", limit))
+		if !hasRsyncOption(step, "--partial") && !hasRsyncOption(step, "-P") {
+			step = append(step, "--partial")
+		}
+		step = append(step, source, destination)
+		steps = append(steps, fmt.Sprintf("%s; rc=$?; if [ $rc -ne %d ]; then exit $rc; fi; ", strings.Join(step, " "), TimeoutExitCode))
+	}
+	return strings.Join(steps, "") + transfer
+}
@@ -0,0 +1,71 @@
+package directvolumemigration
+
+import (
+	"testing"
+	"time"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_getBwLimitRampUpCommand(t *testing.T) {
+	options := []string{"--bwlimit=1000", "--archive"}
+	transfer := "rsync --bwlimit=1000 --archive /mnt/src/ rsync://root@localhost/dst"
+	tests := []struct {
+		name    string
+		options []string
+		rampUp  time.Duration
+		want    string
+	}{
+		{
+			name:    "when ramped up, should raise the limit in steps before the transfer",
+			options: options,
+			rampUp:  2 * time.Minute,
+			want: "timeout 30s rsync --archive --bwlimit=250 --partial /mnt/src/ rsync://root@localhost/dst; rc=$?; if [ $rc -ne 124 ]; then exit $rc; fi; " +
+				"timeout 30s rsync --archive --bwlimit=500 --partial /mnt/src/ rsync://root@localhost/dst; rc=$?; if [ $rc -ne 124 ]; then exit $rc; fi; " +
+				"timeout 30s rsync --archive --bwlimit=750 --partial /mnt/src/ rsync://root@localhost/dst; rc=$?; if [ $rc -ne 124 ]; then exit $rc; fi; " +
+				transfer,
+		},
+		{
+			name:    "when the transfer isn't limited, should only run the transfer",
+			options: []string{"--archive"},
+			rampUp:  2 * time.Minute,
+			want:    transfer,
+		},
+		{
+			name:    "when the ramp up is shorter than a second per step, should only run the transfer",
+			options: options,
+			rampUp:  time.Second,
+			want:    transfer,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getBwLimitRampUpCommand(tt.options, "/mnt/src/", "rsync://root@localhost/dst", tt.rampUp, transfer); got != tt.want {
+				t.Errorf("getBwLimitRampUpCommand() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTask_getRsyncBwLimitRampUp(t *testing.T) {
+	bwLimit, noLimit := 1024, 0
+	rampUp := &metav1.Duration{Duration: time.Minute}
+	tests := []struct {
+		name string
+		spec migapi.DirectVolumeMigrationSpec
+		want time.Duration
+	}{
+		{name: "when not set, should not ramp up", spec: migapi.DirectVolumeMigrationSpec{RsyncBwLimit: &bwLimit}, want: 0},
+		{name: "when set with a limit, should ramp up", spec: migapi.DirectVolumeMigrationSpec{RsyncBwLimit: &bwLimit, RsyncBwLimitRampUp: rampUp}, want: time.Minute},
+		{name: "when set without a limit, should not ramp up", spec: migapi.DirectVolumeMigrationSpec{RsyncBwLimit: &noLimit, RsyncBwLimitRampUp: rampUp}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &Task{Owner: &migapi.DirectVolumeMigration{Spec: tt.spec}}
+			if got := task.getRsyncBwLimitRampUp(); got != tt.want {
+				t.Errorf("Task.getRsyncBwLimitRampUp() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
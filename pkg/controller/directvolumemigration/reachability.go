@@ -0,0 +1,96 @@
+package directvolumemigration
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	liberr "github.com/konveyor/controller/pkg/error"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// SourceProbeTimeout timeout of the request checking the API server of the source cluster is reachable.
+var SourceProbeTimeout = time.Duration(time.Second * 10)
+
+// MaxSourceUnreachableBackOff longest delay between the checks of the source
+// cluster while it is unreachable, the transfer resumes within this delay.
+var MaxSourceUnreachableBackOff = time.Duration(time.Minute)
+
+// probeSourceCluster checks the API server of the source cluster serves its version.
+var probeSourceCluster = func(clientset kubernetes.Interface) error {
+	return clientset.Discovery().RESTClient().Get().AbsPath("/version").Timeout(SourceProbeTimeout).Do(context.TODO()).Error()
+}
+
+// getSourceClusterProbe returns the probe of the API server of the source cluster.
+var getSourceClusterProbe = func(t *Task) (func() error, error) {
+	clientset, err := t.getSourceClientset()
+	if err != nil {
+		return nil, err
+	}
+	return func() error { return probeSourceCluster(clientset) }, nil
+}
+
+// Check the source cluster is reachable during the transfer. While it isn't,
+// the transfer is paused: the Rsync operations are neither processed nor
+// retried, and the DVM is requeued with a delay doubling up to
+// MaxSourceUnreachableBackOff. The Rsync attempts failed during the outage are
+// retried once the source cluster is reachable again, resuming the partially
+// transferred files. Returns whether the source cluster is reachable.
+func (t *Task) checkSourceClusterReachable() (bool, error) {
+	probe, err := getSourceClusterProbe(t)
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	cluster := t.Owner.Spec.SrcMigClusterRef.Name
+	// the condition of the previous reconcile tells when the outage started
+	t.Owner.Status.StageCondition(SourceClusterUnreachable)
+	unreachable := t.Owner.Status.FindCondition(SourceClusterUnreachable)
+	err = probe()
+	if err == nil {
+		if unreachable != nil {
+			outage := time.Since(unreachable.LastTransitionTime.Time).Round(time.Second)
+			t.Log.Info("Source cluster is reachable again, resuming the transfer.",
+				"cluster", cluster,
+				"outage", outage.String())
+			t.Owner.Status.DeleteCondition(SourceClusterUnreachable)
+			t.Owner.Status.SetCondition(migapi.Condition{
+				Type:     SourceClusterRecovered,
+				Status:   True,
+				Reason:   Recovered,
+				Category: Advisory,
+				Message:  fmt.Sprintf(SourceClusterRecoveredMessage, cluster, outage.String()),
+				Durable:  true,
+			})
+		}
+		return true, nil
+	}
+	delay := PollReQ
+	if unreachable != nil {
+		if elapsed := time.Since(unreachable.LastTransitionTime.Time); elapsed > delay {
+			delay = elapsed
+		}
+	}
+	if delay > MaxSourceUnreachableBackOff {
+		delay = MaxSourceUnreachableBackOff
+	}
+	t.Log.Info("Source cluster is unreachable, pausing the transfer.",
+		"cluster", cluster,
+		"error", err.Error(),
+		"retryAfter", delay.String())
+	t.Owner.Status.SetCondition(migapi.Condition{
+		Type:     SourceClusterUnreachable,
+		Status:   True,
+		Reason:   NotReady,
+		Category: Warn,
+		Message:  fmt.Sprintf(SourceClusterUnreachableMessage, cluster),
+	})
+	t.Requeue = delay
+	return false, nil
+}
+
+// Get whether the transfer resumed after the source cluster was unreachable,
+// the Rsync attempts then resume the partially transferred files.
+func (t *Task) hasSourceClusterRecovered() bool {
+	return t.Owner.Status.HasCondition(SourceClusterRecovered)
+}
@@ -0,0 +1,88 @@
+package directvolumemigration
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTask_checkSourceClusterReachable(t *testing.T) {
+	probe := getSourceClusterProbe
+	defer func() { getSourceClusterProbe = probe }()
+	unreachable := migapi.Condition{
+		Type:               SourceClusterUnreachable,
+		Status:             True,
+		Reason:             NotReady,
+		Category:           Warn,
+		Message:            fmt.Sprintf(SourceClusterUnreachableMessage, "source"),
+		LastTransitionTime: metav1.NewTime(time.Now().Add(-10 * time.Minute)),
+	}
+	tests := []struct {
+		name          string
+		probeErr      error
+		conditions    []migapi.Condition
+		wantReachable bool
+		wantRequeue   time.Duration
+		wantCondition string
+	}{
+		{
+			name:          "when reachable, should proceed",
+			wantReachable: true,
+		},
+		{
+			name:          "when unreachable, should pause",
+			probeErr:      errors.New("dial tcp: i/o timeout"),
+			wantReachable: false,
+			wantRequeue:   PollReQ,
+			wantCondition: SourceClusterUnreachable,
+		},
+		{
+			name:          "when unreachable for long, should back off",
+			probeErr:      errors.New("dial tcp: i/o timeout"),
+			conditions:    []migapi.Condition{unreachable},
+			wantReachable: false,
+			wantRequeue:   MaxSourceUnreachableBackOff,
+			wantCondition: SourceClusterUnreachable,
+		},
+		{
+			name:          "when reachable again, should resume",
+			conditions:    []migapi.Condition{unreachable},
+			wantReachable: true,
+			wantCondition: SourceClusterRecovered,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getSourceClusterProbe = func(*Task) (func() error, error) {
+				return func() error { return tt.probeErr }, nil
+			}
+			owner := &migapi.DirectVolumeMigration{
+				Spec: migapi.DirectVolumeMigrationSpec{SrcMigClusterRef: &corev1.ObjectReference{Name: "source"}},
+			}
+			owner.Status.Conditions.List = tt.conditions
+			owner.Status.BeginStagingConditions()
+			task := &Task{Log: log.WithName("test-logger"), Owner: owner}
+			reachable, err := task.checkSourceClusterReachable()
+			if err != nil {
+				t.Fatalf("checkSourceClusterReachable() unexpected error = %v", err)
+			}
+			if reachable != tt.wantReachable {
+				t.Errorf("checkSourceClusterReachable() = %v, want %v", reachable, tt.wantReachable)
+			}
+			if !reachable && task.Requeue != tt.wantRequeue {
+				t.Errorf("checkSourceClusterReachable() requeue = %v, want %v", task.Requeue, tt.wantRequeue)
+			}
+			if tt.wantCondition != "" && !owner.Status.HasCondition(tt.wantCondition) {
+				t.Errorf("checkSourceClusterReachable() conditions = %v, want %v", owner.Status.Conditions.List, tt.wantCondition)
+			}
+			if reachable && owner.Status.HasCondition(SourceClusterUnreachable) {
+				t.Errorf("checkSourceClusterReachable() reachable with the %v condition", SourceClusterUnreachable)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package directvolumemigration
+
+import (
+	"fmt"
+	"time"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"github.com/konveyor/mig-controller/pkg/settings"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Requeue reasons
+const (
+	RequeuePhaseCompleted                = "PhaseCompleted"
+	RequeueProgressing                   = "Progressing"
+	RequeueWaiting                       = "Waiting"
+	RequeueWaitingForPVCsBound           = "WaitingForPVCsBound"
+	RequeueWaitingForPVCsExpanded        = "WaitingForPVCsExpanded"
+	RequeueWaitingForPVCsPrewarmed       = "WaitingForPVCsPrewarmed"
+	RequeueWaitingForPVCsReleased        = "WaitingForPVCsReleased"
+	RequeueWaitingForRouteAdmitted       = "WaitingForRouteAdmitted"
+	RequeueWaitingForTransferPods        = "WaitingForTransferPods"
+	RequeueWaitingForHooks               = "WaitingForHooks"
+	RequeueWaitingForResourcesTerminated = "WaitingForResourcesTerminated"
+	RequeueTransferInProgress            = "TransferInProgress"
+	RequeueConflictRetry                 = "ConflictRetry"
+	RequeueRetryableError                = "RetryableError"
+	RequeueDestinationUnreachable        = "DestinationUnreachable"
+	RequeueSourceUnreachable             = "SourceUnreachable"
+	RequeuePhaseFailed                   = "PhaseFailed"
+	RequeueBlocked                       = "Blocked"
+)
+
+// Requeue messages
+const (
+	RequeueConflictRetryMessage = "A resource was modified concurrently, retrying."
+	RequeueBlockedMessage       = "The migration is blocked by a critical condition."
+)
+
+// Bounds of the requeue intervals of a DVM, keeping the load on the API servers
+// and the responsiveness of the migration reasonable.
+const (
+	MinFastRequeue = 10 * time.Millisecond
+	MaxFastRequeue = 10 * time.Second
+	MinPollRequeue = 500 * time.Millisecond
+	MaxPollRequeue = 5 * time.Minute
+)
+
+// Reasons of the phases polling for a resource or an operation, reported
+// while the phase waits.
+var phaseRequeueReasons = map[string]string{
+	WaitForDestinationPVCsBound:          RequeueWaitingForPVCsBound,
+	ExpandDestinationPVCs:                RequeueWaitingForPVCsExpanded,
+	PrewarmDestinationPVCs:               RequeueWaitingForPVCsPrewarmed,
+	EnsureDestinationPVCsReleased:        RequeueWaitingForPVCsReleased,
+	EnsureRsyncRouteAdmitted:             RequeueWaitingForRouteAdmitted,
+	WaitForRsyncTransferPodsRunning:      RequeueWaitingForTransferPods,
+	PrepareTransfer:                      RequeueWaitingForTransferPods,
+	RunPreTransferHooks:                  RequeueWaitingForHooks,
+	RunPostTransferHooks:                 RequeueWaitingForHooks,
+	WaitForRsyncResourcesTerminated:      RequeueWaitingForResourcesTerminated,
+	WaitForStaleRsyncResourcesTerminated: RequeueWaitingForResourcesTerminated,
+	RunRsyncOperations:                   RequeueTransferInProgress,
+	RunTransfer:                          RequeueTransferInProgress,
+	RunSpeedTest:                         RequeueTransferInProgress,
+}
+
+// Get the reason and the message of the requeue decision of the task which
+// ran the phase.
+func (t *Task) getRequeueReason(phase string) (string, string) {
+	if phase == Created {
+		return RequeuePhaseCompleted, fmt.Sprintf("The migration started, proceeding to the %s phase.", t.Phase)
+	}
+	if t.Phase != phase {
+		return RequeuePhaseCompleted, fmt.Sprintf("The %s phase completed, proceeding to the %s phase.", phase, t.Phase)
+	}
+	if t.Owner.Status.HasCondition(SourceClusterUnreachable) {
+		return RequeueSourceUnreachable, fmt.Sprintf(SourceClusterUnreachableMessage, t.Owner.Spec.SrcMigClusterRef.Name)
+	}
+	if t.Requeue == PollReQ {
+		reason, found := phaseRequeueReasons[phase]
+		if !found {
+			reason = RequeueWaiting
+		}
+		return reason, t.getPhaseDescription(phase)
+	}
+	return RequeueProgressing, t.getPhaseDescription(phase)
+}
+
+// setRequeueStatus records the requeue decision of the reconcile in the status
+// of the DVM. The time the decision was first made is kept while the same
+// decision is made in the same phase, telling a long wait from a new one.
+func setRequeueStatus(direct *migapi.DirectVolumeMigration, phase string, reason string, message string, after time.Duration) {
+	requeue := &migapi.RequeueStatus{
+		Phase:   phase,
+		Reason:  reason,
+		Message: message,
+	}
+	if after > 0 {
+		requeue.After = &metav1.Duration{Duration: after}
+	}
+	if last := direct.Status.Requeue; last != nil && last.Since != nil && last.Phase == phase && last.Reason == reason {
+		requeue.Since = last.Since
+	} else {
+		requeue.Since = &metav1.Time{Time: time.Now()}
+	}
+	direct.Status.Requeue = requeue
+}
+
+// getRequeueAfter returns the interval the DVM is requeued after, the FastReQ
+// and PollReQ defaults are overridden by the requeueIntervals of the DVM spec,
+// then by the DVM_FAST_REQUEUE and DVM_POLL_REQUEUE settings. Other intervals,
+// e.g. a back off, are kept.
+func getRequeueAfter(direct *migapi.DirectVolumeMigration, requeue time.Duration) time.Duration {
+	var override *metav1.Duration
+	switch requeue {
+	case FastReQ:
+		if direct.Spec.RequeueIntervals != nil {
+			override = direct.Spec.RequeueIntervals.Fast
+		}
+		return getRequeueInterval(override, settings.Settings.DvmOpts.FastRequeue, FastReQ, MinFastRequeue, MaxFastRequeue)
+	case PollReQ:
+		if direct.Spec.RequeueIntervals != nil {
+			override = direct.Spec.RequeueIntervals.Poll
+		}
+		return getRequeueInterval(override, settings.Settings.DvmOpts.PollRequeue, PollReQ, MinPollRequeue, MaxPollRequeue)
+	}
+	return requeue
+}
+
+// getRequeueInterval returns the override, else the setting, else the default
+// interval, within the bounds.
+func getRequeueInterval(override *metav1.Duration, setting time.Duration, def time.Duration, min time.Duration, max time.Duration) time.Duration {
+	interval := def
+	if setting > 0 {
+		interval = setting
+	}
+	if override != nil && override.Duration > 0 {
+		interval = override.Duration
+	}
+	if interval < min {
+		return min
+	}
+	if interval > max {
+		return max
+	}
+	return interval
+}
@@ -0,0 +1,129 @@
+package directvolumemigration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"github.com/konveyor/mig-controller/pkg/settings"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestTask_getRequeueReason(t *testing.T) {
+	tests := []struct {
+		name     string
+		phase    string
+		newPhase string
+		requeue  time.Duration
+		want     string
+	}{
+		{name: "when the phase advanced, should report the completion", phase: CreateDestinationPVCs, newPhase: DestinationPVCsCreated, requeue: NoReQ, want: RequeuePhaseCompleted},
+		{name: "when the migration started, should report the completion", phase: Created, newPhase: Started, requeue: FastReQ, want: RequeuePhaseCompleted},
+		{name: "when waiting on the PVCs, should report the PVCs bind", phase: WaitForDestinationPVCsBound, newPhase: WaitForDestinationPVCsBound, requeue: PollReQ, want: RequeueWaitingForPVCsBound},
+		{name: "when the transfer runs, should report the transfer", phase: RunRsyncOperations, newPhase: RunRsyncOperations, requeue: PollReQ, want: RequeueTransferInProgress},
+		{name: "when polling another phase, should report waiting", phase: EnsureRsyncSecretsExist, newPhase: EnsureRsyncSecretsExist, requeue: PollReQ, want: RequeueWaiting},
+		{name: "when the phase runs again, should report progressing", phase: RunRsyncOperations, newPhase: RunRsyncOperations, requeue: FastReQ, want: RequeueProgressing},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &Task{
+				Log:     log.WithName("test-logger"),
+				Owner:   &migapi.DirectVolumeMigration{},
+				Phase:   tt.newPhase,
+				Requeue: tt.requeue,
+			}
+			if got, message := task.getRequeueReason(tt.phase); got != tt.want || message == "" {
+				t.Errorf("Task.getRequeueReason() = %v, %q, want %v", got, message, tt.want)
+			}
+		})
+	}
+}
+
+func Test_setRequeueStatus(t *testing.T) {
+	since := metav1.NewTime(time.Now().Add(-time.Hour))
+	direct := &migapi.DirectVolumeMigration{
+		Status: migapi.DirectVolumeMigrationStatus{
+			Requeue: &migapi.RequeueStatus{
+				Phase:  WaitForDestinationPVCsBound,
+				Reason: RequeueWaitingForPVCsBound,
+				Since:  &since,
+			},
+		},
+	}
+	// the same decision keeps the time it was first made
+	setRequeueStatus(direct, WaitForDestinationPVCsBound, RequeueWaitingForPVCsBound, "waiting", PollReQ)
+	if got := direct.Status.Requeue; !got.Since.Equal(&since) || got.After == nil || got.After.Duration != PollReQ {
+		t.Errorf("setRequeueStatus() = %v, want the same decision since %v", got, since)
+	}
+	// another decision restarts it
+	setRequeueStatus(direct, WaitForDestinationPVCsBound, RequeueConflictRetry, RequeueConflictRetryMessage, NoReQ)
+	if got := direct.Status.Requeue; got.Since.Equal(&since) || got.After != nil {
+		t.Errorf("setRequeueStatus() = %v, want a new decision without interval", got)
+	}
+}
+
+func Test_getRequeueAfter(t *testing.T) {
+	defer func(opts settings.DvmOpts) { settings.Settings.DvmOpts = opts }(settings.Settings.DvmOpts)
+	duration := func(d time.Duration) *metav1.Duration { return &metav1.Duration{Duration: d} }
+	tests := []struct {
+		name      string
+		intervals *migapi.RequeueIntervals
+		setting   time.Duration
+		requeue   time.Duration
+		want      time.Duration
+	}{
+		{name: "when not set, should poll at the default", requeue: PollReQ, want: PollReQ},
+		{name: "when set by the setting, should poll at the setting", setting: 10 * time.Second, requeue: PollReQ, want: 10 * time.Second},
+		{name: "when set by the spec, should poll at the spec", intervals: &migapi.RequeueIntervals{Poll: duration(time.Second)}, setting: 10 * time.Second, requeue: PollReQ, want: time.Second},
+		{name: "when the fast interval is set, should requeue fast at the spec", intervals: &migapi.RequeueIntervals{Fast: duration(time.Second)}, requeue: FastReQ, want: time.Second},
+		{name: "when below the bounds, should poll at the minimum", intervals: &migapi.RequeueIntervals{Poll: duration(time.Millisecond)}, requeue: PollReQ, want: MinPollRequeue},
+		{name: "when above the bounds, should poll at the maximum", intervals: &migapi.RequeueIntervals{Poll: duration(time.Hour)}, requeue: PollReQ, want: MaxPollRequeue},
+		{name: "when not requeued, should not requeue", intervals: &migapi.RequeueIntervals{Poll: duration(time.Second)}, requeue: NoReQ, want: NoReQ},
+		{name: "when backing off, should keep the back off", intervals: &migapi.RequeueIntervals{Poll: duration(time.Second)}, requeue: time.Minute, want: time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings.Settings.DvmOpts.PollRequeue = tt.setting
+			direct := &migapi.DirectVolumeMigration{
+				Spec: migapi.DirectVolumeMigrationSpec{RequeueIntervals: tt.intervals},
+			}
+			if got := getRequeueAfter(direct, tt.requeue); got != tt.want {
+				t.Errorf("getRequeueAfter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconcileDirectVolumeMigration_Reconcile_maintenance(t *testing.T) {
+	direct := &migapi.DirectVolumeMigration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "dvm",
+			Namespace:   migapi.OpenshiftMigrationNamespace,
+			Annotations: map[string]string{migapi.MaintenanceAnnotation: ""},
+		},
+		Status: migapi.DirectVolumeMigrationStatus{Phase: RunRsyncOperations},
+	}
+	r := &ReconcileDirectVolumeMigration{Client: fake.NewFakeClient(direct)}
+	result, err := r.Reconcile(context.TODO(), reconcile.Request{
+		NamespacedName: types.NamespacedName{Namespace: direct.Namespace, Name: direct.Name},
+	})
+	if err != nil {
+		t.Fatalf("Reconcile() unexpected error = %v", err)
+	}
+	if result.RequeueAfter != PollReQ {
+		t.Errorf("Reconcile() requeueAfter = %v, want %v", result.RequeueAfter, PollReQ)
+	}
+	got := &migapi.DirectVolumeMigration{}
+	err = r.Get(context.TODO(), types.NamespacedName{Namespace: direct.Namespace, Name: direct.Name}, got)
+	if err != nil {
+		t.Fatalf("Get() unexpected error = %v", err)
+	}
+	if got.ResourceVersion != direct.ResourceVersion || got.Status.Phase != RunRsyncOperations ||
+		got.Status.Requeue != nil || len(got.Status.Conditions.List) > 0 {
+		t.Errorf("Reconcile() updated the DVM in maintenance, status = %v", got.Status)
+	}
+}
@@ -9,15 +9,18 @@ import (
 	"encoding/hex"
 	"fmt"
 	random "math/rand"
+	"net"
 	"path"
 	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"text/template"
 	"time"
 
+	"github.com/google/uuid"
 	liberr "github.com/konveyor/controller/pkg/error"
 	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
 	"github.com/konveyor/mig-controller/pkg/compat"
@@ -41,10 +44,12 @@ type pvc struct {
 }
 
 type rsyncConfig struct {
-	SshUser   string
-	Namespace string
-	Password  string
-	PVCList   []pvc
+	SshUser          string
+	Namespace        string
+	Password         string
+	PVCList          []pvc
+	TempDir          string
+	LargeFileStreams bool
 }
 
 const (
@@ -71,6 +76,10 @@ const (
 	DefaultRsyncOperationConcurrency = 5
 	// PendingPodWarningTimeLimit time threshold for Rsync Pods in Pending state to show warning
 	PendingPodWarningTimeLimit = 10 * time.Minute
+	// MaxRsyncShards defines the maximum number of concurrent Rsync processes a PVC can be split among
+	MaxRsyncShards = 16
+	// PodDeadlineExceededReason reason of Pods terminated by Kubernetes once their active deadline is exceeded
+	PodDeadlineExceededReason = "DeadlineExceeded"
 )
 
 // labels
@@ -109,6 +118,12 @@ data:
         auth users = {{ $.SshUser }}
         secrets file = /etc/rsyncd.secrets
         read only = false
+        {{- if $.TempDir }}
+        pre-xfer exec = mkdir -p /mnt/{{ $.Namespace }}/{{ $pvc.Name }}/{{ $.TempDir }}
+        {{- end }}
+        {{- if $.LargeFileStreams }}
+        post-xfer exec = /bin/bash /etc/rsync-reassemble.sh
+        {{- end }}
    {{ end }}
 `
 
@@ -243,6 +258,10 @@ func (t *Task) createRsyncConfig() error {
 			pvcHash := getMD5Hash(vol.Name)
 			pvcList = append(pvcList, pvc{Name: pvcHash})
 		}
+		// a speed test transfers generated data to a single module
+		if t.isSpeedTest() {
+			pvcList = []pvc{{Name: SpeedTestModule}}
+		}
 		// Generate template
 		rsyncConf := rsyncConfig{
 			SshUser:   "root",
@@ -250,6 +269,11 @@ func (t *Task) createRsyncConfig() error {
 			PVCList:   pvcList,
 			Password:  password,
 		}
+		// a verify-only migration doesn't modify the destination volumes
+		if !t.isVerifyOnly() && !t.isSpeedTest() {
+			rsyncConf.TempDir = t.Owner.Spec.RsyncTempDir
+		}
+		rsyncConf.LargeFileStreams = t.hasLargeFileStreams()
 		var tpl bytes.Buffer
 		temp, err := template.New("config").Parse(rsyncConfigTemplate)
 		if err != nil {
@@ -268,11 +292,15 @@ func (t *Task) createRsyncConfig() error {
 		}
 		configMap.Labels = t.Owner.GetCorrelationLabels()
 		configMap.Labels["app"] = DirectVolumeMigrationRsyncTransfer
+		configMap.Labels[RsyncTransferGenerationLabel] = string(t.Owner.UID)
 
 		err = yaml.Unmarshal(tpl.Bytes(), &configMap)
 		if err != nil {
 			return err
 		}
+		if rsyncConf.LargeFileStreams {
+			configMap.Data[RsyncReassembleScriptKey] = rsyncReassembleScript
+		}
 
 		// Create configmap on source + dest
 		// Note: when this configmap changes the rsync pod
@@ -311,6 +339,7 @@ func (t *Task) createRsyncConfig() error {
 		}
 		srcSecret.Labels = t.Owner.GetCorrelationLabels()
 		srcSecret.Labels["app"] = DirectVolumeMigrationRsyncTransfer
+		srcSecret.Labels[RsyncTransferGenerationLabel] = string(t.Owner.UID)
 
 		t.Log.Info("Creating Rsync Password Secret on source cluster",
 			"secret", path.Join(srcSecret.Namespace, srcSecret.Name))
@@ -331,6 +360,7 @@ func (t *Task) createRsyncConfig() error {
 		}
 		destSecret.Labels = t.Owner.GetCorrelationLabels()
 		destSecret.Labels["app"] = DirectVolumeMigrationRsyncTransfer
+		destSecret.Labels[RsyncTransferGenerationLabel] = string(t.Owner.UID)
 
 		t.Log.Info("Creating Rsync Password Secret on destination cluster",
 			"secret", path.Join(destSecret.Namespace, destSecret.Name))
@@ -384,6 +414,7 @@ func (t *Task) createRsyncTransferRoute() error {
 		}
 		svc.Labels = t.Owner.GetCorrelationLabels()
 		svc.Labels["app"] = DirectVolumeMigrationRsyncTransfer
+		svc.Labels[RsyncTransferGenerationLabel] = string(t.Owner.UID)
 
 		t.Log.Info("Creating Rsync Transfer Service for Stunnel connection "+
 			"on destination MigCluster ",
@@ -395,6 +426,15 @@ func (t *Task) createRsyncTransferRoute() error {
 		} else if err != nil {
 			return err
 		}
+		// The Service is reached directly by the source cluster on a flat network,
+		// or through the tunnel provided by the user
+		endpointType, err := t.getEndpointType(ns)
+		if err != nil {
+			return err
+		}
+		if endpointType != EndpointTypeRoute {
+			continue
+		}
 		route := routev1.Route{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      DirectVolumeMigrationRsyncTransferRoute,
@@ -415,6 +455,7 @@ func (t *Task) createRsyncTransferRoute() error {
 		}
 		route.Labels = t.Owner.GetCorrelationLabels()
 		route.Labels["app"] = DirectVolumeMigrationRsyncTransfer
+		route.Labels[RsyncTransferGenerationLabel] = string(t.Owner.UID)
 
 		// Get cluster subdomain if it exists
 		cluster, err := t.Owner.GetDestinationCluster(t.Client)
@@ -456,6 +497,91 @@ func (t *Task) createRsyncTransferRoute() error {
 	return nil
 }
 
+// Delete the rsync transfer endpoint (Service, Route and transfer Pod) on the
+// destination cluster so that it can be recreated from scratch. Data already
+// copied stays on the destination PVCs, partially transferred files are picked
+// up by the next rsync attempt when --partial is set.
+// Returns true once all endpoint resources are gone.
+func (t *Task) deleteRsyncTransferEndpoint() (bool, error) {
+	destClient, err := t.getDestinationClient()
+	if err != nil {
+		return false, err
+	}
+	deleted := true
+	for bothNs, _ := range t.getPVCNamespaceMap() {
+		ns := getDestNs(bothNs)
+		endpointResources := []k8sclient.Object{
+			&corev1.Pod{},
+			&corev1.Service{},
+			&routev1.Route{},
+		}
+		names := []string{
+			DirectVolumeMigrationRsyncTransfer,
+			DirectVolumeMigrationRsyncTransferSvc,
+			DirectVolumeMigrationRsyncTransferRoute,
+		}
+		for i, obj := range endpointResources {
+			err := destClient.Get(context.TODO(),
+				types.NamespacedName{Namespace: ns, Name: names[i]}, obj)
+			if k8serror.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return false, err
+			}
+			deleted = false
+			if obj.GetDeletionTimestamp() != nil {
+				continue
+			}
+			t.Log.Info("Deleting Rsync transfer endpoint resource on destination cluster",
+				"resource", path.Join(ns, names[i]))
+			err = destClient.Delete(context.TODO(), obj,
+				k8sclient.PropagationPolicy(metav1.DeletePropagationBackground))
+			if err != nil && !k8serror.IsNotFound(err) {
+				return false, err
+			}
+		}
+	}
+	return deleted, nil
+}
+
+// Delete the Rsync client Pods on the source cluster, the rsync transfer endpoint
+// on the destination cluster is kept.
+// Returns true once all Rsync client Pods are gone.
+func (t *Task) deleteRsyncClientPods(srcClient compat.Client) (bool, error) {
+	selector := labels.SelectorFromSet(map[string]string{
+		"app":                   DirectVolumeMigrationRsyncTransfer,
+		"directvolumemigration": DirectVolumeMigrationRsyncClient,
+	})
+	deleted := true
+	for bothNs, _ := range t.getPVCNamespaceMap() {
+		ns := getSourceNs(bothNs)
+		podList := corev1.PodList{}
+		err := srcClient.List(context.TODO(), &podList, &k8sclient.ListOptions{
+			Namespace:     ns,
+			LabelSelector: selector,
+		})
+		if err != nil {
+			return false, err
+		}
+		for i := range podList.Items {
+			pod := &podList.Items[i]
+			deleted = false
+			if pod.DeletionTimestamp != nil {
+				continue
+			}
+			t.Log.Info("Deleting Rsync client Pod on source cluster",
+				"pod", path.Join(pod.Namespace, pod.Name))
+			err = srcClient.Delete(context.TODO(), pod,
+				k8sclient.PropagationPolicy(metav1.DeletePropagationBackground))
+			if err != nil && !k8serror.IsNotFound(err) {
+				return false, err
+			}
+		}
+	}
+	return deleted, nil
+}
+
 // Transfer pod which runs rsyncd
 func (t *Task) createRsyncTransferPods() error {
 	// Ensure SSH Keys exist
@@ -578,21 +704,43 @@ func (t *Task) createRsyncTransferPods() error {
 		trueBool := true
 		runAsUser := int64(0)
 
-		// Add PVC volume mounts
-		for _, vol := range vols {
-			pvcHash := getMD5Hash(vol.Name)
+		// Add PVC volume mounts, block mode PVCs are attached as raw block devices
+		volumeDevices := []corev1.VolumeDevice{}
+		if t.isSpeedTest() {
+			// the generated data of a speed test is received in an emptyDir,
+			// the PVCs are not mounted
 			volumeMounts = append(volumeMounts, corev1.VolumeMount{
-				Name:      pvcHash,
-				MountPath: fmt.Sprintf("/mnt/%s/%s", ns, pvcHash),
+				Name:      SpeedTestModule,
+				MountPath: fmt.Sprintf("/mnt/%s/%s", ns, SpeedTestModule),
 			})
-			volumes = append(volumes, corev1.Volume{
-				Name: pvcHash,
-				VolumeSource: corev1.VolumeSource{
-					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
-						ClaimName: vol.Name,
+			volumes = append(volumes, getSpeedTestVolume(t.getSpeedTestSize()))
+		} else {
+			blockPVCs, err := getBlockPVCs(destClient, ns)
+			if err != nil {
+				return err
+			}
+			for _, vol := range vols {
+				pvcHash := getMD5Hash(vol.Name)
+				if blockPVCs[vol.Name] {
+					volumeDevices = append(volumeDevices, corev1.VolumeDevice{
+						Name:       pvcHash,
+						DevicePath: getBlockDevicePath(ns, pvcHash),
+					})
+				} else {
+					volumeMounts = append(volumeMounts, corev1.VolumeMount{
+						Name:      pvcHash,
+						MountPath: fmt.Sprintf("/mnt/%s/%s", ns, pvcHash),
+					})
+				}
+				volumes = append(volumes, corev1.Volume{
+					Name: pvcHash,
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+							ClaimName: vol.Name,
+						},
 					},
-				},
-			})
+				})
+			}
 		}
 		// Add rsyncd config mount
 		volumeMounts = append(volumeMounts, corev1.VolumeMount{
@@ -606,6 +754,14 @@ func (t *Task) createRsyncTransferPods() error {
 			MountPath: "/etc/rsyncd.secrets",
 			SubPath:   "rsyncd.secrets",
 		})
+		// Add the script reassembling the large files split among Rsync streams
+		if t.hasLargeFileStreams() {
+			volumeMounts = append(volumeMounts, corev1.VolumeMount{
+				Name:      "rsyncd-conf",
+				MountPath: RsyncReassembleScriptPath,
+				SubPath:   RsyncReassembleScriptKey,
+			})
+		}
 
 		dvmLabels := t.buildDVMLabels()
 		dvmLabels["purpose"] = DirectVolumeMigrationRsync
@@ -617,7 +773,8 @@ func (t *Task) createRsyncTransferPods() error {
 				Labels:    dvmLabels,
 			},
 			Spec: corev1.PodSpec{
-				Volumes: volumes,
+				Volumes:         volumes,
+				SecurityContext: t.getRsyncTransferPodSecurityContext(),
 				Containers: []corev1.Container{
 					{
 						Name:  "rsyncd",
@@ -628,7 +785,7 @@ func (t *Task) createRsyncTransferPods() error {
 								Value: string(pubKeyBytes),
 							},
 						},
-						Command: []string{"/usr/bin/rsync", "--daemon", "--no-detach", "--port=22", "-vvv"},
+						Command: getRsyncDaemonCommand(t.getTransferPodOpenFilesLimit(getSourceNs(bothNs), vols)),
 						Ports: []corev1.ContainerPort{
 							{
 								Name:          "rsyncd",
@@ -636,7 +793,8 @@ func (t *Task) createRsyncTransferPods() error {
 								ContainerPort: int32(22),
 							},
 						},
-						VolumeMounts: volumeMounts,
+						VolumeMounts:  volumeMounts,
+						VolumeDevices: volumeDevices,
 						SecurityContext: &corev1.SecurityContext{
 							Privileged:             &isRsyncPrivileged,
 							RunAsUser:              &runAsUser,
@@ -734,8 +892,26 @@ func (t *Task) getPodResourceLists(cpuLimit string, memoryLimit string, cpuReque
 }
 
 type pvcMapElement struct {
-	Name   string
-	Verify bool
+	Name             string
+	Verify           bool
+	MaxSize          string
+	MinSize          string
+	Shards           int
+	Sparse           string
+	LargeFileStreams int
+}
+
+// Get the element of a PVC to migrate in the PVC namespace map.
+func newPVCMapElement(pvc migapi.PVCToMigrate) pvcMapElement {
+	return pvcMapElement{
+		Name:             pvc.Name,
+		Verify:           pvc.Verify,
+		MaxSize:          pvc.MaxSize,
+		MinSize:          pvc.MinSize,
+		Shards:           pvc.Shards,
+		Sparse:           pvc.Sparse,
+		LargeFileStreams: pvc.LargeFileStreams,
+	}
 }
 
 // With namespace mapping, the destination cluster namespace may be different than that in the source cluster.
@@ -750,10 +926,10 @@ func (t *Task) getPVCNamespaceMap() map[string][]pvcMapElement {
 		}
 		bothNs := srcNs + ":" + destNs
 		if vols, exists := nsMap[bothNs]; exists {
-			vols = append(vols, pvcMapElement{Name: pvc.Name, Verify: pvc.Verify})
+			vols = append(vols, newPVCMapElement(pvc))
 			nsMap[bothNs] = vols
 		} else {
-			nsMap[bothNs] = []pvcMapElement{{Name: pvc.Name, Verify: pvc.Verify}}
+			nsMap[bothNs] = []pvcMapElement{newPVCMapElement(pvc)}
 		}
 	}
 	return nsMap
@@ -774,6 +950,16 @@ func getDestNs(bothNs string) string {
 }
 
 func (t *Task) getRsyncRoute(namespace string) (string, error) {
+	endpointType, err := t.getEndpointType(namespace)
+	if err != nil {
+		return "", err
+	}
+	switch endpointType {
+	case EndpointTypeClusterIP:
+		return t.getRsyncTransferServiceIP(namespace)
+	case EndpointTypeTunnel:
+		return t.getTunnelEndpoint(namespace).Host, nil
+	}
 	// Get client for destination
 	destClient, err := t.getDestinationClient()
 	if err != nil {
@@ -789,22 +975,47 @@ func (t *Task) getRsyncRoute(namespace string) (string, error) {
 	return route.Spec.Host, nil
 }
 
-func (t *Task) areRsyncRoutesAdmitted() (bool, []string, error) {
+func (t *Task) areRsyncRoutesAdmitted() (bool, []string, []string, error) {
 	messages := []string{}
+	rejected := []string{}
 	// Get client for destination
 	destClient, err := t.getDestinationClient()
 	if err != nil {
-		return false, messages, err
+		return false, messages, rejected, err
 	}
+	unreachable := []string{}
 	nsMap := t.getPVCNamespaceMap()
 	for bothNs, _ := range nsMap {
 		namespace := getDestNs(bothNs)
+		// No Route is created for a ClusterIP endpoint
+		endpointType, err := t.getEndpointType(namespace)
+		if err != nil {
+			return false, messages, rejected, err
+		}
+		if endpointType == EndpointTypeClusterIP {
+			continue
+		}
+		// The tunnel provided by the user is reached from the source cluster,
+		// the connection from mig-controller is advisory only
+		if endpointType == EndpointTypeTunnel {
+			tunnel := t.getTunnelEndpoint(namespace)
+			address := net.JoinHostPort(tunnel.Host, strconv.Itoa(int(tunnel.Port)))
+			err = dialTunnelEndpoint(address)
+			if err != nil {
+				t.Log.Info("Rsync transfer tunnel endpoint is unreachable from mig-controller.",
+					"namespace", namespace,
+					"address", address,
+					"error", err.Error())
+				unreachable = append(unreachable, fmt.Sprintf("%s: %s: %s", namespace, address, err.Error()))
+			}
+			continue
+		}
 		route := routev1.Route{}
 
 		key := types.NamespacedName{Name: DirectVolumeMigrationRsyncTransferRoute, Namespace: namespace}
 		err = destClient.Get(context.TODO(), key, &route)
 		if err != nil {
-			return false, messages, err
+			return false, messages, rejected, err
 		}
 		// Logs abnormal events related to route if any are found
 		migevent.LogAbnormalEventsForResource(
@@ -836,11 +1047,70 @@ func (t *Task) areRsyncRoutesAdmitted() (bool, []string, error) {
 		if !admitted {
 			messages = append(messages, message)
 		}
+		if rejection, found := getRouteRejection(&route); found {
+			t.Log.Info("Rsync Transfer Route has been rejected.",
+				"route", path.Join(route.Namespace, route.Name),
+				"host", route.Spec.Host,
+				"rejection", rejection)
+			rejected = append(rejected, rejection)
+		}
 	}
+	t.setTunnelEndpointsUnreachable(unreachable)
 	if len(messages) > 0 {
-		return false, messages, nil
+		return false, messages, rejected, nil
+	}
+	return true, []string{}, rejected, nil
+}
+
+// Warn about the tunnel endpoints mig-controller cannot connect to. The Rsync
+// client Pods connect to the tunnels from the source cluster, which may reach
+// tunnels mig-controller cannot, the migration is not blocked.
+func (t *Task) setTunnelEndpointsUnreachable(unreachable []string) {
+	if len(unreachable) == 0 {
+		t.Owner.Status.DeleteCondition(TunnelEndpointsUnreachable)
+		return
+	}
+	t.Owner.Status.SetCondition(migapi.Condition{
+		Type:     TunnelEndpointsUnreachable,
+		Status:   True,
+		Reason:   NotReady,
+		Category: Warn,
+		Message:  TunnelEndpointsUnreachableMessage,
+		Items:    unreachable,
+		Durable:  true,
+	})
+}
+
+// Get the rejection of a Route no router admitted, the host of the Route and
+// the reason of the routers which rejected it, e.g. HostAlreadyClaimed. A Route
+// which routers haven't reported on yet isn't rejected.
+func getRouteRejection(route *routev1.Route) (string, bool) {
+	reasons := []string{}
+	for _, ingress := range route.Status.Ingress {
+		for _, condition := range ingress.Conditions {
+			if condition.Type != routev1.RouteAdmitted {
+				continue
+			}
+			if condition.Status == corev1.ConditionTrue {
+				return "", false
+			}
+			if condition.Status == corev1.ConditionFalse {
+				reason := condition.Reason
+				if reason == "" {
+					reason = "NotAdmitted"
+				}
+				reasons = append(reasons, fmt.Sprintf("%s by router %s", reason, ingress.RouterName))
+			}
+		}
+	}
+	if len(reasons) == 0 {
+		return "", false
+	}
+	host := route.Spec.Host
+	if host == "" && len(route.Status.Ingress) > 0 {
+		host = route.Status.Ingress[0].Host
 	}
-	return true, []string{}, nil
+	return fmt.Sprintf("%s host %s: %s", path.Join(route.Namespace, route.Name), host, strings.Join(reasons, " and ")), true
 }
 
 func (t *Task) createRsyncPassword() (string, error) {
@@ -864,6 +1134,7 @@ func (t *Task) createRsyncPassword() (string, error) {
 	// Correlation labels for discovery service tree view
 	secret.Labels = t.Owner.GetCorrelationLabels()
 	secret.Labels["app"] = DirectVolumeMigrationRsyncTransfer
+	secret.Labels[RsyncTransferGenerationLabel] = string(t.Owner.UID)
 
 	t.Log.Info("Creating Rsync Password Secret on host cluster",
 		"secret", path.Join(secret.Namespace, secret.Name))
@@ -916,7 +1187,69 @@ func (t *Task) deleteRsyncPassword() error {
 	return nil
 }
 
-//Returns a map of PVCNamespacedName to the pod.NodeName
+// Get the Secrets mounted by the Rsync client and transfer Pods that are
+// missing on the host, source or destination cluster.
+// Returns the list of missing Secrets as "<cluster> cluster: <namespace>/<name>".
+func (t *Task) getMissingRsyncSecrets() ([]string, error) {
+	missing := []string{}
+	srcClient, err := t.getSourceClient()
+	if err != nil {
+		return nil, err
+	}
+	destClient, err := t.getDestinationClient()
+	if err != nil {
+		return nil, err
+	}
+	secretExists := func(client k8sclient.Client, namespace string, name string) (bool, error) {
+		err := client.Get(context.TODO(),
+			types.NamespacedName{Namespace: namespace, Name: name}, &corev1.Secret{})
+		if k8serror.IsNotFound(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	// Rsync password used by the Rsync client Pods
+	found, err := secretExists(t.Client, migapi.OpenshiftMigrationNamespace, DirectVolumeMigrationRsyncPass)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		missing = append(missing, fmt.Sprintf("host cluster: %s",
+			path.Join(migapi.OpenshiftMigrationNamespace, DirectVolumeMigrationRsyncPass)))
+	}
+
+	for bothNs, _ := range t.getPVCNamespaceMap() {
+		srcNs := getSourceNs(bothNs)
+		destNs := getDestNs(bothNs)
+		// Stunnel certs mounted by the Rsync client Pods
+		found, err := secretExists(srcClient, srcNs, DirectVolumeMigrationStunnelCerts)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			missing = append(missing, fmt.Sprintf("source cluster: %s",
+				path.Join(srcNs, DirectVolumeMigrationStunnelCerts)))
+		}
+		// Stunnel certs and Rsync credentials mounted by the Rsync transfer Pod
+		for _, name := range []string{DirectVolumeMigrationStunnelCerts, DirectVolumeMigrationRsyncCreds} {
+			found, err := secretExists(destClient, destNs, name)
+			if err != nil {
+				return nil, err
+			}
+			if !found {
+				missing = append(missing, fmt.Sprintf("destination cluster: %s", path.Join(destNs, name)))
+			}
+		}
+	}
+	sort.Strings(missing)
+	return missing, nil
+}
+
+// Returns a map of PVCNamespacedName to the pod.NodeName
 func (t *Task) getPVCNodeNameMap() (map[string]string, error) {
 	nodeNameMap := map[string]string{}
 	pvcMap := t.getPVCNamespaceMap()
@@ -963,8 +1296,80 @@ func (t *Task) filterRsyncExtraOptions(options []string) (validatedOptions []str
 	return
 }
 
+// Sizes accepted by rsync --max-size and --min-size, a number with an optional
+// fraction, unit suffix and +1/-1 offset. e.g. 100, 1.5K, 2GiB, 1mb+1
+var rsyncSizeRegex = regexp.MustCompile(`^\d+(\.\d+)?([KMGTPkmgtp]([Ii]?[Bb])?|[Bb])?([+-]1)?$`)
+
+// isValidRsyncSize checks whether the given size can be passed to rsync --max-size and --min-size
+func isValidRsyncSize(size string) bool {
+	return rsyncSizeRegex.MatchString(size)
+}
+
+// Get the mode applied to the sparse files of a PVC and the Rsync options it
+// resolves to. In auto mode the Rsync defaults are kept, no sparse files being
+// detected on the source PVCs.
+func getRsyncSparseOptions(sparse string) (string, []string) {
+	switch sparse {
+	case migapi.SparseAlways:
+		return sparse, []string{"--sparse"}
+	case migapi.SparseNever:
+		return sparse, []string{"--no-sparse"}
+	}
+	return migapi.SparseAuto, []string{}
+}
+
+// Get the Rsync options writing the temporary files to the temporary directory
+// of the destination volumes. The directory is excluded for it to be neither
+// transferred from the source nor deleted on the destination, the exclude
+// coming before the filter rules of the DVM.
+func getRsyncTempDirOptions(tempDir string) []string {
+	if tempDir == "" {
+		return []string{}
+	}
+	return []string{
+		fmt.Sprintf("--temp-dir=%s", tempDir),
+		fmt.Sprintf("--exclude=/%s/", tempDir),
+	}
+}
+
+// RsyncPartialDir directory Rsync keeps the partially transferred files in,
+// relative to the directory of each file on the destination volume.
+const RsyncPartialDir = ".dvm-partial"
+
+// Get the Rsync options keeping the partially transferred files of the
+// interrupted attempts on the destination volumes. The partial directory is
+// relative, Rsync creates it next to the partial files and excludes it from
+// the transfer and from the deletions. The next attempt resumes the partial
+// files, including after the Rsync Pods or the controller restarted.
+func (t *Task) getRsyncPartialDirOptions() []string {
+	if !t.Owner.Spec.PreservePartialFiles {
+		return []string{}
+	}
+	return []string{fmt.Sprintf("--partial-dir=%s", RsyncPartialDir)}
+}
+
+// Characters accepted in the temporary directory, it is passed unquoted to
+// the shell running the Rsync command.
+var rsyncTempDirRegex = regexp.MustCompile(`^[\w.-]+(/[\w.-]+)*$`)
+
+// Get whether the temporary directory is a relative path within the volume.
+func isValidRsyncTempDir(tempDir string) bool {
+	clean := path.Clean(tempDir)
+	return clean == tempDir &&
+		clean != "." &&
+		clean != ".." &&
+		!strings.HasPrefix(clean, "../") &&
+		rsyncTempDirRegex.MatchString(clean)
+}
+
 // generates Rsync options based on custom options provided by the user in MigrationController CR
 func (t *Task) getRsyncOptions() []string {
+	return t.getEndpointRsyncOptions("")
+}
+
+// generates the Rsync options of a transfer through an endpoint type, the --timeout of the
+// endpoint type set in the cluster ConfigMap used in place of the cluster timeout
+func (t *Task) getEndpointRsyncOptions(endpointType string) []string {
 	var rsyncOpts []string
 	defaultInfoOpts := "COPY2,DEL2,REMOVE2,SKIP2,FLIST2,PROGRESS2,STATS2"
 	defaultExtraOpts := []string{
@@ -973,9 +1378,9 @@ func (t *Task) getRsyncOptions() []string {
 		"--log-file", "/dev/stdout",
 	}
 	rsyncOptions := settings.Settings.DvmOpts.RsyncOpts
-	if rsyncOptions.BwLimit != -1 {
+	if bwLimit := t.getRsyncBwLimit(); bwLimit != -1 {
 		rsyncOpts = append(rsyncOpts,
-			fmt.Sprintf("--bwlimit=%d", rsyncOptions.BwLimit))
+			fmt.Sprintf("--bwlimit=%d", bwLimit))
 	}
 	if rsyncOptions.Archive {
 		rsyncOpts = append(rsyncOpts, "--archive")
@@ -991,6 +1396,44 @@ func (t *Task) getRsyncOptions() []string {
 	if rsyncOptions.Partial {
 		rsyncOpts = append(rsyncOpts, "--partial")
 	}
+	if rsyncOptions.Acls {
+		rsyncOpts = append(rsyncOpts, "--acls")
+	}
+	if rsyncOptions.Xattrs {
+		rsyncOpts = append(rsyncOpts, "--xattrs")
+		// SELinux labels of the source do not apply to the destination
+		// unless explicitly requested
+		if !rsyncOptions.XattrsSELinux {
+			rsyncOpts = append(rsyncOpts, RsyncXattrsSELinuxFilter)
+		}
+	}
+	if t.getRsyncCompress() {
+		rsyncOpts = append(rsyncOpts, "--compress")
+		if compressLevel := t.getRsyncCompressLevelOption(); compressLevel != "" {
+			rsyncOpts = append(rsyncOpts, compressLevel)
+		}
+	}
+	if wholeFile := t.getRsyncWholeFileOption(); wholeFile != "" {
+		rsyncOpts = append(rsyncOpts, wholeFile)
+	}
+	if timeout := t.getEndpointRsyncTimeout(endpointType); timeout > 0 {
+		rsyncOpts = append(rsyncOpts, fmt.Sprintf("--timeout=%d", timeout))
+	}
+	if chown := t.getRsyncChownOption(); chown != "" {
+		rsyncOpts = append(rsyncOpts, chown)
+	}
+	if t.Owner.Spec.DestinationFSGroup != nil {
+		rsyncOpts = append(rsyncOpts, RsyncFSGroupChmod)
+	}
+	if unsafeLinks := t.getRsyncUnsafeLinksOption(); unsafeLinks != "" {
+		rsyncOpts = append(rsyncOpts, unsafeLinks)
+	}
+	if modifyWindow := t.getRsyncModifyWindowOption(); modifyWindow != "" {
+		rsyncOpts = append(rsyncOpts, modifyWindow)
+	}
+	if pruneEmptyDirs := t.getRsyncPruneEmptyDirsOption(); pruneEmptyDirs != "" {
+		rsyncOpts = append(rsyncOpts, pruneEmptyDirs)
+	}
 	if valid, _ := regexp.Match(`^\w[\w,]*?\w$`, []byte(rsyncOptions.Info)); valid {
 		rsyncOpts = append(rsyncOpts,
 			fmt.Sprintf("--info=%s", rsyncOptions.Info))
@@ -1000,10 +1443,50 @@ func (t *Task) getRsyncOptions() []string {
 	}
 	rsyncOpts = append(rsyncOpts, defaultExtraOpts...)
 	rsyncOpts = append(rsyncOpts,
-		t.filterRsyncExtraOptions(rsyncOptions.Extras)...)
+		t.filterRsyncPruneEmptyDirsOptions(t.filterRsyncExtraOptions(rsyncOptions.Extras))...)
 	return rsyncOpts
 }
 
+// Get the --chown option setting the owner of the files written on the destination.
+// The Rsync daemon on the destination runs as root for the ownership to be applied.
+func (t *Task) getRsyncChownOption() string {
+	uid, gid := t.Owner.Spec.RsyncUID, t.Owner.Spec.RsyncGID
+	if gid == nil {
+		gid = t.Owner.Spec.DestinationFSGroup
+	}
+	if gid == nil {
+		gid = uid
+	}
+	switch {
+	case uid != nil:
+		return fmt.Sprintf("--chown=%d:%d", *uid, *gid)
+	case gid != nil:
+		return fmt.Sprintf("--chown=:%d", *gid)
+	}
+	return ""
+}
+
+// RsyncFSGroupChmod permissions applied along with the destination fsGroup,
+// the ones Kubernetes applies to a volume mounted with an fsGroup: group
+// readable and writable files, setgid directories inheriting the group.
+const RsyncFSGroupChmod = "--chmod=Dug+rwx,Dg+s,Fug+rw"
+
+// RsyncXattrsSELinuxFilter excludes the SELinux labels from the extended
+// attributes preserved, the destination labeling the volume on its own.
+const RsyncXattrsSELinuxFilter = "--filter=-x security.selinux"
+
+// Get the security context of the Rsync transfer Pod. With a destination
+// fsGroup, the kubelet applies the group to the destination PVCs recursively
+// when mounting them, including the data left by a previous migration.
+func (t *Task) getRsyncTransferPodSecurityContext() *corev1.PodSecurityContext {
+	if t.Owner.Spec.DestinationFSGroup == nil {
+		return nil
+	}
+	return &corev1.PodSecurityContext{
+		FSGroup: t.Owner.Spec.DestinationFSGroup,
+	}
+}
+
 type PVCWithSecurityContext struct {
 	name               string
 	pvcHash            string
@@ -1011,6 +1494,13 @@ type PVCWithSecurityContext struct {
 	supplementalGroups []int64
 	seLinuxOptions     *corev1.SELinuxOptions
 	verify             bool
+	maxSize            string
+	minSize            string
+	shards             int
+	largeFileStreams   int
+	sparse             string
+	block              bool
+	targetNamespace    string
 
 	// TODO:
 	// add capabilities for dvm controller to handle case the source
@@ -1044,6 +1534,10 @@ func (t *Task) getfsGroupMapForNamespace() (map[string][]PVCWithSecurityContext,
 		if err != nil {
 			return nil, err
 		}
+		blockPVCs, err := getBlockPVCs(srcClient, ns)
+		if err != nil {
+			return nil, err
+		}
 
 		// for each namespace, have a pvc->SCC map to look up in the pvc loop later
 		// we will use the scc of the last pod in the list mounting the pvc
@@ -1067,6 +1561,13 @@ func (t *Task) getfsGroupMapForNamespace() (map[string][]PVCWithSecurityContext,
 			pss, exists := pvcSecurityContextMapForNamespace[claim.Name]
 			if exists {
 				pss.verify = claim.Verify
+				pss.maxSize = claim.MaxSize
+				pss.minSize = claim.MinSize
+				pss.shards = claim.Shards
+				pss.largeFileStreams = claim.LargeFileStreams
+				pss.sparse = claim.Sparse
+				pss.block = blockPVCs[claim.Name]
+				pss.targetNamespace = getDestNs(bothNs)
 				pvcSecurityContextMap[ns] = append(pvcSecurityContextMap[ns], pss)
 				continue
 			}
@@ -1079,6 +1580,13 @@ func (t *Task) getfsGroupMapForNamespace() (map[string][]PVCWithSecurityContext,
 				supplementalGroups: nil,
 				seLinuxOptions:     nil,
 				verify:             claim.Verify,
+				maxSize:            claim.MaxSize,
+				minSize:            claim.MinSize,
+				shards:             claim.Shards,
+				largeFileStreams:   claim.LargeFileStreams,
+				sparse:             claim.Sparse,
+				block:              blockPVCs[claim.Name],
+				targetNamespace:    getDestNs(bothNs),
 			})
 		}
 	}
@@ -1176,7 +1684,9 @@ func (t *Task) createPVProgressCR() error {
 			if err != nil {
 				return liberr.Wrap(err)
 			}
-			migapi.SetOwnerReference(t.Owner, t.Owner, &dvmp)
+			if settings.Settings.DvmOpts.OwnerReferences {
+				migapi.SetOwnerReference(t.Owner, t.Owner, &dvmp)
+			}
 			t.Log.Info("Creating DVMP on host MigCluster to track Rsync Pod completion on MigCluster",
 				"dvmp", path.Join(dvmp.Namespace, dvmp.Name),
 				"srcNamespace", dvmp.Spec.PodNamespace,
@@ -1236,16 +1746,20 @@ func (t *Task) hasAllProgressReportingCompleted() (bool, error) {
 					Namespace: ns,
 					Name:      vol.Name,
 				},
-				LastObservedProgressPercent: dvmp.Status.TotalProgressPercentage,
-				LastObservedTransferRate:    dvmp.Status.LastObservedTransferRate,
-				TotalElapsedTime:            dvmp.Status.RsyncElapsedTime,
+				LastObservedProgressPercent:  dvmp.Status.TotalProgressPercentage,
+				LastObservedTransferRate:     dvmp.Status.LastObservedTransferRate,
+				LastObservedTransferredFiles: dvmp.Status.LastObservedTransferredFiles,
+				LastObservedRemainingFiles:   dvmp.Status.LastObservedRemainingFiles,
+				LastObservedTotalFiles:       dvmp.Status.LastObservedTotalFiles,
+				TotalElapsedTime:             dvmp.Status.RsyncElapsedTime,
+				RsyncStats:                   dvmp.Status.RsyncStats,
 			}
 			switch {
 			case dvmp.Status.PodPhase == corev1.PodRunning:
 				t.Owner.Status.RunningPods = append(t.Owner.Status.RunningPods, podProgress)
 			case operation.Failed:
 				t.Owner.Status.FailedPods = append(t.Owner.Status.FailedPods, podProgress)
-			case dvmp.Status.PodPhase == corev1.PodSucceeded:
+			case dvmp.Status.PodPhase == corev1.PodSucceeded, operation.Succeeded:
 				t.Owner.Status.SuccessfulPods = append(t.Owner.Status.SuccessfulPods, podProgress)
 			case dvmp.Status.PodPhase == corev1.PodPending:
 				t.Owner.Status.PendingPods = append(t.Owner.Status.PendingPods, podProgress)
@@ -1355,7 +1869,7 @@ func (t *Task) deleteRsyncResources() error {
 		return err
 	}
 
-	if !t.Owner.Spec.DeleteProgressReportingCRs {
+	if !t.deletesProgressReportingCRs() {
 		return nil
 	}
 
@@ -1523,18 +2037,107 @@ func (t *Task) findAndDeleteResources(srcClient, destClient compat.Client, pvcMa
 	for bothNs, _ := range pvcMap {
 		srcNs := getSourceNs(bothNs)
 		destNs := getDestNs(bothNs)
-		err := t.findAndDeleteNsResources(srcClient, srcNs, selector)
+		err := t.findAndDeleteNsResources(srcClient, srcNs, selector, nil)
 		if err != nil {
 			return err
 		}
-		err = t.findAndDeleteNsResources(destClient, destNs, selector)
+		err = t.findAndDeleteNsResources(destClient, destNs, selector, nil)
 		if err != nil {
 			return err
 		}
 	}
 	return nil
 }
-func (t *Task) findAndDeleteNsResources(client compat.Client, ns string, selector labels.Selector) error {
+
+// Sweep the stale Rsync resources left in the namespaces of the DVM by prior DVMs.
+// Only resources labeled as Rsync transfer resources are considered, resources of
+// a different transfer generation are deleted unless the DVM owning them is still
+// running. Resources without a transfer generation predate the generation label,
+// the DVM correlation label is used in its place when it holds a DVM UID, and
+// resources without either are never deleted.
+func (t *Task) sweepStaleRsyncResources() error {
+	srcClient, err := t.getSourceClient()
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	destClient, err := t.getDestinationClient()
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	active, err := t.getActiveRsyncTransferGenerations()
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	isStale := func(resourceLabels map[string]string) bool {
+		return isStaleRsyncResource(resourceLabels, string(t.Owner.UID), active)
+	}
+	selector := labels.SelectorFromSet(map[string]string{
+		"app": DirectVolumeMigrationRsyncTransfer,
+	})
+	t.Log.Info("Sweeping stale Rsync resources on source and destination MigClusters",
+		"generation", string(t.Owner.UID))
+	for bothNs, _ := range t.getPVCNamespaceMap() {
+		err := t.findAndDeleteNsResources(srcClient, getSourceNs(bothNs), selector, isStale)
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		err = t.findAndDeleteNsResources(destClient, getDestNs(bothNs), selector, isStale)
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+	}
+	err = t.deleteRsyncPassword()
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	if !t.deletesProgressReportingCRs() {
+		return nil
+	}
+	err = t.deleteProgressReportingCRs(t.Client)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	return nil
+}
+
+// Get the transfer generations of the DVMs which are neither completed, failed nor canceled.
+func (t *Task) getActiveRsyncTransferGenerations() (map[string]bool, error) {
+	list := migapi.DirectVolumeMigrationList{}
+	err := t.Client.List(context.TODO(), &list)
+	if err != nil {
+		return nil, liberr.Wrap(err)
+	}
+	active := map[string]bool{}
+	for _, dvm := range list.Items {
+		if dvm.Status.HasAnyCondition(Succeeded, Failed) || dvm.Status.Phase == Canceled {
+			continue
+		}
+		active[string(dvm.UID)] = true
+	}
+	return active, nil
+}
+
+// Whether an Rsync resource is stale for the given transfer generation.
+func isStaleRsyncResource(resourceLabels map[string]string, generation string, active map[string]bool) bool {
+	if resourceLabels["app"] != DirectVolumeMigrationRsyncTransfer {
+		return false
+	}
+	resourceGeneration, found := resourceLabels[RsyncTransferGenerationLabel]
+	if !found {
+		// predates the generation label, fall back to the DVM correlation label
+		// when it holds a DVM UID, the Rsync client Pods overwrite it with their role
+		key, _ := migapi.CorrelationLabel(&migapi.DirectVolumeMigration{}, "")
+		resourceGeneration = resourceLabels[key]
+		if _, err := uuid.Parse(resourceGeneration); err != nil {
+			return false
+		}
+	}
+	return resourceGeneration != generation && !active[resourceGeneration]
+}
+
+// Delete the resources matching the selector in the namespace. When set, only
+// the resources whose labels pass the filter are deleted.
+func (t *Task) findAndDeleteNsResources(client compat.Client, ns string, selector labels.Selector, filter func(map[string]string) bool) error {
 	podList := corev1.PodList{}
 	cmList := corev1.ConfigMapList{}
 	svcList := corev1.ServiceList{}
@@ -1602,6 +2205,9 @@ func (t *Task) findAndDeleteNsResources(client compat.Client, ns string, selecto
 
 	// Delete pods
 	for _, pod := range podList.Items {
+		if filter != nil && !filter(pod.Labels) {
+			continue
+		}
 		t.Log.Info("Deleting stale DVM Pod",
 			"pod", path.Join(pod.Namespace, pod.Name))
 		err = client.Delete(context.TODO(), &pod, k8sclient.PropagationPolicy(metav1.DeletePropagationBackground))
@@ -1612,6 +2218,9 @@ func (t *Task) findAndDeleteNsResources(client compat.Client, ns string, selecto
 
 	// Delete secrets
 	for _, secret := range secretList.Items {
+		if filter != nil && !filter(secret.Labels) {
+			continue
+		}
 		t.Log.Info("Deleting stale DVM Secret",
 			"secret", path.Join(secret.Namespace, secret.Name))
 		err = client.Delete(context.TODO(), &secret, k8sclient.PropagationPolicy(metav1.DeletePropagationBackground))
@@ -1622,6 +2231,9 @@ func (t *Task) findAndDeleteNsResources(client compat.Client, ns string, selecto
 
 	// Delete routes
 	for _, route := range routeList.Items {
+		if filter != nil && !filter(route.Labels) {
+			continue
+		}
 		t.Log.Info("Deleting stale DVM Route",
 			"route", path.Join(route.Namespace, route.Name))
 		err = client.Delete(context.TODO(), &route, k8sclient.PropagationPolicy(metav1.DeletePropagationBackground))
@@ -1632,6 +2244,9 @@ func (t *Task) findAndDeleteNsResources(client compat.Client, ns string, selecto
 
 	// Delete svcs
 	for _, svc := range svcList.Items {
+		if filter != nil && !filter(svc.Labels) {
+			continue
+		}
 		t.Log.Info("Deleting stale DVM Service",
 			"service", path.Join(svc.Namespace, svc.Name))
 		err = client.Delete(context.TODO(), &svc, k8sclient.PropagationPolicy(metav1.DeletePropagationBackground))
@@ -1642,6 +2257,9 @@ func (t *Task) findAndDeleteNsResources(client compat.Client, ns string, selecto
 
 	// Delete configmaps
 	for _, cm := range cmList.Items {
+		if filter != nil && !filter(cm.Labels) {
+			continue
+		}
 		t.Log.Info("Deleting stale DVM ConfigMap",
 			"configMap", path.Join(cm.Namespace, cm.Name))
 		err = client.Delete(context.TODO(), &cm, k8sclient.PropagationPolicy(metav1.DeletePropagationBackground))
@@ -1660,11 +2278,11 @@ func (t *Task) deleteProgressReportingCRs(client k8sclient.Client) error {
 		for _, vol := range vols {
 			dvmpName := getMD5Hash(t.Owner.Name + vol.Name + ns)
 			t.Log.Info("Deleting stale DVMP CR.",
-				"dvmp", path.Join(dvmpName, ns))
+				"dvmp", path.Join(migapi.OpenshiftMigrationNamespace, dvmpName))
 			err := client.Delete(context.TODO(), &migapi.DirectVolumeMigrationProgress{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      dvmpName,
-					Namespace: ns,
+					Namespace: migapi.OpenshiftMigrationNamespace,
 				},
 			}, k8sclient.PropagationPolicy(metav1.DeletePropagationBackground))
 			if err != nil && !k8serror.IsNotFound(err) {
@@ -1693,10 +2311,26 @@ type rsyncClientPodRequirements struct {
 	stunnelResourceReq corev1.ResourceRequirements
 	// nodeName node on which Rsync Pod will be launched
 	nodeName string
+	// nodeAffinity node affinity of the Rsync Pod reading the PVC on the node hosting its volume
+	nodeAffinity *corev1.NodeAffinity
 	// destIP destination IP address for Stunnel route
 	destIP string
 	// rsyncOptions rsync command to execute
 	rsyncOptions []string
+	// sourceReadOnly whether the source PVC is mounted read-only
+	sourceReadOnly bool
+	// activeDeadlineSeconds duration the Rsync Pod may run before Kubernetes terminates it
+	activeDeadlineSeconds *int64
+	// rsyncFilter whether the Rsync filter files are mounted in the Rsync Pod
+	rsyncFilter bool
+	// openFilesLimit open files limit raised before running Rsync, the default of the container runtime when 0
+	openFilesLimit int64
+	// skipUnchanged whether the transfer is skipped when an Rsync dry run finds no difference
+	skipUnchanged bool
+	// bwLimitRampUp duration over which the bandwidth limit is raised to the limit, not ramped up when 0
+	bwLimitRampUp time.Duration
+	// largeFileStreams number of parallel Rsync streams the large files are split among, not split when 0
+	largeFileStreams int
 }
 
 // getRsyncClientPodTemplate given RsyncClientPodRequirements, returns a Pod template
@@ -1707,10 +2341,19 @@ func (req rsyncClientPodRequirements) getRsyncClientPodTemplate() corev1.Pod {
 	volumes := []corev1.Volume{}
 	rsyncVolumeMounts := []corev1.VolumeMount{}
 	containers := []corev1.Container{}
-	rsyncVolumeMounts = append(rsyncVolumeMounts, corev1.VolumeMount{
-		Name:      req.pvInfo.pvcHash,
-		MountPath: fmt.Sprintf("/mnt/%s/%s", req.namespace, req.pvInfo.pvcHash),
-	})
+	rsyncVolumeDevices := []corev1.VolumeDevice{}
+	if req.pvInfo.block {
+		rsyncVolumeDevices = append(rsyncVolumeDevices, corev1.VolumeDevice{
+			Name:       req.pvInfo.pvcHash,
+			DevicePath: getBlockDevicePath(req.namespace, req.pvInfo.pvcHash),
+		})
+	} else {
+		rsyncVolumeMounts = append(rsyncVolumeMounts, corev1.VolumeMount{
+			Name:      req.pvInfo.pvcHash,
+			MountPath: fmt.Sprintf("/mnt/%s/%s", req.namespace, req.pvInfo.pvcHash),
+			ReadOnly:  req.sourceReadOnly,
+		})
+	}
 
 	// shared volumeMount for inter-process communication between rsync and stunnel
 	rsyncVolumeMounts = append(rsyncVolumeMounts, corev1.VolumeMount{
@@ -1718,6 +2361,14 @@ func (req rsyncClientPodRequirements) getRsyncClientPodTemplate() corev1.Pod {
 		MountPath: "/usr/share/rsync-stunnel-mgmt",
 	})
 
+	if req.rsyncFilter {
+		rsyncVolumeMounts = append(rsyncVolumeMounts, corev1.VolumeMount{
+			Name:      "rsync-filter",
+			MountPath: RsyncFilterMountPath,
+			ReadOnly:  true,
+		})
+	}
+
 	stunnelVolumeMounts := []corev1.VolumeMount{
 		{
 			Name:      "stunnel-conf",
@@ -1734,23 +2385,24 @@ func (req rsyncClientPodRequirements) getRsyncClientPodTemplate() corev1.Pod {
 		},
 	}
 
+	// A PVC already in use by a running workload is attached read-write to the node,
+	// attaching it read-only once more may fail. The mount itself stays read-only.
 	volumes = append(volumes, corev1.Volume{
 		Name: req.pvInfo.pvcHash,
 		VolumeSource: corev1.VolumeSource{
 			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
 				ClaimName: req.pvInfo.name,
+				ReadOnly:  req.sourceReadOnly && req.nodeName == "",
 			},
 		},
 	})
 
-	// append stunnel config volume
+	// append stunnel config volume, a Secret holding the proxy credentials
 	volumes = append(volumes, corev1.Volume{
 		Name: "stunnel-conf",
 		VolumeSource: corev1.VolumeSource{
-			ConfigMap: &corev1.ConfigMapVolumeSource{
-				LocalObjectReference: corev1.LocalObjectReference{
-					Name: DirectVolumeMigrationStunnelConfig,
-				},
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: DirectVolumeMigrationStunnelConfig,
 			},
 		},
 	})
@@ -1787,13 +2439,59 @@ func (req rsyncClientPodRequirements) getRsyncClientPodTemplate() corev1.Pod {
 		},
 	})
 
-	rsyncCommand := []string{"rsync"}
-	rsyncCommand = append(rsyncCommand, req.rsyncOptions...)
-	rsyncCommand = append(rsyncCommand, fmt.Sprintf("/mnt/%s/%s/", req.namespace, req.pvInfo.pvcHash))
-	rsyncCommand = append(rsyncCommand, fmt.Sprintf("rsync://root@%s/%s", req.destIP, req.pvInfo.pvcHash))
+	// append rsync filter files
+	if req.rsyncFilter {
+		volumes = append(volumes, corev1.Volume{
+			Name: "rsync-filter",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: DirectVolumeMigrationRsyncFilter,
+					},
+				},
+			},
+		})
+	}
 
-	rsyncCommandStr := strings.Join(rsyncCommand, " ")
-	rsyncCommandBashScript := fmt.Sprintf("trap \"touch /usr/share/rsync-stunnel-mgmt/rsync-client-container-done\" EXIT SIGINT SIGTERM; timeout=600; SECONDS=0; while [ $SECONDS -lt $timeout ]; do nc -z localhost 2222; rc=$?; if [ $rc -eq 0 ]; then %s; rc=$?; break; fi; done; exit $rc;", rsyncCommandStr)
+	source := fmt.Sprintf("/mnt/%s/%s/", req.namespace, req.pvInfo.pvcHash)
+	destination := fmt.Sprintf("rsync://root@%s/%s", req.destIP, req.pvInfo.pvcHash)
+	if req.pvInfo.block {
+		// the device is transferred as a single file in the Rsync module
+		source = getBlockDevicePath(req.namespace, req.pvInfo.pvcHash)
+		destination += "/"
+	}
+	getTransferCommand := func(rsyncOptions []string) string {
+		rsyncCommand := []string{"rsync"}
+		rsyncCommand = append(rsyncCommand, rsyncOptions...)
+		rsyncCommand = append(rsyncCommand, source)
+		rsyncCommand = append(rsyncCommand, destination)
+
+		rsyncCommandStr := strings.Join(rsyncCommand, " ")
+		if req.pvInfo.shards > 1 && !req.pvInfo.block {
+			rsyncCommandStr = getShardedRsyncCommand(rsyncOptions, source, destination, req.pvInfo.shards, "/usr/share/rsync-stunnel-mgmt")
+		}
+		if req.bwLimitRampUp > 0 && req.pvInfo.shards <= 1 && !req.pvInfo.block {
+			rsyncCommandStr = getBwLimitRampUpCommand(rsyncOptions, source, destination, req.bwLimitRampUp, rsyncCommandStr)
+		}
+		if req.largeFileStreams > 1 {
+			rsyncCommandStr = getLargeFileStreamsCommand(rsyncOptions, source, destination, req.largeFileStreams, "/usr/share/rsync-stunnel-mgmt", rsyncCommandStr)
+		}
+		if req.skipUnchanged {
+			rsyncCommandStr = getUnchangedPrecheckCommand(rsyncOptions, source, destination, rsyncCommandStr)
+		}
+		return rsyncCommandStr
+	}
+	rsyncCommandStr := getTransferCommand(req.rsyncOptions)
+	// destination filesystems without ACL or extended attribute support fail the
+	// transfer, which is run again without preserving them
+	if withoutXattrs := getRsyncWithoutXattrsOptions(req.rsyncOptions); len(withoutXattrs) < len(req.rsyncOptions) {
+		rsyncCommandStr = getXattrsFallbackCommand(rsyncCommandStr, getTransferCommand(withoutXattrs), "/usr/share/rsync-stunnel-mgmt")
+	}
+	rsyncResources := req.rsyncResourceReq
+	if req.largeFileStreams > 1 {
+		rsyncResources = getLargeFileStreamsResources(req.rsyncResourceReq, req.largeFileStreams)
+	}
+	rsyncCommandBashScript := fmt.Sprintf("trap \"touch /usr/share/rsync-stunnel-mgmt/rsync-client-container-done\" EXIT SIGINT SIGTERM; timeout=600; SECONDS=0; while [ $SECONDS -lt $timeout ]; do nc -z localhost 2222; rc=$?; if [ $rc -eq 0 ]; then %s%s%s; rc=$?; break; fi; done; exit $rc;", RsyncVersionCommand, getOpenFilesLimitCommand(req.openFilesLimit), rsyncCommandStr)
 	rsyncContainerCommand := []string{
 		"/bin/bash",
 		"-c",
@@ -1838,7 +2536,8 @@ func (req rsyncClientPodRequirements) getRsyncClientPodTemplate() corev1.Pod {
 				ContainerPort: int32(22),
 			},
 		},
-		VolumeMounts: rsyncVolumeMounts,
+		VolumeMounts:  rsyncVolumeMounts,
+		VolumeDevices: rsyncVolumeDevices,
 		SecurityContext: &corev1.SecurityContext{
 			Privileged:             &isPrivileged,
 			RunAsUser:              &runAsUser,
@@ -1847,7 +2546,7 @@ func (req rsyncClientPodRequirements) getRsyncClientPodTemplate() corev1.Pod {
 				Drop: []corev1.Capability{"MKNOD", "SETPCAP"},
 			},
 		},
-		Resources: req.rsyncResourceReq,
+		Resources: rsyncResources,
 	})
 
 	// append stunnel container
@@ -1883,10 +2582,11 @@ func (req rsyncClientPodRequirements) getRsyncClientPodTemplate() corev1.Pod {
 			Annotations:  map[string]string{migapi.RsyncPodIdentityLabel: req.pvInfo.name},
 		},
 		Spec: corev1.PodSpec{
-			RestartPolicy: corev1.RestartPolicyNever,
-			Volumes:       volumes,
-			Containers:    containers,
-			NodeName:      req.nodeName,
+			RestartPolicy:         corev1.RestartPolicyNever,
+			ActiveDeadlineSeconds: req.activeDeadlineSeconds,
+			Volumes:               volumes,
+			Containers:            containers,
+			NodeName:              req.nodeName,
 			SecurityContext: &corev1.PodSecurityContext{
 				SupplementalGroups: req.pvInfo.supplementalGroups,
 				FSGroup:            req.pvInfo.fsGroup,
@@ -1894,15 +2594,63 @@ func (req rsyncClientPodRequirements) getRsyncClientPodTemplate() corev1.Pod {
 			},
 		},
 	}
+	if req.nodeAffinity != nil {
+		clientPod.Spec.Affinity = &corev1.Affinity{NodeAffinity: req.nodeAffinity}
+	}
 	return clientPod
 }
 
+// Get the Rsync command transferring the top-level entries of the source split
+// among concurrent Rsync processes. Each top-level entry is assigned to a single
+// shard, the shards never overlap. Once all shards succeed, a final Rsync pass over
+// the whole source reconciles the tree, e.g. top-level deletions and attributes.
+// The shard lists are written to the volume shared with the Stunnel container as
+// the root filesystem of the Rsync container is read-only.
+func getShardedRsyncCommand(rsyncOptions []string, source string, destination string, shards int, shardDir string) string {
+	rsync := strings.Join(append([]string{"rsync"}, rsyncOptions...), " ")
+	hardLinks := hasRsyncOption(rsyncOptions, "--hard-links") || hasRsyncOption(rsyncOptions, "-H")
+	split := getRsyncShardSplitCommand(source, shardDir, shards, hardLinks)
+	run := fmt.Sprintf("pids=(); for s in $(seq 0 %d); do if [ -s %s/shard-$s ]; then %s --recursive --from0 --files-from=%s/shard-$s %s %s & pids+=($!); fi; done; rc=0; for p in ${pids[@]}; do wait $p || rc=$?; done",
+		shards-1, shardDir, rsync, shardDir, source, destination)
+	reconcile := fmt.Sprintf("if [ $rc -eq 0 ]; then %s %s %s; else (exit $rc); fi", rsync, source, destination)
+	return strings.Join([]string{split, run, reconcile}, "; ")
+}
+
+// Get the command writing the shard lists. When hard links are preserved, the
+// shards only list the files of their top-level entries which have a single link.
+// Files with several links are left to the final pass which transfers each group
+// of links once, links spanning several top-level entries are never split among
+// shards and duplicated on the destination.
+func getRsyncShardSplitCommand(source string, shardDir string, shards int, hardLinks bool) string {
+	if hardLinks {
+		return fmt.Sprintf("shopt -s dotglob nullglob; i=0; for f in %s*; do (cd %s && find \"${f#%s}\" ! -type d -links 1 -print0) >> %s/shard-$((i %% %d)); i=$((i+1)); done",
+			source, source, source, shardDir, shards)
+	}
+	return fmt.Sprintf("shopt -s dotglob nullglob; i=0; for f in %s*; do printf '%%s\\0' \"${f#%s}\" >> %s/shard-$((i %% %d)); i=$((i+1)); done",
+		source, source, shardDir, shards)
+}
+
+// Get whether the Rsync options contain the option
+func hasRsyncOption(rsyncOptions []string, option string) bool {
+	for _, opt := range rsyncOptions {
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
+
 func (t *Task) prepareRsyncPodRequirements(srcClient compat.Client) ([]rsyncClientPodRequirements, error) {
 	req := []rsyncClientPodRequirements{}
 	cluster, err := t.Owner.GetSourceCluster(t.Client)
 	if err != nil {
 		return req, liberr.Wrap(err)
 	}
+	t.Log.V(4).Info("Getting default Rsync tuning of destination MigCluster")
+	t.clusterRsyncTuning, err = t.getClusterRsyncTuning()
+	if err != nil {
+		return req, liberr.Wrap(err)
+	}
 	t.Log.V(4).Info("Getting image for Rsync client Pods that will be created on source MigCluster")
 	transferImage, err := cluster.GetRsyncTransferImage(t.Client)
 	if err != nil {
@@ -1933,15 +2681,91 @@ func (t *Task) prepareRsyncPodRequirements(srcClient compat.Client) ([]rsyncClie
 	if err != nil {
 		return req, liberr.Wrap(err)
 	}
+	nodeAffinities := map[string]*corev1.NodeAffinity{}
+	if t.Owner.Spec.NodeLocalReads {
+		t.Log.V(4).Info("Getting [PVC => NodeAffinity] mapping for PVCs read on the node hosting their volume")
+		nodeAffinities, err = t.getPVCNodeAffinities(srcClient, pvcMap, pvcNodeMap)
+		if err != nil {
+			return req, liberr.Wrap(err)
+		}
+	}
 	isPrivileged, _ := isRsyncPrivileged(srcClient)
 	t.Log.V(4).Info(fmt.Sprintf("Rsync client Pods will be created with privileged=[%v]", isPrivileged))
+	endpointTypes := map[string]string{}
+	attachedReadWrite := []string{}
 	for ns, vols := range pvcMap {
 		// Add PVC volume mounts
 		for _, vol := range vols {
-			rsyncOptions := t.getRsyncOptions()
-			if vol.verify {
+			endpointType, found := endpointTypes[vol.targetNamespace]
+			if !found {
+				endpointType, err = t.getEndpointType(vol.targetNamespace)
+				if err != nil {
+					return req, liberr.Wrap(err)
+				}
+				endpointTypes[vol.targetNamespace] = endpointType
+			}
+			rsyncOptions := t.getEndpointRsyncOptions(endpointType)
+			rsyncOptions = append(rsyncOptions, RsyncProtocolDebugOption)
+			// the attempts retried after an outage of the source cluster resume the partially transferred files
+			if t.hasSourceClusterRecovered() && !hasRsyncOption(rsyncOptions, "--partial") {
+				rsyncOptions = append(rsyncOptions, "--partial")
+			}
+			// the modification times can't be compared when the clocks of the clusters are skewed
+			if vol.verify || t.hasClockSkew() {
 				rsyncOptions = append(rsyncOptions, "--checksum")
 			}
+			if vol.maxSize != "" {
+				rsyncOptions = append(rsyncOptions, fmt.Sprintf("--max-size=%s", vol.maxSize))
+			}
+			if vol.minSize != "" {
+				rsyncOptions = append(rsyncOptions, fmt.Sprintf("--min-size=%s", vol.minSize))
+			}
+			sparseMode, sparseOptions := getRsyncSparseOptions(vol.sparse)
+			rsyncOptions = append(rsyncOptions, sparseOptions...)
+			if !t.isVerifyOnly() {
+				rsyncOptions = append(rsyncOptions, getRsyncTempDirOptions(t.Owner.Spec.RsyncTempDir)...)
+			}
+			if !t.isVerifyOnly() && !vol.block {
+				rsyncOptions = append(rsyncOptions, t.getRsyncPartialDirOptions()...)
+			}
+			rsyncOptions = append(rsyncOptions, getRsyncFilterOptions(t.Owner.Spec.RsyncFilter)...)
+			if vol.block {
+				// last, for the metadata of the device nodes not to be transferred
+				rsyncOptions = append(rsyncOptions, getRsyncBlockOptions()...)
+				t.Log.Info("Rsync client Pod will transfer the content of the raw block device of the PVC",
+					"persistentVolumeClaim", path.Join(ns, vol.name))
+				if t.Owner.Spec.BlockDelta {
+					rsyncOptions = getRsyncBlockDeltaOptions(rsyncOptions)
+					t.Log.Info("Rsync client Pod will only transfer the changed blocks of the raw block device of the PVC",
+						"persistentVolumeClaim", path.Join(ns, vol.name))
+				}
+			}
+			if t.isVerifyOnly() {
+				rsyncOptions = getVerifyOnlyRsyncOptions(rsyncOptions)
+			}
+			if vol.shards > 1 {
+				t.Log.V(4).Info("Rsync client Pod will split the transfer of the PVC among concurrent Rsync processes",
+					"persistentVolumeClaim", path.Join(ns, vol.name),
+					"shards", vol.shards)
+			}
+			if streams := t.getLargeFileStreams(vol); streams > 1 {
+				t.Log.V(4).Info("Rsync client Pod will split the transfer of the large files of the PVC among parallel Rsync streams",
+					"persistentVolumeClaim", path.Join(ns, vol.name),
+					"streams", streams)
+			}
+			if vol.maxSize != "" || vol.minSize != "" {
+				t.Log.V(4).Info("Rsync client Pod will only transfer files within size filters",
+					"persistentVolumeClaim", path.Join(ns, vol.name),
+					"maxSize", vol.maxSize,
+					"minSize", vol.minSize)
+			}
+			t.Log.Info("Rsync client Pod will handle sparse files with the resolved mode",
+				"persistentVolumeClaim", path.Join(ns, vol.name),
+				"sparse", sparseMode)
+			nodeName := pvcNodeMap[ns+"/"+vol.name]
+			if settings.Settings.DvmOpts.SourceReadOnly && nodeName != "" {
+				attachedReadWrite = append(attachedReadWrite, path.Join(ns, vol.name))
+			}
 			podRequirements := rsyncClientPodRequirements{
 				pvInfo:    vol,
 				namespace: ns,
@@ -1955,17 +2779,46 @@ func (t *Task) prepareRsyncPodRequirements(srcClient compat.Client) ([]rsyncClie
 					Limits:   stunnelLimits,
 					Requests: stunnelRequests,
 				},
-				privileged:   isPrivileged,
-				nodeName:     pvcNodeMap[ns+"/"+vol.name],
-				destIP:       "localhost",
-				rsyncOptions: rsyncOptions,
+				privileged:            isPrivileged,
+				nodeName:              nodeName,
+				nodeAffinity:          nodeAffinities[ns+"/"+vol.name],
+				destIP:                "localhost",
+				rsyncOptions:          rsyncOptions,
+				sourceReadOnly:        settings.Settings.DvmOpts.SourceReadOnly,
+				activeDeadlineSeconds: t.getRsyncPodActiveDeadlineSeconds(),
+				rsyncFilter:           t.hasRsyncFilter(),
+				openFilesLimit:        t.getPVCOpenFilesLimit(ns, vol.name),
+				skipUnchanged:         t.canSkipUnchangedPVC(vol),
+				bwLimitRampUp:         t.getRsyncBwLimitRampUp(),
+				largeFileStreams:      t.getLargeFileStreams(vol),
 			}
 			req = append(req, podRequirements)
 		}
 	}
+	t.setSourcePVCsAttachedReadWrite(attachedReadWrite)
 	return req, nil
 }
 
+// Warn about the source PVCs in use by a running Pod, attached read-write
+// with a read-only mount in their Rsync client Pod.
+func (t *Task) setSourcePVCsAttachedReadWrite(pvcs []string) {
+	if len(pvcs) == 0 {
+		return
+	}
+	sort.Strings(pvcs)
+	t.Log.Info("Source PVCs are in use by running Pods, attaching them read-write with a read-only mount in the Rsync client Pods.",
+		"persistentVolumeClaims", pvcs)
+	t.Owner.Status.SetCondition(migapi.Condition{
+		Type:     SourcePVCsAttachedReadWrite,
+		Status:   True,
+		Reason:   InUse,
+		Category: Warn,
+		Message:  SourcePVCsAttachedReadWriteMessage,
+		Items:    pvcs,
+		Durable:  true,
+	})
+}
+
 func (t *Task) getRsyncOperationsRequirements() (compat.Client, []rsyncClientPodRequirements, error) {
 	srcClient, err := t.getSourceClient()
 	if err != nil {
@@ -2009,6 +2862,8 @@ func (t *Task) runRsyncOperations() (bool, bool, []string, error) {
 	if err != nil {
 		return false, false, failureReasons, liberr.Wrap(err)
 	}
+	t.updateEstimatedCompletion(time.Now())
+	t.setThroughputBelowBaseline()
 	operationsCompleted, anyFailed, failureReasons, err := t.processRsyncOperationStatus(status, garbageCollectionErrors)
 	if err != nil {
 		return false, false, failureReasons, liberr.Wrap(err)
@@ -2020,6 +2875,8 @@ func (t *Task) runRsyncOperations() (bool, bool, []string, error) {
 // returns whether all operations are completed and whether any of the operation is failed
 func (t *Task) processRsyncOperationStatus(status rsyncClientOperationStatusList, garbageCollectionErrors []error) (bool, bool, []string, error) {
 	isComplete, anyFailed, failureReasons := false, false, make([]string, 0)
+	t.reportRsyncWarnings(status)
+	t.setSourceVolumeAttachFailed(status)
 	if status.AllCompleted() {
 		isComplete = true
 		// we are done running rsync, we can move on
@@ -2027,10 +2884,17 @@ func (t *Task) processRsyncOperationStatus(status rsyncClientOperationStatusList
 		if status.Failed() > 0 {
 			anyFailed = true
 			// attempt to categorize failures in any of the special failure categories we defined
-			failureReasons, err := t.reportAdvancedErrorHeuristics()
+			heuristicReasons, err := t.reportAdvancedErrorHeuristics()
 			if err != nil {
 				return isComplete, anyFailed, failureReasons, liberr.Wrap(err)
 			}
+			failureReasons = append(failureReasons, heuristicReasons...)
+			// report why each of the failed PVCs failed
+			pvcReasons, err := t.reportPVCFailureReasons(status)
+			if err != nil {
+				return isComplete, anyFailed, failureReasons, liberr.Wrap(err)
+			}
+			failureReasons = append(failureReasons, pvcReasons...)
 		}
 		return isComplete, anyFailed, failureReasons, nil
 	}
@@ -2118,6 +2982,221 @@ func (t *Task) reportAdvancedErrorHeuristics() ([]string, error) {
 	return reasons, nil
 }
 
+// rsyncLogFailurePatterns known Rsync errors found in the logs of failed Rsync Pods
+var rsyncLogFailurePatterns = []struct {
+	pattern string
+	reason  string
+}{
+	{pattern: "No space left on device", reason: "no space left on destination volume"},
+	{pattern: "Disk quota exceeded", reason: "disk quota exceeded on destination volume"},
+	{pattern: "Permission denied", reason: "permission denied"},
+	{pattern: "No route to host", reason: "no route to host"},
+	{pattern: "Connection refused", reason: "connection refused"},
+	{pattern: "connection unexpectedly closed", reason: "connection closed unexpectedly"},
+	{pattern: "some files vanished before they could be transferred", reason: "source files vanished during the transfer"},
+}
+
+// Outcomes of a failed Rsync attempt decided from the exit code of rsync
+const (
+	RsyncExitCodeRetry = "Retry"
+	RsyncExitCodeWarn  = "Warn"
+	RsyncExitCodeFail  = "Fail"
+)
+
+// rsyncExitCodeOutcomes default outcomes of rsync exit codes, see EXIT VALUES in rsync(1).
+// Exit codes not listed are retried. Overridden by the RSYNC_EXIT_CODE_OUTCOMES setting.
+var rsyncExitCodeOutcomes = map[int]string{
+	1:  RsyncExitCodeFail,  // syntax or usage error
+	2:  RsyncExitCodeFail,  // protocol incompatibility
+	4:  RsyncExitCodeFail,  // requested action not supported
+	5:  RsyncExitCodeRetry, // error starting client-server protocol
+	10: RsyncExitCodeRetry, // error in socket I/O
+	11: RsyncExitCodeRetry, // error in file I/O
+	12: RsyncExitCodeRetry, // error in rsync protocol data stream
+	23: RsyncExitCodeRetry, // partial transfer due to error
+	24: RsyncExitCodeWarn,  // partial transfer due to vanished source files
+	30: RsyncExitCodeRetry, // timeout in data send/receive
+	35: RsyncExitCodeRetry, // timeout waiting for daemon connection
+}
+
+// getRsyncExitCodeOutcome returns whether a failed Rsync attempt is retried, treated as
+// succeeded with a warning or failed. Attempts without an exit code are retried.
+func getRsyncExitCodeOutcome(exitCode *int32) string {
+	if exitCode == nil {
+		return RsyncExitCodeRetry
+	}
+	if outcome, found := settings.Settings.DvmOpts.ExitCodeOutcomes[int(*exitCode)]; found {
+		return outcome
+	}
+	if outcome, found := rsyncExitCodeOutcomes[int(*exitCode)]; found {
+		return outcome
+	}
+	return RsyncExitCodeRetry
+}
+
+// getRsyncPodActiveDeadlineSeconds returns the active deadline of the Rsync Pods.
+// When set neither in the spec nor in the destination cluster ConfigMap, Rsync Pods
+// are terminated once the Deadline of the migration is exceeded. Returns nil when
+// none is set.
+func (t *Task) getRsyncPodActiveDeadlineSeconds() *int64 {
+	if t.Owner.Spec.RsyncPodActiveDeadlineSeconds != nil {
+		return t.Owner.Spec.RsyncPodActiveDeadlineSeconds
+	}
+	if t.clusterRsyncTuning != nil && t.clusterRsyncTuning.podActiveDeadlineSeconds != nil {
+		return t.clusterRsyncTuning.podActiveDeadlineSeconds
+	}
+	if t.Owner.Spec.Deadline == nil || t.Owner.Status.StartTimestamp == nil {
+		return nil
+	}
+	remaining := int64((t.Owner.Spec.Deadline.Duration - time.Since(t.Owner.Status.StartTimestamp.Time)).Seconds())
+	if remaining < 1 {
+		remaining = 1
+	}
+	return &remaining
+}
+
+// getRsyncContainerExitCode returns the exit code of the terminated rsync container of an Rsync client Pod
+func getRsyncContainerExitCode(pod *corev1.Pod) *int32 {
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if containerStatus.Name == DirectVolumeMigrationRsyncClient && containerStatus.State.Terminated != nil {
+			exitCode := containerStatus.State.Terminated.ExitCode
+			return &exitCode
+		}
+	}
+	return nil
+}
+
+// reportRsyncWarnings reports the Rsync operations which succeeded with a warning in a condition
+func (t *Task) reportRsyncWarnings(status rsyncClientOperationStatusList) {
+	// operations complete over several reconciles, keep the warnings reported earlier
+	warnings := []string{}
+	if existing := t.Owner.Status.FindCondition(RsyncCompletedWithWarnings); existing != nil {
+		warnings = append(warnings, existing.Items...)
+	}
+	reported := map[string]bool{}
+	for _, warning := range warnings {
+		reported[warning] = true
+	}
+	for _, op := range status.ops {
+		if op.warning != "" && !reported[op.warning] {
+			warnings = append(warnings, op.warning)
+			reported[op.warning] = true
+		}
+	}
+	if len(warnings) == 0 {
+		return
+	}
+	t.Owner.Status.SetCondition(migapi.Condition{
+		Type:     RsyncCompletedWithWarnings,
+		Status:   True,
+		Reason:   Warned,
+		Category: Warn,
+		Message:  RsyncCompletedWithWarningsMessage,
+		Items:    warnings,
+		Durable:  true,
+	})
+}
+
+// getRsyncFailureReason returns a human readable reason for a failed Rsync attempt
+func getRsyncFailureReason(podStatus *migapi.RsyncPodStatus) string {
+	if podStatus == nil || podStatus.ExitCode == nil {
+		return "Rsync Pod failed without reporting an exit code"
+	}
+	for _, known := range rsyncLogFailurePatterns {
+		if strings.Contains(podStatus.LogMessage, known.pattern) {
+			return fmt.Sprintf("%s (exit code %d)", known.reason, *podStatus.ExitCode)
+		}
+	}
+	return fmt.Sprintf("rsync exited with code %d", *podStatus.ExitCode)
+}
+
+// rsyncDiskFullPatterns errors logged by Rsync when the destination volume is full
+var rsyncDiskFullPatterns = []string{
+	"No space left on device",
+	"Disk quota exceeded",
+}
+
+// isDestinationVolumeFull returns whether a failed Rsync attempt failed writing
+// to a full destination volume. Rsync exits with a file I/O error or a partial
+// transfer in that case, the cause is only found in its logs.
+func isDestinationVolumeFull(podStatus *migapi.RsyncPodStatus) bool {
+	if podStatus == nil || podStatus.ExitCode == nil {
+		return false
+	}
+	for _, pattern := range rsyncDiskFullPatterns {
+		if strings.Contains(podStatus.LogMessage, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// getDestinationPVC returns the namespaced name of the destination PVC of an Rsync operation
+func (t *Task) getDestinationPVC(operation *migapi.RsyncOperation) string {
+	ns, name := operation.GetPVDetails()
+	for _, pvc := range t.Owner.Spec.PersistentVolumeClaims {
+		if pvc.Namespace == ns && pvc.Name == name && pvc.TargetNamespace != "" {
+			return path.Join(pvc.TargetNamespace, name)
+		}
+	}
+	return path.Join(ns, name)
+}
+
+// reportPVCFailureReasons reads DVMP CRs of all failed Rsync operations and
+// reports the failure reason of each PVC in a condition, the PVCs which
+// destination volume is full are reported in a separate condition
+// returns a list of per-PVC failure reasons
+func (t *Task) reportPVCFailureReasons(status rsyncClientOperationStatusList) ([]string, error) {
+	reasons := make([]string, 0)
+	full := make([]string, 0)
+	for _, op := range status.ops {
+		if !op.failed || op.operation == nil {
+			continue
+		}
+		ns, name := op.operation.GetPVDetails()
+		dvmp := migapi.DirectVolumeMigrationProgress{}
+		err := t.Client.Get(context.TODO(), types.NamespacedName{
+			Name:      getMD5Hash(t.Owner.Name + name + ns),
+			Namespace: migapi.OpenshiftMigrationNamespace,
+		}, &dvmp)
+		if err != nil && !k8serror.IsNotFound(err) {
+			return reasons, liberr.Wrap(err)
+		}
+		reason := getRsyncFailureReason(&dvmp.Status.RsyncPodStatus)
+		if op.attachError != "" {
+			reason = op.attachError
+		}
+		reasons = append(reasons, fmt.Sprintf("PVC %s: %s", op.operation.String(), reason))
+		if isDestinationVolumeFull(&dvmp.Status.RsyncPodStatus) {
+			full = append(full, t.getDestinationPVC(op.operation))
+		}
+	}
+	if len(full) > 0 {
+		t.Log.Info("Rsync failed writing to full destination volumes", "persistentVolumeClaims", full)
+		t.Owner.Status.SetCondition(migapi.Condition{
+			Type:     DestinationVolumeFull,
+			Status:   True,
+			Reason:   NoSpaceLeft,
+			Category: Critical,
+			Message:  DestinationVolumeFullMessage,
+			Items:    full,
+			Durable:  true,
+		})
+	}
+	if len(reasons) > 0 {
+		t.Owner.Status.SetCondition(migapi.Condition{
+			Type:     FailedRsyncOperations,
+			Status:   True,
+			Reason:   Failed,
+			Category: Warn,
+			Message:  FailedRsyncOperationsMessage,
+			Items:    reasons,
+			Durable:  true,
+		})
+	}
+	return reasons, nil
+}
+
 // rsyncClientOperationStatus defines status of one Rsync operation
 type rsyncClientOperationStatus struct {
 	operation *migapi.RsyncOperation
@@ -2129,6 +3208,10 @@ type rsyncClientOperationStatus struct {
 	pending bool
 	// When set, means that the operation is waiting for pod to finish, will retry in next reconcile
 	running bool
+	// When set, means that the operation succeeded with an rsync exit code treated as a warning
+	warning string
+	// When set, means that the operation failed because the source volume cannot be attached or mounted
+	attachError string
 	// List of errors encountered when reconciling one operation
 	errors []error
 }
@@ -2237,6 +3320,15 @@ func (t *Task) ensureRsyncOperations(client compat.Client, podRequirements []rsy
 	garbageCollectionErrors := []error{}
 	waitGroup := &sync.WaitGroup{}
 	mutex := &sync.Mutex{}
+	if settings.Settings.DvmOpts.MaxTransfersPerNode > 0 {
+		nodes := []string{}
+		for _, req := range podRequirements {
+			nodes = append(nodes, getRsyncClientPodNode(req.nodeName, req.nodeAffinity))
+		}
+		unlock := lockNodeLeases(nodes)
+		defer unlock()
+	}
+	scheduled := t.getScheduledRsyncOperations(client, podRequirements)
 	for i := range podRequirements {
 		req := &podRequirements[i]
 		lastObservedOperationStatus := t.Owner.Status.GetRsyncOperationStatusForPVC(&corev1.ObjectReference{
@@ -2252,6 +3344,15 @@ func (t *Task) ensureRsyncOperations(client compat.Client, podRequirements []rsy
 			})
 			continue
 		}
+		// if the maximum number of concurrent transfers is reached, or the node has no transfer lease left, wait for a transfer to complete
+		if !scheduled[i] {
+			t.Log.V(4).Info("Rsync operation is waiting for a concurrent transfer to complete", "pvc", lastObservedOperationStatus)
+			statusList.Add(rsyncClientOperationStatus{
+				pending:   true,
+				operation: lastObservedOperationStatus,
+			})
+			continue
+		}
 		// from this point onwards, do not mutate the original reference, create a copy and use it
 		threadSafeOperationStatus := *lastObservedOperationStatus.DeepCopy()
 		t.garbageCollectPodsForRequirements(
@@ -2366,11 +3467,75 @@ func (t *Task) reconcileRsyncOperationState(client compat.Client, req *rsyncClie
 		if pod != nil {
 			operation.CurrentAttempt, _ = strconv.Atoi(pod.Labels[RsyncAttemptLabel])
 			currentStatus.failed, currentStatus.succeeded, currentStatus.running, currentStatus.pending = t.analyzeRsyncPodStatus(pod)
+			// classify the failure by the rsync exit code
+			// attempts terminated by Kubernetes past their active deadline are always retried
+			outcome := RsyncExitCodeRetry
+			if currentStatus.failed && pod.Status.Reason == PodDeadlineExceededReason {
+				t.Log.Info("Rsync attempt exceeded its active deadline and was terminated",
+					"pod", path.Join(pod.Namespace, pod.Name), "pvc", operation)
+			} else if currentStatus.failed {
+				exitCode := getRsyncContainerExitCode(pod)
+				outcome = getRsyncExitCodeOutcome(exitCode)
+				vanished := exitCode != nil && *exitCode == RsyncExitCodeVanishedFiles
+				if vanished {
+					outcome = t.getVanishedFilesOutcome(outcome)
+					// counting is best effort, the outcome doesn't depend on it
+					count, err := t.countVanishedFiles(pod)
+					if err != nil {
+						t.Log.Info("Failed to count the source files which vanished during the Rsync attempt",
+							"pod", path.Join(pod.Namespace, pod.Name), "error", err.Error())
+					}
+					operation.VanishedFiles = count
+				}
+				switch outcome {
+				case RsyncExitCodeIgnore:
+					currentStatus.failed, currentStatus.succeeded = false, true
+					t.Log.Info("Rsync attempt exited with a code treated as success",
+						"pvc", operation, "exitCode", *exitCode)
+				case RsyncExitCodeWarn:
+					currentStatus.failed, currentStatus.succeeded = false, true
+					currentStatus.warning = fmt.Sprintf("PVC %s: rsync exited with code %d", operation.String(), *exitCode)
+					if vanished {
+						currentStatus.warning = fmt.Sprintf("PVC %s: %d source file(s) vanished during the transfer",
+							operation.String(), operation.VanishedFiles)
+					}
+					t.Log.Info("Rsync attempt exited with a code treated as success with warning",
+						"pvc", operation, "exitCode", *exitCode)
+				case RsyncExitCodeFail:
+					t.Log.Info("Rsync attempt exited with a code treated as permanent failure, not retrying",
+						"pvc", operation, "exitCode", *exitCode)
+				}
+			}
+			// a source volume which cannot be attached or mounted is never transferred
+			if currentStatus.pending {
+				_, pvcName := operation.GetPVDetails()
+				attachError, err := t.getSourceVolumeAttachError(client, pod, pvcName)
+				if err != nil {
+					currentStatus.AddError(err)
+				} else if attachError != "" {
+					currentStatus.pending, currentStatus.failed = false, true
+					currentStatus.attachError = attachError
+					outcome = RsyncExitCodeFail
+					t.Log.Info("Source volume of Rsync Pod cannot be attached or mounted, not retrying",
+						"pod", path.Join(pod.Namespace, pod.Name), "pvc", operation, "error", attachError)
+				}
+			}
+			// the failures within the grace window don't count against the backoff limit,
+			// attempts terminated past their active deadline always count
+			tolerated := false
+			if currentStatus.failed && outcome == RsyncExitCodeRetry && pod.Status.Reason != PodDeadlineExceededReason {
+				tolerated = t.isRsyncFailureTolerated(&operation)
+			}
 			// when pod failed and backoff limit is not reached, create a new pod
-			if currentStatus.failed && operation.CurrentAttempt < GetRsyncPodBackOffLimit(*t.Owner) {
+			if currentStatus.failed && outcome == RsyncExitCodeRetry &&
+				(tolerated || getCountedRsyncAttempts(operation) < GetRsyncPodBackOffLimit(*t.Owner)) {
 				err := t.createNewPodForOperation(client, req, operation)
 				if err != nil {
 					currentStatus.AddError(err)
+				} else if tolerated {
+					operation.ToleratedAttempts += 1
+					t.Log.Info("Rsync attempt failed within the error grace window, not counting it against the backoff limit",
+						"pvc", operation, "firstFailure", operation.FirstFailureTimestamp.UTC().Format(time.RFC3339))
 				}
 				// increment current attempt
 				operation.CurrentAttempt += 1
@@ -2380,6 +3545,19 @@ func (t *Task) reconcileRsyncOperationState(client compat.Client, req *rsyncClie
 			} else {
 				operation.Failed = currentStatus.failed
 				operation.Succeeded = currentStatus.succeeded
+				operation.Unchanged = operation.Succeeded && isRsyncTransferUnchanged(pod)
+				if operation.IsComplete() && operation.RsyncProtocol == nil {
+					// informational, the outcome doesn't depend on it
+					protocol, err := t.getRsyncProtocol(pod)
+					if err != nil {
+						t.Log.Info("Failed to read the Rsync protocol version negotiated by the Rsync attempt",
+							"pod", path.Join(pod.Namespace, pod.Name), "error", err.Error())
+					}
+					operation.RsyncProtocol = protocol
+				}
+				if operation.Succeeded && operation.CompletionTimestamp == nil {
+					operation.CompletionTimestamp = &metav1.Time{Time: time.Now()}
+				}
 				if operation.IsComplete() {
 					t.Log.Info(
 						fmt.Sprintf("Rsync operation completed after %d attempts", operation.CurrentAttempt),
@@ -2462,7 +3640,8 @@ func (t *Task) createNewPodForOperation(client compat.Client, req *rsyncClientPo
 	nextAttempt := operation.CurrentAttempt + 1
 	existingLabels := podTemplate.Labels
 	attemptLabel := map[string]string{
-		RsyncAttemptLabel: fmt.Sprintf("%d", nextAttempt)}
+		RsyncAttemptLabel:            fmt.Sprintf("%d", nextAttempt),
+		RsyncTransferGenerationLabel: string(t.Owner.UID)}
 	podTemplate.Labels = Union(existingLabels, attemptLabel)
 	if len(podTemplate.Spec.Containers) > 0 {
 		t.Log.Info(
@@ -3,8 +3,13 @@ package directvolumemigration
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"reflect"
 	"strings"
+	"syscall"
 	"testing"
 	"time"
 
@@ -13,6 +18,7 @@ import (
 	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
 	"github.com/konveyor/mig-controller/pkg/compat"
 	fakecompat "github.com/konveyor/mig-controller/pkg/compat/fake"
+	"github.com/konveyor/mig-controller/pkg/settings"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -1013,9 +1019,23 @@ func TestTask_ensureRsyncOperations(t *testing.T) {
 					Name:      s.PVCReference.Name,
 					Namespace: s.PVCReference.Namespace,
 				})
-				if !reflect.DeepEqual(*got, *s) {
+				// the times the operation first failed and succeeded are recorded when reconciled
+				observed := *got
+				if s.FirstFailureTimestamp == nil && observed.FirstFailureTimestamp != nil {
+					if observed.FirstFailureTimestamp.After(time.Now()) {
+						t.Errorf("RsyncOperationsContext.EnsureRsyncOperations() first failure observed in the future: %v", observed.FirstFailureTimestamp)
+					}
+					observed.FirstFailureTimestamp = nil
+				}
+				if s.CompletionTimestamp == nil && observed.CompletionTimestamp != nil {
+					if !observed.Succeeded || observed.CompletionTimestamp.After(time.Now()) {
+						t.Errorf("RsyncOperationsContext.EnsureRsyncOperations() unexpected completion time: %v", observed.CompletionTimestamp)
+					}
+					observed.CompletionTimestamp = nil
+				}
+				if !reflect.DeepEqual(observed, *s) {
 					t.Errorf("RsyncOperationsContext.EnsureRsyncOperations() expected operation status doesnt match actual, want %v got %v",
-						*s, *got)
+						*s, observed)
 				}
 			}
 			podExistsInSource := func(pod *corev1.Pod) bool {
@@ -1127,6 +1147,46 @@ func TestTask_processRsyncOperationStatus(t *testing.T) {
 			wantCondition:     &migapi.Condition{Type: FailedCreatingRsyncPods, Status: True, Category: Warn},
 			dontWantCondition: nil,
 		},
+		{
+			name: "when an operation has failed, per PVC failure reasons should be reported",
+			fields: fields{
+				Log: log.WithName("test-logger"),
+				Client: fake.NewFakeClient(&migapi.DirectVolumeMigrationProgress{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      getMD5Hash("test-dvm" + "pvc-1" + "ns-1"),
+						Namespace: migapi.OpenshiftMigrationNamespace,
+					},
+					Status: migapi.DirectVolumeMigrationProgressStatus{
+						RsyncPodStatus: migapi.RsyncPodStatus{
+							PodPhase:   corev1.PodFailed,
+							ExitCode:   func(i int32) *int32 { return &i }(23),
+							LogMessage: "rsync: mkstemp failed: Permission denied (13)",
+						},
+					},
+				}),
+				Owner: &migapi.DirectVolumeMigration{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-dvm", Namespace: "openshift-migration",
+					},
+					Spec: migapi.DirectVolumeMigrationSpec{
+						PersistentVolumeClaims: []migapi.PVCToMigrate{
+							{ObjectReference: &corev1.ObjectReference{Name: "pvc-1", Namespace: "ns-1"}},
+						},
+					},
+				},
+			},
+			args: args{
+				status: rsyncClientOperationStatusList{
+					ops: []rsyncClientOperationStatus{
+						{failed: true, operation: getTestRsyncOperationStatus("pvc-1", "ns-1", 2, false, true)},
+					},
+				},
+			},
+			wantAllCompleted:  true,
+			wantAnyFailed:     true,
+			wantCondition:     &migapi.Condition{Type: FailedRsyncOperations, Status: True, Category: Warn},
+			dontWantCondition: nil,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -1155,3 +1215,811 @@ func TestTask_processRsyncOperationStatus(t *testing.T) {
 		})
 	}
 }
+
+func Test_getRsyncFailureReason(t *testing.T) {
+	exitCode := func(i int32) *int32 { return &i }
+	tests := []struct {
+		name      string
+		podStatus *migapi.RsyncPodStatus
+		want      string
+	}{
+		{
+			name:      "when exit code is not reported, should return generic reason",
+			podStatus: &migapi.RsyncPodStatus{},
+			want:      "Rsync Pod failed without reporting an exit code",
+		},
+		{
+			name:      "when logs contain a known error, should return known reason",
+			podStatus: &migapi.RsyncPodStatus{ExitCode: exitCode(11), LogMessage: "rsync: write failed: No space left on device (28)"},
+			want:      "no space left on destination volume (exit code 11)",
+		},
+		{
+			name:      "when logs contain an unknown error, should return exit code",
+			podStatus: &migapi.RsyncPodStatus{ExitCode: exitCode(12), LogMessage: "rsync error: error in rsync protocol data stream"},
+			want:      "rsync exited with code 12",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getRsyncFailureReason(tt.podStatus); got != tt.want {
+				t.Errorf("getRsyncFailureReason() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_isDestinationVolumeFull(t *testing.T) {
+	exitCode := func(i int32) *int32 { return &i }
+	tests := []struct {
+		name      string
+		podStatus *migapi.RsyncPodStatus
+		want      bool
+	}{
+		{
+			name:      "when exit code is not reported, should not be full",
+			podStatus: &migapi.RsyncPodStatus{LogMessage: "No space left on device"},
+			want:      false,
+		},
+		{
+			name:      "when logs report no space left, should be full",
+			podStatus: &migapi.RsyncPodStatus{ExitCode: exitCode(11), LogMessage: "rsync: write failed on \"/data/db\": No space left on device (28)"},
+			want:      true,
+		},
+		{
+			name:      "when logs report an exceeded quota, should be full",
+			podStatus: &migapi.RsyncPodStatus{ExitCode: exitCode(23), LogMessage: "rsync: close failed on \"/data/db\": Disk quota exceeded (122)"},
+			want:      true,
+		},
+		{
+			name:      "when logs report another error, should not be full",
+			podStatus: &migapi.RsyncPodStatus{ExitCode: exitCode(23), LogMessage: "rsync: send_files failed to open \"/data/db\": Permission denied (13)"},
+			want:      false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isDestinationVolumeFull(tt.podStatus); got != tt.want {
+				t.Errorf("isDestinationVolumeFull() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_getRsyncClientPodTemplateSourceReadOnly(t *testing.T) {
+	tests := []struct {
+		name           string
+		nodeName       string
+		sourceReadOnly bool
+		wantMountRO    bool
+		wantVolumeRO   bool
+	}{
+		{
+			name:           "when source is read-only and PVC is not in use, both mount and volume should be read-only",
+			nodeName:       "",
+			sourceReadOnly: true,
+			wantMountRO:    true,
+			wantVolumeRO:   true,
+		},
+		{
+			name:           "when source is read-only and PVC is in use on a node, only the mount should be read-only",
+			nodeName:       "node1.migration.internal",
+			sourceReadOnly: true,
+			wantMountRO:    true,
+			wantVolumeRO:   false,
+		},
+		{
+			name:           "when source is not read-only, neither mount nor volume should be read-only",
+			nodeName:       "",
+			sourceReadOnly: false,
+			wantMountRO:    false,
+			wantVolumeRO:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := getRsyncClientPodRequirements("pvc-1", "ns-1")
+			req.pvInfo.pvcHash = getMD5Hash("pvc-1")
+			req.nodeName = tt.nodeName
+			req.sourceReadOnly = tt.sourceReadOnly
+			pod := req.getRsyncClientPodTemplate()
+			for _, mount := range pod.Spec.Containers[0].VolumeMounts {
+				if mount.Name == req.pvInfo.pvcHash && mount.ReadOnly != tt.wantMountRO {
+					t.Errorf("getRsyncClientPodTemplate() mount readOnly = %v, want %v", mount.ReadOnly, tt.wantMountRO)
+				}
+			}
+			for _, vol := range pod.Spec.Volumes {
+				if vol.Name == req.pvInfo.pvcHash && vol.PersistentVolumeClaim.ReadOnly != tt.wantVolumeRO {
+					t.Errorf("getRsyncClientPodTemplate() volume readOnly = %v, want %v", vol.PersistentVolumeClaim.ReadOnly, tt.wantVolumeRO)
+				}
+			}
+		})
+	}
+}
+
+func Test_getRsyncClientPodTemplateShards(t *testing.T) {
+	tests := []struct {
+		name        string
+		shards      int
+		wantSharded bool
+	}{
+		{
+			name:        "when shards are not set, should run a single Rsync process",
+			shards:      0,
+			wantSharded: false,
+		},
+		{
+			name:        "when a single shard is set, should run a single Rsync process",
+			shards:      1,
+			wantSharded: false,
+		},
+		{
+			name:        "when multiple shards are set, should run one Rsync process per shard and a final pass",
+			shards:      4,
+			wantSharded: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := getRsyncClientPodRequirements("pvc-1", "ns-1")
+			req.pvInfo.pvcHash = getMD5Hash("pvc-1")
+			req.pvInfo.shards = tt.shards
+			pod := req.getRsyncClientPodTemplate()
+			script := pod.Spec.Containers[0].Command[2]
+			if got := strings.Contains(script, "--files-from="); got != tt.wantSharded {
+				t.Errorf("getRsyncClientPodTemplate() sharded = %v, want %v", got, tt.wantSharded)
+			}
+			if tt.wantSharded && !strings.Contains(script, fmt.Sprintf("$(seq 0 %d)", tt.shards-1)) {
+				t.Errorf("getRsyncClientPodTemplate() script doesn't run %d shards: %s", tt.shards, script)
+			}
+			if !strings.Contains(script, fmt.Sprintf("/mnt/ns-1/%s/ rsync://root@", req.pvInfo.pvcHash)) {
+				t.Errorf("getRsyncClientPodTemplate() script doesn't transfer the whole PVC: %s", script)
+			}
+		})
+	}
+}
+
+func TestTask_getRsyncOptions_defaults(t *testing.T) {
+	for _, key := range []string{
+		settings.RsyncOptBwLimit, settings.RsyncOptArchive, settings.RsyncOptPartial,
+		settings.RsyncOptDelete, settings.RsyncOptHardLinks, settings.RsyncOptAcls,
+		settings.RsyncOptXattrs, settings.RsyncOptSELinux, settings.RsyncOptInfo,
+		settings.RsyncOptExtras,
+	} {
+		if value, found := os.LookupEnv(key); found {
+			defer os.Setenv(key, value)
+			os.Unsetenv(key)
+		}
+	}
+	defer func() {
+		settings.Settings.DvmOpts.RsyncOpts = settings.RsyncOpts{}
+	}()
+	if err := settings.Settings.DvmOpts.RsyncOpts.Load(); err != nil {
+		t.Fatalf("RsyncOpts.Load() error = %v", err)
+	}
+	task := &Task{
+		Log:   log.WithName("test-logger"),
+		Owner: &migapi.DirectVolumeMigration{},
+	}
+	want := []string{
+		"--archive", "--delete", "--recursive", "--hard-links", "--partial",
+		"--acls", "--xattrs", RsyncXattrsSELinuxFilter,
+		"--info=COPY2,DEL2,REMOVE2,SKIP2,FLIST2,PROGRESS2,STATS2",
+		"--human-readable", "--port", "2222", "--log-file", "/dev/stdout",
+	}
+	if got := task.getRsyncOptions(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Task.getRsyncOptions() = %v, want %v", got, want)
+	}
+}
+
+func TestTask_getRsyncOptions(t *testing.T) {
+	defaultOpts := []string{
+		"--info=COPY2,DEL2,REMOVE2,SKIP2,FLIST2,PROGRESS2,STATS2",
+		"--human-readable", "--port", "2222", "--log-file", "/dev/stdout",
+	}
+	uid, gid, fsGroup := int64(1000), int64(2000), int64(3000)
+	modifyWindow := 2
+	tests := []struct {
+		name      string
+		rsyncOpts settings.RsyncOpts
+		spec      migapi.DirectVolumeMigrationSpec
+		want      []string
+	}{
+		{
+			name:      "when no options are set, should return default options only",
+			rsyncOpts: settings.RsyncOpts{BwLimit: -1},
+			want:      defaultOpts,
+		},
+		{
+			name:      "when acls and xattrs are enabled, should preserve acls and extended attributes",
+			rsyncOpts: settings.RsyncOpts{BwLimit: -1, Archive: true, Acls: true, Xattrs: true},
+			want:      append([]string{"--archive", "--acls", "--xattrs", RsyncXattrsSELinuxFilter}, defaultOpts...),
+		},
+		{
+			name:      "when only xattrs is enabled, should not preserve acls",
+			rsyncOpts: settings.RsyncOpts{BwLimit: -1, Xattrs: true},
+			want:      append([]string{"--xattrs", RsyncXattrsSELinuxFilter}, defaultOpts...),
+		},
+		{
+			name:      "when xattrs is enabled with selinux labels, should preserve selinux labels",
+			rsyncOpts: settings.RsyncOpts{BwLimit: -1, Archive: true, Xattrs: true, XattrsSELinux: true},
+			want:      append([]string{"--archive", "--xattrs"}, defaultOpts...),
+		},
+		{
+			name:      "when only selinux labels are requested, should not preserve extended attributes",
+			rsyncOpts: settings.RsyncOpts{BwLimit: -1, XattrsSELinux: true},
+			want:      defaultOpts,
+		},
+		{
+			name:      "when only acls is enabled, should not preserve extended attributes",
+			rsyncOpts: settings.RsyncOpts{BwLimit: -1, Acls: true},
+			want:      append([]string{"--acls"}, defaultOpts...),
+		},
+		{
+			name:      "when rsync UID is set, should chown files to UID and GID equal to UID",
+			rsyncOpts: settings.RsyncOpts{BwLimit: -1},
+			spec:      migapi.DirectVolumeMigrationSpec{RsyncUID: &uid},
+			want:      append([]string{"--chown=1000:1000"}, defaultOpts...),
+		},
+		{
+			name:      "when rsync UID and GID are set, should chown files to UID and GID",
+			rsyncOpts: settings.RsyncOpts{BwLimit: -1},
+			spec:      migapi.DirectVolumeMigrationSpec{RsyncUID: &uid, RsyncGID: &gid},
+			want:      append([]string{"--chown=1000:2000"}, defaultOpts...),
+		},
+		{
+			name:      "when only rsync GID is set, should chown files to GID only",
+			rsyncOpts: settings.RsyncOpts{BwLimit: -1},
+			spec:      migapi.DirectVolumeMigrationSpec{RsyncGID: &gid},
+			want:      append([]string{"--chown=:2000"}, defaultOpts...),
+		},
+		{
+			name:      "when destination fsGroup is set, should chown files to the fsGroup and make them group writable",
+			rsyncOpts: settings.RsyncOpts{BwLimit: -1},
+			spec:      migapi.DirectVolumeMigrationSpec{DestinationFSGroup: &fsGroup},
+			want:      append([]string{"--chown=:3000", RsyncFSGroupChmod}, defaultOpts...),
+		},
+		{
+			name:      "when destination fsGroup and rsync UID are set, should chown files to UID and fsGroup",
+			rsyncOpts: settings.RsyncOpts{BwLimit: -1},
+			spec:      migapi.DirectVolumeMigrationSpec{RsyncUID: &uid, DestinationFSGroup: &fsGroup},
+			want:      append([]string{"--chown=1000:3000", RsyncFSGroupChmod}, defaultOpts...),
+		},
+		{
+			name:      "when destination fsGroup and rsync GID are set, should chown files to GID",
+			rsyncOpts: settings.RsyncOpts{BwLimit: -1},
+			spec:      migapi.DirectVolumeMigrationSpec{RsyncGID: &gid, DestinationFSGroup: &fsGroup},
+			want:      append([]string{"--chown=:2000", RsyncFSGroupChmod}, defaultOpts...),
+		},
+		{
+			name:      "when unsafe links are copied, should copy the files they point to",
+			rsyncOpts: settings.RsyncOpts{BwLimit: -1},
+			spec:      migapi.DirectVolumeMigrationSpec{UnsafeLinks: migapi.UnsafeLinksCopy},
+			want:      append([]string{"--copy-unsafe-links"}, defaultOpts...),
+		},
+		{
+			name:      "when unsafe links are kept, should not pass any option",
+			rsyncOpts: settings.RsyncOpts{BwLimit: -1},
+			spec:      migapi.DirectVolumeMigrationSpec{UnsafeLinks: migapi.UnsafeLinksKeep},
+			want:      defaultOpts,
+		},
+		{
+			name:      "when unsafe links are dropped, should skip them",
+			rsyncOpts: settings.RsyncOpts{BwLimit: -1},
+			spec:      migapi.DirectVolumeMigrationSpec{UnsafeLinks: migapi.UnsafeLinksDrop},
+			want:      append([]string{"--safe-links"}, defaultOpts...),
+		},
+		{
+			name:      "when the modify window is set, should tolerate the difference of modification times",
+			rsyncOpts: settings.RsyncOpts{BwLimit: -1},
+			spec:      migapi.DirectVolumeMigrationSpec{RsyncModifyWindow: &modifyWindow},
+			want:      append([]string{"--modify-window=2"}, defaultOpts...),
+		},
+		{
+			name:      "when empty directories are preserved, should ignore the extra options pruning them",
+			rsyncOpts: settings.RsyncOpts{BwLimit: -1, Archive: true, Extras: []string{"--prune-empty-dirs", "-m", "--numeric-ids"}},
+			want:      append(append([]string{"--archive"}, defaultOpts...), "--numeric-ids"),
+		},
+		{
+			name:      "when empty directories are pruned, should skip them",
+			rsyncOpts: settings.RsyncOpts{BwLimit: -1, Archive: true, Extras: []string{"--prune-empty-dirs"}},
+			spec:      migapi.DirectVolumeMigrationSpec{PruneEmptyDirs: true},
+			want:      append(append([]string{"--archive", "--prune-empty-dirs"}, defaultOpts...), "--prune-empty-dirs"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &Task{
+				Log:   log.WithName("test-logger"),
+				Owner: &migapi.DirectVolumeMigration{Spec: tt.spec},
+			}
+			settings.Settings.DvmOpts.RsyncOpts = tt.rsyncOpts
+			defer func() {
+				settings.Settings.DvmOpts.RsyncOpts = settings.RsyncOpts{}
+			}()
+			if got := task.getRsyncOptions(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Task.getRsyncOptions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_isValidRsyncSize(t *testing.T) {
+	tests := []struct {
+		size string
+		want bool
+	}{
+		{size: "100", want: true},
+		{size: "500K", want: true},
+		{size: "1.5G", want: true},
+		{size: "2GiB", want: true},
+		{size: "10mb", want: true},
+		{size: "1M+1", want: true},
+		{size: "1M-1", want: true},
+		{size: "", want: false},
+		{size: "-1M", want: false},
+		{size: "1X", want: false},
+		{size: "1.G", want: false},
+		{size: "1G --delete", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.size, func(t *testing.T) {
+			if got := isValidRsyncSize(tt.size); got != tt.want {
+				t.Errorf("isValidRsyncSize(%q) = %v, want %v", tt.size, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_getRsyncSparseOptions(t *testing.T) {
+	tests := []struct {
+		sparse      string
+		wantMode    string
+		wantOptions []string
+	}{
+		{sparse: "", wantMode: migapi.SparseAuto, wantOptions: []string{}},
+		{sparse: migapi.SparseAuto, wantMode: migapi.SparseAuto, wantOptions: []string{}},
+		{sparse: migapi.SparseAlways, wantMode: migapi.SparseAlways, wantOptions: []string{"--sparse"}},
+		{sparse: migapi.SparseNever, wantMode: migapi.SparseNever, wantOptions: []string{"--no-sparse"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sparse, func(t *testing.T) {
+			mode, options := getRsyncSparseOptions(tt.sparse)
+			if mode != tt.wantMode || !reflect.DeepEqual(options, tt.wantOptions) {
+				t.Errorf("getRsyncSparseOptions() = %v, %v, want %v, %v", mode, options, tt.wantMode, tt.wantOptions)
+			}
+		})
+	}
+}
+
+func Test_getRsyncExitCodeOutcome(t *testing.T) {
+	exitCode := func(code int32) *int32 { return &code }
+	tests := []struct {
+		name      string
+		exitCode  *int32
+		overrides map[int]string
+		want      string
+	}{
+		{
+			name:     "when exit code is unknown, should retry",
+			exitCode: nil,
+			want:     RsyncExitCodeRetry,
+		},
+		{
+			name:     "when source files vanished, should succeed with warning",
+			exitCode: exitCode(24),
+			want:     RsyncExitCodeWarn,
+		},
+		{
+			name:     "when rsync is called with a syntax error, should fail",
+			exitCode: exitCode(1),
+			want:     RsyncExitCodeFail,
+		},
+		{
+			name:     "when socket I/O failed, should retry",
+			exitCode: exitCode(10),
+			want:     RsyncExitCodeRetry,
+		},
+		{
+			name:     "when exit code is not in the table, should retry",
+			exitCode: exitCode(137),
+			want:     RsyncExitCodeRetry,
+		},
+		{
+			name:      "when exit code is overridden, should use the override",
+			exitCode:  exitCode(24),
+			overrides: map[int]string{24: RsyncExitCodeFail, 23: RsyncExitCodeWarn},
+			want:      RsyncExitCodeFail,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings.Settings.DvmOpts.ExitCodeOutcomes = tt.overrides
+			defer func() {
+				settings.Settings.DvmOpts.ExitCodeOutcomes = nil
+			}()
+			if got := getRsyncExitCodeOutcome(tt.exitCode); got != tt.want {
+				t.Errorf("getRsyncExitCodeOutcome() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTask_getRsyncPodActiveDeadlineSeconds(t *testing.T) {
+	seconds := func(s int64) *int64 { return &s }
+	tests := []struct {
+		name   string
+		spec   migapi.DirectVolumeMigrationSpec
+		status migapi.DirectVolumeMigrationStatus
+		want   *int64
+	}{
+		{
+			name: "when no deadline is set, should not set an active deadline",
+			want: nil,
+		},
+		{
+			name: "when the active deadline is set, should use it",
+			spec: migapi.DirectVolumeMigrationSpec{
+				RsyncPodActiveDeadlineSeconds: seconds(600),
+				Deadline:                      &metav1.Duration{Duration: time.Hour},
+			},
+			status: migapi.DirectVolumeMigrationStatus{StartTimestamp: &metav1.Time{Time: time.Now()}},
+			want:   seconds(600),
+		},
+		{
+			name:   "when only the migration deadline is set, should use the time left before it",
+			spec:   migapi.DirectVolumeMigrationSpec{Deadline: &metav1.Duration{Duration: time.Hour}},
+			status: migapi.DirectVolumeMigrationStatus{StartTimestamp: &metav1.Time{Time: time.Now().Add(-30 * time.Minute)}},
+			want:   seconds(1800),
+		},
+		{
+			name:   "when the migration deadline is exceeded, should use the minimum active deadline",
+			spec:   migapi.DirectVolumeMigrationSpec{Deadline: &metav1.Duration{Duration: time.Hour}},
+			status: migapi.DirectVolumeMigrationStatus{StartTimestamp: &metav1.Time{Time: time.Now().Add(-2 * time.Hour)}},
+			want:   seconds(1),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &Task{
+				Owner: &migapi.DirectVolumeMigration{Spec: tt.spec, Status: tt.status},
+			}
+			got := task.getRsyncPodActiveDeadlineSeconds()
+			if (got == nil) != (tt.want == nil) {
+				t.Errorf("Task.getRsyncPodActiveDeadlineSeconds() = %v, want %v", got, tt.want)
+				return
+			}
+			// allow a second of drift for the time elapsed during the test
+			if got != nil && (*got > *tt.want || *got < *tt.want-1) {
+				t.Errorf("Task.getRsyncPodActiveDeadlineSeconds() = %v, want %v", *got, *tt.want)
+			}
+		})
+	}
+}
+
+// newHardLinkFixture creates a deduplicated store where each object is hard
+// linked from several backup directories, and a few files with a single link.
+func newHardLinkFixture(t *testing.T, root string) {
+	for _, dir := range []string{"store", "backup-1", "backup-2", "backup-3", "single"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 10; i++ {
+		object := filepath.Join(root, "store", fmt.Sprintf("object-%d", i))
+		if err := ioutil.WriteFile(object, []byte(strings.Repeat(fmt.Sprint(i), 1024)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		for _, backup := range []string{"backup-1", "backup-2", "backup-3"} {
+			if err := os.Link(object, filepath.Join(root, backup, fmt.Sprintf("object-%d", i))); err != nil {
+				t.Fatal(err)
+			}
+		}
+		single := filepath.Join(root, "single", fmt.Sprintf("file-%d", i))
+		if err := ioutil.WriteFile(single, []byte(fmt.Sprint(i)), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func statLinks(t *testing.T, path string) (uint64, uint64) {
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stat := info.Sys().(*syscall.Stat_t)
+	return uint64(stat.Ino), uint64(stat.Nlink)
+}
+
+func Test_getShardedRsyncCommandHardLinks(t *testing.T) {
+	for _, bin := range []string{"bash", "find"} {
+		if _, err := exec.LookPath(bin); err != nil {
+			t.Skipf("%s not found", bin)
+		}
+	}
+	dir, err := ioutil.TempDir("", "dvm-hard-links")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	source, destination, shardDir := filepath.Join(dir, "src")+"/", filepath.Join(dir, "dest")+"/", filepath.Join(dir, "shards")
+	for _, d := range []string{source, destination, shardDir} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	newHardLinkFixture(t, source)
+
+	// the shards must not list any hard-linked file
+	split := getRsyncShardSplitCommand(source, shardDir, 3, true)
+	if out, err := exec.Command("bash", "-c", split).CombinedOutput(); err != nil {
+		t.Fatalf("split command failed: %v: %s", err, out)
+	}
+	listed := map[string]int{}
+	shardFiles, _ := filepath.Glob(filepath.Join(shardDir, "shard-*"))
+	for _, shardFile := range shardFiles {
+		content, err := ioutil.ReadFile(shardFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, entry := range strings.Split(string(content), "\x00") {
+			if entry != "" {
+				listed[entry]++
+			}
+		}
+	}
+	for entry, count := range listed {
+		if _, links := statLinks(t, filepath.Join(source, entry)); links > 1 {
+			t.Errorf("shards list the hard-linked file %s", entry)
+		}
+		if count > 1 {
+			t.Errorf("shards list the file %s %d times", entry, count)
+		}
+	}
+	if len(listed) != 10 {
+		t.Errorf("shards list %d files, want the 10 files with a single link", len(listed))
+	}
+
+	// the link counts must be preserved on the destination
+	if _, err := exec.LookPath("rsync"); err != nil {
+		t.Skip("rsync not found")
+	}
+	os.RemoveAll(shardDir)
+	os.MkdirAll(shardDir, 0755)
+	command := getShardedRsyncCommand([]string{"--archive", "--hard-links"}, source, destination, 3, shardDir)
+	if out, err := exec.Command("bash", "-c", command).CombinedOutput(); err != nil {
+		t.Fatalf("sharded rsync command failed: %v: %s", err, out)
+	}
+	for i := 0; i < 10; i++ {
+		storeInode, storeLinks := statLinks(t, filepath.Join(destination, "store", fmt.Sprintf("object-%d", i)))
+		if storeLinks != 4 {
+			t.Errorf("object-%d has %d links on the destination, want 4", i, storeLinks)
+		}
+		for _, backup := range []string{"backup-1", "backup-2", "backup-3"} {
+			if inode, _ := statLinks(t, filepath.Join(destination, backup, fmt.Sprintf("object-%d", i))); inode != storeInode {
+				t.Errorf("%s/object-%d is not linked to the store on the destination", backup, i)
+			}
+		}
+	}
+}
+
+func Test_isStaleRsyncResource(t *testing.T) {
+	active := map[string]bool{"running-dvm": true, "1b4e28ba-2fa1-11d2-883f-0016d3cca427": true}
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   bool
+	}{
+		{
+			name:   "when resource isn't an Rsync transfer resource, should not be stale",
+			labels: map[string]string{"app": "user-app", RsyncTransferGenerationLabel: "failed-dvm"},
+			want:   false,
+		},
+		{
+			name:   "when resource belongs to the current generation, should not be stale",
+			labels: map[string]string{"app": DirectVolumeMigrationRsyncTransfer, RsyncTransferGenerationLabel: "current-dvm"},
+			want:   false,
+		},
+		{
+			name:   "when resource belongs to a running DVM, should not be stale",
+			labels: map[string]string{"app": DirectVolumeMigrationRsyncTransfer, RsyncTransferGenerationLabel: "running-dvm"},
+			want:   false,
+		},
+		{
+			name:   "when resource belongs to a prior failed DVM, should be stale",
+			labels: map[string]string{"app": DirectVolumeMigrationRsyncTransfer, RsyncTransferGenerationLabel: "failed-dvm"},
+			want:   true,
+		},
+		{
+			name:   "when resource has no transfer generation, should not be stale",
+			labels: map[string]string{"app": DirectVolumeMigrationRsyncTransfer},
+			want:   false,
+		},
+		{
+			name:   "when resource has no transfer generation and is correlated to a prior failed DVM, should be stale",
+			labels: map[string]string{"app": DirectVolumeMigrationRsyncTransfer, "directvolumemigration": "6ba7b810-9dad-11d1-80b4-00c04fd430c8"},
+			want:   true,
+		},
+		{
+			name:   "when resource has no transfer generation and is correlated to a running DVM, should not be stale",
+			labels: map[string]string{"app": DirectVolumeMigrationRsyncTransfer, "directvolumemigration": "1b4e28ba-2fa1-11d2-883f-0016d3cca427"},
+			want:   false,
+		},
+		{
+			name:   "when Rsync client Pod has no transfer generation, should not be stale",
+			labels: map[string]string{"app": DirectVolumeMigrationRsyncTransfer, "directvolumemigration": DirectVolumeMigrationRsyncClient},
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isStaleRsyncResource(tt.labels, "current-dvm", active); got != tt.want {
+				t.Errorf("isStaleRsyncResource() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_isValidRsyncTempDir(t *testing.T) {
+	tests := []struct {
+		tempDir string
+		want    bool
+	}{
+		{tempDir: ".rsync-tmp", want: true},
+		{tempDir: "scratch/rsync", want: true},
+		{tempDir: "/tmp", want: false},
+		{tempDir: "../tmp", want: false},
+		{tempDir: "scratch/../../tmp", want: false},
+		{tempDir: ".", want: false},
+		{tempDir: "scratch/", want: false},
+		{tempDir: "tmp --delete", want: false},
+		{tempDir: "tmp;rm", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tempDir, func(t *testing.T) {
+			if got := isValidRsyncTempDir(tt.tempDir); got != tt.want {
+				t.Errorf("isValidRsyncTempDir(%q) = %v, want %v", tt.tempDir, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_getRsyncTempDirOptions(t *testing.T) {
+	if got := getRsyncTempDirOptions(""); len(got) != 0 {
+		t.Errorf("getRsyncTempDirOptions() = %v, want no options", got)
+	}
+	want := []string{"--temp-dir=.rsync-tmp", "--exclude=/.rsync-tmp/"}
+	if got := getRsyncTempDirOptions(".rsync-tmp"); !reflect.DeepEqual(got, want) {
+		t.Errorf("getRsyncTempDirOptions() = %v, want %v", got, want)
+	}
+}
+
+func TestTask_getRsyncPartialDirOptions(t *testing.T) {
+	task := &Task{Owner: &migapi.DirectVolumeMigration{}}
+	if got := task.getRsyncPartialDirOptions(); len(got) != 0 {
+		t.Errorf("getRsyncPartialDirOptions() = %v, want no options", got)
+	}
+	task.Owner.Spec.PreservePartialFiles = true
+	want := []string{"--partial-dir=.dvm-partial"}
+	if got := task.getRsyncPartialDirOptions(); !reflect.DeepEqual(got, want) {
+		t.Errorf("getRsyncPartialDirOptions() = %v, want %v", got, want)
+	}
+}
+
+func TestTask_setTunnelEndpointsUnreachable(t *testing.T) {
+	task := &Task{
+		Log:   log.WithName("test-logger"),
+		Owner: &migapi.DirectVolumeMigration{},
+	}
+	task.setTunnelEndpointsUnreachable([]string{"ns-1: tunnel:2222: connection refused"})
+	condition := task.Owner.Status.FindCondition(TunnelEndpointsUnreachable)
+	if condition == nil || condition.Category != Warn || !condition.Durable || len(condition.Items) != 1 {
+		t.Fatalf("Task.setTunnelEndpointsUnreachable() condition = %v, want a durable warning listing the tunnel", condition)
+	}
+	if task.Owner.Status.HasBlockerCondition() {
+		t.Errorf("Task.setTunnelEndpointsUnreachable() must not block the migration")
+	}
+	task.setTunnelEndpointsUnreachable([]string{})
+	if task.Owner.Status.HasCondition(TunnelEndpointsUnreachable) {
+		t.Errorf("Task.setTunnelEndpointsUnreachable() must clear the warning once the tunnels are reachable")
+	}
+}
+
+func TestTask_setSourcePVCsAttachedReadWrite(t *testing.T) {
+	task := &Task{
+		Log:   log.WithName("test-logger"),
+		Owner: &migapi.DirectVolumeMigration{},
+	}
+	task.setSourcePVCsAttachedReadWrite([]string{})
+	if task.Owner.Status.HasCondition(SourcePVCsAttachedReadWrite) {
+		t.Errorf("Task.setSourcePVCsAttachedReadWrite() set the condition without PVCs in use")
+	}
+	task.setSourcePVCsAttachedReadWrite([]string{"ns-2/pvc-2", "ns-1/pvc-1"})
+	condition := task.Owner.Status.FindCondition(SourcePVCsAttachedReadWrite)
+	want := []string{"ns-1/pvc-1", "ns-2/pvc-2"}
+	if condition == nil || condition.Category != Warn || !reflect.DeepEqual(condition.Items, want) {
+		t.Errorf("Task.setSourcePVCsAttachedReadWrite() condition = %v, want a Warn condition with items %v", condition, want)
+	}
+}
+
+func TestTask_reportRsyncWarnings(t *testing.T) {
+	task := &Task{
+		Log:   log.WithName("test-logger"),
+		Owner: &migapi.DirectVolumeMigration{},
+	}
+	newStatus := func(name string, warning string) rsyncClientOperationStatusList {
+		status := rsyncClientOperationStatusList{}
+		status.Add(rsyncClientOperationStatus{
+			operation: &migapi.RsyncOperation{PVCReference: &corev1.ObjectReference{Namespace: "ns", Name: name}},
+			succeeded: true,
+			warning:   warning,
+		})
+		return status
+	}
+	// the warnings reported in earlier reconciles are read back from the persisted message
+	reconcile := func(status rsyncClientOperationStatusList) {
+		task.Owner.Status.BeginStagingConditions()
+		task.reportRsyncWarnings(status)
+		task.Owner.Status.EndStagingConditions()
+	}
+	reconcile(newStatus("pvc-1", "PVC ns/pvc-1: rsync exited with code 24"))
+	reconcile(newStatus("pvc-2", ""))
+	reconcile(newStatus("pvc-3", "PVC ns/pvc-3: 2 source file(s) vanished during the transfer"))
+	condition := task.Owner.Status.FindCondition(RsyncCompletedWithWarnings)
+	want := "Rsync completed with warnings for PVCs [PVC ns/pvc-1: rsync exited with code 24,PVC ns/pvc-3: 2 source file(s) vanished during the transfer]."
+	if condition == nil || condition.Message != want {
+		t.Errorf("Task.reportRsyncWarnings() condition = %v, want message %q", condition, want)
+	}
+}
+
+func TestTask_reportPVCFailureReasons(t *testing.T) {
+	exitCode := int32(11)
+	getProgress := func(name string, logMessage string) *migapi.DirectVolumeMigrationProgress {
+		return &migapi.DirectVolumeMigrationProgress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      getMD5Hash("test" + name + "ns"),
+				Namespace: migapi.OpenshiftMigrationNamespace,
+			},
+			Status: migapi.DirectVolumeMigrationProgressStatus{
+				RsyncPodStatus: migapi.RsyncPodStatus{ExitCode: &exitCode, LogMessage: logMessage},
+			},
+		}
+	}
+	task := &Task{
+		Log: log.WithName("test-logger"),
+		Client: fake.NewFakeClient(
+			getProgress("pvc-1", "rsync: write failed on \"/data/db\": No space left on device (28)"),
+			getProgress("pvc-2", "rsync: send_files failed to open \"/data/db\": Permission denied (13)")),
+		Owner: &migapi.DirectVolumeMigration{
+			ObjectMeta: metav1.ObjectMeta{Name: "test"},
+			Spec: migapi.DirectVolumeMigrationSpec{
+				PersistentVolumeClaims: []migapi.PVCToMigrate{
+					{ObjectReference: &corev1.ObjectReference{Namespace: "ns", Name: "pvc-1"}, TargetNamespace: "dest"},
+					{ObjectReference: &corev1.ObjectReference{Namespace: "ns", Name: "pvc-2"}},
+				},
+			},
+		},
+	}
+	status := rsyncClientOperationStatusList{}
+	for _, name := range []string{"pvc-1", "pvc-2"} {
+		status.Add(rsyncClientOperationStatus{
+			operation: &migapi.RsyncOperation{PVCReference: &corev1.ObjectReference{Namespace: "ns", Name: name}},
+			failed:    true,
+		})
+	}
+	if _, err := task.reportPVCFailureReasons(status); err != nil {
+		t.Fatalf("Task.reportPVCFailureReasons() unexpected error = %v", err)
+	}
+	// the items are only persisted within the message
+	task.Owner.Status.EndStagingConditions()
+	full := task.Owner.Status.FindCondition(DestinationVolumeFull)
+	wantFull := "The destination volumes of the PVCs [dest/pvc-1] are full, increase the capacity of the destination PVCs."
+	if full == nil || full.Message != wantFull {
+		t.Errorf("Task.reportPVCFailureReasons() %s condition = %v, want message %q", DestinationVolumeFull, full, wantFull)
+	}
+	failed := task.Owner.Status.FindCondition(FailedRsyncOperations)
+	wantFailed := "Rsync failed for PVCs [PVC ns/pvc-1: no space left on destination volume (exit code 11)," +
+		"PVC ns/pvc-2: permission denied (exit code 11)]."
+	if failed == nil || failed.Message != wantFailed {
+		t.Errorf("Task.reportPVCFailureReasons() %s condition = %v, want message %q", FailedRsyncOperations, failed, wantFailed)
+	}
+}
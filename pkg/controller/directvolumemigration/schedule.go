@@ -0,0 +1,254 @@
+package directvolumemigration
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"github.com/konveyor/mig-controller/pkg/compat"
+	"github.com/konveyor/mig-controller/pkg/settings"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Locks of the transfer leases of each node, serializing the reservation of the
+// leases of a node by the DVMs reconciled concurrently. DVMs reading from
+// distinct nodes don't wait for each other.
+var nodeLeaseLocks = struct {
+	sync.Mutex
+	nodes map[string]*sync.Mutex
+}{nodes: map[string]*sync.Mutex{}}
+
+// Lock the transfer leases of the nodes, in the order of their names for DVMs
+// locking overlapping nodes not to deadlock. Unknown nodes aren't locked, their
+// transfers aren't limited. Returns the function unlocking them.
+func lockNodeLeases(nodes []string) func() {
+	names := []string{}
+	found := map[string]bool{}
+	for _, node := range nodes {
+		if node != "" && !found[node] {
+			found[node] = true
+			names = append(names, node)
+		}
+	}
+	sort.Strings(names)
+	locks := []*sync.Mutex{}
+	nodeLeaseLocks.Lock()
+	for _, name := range names {
+		lock, exists := nodeLeaseLocks.nodes[name]
+		if !exists {
+			lock = &sync.Mutex{}
+			nodeLeaseLocks.nodes[name] = lock
+		}
+		locks = append(locks, lock)
+	}
+	nodeLeaseLocks.Unlock()
+	for _, lock := range locks {
+		lock.Lock()
+	}
+	return func() {
+		for i := len(locks) - 1; i >= 0; i-- {
+			locks[i].Unlock()
+		}
+	}
+}
+
+// Get the size of the data to transfer for the Rsync operation, false when unknown.
+func getRsyncOperationSize(operation *migapi.RsyncOperation) (int64, bool) {
+	size := operation.UsedCapacity
+	if size == nil {
+		size = operation.Capacity
+	}
+	if size == nil {
+		return 0, false
+	}
+	return size.Value(), true
+}
+
+// Get whether each Rsync operation may run given the maximum number of
+// concurrent transfers. The started operations keep running, the pending ones
+// start as transfers complete:
+//   - fifo starts them in the order of the operations.
+//   - size-balanced limits the large operations, larger than the median size,
+//     to half of the concurrent transfers. The largest operation left starts
+//     while the large operations take less than half of the transfers, the
+//     smallest one otherwise. The operations of unknown size start last.
+func scheduleRsyncOperations(operations []*migapi.RsyncOperation, maxConcurrent int, strategy string) []bool {
+	scheduled := make([]bool, len(operations))
+	sizes := []int64{}
+	for _, operation := range operations {
+		if size, known := getRsyncOperationSize(operation); known {
+			sizes = append(sizes, size)
+		}
+	}
+	sort.Slice(sizes, func(i, j int) bool { return sizes[i] < sizes[j] })
+	isLarge := func(operation *migapi.RsyncOperation) bool {
+		size, known := getRsyncOperationSize(operation)
+		return known && size >= sizes[len(sizes)/2]
+	}
+	running, runningLarge := 0, 0
+	pending := []int{}
+	for i, operation := range operations {
+		if operation.IsComplete() || operation.CurrentAttempt > 0 {
+			scheduled[i] = true
+			if !operation.IsComplete() {
+				running++
+				if isLarge(operation) {
+					runningLarge++
+				}
+			}
+			continue
+		}
+		pending = append(pending, i)
+	}
+	balanced := strategy == migapi.TransferSchedulingSizeBalanced
+	if balanced {
+		// largest first, the operations of unknown size last
+		sort.SliceStable(pending, func(i, j int) bool {
+			a, aKnown := getRsyncOperationSize(operations[pending[i]])
+			b, bKnown := getRsyncOperationSize(operations[pending[j]])
+			if aKnown != bKnown {
+				return aKnown
+			}
+			return a > b
+		})
+	}
+	maxLarge := (maxConcurrent + 1) / 2
+	for running < maxConcurrent && len(pending) > 0 {
+		next := 0
+		if balanced && runningLarge >= maxLarge {
+			for j := len(pending) - 1; j >= 0; j-- {
+				if _, known := getRsyncOperationSize(operations[pending[j]]); known {
+					next = j
+					break
+				}
+			}
+		}
+		operation := operations[pending[next]]
+		scheduled[pending[next]] = true
+		pending = append(pending[:next], pending[next+1:]...)
+		running++
+		if isLarge(operation) {
+			runningLarge++
+		}
+	}
+	return scheduled
+}
+
+// Get whether the Rsync operation of each Pod requirement may run, all of them
+// run when the number of concurrent transfers isn't limited. The pending
+// operations then wait for a transfer lease on the node they read from.
+func (t *Task) getScheduledRsyncOperations(client compat.Client, podRequirements []rsyncClientPodRequirements) []bool {
+	operations := []*migapi.RsyncOperation{}
+	nodes := []string{}
+	for _, req := range podRequirements {
+		operations = append(operations, t.Owner.Status.GetRsyncOperationStatusForPVC(&corev1.ObjectReference{
+			Name:      req.pvInfo.name,
+			Namespace: req.namespace,
+		}))
+		nodes = append(nodes, getRsyncClientPodNode(req.nodeName, req.nodeAffinity))
+	}
+	scheduled := make([]bool, len(operations))
+	maxConcurrent := t.Owner.Spec.MaxConcurrentTransfers
+	if maxConcurrent == nil {
+		for i := range scheduled {
+			scheduled[i] = true
+		}
+	} else {
+		scheduled = scheduleRsyncOperations(operations, *maxConcurrent, t.Owner.Spec.TransferScheduling)
+	}
+	maxPerNode := settings.Settings.DvmOpts.MaxTransfersPerNode
+	if maxPerNode == 0 {
+		return scheduled
+	}
+	leases, err := getNodeTransferLeases(client)
+	if err != nil {
+		t.Log.Info("Cannot list the Rsync client Pods holding the transfer leases of the nodes, the pending transfers keep waiting.",
+			"error", err.Error())
+		leases = nil
+	}
+	return leaseRsyncOperations(operations, nodes, scheduled, leases, maxPerNode)
+}
+
+// Get the node an Rsync client Pod reads from, set by its node name or by a
+// node affinity selecting a single node. Empty when unknown.
+func getRsyncClientPodNode(nodeName string, affinity *corev1.NodeAffinity) string {
+	if nodeName != "" {
+		return nodeName
+	}
+	if affinity == nil || affinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return ""
+	}
+	terms := affinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	if len(terms) != 1 {
+		return ""
+	}
+	requirements := append([]corev1.NodeSelectorRequirement{}, terms[0].MatchFields...)
+	requirements = append(requirements, terms[0].MatchExpressions...)
+	for _, requirement := range requirements {
+		if (requirement.Key == "metadata.name" || requirement.Key == corev1.LabelHostname) &&
+			requirement.Operator == corev1.NodeSelectorOpIn && len(requirement.Values) == 1 {
+			return requirement.Values[0]
+		}
+	}
+	return ""
+}
+
+// Get the number of transfer leases held on each node of the source cluster.
+// Every Rsync client Pod, of any DVM, holds a lease on the node it reads from
+// until it completes.
+func getNodeTransferLeases(client compat.Client) (map[string]int, error) {
+	podList := corev1.PodList{}
+	err := client.List(context.TODO(), &podList, &k8sclient.ListOptions{
+		LabelSelector: labels.SelectorFromSet(map[string]string{
+			"app":                   DirectVolumeMigrationRsyncTransfer,
+			"directvolumemigration": DirectVolumeMigrationRsyncClient,
+		}),
+	})
+	if err != nil {
+		return nil, err
+	}
+	leases := map[string]int{}
+	for _, pod := range podList.Items {
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed ||
+			pod.DeletionTimestamp != nil {
+			continue
+		}
+		var affinity *corev1.NodeAffinity
+		if pod.Spec.Affinity != nil {
+			affinity = pod.Spec.Affinity.NodeAffinity
+		}
+		if node := getRsyncClientPodNode(pod.Spec.NodeName, affinity); node != "" {
+			leases[node]++
+		}
+	}
+	return leases, nil
+}
+
+// Get whether each scheduled Rsync operation may run given the transfer leases
+// held on the nodes and the maximum number of leases per node. The started
+// operations keep running, their Pods already hold a lease. A pending operation
+// reserves a lease on its node, in the order of the scheduled operations, or
+// waits for a transfer of the node to complete. The operations which node is
+// unknown aren't limited. All pending operations wait when the leases are
+// unknown.
+func leaseRsyncOperations(operations []*migapi.RsyncOperation, nodes []string, scheduled []bool, leases map[string]int, maxPerNode int) []bool {
+	leased := make([]bool, len(operations))
+	for i, operation := range operations {
+		if !scheduled[i] {
+			continue
+		}
+		if operation.IsComplete() || operation.CurrentAttempt > 0 || nodes[i] == "" {
+			leased[i] = true
+			continue
+		}
+		if leases == nil || leases[nodes[i]] >= maxPerNode {
+			continue
+		}
+		leases[nodes[i]]++
+		leased[i] = true
+	}
+	return leased
+}
@@ -0,0 +1,266 @@
+package directvolumemigration
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	fakecompat "github.com/konveyor/mig-controller/pkg/compat/fake"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_scheduleRsyncOperations(t *testing.T) {
+	operation := func(size string, attempt int, succeeded bool) *migapi.RsyncOperation {
+		operation := &migapi.RsyncOperation{CurrentAttempt: attempt, Succeeded: succeeded}
+		if size != "" {
+			quantity := resource.MustParse(size)
+			operation.UsedCapacity = &quantity
+		}
+		return operation
+	}
+	tests := []struct {
+		name          string
+		operations    []*migapi.RsyncOperation
+		maxConcurrent int
+		strategy      string
+		want          []bool
+	}{
+		{
+			name: "when fifo, should start the operations in their order",
+			operations: []*migapi.RsyncOperation{
+				operation("1Gi", 0, false), operation("100Gi", 0, false), operation("200Gi", 0, false),
+			},
+			maxConcurrent: 2,
+			strategy:      migapi.TransferSchedulingFIFO,
+			want:          []bool{true, true, false},
+		},
+		{
+			name: "when fifo and transfers completed, should start the next operations",
+			operations: []*migapi.RsyncOperation{
+				operation("1Gi", 1, true), operation("100Gi", 1, false), operation("200Gi", 0, false), operation("2Gi", 0, false),
+			},
+			maxConcurrent: 2,
+			want:          []bool{true, true, true, false},
+		},
+		{
+			name: "when size-balanced, should start the largest and the smallest operations",
+			operations: []*migapi.RsyncOperation{
+				operation("100Gi", 0, false), operation("1Gi", 0, false), operation("200Gi", 0, false), operation("2Gi", 0, false),
+			},
+			maxConcurrent: 2,
+			strategy:      migapi.TransferSchedulingSizeBalanced,
+			want:          []bool{false, true, true, false},
+		},
+		{
+			name: "when size-balanced and a large operation runs, should start a small operation",
+			operations: []*migapi.RsyncOperation{
+				operation("100Gi", 0, false), operation("1Gi", 1, true), operation("200Gi", 1, false), operation("2Gi", 0, false),
+			},
+			maxConcurrent: 2,
+			strategy:      migapi.TransferSchedulingSizeBalanced,
+			want:          []bool{false, true, true, true},
+		},
+		{
+			name: "when size-balanced and only large operations are left, should start the smallest of them",
+			operations: []*migapi.RsyncOperation{
+				operation("100Gi", 1, false), operation("1Gi", 1, true), operation("300Gi", 0, false), operation("2Gi", 1, true), operation("200Gi", 0, false),
+			},
+			maxConcurrent: 2,
+			strategy:      migapi.TransferSchedulingSizeBalanced,
+			want:          []bool{true, true, false, true, true},
+		},
+		{
+			name: "when size-balanced and sizes are unknown, should start the operations of unknown size last",
+			operations: []*migapi.RsyncOperation{
+				operation("", 0, false), operation("1Gi", 0, false), operation("", 0, false),
+			},
+			maxConcurrent: 2,
+			strategy:      migapi.TransferSchedulingSizeBalanced,
+			want:          []bool{true, true, false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scheduleRsyncOperations(tt.operations, tt.maxConcurrent, tt.strategy); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("scheduleRsyncOperations() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_getRsyncClientPodNode(t *testing.T) {
+	hostnameAffinity := &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{
+				{
+					MatchExpressions: []corev1.NodeSelectorRequirement{
+						{Key: corev1.LabelHostname, Operator: corev1.NodeSelectorOpIn, Values: []string{"node-2"}},
+					},
+				},
+			},
+		},
+	}
+	zoneAffinity := &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{
+				{
+					MatchExpressions: []corev1.NodeSelectorRequirement{
+						{Key: corev1.LabelZoneFailureDomainStable, Operator: corev1.NodeSelectorOpIn, Values: []string{"zone-a"}},
+					},
+				},
+			},
+		},
+	}
+	tests := []struct {
+		name     string
+		nodeName string
+		affinity *corev1.NodeAffinity
+		want     string
+	}{
+		{name: "when the node name is set, should use it", nodeName: "node-1", affinity: hostnameAffinity, want: "node-1"},
+		{name: "when the affinity selects a node by name, should use it", affinity: getNodeNameAffinity("node-3"), want: "node-3"},
+		{name: "when the affinity selects a node by hostname, should use it", affinity: hostnameAffinity, want: "node-2"},
+		{name: "when the affinity selects a zone, should be unknown", affinity: zoneAffinity, want: ""},
+		{name: "when not set, should be unknown", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getRsyncClientPodNode(tt.nodeName, tt.affinity); got != tt.want {
+				t.Errorf("getRsyncClientPodNode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_getNodeTransferLeases(t *testing.T) {
+	pod := func(name string, node string, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "ns",
+				Labels: map[string]string{
+					"app":                   DirectVolumeMigrationRsyncTransfer,
+					"directvolumemigration": DirectVolumeMigrationRsyncClient,
+				},
+			},
+			Spec:   corev1.PodSpec{NodeName: node},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+	}
+	client := fakecompat.NewFakeClient(
+		pod("rsync-1", "node-1", corev1.PodRunning),
+		pod("rsync-2", "node-1", corev1.PodPending),
+		pod("rsync-3", "node-1", corev1.PodSucceeded),
+		pod("rsync-4", "node-2", corev1.PodFailed),
+		pod("rsync-5", "", corev1.PodPending),
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "ns"}, Spec: corev1.PodSpec{NodeName: "node-2"}},
+	)
+	got, err := getNodeTransferLeases(client)
+	want := map[string]int{"node-1": 2}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("getNodeTransferLeases() = %v, %v, want %v", got, err, want)
+	}
+}
+
+func Test_leaseRsyncOperations(t *testing.T) {
+	pending := func() *migapi.RsyncOperation { return &migapi.RsyncOperation{} }
+	started := func() *migapi.RsyncOperation { return &migapi.RsyncOperation{CurrentAttempt: 1} }
+	tests := []struct {
+		name       string
+		operations []*migapi.RsyncOperation
+		nodes      []string
+		scheduled  []bool
+		leases     map[string]int
+		want       []bool
+	}{
+		{
+			name:       "when the node has leases left, should start the pending operations in order",
+			operations: []*migapi.RsyncOperation{pending(), pending(), pending()},
+			nodes:      []string{"node-1", "node-1", "node-1"},
+			scheduled:  []bool{true, true, true},
+			leases:     map[string]int{},
+			want:       []bool{true, true, false},
+		},
+		{
+			name:       "when the leases of the node are held by other Pods, should wait",
+			operations: []*migapi.RsyncOperation{pending(), pending()},
+			nodes:      []string{"node-1", "node-2"},
+			scheduled:  []bool{true, true},
+			leases:     map[string]int{"node-1": 2, "node-2": 1},
+			want:       []bool{false, true},
+		},
+		{
+			name:       "when started, should keep running",
+			operations: []*migapi.RsyncOperation{started(), started(), pending()},
+			nodes:      []string{"node-1", "node-1", "node-1"},
+			scheduled:  []bool{true, true, true},
+			leases:     map[string]int{"node-1": 2},
+			want:       []bool{true, true, false},
+		},
+		{
+			name:       "when the node is unknown, should not be limited",
+			operations: []*migapi.RsyncOperation{pending(), pending()},
+			nodes:      []string{"", ""},
+			scheduled:  []bool{true, true},
+			leases:     map[string]int{},
+			want:       []bool{true, true},
+		},
+		{
+			name:       "when not scheduled, should wait",
+			operations: []*migapi.RsyncOperation{pending(), pending()},
+			nodes:      []string{"node-1", "node-2"},
+			scheduled:  []bool{false, true},
+			leases:     map[string]int{},
+			want:       []bool{false, true},
+		},
+		{
+			name:       "when the leases are unknown, should keep the pending operations waiting",
+			operations: []*migapi.RsyncOperation{started(), pending()},
+			nodes:      []string{"node-1", "node-2"},
+			scheduled:  []bool{true, true},
+			leases:     nil,
+			want:       []bool{true, false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := leaseRsyncOperations(tt.operations, tt.nodes, tt.scheduled, tt.leases, 2); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("leaseRsyncOperations() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_lockNodeLeases(t *testing.T) {
+	unlock := lockNodeLeases([]string{"node-1", "", "node-1"})
+	// a DVM reading from another node doesn't wait
+	done := make(chan bool)
+	go func() {
+		lockNodeLeases([]string{"node-2"})()
+		done <- true
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("lockNodeLeases() waited for the lock of another node")
+	}
+	// a DVM reading from the same node waits for the leases to be reserved
+	go func() {
+		lockNodeLeases([]string{"node-2", "node-1"})()
+		done <- true
+	}()
+	select {
+	case <-done:
+		t.Fatalf("lockNodeLeases() did not wait for the lock of the node")
+	case <-time.After(100 * time.Millisecond):
+	}
+	unlock()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("lockNodeLeases() did not get the lock of the node once unlocked")
+	}
+}
@@ -0,0 +1,273 @@
+package directvolumemigration
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	liberr "github.com/konveyor/controller/pkg/error"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"github.com/konveyor/mig-controller/pkg/compat"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Speed test of the Rsync transfer
+const (
+	// SpeedTestModule Rsync module and volume the generated data is transferred from and to
+	SpeedTestModule = "speed-test"
+	// DefaultSpeedTestSize size of the data generated for each destination namespace
+	DefaultSpeedTestSize = "1Gi"
+	// SpeedTestVolumeHeadroom space of the speed test volume above the generated data,
+	// for the file system overhead and the temporary file Rsync writes, with
+	// SpeedTestVolumeHeadroomPercent percent of the size of the data
+	SpeedTestVolumeHeadroom        = 64 * 1024 * 1024
+	SpeedTestVolumeHeadroomPercent = 10
+)
+
+// Get whether the DVM runs a speed test rather than transferring the PVCs.
+func (t *Task) isSpeedTest() bool {
+	return t.Owner.Spec.SpeedTest != nil || t.Owner.Spec.Itinerary == SpeedTestMigration.Name
+}
+
+// Get the size of the data generated by the speed test in bytes.
+func (t *Task) getSpeedTestSize() int64 {
+	size := resource.MustParse(DefaultSpeedTestSize)
+	if t.Owner.Spec.SpeedTest != nil && t.Owner.Spec.SpeedTest.Size != nil {
+		size = *t.Owner.Spec.SpeedTest.Size
+	}
+	return size.Value()
+}
+
+// Get the volume holding the generated data of the speed test, in place of the
+// PVCs in the Rsync transfer and client Pods. The volume is limited to the size
+// of the data with some headroom, the kubelet evicts the Pods exceeding it.
+func getSpeedTestVolume(size int64) corev1.Volume {
+	limit := size + size*SpeedTestVolumeHeadroomPercent/100 + SpeedTestVolumeHeadroom
+	return corev1.Volume{
+		Name: SpeedTestModule,
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{
+				SizeLimit: resource.NewQuantity(limit, resource.BinarySI),
+			},
+		},
+	}
+}
+
+// Get the command of the Rsync container of the speed test Pod. The data is
+// generated from random bytes for compression not to skew the throughput. The
+// latency is the time taken to list the Rsync module through the endpoint, the
+// connection and authentication included, the elapsed time is the time taken to
+// transfer the data. Both are written in nanoseconds to the termination message.
+func getSpeedTestCommand(rsyncOptions []string, source string, destination string, size int64) string {
+	rsync := strings.Join(append([]string{"rsync"}, rsyncOptions...), " ")
+	return strings.Join([]string{
+		"trap \"touch /usr/share/rsync-stunnel-mgmt/rsync-client-container-done\" EXIT SIGINT SIGTERM",
+		fmt.Sprintf("head -c %d /dev/urandom > %sdata || exit 1", size, source),
+		"timeout=600; SECONDS=0; while [ $SECONDS -lt $timeout ]; do nc -z localhost 2222 && break; done",
+		fmt.Sprintf("start=$(date +%%s%%N); rsync --list-only %s/ > /dev/null || exit $?; listed=$(date +%%s%%N)", destination),
+		fmt.Sprintf("%s %s %s || exit $?; transferred=$(date +%%s%%N)", rsync, source, destination),
+		"echo \"latency=$((listed-start)) elapsed=$((transferred-listed))\" > /dev/termination-log",
+	}, "; ")
+}
+
+// Parse the latency and elapsed time written by the speed test Pod.
+func parseSpeedTestMessage(message string) (time.Duration, time.Duration, bool) {
+	latency, elapsed := int64(0), int64(0)
+	_, err := fmt.Sscanf(strings.TrimSpace(message), "latency=%d elapsed=%d", &latency, &elapsed)
+	if err != nil || latency < 0 || elapsed <= 0 {
+		return 0, 0, false
+	}
+	return time.Duration(latency), time.Duration(elapsed), true
+}
+
+// Get the speed test Pod template transferring the generated data of the
+// source namespace to the Rsync transfer Pod of the destination namespace.
+func (req rsyncClientPodRequirements) getSpeedTestPodTemplate(size int64) corev1.Pod {
+	req.pvInfo = PVCWithSecurityContext{name: SpeedTestModule, pvcHash: SpeedTestModule}
+	pod := req.getRsyncClientPodTemplate()
+	pod.GenerateName = "dvm-speed-test-"
+	pod.Annotations = nil
+	for i := range pod.Spec.Volumes {
+		if pod.Spec.Volumes[i].Name == SpeedTestModule {
+			pod.Spec.Volumes[i] = getSpeedTestVolume(size)
+		}
+	}
+	source := fmt.Sprintf("/mnt/%s/%s/", req.namespace, SpeedTestModule)
+	destination := fmt.Sprintf("rsync://root@%s/%s", req.destIP, SpeedTestModule)
+	for i := range pod.Spec.Containers {
+		if pod.Spec.Containers[i].Name == DirectVolumeMigrationRsyncClient {
+			pod.Spec.Containers[i].Command = []string{
+				"/bin/bash",
+				"-c",
+				getSpeedTestCommand(req.rsyncOptions, source, destination, size),
+			}
+		}
+	}
+	return pod
+}
+
+// Get the requirements common to the speed test Pods of all namespaces.
+func (t *Task) getSpeedTestPodRequirements(srcClient compat.Client) (rsyncClientPodRequirements, error) {
+	req := rsyncClientPodRequirements{}
+	cluster, err := t.Owner.GetSourceCluster(t.Client)
+	if err != nil {
+		return req, liberr.Wrap(err)
+	}
+	t.clusterRsyncTuning, err = t.getClusterRsyncTuning()
+	if err != nil {
+		return req, liberr.Wrap(err)
+	}
+	transferImage, err := cluster.GetRsyncTransferImage(t.Client)
+	if err != nil {
+		return req, liberr.Wrap(err)
+	}
+	password, err := t.getRsyncPassword()
+	if err != nil {
+		return req, liberr.Wrap(err)
+	}
+	rsyncLimits, rsyncRequests, err := t.getPodResourceLists(CLIENT_POD_CPU_LIMIT, CLIENT_POD_MEMORY_LIMIT, CLIENT_POD_CPU_REQUEST, CLIENT_POD_MEMORY_REQUEST)
+	if err != nil {
+		return req, liberr.Wrap(err)
+	}
+	stunnelLimits, stunnelRequests, err := t.getPodResourceLists(STUNNEL_POD_CPU_LIMIT, STUNNEL_POD_MEMORY_LIMIT, STUNNEL_POD_CPU_REQUEST, STUNNEL_POD_MEMORY_REQUEST)
+	if err != nil {
+		return req, liberr.Wrap(err)
+	}
+	isPrivileged, _ := isRsyncPrivileged(srcClient)
+	req = rsyncClientPodRequirements{
+		image:    transferImage,
+		password: password,
+		rsyncResourceReq: corev1.ResourceRequirements{
+			Limits:   rsyncLimits,
+			Requests: rsyncRequests,
+		},
+		stunnelResourceReq: corev1.ResourceRequirements{
+			Limits:   stunnelLimits,
+			Requests: stunnelRequests,
+		},
+		privileged:            isPrivileged,
+		destIP:                "localhost",
+		activeDeadlineSeconds: t.getRsyncPodActiveDeadlineSeconds(),
+	}
+	return req, nil
+}
+
+// Get the speed test Pod of the namespace, nil when not created yet.
+func (t *Task) getSpeedTestPod(srcClient compat.Client, ns string) (*corev1.Pod, error) {
+	podList := corev1.PodList{}
+	selector := Union(GetRsyncPodSelector(SpeedTestModule), map[string]string{
+		RsyncTransferGenerationLabel: string(t.Owner.UID),
+	})
+	err := srcClient.List(context.TODO(), &podList,
+		k8sclient.InNamespace(ns), k8sclient.MatchingLabels(selector))
+	if err != nil {
+		return nil, liberr.Wrap(err)
+	}
+	if len(podList.Items) == 0 {
+		return nil, nil
+	}
+	return &podList.Items[0], nil
+}
+
+// Get the result of a completed speed test Pod.
+func getSpeedTestResult(pod *corev1.Pod, size int64) migapi.SpeedTestResult {
+	result := migapi.SpeedTestResult{}
+	message := ""
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == DirectVolumeMigrationRsyncClient && status.State.Terminated != nil {
+			message = strings.TrimSpace(status.State.Terminated.Message)
+		}
+	}
+	if pod.Status.Phase == corev1.PodFailed {
+		result.Error = message
+		if result.Error == "" {
+			result.Error = pod.Status.Message
+		}
+		return result
+	}
+	latency, elapsed, ok := parseSpeedTestMessage(message)
+	if !ok {
+		result.Error = fmt.Sprintf("unexpected speed test output: %q", message)
+		return result
+	}
+	result.TransferredBytes = resource.NewQuantity(size, resource.BinarySI)
+	result.Throughput = resource.NewQuantity(int64(float64(size)/elapsed.Seconds()), resource.BinarySI)
+	result.Latency = &metav1.Duration{Duration: latency}
+	result.ElapsedTime = &metav1.Duration{Duration: elapsed}
+	return result
+}
+
+// Run the speed test Pod of each source namespace and report the measured
+// throughput and latency once the Pods completed. The Pods are deleted with the
+// Rsync resources of the migration, the generated data along with them.
+func (t *Task) runSpeedTest() (bool, error) {
+	srcClient, err := t.getSourceClient()
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	size := t.getSpeedTestSize()
+	results := []migapi.SpeedTestResult{}
+	completed := true
+	var req *rsyncClientPodRequirements
+	for bothNs := range t.getPVCNamespaceMap() {
+		srcNs := getSourceNs(bothNs)
+		destNs := getDestNs(bothNs)
+		endpointType, err := t.getEndpointType(destNs)
+		if err != nil {
+			return false, liberr.Wrap(err)
+		}
+		pod, err := t.getSpeedTestPod(srcClient, srcNs)
+		if err != nil {
+			return false, liberr.Wrap(err)
+		}
+		if pod == nil {
+			if req == nil {
+				common, err := t.getSpeedTestPodRequirements(srcClient)
+				if err != nil {
+					return false, liberr.Wrap(err)
+				}
+				req = &common
+			}
+			nsReq := *req
+			nsReq.namespace = srcNs
+			nsReq.rsyncOptions = t.getEndpointRsyncOptions(endpointType)
+			podTemplate := nsReq.getSpeedTestPodTemplate(size)
+			podTemplate.Labels = Union(podTemplate.Labels, map[string]string{
+				RsyncTransferGenerationLabel: string(t.Owner.UID),
+			})
+			t.Log.Info("Creating speed test Pod on source cluster",
+				"namespace", srcNs,
+				"size", size)
+			err = srcClient.Create(context.TODO(), &podTemplate)
+			if err != nil {
+				return false, liberr.Wrap(err)
+			}
+			completed = false
+			continue
+		}
+		if pod.Status.Phase != corev1.PodSucceeded && pod.Status.Phase != corev1.PodFailed {
+			completed = false
+			continue
+		}
+		result := getSpeedTestResult(pod, size)
+		result.Namespace = destNs
+		result.EndpointType = endpointType
+		if result.Error != "" {
+			t.Log.Info("Speed test failed",
+				"pod", path.Join(pod.Namespace, pod.Name),
+				"error", result.Error)
+		}
+		results = append(results, result)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Namespace < results[j].Namespace
+	})
+	t.Owner.Status.SpeedTestResults = results
+	return completed, nil
+}
@@ -0,0 +1,114 @@
+package directvolumemigration
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func Test_parseSpeedTestMessage(t *testing.T) {
+	tests := []struct {
+		message     string
+		wantLatency time.Duration
+		wantElapsed time.Duration
+		wantOk      bool
+	}{
+		{message: "latency=25000000 elapsed=4000000000\n", wantLatency: 25 * time.Millisecond, wantElapsed: 4 * time.Second, wantOk: true},
+		{message: "latency=0 elapsed=0", wantOk: false},
+		{message: "rsync: connection unexpectedly closed", wantOk: false},
+		{message: "", wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			latency, elapsed, ok := parseSpeedTestMessage(tt.message)
+			if latency != tt.wantLatency || elapsed != tt.wantElapsed || ok != tt.wantOk {
+				t.Errorf("parseSpeedTestMessage() = %v, %v, %v, want %v, %v, %v",
+					latency, elapsed, ok, tt.wantLatency, tt.wantElapsed, tt.wantOk)
+			}
+		})
+	}
+}
+
+func Test_getSpeedTestResult(t *testing.T) {
+	pod := func(phase corev1.PodPhase, message string) *corev1.Pod {
+		return &corev1.Pod{
+			Status: corev1.PodStatus{
+				Phase: phase,
+				ContainerStatuses: []corev1.ContainerStatus{
+					{
+						Name: DirectVolumeMigrationRsyncClient,
+						State: corev1.ContainerState{
+							Terminated: &corev1.ContainerStateTerminated{Message: message},
+						},
+					},
+				},
+			},
+		}
+	}
+	size := int64(1 << 30)
+	tests := []struct {
+		name           string
+		pod            *corev1.Pod
+		wantThroughput int64
+		wantError      string
+	}{
+		{
+			name:           "when the speed test succeeded, should report the throughput",
+			pod:            pod(corev1.PodSucceeded, "latency=10000000 elapsed=8000000000"),
+			wantThroughput: size / 8,
+		},
+		{
+			name:      "when the speed test failed, should report the output",
+			pod:       pod(corev1.PodFailed, "rsync error: timeout in data send/receive (code 30)"),
+			wantError: "rsync error: timeout in data send/receive (code 30)",
+		},
+		{
+			name:      "when the output is unexpected, should report an error",
+			pod:       pod(corev1.PodSucceeded, ""),
+			wantError: `unexpected speed test output: ""`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := getSpeedTestResult(tt.pod, size)
+			if got.Error != tt.wantError {
+				t.Errorf("getSpeedTestResult() error = %v, want %v", got.Error, tt.wantError)
+			}
+			if tt.wantThroughput > 0 && (got.Throughput == nil || got.Throughput.Value() != tt.wantThroughput) {
+				t.Errorf("getSpeedTestResult() throughput = %v, want %v", got.Throughput, tt.wantThroughput)
+			}
+			if tt.wantThroughput > 0 && (got.Latency == nil || got.Latency.Duration != 10*time.Millisecond) {
+				t.Errorf("getSpeedTestResult() latency = %v, want %v", got.Latency, 10*time.Millisecond)
+			}
+		})
+	}
+}
+
+func Test_getSpeedTestPodTemplate(t *testing.T) {
+	req := rsyncClientPodRequirements{
+		namespace:    "ns",
+		destIP:       "localhost",
+		rsyncOptions: []string{"--archive", "--timeout=300"},
+	}
+	pod := req.getSpeedTestPodTemplate(1 << 20)
+	for _, volume := range pod.Spec.Volumes {
+		if volume.PersistentVolumeClaim != nil {
+			t.Errorf("getSpeedTestPodTemplate() mounts PVC %s", volume.PersistentVolumeClaim.ClaimName)
+		}
+		if volume.Name == SpeedTestModule && (volume.EmptyDir == nil || volume.EmptyDir.SizeLimit.Value() != 1<<20+104857+64<<20) {
+			t.Errorf("getSpeedTestPodTemplate() volume = %v, want an emptyDir of 1Mi with the headroom", volume)
+		}
+	}
+	command := pod.Spec.Containers[0].Command[2]
+	for _, want := range []string{
+		"head -c 1048576 /dev/urandom > /mnt/ns/speed-test/data",
+		"rsync --list-only rsync://root@localhost/speed-test/",
+		"rsync --archive --timeout=300 /mnt/ns/speed-test/ rsync://root@localhost/speed-test",
+	} {
+		if !strings.Contains(command, want) {
+			t.Errorf("getSpeedTestPodTemplate() command = %v, want %v", command, want)
+		}
+	}
+}
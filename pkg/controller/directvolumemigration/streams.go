@@ -0,0 +1,226 @@
+package directvolumemigration
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	// MaxLargeFileStreams defines the maximum number of parallel Rsync streams a large file can be split among
+	MaxLargeFileStreams = 16
+	// LargeFileStreamMinSize size in bytes above which a file is transferred in byte ranges over parallel streams
+	LargeFileStreamMinSize = 1024 * 1024 * 1024
+	// LargeFileStreamChunkSize size in MiB of the byte ranges of a large file, each transferred by a single Rsync process
+	LargeFileStreamChunkSize = 256
+	// LargeFileStreamsDir directory of the Rsync module receiving the byte ranges of the large files
+	LargeFileStreamsDir = ".dvm-streams"
+	// RsyncReassembleScriptKey key of the Rsync transfer Pod ConfigMap holding the reassembly script
+	RsyncReassembleScriptKey = "rsync-reassemble.sh"
+	// RsyncReassembleScriptPath path of the reassembly script in the Rsync transfer Pod
+	RsyncReassembleScriptPath = "/etc/rsync-reassemble.sh"
+	// LargeFileStreamReassemblyPolls number of times the reassembly of the large files is polled before the transfer, up to an hour
+	LargeFileStreamReassemblyPolls = 720
+	// LargeFileStreamReassemblyInterval interval in seconds the reassembly of the large files is polled at
+	LargeFileStreamReassemblyInterval = 5
+)
+
+// rsyncReassembleScript is run by the Rsync daemon after each transfer to a
+// module. The byte ranges of each large file received with their manifest are
+// written at their offset in a single file, each byte range is removed once
+// written for the reassembly to only need the space of a byte range on top of
+// the received ones. The file replaces the destination file only when its
+// checksum matches the checksum of the source file. Otherwise, or when the
+// volume lacks the space of a byte range, the byte ranges are dropped and the
+// file is left to the Rsync transfer of the PVC. The manifest lists the number
+// of byte ranges, their size in MiB, the size, the modification time and the
+// SHA-256 checksum of the source file, and its path.
+const rsyncReassembleScript = `#!/bin/bash
+dir="${RSYNC_MODULE_PATH}/` + LargeFileStreamsDir + `"
+for stream in "${dir}"/*/; do
+  stream="${stream%/}"
+  [ -f "${stream}/manifest" ] || continue
+  mkdir "${stream}/lock" 2>/dev/null || continue
+  { read -r parts; read -r chunk; read -r size; read -r mtime; read -r sum; IFS= read -r file; } < "${stream}/manifest"
+  target="${RSYNC_MODULE_PATH}/${file}"
+  if [ $(($(df -Pk "${stream}" | awk 'NR==2 {print $4}') / 1024)) -lt "${chunk}" ]; then
+    echo "Not enough space left to reassemble ${file} from ${parts} byte ranges, left to the Rsync transfer"
+    rm -rf "${stream}"
+    continue
+  fi
+  ok=1
+  for ((n=0; n<parts; n++)); do
+    dd if="${stream}/part-${n}" of="${stream}/file" bs=1M seek=$((n*chunk)) conv=notrunc,sparse status=none || { ok=0; break; }
+    rm -f "${stream}/part-${n}"
+  done
+  if [ ${ok} -eq 1 ] && truncate -s "${size}" "${stream}/file" && [ "$(sha256sum "${stream}/file" | cut -d' ' -f1)" = "${sum}" ]; then
+    mkdir -p "$(dirname "${target}")" && mv -f "${stream}/file" "${target}" && touch -d "@${mtime}" "${target}"
+    echo "Reassembled ${file} from ${parts} byte ranges"
+  else
+    echo "Checksum mismatch of ${file} reassembled from ${parts} byte ranges, left to the Rsync transfer"
+  fi
+  rm -rf "${stream}"
+done
+rmdir "${dir}" 2>/dev/null
+exit 0
+`
+
+// Get the number of parallel Rsync streams the large files of the PVC are
+// split among, 0 when they are transferred by the Rsync transfer of the PVC.
+// The byte ranges can't be filtered by the Rsync options, a PVC transferred
+// with filter rules or size limits, or only verified, isn't split.
+func (t *Task) getLargeFileStreams(vol PVCWithSecurityContext) int {
+	if vol.largeFileStreams <= 1 || vol.block || t.isVerifyOnly() || t.isSpeedTest() ||
+		t.hasRsyncFilter() || vol.maxSize != "" || vol.minSize != "" {
+		return 0
+	}
+	return vol.largeFileStreams
+}
+
+// Get whether the large files of any PVC of the migration are split among
+// parallel Rsync streams, the Rsync daemon then reassembles them.
+func (t *Task) hasLargeFileStreams() bool {
+	if t.isVerifyOnly() || t.isSpeedTest() || t.hasRsyncFilter() {
+		return false
+	}
+	for _, pvc := range t.Owner.Spec.PersistentVolumeClaims {
+		if pvc.LargeFileStreams > 1 && pvc.MaxSize == "" && pvc.MinSize == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// largeFileStreamsTemplate bash commands transferring the large files of the
+// source in byte ranges split among parallel streams before the transfer, see
+// getLargeFileStreamsCommand.
+var largeFileStreamsTemplate = template.Must(template.New("streams").Parse(`mkdir -p {{ .StreamDir }}/streams-empty {{ .StreamDir }}/streams-tree
+rsync -r --delete {{ .StreamDir }}/streams-empty/ {{ .Remote }}/ || exit $?
+find {{ .Source }} -type f -links 1 -size +{{ .MinSize }}c ! -name "$(printf '*\n*')" -print0 > {{ .StreamDir }}/large-files
+if [ $(($(df -Pk {{ .StreamDir }} | awk 'NR==2 {print $4}') / 1024)) -lt {{ .StagingSize }} ]; then
+  echo "Not enough space left to stage the byte ranges of the large files, left to the Rsync transfer"
+  : > {{ .StreamDir }}/large-files
+fi
+k=0
+while IFS= read -r -d '' f; do
+  size=$(stat -c %s "$f"); mtime=$(date -r "$f" +%s.%N); sum=$(sha256sum "$f" | cut -d' ' -f1)
+  parts=$(( (size + {{ .ChunkBytes }} - 1) / {{ .ChunkBytes }} ))
+  mkdir -p {{ .StreamDir }}/streams-tree/{{ .StreamsDir }}/$k && rsync -r {{ .StreamDir }}/streams-tree/ {{ .Destination }}/ || exit $?
+  rm -rf {{ .StreamDir }}/streams-tree/{{ .StreamsDir }}
+  pids=()
+  for s in $(seq 0 {{ .LastStream }}); do
+    (for ((n=s; n<parts; n+={{ .Streams }})); do
+      dd if="$f" of={{ .StreamDir }}/chunk-$s bs=1M skip=$((n*{{ .ChunkSize }})) count={{ .ChunkSize }} status=none && {{ .Rsync }} {{ .StreamDir }}/chunk-$s {{ .Remote }}/$k/part-$n || exit $?
+    done
+    rm -f {{ .StreamDir }}/chunk-$s) &
+    pids+=($!)
+  done
+  rc=0; for p in ${pids[@]}; do wait $p || rc=$?; done
+  if [ $rc -ne 0 ]; then exit $rc; fi
+  printf '%s\n' "$parts" {{ .ChunkSize }} "$size" "$mtime" "$sum" "${f#{{ .Source }}}" > {{ .StreamDir }}/manifest && {{ .Rsync }} {{ .StreamDir }}/manifest {{ .Remote }}/$k/manifest || exit $?
+  k=$((k+1))
+done < {{ .StreamDir }}/large-files
+w=0; while [ $w -lt {{ .ReassemblyPolls }} ] && rsync --list-only -r {{ .Remote }}/ 2>/dev/null | grep -q '/manifest$'; do sleep {{ .ReassemblyInterval }}; w=$((w+1)); done
+rsync -r --delete {{ .StreamDir }}/streams-empty/ {{ .Remote }}/ > /dev/null 2>&1
+{{ .Transfer }}`))
+
+// largeFileStreams parameters of the largeFileStreamsTemplate.
+type largeFileStreams struct {
+	// Rsync rsync command with the options of the transfer
+	Rsync string
+	// Source path of the source volume, ending with a slash
+	Source string
+	// Destination Rsync module of the destination volume
+	Destination string
+	// Remote directory of the Rsync module receiving the byte ranges
+	Remote string
+	// StreamDir directory shared with the Stunnel container staging the byte ranges
+	StreamDir string
+	// StreamsDir name of the Remote directory in the Rsync module
+	StreamsDir string
+	// Streams number of parallel streams, LastStream the index of the last one
+	Streams    int
+	LastStream int
+	// MinSize size in bytes above which a file is transferred in byte ranges
+	MinSize int64
+	// ChunkSize size in MiB of the byte ranges, ChunkBytes in bytes
+	ChunkSize  int
+	ChunkBytes int64
+	// StagingSize space in MiB needed to stage a byte range per stream
+	StagingSize int
+	// ReassemblyPolls number of times the reassembly is polled every ReassemblyInterval seconds
+	ReassemblyPolls    int
+	ReassemblyInterval int
+	// Transfer commands of the Rsync transfer of the PVC
+	Transfer string
+}
+
+// Get the largeFileStreams of the transfer with the default sizes and reassembly wait.
+func newLargeFileStreams(rsyncOptions []string, source string, destination string, streams int, streamDir string, transfer string) largeFileStreams {
+	return largeFileStreams{
+		Rsync:              strings.Join(append([]string{"rsync"}, rsyncOptions...), " "),
+		Source:             source,
+		Destination:        destination,
+		Remote:             fmt.Sprintf("%s/%s", destination, LargeFileStreamsDir),
+		StreamDir:          streamDir,
+		StreamsDir:         LargeFileStreamsDir,
+		Streams:            streams,
+		LastStream:         streams - 1,
+		MinSize:            LargeFileStreamMinSize,
+		ChunkSize:          LargeFileStreamChunkSize,
+		ChunkBytes:         LargeFileStreamChunkSize * 1024 * 1024,
+		StagingSize:        streams * LargeFileStreamChunkSize,
+		ReassemblyPolls:    LargeFileStreamReassemblyPolls,
+		ReassemblyInterval: LargeFileStreamReassemblyInterval,
+		Transfer:           transfer,
+	}
+}
+
+// Get the bash commands of the large file streams. The transfer is run on its
+// own in the unlikely case the template fails.
+func (s largeFileStreams) command() string {
+	var command strings.Builder
+	if err := largeFileStreamsTemplate.Execute(&command, s); err != nil {
+		return s.Transfer
+	}
+	return command.String()
+}
+
+// getLargeFileStreamsCommand returns the bash commands transferring each file of
+// the source larger than LargeFileStreamMinSize in byte ranges, split among the
+// parallel streams, before the transfer. Each byte range is copied to the volume
+// shared with the Stunnel container, as the root filesystem of the Rsync container
+// is read-only, and transferred by its own Rsync process. The large files are
+// left to the transfer when the shared volume lacks the space of a byte range
+// per stream, see getLargeFileStreamsResources. The manifest of a file
+// is transferred once all its byte ranges succeeded, the Rsync daemon reassembles
+// the file and verifies its checksum. The transfer waits for the reassembly of
+// the files, then runs and skips the reassembled files as unchanged. Files with
+// several links are left to the transfer for the links to be preserved.
+func getLargeFileStreamsCommand(rsyncOptions []string, source string, destination string, streams int, streamDir string, transfer string) string {
+	return newLargeFileStreams(rsyncOptions, source, destination, streams, streamDir, transfer).command()
+}
+
+// Get the resource requirements of the Rsync container staging the byte ranges
+// of the large files on the volume shared with the Stunnel container, an
+// emptyDir. The ephemeral storage of a byte range per stream is requested on
+// top of the requested resources, for the Pod to be scheduled on a node with
+// the space to stage them.
+func getLargeFileStreamsResources(resources corev1.ResourceRequirements, streams int) corev1.ResourceRequirements {
+	resources = *resources.DeepCopy()
+	staging := resource.NewQuantity(int64(streams)*LargeFileStreamChunkSize*1024*1024, resource.BinarySI)
+	if resources.Requests == nil {
+		resources.Requests = corev1.ResourceList{}
+	}
+	if requested, found := resources.Requests[corev1.ResourceEphemeralStorage]; found {
+		staging.Add(requested)
+	}
+	resources.Requests[corev1.ResourceEphemeralStorage] = *staging
+	if limit, found := resources.Limits[corev1.ResourceEphemeralStorage]; found && limit.Cmp(*staging) < 0 {
+		resources.Limits[corev1.ResourceEphemeralStorage] = *staging
+	}
+	return resources
+}
@@ -0,0 +1,317 @@
+package directvolumemigration
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func Test_getLargeFileStreamsCommand(t *testing.T) {
+	transfer := "rsync --archive /mnt/src/ rsync://root@localhost/dst"
+	got := getLargeFileStreamsCommand([]string{"--archive"}, "/mnt/src/", "rsync://root@localhost/dst", 4, "/tmp/streams", transfer)
+	for _, want := range []string{
+		"find /mnt/src/ -type f -links 1 -size +1073741824c",
+		"for s in $(seq 0 3); do",
+		"(for ((n=s; n<parts; n+=4)); do",
+		"dd if=\"$f\" of=/tmp/streams/chunk-$s bs=1M skip=$((n*256)) count=256",
+		"rsync --archive /tmp/streams/chunk-$s rsync://root@localhost/dst/.dvm-streams/$k/part-$n",
+		"rsync --archive /tmp/streams/manifest rsync://root@localhost/dst/.dvm-streams/$k/manifest",
+		"\"${f#/mnt/src/}\"",
+		"while [ $w -lt 720 ] && rsync --list-only -r rsync://root@localhost/dst/.dvm-streams/",
+		// a byte range per stream is staged
+		"if [ $(($(df -Pk /tmp/streams | awk 'NR==2 {print $4}') / 1024)) -lt 1024 ]; then",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("getLargeFileStreamsCommand() = %v, want it to contain %v", got, want)
+		}
+	}
+	if !strings.HasSuffix(got, "\n"+transfer) {
+		t.Errorf("getLargeFileStreamsCommand() = %v, want it to end with the transfer %v", got, transfer)
+	}
+}
+
+// Write a df reporting no space left, for the commands to take their low space path.
+func writeFullDf(t *testing.T, dir string) string {
+	bin := filepath.Join(dir, "bin")
+	if err := os.MkdirAll(bin, 0755); err != nil {
+		t.Fatal(err)
+	}
+	df := "#!/bin/sh\necho 'Filesystem 1024-blocks Used Available Capacity Mounted on'\necho 'full 1024 1024 0 100% /'\n"
+	if err := ioutil.WriteFile(filepath.Join(bin, "df"), []byte(df), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return "PATH=" + bin + ":" + os.Getenv("PATH")
+}
+
+// Write the byte ranges and the manifest of a large file received by the Rsync daemon.
+func writeLargeFileStream(t *testing.T, module string, file string, content []byte, sum string) {
+	stream := filepath.Join(module, LargeFileStreamsDir, "0")
+	if err := os.MkdirAll(stream, 0755); err != nil {
+		t.Fatal(err)
+	}
+	chunk := 1 << 20
+	parts := 0
+	for offset := 0; offset < len(content); offset += chunk {
+		end := offset + chunk
+		if end > len(content) {
+			end = len(content)
+		}
+		if err := ioutil.WriteFile(filepath.Join(stream, fmt.Sprintf("part-%d", parts)), content[offset:end], 0644); err != nil {
+			t.Fatal(err)
+		}
+		parts++
+	}
+	manifest := fmt.Sprintf("%d\n1\n%d\n1600000000.000000000\n%s\n%s\n", parts, len(content), sum, file)
+	if err := ioutil.WriteFile(filepath.Join(stream, "manifest"), []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Run the reassembly script as the Rsync daemon does after a transfer to the module.
+func runReassembleScript(module string, env ...string) (string, error) {
+	cmd := exec.Command("bash", "-c", rsyncReassembleScript)
+	cmd.Env = append(append(os.Environ(), "RSYNC_MODULE_PATH="+module), env...)
+	out, err := cmd.CombinedOutput()
+	return string(out), err
+}
+
+func Test_rsyncReassembleScript(t *testing.T) {
+	content := make([]byte, 3<<20+512<<10)
+	rand.New(rand.NewSource(1)).Read(content)
+	checksum := sha256.Sum256(content)
+	previous := []byte("previous content")
+	tests := []struct {
+		name        string
+		sum         string
+		fullDf      bool
+		wantMessage string
+		want        []byte
+	}{
+		{
+			name:        "when the checksum matches, should replace the destination file",
+			sum:         hex.EncodeToString(checksum[:]),
+			wantMessage: "Reassembled data/disk.img from 4 byte ranges",
+			want:        content,
+		},
+		{
+			name:        "when the checksum does not match, should leave the destination file to the transfer",
+			sum:         strings.Repeat("0", 64),
+			wantMessage: "Checksum mismatch of data/disk.img",
+			want:        previous,
+		},
+		{
+			name:        "when the volume lacks the space of a byte range, should leave the destination file to the transfer",
+			sum:         hex.EncodeToString(checksum[:]),
+			fullDf:      true,
+			wantMessage: "Not enough space left to reassemble data/disk.img",
+			want:        previous,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "dvm-reassemble")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			module := filepath.Join(dir, "module")
+			target := filepath.Join(module, "data", "disk.img")
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := ioutil.WriteFile(target, previous, 0644); err != nil {
+				t.Fatal(err)
+			}
+			writeLargeFileStream(t, module, "data/disk.img", content, tt.sum)
+			env := []string{}
+			if tt.fullDf {
+				env = append(env, writeFullDf(t, dir))
+			}
+			out, err := runReassembleScript(module, env...)
+			if err != nil {
+				t.Fatalf("rsyncReassembleScript failed: %v: %s", err, out)
+			}
+			if !strings.Contains(out, tt.wantMessage) {
+				t.Errorf("rsyncReassembleScript output = %s, want %s", out, tt.wantMessage)
+			}
+			got, err := ioutil.ReadFile(target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("rsyncReassembleScript left a destination file of %d bytes, want %d bytes", len(got), len(tt.want))
+			}
+			if _, err := os.Stat(filepath.Join(module, LargeFileStreamsDir)); !os.IsNotExist(err) {
+				t.Errorf("rsyncReassembleScript must remove the byte ranges once reassembled or dropped")
+			}
+		})
+	}
+}
+
+func Test_getLargeFileStreamsCommand_transfer(t *testing.T) {
+	if _, err := exec.LookPath("rsync"); err != nil {
+		t.Skip("rsync not found")
+	}
+	content := make([]byte, 5<<20+512<<10)
+	rand.New(rand.NewSource(1)).Read(content)
+	tests := []struct {
+		name        string
+		fullDf      bool
+		wantMessage string
+	}{
+		{
+			name:        "when the byte ranges can be staged, should transfer the large file in byte ranges",
+			wantMessage: "Reassembled disk.img from 6 byte ranges",
+		},
+		{
+			name:        "when the byte ranges can't be staged, should leave the large file to the transfer",
+			fullDf:      true,
+			wantMessage: "Not enough space left to stage the byte ranges",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "dvm-streams")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			source, destination, streamDir := filepath.Join(dir, "src")+"/", filepath.Join(dir, "dest"), filepath.Join(dir, "streams")
+			for _, d := range []string{source, destination, streamDir} {
+				if err := os.MkdirAll(d, 0755); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := ioutil.WriteFile(filepath.Join(source, "disk.img"), content, 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := ioutil.WriteFile(filepath.Join(source, "small"), []byte("small"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			options := []string{"--archive"}
+			transfer := "rsync --archive " + source + " " + destination
+			streams := newLargeFileStreams(options, source, destination, 2, streamDir, transfer)
+			// 1MiB byte ranges of the files larger than 1MiB, the reassembly polled every second
+			streams.MinSize, streams.ChunkSize, streams.ChunkBytes, streams.StagingSize = 1<<20, 1, 1<<20, 2
+			streams.ReassemblyInterval = 1
+			env := []string{}
+			if tt.fullDf {
+				env = append(env, writeFullDf(t, dir))
+			}
+
+			// the Rsync daemon reassembles the files after each transfer to the module
+			done := make(chan struct{})
+			reassembled := make(chan string)
+			go func() {
+				out := ""
+				for {
+					select {
+					case <-done:
+						reassembled <- out
+						return
+					case <-time.After(100 * time.Millisecond):
+						o, _ := runReassembleScript(destination)
+						out += o
+					}
+				}
+			}()
+			cmd := exec.Command("bash", "-c", streams.command())
+			cmd.Env = append(os.Environ(), env...)
+			out, err := cmd.CombinedOutput()
+			close(done)
+			out = append(out, <-reassembled...)
+			// and after the last one
+			last, _ := runReassembleScript(destination)
+			out = append(out, last...)
+			if err != nil {
+				t.Fatalf("large file streams command failed: %v: %s", err, out)
+			}
+			if !strings.Contains(string(out), tt.wantMessage) {
+				t.Errorf("large file streams command output = %s, want %s", out, tt.wantMessage)
+			}
+			got, err := ioutil.ReadFile(filepath.Join(destination, "disk.img"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, content) {
+				t.Errorf("large file streams command did not transfer the large file byte for byte")
+			}
+			if _, err := os.Stat(filepath.Join(destination, "small")); err != nil {
+				t.Errorf("large file streams command did not transfer the small file: %v", err)
+			}
+			if _, err := os.Stat(filepath.Join(destination, LargeFileStreamsDir)); !os.IsNotExist(err) {
+				t.Errorf("large file streams command must leave no byte range on the destination")
+			}
+		})
+	}
+}
+
+func TestTask_getLargeFileStreams(t *testing.T) {
+	tests := []struct {
+		name string
+		spec migapi.DirectVolumeMigrationSpec
+		vol  PVCWithSecurityContext
+		want int
+	}{
+		{name: "when not set, should not split", vol: PVCWithSecurityContext{}, want: 0},
+		{name: "when a single stream, should not split", vol: PVCWithSecurityContext{largeFileStreams: 1}, want: 0},
+		{name: "when set, should split", vol: PVCWithSecurityContext{largeFileStreams: 4}, want: 4},
+		{name: "when a block PVC, should not split", vol: PVCWithSecurityContext{largeFileStreams: 4, block: true}, want: 0},
+		{name: "when size filtered, should not split", vol: PVCWithSecurityContext{largeFileStreams: 4, maxSize: "10G"}, want: 0},
+		{
+			name: "when verify only, should not split",
+			spec: migapi.DirectVolumeMigrationSpec{VerifyOnly: true},
+			vol:  PVCWithSecurityContext{largeFileStreams: 4},
+			want: 0,
+		},
+		{
+			name: "when filtered by rules, should not split",
+			spec: migapi.DirectVolumeMigrationSpec{RsyncFilter: &migapi.RsyncFilter{Excludes: []string{"*.tmp"}}},
+			vol:  PVCWithSecurityContext{largeFileStreams: 4},
+			want: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &Task{Owner: &migapi.DirectVolumeMigration{Spec: tt.spec}}
+			if got := task.getLargeFileStreams(tt.vol); got != tt.want {
+				t.Errorf("getLargeFileStreams() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_getLargeFileStreamsResources(t *testing.T) {
+	resources := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceEphemeralStorage: resource.MustParse("1Gi")},
+		Limits:   corev1.ResourceList{corev1.ResourceEphemeralStorage: resource.MustParse("2Gi")},
+	}
+	got := getLargeFileStreamsResources(resources, 8)
+	if requested := got.Requests[corev1.ResourceEphemeralStorage]; requested.Cmp(resource.MustParse("3Gi")) != 0 {
+		t.Errorf("getLargeFileStreamsResources() requests = %v, want 3Gi", requested.String())
+	}
+	if limit := got.Limits[corev1.ResourceEphemeralStorage]; limit.Cmp(resource.MustParse("3Gi")) != 0 {
+		t.Errorf("getLargeFileStreamsResources() limits = %v, want 3Gi", limit.String())
+	}
+	// the requirements of the other Pods are left as they are
+	if requested := resources.Requests[corev1.ResourceEphemeralStorage]; requested.Cmp(resource.MustParse("1Gi")) != 0 {
+		t.Errorf("getLargeFileStreamsResources() changed the requests to %v", requested.String())
+	}
+	got = getLargeFileStreamsResources(corev1.ResourceRequirements{}, 4)
+	if requested := got.Requests[corev1.ResourceEphemeralStorage]; requested.Cmp(resource.MustParse("1Gi")) != 0 {
+		t.Errorf("getLargeFileStreamsResources() requests = %v, want 1Gi", requested.String())
+	}
+}
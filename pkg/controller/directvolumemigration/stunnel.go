@@ -9,6 +9,7 @@ import (
 	"text/template"
 
 	liberr "github.com/konveyor/controller/pkg/error"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
 	"github.com/konveyor/mig-controller/pkg/settings"
 	"gopkg.in/yaml.v2"
 
@@ -23,22 +24,31 @@ import (
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
-	//"k8s.io/apimachinery/pkg/types"
 	k8serror "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type stunnelConfig struct {
-	Name          string
-	Namespace     string
-	StunnelPort   int32
-	RsyncRoute    string
-	RsyncPort     int32
-	VerifyCA      bool
-	VerifyCALevel string
+	Name           string
+	Namespace      string
+	StunnelPort    int32
+	RsyncRoute     string
+	RsyncRoutePort int32
+	RsyncPort      int32
+	VerifyCA       bool
+	VerifyCALevel  string
+	ConnectTimeout int
 	stunnelProxyConfig
 }
 
+// Keys of the stunnel proxy credentials Secret
+const (
+	StunnelProxyUsernameKey = "username"
+	StunnelProxyPasswordKey = "password"
+)
+
 type stunnelProxyConfig struct {
 	ProxyHost     string
 	ProxyUsername string
@@ -47,40 +57,37 @@ type stunnelProxyConfig struct {
 
 // TODO: Parameterize this more to support custom
 // networking configs from directvolumemigration spec
-const stunnelClientConfigTemplate = `apiVersion: v1
-kind: ConfigMap
-metadata:
-  labels:
-    purpose: stunnel
-data:
-  stunnel.conf: |
-    pid =
-    sslVersion = TLSv1.2
-    client = yes
-    syslog = no
-    output = /dev/stdout
-    [rsync]
-    accept = localhost:{{ .StunnelPort }}
-    CAFile = /etc/stunnel/certs/ca.crt
-    cert = /etc/stunnel/certs/tls.crt
+// The client config holds the proxy credentials, it is stored in a Secret.
+const stunnelClientConfigTemplate = `pid =
+sslVersion = TLSv1.2
+client = yes
+syslog = no
+output = /dev/stdout
+[rsync]
+accept = localhost:{{ .StunnelPort }}
+CAFile = /etc/stunnel/certs/ca.crt
+cert = /etc/stunnel/certs/tls.crt
 {{ if not (eq .ProxyHost "") }}
-    protocol = connect
-    connect = {{ .ProxyHost }}
-    protocolHost = {{ .RsyncRoute }}:443
+protocol = connect
+connect = {{ .ProxyHost }}
+protocolHost = {{ .RsyncRoute }}:{{ .RsyncRoutePort }}
 {{ if not (eq .ProxyUsername "") }}
-    protocolUsername = {{ .ProxyUsername }}
+protocolUsername = {{ .ProxyUsername }}
 {{ end }}
 {{ if not (eq .ProxyPassword "") }}
-    protocolPassword = {{ .ProxyPassword }}
+protocolPassword = {{ .ProxyPassword }}
 {{ end }}
 {{ else }}
-    connect = {{ .RsyncRoute }}:443
+connect = {{ .RsyncRoute }}:{{ .RsyncRoutePort }}
 {{ end }}
 {{ if .VerifyCA }}
-    verify = {{ .VerifyCALevel }}
+verify = {{ .VerifyCALevel }}
 {{ end }}
-    key = /etc/stunnel/certs/tls.key
-    debug = 7
+{{ if gt .ConnectTimeout 0 }}
+TIMEOUTconnect = {{ .ConnectTimeout }}
+{{ end }}
+key = /etc/stunnel/certs/tls.key
+debug = 7
 `
 
 const stunnelDestinationConfigTemplate = `apiVersion: v1
@@ -110,13 +117,15 @@ func (t *Task) generateStunnelProxyConfig() (stunnelProxyConfig, error) {
 	var proxyConfig stunnelProxyConfig
 	tcpProxyString := settings.Settings.DvmOpts.StunnelTCPProxy
 	if tcpProxyString != "" {
-		t.Log.Info("Found TCP proxy string. Configuring Stunnel proxy.",
-			"tcpProxyString", tcpProxyString)
 		url, err := url.Parse(tcpProxyString)
 		if err != nil {
+			err = redactURLError(err)
 			t.Log.Error(err, fmt.Sprintf("failed to parse %s setting", settings.TCPProxyKey))
 			return proxyConfig, liberr.Wrap(err)
 		}
+		// The proxy URL may hold credentials, only its host is logged
+		t.Log.Info("Found TCP proxy string. Configuring Stunnel proxy.",
+			"proxyHost", url.Host)
 		proxyConfig.ProxyHost = url.Host
 		if url.User != nil {
 			proxyConfig.ProxyUsername = url.User.Username()
@@ -124,10 +133,61 @@ func (t *Task) generateStunnelProxyConfig() (stunnelProxyConfig, error) {
 				proxyConfig.ProxyPassword = pass
 			}
 		}
+		// Credentials in the proxy Secret take precedence over the ones in the URL
+		if settings.Settings.DvmOpts.StunnelTCPProxySecret != "" {
+			username, password, err := t.getStunnelProxyCredentials()
+			if err != nil {
+				return proxyConfig, liberr.Wrap(err)
+			}
+			proxyConfig.ProxyUsername = username
+			proxyConfig.ProxyPassword = password
+		}
 	}
 	return proxyConfig, nil
 }
 
+// redactURLError drops the URL, which may hold credentials, from a URL parse error.
+func redactURLError(err error) error {
+	if urlErr, ok := err.(*url.Error); ok {
+		return urlErr.Err
+	}
+	return err
+}
+
+// getStunnelProxyCredentials reads the proxy username and password from the
+// Secret referenced by the STUNNEL_TCP_PROXY_SECRET setting
+func (t *Task) getStunnelProxyCredentials() (string, string, error) {
+	secret, err := getStunnelProxySecret(t.Client)
+	if err != nil {
+		return "", "", liberr.Wrap(err)
+	}
+	if secret == nil {
+		return "", "", liberr.Wrap(
+			fmt.Errorf("stunnel proxy secret %s not found",
+				path.Join(migapi.OpenshiftMigrationNamespace, settings.Settings.DvmOpts.StunnelTCPProxySecret)))
+	}
+	return string(secret.Data[StunnelProxyUsernameKey]), string(secret.Data[StunnelProxyPasswordKey]), nil
+}
+
+// getStunnelProxySecret fetches the proxy credentials Secret from the host cluster.
+// Returns nil when the Secret does not exist.
+func getStunnelProxySecret(client k8sclient.Client) (*corev1.Secret, error) {
+	secret := &corev1.Secret{}
+	err := client.Get(context.TODO(),
+		types.NamespacedName{
+			Namespace: migapi.OpenshiftMigrationNamespace,
+			Name:      settings.Settings.DvmOpts.StunnelTCPProxySecret,
+		},
+		secret)
+	if k8serror.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, liberr.Wrap(err)
+	}
+	return secret, nil
+}
+
 func (t *Task) createStunnelConfig() error {
 	// Get client for destination
 	destClient, err := t.getDestinationClient()
@@ -152,6 +212,12 @@ func (t *Task) createStunnelConfig() error {
 		return err
 	}
 
+	// Get the connect timeouts of the endpoint types
+	t.clusterRsyncTuning, err = t.getClusterRsyncTuning()
+	if err != nil {
+		return err
+	}
+
 	// openssl library? to generate new certs
 
 	// Create same stunnel configmap with certs on both source+destination
@@ -189,14 +255,24 @@ func (t *Task) createStunnelConfig() error {
 		if err != nil {
 			return err
 		}
+		rsyncRoutePort, err := t.getEndpointPort(destNs)
+		if err != nil {
+			return err
+		}
+		endpointType, err := t.getEndpointType(destNs)
+		if err != nil {
+			return err
+		}
 		srcStunnelConf := stunnelConfig{
 			Namespace:          srcNs,
 			StunnelPort:        2222,
 			RsyncPort:          22,
 			RsyncRoute:         rsyncRoute,
+			RsyncRoutePort:     rsyncRoutePort,
 			stunnelProxyConfig: srcStunnelProxyConfig,
 			VerifyCA:           settings.Settings.StunnelVerifyCA,
 			VerifyCALevel:      settings.Settings.StunnelVerifyCALevel,
+			ConnectTimeout:     t.getEndpointConnectTimeout(endpointType),
 		}
 
 		destStunnelConf := stunnelConfig{
@@ -228,20 +304,19 @@ func (t *Task) createStunnelConfig() error {
 			return err
 		}
 
-		// Generate configmaps
-		clientConfigMap := corev1.ConfigMap{
+		// Generate the client config Secret and the destination configmap
+		clientSecret := corev1.Secret{
 			ObjectMeta: metav1.ObjectMeta{
 				Namespace: srcNs,
 				Name:      DirectVolumeMigrationStunnelConfig,
 			},
+			Data: map[string][]byte{
+				"stunnel.conf": clientTpl.Bytes(),
+			},
 		}
-		clientConfigMap.Labels = t.Owner.GetCorrelationLabels()
-		clientConfigMap.Labels["app"] = DirectVolumeMigrationRsyncTransfer
-
-		err = yaml.Unmarshal(clientTpl.Bytes(), &clientConfigMap)
-		if err != nil {
-			return err
-		}
+		clientSecret.Labels = t.Owner.GetCorrelationLabels()
+		clientSecret.Labels["app"] = DirectVolumeMigrationRsyncTransfer
+		clientSecret.Labels[RsyncTransferGenerationLabel] = string(t.Owner.UID)
 
 		destConfigMap := corev1.ConfigMap{
 			ObjectMeta: metav1.ObjectMeta{
@@ -251,19 +326,20 @@ func (t *Task) createStunnelConfig() error {
 		}
 		destConfigMap.Labels = t.Owner.GetCorrelationLabels()
 		destConfigMap.Labels["app"] = DirectVolumeMigrationRsyncTransfer
+		destConfigMap.Labels[RsyncTransferGenerationLabel] = string(t.Owner.UID)
 
 		err = yaml.Unmarshal(destTpl.Bytes(), &destConfigMap)
 		if err != nil {
 			return err
 		}
 
-		// Create configmaps on source + dest
-		t.Log.Info("Creating Stunnel client ConfigMap on source cluster.",
-			"configMap", path.Join(clientConfigMap.Namespace, clientConfigMap.Name))
-		err = srcClient.Create(context.TODO(), &clientConfigMap)
+		// Create the client Secret on source and the configmap on dest
+		t.Log.Info("Creating Stunnel client config Secret on source cluster.",
+			"secret", path.Join(clientSecret.Namespace, clientSecret.Name))
+		err = srcClient.Create(context.TODO(), &clientSecret)
 		if k8serror.IsAlreadyExists(err) {
-			t.Log.Info("Configmap already exists on source cluster",
-				"configMap", path.Join(clientConfigMap.Namespace, clientConfigMap.Name))
+			t.Log.Info("Stunnel client config Secret already exists on source cluster",
+				"secret", path.Join(clientSecret.Namespace, clientSecret.Name))
 		} else if err != nil {
 			return err
 		}
@@ -375,7 +451,8 @@ func (t *Task) setupCerts() error {
 				Namespace: srcNs,
 				Name:      DirectVolumeMigrationStunnelCerts,
 				Labels: map[string]string{
-					"app": DirectVolumeMigrationRsyncTransfer,
+					"app":                        DirectVolumeMigrationRsyncTransfer,
+					RsyncTransferGenerationLabel: string(t.Owner.UID),
 				},
 			},
 			Data: map[string][]byte{
@@ -389,7 +466,8 @@ func (t *Task) setupCerts() error {
 				Namespace: destNs,
 				Name:      DirectVolumeMigrationStunnelCerts,
 				Labels: map[string]string{
-					"app": DirectVolumeMigrationRsyncTransfer,
+					"app":                        DirectVolumeMigrationRsyncTransfer,
+					RsyncTransferGenerationLabel: string(t.Owner.UID),
 				},
 			},
 			Data: map[string][]byte{
@@ -0,0 +1,138 @@
+package directvolumemigration
+
+import (
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// rsyncSizeUnits multipliers of the unit suffixes of the sizes printed by rsync --human-readable
+var rsyncSizeUnits = map[byte]float64{
+	'K': 1e3,
+	'M': 1e6,
+	'G': 1e9,
+	'T': 1e12,
+	'P': 1e15,
+}
+
+// parseRsyncSize parses a size reported by rsync --stats in bytes, e.g. 1,048,576
+// or 2.15G. Returns false when the size cannot be parsed.
+func parseRsyncSize(size string) (int64, bool) {
+	size = strings.ReplaceAll(strings.TrimSpace(size), ",", "")
+	if size == "" {
+		return 0, false
+	}
+	multiplier := 1.0
+	if unit, found := rsyncSizeUnits[size[len(size)-1]]; found {
+		multiplier = unit
+		size = size[:len(size)-1]
+	}
+	value, err := strconv.ParseFloat(size, 64)
+	if err != nil || value < 0 {
+		return 0, false
+	}
+	return int64(value * multiplier), true
+}
+
+// getTransferSummary summarizes the transfer of each PVC of the spec from its
+// Rsync operation and the progress of its Rsync Pods. Only the PVCs which
+// transfer succeeded report the bytes and files transferred, the endpoint type
+// of a namespace is left out when it cannot be read.
+func (t *Task) getTransferSummary() *migapi.TransferSummary {
+	status := &t.Owner.Status
+	summary := &migapi.TransferSummary{
+		Succeeded: t.Phase == Completed && !t.failed(),
+	}
+	if status.StartTimestamp != nil && status.CompletionTimestamp != nil {
+		summary.Duration = &metav1.Duration{
+			Duration: status.CompletionTimestamp.Sub(status.StartTimestamp.Time).Round(time.Second),
+		}
+	}
+	operations := map[string]*migapi.RsyncOperation{}
+	for _, operation := range status.RsyncOperations {
+		if operation.PVCReference != nil {
+			operations[operation.String()] = operation
+		}
+	}
+	pods := map[string]*migapi.PodProgress{}
+	for _, list := range [][]*migapi.PodProgress{status.FailedPods, status.SuccessfulPods} {
+		for _, pod := range list {
+			if pod.PVCReference != nil {
+				pods[path.Join(pod.PVCReference.Namespace, pod.PVCReference.Name)] = pod
+			}
+		}
+	}
+	endpointTypes := map[string]string{}
+	transferredBytes, bytesReported := int64(0), false
+	for _, pvc := range t.Owner.Spec.PersistentVolumeClaims {
+		key := path.Join(pvc.Namespace, pvc.Name)
+		pvcSummary := migapi.PVCTransferSummary{
+			PVCReference: &corev1.ObjectReference{Namespace: pvc.Namespace, Name: pvc.Name},
+			State:        migapi.PVCTransferPending,
+		}
+		if operation := operations[key]; operation != nil {
+			switch {
+			case operation.Succeeded:
+				pvcSummary.State = migapi.PVCTransferCompleted
+			case operation.Failed:
+				pvcSummary.State = migapi.PVCTransferFailed
+			}
+			pvcSummary.Skipped = operation.Skipped
+			pvcSummary.Unchanged = operation.Unchanged
+			pvcSummary.Attempts = operation.CurrentAttempt
+		}
+		destNs := pvc.Namespace
+		if pvc.TargetNamespace != "" {
+			destNs = pvc.TargetNamespace
+		}
+		if pvcSummary.State != migapi.PVCTransferPending {
+			if _, found := endpointTypes[destNs]; !found {
+				endpointType, err := t.getEndpointType(destNs)
+				if err != nil {
+					t.Log.Info("Cannot read the endpoint type of the destination namespace for the transfer summary.",
+						"namespace", destNs, "error", err.Error())
+				}
+				endpointTypes[destNs] = endpointType
+			}
+			pvcSummary.EndpointType = endpointTypes[destNs]
+		}
+		if pod := pods[key]; pod != nil {
+			pvcSummary.ElapsedTime = pod.TotalElapsedTime
+			if pod.RsyncStats != nil {
+				pvcSummary.TransferredFiles = pod.RsyncStats.NumberOfFilesTransferred
+				summary.TransferredFiles += pod.RsyncStats.NumberOfFilesTransferred
+				if size, ok := parseRsyncSize(pod.RsyncStats.TotalTransferredFileSize); ok {
+					pvcSummary.TransferredBytes = resource.NewQuantity(size, resource.BinarySI)
+					transferredBytes += size
+					bytesReported = true
+				}
+				if size, ok := parseRsyncSize(pod.RsyncStats.LiteralData); ok {
+					pvcSummary.ChangedBytes = resource.NewQuantity(size, resource.BinarySI)
+				}
+				if size, ok := parseRsyncSize(pod.RsyncStats.TotalFileSize); ok {
+					pvcSummary.TotalBytes = resource.NewQuantity(size, resource.BinarySI)
+				}
+			}
+		}
+		summary.PersistentVolumeClaims = append(summary.PersistentVolumeClaims, pvcSummary)
+	}
+	if bytesReported {
+		summary.TransferredBytes = resource.NewQuantity(transferredBytes, resource.BinarySI)
+	}
+	used := map[string]bool{}
+	for _, endpointType := range endpointTypes {
+		if endpointType != "" && !used[endpointType] {
+			used[endpointType] = true
+			summary.EndpointTypes = append(summary.EndpointTypes, endpointType)
+		}
+	}
+	sort.Strings(summary.EndpointTypes)
+	return summary
+}
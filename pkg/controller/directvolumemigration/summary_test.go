@@ -0,0 +1,112 @@
+package directvolumemigration
+
+import (
+	"testing"
+	"time"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_parseRsyncSize(t *testing.T) {
+	tests := []struct {
+		size   string
+		want   int64
+		wantOk bool
+	}{
+		{size: "1,048,576", want: 1048576, wantOk: true},
+		{size: "104857600", want: 104857600, wantOk: true},
+		{size: "2.15G", want: 2150000000, wantOk: true},
+		{size: "0", want: 0, wantOk: true},
+		{size: "", wantOk: false},
+		{size: "2.15X", wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.size, func(t *testing.T) {
+			got, ok := parseRsyncSize(tt.size)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("parseRsyncSize() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestTask_getTransferSummary(t *testing.T) {
+	ref := func(name string) *corev1.ObjectReference {
+		return &corev1.ObjectReference{Namespace: "ns", Name: name}
+	}
+	start := metav1.NewTime(time.Now().Add(-time.Hour))
+	completion := metav1.NewTime(start.Add(10 * time.Minute))
+	elapsed := &metav1.Duration{Duration: 5 * time.Minute}
+	task := &Task{
+		Log:   log.WithName("test-logger"),
+		Phase: Completed,
+		Owner: &migapi.DirectVolumeMigration{
+			Spec: migapi.DirectVolumeMigrationSpec{
+				PersistentVolumeClaims: []migapi.PVCToMigrate{
+					{ObjectReference: ref("pvc-1")},
+					{ObjectReference: ref("pvc-2")},
+					{ObjectReference: ref("pvc-3")},
+				},
+			},
+			Status: migapi.DirectVolumeMigrationStatus{
+				StartTimestamp:      &start,
+				CompletionTimestamp: &completion,
+				RsyncOperations: []*migapi.RsyncOperation{
+					{PVCReference: ref("pvc-1"), Succeeded: true, CurrentAttempt: 1},
+					{PVCReference: ref("pvc-2"), Failed: true, CurrentAttempt: 3},
+				},
+				SuccessfulPods: []*migapi.PodProgress{
+					{
+						PVCReference:     ref("pvc-1"),
+						TotalElapsedTime: elapsed,
+						RsyncStats: &migapi.RsyncStats{
+							NumberOfFilesTransferred: 10,
+							TotalFileSize:            "4,194,304",
+							TotalTransferredFileSize: "1,048,576",
+							LiteralData:              "524,288",
+						},
+					},
+				},
+				FailedPods: []*migapi.PodProgress{
+					{PVCReference: ref("pvc-2"), TotalElapsedTime: elapsed},
+				},
+			},
+		},
+	}
+	summary := task.getTransferSummary()
+	if !summary.Succeeded {
+		t.Errorf("getTransferSummary() succeeded = false, want true")
+	}
+	if summary.Duration == nil || summary.Duration.Duration != 10*time.Minute {
+		t.Errorf("getTransferSummary() duration = %v, want %v", summary.Duration, 10*time.Minute)
+	}
+	if summary.TransferredBytes == nil || summary.TransferredBytes.Value() != 1048576 || summary.TransferredFiles != 10 {
+		t.Errorf("getTransferSummary() transferred = %v bytes, %v files, want 1048576 bytes, 10 files",
+			summary.TransferredBytes, summary.TransferredFiles)
+	}
+	if len(summary.EndpointTypes) != 1 || summary.EndpointTypes[0] != EndpointTypeRoute {
+		t.Errorf("getTransferSummary() endpointTypes = %v, want [%s]", summary.EndpointTypes, EndpointTypeRoute)
+	}
+	wantStates := []string{migapi.PVCTransferCompleted, migapi.PVCTransferFailed, migapi.PVCTransferPending}
+	if len(summary.PersistentVolumeClaims) != len(wantStates) {
+		t.Fatalf("getTransferSummary() persistentVolumeClaims = %v, want %d PVCs", summary.PersistentVolumeClaims, len(wantStates))
+	}
+	for i, pvc := range summary.PersistentVolumeClaims {
+		if pvc.State != wantStates[i] {
+			t.Errorf("getTransferSummary() state of %s = %v, want %v", pvc.PVCReference.Name, pvc.State, wantStates[i])
+		}
+	}
+	if completed := summary.PersistentVolumeClaims[0]; completed.ChangedBytes == nil || completed.ChangedBytes.Value() != 524288 ||
+		completed.TotalBytes == nil || completed.TotalBytes.Value() != 4194304 {
+		t.Errorf("getTransferSummary() completed PVC changed %v of %v bytes, want 524288 of 4194304 bytes",
+			completed.ChangedBytes, completed.TotalBytes)
+	}
+	if failed := summary.PersistentVolumeClaims[1]; failed.Attempts != 3 || failed.ElapsedTime != elapsed || failed.TransferredBytes != nil {
+		t.Errorf("getTransferSummary() failed PVC = %+v, want 3 attempts, the elapsed time and no transferred bytes", failed)
+	}
+	if pending := summary.PersistentVolumeClaims[2]; pending.EndpointType != "" {
+		t.Errorf("getTransferSummary() endpoint type of the PVC not transferred = %v, want none", pending.EndpointType)
+	}
+}
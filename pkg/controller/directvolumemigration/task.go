@@ -11,6 +11,7 @@ import (
 	liberr "github.com/konveyor/controller/pkg/error"
 	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
 	"github.com/konveyor/mig-controller/pkg/compat"
+	"github.com/konveyor/mig-controller/pkg/settings"
 	"github.com/opentracing/opentracing-go"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -30,22 +31,41 @@ const (
 	DestinationNamespacesCreated         = "DestinationNamespacesCreated"
 	CreateDestinationPVCs                = "CreateDestinationPVCs"
 	DestinationPVCsCreated               = "DestinationPVCsCreated"
+	WaitForDestinationPVCsBound          = "WaitForDestinationPVCsBound"
+	EnsureDestinationPVCsExist           = "EnsureDestinationPVCsExist"
+	ExpandDestinationPVCs                = "ExpandDestinationPVCs"
+	PrewarmDestinationPVCs               = "PrewarmDestinationPVCs"
 	CreateStunnelConfig                  = "CreateStunnelConfig"
 	CreateRsyncConfig                    = "CreateRsyncConfig"
 	CreateRsyncRoute                     = "CreateRsyncRoute"
 	EnsureRsyncRouteAdmitted             = "EnsureRsyncRouteAdmitted"
+	EnsureDestinationPVCsReleased        = "EnsureDestinationPVCsReleased"
 	CreateRsyncTransferPods              = "CreateRsyncTransferPods"
+	EnsureRsyncSecretsExist              = "EnsureRsyncSecretsExist"
 	WaitForRsyncTransferPodsRunning      = "WaitForRsyncTransferPodsRunning"
 	CreatePVProgressCRs                  = "CreatePVProgressCRs"
+	RunPreTransferHooks                  = "RunPreTransferHooks"
 	RunRsyncOperations                   = "RunRsyncOperations"
+	PrepareTransfer                      = "PrepareTransfer"
+	RunTransfer                          = "RunTransfer"
+	VerifyTransfer                       = "VerifyTransfer"
+	VerifyData                           = "VerifyData"
 	CreateRsyncClientPods                = "CreateRsyncClientPods"
 	WaitForRsyncClientPodsCompleted      = "WaitForRsyncClientPodsCompleted"
 	Verification                         = "Verification"
+	CollectVerificationResults           = "CollectVerificationResults"
+	PlanTransfer                         = "PlanTransfer"
+	RunSpeedTest                         = "RunSpeedTest"
 	DeleteRsyncResources                 = "DeleteRsyncResources"
 	WaitForRsyncResourcesTerminated      = "WaitForRsyncResourcesTerminated"
 	WaitForStaleRsyncResourcesTerminated = "WaitForStaleRsyncResourcesTerminated"
+	RunPostTransferHooks                 = "RunPostTransferHooks"
+	DeleteHookJobs                       = "DeleteHookJobs"
+	VerifyDestinationConfigReferences    = "VerifyDestinationConfigReferences"
+	RecordCheckpoints                    = "RecordCheckpoints"
 	Completed                            = "Completed"
 	MigrationFailed                      = "MigrationFailed"
+	Canceled                             = "Canceled"
 )
 
 // labels
@@ -54,6 +74,7 @@ const (
 	DirectVolumeMigrationRsyncTransfer      = "directvolumemigration-rsync-transfer"
 	DirectVolumeMigrationRsyncConfig        = "directvolumemigration-rsync-config"
 	DirectVolumeMigrationRsyncCreds         = "directvolumemigration-rsync-creds"
+	DirectVolumeMigrationRsyncFilter        = "directvolumemigration-rsync-filter"
 	DirectVolumeMigrationRsyncTransferSvc   = "directvolumemigration-rsync-transfer-svc"
 	DirectVolumeMigrationRsyncTransferRoute = "dvm"
 	DirectVolumeMigrationStunnelConfig      = "directvolumemigration-stunnel-config"
@@ -64,6 +85,7 @@ const (
 	DirectVolumeMigrationRsyncClient        = "rsync-client"
 	DirectVolumeMigrationStunnel            = "stunnel"
 	MigratedByDirectVolumeMigration         = "migration.openshift.io/migrated-by-directvolumemigration" // (dvm UID)
+	RsyncTransferGenerationLabel            = "migration.openshift.io/rsync-transfer-generation"         // (dvm UID)
 )
 
 // Flags
@@ -102,6 +124,19 @@ func (r Itinerary) progressReport(phase string) (string, int, int) {
 	return phase, n, total
 }
 
+// Get the phases of the steps, in the order they run. The Created phase, which
+// has no name, is omitted.
+func (r Itinerary) stepPhases() []string {
+	phases := []string{}
+	for _, step := range r.Steps {
+		if step.phase == Created {
+			continue
+		}
+		phases = append(phases, step.phase)
+	}
+	return phases
+}
+
 var VolumeMigration = Itinerary{
 	Name: "VolumeMigration",
 	Steps: []Step{
@@ -114,28 +149,211 @@ var VolumeMigration = Itinerary{
 		{phase: DestinationNamespacesCreated},
 		{phase: CreateDestinationPVCs},
 		{phase: DestinationPVCsCreated},
+		{phase: WaitForDestinationPVCsBound},
+		{phase: ExpandDestinationPVCs},
+		{phase: PrewarmDestinationPVCs},
 		{phase: CreateRsyncRoute},
 		{phase: EnsureRsyncRouteAdmitted},
 		{phase: CreateRsyncConfig},
 		{phase: CreateStunnelConfig},
 		{phase: CreatePVProgressCRs},
+		{phase: EnsureDestinationPVCsReleased},
+		{phase: EnsureRsyncSecretsExist},
 		{phase: CreateRsyncTransferPods},
 		{phase: WaitForRsyncTransferPodsRunning},
+		{phase: RunPreTransferHooks},
 		{phase: RunRsyncOperations},
+		{phase: VerifyData},
+		{phase: DeleteRsyncResources},
+		{phase: WaitForRsyncResourcesTerminated},
+		{phase: RunPostTransferHooks},
+		{phase: VerifyDestinationConfigReferences},
+		{phase: RecordCheckpoints},
+		{phase: Completed},
+	},
+}
+
+var VerifyOnlyMigration = Itinerary{
+	Name: "VerifyOnlyMigration",
+	Steps: []Step{
+		{phase: Created},
+		{phase: Started},
+		{phase: Prepare},
+		{phase: CleanStaleRsyncResources},
+		{phase: WaitForStaleRsyncResourcesTerminated},
+		{phase: EnsureDestinationPVCsExist},
+		{phase: WaitForDestinationPVCsBound},
+		{phase: CreateRsyncRoute},
+		{phase: EnsureRsyncRouteAdmitted},
+		{phase: CreateRsyncConfig},
+		{phase: CreateStunnelConfig},
+		{phase: CreatePVProgressCRs},
+		{phase: EnsureDestinationPVCsReleased},
+		{phase: EnsureRsyncSecretsExist},
+		{phase: CreateRsyncTransferPods},
+		{phase: WaitForRsyncTransferPodsRunning},
+		{phase: RunRsyncOperations},
+		{phase: CollectVerificationResults},
+		{phase: DeleteRsyncResources},
+		{phase: WaitForRsyncResourcesTerminated},
+		{phase: Completed},
+	},
+}
+
+// EngineMigration transfers the PVCs with a transfer engine other than rsync.
+var EngineMigration = Itinerary{
+	Name: "EngineMigration",
+	Steps: []Step{
+		{phase: Created},
+		{phase: Started},
+		{phase: Prepare},
+		{phase: CleanStaleRsyncResources},
+		{phase: WaitForStaleRsyncResourcesTerminated},
+		{phase: CreateDestinationNamespaces},
+		{phase: DestinationNamespacesCreated},
+		{phase: CreateDestinationPVCs},
+		{phase: DestinationPVCsCreated},
+		{phase: WaitForDestinationPVCsBound},
+		{phase: ExpandDestinationPVCs},
+		{phase: PrewarmDestinationPVCs},
+		{phase: PrepareTransfer},
+		{phase: RunPreTransferHooks},
+		{phase: RunTransfer},
+		{phase: DeleteRsyncResources},
+		{phase: WaitForRsyncResourcesTerminated},
+		{phase: RunPostTransferHooks},
+		{phase: VerifyDestinationConfigReferences},
+		{phase: RecordCheckpoints},
+		{phase: Completed},
+	},
+}
+
+// EngineVerifyOnlyMigration verifies the PVCs with a transfer engine other than rsync.
+var EngineVerifyOnlyMigration = Itinerary{
+	Name: "EngineVerifyOnlyMigration",
+	Steps: []Step{
+		{phase: Created},
+		{phase: Started},
+		{phase: Prepare},
+		{phase: CleanStaleRsyncResources},
+		{phase: WaitForStaleRsyncResourcesTerminated},
+		{phase: EnsureDestinationPVCsExist},
+		{phase: WaitForDestinationPVCsBound},
+		{phase: PrepareTransfer},
+		{phase: RunTransfer},
+		{phase: VerifyTransfer},
+		{phase: DeleteRsyncResources},
+		{phase: WaitForRsyncResourcesTerminated},
+		{phase: Completed},
+	},
+}
+
+var PreviewMigration = Itinerary{
+	Name: "PreviewMigration",
+	Steps: []Step{
+		{phase: Created},
+		{phase: Started},
+		{phase: Prepare},
+		{phase: PlanTransfer},
+		{phase: Completed},
+	},
+}
+
+var SpeedTestMigration = Itinerary{
+	Name: "SpeedTestMigration",
+	Steps: []Step{
+		{phase: Created},
+		{phase: Started},
+		{phase: Prepare},
+		{phase: CleanStaleRsyncResources},
+		{phase: WaitForStaleRsyncResourcesTerminated},
+		{phase: CreateDestinationNamespaces},
+		{phase: DestinationNamespacesCreated},
+		{phase: CreateRsyncRoute},
+		{phase: EnsureRsyncRouteAdmitted},
+		{phase: CreateRsyncConfig},
+		{phase: CreateStunnelConfig},
+		{phase: EnsureRsyncSecretsExist},
+		{phase: CreateRsyncTransferPods},
+		{phase: WaitForRsyncTransferPodsRunning},
+		{phase: RunSpeedTest},
 		{phase: DeleteRsyncResources},
 		{phase: WaitForRsyncResourcesTerminated},
 		{phase: Completed},
 	},
 }
 
+var NoVolumesMigration = Itinerary{
+	Name: "NoVolumesMigration",
+	Steps: []Step{
+		{phase: Created},
+		{phase: Started},
+		{phase: Completed},
+	},
+}
+
 var FailedItinerary = Itinerary{
 	Name: "VolumeMigrationFailed",
 	Steps: []Step{
 		{phase: MigrationFailed},
+		{phase: DeleteHookJobs},
+		{phase: Completed},
+	},
+}
+
+var FailedCleanupItinerary = Itinerary{
+	Name: "VolumeMigrationFailedCleanup",
+	Steps: []Step{
+		{phase: MigrationFailed},
+		{phase: DeleteRsyncResources},
+		{phase: DeleteHookJobs},
+		{phase: WaitForRsyncResourcesTerminated},
 		{phase: Completed},
 	},
 }
 
+var CanceledItinerary = Itinerary{
+	Name: "VolumeMigrationCanceled",
+	Steps: []Step{
+		{phase: DeleteRsyncResources},
+		{phase: DeleteHookJobs},
+		{phase: WaitForRsyncResourcesTerminated},
+		{phase: Canceled},
+	},
+}
+
+// SelectableItineraries itineraries selectable with the itinerary of the DVM spec.
+var SelectableItineraries = []Itinerary{
+	VolumeMigration,
+	VerifyOnlyMigration,
+	PreviewMigration,
+	SpeedTestMigration,
+}
+
+// Get the selectable itinerary with the name, nil when it isn't selectable.
+func getSelectableItinerary(name string) *Itinerary {
+	for i := range SelectableItineraries {
+		if SelectableItineraries[i].Name == name {
+			return &SelectableItineraries[i]
+		}
+	}
+	return nil
+}
+
+// Get the itinerary selected by the flags of the DVM spec, the preview, speed
+// test and verify-only flags, empty when none is set.
+func getFlaggedItinerary(direct *migapi.DirectVolumeMigration) string {
+	switch {
+	case direct.Spec.Preview:
+		return PreviewMigration.Name
+	case direct.Spec.SpeedTest != nil:
+		return SpeedTestMigration.Name
+	case direct.Spec.VerifyOnly:
+		return VerifyOnlyMigration.Name
+	}
+	return ""
+}
+
 // A task that provides the complete migration workflow.
 // Log - A controller's logger.
 // Client - A controller's (local) client.
@@ -158,6 +376,16 @@ type Task struct {
 	Itinerary        Itinerary
 	Errors           []string
 
+	// Default tuning of the Rsync transfer from the destination cluster ConfigMap
+	clusterRsyncTuning *rsyncTuning
+
+	// Endpoint type rules of the destination cluster, parsed once per reconcile
+	endpointTypeRules *endpointTypeRules
+
+	// MigMigration owning the migration, fetched once per reconcile
+	ownerMigration        *migapi.MigMigration
+	ownerMigrationFetched bool
+
 	Tracer        opentracing.Tracer
 	ReconcileSpan opentracing.Span
 }
@@ -170,14 +398,51 @@ type sshKeys struct {
 func (t *Task) init() error {
 	t.RsyncRoutes = make(map[string]string)
 	t.Requeue = FastReQ
-	if t.failed() {
+	if t.canceled() {
+		t.Itinerary = CanceledItinerary
+	} else if t.failed() {
 		t.Itinerary = FailedItinerary
+		if t.Owner.Status.HasAnyCondition(DeadlineExceeded, TransferHookFailed, RsyncSecretsNotFound, RsyncRouteRejected, DestinationPVCsInUse) {
+			t.Itinerary = FailedCleanupItinerary
+		}
+	} else if t.isPreview() {
+		t.Itinerary = PreviewMigration
+	} else if t.isSpeedTest() {
+		t.Itinerary = SpeedTestMigration
+	} else if len(t.Owner.Spec.PersistentVolumeClaims) == 0 {
+		t.Itinerary = NoVolumesMigration
 	} else {
-		t.Itinerary = VolumeMigration
+		t.Itinerary = t.getTransferItinerary()
 	}
 	return nil
 }
 
+// Get the itinerary transferring the PVCs of the migration with its transfer engine.
+func (t *Task) getTransferItinerary() Itinerary {
+	if !t.isRsyncTransferEngine() {
+		if t.isVerifyOnly() {
+			return EngineVerifyOnlyMigration
+		}
+		return EngineMigration
+	}
+	if t.isVerifyOnly() {
+		return VerifyOnlyMigration
+	}
+	return VolumeMigration
+}
+
+// Get whether the migration only verifies the PVCs, requested by the
+// verify-only flag or the itinerary of the DVM spec.
+func (t *Task) isVerifyOnly() bool {
+	return t.Owner.Spec.VerifyOnly || t.Owner.Spec.Itinerary == VerifyOnlyMigration.Name
+}
+
+// Get whether the migration only resolves its transfer plan, requested by the
+// preview flag or the itinerary of the DVM spec.
+func (t *Task) isPreview() bool {
+	return t.Owner.Spec.Preview || t.Owner.Spec.Itinerary == PreviewMigration.Name
+}
+
 func (t *Task) Run(ctx context.Context) error {
 	t.Log = t.Log.WithValues("phase", t.Phase)
 	// Init
@@ -195,6 +460,75 @@ func (t *Task) Run(ctx context.Context) error {
 		defer span.Finish()
 	}
 
+	// Fail the migration once its deadline is exceeded.
+	if t.hasDeadlineExceeded() {
+		t.failDeadlineExceeded()
+		return nil
+	}
+
+	// Cancel the migration once the MigMigration owning it is deleted.
+	orphaned, err := t.isOrphaned()
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	if orphaned {
+		t.cancelOrphaned()
+		return nil
+	}
+
+	// Cancel the migration when the MigMigration owning it requests its DVMs to be canceled.
+	cancelRequested, err := t.isCancelRequested()
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	if cancelRequested {
+		t.cancelRequested()
+		return nil
+	}
+
+	// Report the PVCs skipped by a restarted Rsync transfer.
+	t.setPVCsSkipped()
+
+	// Report the PVCs not transferred as their destination already matched the source.
+	t.setPVCsUnchanged()
+
+	// Recreate the rsync transfer endpoint when requested.
+	handled, err := t.recreateRsyncTransferEndpoint()
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	if handled {
+		return nil
+	}
+
+	// Stop the rsync transfer, keeping the endpoint, when requested.
+	handled, err = t.stopRsyncTransfer()
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	if handled {
+		return nil
+	}
+
+	// Report the state of the transfer of each PVC once the phase ran.
+	defer t.updatePVCTransferStates()
+
+	// Report the readiness of the rsync transfer endpoint, checked once per phase.
+	if t.hasRsyncTransferEndpoint() {
+		err = t.ensureEndpointReady()
+		if err != nil {
+			t.Log.Info("Failed checking the readiness of the Rsync transfer endpoints.", "error", err.Error())
+		}
+	}
+
+	// Warn when the PVCs are copied from a live source.
+	if t.Phase == RunRsyncOperations || t.Phase == RunTransfer {
+		err = t.setSourceNotQuiesced()
+		if err != nil {
+			t.Log.Info("Failed checking whether the source Pods are quiesced.", "error", err.Error())
+		}
+	}
+
 	// Run the current phase.
 	switch t.Phase {
 	case Created, Started:
@@ -202,14 +536,20 @@ func (t *Task) Run(ctx context.Context) error {
 			return liberr.Wrap(err)
 		}
 	case Prepare:
+		err := t.setSourcePVCCapacities()
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		t.setOpenFilesLimitRaised()
+		err = t.checkClockSkew()
+		if err != nil {
+			return liberr.Wrap(err)
+		}
 		if err = t.next(); err != nil {
 			return liberr.Wrap(err)
 		}
 	case CleanStaleRsyncResources:
-		// TODO Need to add some labels during DVM run to differentiate
-		// deletion of rsync resources that are active vs stale. Using
-		// one label for both is the wrong approach.
-		err := t.deleteRsyncResources()
+		err := t.sweepStaleRsyncResources()
 		if err != nil {
 			return liberr.Wrap(err)
 		}
@@ -241,10 +581,24 @@ func (t *Task) Run(ctx context.Context) error {
 		}
 	case CreateDestinationPVCs:
 		// Create the PVCs on the destination
-		err := t.createDestinationPVCs()
+		created, err := t.createDestinationPVCs()
 		if err != nil {
 			return liberr.Wrap(err)
 		}
+		if !created {
+			t.Log.Info("Some existing destination PVCs are being deleted to be recreated. Waiting.")
+			t.Requeue = PollReQ
+			t.Owner.Status.StageCondition(Running)
+			cond := t.Owner.Status.FindCondition(Running)
+			if cond == nil {
+				return fmt.Errorf("'Running' condition not found on DVM [%v/%v]", t.Owner.Namespace, t.Owner.Name)
+			}
+			if time.Now().UTC().Sub(cond.LastTransitionTime.Time.UTC()) > DestinationPVCRecreateTimeout {
+				t.fail(MigrationFailed, []string{
+					fmt.Sprintf("Existing destination PVC(s) were not deleted within %v to be recreated", DestinationPVCRecreateTimeout)})
+			}
+			return nil
+		}
 		t.Requeue = NoReQ
 		if err = t.next(); err != nil {
 			return liberr.Wrap(err)
@@ -259,6 +613,66 @@ func (t *Task) Run(ctx context.Context) error {
 		if err = t.next(); err != nil {
 			return liberr.Wrap(err)
 		}
+	case EnsureDestinationPVCsExist:
+		missing, err := t.getMissingDestinationPVCs()
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		if len(missing) > 0 {
+			t.fail(MigrationFailed, []string{
+				fmt.Sprintf("Destination PVCs to verify not found: [%s]", strings.Join(missing, ", "))})
+			return nil
+		}
+		t.Requeue = NoReQ
+		if err = t.next(); err != nil {
+			return liberr.Wrap(err)
+		}
+	case WaitForDestinationPVCsBound:
+		bound, unboundPVCs, err := t.areDestinationPVCsBound()
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		if bound {
+			t.Requeue = NoReQ
+			if err = t.next(); err != nil {
+				return liberr.Wrap(err)
+			}
+		} else {
+			t.Log.Info("Some destination PVCs are not bound yet. Waiting.",
+				"unboundPersistentVolumeClaims", unboundPVCs)
+			t.Requeue = PollReQ
+			t.Owner.Status.StageCondition(Running)
+			cond := t.Owner.Status.FindCondition(Running)
+			if cond == nil {
+				return fmt.Errorf("'Running' condition not found on DVM [%v/%v]", t.Owner.Namespace, t.Owner.Name)
+			}
+			if time.Now().UTC().Sub(cond.LastTransitionTime.Time.UTC()) > DestinationPVCBindTimeout {
+				msg := fmt.Sprintf("Destination PVC(s) failed to bind within %v", DestinationPVCBindTimeout)
+				t.Owner.Status.SetCondition(
+					migapi.Condition{
+						Type:     DestinationPVCsNotBound,
+						Status:   True,
+						Reason:   NotReady,
+						Category: Warn,
+						Message:  msg,
+						Items:    unboundPVCs,
+						Durable:  true,
+					},
+				)
+				t.fail(MigrationFailed, []string{fmt.Sprintf("%s: [%s]", msg, strings.Join(unboundPVCs, ", "))})
+			} else {
+				t.Owner.Status.SetCondition(
+					migapi.Condition{
+						Type:     DestinationPVCsPending,
+						Status:   True,
+						Reason:   migapi.NotReady,
+						Category: Advisory,
+						Message:  fmt.Sprintf(DestinationPVCsPendingMessage, DestinationPVCBindTimeout),
+						Items:    unboundPVCs,
+					},
+				)
+			}
+		}
 	case CreateRsyncRoute:
 		err := t.createRsyncTransferRoute()
 		if err != nil {
@@ -269,10 +683,14 @@ func (t *Task) Run(ctx context.Context) error {
 			return liberr.Wrap(err)
 		}
 	case EnsureRsyncRouteAdmitted:
-		admitted, reasons, err := t.areRsyncRoutesAdmitted()
+		admitted, reasons, rejected, err := t.areRsyncRoutesAdmitted()
 		if err != nil {
 			return liberr.Wrap(err)
 		}
+		if len(rejected) > 0 {
+			t.failRsyncRoutesRejected(rejected)
+			break
+		}
 		if admitted {
 			t.Requeue = NoReQ
 			if err = t.next(); err != nil {
@@ -307,6 +725,10 @@ func (t *Task) Run(ctx context.Context) error {
 		if err != nil {
 			return liberr.Wrap(err)
 		}
+		err = t.createRsyncFilterConfig()
+		if err != nil {
+			return liberr.Wrap(err)
+		}
 		t.Requeue = NoReQ
 		if err = t.next(); err != nil {
 			return liberr.Wrap(err)
@@ -320,6 +742,38 @@ func (t *Task) Run(ctx context.Context) error {
 		if err = t.next(); err != nil {
 			return liberr.Wrap(err)
 		}
+	case EnsureDestinationPVCsReleased:
+		released, conflicts, err := t.releaseDestinationPVCs()
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		if len(conflicts) > 0 {
+			t.Owner.Status.SetCondition(
+				migapi.Condition{
+					Type:     DestinationPVCsInUse,
+					Status:   True,
+					Reason:   InUse,
+					Category: Critical,
+					Message:  DestinationPVCsInUseMessage,
+					Items:    conflicts,
+					Durable:  true,
+				},
+			)
+			t.fail(MigrationFailed, []string{fmt.Sprintf("Destination PVC(s) are mounted by conflicting Pod(s): [%s]",
+				strings.Join(conflicts, ", "))})
+			t.Itinerary = FailedCleanupItinerary
+			t.Requeue = NoReQ
+			return nil
+		}
+		if !released {
+			t.Log.Info("Stale Pods holding the destination PVCs are terminating. Waiting.")
+			t.Requeue = PollReQ
+			break
+		}
+		t.Requeue = NoReQ
+		if err = t.next(); err != nil {
+			return liberr.Wrap(err)
+		}
 	case CreateRsyncTransferPods:
 		err := t.createRsyncTransferPods()
 		if err != nil {
@@ -329,6 +783,33 @@ func (t *Task) Run(ctx context.Context) error {
 		if err = t.next(); err != nil {
 			return liberr.Wrap(err)
 		}
+	case EnsureRsyncSecretsExist:
+		missing, err := t.getMissingRsyncSecrets()
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		if len(missing) > 0 {
+			t.Owner.Status.SetCondition(
+				migapi.Condition{
+					Type:     RsyncSecretsNotFound,
+					Status:   True,
+					Reason:   NotFound,
+					Category: Critical,
+					Message:  RsyncSecretsNotFoundMessage,
+					Items:    missing,
+					Durable:  true,
+				},
+			)
+			t.fail(MigrationFailed, []string{fmt.Sprintf("Secret(s) mounted by the Rsync Pods were not found: [%s]",
+				strings.Join(missing, ", "))})
+			t.Itinerary = FailedCleanupItinerary
+			t.Requeue = NoReQ
+			return nil
+		}
+		t.Requeue = NoReQ
+		if err = t.next(); err != nil {
+			return liberr.Wrap(err)
+		}
 	case WaitForRsyncTransferPodsRunning:
 		running, nonRunningPods, err := t.areRsyncTransferPodsRunning()
 		if err != nil {
@@ -379,8 +860,170 @@ func (t *Task) Run(ctx context.Context) error {
 				)
 			}
 		}
-	case RunRsyncOperations:
-		allCompleted, anyFailed, failureReasons, err := t.runRsyncOperations()
+	case ExpandDestinationPVCs:
+		if !t.Owner.Spec.ExpandDestinationPVCs {
+			t.Requeue = NoReQ
+			if err = t.next(); err != nil {
+				return liberr.Wrap(err)
+			}
+			break
+		}
+		expanding, notExpandable, err := t.expandDestinationPVCs()
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		if len(notExpandable) > 0 {
+			t.Owner.Status.SetCondition(migapi.Condition{
+				Type:     DestinationPVCsNotExpandable,
+				Status:   True,
+				Reason:   NotSupported,
+				Category: Critical,
+				Message:  DestinationPVCsNotExpandableMessage,
+				Items:    notExpandable,
+				Durable:  true,
+			})
+			t.fail(MigrationFailed, []string{fmt.Sprintf("%s: [%s]",
+				DestinationPVCsNotExpandableMessage, strings.Join(notExpandable, ", "))})
+			break
+		}
+		if len(expanding) == 0 {
+			t.Requeue = NoReQ
+			if err = t.next(); err != nil {
+				return liberr.Wrap(err)
+			}
+			break
+		}
+		t.Log.Info("Some destination PVCs are being expanded. Waiting.",
+			"expandingPersistentVolumeClaims", expanding)
+		t.Requeue = PollReQ
+		t.Owner.Status.StageCondition(Running)
+		cond := t.Owner.Status.FindCondition(Running)
+		if cond == nil {
+			return fmt.Errorf("'Running' condition not found on DVM [%v/%v]", t.Owner.Namespace, t.Owner.Name)
+		}
+		if time.Now().UTC().Sub(cond.LastTransitionTime.Time.UTC()) > DestinationPVCExpansionTimeout {
+			msg := fmt.Sprintf("Destination PVC(s) failed to expand within %v", DestinationPVCExpansionTimeout)
+			t.fail(MigrationFailed, []string{fmt.Sprintf("%s: [%s]", msg, strings.Join(expanding, ", "))})
+			break
+		}
+		t.Owner.Status.SetCondition(migapi.Condition{
+			Type:     DestinationPVCsExpanding,
+			Status:   True,
+			Reason:   migapi.NotReady,
+			Category: Advisory,
+			Message:  fmt.Sprintf(DestinationPVCsExpandingMessage, DestinationPVCExpansionTimeout),
+			Items:    expanding,
+		})
+	case PrewarmDestinationPVCs:
+		if !t.Owner.Spec.PrewarmDestinationPVCs {
+			t.Requeue = NoReQ
+			if err = t.next(); err != nil {
+				return liberr.Wrap(err)
+			}
+			break
+		}
+		completed, failedPVCs, err := t.prewarmDestinationPVCs()
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		if !completed {
+			t.Requeue = PollReQ
+			break
+		}
+		// prewarming is an optimization, PVCs failing to be prewarmed don't fail the migration
+		if len(failedPVCs) > 0 {
+			t.Owner.Status.SetCondition(migapi.Condition{
+				Type:     PrewarmFailed,
+				Status:   True,
+				Reason:   NotReady,
+				Category: Warn,
+				Message:  PrewarmFailedMessage,
+				Items:    failedPVCs,
+				Durable:  true,
+			})
+		}
+		t.Requeue = NoReQ
+		if err = t.next(); err != nil {
+			return liberr.Wrap(err)
+		}
+	case RunPreTransferHooks, RunPostTransferHooks:
+		hookPhase := migapi.PreTransferHookPhase
+		if t.Phase == RunPostTransferHooks {
+			hookPhase = migapi.PostTransferHookPhase
+		}
+		completed, failureReason, err := t.runHooks(hookPhase)
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		if failureReason != "" {
+			t.failHook(hookPhase, failureReason)
+			return nil
+		}
+		if completed {
+			t.Requeue = NoReQ
+			if err = t.next(); err != nil {
+				return liberr.Wrap(err)
+			}
+		} else {
+			t.Requeue = PollReQ
+		}
+	case VerifyDestinationConfigReferences:
+		if t.Owner.Spec.VerifyConfigReferences {
+			err = t.setDestinationConfigMissing()
+			if err != nil {
+				return liberr.Wrap(err)
+			}
+		}
+		t.Requeue = NoReQ
+		if err = t.next(); err != nil {
+			return liberr.Wrap(err)
+		}
+	case RecordCheckpoints:
+		err = t.recordCheckpoints()
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		t.Requeue = NoReQ
+		if err = t.next(); err != nil {
+			return liberr.Wrap(err)
+		}
+	case PrepareTransfer:
+		engine, err := t.getTransferEngine()
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		ready, err := engine.Prepare()
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		if !ready {
+			t.Log.Info("Resources of the transfer engine are not ready. Waiting.", "transferEngine", t.getTransferEngineName())
+			t.Requeue = PollReQ
+			break
+		}
+		t.Requeue = NoReQ
+		if err = t.next(); err != nil {
+			return liberr.Wrap(err)
+		}
+	case RunRsyncOperations, RunTransfer:
+		engine, err := t.getTransferEngine()
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		reachable, err := t.checkSourceClusterReachable()
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		if !reachable {
+			return nil
+		}
+		if t.Phase == RunRsyncOperations {
+			// best effort, the metrics API isn't served without a metrics-server
+			if err := t.sampleRsyncPodResourceUsage(); err != nil {
+				t.Log.V(4).Info("Failed to sample the resource usage of the Rsync Pods.", "error", err.Error())
+			}
+		}
+		allCompleted, anyFailed, failureReasons, err := engine.Run()
 		if err != nil {
 			return liberr.Wrap(err)
 		}
@@ -388,6 +1031,10 @@ func (t *Task) Run(ctx context.Context) error {
 		if allCompleted {
 			t.Requeue = NoReQ
 			if anyFailed {
+				if full := t.Owner.Status.FindCondition(DestinationVolumeFull); full != nil {
+					t.addErrors(failureReasons)
+					return liberr.Wrap(&DestinationFullError{PVCs: full.Items})
+				}
 				t.fail(MigrationFailed, failureReasons)
 				return nil
 			}
@@ -395,6 +1042,40 @@ func (t *Task) Run(ctx context.Context) error {
 				return liberr.Wrap(err)
 			}
 		}
+	case PlanTransfer:
+		err := t.planTransfer()
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		t.Requeue = NoReQ
+		if err = t.next(); err != nil {
+			return liberr.Wrap(err)
+		}
+	case RunSpeedTest:
+		completed, err := t.runSpeedTest()
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		t.Requeue = PollReQ
+		if completed {
+			t.Requeue = NoReQ
+			if err = t.next(); err != nil {
+				return liberr.Wrap(err)
+			}
+		}
+	case CollectVerificationResults, VerifyTransfer:
+		engine, err := t.getTransferEngine()
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		err = engine.Verify()
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		t.Requeue = NoReQ
+		if err = t.next(); err != nil {
+			return liberr.Wrap(err)
+		}
 	case CreatePVProgressCRs:
 		err := t.createPVProgressCR()
 		if err != nil {
@@ -404,6 +1085,15 @@ func (t *Task) Run(ctx context.Context) error {
 		if err = t.next(); err != nil {
 			return liberr.Wrap(err)
 		}
+	case VerifyData:
+		err := t.verifyTransferredData()
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		t.Requeue = NoReQ
+		if err = t.next(); err != nil {
+			return liberr.Wrap(err)
+		}
 	case DeleteRsyncResources:
 		err := t.deleteRsyncResources()
 		if err != nil {
@@ -413,11 +1103,26 @@ func (t *Task) Run(ctx context.Context) error {
 		if err = t.next(); err != nil {
 			return liberr.Wrap(err)
 		}
+	case DeleteHookJobs:
+		err := t.deleteHookJobs()
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		t.Requeue = NoReQ
+		if err = t.next(); err != nil {
+			return liberr.Wrap(err)
+		}
 	case WaitForStaleRsyncResourcesTerminated, WaitForRsyncResourcesTerminated:
 		err, deleted := t.waitForRsyncResourcesDeleted()
 		if err != nil {
 			return liberr.Wrap(err)
 		}
+		if deleted {
+			deleted, err = t.cleanupTransferEngine()
+			if err != nil {
+				return liberr.Wrap(err)
+			}
+		}
 		if deleted {
 			t.Requeue = NoReQ
 			if err = t.next(); err != nil {
@@ -426,7 +1131,7 @@ func (t *Task) Run(ctx context.Context) error {
 		}
 		t.Log.Info("Stale Rsync resources are still terminating. Waiting.")
 		t.Requeue = PollReQ
-	case Completed:
+	case Completed, Canceled:
 	default:
 		t.Requeue = NoReQ
 		if err = t.next(); err != nil {
@@ -439,6 +1144,11 @@ func (t *Task) Run(ctx context.Context) error {
 		t.Log.Info("[COMPLETED]")
 	}
 
+	if t.Phase == Canceled {
+		t.Requeue = NoReQ
+		t.Log.Info("[CANCELED]")
+	}
+
 	return nil
 }
 
@@ -491,6 +1201,240 @@ func (t *Task) fail(nextPhase string, reasons []string) {
 	t.Phase = nextPhase
 }
 
+// Handle a request to recreate the rsync transfer endpoint made through the
+// recreate-rsync-endpoint annotation. The endpoint resources are deleted on the
+// destination and the task is moved back to CreateRsyncRoute. The annotation is
+// cleared once the endpoint is gone.
+// Returns true when the current phase must not be run.
+func (t *Task) recreateRsyncTransferEndpoint() (bool, error) {
+	if _, found := t.Owner.Annotations[migapi.RecreateRsyncEndpointAnnotation]; !found {
+		return false, nil
+	}
+	if !t.hasRsyncTransferEndpoint() {
+		t.Log.Info("Ignoring request to recreate Rsync transfer endpoint, no endpoint is in use in this phase.")
+		delete(t.Owner.Annotations, migapi.RecreateRsyncEndpointAnnotation)
+		return false, nil
+	}
+	deleted, err := t.deleteRsyncTransferEndpoint()
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	if !deleted {
+		t.Log.Info("Waiting for Rsync transfer endpoint resources to terminate.")
+		t.Requeue = PollReQ
+		return true, nil
+	}
+	t.Log.Info("Rsync transfer endpoint deleted, recreating.")
+	delete(t.Owner.Annotations, migapi.RecreateRsyncEndpointAnnotation)
+	t.skipSucceededRsyncOperations()
+	t.Phase = CreateRsyncRoute
+	t.PhaseDescription = phaseDescriptions[t.Phase]
+	t.Requeue = NoReQ
+	return true, nil
+}
+
+// Handle a request to stop the rsync transfer made through the stop-rsync-transfer
+// annotation. The Rsync client Pods are deleted on the source while the transfer
+// endpoint is kept, the failed Rsync operations are reset and the migration is held
+// in RunRsyncOperations. Once the annotation is removed, the transfer of the PVCs
+// not transferred yet is retried through the existing endpoint.
+// Returns true when the current phase must not be run.
+func (t *Task) stopRsyncTransfer() (bool, error) {
+	if _, found := t.Owner.Annotations[migapi.StopRsyncTransferAnnotation]; !found {
+		return false, nil
+	}
+	if t.Phase != RunRsyncOperations {
+		return false, nil
+	}
+	srcClient, err := t.getSourceClient()
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	return t.holdRsyncTransfer(srcClient)
+}
+
+// Hold the transfer stopped with the stop-rsync-transfer annotation, deleting
+// the Rsync client Pods on the source and resetting the failed Rsync operations
+// once the Pods are gone.
+func (t *Task) holdRsyncTransfer(srcClient compat.Client) (bool, error) {
+	deleted, err := t.deleteRsyncClientPods(srcClient)
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	if !deleted {
+		t.Log.Info("Waiting for Rsync client Pods to terminate before holding the transfer.")
+		t.Requeue = PollReQ
+		return true, nil
+	}
+	for _, operation := range t.Owner.Status.RsyncOperations {
+		if !operation.Succeeded {
+			operation.Failed = false
+			operation.CurrentAttempt = 0
+		}
+	}
+	t.skipSucceededRsyncOperations()
+	t.Owner.Status.SetCondition(migapi.Condition{
+		Type:     RsyncTransferStopped,
+		Status:   True,
+		Reason:   t.Phase,
+		Category: Advisory,
+		Message:  RsyncTransferStoppedMessage,
+	})
+	t.Requeue = NoReQ
+	return true, nil
+}
+
+// Get whether the rsync transfer endpoint exists in the current phase.
+func (t *Task) hasRsyncTransferEndpoint() bool {
+	if !t.isRsyncTransferEngine() {
+		return false
+	}
+	switch t.Phase {
+	case EnsureRsyncRouteAdmitted,
+		CreateRsyncConfig,
+		CreateStunnelConfig,
+		CreatePVProgressCRs,
+		EnsureDestinationPVCsReleased,
+		EnsureRsyncSecretsExist,
+		CreateRsyncTransferPods,
+		WaitForRsyncTransferPodsRunning,
+		RunPreTransferHooks,
+		RunRsyncOperations:
+		return true
+	}
+	return false
+}
+
+// Get whether the migration has been running for longer than its deadline.
+func (t *Task) hasDeadlineExceeded() bool {
+	if t.Owner.Spec.Deadline == nil || t.Owner.Status.StartTimestamp == nil {
+		return false
+	}
+	if t.Phase == Completed || t.failed() || t.canceled() {
+		return false
+	}
+	return time.Since(t.Owner.Status.StartTimestamp.Time) > t.Owner.Spec.Deadline.Duration
+}
+
+// Fail the migration because of an exceeded deadline. The Rsync resources
+// are cleaned up by the failed itinerary.
+func (t *Task) failDeadlineExceeded() {
+	msg := fmt.Sprintf("The migration did not complete within its deadline of %v", t.Owner.Spec.Deadline.Duration)
+	t.Log.Info(msg)
+	t.Owner.Status.SetCondition(migapi.Condition{
+		Type:     DeadlineExceeded,
+		Status:   True,
+		Reason:   t.Phase,
+		Category: Warn,
+		Message:  msg,
+		Durable:  true,
+	})
+	t.fail(MigrationFailed, []string{msg})
+	t.Itinerary = FailedCleanupItinerary
+	t.PhaseDescription = phaseDescriptions[t.Phase]
+	t.Requeue = NoReQ
+}
+
+// Fail the migration because the routers of the destination cluster rejected
+// Rsync transfer Routes, e.g. for a host claimed by another Route or a domain
+// not allowed. The Rsync resources are cleaned up by the failed itinerary.
+func (t *Task) failRsyncRoutesRejected(rejected []string) {
+	t.Log.Info("Rsync Transfer Routes have been rejected on destination cluster.", "routes", rejected)
+	t.Owner.Status.SetCondition(migapi.Condition{
+		Type:     RsyncRouteRejected,
+		Status:   True,
+		Reason:   Rejected,
+		Category: Critical,
+		Message:  RsyncRouteRejectedMessage,
+		Items:    rejected,
+		Durable:  true,
+	})
+	t.fail(MigrationFailed, []string{fmt.Sprintf("Rsync Transfer Routes rejected: %s", strings.Join(rejected, ", "))})
+	t.Itinerary = FailedCleanupItinerary
+	t.PhaseDescription = phaseDescriptions[t.Phase]
+	t.Requeue = NoReQ
+}
+
+// Get whether the MigMigration owning the DVM was deleted while the DVM
+// was still running. DVMs created without an owner are never orphaned.
+func (t *Task) isOrphaned() (bool, error) {
+	if len(t.Owner.OwnerReferences) == 0 || t.Phase == Completed || t.canceled() {
+		return false, nil
+	}
+	migration, err := t.getOwnerMigration()
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	return migration == nil, nil
+}
+
+// Get the MigMigration owning the migration, nil when not found. The MigMigration
+// is fetched once per reconcile. Failing to fetch it is retryable, the migration
+// is not failed by an unavailable API server.
+func (t *Task) getOwnerMigration() (*migapi.MigMigration, error) {
+	if t.ownerMigrationFetched {
+		return t.ownerMigration, nil
+	}
+	migration, err := t.Owner.GetMigrationForDVM(t.Client)
+	if err != nil {
+		return nil, &OwnerMigrationUnavailableError{Reason: err.Error()}
+	}
+	t.ownerMigration = migration
+	t.ownerMigrationFetched = true
+	return migration, nil
+}
+
+// Cancel the orphaned migration. The Rsync resources are cleaned up
+// by the canceled itinerary.
+func (t *Task) cancelOrphaned() {
+	t.cancel(OwnerNotFound, "The MigMigration owning the migration was deleted, the migration is canceled.")
+}
+
+// Get whether the MigMigration owning the migration requests all of its DVMs
+// to be canceled with the cancel-direct-volume-migrations annotation.
+func (t *Task) isCancelRequested() (bool, error) {
+	if len(t.Owner.OwnerReferences) == 0 || t.Phase == Completed || t.canceled() {
+		return false, nil
+	}
+	migration, err := t.getOwnerMigration()
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	if migration == nil {
+		return false, nil
+	}
+	_, found := migration.Annotations[migapi.CancelDirectVolumeMigrationsAnnotation]
+	return found, nil
+}
+
+// Cancel the migration as requested by the MigMigration owning it. The Rsync
+// resources are cleaned up by the canceled itinerary.
+func (t *Task) cancelRequested() {
+	t.cancel(CancelRequested, "The MigMigration owning the migration requested its DVMs to be canceled, the migration is canceled.")
+}
+
+// Cancel the migration with a durable condition of the type telling why.
+func (t *Task) cancel(conditionType string, msg string) {
+	t.Log.Info(msg)
+	t.Owner.Status.SetCondition(migapi.Condition{
+		Type:     conditionType,
+		Status:   True,
+		Reason:   t.Phase,
+		Category: Warn,
+		Message:  msg,
+		Durable:  true,
+	})
+	t.Itinerary = CanceledItinerary
+	t.Phase = CanceledItinerary.Steps[0].phase
+	t.PhaseDescription = phaseDescriptions[t.Phase]
+	t.Requeue = NoReQ
+}
+
+// Get whether the migration was canceled.
+func (t *Task) canceled() bool {
+	return t.Owner.Status.HasAnyCondition(OwnerNotFound, CancelRequested)
+}
+
 // Add errors.
 func (t *Task) addErrors(errors []string) {
 	for _, error := range errors {
@@ -513,6 +1457,9 @@ func (t *Task) getSourceClient() (compat.Client, error) {
 	if err != nil {
 		return nil, err
 	}
+	if settings.Settings.DvmOpts.APICallMetrics {
+		client = countRemoteAPICalls(client, t.Phase, SourceCluster)
+	}
 	return client, nil
 }
 
@@ -526,6 +1473,9 @@ func (t *Task) getDestinationClient() (compat.Client, error) {
 	if err != nil {
 		return nil, err
 	}
+	if settings.Settings.DvmOpts.APICallMetrics {
+		client = countRemoteAPICalls(client, t.Phase, DestinationCluster)
+	}
 	return client, nil
 }
 
@@ -536,6 +1486,7 @@ func (t *Task) buildDVMLabels() map[string]string {
 	dvmLabels["app"] = DirectVolumeMigrationRsyncTransfer
 	dvmLabels["owner"] = DirectVolumeMigration
 	dvmLabels[migapi.PartOfLabel] = migapi.Application
+	dvmLabels[RsyncTransferGenerationLabel] = string(t.Owner.UID)
 	// Label resources for rollback targeting
 	if t.PlanResources != nil {
 		if t.PlanResources.MigPlan != nil {
@@ -0,0 +1,447 @@
+package directvolumemigration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	fakecompat "github.com/konveyor/mig-controller/pkg/compat/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestTask_hasDeadlineExceeded(t *testing.T) {
+	tests := []struct {
+		name     string
+		phase    string
+		deadline *metav1.Duration
+		started  *metav1.Time
+		want     bool
+	}{
+		{
+			name:     "when deadline is not set, should not be exceeded",
+			phase:    RunRsyncOperations,
+			deadline: nil,
+			started:  &metav1.Time{Time: time.Now().Add(-10 * time.Hour)},
+			want:     false,
+		},
+		{
+			name:     "when migration is running for less than the deadline, should not be exceeded",
+			phase:    RunRsyncOperations,
+			deadline: &metav1.Duration{Duration: time.Hour},
+			started:  &metav1.Time{Time: time.Now().Add(-10 * time.Minute)},
+			want:     false,
+		},
+		{
+			name:     "when migration is running for longer than the deadline, should be exceeded",
+			phase:    RunRsyncOperations,
+			deadline: &metav1.Duration{Duration: time.Hour},
+			started:  &metav1.Time{Time: time.Now().Add(-2 * time.Hour)},
+			want:     true,
+		},
+		{
+			name:     "when migration is completed, should not be exceeded",
+			phase:    Completed,
+			deadline: &metav1.Duration{Duration: time.Hour},
+			started:  &metav1.Time{Time: time.Now().Add(-2 * time.Hour)},
+			want:     false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &Task{
+				Log:   log.WithName("test-logger"),
+				Phase: tt.phase,
+				Owner: &migapi.DirectVolumeMigration{
+					Spec: migapi.DirectVolumeMigrationSpec{
+						Deadline: tt.deadline,
+					},
+					Status: migapi.DirectVolumeMigrationStatus{
+						StartTimestamp: tt.started,
+					},
+				},
+			}
+			if got := task.hasDeadlineExceeded(); got != tt.want {
+				t.Errorf("Task.hasDeadlineExceeded() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTask_failDeadlineExceeded(t *testing.T) {
+	task := &Task{
+		Log:   log.WithName("test-logger"),
+		Phase: RunRsyncOperations,
+		Owner: &migapi.DirectVolumeMigration{
+			Spec: migapi.DirectVolumeMigrationSpec{
+				Deadline: &metav1.Duration{Duration: time.Hour},
+			},
+		},
+	}
+	task.failDeadlineExceeded()
+	if task.Phase != MigrationFailed {
+		t.Errorf("Task.failDeadlineExceeded() phase = %v, want %v", task.Phase, MigrationFailed)
+	}
+	if !task.Owner.Status.HasCondition(DeadlineExceeded) {
+		t.Errorf("Task.failDeadlineExceeded() didn't find expected condition of type %s", DeadlineExceeded)
+	}
+	if err := task.next(); err != nil || task.Phase != DeleteRsyncResources {
+		t.Errorf("Task.next() after deadline failure phase = %v, want %v", task.Phase, DeleteRsyncResources)
+	}
+}
+
+func TestTask_isOrphaned(t *testing.T) {
+	migration := &migapi.MigMigration{
+		ObjectMeta: metav1.ObjectMeta{Name: "migration", Namespace: migapi.OpenshiftMigrationNamespace, UID: "migration-uid"},
+	}
+	owners := []metav1.OwnerReference{{Kind: "MigMigration", Name: "migration", UID: "migration-uid"}}
+	tests := []struct {
+		name    string
+		phase   string
+		owners  []metav1.OwnerReference
+		objects []runtime.Object
+		want    bool
+	}{
+		{
+			name:    "when DVM has no owner, should not be orphaned",
+			phase:   RunRsyncOperations,
+			owners:  nil,
+			objects: []runtime.Object{},
+			want:    false,
+		},
+		{
+			name:    "when owning MigMigration exists, should not be orphaned",
+			phase:   RunRsyncOperations,
+			owners:  owners,
+			objects: []runtime.Object{migration},
+			want:    false,
+		},
+		{
+			name:    "when owning MigMigration is deleted, should be orphaned",
+			phase:   RunRsyncOperations,
+			owners:  owners,
+			objects: []runtime.Object{},
+			want:    true,
+		},
+		{
+			name:    "when owning MigMigration is deleted after completion, should not be orphaned",
+			phase:   Completed,
+			owners:  owners,
+			objects: []runtime.Object{},
+			want:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &Task{
+				Log:    log.WithName("test-logger"),
+				Client: fake.NewFakeClient(tt.objects...),
+				Phase:  tt.phase,
+				Owner: &migapi.DirectVolumeMigration{
+					ObjectMeta: metav1.ObjectMeta{Name: "dvm", Namespace: migapi.OpenshiftMigrationNamespace, OwnerReferences: tt.owners},
+				},
+			}
+			got, err := task.isOrphaned()
+			if err != nil {
+				t.Errorf("Task.isOrphaned() unexpected error = %v", err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Task.isOrphaned() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTask_cancelOrphaned(t *testing.T) {
+	task := &Task{
+		Log:   log.WithName("test-logger"),
+		Phase: RunRsyncOperations,
+		Owner: &migapi.DirectVolumeMigration{},
+	}
+	task.cancelOrphaned()
+	if task.Phase != DeleteRsyncResources {
+		t.Errorf("Task.cancelOrphaned() phase = %v, want %v", task.Phase, DeleteRsyncResources)
+	}
+	if !task.Owner.Status.HasCondition(OwnerNotFound) {
+		t.Errorf("Task.cancelOrphaned() didn't find expected condition of type %s", OwnerNotFound)
+	}
+	if err := task.init(); err != nil || task.Itinerary.Name != CanceledItinerary.Name {
+		t.Errorf("Task.init() after cancel itinerary = %v, want %v", task.Itinerary.Name, CanceledItinerary.Name)
+	}
+	task.Phase = WaitForRsyncResourcesTerminated
+	if err := task.next(); err != nil || task.Phase != Canceled {
+		t.Errorf("Task.next() after cleanup phase = %v, want %v", task.Phase, Canceled)
+	}
+}
+
+func TestTask_isCancelRequested(t *testing.T) {
+	owners := []metav1.OwnerReference{{Kind: "MigMigration", Name: "migration", UID: "migration-uid"}}
+	newMigration := func(annotations map[string]string) *migapi.MigMigration {
+		return &migapi.MigMigration{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "migration",
+				Namespace:   migapi.OpenshiftMigrationNamespace,
+				UID:         "migration-uid",
+				Annotations: annotations,
+			},
+		}
+	}
+	cancel := map[string]string{migapi.CancelDirectVolumeMigrationsAnnotation: "true"}
+	tests := []struct {
+		name    string
+		phase   string
+		objects []runtime.Object
+		want    bool
+	}{
+		{
+			name:    "when owning MigMigration isn't annotated, should not be canceled",
+			phase:   RunRsyncOperations,
+			objects: []runtime.Object{newMigration(nil)},
+			want:    false,
+		},
+		{
+			name:    "when owning MigMigration is annotated, should be canceled",
+			phase:   RunRsyncOperations,
+			objects: []runtime.Object{newMigration(cancel)},
+			want:    true,
+		},
+		{
+			name:    "when owning MigMigration is annotated after completion, should not be canceled",
+			phase:   Completed,
+			objects: []runtime.Object{newMigration(cancel)},
+			want:    false,
+		},
+		{
+			name:    "when owning MigMigration is deleted, should not be canceled by request",
+			phase:   RunRsyncOperations,
+			objects: []runtime.Object{},
+			want:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &Task{
+				Log:    log.WithName("test-logger"),
+				Client: fake.NewFakeClient(tt.objects...),
+				Phase:  tt.phase,
+				Owner: &migapi.DirectVolumeMigration{
+					ObjectMeta: metav1.ObjectMeta{Name: "dvm", Namespace: migapi.OpenshiftMigrationNamespace, OwnerReferences: owners},
+				},
+			}
+			got, err := task.isCancelRequested()
+			if err != nil {
+				t.Errorf("Task.isCancelRequested() unexpected error = %v", err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Task.isCancelRequested() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTask_cancelRequested(t *testing.T) {
+	task := &Task{
+		Log:   log.WithName("test-logger"),
+		Phase: RunRsyncOperations,
+		Owner: &migapi.DirectVolumeMigration{},
+	}
+	task.cancelRequested()
+	if task.Phase != DeleteRsyncResources {
+		t.Errorf("Task.cancelRequested() phase = %v, want %v", task.Phase, DeleteRsyncResources)
+	}
+	if !task.Owner.Status.HasCondition(CancelRequested) {
+		t.Errorf("Task.cancelRequested() didn't find expected condition of type %s", CancelRequested)
+	}
+	if err := task.init(); err != nil || task.Itinerary.Name != CanceledItinerary.Name {
+		t.Errorf("Task.init() after cancel itinerary = %v, want %v", task.Itinerary.Name, CanceledItinerary.Name)
+	}
+}
+
+func TestTask_stopRsyncTransfer(t *testing.T) {
+	tests := []struct {
+		name        string
+		phase       string
+		annotations map[string]string
+		wantHandled bool
+	}{
+		{
+			name:        "when the transfer is not requested to stop, should run the phase",
+			phase:       RunRsyncOperations,
+			annotations: map[string]string{},
+			wantHandled: false,
+		},
+		{
+			name:        "when the transfer is requested to stop before it runs, should run the phase",
+			phase:       WaitForRsyncTransferPodsRunning,
+			annotations: map[string]string{migapi.StopRsyncTransferAnnotation: "true"},
+			wantHandled: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &Task{
+				Log:   log.WithName("test-logger"),
+				Phase: tt.phase,
+				Owner: &migapi.DirectVolumeMigration{
+					ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+				},
+			}
+			handled, err := task.stopRsyncTransfer()
+			if err != nil || handled != tt.wantHandled {
+				t.Errorf("Task.stopRsyncTransfer() = %v, %v, want %v", handled, err, tt.wantHandled)
+			}
+			if task.Owner.Status.HasCondition(RsyncTransferStopped) {
+				t.Errorf("Task.stopRsyncTransfer() unexpected condition of type %s", RsyncTransferStopped)
+			}
+		})
+	}
+}
+
+func TestTask_holdRsyncTransfer(t *testing.T) {
+	clientPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rsync-client",
+			Namespace: "ns",
+			Labels: map[string]string{
+				"app":                   DirectVolumeMigrationRsyncTransfer,
+				"directvolumemigration": DirectVolumeMigrationRsyncClient,
+			},
+		},
+	}
+	srcClient := fakecompat.NewFakeClient(clientPod)
+	failed := &migapi.RsyncOperation{
+		PVCReference:   &corev1.ObjectReference{Namespace: "ns", Name: "pvc-1"},
+		Failed:         true,
+		CurrentAttempt: 3,
+	}
+	succeeded := &migapi.RsyncOperation{
+		PVCReference: &corev1.ObjectReference{Namespace: "ns", Name: "pvc-2"},
+		Succeeded:    true,
+	}
+	task := &Task{
+		Log:   log.WithName("test-logger"),
+		Phase: RunRsyncOperations,
+		Owner: &migapi.DirectVolumeMigration{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{migapi.StopRsyncTransferAnnotation: "true"},
+			},
+			Spec: migapi.DirectVolumeMigrationSpec{
+				PersistentVolumeClaims: []migapi.PVCToMigrate{
+					{ObjectReference: &corev1.ObjectReference{Namespace: "ns", Name: "pvc-1"}},
+					{ObjectReference: &corev1.ObjectReference{Namespace: "ns", Name: "pvc-2"}},
+				},
+			},
+			Status: migapi.DirectVolumeMigrationStatus{
+				RsyncOperations: []*migapi.RsyncOperation{failed, succeeded},
+			},
+		},
+	}
+
+	// the Rsync client Pods are deleted first
+	handled, err := task.holdRsyncTransfer(srcClient)
+	if err != nil || !handled || task.Requeue != PollReQ {
+		t.Fatalf("Task.holdRsyncTransfer() = %v, %v, requeue %v, want the phase held while the Pods terminate", handled, err, task.Requeue)
+	}
+	pods := corev1.PodList{}
+	if err := srcClient.List(context.TODO(), &pods); err != nil || len(pods.Items) != 0 {
+		t.Errorf("Task.holdRsyncTransfer() left Rsync client Pods = %v, %v", pods.Items, err)
+	}
+	if task.Owner.Status.HasCondition(RsyncTransferStopped) || !failed.Failed {
+		t.Errorf("Task.holdRsyncTransfer() reported the transfer stopped before the Rsync client Pods are gone")
+	}
+
+	// once the Pods are gone, the failed operations are reset and the transfer is reported stopped
+	handled, err = task.holdRsyncTransfer(srcClient)
+	if err != nil || !handled || task.Requeue != NoReQ {
+		t.Fatalf("Task.holdRsyncTransfer() = %v, %v, requeue %v, want the phase held", handled, err, task.Requeue)
+	}
+	if failed.Failed || failed.CurrentAttempt != 0 {
+		t.Errorf("Task.holdRsyncTransfer() failed operation = %+v, want it reset", failed)
+	}
+	if !succeeded.Succeeded || !succeeded.Skipped {
+		t.Errorf("Task.holdRsyncTransfer() succeeded operation = %+v, want it skipped", succeeded)
+	}
+	if !task.Owner.Status.HasCondition(RsyncTransferStopped) {
+		t.Errorf("Task.holdRsyncTransfer() didn't find expected condition of type %s", RsyncTransferStopped)
+	}
+
+	// the transfer resumes once the annotation is removed
+	delete(task.Owner.Annotations, migapi.StopRsyncTransferAnnotation)
+	task.Owner.Status.BeginStagingConditions()
+	handled, err = task.stopRsyncTransfer()
+	task.Owner.Status.EndStagingConditions()
+	if err != nil || handled {
+		t.Errorf("Task.stopRsyncTransfer() = %v, %v, want the phase run once the annotation is removed", handled, err)
+	}
+	if task.Owner.Status.HasCondition(RsyncTransferStopped) {
+		t.Errorf("Task.stopRsyncTransfer() kept the %s condition once the annotation is removed", RsyncTransferStopped)
+	}
+}
+
+func TestItinerary_stepPhases(t *testing.T) {
+	for _, itinerary := range []Itinerary{VolumeMigration, EngineMigration, PreviewMigration, FailedCleanupItinerary} {
+		t.Run(itinerary.Name, func(t *testing.T) {
+			phases := itinerary.stepPhases()
+			for i, phase := range phases {
+				if phase == Created {
+					t.Errorf("Itinerary.stepPhases() includes the Created phase")
+				}
+				// the progress report counts the Created step
+				_, n, _ := itinerary.progressReport(phase)
+				if offset := len(itinerary.Steps) - len(phases); n != i+offset+1 {
+					t.Errorf("Itinerary.stepPhases() phase %s at %d, reported as step %d", phase, i, n)
+				}
+			}
+		})
+	}
+}
+
+func TestItinerary_rsyncSecretsCheckedBeforeTransferPods(t *testing.T) {
+	for _, itinerary := range []Itinerary{VolumeMigration, VerifyOnlyMigration, SpeedTestMigration} {
+		t.Run(itinerary.Name, func(t *testing.T) {
+			_, secrets, _ := itinerary.progressReport(EnsureRsyncSecretsExist)
+			_, pods, _ := itinerary.progressReport(CreateRsyncTransferPods)
+			if secrets == 0 || pods == 0 || secrets > pods {
+				t.Errorf("Itinerary %s checks the Rsync secrets at step %d, creates the transfer Pods at step %d",
+					itinerary.Name, secrets, pods)
+			}
+		})
+	}
+}
+
+func TestTask_getOwnerMigration(t *testing.T) {
+	migration := &migapi.MigMigration{
+		ObjectMeta: metav1.ObjectMeta{Name: "migration", Namespace: migapi.OpenshiftMigrationNamespace, UID: "migration-uid"},
+	}
+	owner := &migapi.DirectVolumeMigration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "dvm",
+			Namespace:       migapi.OpenshiftMigrationNamespace,
+			OwnerReferences: []metav1.OwnerReference{{Kind: "MigMigration", Name: "migration", UID: "migration-uid"}},
+		},
+	}
+	client := fake.NewFakeClient(migration)
+	task := &Task{Log: log.WithName("test-logger"), Client: client, Owner: owner, Phase: RunRsyncOperations}
+	got, err := task.getOwnerMigration()
+	if err != nil || got == nil || got.Name != migration.Name {
+		t.Fatalf("Task.getOwnerMigration() = %v, %v, want %v", got, err, migration.Name)
+	}
+	// fetched once per reconcile, the deletion is seen by the next reconcile
+	if err = client.Delete(context.TODO(), migration); err != nil {
+		t.Fatal(err)
+	}
+	if orphaned, err := task.isOrphaned(); err != nil || orphaned {
+		t.Errorf("Task.isOrphaned() = %v, %v, want the MigMigration fetched by the reconcile", orphaned, err)
+	}
+
+	// an unavailable API server is retried
+	task = &Task{Log: log.WithName("test-logger"), Client: fake.NewFakeClientWithScheme(runtime.NewScheme()), Owner: owner, Phase: RunRsyncOperations}
+	if _, err := task.isCancelRequested(); err == nil || !isRetryableError(err) {
+		t.Errorf("Task.isCancelRequested() error = %v, want a retryable error", err)
+	}
+}
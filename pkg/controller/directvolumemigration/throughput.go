@@ -0,0 +1,46 @@
+package directvolumemigration
+
+import (
+	"fmt"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// ThroughputRegressionRatio fraction of the baseline throughput of the DVM
+// under which the measured transfer rate is reported as a regression.
+const ThroughputRegressionRatio = 0.5
+
+// Get whether the measured transfer rate falls significantly below the
+// baseline throughput in MB/s, powers of 1024 as printed by rsync.
+func isBelowBaselineThroughput(rate *resource.Quantity, baseline int) bool {
+	if rate == nil || baseline <= 0 {
+		return false
+	}
+	return float64(rate.Value()) < ThroughputRegressionRatio*float64(baseline)*transferRateUnits["M"]
+}
+
+// Warn with the ThroughputBelowBaseline condition while the smoothed transfer
+// rate of the running Rsync Pods falls below the baseline throughput of the
+// DVM. The condition is purely informational, the migration proceeds.
+func (t *Task) setThroughputBelowBaseline() {
+	baseline := t.Owner.Spec.BaselineThroughput
+	status := t.Owner.Status
+	if baseline == nil || len(status.RunningPods) == 0 {
+		return
+	}
+	if !isBelowBaselineThroughput(status.SmoothedTransferRate, *baseline) {
+		return
+	}
+	rate := float64(status.SmoothedTransferRate.Value()) / transferRateUnits["M"]
+	t.Log.Info("Transfer rate is below the baseline throughput.",
+		"transferRateMBps", fmt.Sprintf("%.2f", rate),
+		"baselineThroughputMBps", *baseline)
+	t.Owner.Status.SetCondition(migapi.Condition{
+		Type:     ThroughputBelowBaseline,
+		Status:   True,
+		Reason:   BelowBaseline,
+		Category: Warn,
+		Message:  fmt.Sprintf(ThroughputBelowBaselineMessage, rate, *baseline),
+	})
+}
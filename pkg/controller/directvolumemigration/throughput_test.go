@@ -0,0 +1,64 @@
+package directvolumemigration
+
+import (
+	"testing"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestTask_setThroughputBelowBaseline(t *testing.T) {
+	baseline := 100
+	mib := func(n int64) *resource.Quantity {
+		return resource.NewQuantity(n*1024*1024, resource.BinarySI)
+	}
+	running := []*migapi.PodProgress{{LastObservedTransferRate: "10.00MB/s"}}
+	tests := []struct {
+		name   string
+		spec   migapi.DirectVolumeMigrationSpec
+		status migapi.DirectVolumeMigrationStatus
+		want   bool
+	}{
+		{
+			name:   "when no baseline is set, should not warn",
+			status: migapi.DirectVolumeMigrationStatus{RunningPods: running, SmoothedTransferRate: mib(10)},
+			want:   false,
+		},
+		{
+			name:   "when the rate falls below half of the baseline, should warn",
+			spec:   migapi.DirectVolumeMigrationSpec{BaselineThroughput: &baseline},
+			status: migapi.DirectVolumeMigrationStatus{RunningPods: running, SmoothedTransferRate: mib(10)},
+			want:   true,
+		},
+		{
+			name:   "when the rate is slightly below the baseline, should not warn",
+			spec:   migapi.DirectVolumeMigrationSpec{BaselineThroughput: &baseline},
+			status: migapi.DirectVolumeMigrationStatus{RunningPods: running, SmoothedTransferRate: mib(80)},
+			want:   false,
+		},
+		{
+			name:   "when no Rsync Pod is running, should not warn",
+			spec:   migapi.DirectVolumeMigrationSpec{BaselineThroughput: &baseline},
+			status: migapi.DirectVolumeMigrationStatus{SmoothedTransferRate: mib(10)},
+			want:   false,
+		},
+		{
+			name:   "when no rate was measured, should not warn",
+			spec:   migapi.DirectVolumeMigrationSpec{BaselineThroughput: &baseline},
+			status: migapi.DirectVolumeMigrationStatus{RunningPods: running},
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &Task{
+				Log:   log.WithName("test-logger"),
+				Owner: &migapi.DirectVolumeMigration{Spec: tt.spec, Status: tt.status},
+			}
+			task.setThroughputBelowBaseline()
+			if got := task.Owner.Status.HasCondition(ThroughputBelowBaseline); got != tt.want {
+				t.Errorf("Task.setThroughputBelowBaseline() condition = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -29,21 +29,17 @@ func (r *ReconcileDirectVolumeMigration) initTracer(direct *migapi.DirectVolumeM
 		return nil
 	}
 
-	// Set tracer on reconciler if it's not already present.
+	// Set tracer on reconciler if it's not already present, once as DVMs
+	// may be reconciled concurrently.
 	// We will never close this, so the 'closer' is discarded.
-	if r.tracer == nil {
-		r.tracer, _ = migtrace.InitJaeger("DirectVolumeMigration")
-	}
+	r.tracerOnce.Do(func() {
+		r.tracer, _ = migtrace.InitTracer("DirectVolumeMigration")
+	})
 
 	// Get overall migration span
 	var migrationSpan opentracing.Span
-	ownerRefs := direct.GetOwnerReferences()
-	for _, ownerRef := range ownerRefs {
-		if ownerRef.Kind != "MigMigration" {
-			continue
-		}
-		migrationUID := ownerRef.UID
-		migrationSpan = migtrace.GetSpanForMigrationUID(string(migrationUID))
+	if migrationUID := direct.GetMigrationUID(); migrationUID != "" {
+		migrationSpan = migtrace.GetSpanForMigrationUID(migrationUID)
 	}
 	if migrationSpan == nil {
 		return nil
@@ -0,0 +1,149 @@
+package directvolumemigration
+
+import (
+	"context"
+	"path"
+	"time"
+
+	liberr "github.com/konveyor/controller/pkg/error"
+	"github.com/konveyor/controller/pkg/logging"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"github.com/konveyor/mig-controller/pkg/compat"
+	"github.com/konveyor/mig-controller/pkg/settings"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Get whether the finished DVM failed or was canceled.
+func isFinishedUnsuccessfully(direct *migapi.DirectVolumeMigration) bool {
+	return direct.Status.Phase == Canceled ||
+		direct.HasErrors() ||
+		direct.Status.HasCondition(Failed)
+}
+
+// Get the duration a finished DVM is kept before it is deleted, 0 when kept
+// indefinitely. Failed and canceled DVMs use their own TTL when set, falling
+// back to the TTL of completed DVMs. The spec takes precedence over the settings.
+func getFinishedTTL(direct *migapi.DirectVolumeMigration) time.Duration {
+	if isFinishedUnsuccessfully(direct) {
+		if direct.Spec.TTLAfterFailed != nil {
+			return direct.Spec.TTLAfterFailed.Duration
+		}
+		if settings.Settings.DvmOpts.FailedTTL > 0 {
+			return settings.Settings.DvmOpts.FailedTTL
+		}
+	}
+	if direct.Spec.TTLAfterCompleted != nil {
+		return direct.Spec.TTLAfterCompleted.Duration
+	}
+	return settings.Settings.DvmOpts.CompletedTTL
+}
+
+// Get the time the DVM finished. DVMs finished before the completion
+// timestamp was recorded use the transition of their outcome condition.
+func getFinishedTime(direct *migapi.DirectVolumeMigration) *time.Time {
+	if direct.Status.CompletionTimestamp != nil {
+		return &direct.Status.CompletionTimestamp.Time
+	}
+	for _, cndType := range []string{Succeeded, Failed} {
+		if condition := direct.Status.FindCondition(cndType); condition != nil {
+			return &condition.LastTransitionTime.Time
+		}
+	}
+	return nil
+}
+
+// Delete the finished DVM once its TTL expired, along with the Rsync resources
+// of its transfer left on the clusters. The MigMigration owning the DVM reads
+// its outcome, the DVM is kept until the MigMigration is completed or deleted.
+// Returns the delay before the DVM is checked again, 0 when the DVM is deleted
+// or kept indefinitely.
+func (r *ReconcileDirectVolumeMigration) deleteExpired(log *logging.Logger, direct *migapi.DirectVolumeMigration) (time.Duration, error) {
+	ttl := getFinishedTTL(direct)
+	if ttl <= 0 {
+		return 0, nil
+	}
+	finished := getFinishedTime(direct)
+	if finished == nil {
+		return 0, nil
+	}
+	if remaining := time.Until(finished.Add(ttl)); remaining > 0 {
+		return remaining, nil
+	}
+	migration, err := direct.GetMigrationForDVM(r)
+	if err != nil {
+		return 0, liberr.Wrap(err)
+	}
+	if migration != nil && migration.Status.Phase != Completed {
+		log.Info("Keeping DirectVolumeMigration past its TTL, the MigMigration owning it is not completed.",
+			"dvm", path.Join(direct.Namespace, direct.Name),
+			"migration", path.Join(migration.Namespace, migration.Name))
+		return MaxPollRequeue, nil
+	}
+	task := Task{
+		Log:    log,
+		Client: r,
+		Owner:  direct,
+	}
+	err = task.deleteRsyncTransferGeneration()
+	if err != nil {
+		return 0, liberr.Wrap(err)
+	}
+	log.Info("Deleting DirectVolumeMigration, its TTL expired.",
+		"dvm", path.Join(direct.Namespace, direct.Name),
+		"ttl", ttl.String())
+	// The DVMPs owned by the DVM are garbage collected.
+	err = r.Delete(context.TODO(), direct, k8sclient.PropagationPolicy(metav1.DeletePropagationBackground))
+	if err != nil && !k8serror.IsNotFound(err) {
+		return 0, liberr.Wrap(err)
+	}
+	return 0, nil
+}
+
+// Delete the Rsync resources labeled with the transfer generation of the DVM
+// on the source and destination clusters. Resources of other DVMs in the same
+// namespaces are kept. Clusters that no longer exist are skipped.
+func (t *Task) deleteRsyncTransferGeneration() error {
+	selector := labels.SelectorFromSet(map[string]string{
+		"app":                        DirectVolumeMigrationRsyncTransfer,
+		RsyncTransferGenerationLabel: string(t.Owner.UID),
+	})
+	srcCluster, err := t.Owner.GetSourceCluster(t.Client)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	destCluster, err := t.Owner.GetDestinationCluster(t.Client)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	var srcClient, destClient compat.Client
+	if srcCluster != nil {
+		srcClient, err = srcCluster.GetClient(t.Client)
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+	}
+	if destCluster != nil {
+		destClient, err = destCluster.GetClient(t.Client)
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+	}
+	for bothNs := range t.getPVCNamespaceMap() {
+		if srcClient != nil {
+			err = t.findAndDeleteNsResources(srcClient, getSourceNs(bothNs), selector, nil)
+			if err != nil {
+				return liberr.Wrap(err)
+			}
+		}
+		if destClient != nil {
+			err = t.findAndDeleteNsResources(destClient, getDestNs(bothNs), selector, nil)
+			if err != nil {
+				return liberr.Wrap(err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package directvolumemigration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"github.com/konveyor/mig-controller/pkg/settings"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func Test_getFinishedTTL(t *testing.T) {
+	hour, day := &metav1.Duration{Duration: time.Hour}, &metav1.Duration{Duration: 24 * time.Hour}
+	failed := migapi.DirectVolumeMigrationStatus{Phase: Completed}
+	failed.SetCondition(migapi.Condition{Type: Failed, Status: True, Category: Advisory})
+	tests := []struct {
+		name         string
+		spec         migapi.DirectVolumeMigrationSpec
+		status       migapi.DirectVolumeMigrationStatus
+		completedTTL time.Duration
+		failedTTL    time.Duration
+		want         time.Duration
+	}{
+		{
+			name:   "when no TTL is set, should keep the DVM indefinitely",
+			status: migapi.DirectVolumeMigrationStatus{Phase: Completed},
+			want:   0,
+		},
+		{
+			name:         "when completed with a TTL setting, should use the setting",
+			status:       migapi.DirectVolumeMigrationStatus{Phase: Completed},
+			completedTTL: 2 * time.Hour,
+			want:         2 * time.Hour,
+		},
+		{
+			name:         "when completed with a TTL in the spec, should use the spec over the setting",
+			spec:         migapi.DirectVolumeMigrationSpec{TTLAfterCompleted: hour},
+			status:       migapi.DirectVolumeMigrationStatus{Phase: Completed},
+			completedTTL: 2 * time.Hour,
+			want:         time.Hour,
+		},
+		{
+			name:   "when failed without a failed TTL, should use the completed TTL",
+			spec:   migapi.DirectVolumeMigrationSpec{TTLAfterCompleted: hour},
+			status: failed,
+			want:   time.Hour,
+		},
+		{
+			name:      "when failed with a failed TTL setting, should use the failed TTL",
+			spec:      migapi.DirectVolumeMigrationSpec{TTLAfterCompleted: hour},
+			status:    failed,
+			failedTTL: 48 * time.Hour,
+			want:      48 * time.Hour,
+		},
+		{
+			name:   "when canceled with a failed TTL in the spec, should use the failed TTL",
+			spec:   migapi.DirectVolumeMigrationSpec{TTLAfterCompleted: hour, TTLAfterFailed: day},
+			status: migapi.DirectVolumeMigrationStatus{Phase: Canceled},
+			want:   24 * time.Hour,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings.Settings.DvmOpts.CompletedTTL = tt.completedTTL
+			settings.Settings.DvmOpts.FailedTTL = tt.failedTTL
+			defer func() {
+				settings.Settings.DvmOpts.CompletedTTL = 0
+				settings.Settings.DvmOpts.FailedTTL = 0
+			}()
+			direct := &migapi.DirectVolumeMigration{Spec: tt.spec, Status: tt.status}
+			if got := getFinishedTTL(direct); got != tt.want {
+				t.Errorf("getFinishedTTL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconcileDirectVolumeMigration_deleteExpired(t *testing.T) {
+	tests := []struct {
+		name           string
+		finished       time.Duration
+		migrationPhase string
+		wantDeleted    bool
+	}{
+		{
+			name:        "when the TTL has not expired, should keep the DVM",
+			finished:    10 * time.Minute,
+			wantDeleted: false,
+		},
+		{
+			name:        "when the TTL has expired, should delete the DVM",
+			finished:    2 * time.Hour,
+			wantDeleted: true,
+		},
+		{
+			name:           "when the TTL has expired and the owner migration is not completed, should keep the DVM",
+			finished:       2 * time.Hour,
+			migrationPhase: "WaitForDirectVolumeMigrationToComplete",
+			wantDeleted:    false,
+		},
+		{
+			name:           "when the TTL has expired and the owner migration is completed, should delete the DVM",
+			finished:       2 * time.Hour,
+			migrationPhase: Completed,
+			wantDeleted:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects := []runtime.Object{}
+			owners := []metav1.OwnerReference{}
+			if tt.migrationPhase != "" {
+				migration := &migapi.MigMigration{
+					ObjectMeta: metav1.ObjectMeta{Name: "migration", Namespace: migapi.OpenshiftMigrationNamespace},
+					Status:     migapi.MigMigrationStatus{Phase: tt.migrationPhase},
+				}
+				objects = append(objects, migration)
+				owners = append(owners, metav1.OwnerReference{Kind: "MigMigration", Name: migration.Name})
+			}
+			direct := &migapi.DirectVolumeMigration{
+				ObjectMeta: metav1.ObjectMeta{Name: "dvm", Namespace: migapi.OpenshiftMigrationNamespace, OwnerReferences: owners},
+				Spec:       migapi.DirectVolumeMigrationSpec{TTLAfterCompleted: &metav1.Duration{Duration: time.Hour}},
+				Status: migapi.DirectVolumeMigrationStatus{
+					Phase:               Completed,
+					CompletionTimestamp: &metav1.Time{Time: time.Now().Add(-tt.finished)},
+				},
+			}
+			r := &ReconcileDirectVolumeMigration{Client: fake.NewFakeClient(append(objects, direct)...)}
+			requeueAfter, err := r.deleteExpired(log, direct)
+			if err != nil {
+				t.Fatalf("deleteExpired() unexpected error = %v", err)
+			}
+			if !tt.wantDeleted && requeueAfter <= 0 {
+				t.Errorf("deleteExpired() requeueAfter = %v, want the time left before the TTL expires", requeueAfter)
+			}
+			err = r.Get(context.TODO(), types.NamespacedName{Namespace: direct.Namespace, Name: direct.Name}, &migapi.DirectVolumeMigration{})
+			if deleted := k8serror.IsNotFound(err); deleted != tt.wantDeleted {
+				t.Errorf("deleteExpired() deleted = %v, want %v", deleted, tt.wantDeleted)
+			}
+		})
+	}
+}
@@ -0,0 +1,270 @@
+package directvolumemigration
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	liberr "github.com/konveyor/controller/pkg/error"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"github.com/konveyor/mig-controller/pkg/settings"
+)
+
+// Range of the compression levels of the zlib compression of Rsync
+const (
+	MinRsyncCompressLevel = 0
+	MaxRsyncCompressLevel = 9
+)
+
+// Default tuning of the Rsync transfer set in the cluster ConfigMap of the
+// destination cluster. Values set in the DVM spec take precedence over the
+// cluster defaults, which take precedence over the controller settings.
+type rsyncTuning struct {
+	bwLimit                  *int
+	compress                 *bool
+	timeout                  *int
+	podActiveDeadlineSeconds *int64
+	// Rsync timeouts of each endpoint type
+	endpointTimeouts map[string]*rsyncTimeouts
+}
+
+// Rsync timeouts of an endpoint type, in seconds.
+type rsyncTimeouts struct {
+	timeout    *int
+	conTimeout *int
+}
+
+// Cluster ConfigMap keys of the Rsync timeouts of each endpoint type.
+var endpointTimeoutKeys = map[string]struct {
+	endpointType string
+	connection   bool
+}{
+	migapi.RsyncRouteTimeoutKey:        {endpointType: EndpointTypeRoute},
+	migapi.RsyncRouteConTimeoutKey:     {endpointType: EndpointTypeRoute, connection: true},
+	migapi.RsyncClusterIPTimeoutKey:    {endpointType: EndpointTypeClusterIP},
+	migapi.RsyncClusterIPConTimeoutKey: {endpointType: EndpointTypeClusterIP, connection: true},
+}
+
+// Parse the tuning keys of a cluster ConfigMap. Invalid values are rejected.
+func parseRsyncTuning(data map[string]string) (*rsyncTuning, error) {
+	tuning := &rsyncTuning{}
+	invalid := []string{}
+	for key, value := range data {
+		value = strings.TrimSpace(value)
+		switch key {
+		case migapi.RsyncBwLimitKey:
+			bwLimit, err := strconv.Atoi(value)
+			if err != nil || bwLimit < 0 {
+				invalid = append(invalid, fmt.Sprintf("%s: %s", key, value))
+				continue
+			}
+			tuning.bwLimit = &bwLimit
+		case migapi.RsyncCompressKey:
+			compress, err := strconv.ParseBool(value)
+			if err != nil {
+				invalid = append(invalid, fmt.Sprintf("%s: %s", key, value))
+				continue
+			}
+			tuning.compress = &compress
+		case migapi.RsyncTimeoutKey:
+			timeout, err := strconv.Atoi(value)
+			if err != nil || timeout < 0 {
+				invalid = append(invalid, fmt.Sprintf("%s: %s", key, value))
+				continue
+			}
+			tuning.timeout = &timeout
+		case migapi.RsyncPodActiveDeadlineKey:
+			deadline, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || deadline <= 0 {
+				invalid = append(invalid, fmt.Sprintf("%s: %s", key, value))
+				continue
+			}
+			tuning.podActiveDeadlineSeconds = &deadline
+		default:
+			endpointKey, found := endpointTimeoutKeys[key]
+			if !found {
+				continue
+			}
+			timeout, err := strconv.Atoi(value)
+			if err != nil || timeout < 0 {
+				invalid = append(invalid, fmt.Sprintf("%s: %s", key, value))
+				continue
+			}
+			if tuning.endpointTimeouts == nil {
+				tuning.endpointTimeouts = map[string]*rsyncTimeouts{}
+			}
+			timeouts, found := tuning.endpointTimeouts[endpointKey.endpointType]
+			if !found {
+				timeouts = &rsyncTimeouts{}
+				tuning.endpointTimeouts[endpointKey.endpointType] = timeouts
+			}
+			if endpointKey.connection {
+				timeouts.conTimeout = &timeout
+			} else {
+				timeouts.timeout = &timeout
+			}
+		}
+	}
+	if len(invalid) > 0 {
+		sort.Strings(invalid)
+		return nil, fmt.Errorf("invalid values [%s]", strings.Join(invalid, ", "))
+	}
+	return tuning, nil
+}
+
+// Get the default tuning of the Rsync transfer from the cluster ConfigMap of the destination cluster.
+func (t *Task) getClusterRsyncTuning() (*rsyncTuning, error) {
+	cluster, err := t.Owner.GetDestinationCluster(t.Client)
+	if err != nil {
+		return nil, liberr.Wrap(err)
+	}
+	if cluster == nil {
+		return &rsyncTuning{}, nil
+	}
+	data, err := cluster.GetRsyncTransferTuning(t.Client)
+	if err != nil {
+		return nil, liberr.Wrap(err)
+	}
+	tuning, err := parseRsyncTuning(data)
+	if err != nil {
+		return nil, liberr.Wrap(err)
+	}
+	return tuning, nil
+}
+
+// Get the bandwidth limit of the Rsync transfer, -1 when not limited by any setting.
+func (t *Task) getRsyncBwLimit() int {
+	if t.Owner.Spec.RsyncBwLimit != nil {
+		return *t.Owner.Spec.RsyncBwLimit
+	}
+	if t.clusterRsyncTuning != nil && t.clusterRsyncTuning.bwLimit != nil {
+		return *t.clusterRsyncTuning.bwLimit
+	}
+	return settings.Settings.DvmOpts.RsyncOpts.BwLimit
+}
+
+// Get whether the Rsync transfer is compressed.
+func (t *Task) getRsyncCompress() bool {
+	if t.Owner.Spec.RsyncCompress != nil {
+		return *t.Owner.Spec.RsyncCompress
+	}
+	if t.clusterRsyncTuning != nil && t.clusterRsyncTuning.compress != nil {
+		return *t.clusterRsyncTuning.compress
+	}
+	return false
+}
+
+// Get the Rsync option setting the compression level of the compressed
+// transfer, empty for Rsync to choose the level.
+func (t *Task) getRsyncCompressLevelOption() string {
+	if t.Owner.Spec.RsyncCompressLevel == nil {
+		return ""
+	}
+	return fmt.Sprintf("--compress-level=%d", *t.Owner.Spec.RsyncCompressLevel)
+}
+
+// Get the Rsync option selecting the transfer of whole files or deltas, empty
+// in auto mode for the Rsync defaults to apply.
+func (t *Task) getRsyncWholeFileOption() string {
+	switch t.Owner.Spec.WholeFile {
+	case migapi.WholeFileOn:
+		return "--whole-file"
+	case migapi.WholeFileOff:
+		return "--no-whole-file"
+	}
+	return ""
+}
+
+// Get the Rsync option handling the symlinks pointing outside of the source
+// volume, the unsafe symlinks are kept as symlinks unless requested otherwise.
+func (t *Task) getRsyncUnsafeLinksOption() string {
+	switch t.Owner.Spec.UnsafeLinks {
+	case migapi.UnsafeLinksDrop:
+		return "--safe-links"
+	case migapi.UnsafeLinksCopy:
+		return "--copy-unsafe-links"
+	}
+	return ""
+}
+
+// Get the Rsync option skipping the empty directories of the source volumes,
+// empty unless requested. --archive recurses into every directory, the empty
+// directories included, for the destination to get the whole directory tree.
+func (t *Task) getRsyncPruneEmptyDirsOption() string {
+	if t.Owner.Spec.PruneEmptyDirs {
+		return "--prune-empty-dirs"
+	}
+	return ""
+}
+
+// Remove the extra Rsync options of the RSYNC_OPT_EXTRAS setting pruning the
+// empty directories of the source volumes, unless pruning them is requested.
+func (t *Task) filterRsyncPruneEmptyDirsOptions(options []string) []string {
+	if t.Owner.Spec.PruneEmptyDirs {
+		return options
+	}
+	filtered := []string{}
+	for _, option := range options {
+		if option == "--prune-empty-dirs" || option == "-m" {
+			t.Log.Info("Rsync extra option pruning the empty directories ignored, set pruneEmptyDirs instead.",
+				"option", option)
+			continue
+		}
+		filtered = append(filtered, option)
+	}
+	return filtered
+}
+
+// Get the Rsync option tolerating a difference in the modification times of the
+// files, for the destination filesystems with coarse timestamps not to recopy
+// unchanged files. Empty when not set.
+func (t *Task) getRsyncModifyWindowOption() string {
+	if t.Owner.Spec.RsyncModifyWindow == nil {
+		return ""
+	}
+	return fmt.Sprintf("--modify-window=%d", *t.Owner.Spec.RsyncModifyWindow)
+}
+
+// Get the I/O timeout of the Rsync transfer in seconds, 0 when not set.
+func (t *Task) getRsyncTimeout() int {
+	if t.Owner.Spec.RsyncTimeout != nil {
+		return *t.Owner.Spec.RsyncTimeout
+	}
+	if t.clusterRsyncTuning != nil && t.clusterRsyncTuning.timeout != nil {
+		return *t.clusterRsyncTuning.timeout
+	}
+	return 0
+}
+
+// Get the Rsync timeouts of the endpoint type set in the cluster ConfigMap,
+// nil when not set.
+func (t *Task) getEndpointRsyncTimeouts(endpointType string) *rsyncTimeouts {
+	if t.clusterRsyncTuning == nil {
+		return nil
+	}
+	return t.clusterRsyncTuning.endpointTimeouts[endpointType]
+}
+
+// Get the I/O timeout of the Rsync transfer through an endpoint type in
+// seconds, 0 when not set. The timeout of the endpoint type takes precedence
+// over the timeout of the cluster ConfigMap, not over the timeout of the spec.
+func (t *Task) getEndpointRsyncTimeout(endpointType string) int {
+	if t.Owner.Spec.RsyncTimeout != nil {
+		return *t.Owner.Spec.RsyncTimeout
+	}
+	if timeouts := t.getEndpointRsyncTimeouts(endpointType); timeouts != nil && timeouts.timeout != nil {
+		return *timeouts.timeout
+	}
+	return t.getRsyncTimeout()
+}
+
+// Get the timeout of the connection of the Stunnel client to the endpoint type
+// in seconds, 0 when not set. Rsync connects to the local Stunnel client, the
+// connection through the endpoint is made by Stunnel.
+func (t *Task) getEndpointConnectTimeout(endpointType string) int {
+	if timeouts := t.getEndpointRsyncTimeouts(endpointType); timeouts != nil && timeouts.conTimeout != nil {
+		return *timeouts.conTimeout
+	}
+	return 0
+}
@@ -0,0 +1,382 @@
+package directvolumemigration
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"github.com/konveyor/mig-controller/pkg/settings"
+)
+
+func Test_parseRsyncTuning(t *testing.T) {
+	bwLimit, timeout, compress, deadline := 1024, 300, true, int64(3600)
+	tests := []struct {
+		name    string
+		data    map[string]string
+		want    *rsyncTuning
+		wantErr bool
+	}{
+		{
+			name: "when no tuning key is set, should return empty tuning",
+			data: map[string]string{},
+			want: &rsyncTuning{},
+		},
+		{
+			name: "when all tuning keys are set, should parse them",
+			data: map[string]string{
+				migapi.RsyncBwLimitKey:           "1024",
+				migapi.RsyncCompressKey:          "true",
+				migapi.RsyncTimeoutKey:           " 300 ",
+				migapi.RsyncPodActiveDeadlineKey: "3600",
+			},
+			want: &rsyncTuning{
+				bwLimit:                  &bwLimit,
+				compress:                 &compress,
+				timeout:                  &timeout,
+				podActiveDeadlineSeconds: &deadline,
+			},
+		},
+		{
+			name: "when endpoint type timeouts are set, should parse them by endpoint type",
+			data: map[string]string{
+				migapi.RsyncRouteTimeoutKey:        "300",
+				migapi.RsyncRouteConTimeoutKey:     "300",
+				migapi.RsyncClusterIPConTimeoutKey: "300",
+			},
+			want: &rsyncTuning{
+				endpointTimeouts: map[string]*rsyncTimeouts{
+					EndpointTypeRoute:     {timeout: &timeout, conTimeout: &timeout},
+					EndpointTypeClusterIP: {conTimeout: &timeout},
+				},
+			},
+		},
+		{
+			name:    "when an endpoint type timeout is negative, should fail",
+			data:    map[string]string{migapi.RsyncClusterIPTimeoutKey: "-1"},
+			wantErr: true,
+		},
+		{
+			name:    "when the bandwidth limit is negative, should fail",
+			data:    map[string]string{migapi.RsyncBwLimitKey: "-1"},
+			wantErr: true,
+		},
+		{
+			name:    "when compress isn't a boolean, should fail",
+			data:    map[string]string{migapi.RsyncCompressKey: "sometimes"},
+			wantErr: true,
+		},
+		{
+			name:    "when the active deadline is zero, should fail",
+			data:    map[string]string{migapi.RsyncPodActiveDeadlineKey: "0"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseRsyncTuning(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseRsyncTuning() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseRsyncTuning() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTask_getRsyncTuning(t *testing.T) {
+	specBwLimit, clusterBwLimit, clusterTimeout := 0, 2048, 600
+	specCompress, clusterCompress := false, true
+	tests := []struct {
+		name         string
+		spec         migapi.DirectVolumeMigrationSpec
+		cluster      *rsyncTuning
+		wantBwLimit  int
+		wantCompress bool
+		wantTimeout  int
+	}{
+		{
+			name:         "when no tuning is set, should use the controller defaults",
+			wantBwLimit:  512,
+			wantCompress: false,
+			wantTimeout:  0,
+		},
+		{
+			name:         "when the cluster sets a tuning, should use it over the controller defaults",
+			cluster:      &rsyncTuning{bwLimit: &clusterBwLimit, compress: &clusterCompress, timeout: &clusterTimeout},
+			wantBwLimit:  2048,
+			wantCompress: true,
+			wantTimeout:  600,
+		},
+		{
+			name:         "when the spec sets a tuning, should use it over the cluster defaults",
+			spec:         migapi.DirectVolumeMigrationSpec{RsyncBwLimit: &specBwLimit, RsyncCompress: &specCompress},
+			cluster:      &rsyncTuning{bwLimit: &clusterBwLimit, compress: &clusterCompress, timeout: &clusterTimeout},
+			wantBwLimit:  0,
+			wantCompress: false,
+			wantTimeout:  600,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &Task{
+				Owner:              &migapi.DirectVolumeMigration{Spec: tt.spec},
+				clusterRsyncTuning: tt.cluster,
+			}
+			settings.Settings.DvmOpts.RsyncOpts.BwLimit = 512
+			defer func() {
+				settings.Settings.DvmOpts.RsyncOpts.BwLimit = 0
+			}()
+			if got := task.getRsyncBwLimit(); got != tt.wantBwLimit {
+				t.Errorf("Task.getRsyncBwLimit() = %v, want %v", got, tt.wantBwLimit)
+			}
+			if got := task.getRsyncCompress(); got != tt.wantCompress {
+				t.Errorf("Task.getRsyncCompress() = %v, want %v", got, tt.wantCompress)
+			}
+			if got := task.getRsyncTimeout(); got != tt.wantTimeout {
+				t.Errorf("Task.getRsyncTimeout() = %v, want %v", got, tt.wantTimeout)
+			}
+		})
+	}
+}
+
+func TestTask_getEndpointRsyncOptions_timeouts(t *testing.T) {
+	specTimeout, clusterTimeout, routeTimeout, routeConTimeout := 60, 600, 1200, 30
+	cluster := &rsyncTuning{
+		timeout: &clusterTimeout,
+		endpointTimeouts: map[string]*rsyncTimeouts{
+			EndpointTypeRoute: {timeout: &routeTimeout, conTimeout: &routeConTimeout},
+		},
+	}
+	tests := []struct {
+		name               string
+		spec               migapi.DirectVolumeMigrationSpec
+		cluster            *rsyncTuning
+		endpointType       string
+		wantTimeout        string
+		wantConnectTimeout int
+	}{
+		{
+			name:         "when no timeout is set, should not set a timeout",
+			endpointType: EndpointTypeRoute,
+		},
+		{
+			name:               "when the endpoint type sets timeouts, should use them over the cluster timeout",
+			cluster:            cluster,
+			endpointType:       EndpointTypeRoute,
+			wantTimeout:        "--timeout=1200",
+			wantConnectTimeout: 30,
+		},
+		{
+			name:         "when the endpoint type sets no timeout, should use the cluster timeout",
+			cluster:      cluster,
+			endpointType: EndpointTypeClusterIP,
+			wantTimeout:  "--timeout=600",
+		},
+		{
+			name:         "when the endpoint type isn't known, should use the cluster timeout",
+			cluster:      cluster,
+			endpointType: "",
+			wantTimeout:  "--timeout=600",
+		},
+		{
+			name:               "when the spec sets a timeout, should use it over the endpoint type timeout",
+			spec:               migapi.DirectVolumeMigrationSpec{RsyncTimeout: &specTimeout},
+			cluster:            cluster,
+			endpointType:       EndpointTypeRoute,
+			wantTimeout:        "--timeout=60",
+			wantConnectTimeout: 30,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &Task{
+				Owner:              &migapi.DirectVolumeMigration{Spec: tt.spec},
+				clusterRsyncTuning: tt.cluster,
+			}
+			timeouts := []string{}
+			for _, option := range task.getEndpointRsyncOptions(tt.endpointType) {
+				if strings.HasPrefix(option, "--timeout") || strings.HasPrefix(option, "--contimeout") {
+					timeouts = append(timeouts, option)
+				}
+			}
+			want := []string{}
+			if tt.wantTimeout != "" {
+				want = append(want, tt.wantTimeout)
+			}
+			if !reflect.DeepEqual(timeouts, want) {
+				t.Errorf("Task.getEndpointRsyncOptions() timeouts = %v, want %v", timeouts, want)
+			}
+			if got := task.getEndpointConnectTimeout(tt.endpointType); got != tt.wantConnectTimeout {
+				t.Errorf("Task.getEndpointConnectTimeout() = %v, want %v", got, tt.wantConnectTimeout)
+			}
+		})
+	}
+}
+
+func TestTask_getRsyncWholeFileOption(t *testing.T) {
+	tests := []struct {
+		wholeFile string
+		want      string
+	}{
+		{wholeFile: "", want: ""},
+		{wholeFile: migapi.WholeFileAuto, want: ""},
+		{wholeFile: migapi.WholeFileOn, want: "--whole-file"},
+		{wholeFile: migapi.WholeFileOff, want: "--no-whole-file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.wholeFile, func(t *testing.T) {
+			task := &Task{
+				Owner: &migapi.DirectVolumeMigration{Spec: migapi.DirectVolumeMigrationSpec{WholeFile: tt.wholeFile}},
+			}
+			if got := task.getRsyncWholeFileOption(); got != tt.want {
+				t.Errorf("Task.getRsyncWholeFileOption() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTask_getRsyncCompressLevelOption(t *testing.T) {
+	level := 9
+	task := &Task{Owner: &migapi.DirectVolumeMigration{}}
+	if got := task.getRsyncCompressLevelOption(); got != "" {
+		t.Errorf("Task.getRsyncCompressLevelOption() = %v, want none", got)
+	}
+	task.Owner.Spec.RsyncCompressLevel = &level
+	if got := task.getRsyncCompressLevelOption(); got != "--compress-level=9" {
+		t.Errorf("Task.getRsyncCompressLevelOption() = %v, want --compress-level=9", got)
+	}
+}
+
+func Test_getRsyncUnsafeLinksOptionContent(t *testing.T) {
+	if _, err := exec.LookPath("rsync"); err != nil {
+		t.Skip("rsync not found")
+	}
+	tests := []struct {
+		unsafeLinks string
+		wantLink    bool
+		wantFile    bool
+	}{
+		{unsafeLinks: "", wantLink: true, wantFile: false},
+		{unsafeLinks: migapi.UnsafeLinksDrop, wantLink: false, wantFile: false},
+		{unsafeLinks: migapi.UnsafeLinksCopy, wantLink: false, wantFile: true},
+		{unsafeLinks: migapi.UnsafeLinksKeep, wantLink: true, wantFile: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.unsafeLinks, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "dvm-unsafe-links")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			// a volume fixture with a symlink within the volume and one pointing outside of it
+			source, destination := filepath.Join(dir, "src"), filepath.Join(dir, "dest")
+			for _, d := range []string{source, destination} {
+				if err := os.MkdirAll(d, 0755); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := ioutil.WriteFile(filepath.Join(dir, "secret"), []byte("outside"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := ioutil.WriteFile(filepath.Join(source, "data"), []byte("inside"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Symlink("data", filepath.Join(source, "safe")); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Symlink("../secret", filepath.Join(source, "unsafe")); err != nil {
+				t.Fatal(err)
+			}
+			task := &Task{
+				Owner: &migapi.DirectVolumeMigration{Spec: migapi.DirectVolumeMigrationSpec{UnsafeLinks: tt.unsafeLinks}},
+			}
+			args := []string{"--archive"}
+			if option := task.getRsyncUnsafeLinksOption(); option != "" {
+				args = append(args, option)
+			}
+			args = append(args, source+"/", destination)
+			if out, err := exec.Command("rsync", args...).CombinedOutput(); err != nil {
+				t.Fatalf("rsync failed: %v: %s", err, out)
+			}
+			if target, err := os.Readlink(filepath.Join(destination, "safe")); err != nil || target != "data" {
+				t.Errorf("safe symlink = %v, %v, want a symlink to data", target, err)
+			}
+			info, err := os.Lstat(filepath.Join(destination, "unsafe"))
+			gotLink := err == nil && info.Mode()&os.ModeSymlink != 0
+			gotFile := err == nil && info.Mode().IsRegular()
+			if gotLink != tt.wantLink || gotFile != tt.wantFile {
+				t.Errorf("unsafe symlink transferred as symlink = %v, as file = %v, want %v, %v",
+					gotLink, gotFile, tt.wantLink, tt.wantFile)
+			}
+		})
+	}
+}
+
+func Test_getRsyncPruneEmptyDirsOptionContent(t *testing.T) {
+	if _, err := exec.LookPath("rsync"); err != nil {
+		t.Skip("rsync not found")
+	}
+	tests := []struct {
+		name           string
+		pruneEmptyDirs bool
+		wantEmptyDirs  bool
+	}{
+		{name: "preserved", pruneEmptyDirs: false, wantEmptyDirs: true},
+		{name: "pruned", pruneEmptyDirs: true, wantEmptyDirs: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "dvm-empty-dirs")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			// a volume fixture with empty directories, nested and excluded, and a directory with a file
+			source, destination := filepath.Join(dir, "src"), filepath.Join(dir, "dest")
+			for _, d := range []string{
+				destination,
+				filepath.Join(source, "data"),
+				filepath.Join(source, "cache", "tmp"),
+				filepath.Join(source, "logs"),
+			} {
+				if err := os.MkdirAll(d, 0755); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := ioutil.WriteFile(filepath.Join(source, "data", "file"), []byte("data"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := ioutil.WriteFile(filepath.Join(source, "logs", "app.log"), []byte("log"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			task := &Task{
+				Owner: &migapi.DirectVolumeMigration{Spec: migapi.DirectVolumeMigrationSpec{PruneEmptyDirs: tt.pruneEmptyDirs}},
+			}
+			args := []string{"--archive", "--exclude=*.log"}
+			if option := task.getRsyncPruneEmptyDirsOption(); option != "" {
+				args = append(args, option)
+			}
+			args = append(args, source+"/", destination)
+			if out, err := exec.Command("rsync", args...).CombinedOutput(); err != nil {
+				t.Fatalf("rsync failed: %v: %s", err, out)
+			}
+			if _, err := os.Stat(filepath.Join(destination, "data", "file")); err != nil {
+				t.Errorf("file not transferred: %v", err)
+			}
+			for _, emptyDir := range []string{filepath.Join("cache", "tmp"), "logs"} {
+				info, err := os.Stat(filepath.Join(destination, emptyDir))
+				gotEmptyDir := err == nil && info.IsDir()
+				if gotEmptyDir != tt.wantEmptyDirs {
+					t.Errorf("empty directory %s created = %v, want %v", emptyDir, gotEmptyDir, tt.wantEmptyDirs)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package directvolumemigration
+
+import (
+	"fmt"
+	"strings"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// UnchangedOutputPrefix prefix of the lines the Rsync dry run prints for each item differing before the transfer
+	UnchangedOutputPrefix = "dvm-precheck:"
+	// UnchangedTerminationMessage termination message of the rsync container of an Rsync client Pod which found no difference
+	UnchangedTerminationMessage = "dvm-unchanged"
+)
+
+// getUnchangedPrecheckCommand returns the bash commands comparing the source with
+// the destination by an Rsync dry run with the options of the transfer. The
+// transfer only runs when the dry run fails or finds any differing item,
+// including the items to delete. Otherwise the rsync container terminates with
+// the UnchangedTerminationMessage.
+func getUnchangedPrecheckCommand(rsyncOptions []string, source string, destination string, transfer string) string {
+	dryRun := []string{"rsync"}
+	dryRun = append(dryRun, rsyncOptions...)
+	if !hasRsyncOption(dryRun, "--dry-run") && !hasRsyncOption(dryRun, "-n") {
+		dryRun = append(dryRun, "--dry-run")
+	}
+	dryRun = append(dryRun, fmt.Sprintf("--out-format=%s%%i:%%n", UnchangedOutputPrefix), source, destination)
+	return fmt.Sprintf("changes=$(%s); if [ $? -eq 0 ] && ! grep -q '^%s' <<< \"$changes\"; then echo %s > /dev/termination-log; else %s; fi",
+		strings.Join(dryRun, " "), UnchangedOutputPrefix, UnchangedTerminationMessage, transfer)
+}
+
+// Get whether the Rsync client Pod completed without transferring its PVC, the
+// dry run before the transfer found no difference.
+func isRsyncTransferUnchanged(pod *corev1.Pod) bool {
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if containerStatus.Name == DirectVolumeMigrationRsyncClient && containerStatus.State.Terminated != nil {
+			return strings.TrimSpace(containerStatus.State.Terminated.Message) == UnchangedTerminationMessage
+		}
+	}
+	return false
+}
+
+// Get whether the transfer of the PVC may be skipped when it is unchanged. The
+// sharded and raw block transfers always run, as do verify-only migrations.
+func (t *Task) canSkipUnchangedPVC(pvInfo PVCWithSecurityContext) bool {
+	return t.Owner.Spec.SkipUnchangedPVCs && !t.isVerifyOnly() && !pvInfo.block && pvInfo.shards <= 1
+}
+
+// setPVCsUnchanged reports the PVCs which destination already matched the
+// source, completed without being transferred.
+func (t *Task) setPVCsUnchanged() {
+	unchanged := []string{}
+	for _, operation := range t.Owner.Status.RsyncOperations {
+		if operation.Unchanged {
+			unchanged = append(unchanged, operation.String())
+		}
+	}
+	if len(unchanged) == 0 {
+		return
+	}
+	t.Owner.Status.SetCondition(migapi.Condition{
+		Type:     PVCsUnchanged,
+		Status:   True,
+		Reason:   AlreadyTransferred,
+		Category: Advisory,
+		Message:  PVCsUnchangedMessage,
+		Items:    unchanged,
+	})
+}
@@ -0,0 +1,129 @@
+package directvolumemigration
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func Test_getUnchangedPrecheckCommand(t *testing.T) {
+	if _, err := exec.LookPath("rsync"); err != nil {
+		t.Skip("rsync not found")
+	}
+	dir, err := ioutil.TempDir("", "dvm-unchanged")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	source, destination := filepath.Join(dir, "src")+"/", filepath.Join(dir, "dest")
+	for _, d := range []string{source, destination} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(source, "data"), []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	terminationLog := filepath.Join(dir, "termination-log")
+	options := []string{"--archive", "--delete"}
+	transfer := "rsync " + strings.Join(options, " ") + " " + source + " " + destination
+	// the container terminates with the termination message when the dry run finds no difference
+	run := func() string {
+		os.Remove(terminationLog)
+		command := getUnchangedPrecheckCommand(options, source, destination, transfer)
+		command = strings.Replace(command, "/dev/termination-log", terminationLog, 1)
+		if out, err := exec.Command("bash", "-c", command).CombinedOutput(); err != nil {
+			t.Fatalf("precheck command failed: %v: %s", err, out)
+		}
+		message, _ := ioutil.ReadFile(terminationLog)
+		return strings.TrimSpace(string(message))
+	}
+
+	if message := run(); message != "" {
+		t.Errorf("precheck of a differing destination terminated with %q, want the transfer", message)
+	}
+	if _, err := os.Stat(filepath.Join(destination, "data")); err != nil {
+		t.Errorf("precheck of a differing destination didn't transfer: %v", err)
+	}
+	if message := run(); message != UnchangedTerminationMessage {
+		t.Errorf("precheck of a matching destination terminated with %q, want %q", message, UnchangedTerminationMessage)
+	}
+	// an extraneous file on the destination is a difference to delete
+	if err := ioutil.WriteFile(filepath.Join(destination, "extra"), []byte("extra"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if message := run(); message != "" {
+		t.Errorf("precheck of a destination with an extraneous file terminated with %q, want the transfer", message)
+	}
+	if _, err := os.Stat(filepath.Join(destination, "extra")); !os.IsNotExist(err) {
+		t.Errorf("precheck of a destination with an extraneous file didn't transfer")
+	}
+}
+
+func Test_isRsyncTransferUnchanged(t *testing.T) {
+	pod := func(container string, message string) *corev1.Pod {
+		return &corev1.Pod{
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{
+					{
+						Name: container,
+						State: corev1.ContainerState{
+							Terminated: &corev1.ContainerStateTerminated{Message: message},
+						},
+					},
+				},
+			},
+		}
+	}
+	tests := []struct {
+		name string
+		pod  *corev1.Pod
+		want bool
+	}{
+		{name: "when the rsync container found no difference, should be unchanged", pod: pod(DirectVolumeMigrationRsyncClient, UnchangedTerminationMessage+"\n"), want: true},
+		{name: "when the rsync container transferred, should not be unchanged", pod: pod(DirectVolumeMigrationRsyncClient, ""), want: false},
+		{name: "when another container found no difference, should not be unchanged", pod: pod("stunnel", UnchangedTerminationMessage), want: false},
+		{name: "when the rsync container is running, should not be unchanged", pod: &corev1.Pod{}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRsyncTransferUnchanged(tt.pod); got != tt.want {
+				t.Errorf("isRsyncTransferUnchanged() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTask_canSkipUnchangedPVC(t *testing.T) {
+	tests := []struct {
+		name       string
+		skip       bool
+		verifyOnly bool
+		pvInfo     PVCWithSecurityContext
+		want       bool
+	}{
+		{name: "when not set, should transfer", want: false},
+		{name: "when set, should skip", skip: true, want: true},
+		{name: "when set and verifyOnly, should transfer", skip: true, verifyOnly: true, want: false},
+		{name: "when set and raw block, should transfer", skip: true, pvInfo: PVCWithSecurityContext{block: true}, want: false},
+		{name: "when set and sharded, should transfer", skip: true, pvInfo: PVCWithSecurityContext{shards: 4}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &Task{
+				Owner: &migapi.DirectVolumeMigration{
+					Spec: migapi.DirectVolumeMigrationSpec{SkipUnchangedPVCs: tt.skip, VerifyOnly: tt.verifyOnly},
+				},
+			}
+			if got := task.canSkipUnchangedPVC(tt.pvInfo); got != tt.want {
+				t.Errorf("Task.canSkipUnchangedPVC() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package directvolumemigration
+
+import (
+	"context"
+
+	liberr "github.com/konveyor/controller/pkg/error"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"github.com/konveyor/mig-controller/pkg/compat"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// PodMetricsListKind metrics of the Pods served by the metrics API, from the metrics-server
+var PodMetricsListKind = schema.GroupVersionKind{Group: "metrics.k8s.io", Version: "v1beta1", Kind: "PodMetricsList"}
+
+// Get the CPU and memory usage of each container of the Pods selected in the
+// namespace, keyed by container name, from the PodMetrics of the metrics API.
+// When set, only the Pods with one of the identities are sampled.
+func getContainerUsage(client compat.Client, namespace string, selector map[string]string, identities map[string]bool) (map[string][]resourceUsageSample, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(PodMetricsListKind)
+	err := client.List(context.TODO(), list, k8sclient.InNamespace(namespace), k8sclient.MatchingLabels(selector))
+	if err != nil {
+		return nil, liberr.Wrap(err)
+	}
+	usage := map[string][]resourceUsageSample{}
+	for _, item := range list.Items {
+		if identities != nil && !identities[item.GetLabels()[migapi.RsyncPodIdentityLabel]] {
+			continue
+		}
+		containers, _, err := unstructured.NestedSlice(item.Object, "containers")
+		if err != nil {
+			return nil, liberr.Wrap(err)
+		}
+		for _, c := range containers {
+			container, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _, _ := unstructured.NestedString(container, "name")
+			values, _, _ := unstructured.NestedStringMap(container, "usage")
+			sample := resourceUsageSample{}
+			if cpu, err := resource.ParseQuantity(values["cpu"]); err == nil {
+				sample.cpu = &cpu
+			}
+			if memory, err := resource.ParseQuantity(values["memory"]); err == nil {
+				sample.memory = &memory
+			}
+			usage[name] = append(usage[name], sample)
+		}
+	}
+	return usage, nil
+}
+
+// A sample of the resource usage of a container.
+type resourceUsageSample struct {
+	cpu    *resource.Quantity
+	memory *resource.Quantity
+}
+
+// Record the sampled usage of the container when it exceeds the peak of the status.
+func (t *Task) recordPeakResourceUsage(cluster string, container string, sample resourceUsageSample) {
+	var peak *migapi.ContainerResourceUsage
+	for i := range t.Owner.Status.PeakResourceUsage {
+		usage := &t.Owner.Status.PeakResourceUsage[i]
+		if usage.Cluster == cluster && usage.Container == container {
+			peak = usage
+		}
+	}
+	if peak == nil {
+		t.Owner.Status.PeakResourceUsage = append(t.Owner.Status.PeakResourceUsage,
+			migapi.ContainerResourceUsage{Cluster: cluster, Container: container})
+		peak = &t.Owner.Status.PeakResourceUsage[len(t.Owner.Status.PeakResourceUsage)-1]
+	}
+	if sample.cpu != nil && (peak.CPU == nil || sample.cpu.Cmp(*peak.CPU) > 0) {
+		peak.CPU = sample.cpu
+	}
+	if sample.memory != nil && (peak.Memory == nil || sample.memory.Cmp(*peak.Memory) > 0) {
+		peak.Memory = sample.memory
+	}
+}
+
+// Sample the resource usage of the Rsync client Pods of the source cluster and
+// of the Rsync transfer Pods of the destination cluster, and record the peaks of
+// each container in the status. Only the client Pods of the migrated PVCs are
+// sampled.
+func (t *Task) sampleRsyncPodResourceUsage() error {
+	srcClient, err := t.getSourceClient()
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	destClient, err := t.getDestinationClient()
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	clientLabels := map[string]string{
+		"app":                   DirectVolumeMigrationRsyncTransfer,
+		"directvolumemigration": DirectVolumeMigrationRsyncClient,
+	}
+	transferLabels := t.buildDVMLabels()
+	transferLabels["purpose"] = DirectVolumeMigrationRsync
+	for bothNs, vols := range t.getPVCNamespaceMap() {
+		srcNs, destNs := getSourceNs(bothNs), getDestNs(bothNs)
+		identities := map[string]bool{}
+		for _, vol := range vols {
+			identities[GetRsyncPodSelector(vol.Name)[migapi.RsyncPodIdentityLabel]] = true
+		}
+		usage, err := getContainerUsage(srcClient, srcNs, clientLabels, identities)
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		for container, samples := range usage {
+			for _, sample := range samples {
+				t.recordPeakResourceUsage(SourceCluster, container, sample)
+			}
+		}
+		usage, err = getContainerUsage(destClient, destNs, transferLabels, nil)
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		for container, samples := range usage {
+			for _, sample := range samples {
+				t.recordPeakResourceUsage(DestinationCluster, container, sample)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package directvolumemigration
+
+import (
+	"testing"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestTask_recordPeakResourceUsage(t *testing.T) {
+	sample := func(cpu string, memory string) resourceUsageSample {
+		c, m := resource.MustParse(cpu), resource.MustParse(memory)
+		return resourceUsageSample{cpu: &c, memory: &m}
+	}
+	task := &Task{Owner: &migapi.DirectVolumeMigration{}}
+	task.recordPeakResourceUsage(SourceCluster, DirectVolumeMigrationRsyncClient, sample("250m", "64Mi"))
+	task.recordPeakResourceUsage(SourceCluster, DirectVolumeMigrationRsyncClient, sample("500m", "32Mi"))
+	task.recordPeakResourceUsage(DestinationCluster, DirectVolumeMigrationStunnel, sample("10m", "8Mi"))
+	peaks := task.Owner.Status.PeakResourceUsage
+	if len(peaks) != 2 {
+		t.Fatalf("Task.recordPeakResourceUsage() peaks = %v, want 2", len(peaks))
+	}
+	client := peaks[0]
+	if client.Cluster != SourceCluster || client.Container != DirectVolumeMigrationRsyncClient {
+		t.Errorf("Task.recordPeakResourceUsage() peak = %s/%s, want %s/%s",
+			client.Cluster, client.Container, SourceCluster, DirectVolumeMigrationRsyncClient)
+	}
+	if client.CPU.String() != "500m" || client.Memory.String() != "64Mi" {
+		t.Errorf("Task.recordPeakResourceUsage() peak = %v/%v, want 500m/64Mi", client.CPU, client.Memory)
+	}
+}
@@ -2,11 +2,17 @@ package directvolumemigration
 
 import (
 	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"path"
 	"reflect"
+	"strings"
 
 	liberr "github.com/konveyor/controller/pkg/error"
 	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
 	migref "github.com/konveyor/mig-controller/pkg/reference"
+	"github.com/konveyor/mig-controller/pkg/settings"
 	"github.com/opentracing/opentracing-go"
 	kapi "k8s.io/api/core/v1"
 	k8serror "k8s.io/apimachinery/pkg/api/errors"
@@ -26,6 +32,7 @@ const (
 	StunnelClientPodsPending        = "StunnelClientPodsPending"
 	RsyncTransferPodsPending        = "RsyncTransferPodsPending"
 	RsyncRouteNotAdmitted           = "RsyncRouteNotAdmitted"
+	RsyncRouteRejected              = "RsyncRouteRejected"
 	Running                         = "Running"
 	Failed                          = "Failed"
 	RsyncClientPodsPending          = "RsyncClientPodsPending"
@@ -33,6 +40,64 @@ const (
 	SourceToDestinationNetworkError = "SourceToDestinationNetworkError"
 	FailedCreatingRsyncPods         = "FailedCreatingRsyncPods"
 	FailedDeletingRsyncPods         = "FailedDeletingRsyncPods"
+	FailedRsyncOperations           = "FailedRsyncOperations"
+	DestinationPVCsNotBound         = "DestinationPVCsNotBound"
+	DestinationPVCsPending          = "DestinationPVCsPending"
+	DeadlineExceeded                = "DeadlineExceeded"
+	InvalidStunnelProxy             = "InvalidStunnelProxy"
+	InvalidStunnelProxySecret       = "InvalidStunnelProxySecret"
+	InvalidHooks                    = "InvalidHooks"
+	TransferHookFailed              = "TransferHookFailed"
+	RsyncSecretsNotFound            = "RsyncSecretsNotFound"
+	InvalidRsyncUser                = "InvalidRsyncUser"
+	InvalidRsyncSizeFilters         = "InvalidRsyncSizeFilters"
+	OwnerNotFound                   = "OwnerNotFound"
+	CancelRequested                 = "CancelRequested"
+	InvalidRsyncShards              = "InvalidRsyncShards"
+	InvalidLargeFileStreams         = "InvalidLargeFileStreams"
+	InvalidPVCAnnotations           = "InvalidPVCAnnotations"
+	InvalidEndpointType             = "InvalidEndpointType"
+	RsyncCompletedWithWarnings      = "RsyncCompletedWithWarnings"
+	DuplicatePVCs                   = "DuplicatePVCs"
+	InvalidRsyncPodActiveDeadline   = "InvalidRsyncPodActiveDeadline"
+	PrewarmFailed                   = "PrewarmFailed"
+	RsyncTransferStopped            = "RsyncTransferStopped"
+	VerificationDifferencesFound    = "VerificationDifferencesFound"
+	InvalidRsyncTuning              = "InvalidRsyncTuning"
+	InvalidRsyncFilter              = "InvalidRsyncFilter"
+	DestinationClusterUnreachable   = "DestinationClusterUnreachable"
+	InvalidTTL                      = "InvalidTTL"
+	InvalidRequeueIntervals         = "InvalidRequeueIntervals"
+	EndpointReady                   = "EndpointReady"
+	InvalidRsyncSparse              = "InvalidRsyncSparse"
+	DestinationVolumeFull           = "DestinationVolumeFull"
+	DestinationPVCsExpanding        = "DestinationPVCsExpanding"
+	DestinationPVCsNotExpandable    = "DestinationPVCsNotExpandable"
+	SourceNotQuiesced               = "SourceNotQuiesced"
+	ThroughputBelowBaseline         = "ThroughputBelowBaseline"
+	UnsupportedTargetAccessModes    = "UnsupportedTargetAccessModes"
+	PVCsSkipped                     = "PVCsSkipped"
+	InvalidBaselineThroughput       = "InvalidBaselineThroughput"
+	InvalidSpeedTestSize            = "InvalidSpeedTestSize"
+	ClockSkewDetected               = "ClockSkewDetected"
+	OpenFilesLimitRaised            = "OpenFilesLimitRaised"
+	InvalidTransferEngine           = "InvalidTransferEngine"
+	DestinationPVCsInUse            = "DestinationPVCsInUse"
+	PVCsUnchanged                   = "PVCsUnchanged"
+	InvalidTunnelEndpoints          = "InvalidTunnelEndpoints"
+	TunnelEndpointsUnreachable      = "TunnelEndpointsUnreachable"
+	SourcePVsNotFound               = "SourcePVsNotFound"
+	SourceVolumeAttachFailed        = "SourceVolumeAttachFailed"
+	SourcePVCsAttachedReadWrite     = "SourcePVCsAttachedReadWrite"
+	DestinationConfigMissing        = "DestinationConfigMissing"
+	VerifyingData                   = "VerifyingData"
+	InvalidItinerary                = "InvalidItinerary"
+	NoVolumesToMigrate              = "NoVolumesToMigrate"
+	SourceClusterUnreachable        = "SourceClusterUnreachable"
+	SourceClusterRecovered          = "SourceClusterRecovered"
+	InvalidExistingPVCPolicy        = "InvalidExistingPVCPolicy"
+	InvalidDeltaSinceCheckpoint     = "InvalidDeltaSinceCheckpoint"
+	NoEndpointTypeAvailable         = "NoEndpointTypeAvailable"
 )
 
 // Reasons
@@ -43,12 +108,27 @@ const (
 	NotReady           = "NotReady"
 	RsyncTimeout       = "RsyncTimedOut"
 	RsyncNoRouteToHost = "RsyncNoRouteToHost"
+	Malformed          = "Malformed"
+	NotSupported       = "NotSupported"
+	Warned             = "Warned"
+	NoSpaceLeft        = "NoSpaceLeft"
+	NotQuiesced        = "NotQuiesced"
+	QuiesceNotHonored  = "QuiesceNotHonored"
+	BelowBaseline      = "BelowBaseline"
+	AlreadyTransferred = "AlreadyTransferred"
+	Rejected           = "Rejected"
+	Skewed             = "Skewed"
+	ManyFiles          = "ManyFiles"
+	AttachFailed       = "AttachFailed"
+	InUse              = "InUse"
+	Recovered          = "Recovered"
 )
 
 // Messages
 const (
 	ReadyMessage                              = "Direct migration is ready"
 	RunningMessage                            = "Step: %d/%d"
+	RunningPVCsMessage                        = "Step: %d/%d, PVCs completed: %d/%d"
 	InvalidSourceClusterReferenceMessage      = "The source cluster reference is invalid"
 	InvalidDestinationClusterReferenceMessage = "The destination cluster reference is invalid"
 	InvalidSourceClusterMessage               = "The source cluster is invalid"
@@ -59,6 +139,62 @@ const (
 	PVCsNotFoundOnSourceClusterMessage        = "The set of pvcs were not found on source cluster"
 	SucceededMessage                          = "The migration has succeeded"
 	FailedMessage                             = "The migration has failed.  See: Errors."
+	InvalidStunnelProxyMessage                = "The stunnel TCP proxy setting [%s] is invalid: %s."
+	InvalidStunnelProxySecretMessage          = "The stunnel TCP proxy credentials secret [%s] was not found."
+	InvalidHooksMessage                       = "Hooks must reference a MigHook and use one of the phases: PreTransfer, PostTransfer. A PreTransfer hook is required by requireHookConsistency."
+	InvalidRsyncUserMessage                   = "The rsyncUID, rsyncGID and destinationFSGroup must be between 0 and %d: []."
+	InvalidRsyncSizeFiltersMessage            = "The maxSize and minSize of PVCs must be valid rsync sizes, e.g. 500K, 1.5G, 2GiB."
+	InvalidRsyncSparseMessage                 = "The sparse mode of PVCs must be one of auto, always, never."
+	InvalidRsyncShardsMessage                 = "The shards of PVCs must be between 0 and %d: []."
+	InvalidLargeFileStreamsMessage            = "The large file streams of PVCs must be between 0 and %d: []."
+	InvalidPVCAnnotationsMessage              = "The pvcAnnotations must be valid annotation keys and values, the binding and provisioning annotations of PVCs cannot be propagated nor set."
+	DestinationPVCsPendingMessage             = "Waiting for the destination PVCs [] to be bound, the migration fails if they are not bound within %v."
+	InvalidEndpointTypeMessage                = "The RSYNC_ENDPOINT_TYPE of the destination cluster is invalid: %s."
+	InvalidRsyncPodActiveDeadlineMessage      = "The rsyncPodActiveDeadlineSeconds must be greater than 0."
+	PrewarmFailedMessage                      = "The storage of the destination PVCs could not be prewarmed, the transfer may be slower."
+	RsyncTransferStoppedMessage               = "The Rsync transfer is stopped, remove the migration.openshift.io/stop-rsync-transfer annotation to retry it."
+	InvalidRsyncTuningMessage                 = "The Rsync tuning is invalid: %s."
+	InvalidRsyncFilterMessage                 = "The Rsync filter is invalid: %s."
+	InvalidTTLMessage                         = "The ttlAfterCompleted and ttlAfterFailed must not be negative."
+	InvalidRequeueIntervalsMessage            = "The requeueIntervals must be within their bounds, fast between 10ms and 10s and poll between 500ms and 5m."
+	SourcePVsNotFoundMessage                  = "The persistent volumes bound to the source PVCs were not found on the source cluster: []."
+	SourceVolumeAttachFailedMessage           = "The source volumes could not be attached or mounted in the Rsync client Pods, their transfer failed: []."
+	SourcePVCsAttachedReadWriteMessage        = "The source PVCs are in use by running Pods, they are attached read-write and only mounted read-only in the Rsync client Pods: []."
+	RsyncSecretsNotFoundMessage               = "The Secrets mounted by the Rsync Pods were not found: []."
+	DestinationPVCsInUseMessage               = "The destination PVCs are mounted by Pods which do not belong to the migration, delete these Pods and run a new migration: []."
+	InvalidTransferEngineMessage              = "The transfer engine [%s] is not registered, use one of: [%s]."
+	InvalidItineraryMessage                   = "The itinerary [%s] is unknown or conflicts with the verifyOnly, preview or speedTest of the spec, use one of: [%s]."
+	InvalidTunnelEndpointsMessage             = "The tunnel endpoints must have a host and a port for a distinct destination namespace of the PVCs: []."
+	NoEndpointTypeAvailableMessage            = "No usable endpoint type was found for the auto endpoint type, the destination cluster doesn't serve the Route API and the clusters don't share a flat network. Set a tunnel endpoint or another endpoint type."
+	TunnelEndpointsUnreachableMessage         = "The tunnel endpoints are unreachable from mig-controller, the transfer fails unless the Rsync client Pods of the source cluster can reach them: []."
+	DestinationClusterUnreachableMessage      = "The client of destination cluster [%s] cannot be built, check its credentials and coordinates: %s."
+	SourceClusterUnreachableMessage           = "The source cluster [%s] is unreachable, the transfer is paused until it is reachable again."
+	SourceClusterRecoveredMessage             = "The source cluster [%s] was unreachable for %s, the transfer resumed."
+	FailedRsyncOperationsMessage              = "Rsync failed for PVCs []."
+	DestinationVolumeFullMessage              = "The destination volumes of the PVCs [] are full, increase the capacity of the destination PVCs."
+	DestinationPVCsExpandingMessage           = "Waiting for the destination PVCs to be expanded to fit the source data, the migration fails if they are not expanded within %v."
+	DestinationPVCsNotExpandableMessage       = "The destination PVCs are smaller than the source data and their storage class does not allow volume expansion."
+	RsyncCompletedWithWarningsMessage         = "Rsync completed with warnings for PVCs []."
+	SourceNotQuiescedMessage                  = "The PVCs are copied while running Pods mount them read-write, the migrated data may be inconsistent: []."
+	QuiesceNotHonoredMessage                  = "The Pods were requested to be quiesced but still mount the PVCs read-write, the migrated data may be inconsistent: []."
+	ThroughputBelowBaselineMessage            = "The transfer rate of the migration [%.2f MB/s] is significantly below its baseline throughput [%d MB/s]."
+	InvalidBaselineThroughputMessage          = "The baselineThroughput must be greater than 0."
+	InvalidSpeedTestSizeMessage               = "The size of the speed test must be greater than 0."
+	ClockSkewDetectedMessage                  = "The clock skew between the source and destination clusters [%v] exceeds [%v], Rsync compares the files by checksum."
+	UnsupportedTargetAccessModesMessage       = "The access modes of the destination PVCs are not supported by their storage class on the destination cluster: []."
+	PVCsUnchangedMessage                      = "The destination PVCs already matched the source PVCs, they were not transferred: []."
+	DestinationConfigMissingMessage           = "The ConfigMaps and Secrets required by the workloads of the PVCs are missing on the destination cluster: []."
+	PVCsSkippedMessage                        = "The PVCs already transferred before the Rsync transfer was restarted are skipped: []."
+	OpenFilesLimitRaisedMessage               = "The open files limit of the Rsync Pods is raised for the PVCs with many files: []."
+	RsyncRouteRejectedMessage                 = "The Rsync transfer Routes were rejected by the routers of the destination cluster, fix the host or the allowed domains of the routers: []."
+	EndpointReadyMessage                      = "The Rsync transfer endpoints are provisioned and ready."
+	VerifyingDataMessage                      = "The data of the PVCs is transferred, the migration completes once the data of the verified PVCs is verified: []."
+	VerificationDifferencesFoundMessage       = "Files differ between the source and destination of [%d] PVC(s), see the differences of their Rsync operations."
+	DuplicatePVCsMessage                      = "The persistent volume claims [] are listed more than once, a source PVC can only be migrated to a single destination."
+	NoVolumesToMigrateMessage                 = "The migration has no persistent volume claims to migrate, it completed without transferring any data."
+	PVCsExpectedMessage                       = "The migration plan selects persistent volumes to copy with the direct volume migration, but the set of persistent volume claims is empty."
+	InvalidExistingPVCPolicyMessage           = "The existingPVCPolicy [%s] is invalid, use one of: [adopt, fail, recreate]."
+	InvalidDeltaSinceCheckpointMessage        = "The deltaSinceCheckpoint requires the existingPVCPolicy adopt, the destination PVCs holding the checkpoint are reused."
 )
 
 // Categories
@@ -93,6 +229,94 @@ func (r ReconcileDirectVolumeMigration) validate(ctx context.Context, direct *mi
 	if err != nil {
 		return liberr.Wrap(err)
 	}
+	err = r.validateDuplicatePVCs(ctx, direct)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	err = r.validateTargetAccessModes(ctx, direct)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	err = r.validateStunnelProxy(ctx, direct)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	err = r.validateHooks(ctx, direct)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	err = r.validateRsyncUser(ctx, direct)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	err = r.validateRsyncSizeFilters(ctx, direct)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	err = r.validateRsyncSparse(ctx, direct)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	err = r.validateBaselineThroughput(ctx, direct)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	err = r.validateSpeedTest(ctx, direct)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	err = r.validateItinerary(ctx, direct)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	err = r.validateRsyncShards(ctx, direct)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	err = r.validateLargeFileStreams(ctx, direct)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	err = r.validatePVCAnnotations(ctx, direct)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	err = r.validateRsyncPodActiveDeadline(ctx, direct)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	err = r.validateEndpointType(ctx, direct)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	err = r.validateRsyncTuning(ctx, direct)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	err = r.validateRsyncFilter(ctx, direct)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	err = r.validateTTL(ctx, direct)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	err = r.validateRequeueIntervals(ctx, direct)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	err = r.validateTunnelEndpoints(ctx, direct)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	err = r.validateTransferEngine(ctx, direct)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	err = r.validateExistingPVCPolicy(ctx, direct)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
 	return nil
 }
 
@@ -222,15 +446,21 @@ func (r ReconcileDirectVolumeMigration) validatePVCs(ctx context.Context, direct
 
 	allPVCs := direct.Spec.PersistentVolumeClaims
 
-	// Check if PVCs were set
-	if allPVCs == nil {
-		direct.Status.SetCondition(migapi.Condition{
-			Type:     InvalidPVCs,
-			Status:   True,
-			Reason:   NotSet,
-			Category: Critical,
-			Message:  InvalidPVCsMessage,
-		})
+	// A migration without PVCs completes at once, unless its plan expected PVCs
+	if len(allPVCs) == 0 {
+		expected, err := r.hasPlanDirectVolumes(direct)
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		if expected {
+			direct.Status.SetCondition(migapi.Condition{
+				Type:     InvalidPVCs,
+				Status:   True,
+				Reason:   NotSet,
+				Category: Critical,
+				Message:  PVCsExpectedMessage,
+			})
+		}
 		return nil
 	}
 	// Get source cluster client
@@ -248,6 +478,7 @@ func (r ReconcileDirectVolumeMigration) validatePVCs(ctx context.Context, direct
 	// Check if these PVCs actually exist on the source
 	// cluster
 	notFound := make([]string, 0)
+	pvsNotFound := make([]string, 0)
 	for _, specPVC := range allPVCs {
 		// Check if pvc actually exists and is bound on source cluster
 		// TODO: Check if PVC is actually attached. We should
@@ -255,15 +486,36 @@ func (r ReconcileDirectVolumeMigration) validatePVCs(ctx context.Context, direct
 		pvc := kapi.PersistentVolumeClaim{}
 		key := types.NamespacedName{Name: specPVC.Name, Namespace: specPVC.Namespace}
 		err = client.Get(context.TODO(), key, &pvc)
-		if err == nil {
+		if k8serror.IsNotFound(err) {
+			notFound = append(notFound, specPVC.Name)
+			continue
+		} else if err != nil {
+			return liberr.Wrap(err)
+		}
+		// Check if the PV the pvc is bound to still exists, its Rsync
+		// client Pod would never start otherwise
+		if pvc.Spec.VolumeName == "" {
 			continue
 		}
+		pv := kapi.PersistentVolume{}
+		err = client.Get(context.TODO(), types.NamespacedName{Name: pvc.Spec.VolumeName}, &pv)
 		if k8serror.IsNotFound(err) {
-			notFound = append(notFound, specPVC.Name)
-		} else {
+			pvsNotFound = append(pvsNotFound,
+				fmt.Sprintf("%s: %s", path.Join(pvc.Namespace, pvc.Name), pvc.Spec.VolumeName))
+		} else if err != nil {
 			return liberr.Wrap(err)
 		}
 	}
+	if len(pvsNotFound) > 0 {
+		direct.Status.SetCondition(migapi.Condition{
+			Type:     SourcePVsNotFound,
+			Status:   True,
+			Reason:   NotFound,
+			Category: Critical,
+			Message:  SourcePVsNotFoundMessage,
+			Items:    pvsNotFound,
+		})
+	}
 	if len(notFound) > 0 {
 		direct.Status.SetCondition(migapi.Condition{
 			Type:     PVCsNotFoundOnSourceCluster,
@@ -277,3 +529,846 @@ func (r ReconcileDirectVolumeMigration) validatePVCs(ctx context.Context, direct
 	}
 	return nil
 }
+
+// Get whether the plan of the MigMigration owning the DVM selects persistent
+// volumes to copy with the direct volume migration, the PVCs the DVM migrates.
+func (r ReconcileDirectVolumeMigration) hasPlanDirectVolumes(direct *migapi.DirectVolumeMigration) (bool, error) {
+	migration, err := direct.GetMigrationForDVM(r)
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	if migration == nil {
+		return false, nil
+	}
+	plan, err := migration.GetPlan(r)
+	if err != nil {
+		return false, liberr.Wrap(err)
+	}
+	if plan == nil || plan.Spec.IndirectVolumeMigration {
+		return false, nil
+	}
+	for _, pv := range plan.Spec.PersistentVolumes.List {
+		if pv.Selection.Action == migapi.PvCopyAction && pv.Selection.CopyMethod == migapi.PvFilesystemCopyMethod {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Validate that each source PVC is listed once, migrating the same PVC to
+// several destinations is not supported.
+func (r ReconcileDirectVolumeMigration) validateDuplicatePVCs(ctx context.Context, direct *migapi.DirectVolumeMigration) error {
+	if opentracing.SpanFromContext(ctx) != nil {
+		span, _ := opentracing.StartSpanFromContextWithTracer(ctx, r.tracer, "validateDuplicatePVCs")
+		defer span.Finish()
+	}
+
+	seen := map[string]int{}
+	duplicates := []string{}
+	for _, pvc := range direct.Spec.PersistentVolumeClaims {
+		if pvc.ObjectReference == nil {
+			continue
+		}
+		key := path.Join(pvc.Namespace, pvc.Name)
+		seen[key]++
+		if seen[key] == 2 {
+			duplicates = append(duplicates, key)
+		}
+	}
+	if len(duplicates) > 0 {
+		direct.Status.SetCondition(migapi.Condition{
+			Type:     DuplicatePVCs,
+			Status:   True,
+			Reason:   NotDistinct,
+			Category: Critical,
+			Message:  DuplicatePVCsMessage,
+			Items:    duplicates,
+		})
+	}
+	return nil
+}
+
+// Validate the stunnel TCP proxy settings and the referenced credentials Secret.
+func (r ReconcileDirectVolumeMigration) validateStunnelProxy(ctx context.Context, direct *migapi.DirectVolumeMigration) error {
+	if opentracing.SpanFromContext(ctx) != nil {
+		span, _ := opentracing.StartSpanFromContextWithTracer(ctx, r.tracer, "validateStunnelProxy")
+		defer span.Finish()
+	}
+
+	proxy := settings.Settings.DvmOpts.StunnelTCPProxy
+	if proxy == "" {
+		return nil
+	}
+	if problem := getStunnelProxyProblem(proxy); problem != "" {
+		direct.Status.SetCondition(migapi.Condition{
+			Type:     InvalidStunnelProxy,
+			Status:   True,
+			Reason:   Malformed,
+			Category: Critical,
+			Message:  fmt.Sprintf(InvalidStunnelProxyMessage, settings.TCPProxyKey, problem),
+		})
+		return nil
+	}
+
+	if settings.Settings.DvmOpts.StunnelTCPProxySecret == "" {
+		return nil
+	}
+	secret, err := getStunnelProxySecret(r)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	if secret == nil {
+		direct.Status.SetCondition(migapi.Condition{
+			Type:     InvalidStunnelProxySecret,
+			Status:   True,
+			Reason:   NotFound,
+			Category: Critical,
+			Message: fmt.Sprintf(InvalidStunnelProxySecretMessage,
+				path.Join(migapi.OpenshiftMigrationNamespace, settings.Settings.DvmOpts.StunnelTCPProxySecret)),
+		})
+	}
+	return nil
+}
+
+// Get the problem of the stunnel TCP proxy setting, empty when valid. The proxy
+// is a URL with a scheme, a host and a port, stunnel connecting to host:port.
+func getStunnelProxyProblem(proxy string) string {
+	proxyURL, err := url.Parse(proxy)
+	if err != nil {
+		return redactURLError(err).Error()
+	}
+	if proxyURL.Scheme == "" {
+		return "the scheme is missing"
+	}
+	if proxyURL.Scheme != "http" && proxyURL.Scheme != "https" {
+		return fmt.Sprintf("the scheme %s is not supported, only HTTP CONNECT proxies are", proxyURL.Scheme)
+	}
+	if proxyURL.Hostname() == "" {
+		return "the host is missing"
+	}
+	if proxyURL.Port() == "" {
+		return "the port is missing"
+	}
+	return ""
+}
+
+// Validate the hooks reference a MigHook and are attached to a supported phase.
+func (r ReconcileDirectVolumeMigration) validateHooks(ctx context.Context, direct *migapi.DirectVolumeMigration) error {
+	if opentracing.SpanFromContext(ctx) != nil {
+		span, _ := opentracing.StartSpanFromContextWithTracer(ctx, r.tracer, "validateHooks")
+		defer span.Finish()
+	}
+
+	invalid := []string{}
+	for i, hook := range direct.Spec.Hooks {
+		switch {
+		case !migref.RefSet(hook.Reference):
+			invalid = append(invalid, fmt.Sprintf("hooks[%d]: reference not set", i))
+		case hook.Phase != migapi.PreTransferHookPhase && hook.Phase != migapi.PostTransferHookPhase:
+			invalid = append(invalid, fmt.Sprintf("hooks[%d]: phase %s not supported", i, hook.Phase))
+		}
+	}
+	if direct.Spec.RequireHookConsistency && !hasHookPhase(direct.Spec.Hooks, migapi.PreTransferHookPhase) {
+		invalid = append(invalid, "requireHookConsistency: no PreTransfer hook confirms the consistency")
+	}
+	if len(invalid) > 0 {
+		direct.Status.SetCondition(migapi.Condition{
+			Type:     InvalidHooks,
+			Status:   True,
+			Reason:   NotSupported,
+			Category: Critical,
+			Message:  InvalidHooksMessage,
+			Items:    invalid,
+		})
+	}
+	return nil
+}
+
+// Validate the UID, GID and fsGroup owning the files written on the destination.
+// They are applied with rsync --chown by the Rsync daemon on the destination
+// which runs as root, the range is the one accepted for a Pod runAsUser.
+// Whether the SCC or the Pod security admission of the destination namespace
+// admits the Rsync transfer Pod is not checked, a rejected Pod is reported
+// when the transfer Pod is created.
+func (r ReconcileDirectVolumeMigration) validateRsyncUser(ctx context.Context, direct *migapi.DirectVolumeMigration) error {
+	if opentracing.SpanFromContext(ctx) != nil {
+		span, _ := opentracing.StartSpanFromContextWithTracer(ctx, r.tracer, "validateRsyncUser")
+		defer span.Finish()
+	}
+
+	invalid := []string{}
+	if uid := direct.Spec.RsyncUID; uid != nil && (*uid < 0 || *uid > math.MaxInt32) {
+		invalid = append(invalid, fmt.Sprintf("rsyncUID: %d", *uid))
+	}
+	if gid := direct.Spec.RsyncGID; gid != nil && (*gid < 0 || *gid > math.MaxInt32) {
+		invalid = append(invalid, fmt.Sprintf("rsyncGID: %d", *gid))
+	}
+	if fsGroup := direct.Spec.DestinationFSGroup; fsGroup != nil && (*fsGroup < 0 || *fsGroup > math.MaxInt32) {
+		invalid = append(invalid, fmt.Sprintf("destinationFSGroup: %d", *fsGroup))
+	}
+	if len(invalid) > 0 {
+		direct.Status.SetCondition(migapi.Condition{
+			Type:     InvalidRsyncUser,
+			Status:   True,
+			Reason:   Malformed,
+			Category: Critical,
+			Message:  fmt.Sprintf(InvalidRsyncUserMessage, math.MaxInt32),
+			Items:    invalid,
+		})
+	}
+	return nil
+}
+
+// Validate the sparse mode of PVCs.
+func (r ReconcileDirectVolumeMigration) validateRsyncSparse(ctx context.Context, direct *migapi.DirectVolumeMigration) error {
+	if opentracing.SpanFromContext(ctx) != nil {
+		span, _ := opentracing.StartSpanFromContextWithTracer(ctx, r.tracer, "validateRsyncSparse")
+		defer span.Finish()
+	}
+
+	invalid := []string{}
+	for _, pvc := range direct.Spec.PersistentVolumeClaims {
+		switch pvc.Sparse {
+		case "", migapi.SparseAuto, migapi.SparseAlways, migapi.SparseNever:
+		default:
+			invalid = append(invalid, fmt.Sprintf("%s: sparse %s", path.Join(pvc.Namespace, pvc.Name), pvc.Sparse))
+		}
+	}
+	if len(invalid) > 0 {
+		direct.Status.SetCondition(migapi.Condition{
+			Type:     InvalidRsyncSparse,
+			Status:   True,
+			Reason:   NotSupported,
+			Category: Critical,
+			Message:  InvalidRsyncSparseMessage,
+			Items:    invalid,
+		})
+	}
+	return nil
+}
+
+// Validate the maxSize and minSize filters of PVCs parse as rsync sizes.
+func (r ReconcileDirectVolumeMigration) validateRsyncSizeFilters(ctx context.Context, direct *migapi.DirectVolumeMigration) error {
+	if opentracing.SpanFromContext(ctx) != nil {
+		span, _ := opentracing.StartSpanFromContextWithTracer(ctx, r.tracer, "validateRsyncSizeFilters")
+		defer span.Finish()
+	}
+
+	invalid := []string{}
+	for _, pvc := range direct.Spec.PersistentVolumeClaims {
+		if pvc.MaxSize != "" && !isValidRsyncSize(pvc.MaxSize) {
+			invalid = append(invalid, fmt.Sprintf("%s: maxSize %s", path.Join(pvc.Namespace, pvc.Name), pvc.MaxSize))
+		}
+		if pvc.MinSize != "" && !isValidRsyncSize(pvc.MinSize) {
+			invalid = append(invalid, fmt.Sprintf("%s: minSize %s", path.Join(pvc.Namespace, pvc.Name), pvc.MinSize))
+		}
+	}
+	if len(invalid) > 0 {
+		direct.Status.SetCondition(migapi.Condition{
+			Type:     InvalidRsyncSizeFilters,
+			Status:   True,
+			Reason:   Malformed,
+			Category: Critical,
+			Message:  InvalidRsyncSizeFiltersMessage,
+			Items:    invalid,
+		})
+	}
+	return nil
+}
+
+// Validate the number of Rsync processes the PVCs are split among.
+func (r ReconcileDirectVolumeMigration) validateRsyncShards(ctx context.Context, direct *migapi.DirectVolumeMigration) error {
+	if opentracing.SpanFromContext(ctx) != nil {
+		span, _ := opentracing.StartSpanFromContextWithTracer(ctx, r.tracer, "validateRsyncShards")
+		defer span.Finish()
+	}
+
+	invalid := []string{}
+	for _, pvc := range direct.Spec.PersistentVolumeClaims {
+		if pvc.Shards < 0 || pvc.Shards > MaxRsyncShards {
+			invalid = append(invalid, fmt.Sprintf("%s: shards %d", path.Join(pvc.Namespace, pvc.Name), pvc.Shards))
+		}
+	}
+	if len(invalid) > 0 {
+		direct.Status.SetCondition(migapi.Condition{
+			Type:     InvalidRsyncShards,
+			Status:   True,
+			Reason:   Malformed,
+			Category: Critical,
+			Message:  fmt.Sprintf(InvalidRsyncShardsMessage, MaxRsyncShards),
+			Items:    invalid,
+		})
+	}
+	return nil
+}
+
+// Validate the number of parallel Rsync streams the large files of the PVCs are split among.
+func (r ReconcileDirectVolumeMigration) validateLargeFileStreams(ctx context.Context, direct *migapi.DirectVolumeMigration) error {
+	if opentracing.SpanFromContext(ctx) != nil {
+		span, _ := opentracing.StartSpanFromContextWithTracer(ctx, r.tracer, "validateLargeFileStreams")
+		defer span.Finish()
+	}
+
+	invalid := []string{}
+	for _, pvc := range direct.Spec.PersistentVolumeClaims {
+		if pvc.LargeFileStreams < 0 || pvc.LargeFileStreams > MaxLargeFileStreams {
+			invalid = append(invalid, fmt.Sprintf("%s: largeFileStreams %d", path.Join(pvc.Namespace, pvc.Name), pvc.LargeFileStreams))
+		}
+	}
+	if len(invalid) > 0 {
+		direct.Status.SetCondition(migapi.Condition{
+			Type:     InvalidLargeFileStreams,
+			Status:   True,
+			Reason:   Malformed,
+			Category: Critical,
+			Message:  fmt.Sprintf(InvalidLargeFileStreamsMessage, MaxLargeFileStreams),
+			Items:    invalid,
+		})
+	}
+	return nil
+}
+
+// Validate the annotations of the destination PVCs.
+func (r ReconcileDirectVolumeMigration) validatePVCAnnotations(ctx context.Context, direct *migapi.DirectVolumeMigration) error {
+	if opentracing.SpanFromContext(ctx) != nil {
+		span, _ := opentracing.StartSpanFromContextWithTracer(ctx, r.tracer, "validatePVCAnnotations")
+		defer span.Finish()
+	}
+
+	problems := getPVCAnnotationsProblems(direct.Spec.PVCAnnotations)
+	if len(problems) > 0 {
+		direct.Status.SetCondition(migapi.Condition{
+			Type:     InvalidPVCAnnotations,
+			Status:   True,
+			Reason:   Malformed,
+			Category: Critical,
+			Message:  InvalidPVCAnnotationsMessage,
+			Items:    problems,
+		})
+	}
+	return nil
+}
+
+// Validate the active deadline of the Rsync Pods.
+func (r ReconcileDirectVolumeMigration) validateRsyncPodActiveDeadline(ctx context.Context, direct *migapi.DirectVolumeMigration) error {
+	if opentracing.SpanFromContext(ctx) != nil {
+		span, _ := opentracing.StartSpanFromContextWithTracer(ctx, r.tracer, "validateRsyncPodActiveDeadline")
+		defer span.Finish()
+	}
+
+	deadline := direct.Spec.RsyncPodActiveDeadlineSeconds
+	if deadline != nil && *deadline <= 0 {
+		direct.Status.SetCondition(migapi.Condition{
+			Type:     InvalidRsyncPodActiveDeadline,
+			Status:   True,
+			Reason:   Malformed,
+			Category: Critical,
+			Message:  InvalidRsyncPodActiveDeadlineMessage,
+		})
+	}
+	return nil
+}
+
+// Validate that the storage class of each destination PVC supports the
+// target access modes of the PVC. The access modes supported by a storage
+// class are those of its provisioner, the storage classes of provisioners
+// which access modes aren't known are not validated. The storage classes are
+// only read from the destination cluster until the migration starts and when
+// target access modes are set.
+func (r ReconcileDirectVolumeMigration) validateTargetAccessModes(ctx context.Context, direct *migapi.DirectVolumeMigration) error {
+	if opentracing.SpanFromContext(ctx) != nil {
+		span, _ := opentracing.StartSpanFromContextWithTracer(ctx, r.tracer, "validateTargetAccessModes")
+		defer span.Finish()
+	}
+
+	if direct.Status.StartTimestamp != nil || !hasTargetAccessModes(direct.Spec.PersistentVolumeClaims) {
+		return nil
+	}
+	cluster, err := direct.GetDestinationCluster(r)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	if cluster == nil || !cluster.Status.IsReady() {
+		return nil
+	}
+	client, err := cluster.GetClient(r)
+	if err != nil {
+		setDestinationClusterUnreachable(direct, &destinationClientError{
+			cluster: path.Join(cluster.Namespace, cluster.Name),
+			err:     err,
+		})
+		return nil
+	}
+	clusterStorageClasses, err := cluster.GetStorageClasses(client)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	storageClasses := []migapi.StorageClass{}
+	for _, storageClass := range clusterStorageClasses {
+		if cluster.HasKnownAccessModes(storageClass.Provisioner) {
+			storageClasses = append(storageClasses, storageClass)
+		}
+	}
+	unsupported := getUnsupportedTargetAccessModes(direct.Spec.PersistentVolumeClaims, storageClasses)
+	if len(unsupported) > 0 {
+		direct.Status.SetCondition(migapi.Condition{
+			Type:     UnsupportedTargetAccessModes,
+			Status:   True,
+			Reason:   NotSupported,
+			Category: Critical,
+			Message:  UnsupportedTargetAccessModesMessage,
+			Items:    unsupported,
+		})
+	}
+	return nil
+}
+
+// Whether target access modes are set on any of the PVCs.
+func hasTargetAccessModes(pvcs []migapi.PVCToMigrate) bool {
+	for _, pvc := range pvcs {
+		if len(pvc.TargetAccessModes) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Get the PVCs which target access modes are not supported by their target
+// storage class, with the unsupported modes. PVCs which storage class is not
+// found are left to the provisioning of the destination PVCs.
+func getUnsupportedTargetAccessModes(pvcs []migapi.PVCToMigrate, storageClasses []migapi.StorageClass) []string {
+	supported := map[string]map[kapi.PersistentVolumeAccessMode]bool{}
+	for _, storageClass := range storageClasses {
+		supported[storageClass.Name] = map[kapi.PersistentVolumeAccessMode]bool{}
+		for _, mode := range storageClass.AccessModes {
+			supported[storageClass.Name][mode] = true
+		}
+	}
+	unsupported := []string{}
+	for _, pvc := range pvcs {
+		modes, found := supported[pvc.TargetStorageClass]
+		if !found {
+			continue
+		}
+		for _, mode := range pvc.TargetAccessModes {
+			if !modes[mode] {
+				unsupported = append(unsupported,
+					fmt.Sprintf("%s: %s on %s", path.Join(pvc.Namespace, pvc.Name), mode, pvc.TargetStorageClass))
+			}
+		}
+	}
+	return unsupported
+}
+
+// Validate the baseline throughput of the migration.
+func (r ReconcileDirectVolumeMigration) validateBaselineThroughput(ctx context.Context, direct *migapi.DirectVolumeMigration) error {
+	if opentracing.SpanFromContext(ctx) != nil {
+		span, _ := opentracing.StartSpanFromContextWithTracer(ctx, r.tracer, "validateBaselineThroughput")
+		defer span.Finish()
+	}
+
+	baseline := direct.Spec.BaselineThroughput
+	if baseline != nil && *baseline <= 0 {
+		direct.Status.SetCondition(migapi.Condition{
+			Type:     InvalidBaselineThroughput,
+			Status:   True,
+			Reason:   Malformed,
+			Category: Critical,
+			Message:  InvalidBaselineThroughputMessage,
+		})
+	}
+	return nil
+}
+
+// Validate the size of the data generated by the speed test.
+func (r ReconcileDirectVolumeMigration) validateSpeedTest(ctx context.Context, direct *migapi.DirectVolumeMigration) error {
+	if opentracing.SpanFromContext(ctx) != nil {
+		span, _ := opentracing.StartSpanFromContextWithTracer(ctx, r.tracer, "validateSpeedTest")
+		defer span.Finish()
+	}
+
+	speedTest := direct.Spec.SpeedTest
+	if speedTest != nil && speedTest.Size != nil && speedTest.Size.Sign() <= 0 {
+		direct.Status.SetCondition(migapi.Condition{
+			Type:     InvalidSpeedTestSize,
+			Status:   True,
+			Reason:   Malformed,
+			Category: Critical,
+			Message:  InvalidSpeedTestSizeMessage,
+		})
+	}
+	return nil
+}
+
+// Validate the itinerary selected by the spec is selectable and agrees with the
+// flags of the spec selecting an itinerary.
+func (r ReconcileDirectVolumeMigration) validateItinerary(ctx context.Context, direct *migapi.DirectVolumeMigration) error {
+	if opentracing.SpanFromContext(ctx) != nil {
+		span, _ := opentracing.StartSpanFromContextWithTracer(ctx, r.tracer, "validateItinerary")
+		defer span.Finish()
+	}
+
+	name := direct.Spec.Itinerary
+	if name == "" {
+		return nil
+	}
+	flagged := getFlaggedItinerary(direct)
+	if getSelectableItinerary(name) != nil && (flagged == "" || flagged == name) {
+		return nil
+	}
+	names := []string{}
+	for _, itinerary := range SelectableItineraries {
+		names = append(names, itinerary.Name)
+	}
+	direct.Status.SetCondition(migapi.Condition{
+		Type:     InvalidItinerary,
+		Status:   True,
+		Reason:   NotSupported,
+		Category: Critical,
+		Message:  fmt.Sprintf(InvalidItineraryMessage, name, strings.Join(names, ", ")),
+	})
+	return nil
+}
+
+// Validate the endpoint type rules set in the cluster ConfigMap of the destination cluster.
+func (r ReconcileDirectVolumeMigration) validateEndpointType(ctx context.Context, direct *migapi.DirectVolumeMigration) error {
+	if opentracing.SpanFromContext(ctx) != nil {
+		span, _ := opentracing.StartSpanFromContextWithTracer(ctx, r.tracer, "validateEndpointType")
+		defer span.Finish()
+	}
+
+	cluster, err := direct.GetDestinationCluster(r)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	if cluster == nil || !cluster.Status.IsReady() {
+		return nil
+	}
+	_, err = cluster.GetClient(r)
+	if err != nil {
+		setDestinationClusterUnreachable(direct, &destinationClientError{
+			cluster: path.Join(cluster.Namespace, cluster.Name),
+			err:     err,
+		})
+		return nil
+	}
+	value, err := cluster.GetRsyncEndpointType(r)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	_, err = parseEndpointTypeRules(value)
+	if err != nil {
+		direct.Status.SetCondition(migapi.Condition{
+			Type:     InvalidEndpointType,
+			Status:   True,
+			Reason:   NotSupported,
+			Category: Critical,
+			Message:  fmt.Sprintf(InvalidEndpointTypeMessage, err.Error()),
+		})
+	}
+	return nil
+}
+
+// Validate the Rsync tuning set in the spec and in the cluster ConfigMap of the destination cluster.
+func (r ReconcileDirectVolumeMigration) validateRsyncTuning(ctx context.Context, direct *migapi.DirectVolumeMigration) error {
+	if opentracing.SpanFromContext(ctx) != nil {
+		span, _ := opentracing.StartSpanFromContextWithTracer(ctx, r.tracer, "validateRsyncTuning")
+		defer span.Finish()
+	}
+
+	invalid := []string{}
+	if direct.Spec.RsyncBwLimit != nil && *direct.Spec.RsyncBwLimit < 0 {
+		invalid = append(invalid, "rsyncBwLimit must not be negative")
+	}
+	if direct.Spec.RsyncBwLimitRampUp != nil && direct.Spec.RsyncBwLimitRampUp.Duration <= 0 {
+		invalid = append(invalid, "rsyncBwLimitRampUp must be greater than 0")
+	}
+	if direct.Spec.RsyncErrorGraceWindow != nil && direct.Spec.RsyncErrorGraceWindow.Duration < 0 {
+		invalid = append(invalid, "rsyncErrorGraceWindow must not be negative")
+	}
+	if direct.Spec.RsyncTimeout != nil && *direct.Spec.RsyncTimeout < 0 {
+		invalid = append(invalid, "rsyncTimeout must not be negative")
+	}
+	if direct.Spec.RsyncOpenFilesLimit != nil && *direct.Spec.RsyncOpenFilesLimit <= 0 {
+		invalid = append(invalid, "rsyncOpenFilesLimit must be greater than 0")
+	}
+	if level := direct.Spec.RsyncCompressLevel; level != nil && (*level < MinRsyncCompressLevel || *level > MaxRsyncCompressLevel) {
+		invalid = append(invalid, fmt.Sprintf("rsyncCompressLevel must be in the range [%d, %d]", MinRsyncCompressLevel, MaxRsyncCompressLevel))
+	}
+	if direct.Spec.RsyncModifyWindow != nil && *direct.Spec.RsyncModifyWindow < 0 {
+		invalid = append(invalid, "rsyncModifyWindow must not be negative")
+	}
+	switch direct.Spec.WholeFile {
+	case "", migapi.WholeFileAuto, migapi.WholeFileOn, migapi.WholeFileOff:
+	default:
+		invalid = append(invalid, "wholeFile must be one of auto, on, off")
+	}
+	switch direct.Spec.UnsafeLinks {
+	case "", migapi.UnsafeLinksDrop, migapi.UnsafeLinksCopy, migapi.UnsafeLinksKeep:
+	default:
+		invalid = append(invalid, "unsafeLinks must be one of drop, copy, keep")
+	}
+	switch direct.Spec.VanishedFiles {
+	case "", migapi.VanishedFilesIgnore, migapi.VanishedFilesWarn, migapi.VanishedFilesFail:
+	default:
+		invalid = append(invalid, "vanishedFiles must be one of ignore, warn, fail")
+	}
+	if direct.Spec.MaxConcurrentTransfers != nil && *direct.Spec.MaxConcurrentTransfers <= 0 {
+		invalid = append(invalid, "maxConcurrentTransfers must be greater than 0")
+	}
+	switch direct.Spec.TransferScheduling {
+	case "", migapi.TransferSchedulingFIFO, migapi.TransferSchedulingSizeBalanced:
+	default:
+		invalid = append(invalid, "transferScheduling must be one of fifo, size-balanced")
+	}
+	if tempDir := direct.Spec.RsyncTempDir; tempDir != "" && !isValidRsyncTempDir(tempDir) {
+		invalid = append(invalid, "rsyncTempDir must be a relative path within the volume without spaces")
+	}
+	cluster, err := direct.GetDestinationCluster(r)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	if cluster != nil && cluster.Status.IsReady() {
+		data, err := cluster.GetRsyncTransferTuning(r)
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		_, err = parseRsyncTuning(data)
+		if err != nil {
+			invalid = append(invalid, fmt.Sprintf("%s of the destination cluster", err.Error()))
+		}
+	}
+	if len(invalid) > 0 {
+		direct.Status.SetCondition(migapi.Condition{
+			Type:     InvalidRsyncTuning,
+			Status:   True,
+			Reason:   Malformed,
+			Category: Critical,
+			Message:  fmt.Sprintf(InvalidRsyncTuningMessage, strings.Join(invalid, ", ")),
+		})
+	}
+	return nil
+}
+
+func (r ReconcileDirectVolumeMigration) validateRsyncFilter(ctx context.Context, direct *migapi.DirectVolumeMigration) error {
+	if opentracing.SpanFromContext(ctx) != nil {
+		span, _ := opentracing.StartSpanFromContextWithTracer(ctx, r.tracer, "validateRsyncFilter")
+		defer span.Finish()
+	}
+
+	filter := direct.Spec.RsyncFilter
+	if filter == nil {
+		return nil
+	}
+	for _, exclude := range filter.Excludes {
+		if strings.TrimSpace(exclude) == "" || strings.ContainsAny(exclude, "\r\n") {
+			direct.Status.SetCondition(migapi.Condition{
+				Type:     InvalidRsyncFilter,
+				Status:   True,
+				Reason:   Malformed,
+				Category: Critical,
+				Message:  fmt.Sprintf(InvalidRsyncFilterMessage, "excludes must be single line, non-empty patterns"),
+			})
+			return nil
+		}
+	}
+	ref := filter.ConfigMapRef
+	if ref == nil {
+		return nil
+	}
+	if !migref.RefSet(ref) {
+		direct.Status.SetCondition(migapi.Condition{
+			Type:     InvalidRsyncFilter,
+			Status:   True,
+			Reason:   NotSet,
+			Category: Critical,
+			Message:  fmt.Sprintf(InvalidRsyncFilterMessage, "configMapRef must set the namespace and name of the ConfigMap"),
+		})
+		return nil
+	}
+	configMap, err := migapi.GetConfigMap(r, ref)
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	if configMap == nil {
+		direct.Status.SetCondition(migapi.Condition{
+			Type:     InvalidRsyncFilter,
+			Status:   True,
+			Reason:   NotFound,
+			Category: Critical,
+			Message: fmt.Sprintf(InvalidRsyncFilterMessage,
+				fmt.Sprintf("ConfigMap %s not found", path.Join(ref.Namespace, ref.Name))),
+		})
+		return nil
+	}
+	key := getRsyncFilterKey(filter)
+	if _, found := configMap.Data[key]; !found {
+		direct.Status.SetCondition(migapi.Condition{
+			Type:     InvalidRsyncFilter,
+			Status:   True,
+			Reason:   NotFound,
+			Category: Critical,
+			Message: fmt.Sprintf(InvalidRsyncFilterMessage,
+				fmt.Sprintf("ConfigMap %s has no key %s", path.Join(ref.Namespace, ref.Name), key)),
+		})
+	}
+	return nil
+}
+
+// Validate the TTLs of the finished DVM.
+func (r ReconcileDirectVolumeMigration) validateTTL(ctx context.Context, direct *migapi.DirectVolumeMigration) error {
+	if opentracing.SpanFromContext(ctx) != nil {
+		span, _ := opentracing.StartSpanFromContextWithTracer(ctx, r.tracer, "validateTTL")
+		defer span.Finish()
+	}
+
+	invalid := []string{}
+	if ttl := direct.Spec.TTLAfterCompleted; ttl != nil && ttl.Duration < 0 {
+		invalid = append(invalid, fmt.Sprintf("ttlAfterCompleted: %s", ttl.Duration))
+	}
+	if ttl := direct.Spec.TTLAfterFailed; ttl != nil && ttl.Duration < 0 {
+		invalid = append(invalid, fmt.Sprintf("ttlAfterFailed: %s", ttl.Duration))
+	}
+	if len(invalid) > 0 {
+		direct.Status.SetCondition(migapi.Condition{
+			Type:     InvalidTTL,
+			Status:   True,
+			Reason:   Malformed,
+			Category: Critical,
+			Message:  InvalidTTLMessage,
+			Items:    invalid,
+		})
+	}
+	return nil
+}
+
+// Validate the requeue intervals are within their bounds.
+func (r ReconcileDirectVolumeMigration) validateRequeueIntervals(ctx context.Context, direct *migapi.DirectVolumeMigration) error {
+	if opentracing.SpanFromContext(ctx) != nil {
+		span, _ := opentracing.StartSpanFromContextWithTracer(ctx, r.tracer, "validateRequeueIntervals")
+		defer span.Finish()
+	}
+
+	intervals := direct.Spec.RequeueIntervals
+	if intervals == nil {
+		return nil
+	}
+	invalid := []string{}
+	if fast := intervals.Fast; fast != nil && (fast.Duration < MinFastRequeue || fast.Duration > MaxFastRequeue) {
+		invalid = append(invalid, fmt.Sprintf("fast: %s", fast.Duration))
+	}
+	if poll := intervals.Poll; poll != nil && (poll.Duration < MinPollRequeue || poll.Duration > MaxPollRequeue) {
+		invalid = append(invalid, fmt.Sprintf("poll: %s", poll.Duration))
+	}
+	if len(invalid) > 0 {
+		direct.Status.SetCondition(migapi.Condition{
+			Type:     InvalidRequeueIntervals,
+			Status:   True,
+			Reason:   Malformed,
+			Category: Critical,
+			Message:  InvalidRequeueIntervalsMessage,
+			Items:    invalid,
+		})
+	}
+	return nil
+}
+
+// Validate the tunnel endpoints provided by the user.
+func (r ReconcileDirectVolumeMigration) validateTunnelEndpoints(ctx context.Context, direct *migapi.DirectVolumeMigration) error {
+	if opentracing.SpanFromContext(ctx) != nil {
+		span, _ := opentracing.StartSpanFromContextWithTracer(ctx, r.tracer, "validateTunnelEndpoints")
+		defer span.Finish()
+	}
+
+	destNamespaces := map[string]bool{}
+	for _, pvc := range direct.Spec.PersistentVolumeClaims {
+		if pvc.ObjectReference == nil {
+			continue
+		}
+		destNs := pvc.Namespace
+		if pvc.TargetNamespace != "" {
+			destNs = pvc.TargetNamespace
+		}
+		destNamespaces[destNs] = true
+	}
+	seen := map[string]bool{}
+	invalid := []string{}
+	for _, tunnel := range direct.Spec.TunnelEndpoints {
+		switch {
+		case !destNamespaces[tunnel.Namespace]:
+			invalid = append(invalid, fmt.Sprintf("%s: not a destination namespace", tunnel.Namespace))
+		case seen[tunnel.Namespace]:
+			invalid = append(invalid, fmt.Sprintf("%s: more than one tunnel", tunnel.Namespace))
+		case tunnel.Host == "":
+			invalid = append(invalid, fmt.Sprintf("%s: host not set", tunnel.Namespace))
+		case tunnel.Port <= 0 || tunnel.Port > 65535:
+			invalid = append(invalid, fmt.Sprintf("%s: invalid port %d", tunnel.Namespace, tunnel.Port))
+		}
+		seen[tunnel.Namespace] = true
+	}
+	if len(invalid) > 0 {
+		direct.Status.SetCondition(migapi.Condition{
+			Type:     InvalidTunnelEndpoints,
+			Status:   True,
+			Reason:   Malformed,
+			Category: Critical,
+			Message:  InvalidTunnelEndpointsMessage,
+			Items:    invalid,
+		})
+	}
+	return nil
+}
+
+func (r ReconcileDirectVolumeMigration) validateTransferEngine(ctx context.Context, direct *migapi.DirectVolumeMigration) error {
+	if opentracing.SpanFromContext(ctx) != nil {
+		span, _ := opentracing.StartSpanFromContextWithTracer(ctx, r.tracer, "validateTransferEngine")
+		defer span.Finish()
+	}
+
+	engine := direct.Spec.TransferEngine
+	if engine == "" {
+		return nil
+	}
+	if _, found := transferEngines[engine]; !found {
+		direct.Status.SetCondition(migapi.Condition{
+			Type:     InvalidTransferEngine,
+			Status:   True,
+			Reason:   NotSupported,
+			Category: Critical,
+			Message:  fmt.Sprintf(InvalidTransferEngineMessage, engine, strings.Join(getTransferEngineNames(), ", ")),
+		})
+	}
+	return nil
+}
+
+// Validate the policy applied when a destination PVC already exists, a delta
+// transfer since a checkpoint adopts the destination PVCs.
+func (r ReconcileDirectVolumeMigration) validateExistingPVCPolicy(ctx context.Context, direct *migapi.DirectVolumeMigration) error {
+	if opentracing.SpanFromContext(ctx) != nil {
+		span, _ := opentracing.StartSpanFromContextWithTracer(ctx, r.tracer, "validateExistingPVCPolicy")
+		defer span.Finish()
+	}
+
+	switch direct.Spec.ExistingPVCPolicy {
+	case "", migapi.ExistingPVCAdopt:
+	case migapi.ExistingPVCFail, migapi.ExistingPVCRecreate:
+		if direct.Spec.DeltaSinceCheckpoint {
+			direct.Status.SetCondition(migapi.Condition{
+				Type:     InvalidDeltaSinceCheckpoint,
+				Status:   True,
+				Reason:   NotSupported,
+				Category: Critical,
+				Message:  InvalidDeltaSinceCheckpointMessage,
+			})
+		}
+	default:
+		direct.Status.SetCondition(migapi.Condition{
+			Type:     InvalidExistingPVCPolicy,
+			Status:   True,
+			Reason:   NotSupported,
+			Category: Critical,
+			Message:  fmt.Sprintf(InvalidExistingPVCPolicyMessage, direct.Spec.ExistingPVCPolicy),
+		})
+	}
+	return nil
+}
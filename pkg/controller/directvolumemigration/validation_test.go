@@ -0,0 +1,249 @@
+package directvolumemigration
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func Test_getUnsupportedTargetAccessModes(t *testing.T) {
+	storageClasses := []migapi.StorageClass{
+		{Name: "block", AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}},
+		{Name: "file", AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce, corev1.ReadWriteMany}},
+	}
+	getPVC := func(storageClass string, modes ...corev1.PersistentVolumeAccessMode) migapi.PVCToMigrate {
+		return migapi.PVCToMigrate{
+			ObjectReference:    &corev1.ObjectReference{Namespace: "ns", Name: "pvc-1"},
+			TargetStorageClass: storageClass,
+			TargetAccessModes:  modes,
+		}
+	}
+	tests := []struct {
+		name string
+		pvcs []migapi.PVCToMigrate
+		want []string
+	}{
+		{
+			name: "when the storage class supports the access modes, should return none",
+			pvcs: []migapi.PVCToMigrate{getPVC("file", corev1.ReadWriteMany)},
+			want: []string{},
+		},
+		{
+			name: "when the storage class does not support an access mode, should return it",
+			pvcs: []migapi.PVCToMigrate{getPVC("block", corev1.ReadWriteOnce, corev1.ReadWriteMany)},
+			want: []string{"ns/pvc-1: ReadWriteMany on block"},
+		},
+		{
+			name: "when the storage class is not found, should return none",
+			pvcs: []migapi.PVCToMigrate{getPVC("unknown", corev1.ReadWriteMany)},
+			want: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getUnsupportedTargetAccessModes(tt.pvcs, storageClasses); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getUnsupportedTargetAccessModes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconcileDirectVolumeMigration_validateItinerary(t *testing.T) {
+	tests := []struct {
+		name       string
+		itinerary  string
+		verifyOnly bool
+		preview    bool
+		wantValid  bool
+	}{
+		{name: "when not set, should be valid", wantValid: true},
+		{name: "when selectable, should be valid", itinerary: VerifyOnlyMigration.Name, wantValid: true},
+		{name: "when it agrees with the flags, should be valid", itinerary: VerifyOnlyMigration.Name, verifyOnly: true, wantValid: true},
+		{name: "when unknown, should be invalid", itinerary: "ContinuousMigration", wantValid: false},
+		{name: "when not selectable, should be invalid", itinerary: EngineMigration.Name, wantValid: false},
+		{name: "when it conflicts with the flags, should be invalid", itinerary: VolumeMigration.Name, preview: true, wantValid: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			direct := &migapi.DirectVolumeMigration{
+				Spec: migapi.DirectVolumeMigrationSpec{Itinerary: tt.itinerary, VerifyOnly: tt.verifyOnly, Preview: tt.preview},
+			}
+			err := ReconcileDirectVolumeMigration{}.validateItinerary(context.TODO(), direct)
+			if err != nil {
+				t.Fatalf("validateItinerary() unexpected error = %v", err)
+			}
+			if valid := !direct.Status.HasCondition(InvalidItinerary); valid != tt.wantValid {
+				t.Errorf("validateItinerary() valid = %v, want %v", valid, tt.wantValid)
+			}
+		})
+	}
+}
+
+func TestReconcileDirectVolumeMigration_validateDuplicatePVCs(t *testing.T) {
+	getPVC := func(ns string, name string, targetNamespace string) migapi.PVCToMigrate {
+		return migapi.PVCToMigrate{
+			ObjectReference: &corev1.ObjectReference{Namespace: ns, Name: name},
+			TargetNamespace: targetNamespace,
+		}
+	}
+	tests := []struct {
+		name        string
+		pvcs        []migapi.PVCToMigrate
+		wantItems   []string
+		wantMessage string
+	}{
+		{
+			name:      "when each PVC is listed once, should be valid",
+			pvcs:      []migapi.PVCToMigrate{getPVC("ns-1", "pvc-1", ""), getPVC("ns-2", "pvc-1", ""), getPVC("ns-1", "pvc-2", "")},
+			wantItems: nil,
+		},
+		{
+			name:        "when a PVC is listed twice with different destinations, should report it once",
+			pvcs:        []migapi.PVCToMigrate{getPVC("ns-1", "pvc-1", "dest-1"), getPVC("ns-1", "pvc-2", ""), getPVC("ns-1", "pvc-1", "dest-2")},
+			wantItems:   []string{"ns-1/pvc-1"},
+			wantMessage: "The persistent volume claims [ns-1/pvc-1] are listed more than once, a source PVC can only be migrated to a single destination.",
+		},
+		{
+			name: "when a PVC is listed three times along another duplicate, should report each once",
+			pvcs: []migapi.PVCToMigrate{
+				getPVC("ns-1", "pvc-1", ""), getPVC("ns-2", "pvc-2", ""), getPVC("ns-1", "pvc-1", ""),
+				getPVC("ns-2", "pvc-2", ""), getPVC("ns-1", "pvc-1", ""),
+			},
+			wantItems:   []string{"ns-1/pvc-1", "ns-2/pvc-2"},
+			wantMessage: "The persistent volume claims [ns-1/pvc-1,ns-2/pvc-2] are listed more than once, a source PVC can only be migrated to a single destination.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			direct := &migapi.DirectVolumeMigration{
+				Spec: migapi.DirectVolumeMigrationSpec{PersistentVolumeClaims: tt.pvcs},
+			}
+			err := ReconcileDirectVolumeMigration{}.validateDuplicatePVCs(context.TODO(), direct)
+			if err != nil {
+				t.Fatalf("validateDuplicatePVCs() unexpected error = %v", err)
+			}
+			condition := direct.Status.FindCondition(DuplicatePVCs)
+			if tt.wantItems == nil {
+				if condition != nil {
+					t.Errorf("validateDuplicatePVCs() condition = %v, want none", condition)
+				}
+				return
+			}
+			if condition == nil {
+				t.Fatalf("validateDuplicatePVCs() reported no %s condition", DuplicatePVCs)
+			}
+			if condition.Category != Critical || !reflect.DeepEqual(condition.Items, tt.wantItems) {
+				t.Errorf("validateDuplicatePVCs() condition = %v %v, want a critical condition with items %v",
+					condition.Category, condition.Items, tt.wantItems)
+			}
+			// the items are only persisted within the message
+			condition.ExpandItems()
+			if condition.Message != tt.wantMessage {
+				t.Errorf("validateDuplicatePVCs() message = %q, want %q", condition.Message, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestReconcileDirectVolumeMigration_validatePVCs_noPVCs(t *testing.T) {
+	getPlan := func(action string, copyMethod string) *migapi.MigPlan {
+		return &migapi.MigPlan{
+			ObjectMeta: metav1.ObjectMeta{Name: "plan", Namespace: migapi.OpenshiftMigrationNamespace},
+			Spec: migapi.MigPlanSpec{
+				PersistentVolumes: migapi.PersistentVolumes{
+					List: []migapi.PV{{Name: "pv-1", Selection: migapi.Selection{Action: action, CopyMethod: copyMethod}}},
+				},
+			},
+		}
+	}
+	migration := &migapi.MigMigration{
+		ObjectMeta: metav1.ObjectMeta{Name: "migration", Namespace: migapi.OpenshiftMigrationNamespace},
+		Spec: migapi.MigMigrationSpec{
+			MigPlanRef: &corev1.ObjectReference{Name: "plan", Namespace: migapi.OpenshiftMigrationNamespace},
+		},
+	}
+	tests := []struct {
+		name      string
+		owned     bool
+		objects   []runtime.Object
+		wantValid bool
+	}{
+		{name: "when not owned by a migration, should be valid", wantValid: true},
+		{
+			name:      "when the plan copies no PV with the filesystem copy, should be valid",
+			owned:     true,
+			objects:   []runtime.Object{migration, getPlan(migapi.PvCopyAction, migapi.PvSnapshotCopyMethod)},
+			wantValid: true,
+		},
+		{
+			name:      "when the plan copies a PV with the filesystem copy, should be invalid",
+			owned:     true,
+			objects:   []runtime.Object{migration, getPlan(migapi.PvCopyAction, migapi.PvFilesystemCopyMethod)},
+			wantValid: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			direct := &migapi.DirectVolumeMigration{}
+			if tt.owned {
+				direct.OwnerReferences = []metav1.OwnerReference{{Kind: "MigMigration", Name: migration.Name}}
+			}
+			r := ReconcileDirectVolumeMigration{Client: fake.NewFakeClient(tt.objects...)}
+			err := r.validatePVCs(context.TODO(), direct)
+			if err != nil {
+				t.Fatalf("validatePVCs() unexpected error = %v", err)
+			}
+			if valid := !direct.Status.HasCondition(InvalidPVCs); valid != tt.wantValid {
+				t.Errorf("validatePVCs() valid = %v, want %v", valid, tt.wantValid)
+			}
+		})
+	}
+}
+
+func TestReconcileDirectVolumeMigration_validateRsyncUser(t *testing.T) {
+	uid, gid := int64(-1), int64(1000)
+	direct := &migapi.DirectVolumeMigration{
+		Spec: migapi.DirectVolumeMigrationSpec{RsyncUID: &uid, RsyncGID: &gid},
+	}
+	err := ReconcileDirectVolumeMigration{}.validateRsyncUser(context.TODO(), direct)
+	if err != nil {
+		t.Fatalf("validateRsyncUser() unexpected error = %v", err)
+	}
+	// the items are only persisted within the message
+	direct.Status.EndStagingConditions()
+	direct.Status.BeginStagingConditions()
+	condition := direct.Status.FindCondition(InvalidRsyncUser)
+	if condition == nil || !reflect.DeepEqual(condition.Items, []string{"rsyncUID: -1"}) {
+		t.Errorf("validateRsyncUser() condition = %v, want the invalid rsyncUID as the only item", condition)
+	}
+}
+
+func TestReconcileDirectVolumeMigration_validateRsyncShards(t *testing.T) {
+	direct := &migapi.DirectVolumeMigration{
+		Spec: migapi.DirectVolumeMigrationSpec{
+			PersistentVolumeClaims: []migapi.PVCToMigrate{
+				{ObjectReference: &corev1.ObjectReference{Namespace: "ns", Name: "pvc-1"}, Shards: 4},
+				{ObjectReference: &corev1.ObjectReference{Namespace: "ns", Name: "pvc-2"}, Shards: MaxRsyncShards + 1},
+			},
+		},
+	}
+	err := ReconcileDirectVolumeMigration{}.validateRsyncShards(context.TODO(), direct)
+	if err != nil {
+		t.Fatalf("validateRsyncShards() unexpected error = %v", err)
+	}
+	// the items are only persisted within the message
+	direct.Status.EndStagingConditions()
+	direct.Status.BeginStagingConditions()
+	condition := direct.Status.FindCondition(InvalidRsyncShards)
+	want := []string{fmt.Sprintf("ns/pvc-2: shards %d", MaxRsyncShards+1)}
+	if condition == nil || !reflect.DeepEqual(condition.Items, want) {
+		t.Errorf("validateRsyncShards() condition = %v, want items %v", condition, want)
+	}
+}
@@ -0,0 +1,75 @@
+package directvolumemigration
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"path"
+	"strings"
+
+	liberr "github.com/konveyor/controller/pkg/error"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// RsyncExitCodeVanishedFiles exit code of rsync when source files vanished during the transfer
+	RsyncExitCodeVanishedFiles = 24
+	// RsyncExitCodeIgnore outcome of a failed Rsync attempt treated as succeeded without any warning
+	RsyncExitCodeIgnore = "Ignore"
+	// RsyncVanishedFilePattern logged by rsync for each source file which vanished
+	RsyncVanishedFilePattern = "file has vanished: "
+)
+
+// Get the outcome of an Rsync attempt which exited as source files vanished
+// during the transfer, the vanishedFiles spec overrides the exit code outcome.
+func (t *Task) getVanishedFilesOutcome(outcome string) string {
+	switch t.Owner.Spec.VanishedFiles {
+	case migapi.VanishedFilesIgnore:
+		return RsyncExitCodeIgnore
+	case migapi.VanishedFilesWarn:
+		return RsyncExitCodeWarn
+	case migapi.VanishedFilesFail:
+		return RsyncExitCodeFail
+	}
+	return outcome
+}
+
+// parseVanishedFiles returns the number of source files rsync logged as vanished.
+func parseVanishedFiles(logs io.Reader) (int, error) {
+	count := 0
+	scanner := bufio.NewScanner(logs)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	scanner.Split(scanLogLines)
+	for scanner.Scan() {
+		if strings.Contains(scanner.Text(), RsyncVanishedFilePattern) {
+			count++
+		}
+	}
+	return count, scanner.Err()
+}
+
+// countVanishedFiles returns the number of source files which vanished during
+// the Rsync attempt of the Rsync client Pod, read from its logs.
+func (t *Task) countVanishedFiles(pod *corev1.Pod) (int, error) {
+	clientset, err := t.getSourceClientset()
+	if err != nil {
+		return 0, liberr.Wrap(err)
+	}
+	req := clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+		Container: DirectVolumeMigrationRsyncClient,
+	})
+	readCloser, err := req.Stream(context.TODO())
+	if err != nil {
+		return 0, liberr.Wrap(err)
+	}
+	defer readCloser.Close()
+	count, err := parseVanishedFiles(readCloser)
+	if err != nil {
+		return 0, liberr.Wrap(err)
+	}
+	t.Log.Info("Counted the source files which vanished during the Rsync attempt.",
+		"pod", path.Join(pod.Namespace, pod.Name),
+		"vanishedFiles", count)
+	return count, nil
+}
@@ -0,0 +1,51 @@
+package directvolumemigration
+
+import (
+	"strings"
+	"testing"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+)
+
+func Test_parseVanishedFiles(t *testing.T) {
+	logs := strings.Join([]string{
+		"data/file-1",
+		"file has vanished: \"/mnt/ns/pvc/cache/tmp-1\"",
+		"        4.00K 100%    3.91MB/s    0:00:00 (xfr#1, to-chk=2/5)\rfile has vanished: \"/mnt/ns/pvc/cache/tmp-2\"",
+		"rsync warning: some files vanished before they could be transferred (code 24) at main.c(1207) [sender=3.1.3]",
+	}, "\n")
+	count, err := parseVanishedFiles(strings.NewReader(logs))
+	if err != nil {
+		t.Fatalf("parseVanishedFiles() error = %v", err)
+	}
+	if count != 2 {
+		t.Errorf("parseVanishedFiles() = %v, want 2", count)
+	}
+}
+
+func TestTask_getVanishedFilesOutcome(t *testing.T) {
+	tests := []struct {
+		name          string
+		vanishedFiles string
+		outcome       string
+		want          string
+	}{
+		{name: "when not set, should keep the exit code outcome", outcome: RsyncExitCodeWarn, want: RsyncExitCodeWarn},
+		{name: "when not set and retried by the setting, should retry", outcome: RsyncExitCodeRetry, want: RsyncExitCodeRetry},
+		{name: "when ignore, should ignore", vanishedFiles: migapi.VanishedFilesIgnore, outcome: RsyncExitCodeWarn, want: RsyncExitCodeIgnore},
+		{name: "when warn, should warn", vanishedFiles: migapi.VanishedFilesWarn, outcome: RsyncExitCodeRetry, want: RsyncExitCodeWarn},
+		{name: "when fail, should fail", vanishedFiles: migapi.VanishedFilesFail, outcome: RsyncExitCodeWarn, want: RsyncExitCodeFail},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &Task{
+				Owner: &migapi.DirectVolumeMigration{
+					Spec: migapi.DirectVolumeMigrationSpec{VanishedFiles: tt.vanishedFiles},
+				},
+			}
+			if got := task.getVanishedFilesOutcome(tt.outcome); got != tt.want {
+				t.Errorf("Task.getVanishedFilesOutcome() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,283 @@
+package directvolumemigration
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+
+	liberr "github.com/konveyor/controller/pkg/error"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// VerifyOutputPrefix prefix of the lines rsync prints for each item differing in a verify-only migration
+	VerifyOutputPrefix = "dvm-verify:"
+	// MaxVerificationDifferences maximum number of differing files reported per PVC
+	MaxVerificationDifferences = 100
+)
+
+// RsyncFailedVerificationPattern matches the files which checksum Rsync failed
+// to verify once transferred, on the last attempt of the file.
+var RsyncFailedVerificationPattern = regexp.MustCompile(`^(?:ERROR|WARNING): (.+) failed verification -- update (?:discarded|retained)\.$`)
+
+// getVerifyOnlyRsyncOptions returns the Rsync options comparing the source with the
+// destination by checksum without modifying the destination. Each differing item
+// is printed with its itemized changes.
+func getVerifyOnlyRsyncOptions(rsyncOptions []string) []string {
+	options := append([]string{}, rsyncOptions...)
+	if !hasRsyncOption(options, "--dry-run") && !hasRsyncOption(options, "-n") {
+		options = append(options, "--dry-run")
+	}
+	if !hasRsyncOption(options, "--checksum") && !hasRsyncOption(options, "-c") {
+		options = append(options, "--checksum")
+	}
+	return append(options, fmt.Sprintf("--out-format=%s%%i:%%n", VerifyOutputPrefix))
+}
+
+// parseVerificationDifferences returns the first files differing between the source
+// and destination printed in the Rsync logs and the total number of differing files.
+// Directories only differing by their attributes aren't reported.
+func parseVerificationDifferences(logs io.Reader, max int) ([]string, int, error) {
+	differences := []string{}
+	count := 0
+	scanner := bufio.NewScanner(logs)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	scanner.Split(scanLogLines)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, VerifyOutputPrefix) {
+			continue
+		}
+		item := strings.SplitN(strings.TrimPrefix(line, VerifyOutputPrefix), ":", 2)
+		if len(item) != 2 || item[1] == "" {
+			continue
+		}
+		changes, name := strings.TrimSpace(item[0]), item[1]
+		if len(changes) > 1 && changes[1] == 'd' && !strings.HasPrefix(changes, "*") {
+			continue
+		}
+		count++
+		if len(differences) < max {
+			differences = append(differences, name)
+		}
+	}
+	return differences, count, scanner.Err()
+}
+
+// scanLogLines splits the logs on both line feeds and the carriage returns rsync
+// separates its progress updates with.
+func scanLogLines(data []byte, atEOF bool) (int, []byte, error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// Get the clientset of the source cluster, reading the logs of the Rsync client Pods.
+func (t *Task) getSourceClientset() (kubernetes.Interface, error) {
+	cluster, err := t.Owner.GetSourceCluster(t.Client)
+	if err != nil {
+		return nil, liberr.Wrap(err)
+	}
+	if cluster == nil {
+		return nil, liberr.Wrap(fmt.Errorf("source cluster of DVM %s/%s not found", t.Owner.Namespace, t.Owner.Name))
+	}
+	config, err := cluster.BuildRestConfig(t.Client)
+	if err != nil {
+		return nil, liberr.Wrap(err)
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, liberr.Wrap(err)
+	}
+	return clientset, nil
+}
+
+// collectVerificationResults reads the differing files from the logs of the last
+// Rsync client Pod of each operation and reports them in the Rsync operations.
+func (t *Task) collectVerificationResults() error {
+	srcClient, err := t.getSourceClient()
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	clientset, err := t.getSourceClientset()
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	differing := []string{}
+	for _, operation := range t.Owner.Status.RsyncOperations {
+		pod, err := t.getLatestPodForOperation(srcClient, *operation)
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		if pod == nil {
+			t.Log.Info("Rsync client Pod of the verified PVC not found, differences are unknown.",
+				"persistentVolumeClaim", operation.String())
+			continue
+		}
+		differences, count, err := t.getPodVerificationDifferences(clientset, pod)
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		operation.Differences = differences
+		operation.DifferencesCount = count
+		if count > 0 {
+			differing = append(differing, operation.String())
+		}
+	}
+	if len(differing) > 0 {
+		t.Owner.Status.SetCondition(migapi.Condition{
+			Type:     VerificationDifferencesFound,
+			Status:   True,
+			Reason:   Warned,
+			Category: Warn,
+			Message:  fmt.Sprintf(VerificationDifferencesFoundMessage, len(differing)),
+			Items:    differing,
+			Durable:  true,
+		})
+	}
+	return nil
+}
+
+// getPodVerificationDifferences returns the differing files printed in the logs of the Rsync client Pod.
+func (t *Task) getPodVerificationDifferences(clientset kubernetes.Interface, pod *corev1.Pod) ([]string, int, error) {
+	req := clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+		Container: DirectVolumeMigrationRsyncClient,
+	})
+	readCloser, err := req.Stream(context.TODO())
+	if err != nil {
+		return nil, 0, liberr.Wrap(err)
+	}
+	defer readCloser.Close()
+	differences, count, err := parseVerificationDifferences(readCloser, MaxVerificationDifferences)
+	if err != nil {
+		return nil, 0, liberr.Wrap(err)
+	}
+	t.Log.Info("Collected the files differing between source and destination PVC.",
+		"pod", path.Join(pod.Namespace, pod.Name),
+		"differences", count)
+	return differences, count, nil
+}
+
+// parseFailedVerifications returns the first files Rsync failed to verify once
+// transferred printed in the Rsync logs and the total number of these files.
+// The files verified on a later attempt aren't reported.
+func parseFailedVerifications(logs io.Reader, max int) ([]string, int, error) {
+	failed := []string{}
+	count := 0
+	scanner := bufio.NewScanner(logs)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	scanner.Split(scanLogLines)
+	for scanner.Scan() {
+		match := RsyncFailedVerificationPattern.FindStringSubmatch(scanner.Text())
+		if match == nil {
+			continue
+		}
+		count++
+		if len(failed) < max {
+			failed = append(failed, match[1])
+		}
+	}
+	return failed, count, scanner.Err()
+}
+
+// Get the source PVCs verified by checksum, as "<namespace>/<name>".
+func (t *Task) getVerifiedPVCs() []string {
+	verified := []string{}
+	for _, pvc := range t.Owner.Spec.PersistentVolumeClaims {
+		if pvc.Verify {
+			verified = append(verified, path.Join(pvc.Namespace, pvc.Name))
+		}
+	}
+	return verified
+}
+
+// verifyTransferredData reads the files Rsync failed to verify by checksum from
+// the logs of the last Rsync client Pod of each verified PVC and reports them
+// as the differences of their Rsync operations.
+func (t *Task) verifyTransferredData() error {
+	verified := map[string]bool{}
+	for _, pvc := range t.getVerifiedPVCs() {
+		verified[pvc] = true
+	}
+	if len(verified) == 0 {
+		return nil
+	}
+	srcClient, err := t.getSourceClient()
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	clientset, err := t.getSourceClientset()
+	if err != nil {
+		return liberr.Wrap(err)
+	}
+	differing := []string{}
+	for _, operation := range t.Owner.Status.RsyncOperations {
+		if !verified[operation.String()] || operation.Skipped || operation.Unchanged {
+			continue
+		}
+		pod, err := t.getLatestPodForOperation(srcClient, *operation)
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		if pod == nil {
+			t.Log.Info("Rsync client Pod of the verified PVC not found, verification failures are unknown.",
+				"persistentVolumeClaim", operation.String())
+			continue
+		}
+		failed, count, err := t.getPodFailedVerifications(clientset, pod)
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		operation.Differences = failed
+		operation.DifferencesCount = count
+		if count > 0 {
+			differing = append(differing, operation.String())
+		}
+	}
+	if len(differing) > 0 {
+		t.Owner.Status.SetCondition(migapi.Condition{
+			Type:     VerificationDifferencesFound,
+			Status:   True,
+			Reason:   Warned,
+			Category: Warn,
+			Message:  fmt.Sprintf(VerificationDifferencesFoundMessage, len(differing)),
+			Items:    differing,
+			Durable:  true,
+		})
+	}
+	return nil
+}
+
+// getPodFailedVerifications returns the files which failed the verification printed in the logs of the Rsync client Pod.
+func (t *Task) getPodFailedVerifications(clientset kubernetes.Interface, pod *corev1.Pod) ([]string, int, error) {
+	req := clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+		Container: DirectVolumeMigrationRsyncClient,
+	})
+	readCloser, err := req.Stream(context.TODO())
+	if err != nil {
+		return nil, 0, liberr.Wrap(err)
+	}
+	defer readCloser.Close()
+	failed, count, err := parseFailedVerifications(readCloser, MaxVerificationDifferences)
+	if err != nil {
+		return nil, 0, liberr.Wrap(err)
+	}
+	t.Log.Info("Collected the files which failed the verification of the verified PVC.",
+		"pod", path.Join(pod.Namespace, pod.Name),
+		"failedVerifications", count)
+	return failed, count, nil
+}
@@ -0,0 +1,95 @@
+package directvolumemigration
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_getVerifyOnlyRsyncOptions(t *testing.T) {
+	tests := []struct {
+		name         string
+		rsyncOptions []string
+		want         []string
+	}{
+		{
+			name:         "when options don't compare checksums, should add dry run and checksum",
+			rsyncOptions: []string{"--archive", "--delete"},
+			want:         []string{"--archive", "--delete", "--dry-run", "--checksum", "--out-format=dvm-verify:%i:%n"},
+		},
+		{
+			name:         "when options already compare checksums, should not duplicate it",
+			rsyncOptions: []string{"--archive", "--checksum"},
+			want:         []string{"--archive", "--checksum", "--dry-run", "--out-format=dvm-verify:%i:%n"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := getVerifyOnlyRsyncOptions(tt.rsyncOptions)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getVerifyOnlyRsyncOptions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseVerificationDifferences(t *testing.T) {
+	logs := strings.Join([]string{
+		"2021/06/01 10:00:00 [12] building file list",
+		"dvm-verify:>f+++++++++:data/new.txt",
+		"dvm-verify:.d..t......:data/",
+		"      1.00M  50%  10.00MB/s    0:00:00\r      2.00M 100%  10.00MB/s    0:00:00 (xfr#1, to-chk=0/3)",
+		"dvm-verify:>fcs.......:data/changed file.txt",
+		"dvm-verify:*deleting  :data/extra.txt",
+		"2021/06/01 10:00:01 [12] sent 1.00K bytes  received 30 bytes",
+	}, "\n")
+	tests := []struct {
+		name      string
+		max       int
+		want      []string
+		wantCount int
+	}{
+		{
+			name:      "when differences are below the maximum, should report all of them",
+			max:       10,
+			want:      []string{"data/new.txt", "data/changed file.txt", "data/extra.txt"},
+			wantCount: 3,
+		},
+		{
+			name:      "when differences exceed the maximum, should report the first ones and count all of them",
+			max:       1,
+			want:      []string{"data/new.txt"},
+			wantCount: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotCount, err := parseVerificationDifferences(strings.NewReader(logs), tt.max)
+			if err != nil {
+				t.Fatalf("parseVerificationDifferences() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) || gotCount != tt.wantCount {
+				t.Errorf("parseVerificationDifferences() = %v, %v, want %v, %v", got, gotCount, tt.want, tt.wantCount)
+			}
+		})
+	}
+}
+
+func Test_parseFailedVerifications(t *testing.T) {
+	logs := strings.Join([]string{
+		"2021/06/01 10:00:00 [12] building file list",
+		"WARNING: data/db.ibd failed verification -- update discarded (will try again).",
+		"data/db.ibd",
+		"ERROR: data/log.bin failed verification -- update discarded.",
+		"WARNING: data/index failed verification -- update retained.",
+		"rsync error: some files/attrs were not transferred (see previous errors) (code 23) at main.c(1207) [sender=3.1.3]",
+	}, "\n")
+	got, count, err := parseFailedVerifications(strings.NewReader(logs), MaxVerificationDifferences)
+	if err != nil {
+		t.Fatalf("parseFailedVerifications() error = %v", err)
+	}
+	want := []string{"data/log.bin", "data/index"}
+	if !reflect.DeepEqual(got, want) || count != 2 {
+		t.Errorf("parseFailedVerifications() = %v, %v, want %v, 2", got, count, want)
+	}
+}
@@ -0,0 +1,43 @@
+package directvolumemigration
+
+import (
+	"fmt"
+	"path"
+)
+
+const (
+	// RsyncPartialTransferExitCode exit code of rsync when some files could not be transferred
+	RsyncPartialTransferExitCode = 23
+	// RsyncXattrsUnsupportedPattern pattern of the rsync errors reported when the
+	// destination filesystem does not support ACLs or extended attributes
+	RsyncXattrsUnsupportedPattern = "(acl|xattr|xal).*Operation not supported"
+	// RsyncXattrsFallbackMessage line printed when the transfer is run again without ACLs and extended attributes
+	RsyncXattrsFallbackMessage = "dvm-xattrs: destination filesystem does not support ACLs or extended attributes, transferring without them"
+)
+
+// Get the Rsync options without the ones preserving the ACLs and extended attributes.
+func getRsyncWithoutXattrsOptions(rsyncOptions []string) []string {
+	options := []string{}
+	for _, option := range rsyncOptions {
+		switch option {
+		case "--acls", "-A", "--xattrs", "-X", RsyncXattrsSELinuxFilter:
+			continue
+		}
+		options = append(options, option)
+	}
+	return options
+}
+
+// getXattrsFallbackCommand returns the bash commands running the transfer and,
+// when it fails with exit code 23 because the destination filesystem does not
+// support ACLs or extended attributes, running the fallback transfer without
+// them. The output of the transfer is kept in the shared directory of the Rsync
+// client Pod to classify the failure, and still printed for the progress.
+func getXattrsFallbackCommand(transfer string, fallback string, ipcDir string) string {
+	output := path.Join(ipcDir, "rsync-xattrs.log")
+	return fmt.Sprintf("{ %s; } 2>&1 | tee %s; rc=${PIPESTATUS[0]}; if [ $rc -eq %d ] && grep -qE '%s' %s; then echo '%s'; %s; rc=$?; fi; rm -f %s; (exit $rc)",
+		transfer, output,
+		RsyncPartialTransferExitCode, RsyncXattrsUnsupportedPattern, output,
+		RsyncXattrsFallbackMessage, fallback,
+		output)
+}
@@ -0,0 +1,164 @@
+package directvolumemigration
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"syscall"
+	"testing"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"github.com/konveyor/mig-controller/pkg/settings"
+)
+
+func Test_getRsyncWithoutXattrsOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []string
+		want    []string
+	}{
+		{
+			name:    "when acls and xattrs are preserved, should drop them and the selinux filter",
+			options: []string{"--archive", "--acls", "--xattrs", RsyncXattrsSELinuxFilter, "--partial"},
+			want:    []string{"--archive", "--partial"},
+		},
+		{
+			name:    "when short options are set, should drop them",
+			options: []string{"-A", "-X", "--delete"},
+			want:    []string{"--delete"},
+		},
+		{
+			name:    "when acls and xattrs are not preserved, should keep the options",
+			options: []string{"--archive", "--partial"},
+			want:    []string{"--archive", "--partial"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getRsyncWithoutXattrsOptions(tt.options); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getRsyncWithoutXattrsOptions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_getXattrsFallbackCommand(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dvm-xattrs-fallback")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	marker := filepath.Join(dir, "fallback")
+	tests := []struct {
+		name         string
+		transfer     string
+		wantFallback bool
+		wantExitCode int
+	}{
+		{
+			name:         "when the transfer succeeds, should not run the fallback",
+			transfer:     "true",
+			wantFallback: false,
+			wantExitCode: 0,
+		},
+		{
+			name:         "when extended attributes are not supported, should run the fallback",
+			transfer:     `echo 'rsync: [receiver] rsync_xal_set: lsetxattr("/mnt/data","user.dvm") failed: Operation not supported (95)' >&2; exit 23`,
+			wantFallback: true,
+			wantExitCode: 0,
+		},
+		{
+			name:         "when acls are not supported, should run the fallback",
+			transfer:     `echo 'rsync: [receiver] set_acl: sys_acl_set_file(data, ACL_TYPE_ACCESS): Operation not supported (95)'; exit 23`,
+			wantFallback: true,
+			wantExitCode: 0,
+		},
+		{
+			name:         "when the transfer partially fails for another reason, should not run the fallback",
+			transfer:     `echo 'rsync: [receiver] mkstemp "/mnt/data" failed: Permission denied (13)'; exit 23`,
+			wantFallback: false,
+			wantExitCode: 23,
+		},
+		{
+			name:         "when the transfer fails with another exit code, should not run the fallback",
+			transfer:     `echo 'rsync_xal_set: Operation not supported'; exit 12`,
+			wantFallback: false,
+			wantExitCode: 12,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Remove(marker)
+			command := getXattrsFallbackCommand(tt.transfer, "touch "+marker, dir)
+			out, err := exec.Command("bash", "-c", command).CombinedOutput()
+			exitCode := 0
+			if exitErr, ok := err.(*exec.ExitError); ok {
+				exitCode = exitErr.ExitCode()
+			} else if err != nil {
+				t.Fatalf("fallback command failed: %v: %s", err, out)
+			}
+			if exitCode != tt.wantExitCode {
+				t.Errorf("fallback command exit code = %d, want %d: %s", exitCode, tt.wantExitCode, out)
+			}
+			_, err = os.Stat(marker)
+			if ranFallback := err == nil; ranFallback != tt.wantFallback {
+				t.Errorf("fallback command ran the fallback = %v, want %v: %s", ranFallback, tt.wantFallback, out)
+			}
+			if tt.wantFallback != strings.Contains(string(out), RsyncXattrsFallbackMessage) {
+				t.Errorf("fallback command output = %s, want the fallback message reported when the fallback runs", out)
+			}
+			if _, err := os.Stat(filepath.Join(dir, "rsync-xattrs.log")); !os.IsNotExist(err) {
+				t.Errorf("fallback command must remove the transfer output once classified")
+			}
+		})
+	}
+}
+
+func Test_getRsyncOptionsXattrsPreserved(t *testing.T) {
+	if _, err := exec.LookPath("rsync"); err != nil {
+		t.Skip("rsync not found")
+	}
+	dir, err := ioutil.TempDir("", "dvm-xattrs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	source, destination := filepath.Join(dir, "src")+"/", filepath.Join(dir, "dest")
+	if err := os.MkdirAll(source, 0755); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(source, "data")
+	if err := ioutil.WriteFile(file, []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := syscall.Setxattr(file, "user.dvm", []byte("labeled"), 0); err != nil {
+		t.Skipf("extended attributes not supported: %v", err)
+	}
+	defer func() {
+		settings.Settings.DvmOpts.RsyncOpts = settings.RsyncOpts{}
+	}()
+	settings.Settings.DvmOpts.RsyncOpts = settings.RsyncOpts{BwLimit: -1, Archive: true, Xattrs: true}
+	task := &Task{
+		Log:   log.WithName("test-logger"),
+		Owner: &migapi.DirectVolumeMigration{},
+	}
+	args := append(task.getRsyncOptions(), source, destination)
+	out, err := exec.Command("rsync", args...).CombinedOutput()
+	if err != nil && strings.Contains(string(out), "not supported") {
+		t.Skipf("rsync does not support extended attributes: %s", out)
+	}
+	if err != nil {
+		t.Fatalf("rsync failed: %v: %s", err, out)
+	}
+	value := make([]byte, 64)
+	n, err := syscall.Getxattr(filepath.Join(destination, "data"), "user.dvm", value)
+	if err != nil {
+		t.Fatalf("rsync with the xattrs options did not preserve the extended attribute: %v", err)
+	}
+	if got := string(value[:n]); got != "labeled" {
+		t.Errorf("rsync with the xattrs options preserved the extended attribute as %q, want %q", got, "labeled")
+	}
+}
@@ -120,10 +120,10 @@ func (r *ReconcileDirectVolumeMigrationProgress) Reconcile(ctx context.Context,
 		return reconcile.Result{Requeue: true}, err
 	}
 
-	// Set MigMigration name key on logger
+	// Set MigMigration name and UID keys on logger
 	migration, err := pvProgress.GetMigrationforDVMP(r)
 	if migration != nil {
-		log.Real = log.WithValues("migMigration", migration.Name)
+		log.Real = log.WithValues("migMigration", migration.Name, "migrationUID", string(migration.UID))
 	}
 
 	// Set up jaeger tracing
@@ -203,6 +203,9 @@ func (r *RsyncPodProgressTask) Run() error {
 
 		if rsyncPodStatus != nil {
 			pvProgress.Status.RsyncPodStatus = *rsyncPodStatus
+			if rsyncPodStatus.PodPhase == kapi.PodSucceeded && pvProgress.Status.RsyncStats == nil {
+				pvProgress.Status.RsyncStats = r.getRsyncStats(pod)
+			}
 		}
 	} else if podSelector != nil && podNamespace != "" {
 		podList, err := r.getAllMatchingRsyncPods()
@@ -228,6 +231,9 @@ func (r *RsyncPodProgressTask) Run() error {
 					// merge progress stats from previous run
 					MergeProgressStats(rsyncPodStatus, &pvProgress.Status.RsyncPodStatus)
 					pvProgress.Status.RsyncPodStatuses = append(pvProgress.Status.RsyncPodStatuses, *rsyncPodStatus)
+					if rsyncPodStatus.PodPhase == kapi.PodSucceeded {
+						pvProgress.Status.RsyncStats = r.getRsyncStats(pod)
+					}
 				}
 				// update mostRecentPodStatus if current pod is more recent
 				if mostRecentPodStatus == nil ||
@@ -297,6 +303,11 @@ func MergeProgressStats(p1, p2 *migapi.RsyncPodStatus) {
 	p1.ExitCode = getNonNil(p1.ExitCode, p2.ExitCode)
 	p1.LastObservedProgressPercent = MaxProgressString(p1.LastObservedProgressPercent, p2.LastObservedProgressPercent)
 	p1.LastObservedTransferRate = getNonEmpty(p1.LastObservedTransferRate, p2.LastObservedTransferRate)
+	if p2.LastObservedTotalFiles > 0 {
+		p1.LastObservedTransferredFiles = p2.LastObservedTransferredFiles
+		p1.LastObservedRemainingFiles = p2.LastObservedRemainingFiles
+		p1.LastObservedTotalFiles = p2.LastObservedTotalFiles
+	}
 }
 
 func IsPodTerminal(phase kapi.PodPhase) bool {
@@ -376,6 +387,7 @@ func (r *RsyncPodProgressTask) getRsyncClientContainerStatus(podRef *kapi.Pod, p
 		if transferRate != "" {
 			rsyncPodStatus.LastObservedTransferRate = transferRate
 		}
+		setFileProgress(&rsyncPodStatus, logMessage)
 		rsyncPodStatus.ContainerElapsedTime = nil
 	case !containerStatus.Ready && containerStatus.LastTerminationState.Terminated != nil && containerStatus.LastTerminationState.Terminated.ExitCode != 0:
 		// pod has a failure, report last failure reason
@@ -389,6 +401,7 @@ func (r *RsyncPodProgressTask) getRsyncClientContainerStatus(podRef *kapi.Pod, p
 		if transferRate != "" {
 			rsyncPodStatus.LastObservedTransferRate = transferRate
 		}
+		setFileProgress(&rsyncPodStatus, containerStatus.LastTerminationState.Terminated.Message)
 		exitCode := containerStatus.LastTerminationState.Terminated.ExitCode
 		rsyncPodStatus.ExitCode = &exitCode
 		rsyncPodStatus.ContainerElapsedTime = &metav1.Duration{Duration: containerStatus.LastTerminationState.Terminated.FinishedAt.Sub(containerStatus.LastTerminationState.Terminated.StartedAt.Time).Round(time.Second)}
@@ -407,6 +420,7 @@ func (r *RsyncPodProgressTask) getRsyncClientContainerStatus(podRef *kapi.Pod, p
 		if transferRate != "" {
 			rsyncPodStatus.LastObservedTransferRate = transferRate
 		}
+		setFileProgress(&rsyncPodStatus, containerStatus.State.Terminated.Message)
 		exitCode := containerStatus.State.Terminated.ExitCode
 		rsyncPodStatus.ExitCode = &exitCode
 		rsyncPodStatus.ContainerElapsedTime = &metav1.Duration{Duration: containerStatus.State.Terminated.FinishedAt.Sub(containerStatus.State.Terminated.StartedAt.Time).Round(time.Second)}
@@ -454,6 +468,15 @@ func (r *RsyncPodProgressTask) getAllMatchingRsyncPods() (*kapi.PodList, error)
 }
 
 func (r *RsyncPodProgressTask) getPodLogs(pod *kapi.Pod, containerName string, tailLines *int64, previous bool) (string, error) {
+	logs, err := r.getRawPodLogs(pod, containerName, tailLines, previous)
+	if err != nil {
+		return "", err
+	}
+	return parseLogs(strings.NewReader(logs))
+}
+
+// getRawPodLogs returns logs of the given container without trimming them
+func (r *RsyncPodProgressTask) getRawPodLogs(pod *kapi.Pod, containerName string, tailLines *int64, previous bool) (string, error) {
 	config, err := r.Cluster.BuildRestConfig(r.Client)
 	if err != nil {
 		return "", err
@@ -476,7 +499,66 @@ func (r *RsyncPodProgressTask) getPodLogs(pod *kapi.Pod, containerName string, t
 
 	defer readCloser.Close()
 
-	return parseLogs(readCloser)
+	buf := new(strings.Builder)
+	_, err = io.Copy(buf, readCloser)
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// getRsyncStats returns transfer summary logged by Rsync in the given pod
+func (r *RsyncPodProgressTask) getRsyncStats(pod *kapi.Pod) *migapi.RsyncStats {
+	numberOfLogLines := int64(30)
+	logs, err := r.getRawPodLogs(pod, RsyncContainerName, &numberOfLogLines, false)
+	if err != nil {
+		log.Info("Failed to get Rsync stats from Rsync Pod on source cluster",
+			"pod", path.Join(pod.Namespace, pod.Name))
+		return nil
+	}
+	return ParseRsyncStats(logs)
+}
+
+// ParseRsyncStats given logs from Rsync Pod, returns the summary printed by rsync --stats
+func ParseRsyncStats(logs string) *migapi.RsyncStats {
+	stats := migapi.RsyncStats{}
+	found := false
+	if match := getLastSubmatch(`Number of files: ([\d,]+)`, logs); match != "" {
+		if v, err := strconv.ParseInt(strings.ReplaceAll(match, ",", ""), 10, 64); err == nil {
+			stats.NumberOfFiles = v
+			found = true
+		}
+	}
+	if match := getLastSubmatch(`Number of (?:regular )?files transferred: ([\d,]+)`, logs); match != "" {
+		if v, err := strconv.ParseInt(strings.ReplaceAll(match, ",", ""), 10, 64); err == nil {
+			stats.NumberOfFilesTransferred = v
+			found = true
+		}
+	}
+	if match := getLastSubmatch(`Total file size: ([\d,\.]+\w?) bytes`, logs); match != "" {
+		stats.TotalFileSize = match
+		found = true
+	}
+	if match := getLastSubmatch(`Total transferred file size: ([\d,\.]+\w?) bytes`, logs); match != "" {
+		stats.TotalTransferredFileSize = match
+		found = true
+	}
+	if match := getLastSubmatch(`Literal data: ([\d,\.]+\w?) bytes`, logs); match != "" {
+		stats.LiteralData = match
+		found = true
+	}
+	if match := getLastSubmatch(`Matched data: ([\d,\.]+\w?) bytes`, logs); match != "" {
+		stats.MatchedData = match
+		found = true
+	}
+	if match := getLastSubmatch(`speedup is ([\d,\.]+)`, logs); match != "" {
+		stats.Speedup = match
+		found = true
+	}
+	if !found {
+		return nil
+	}
+	return &stats
 }
 
 // GetProgressPercent given logs from Rsync Pod, returns logged progress percentage
@@ -489,6 +571,40 @@ func GetTransferRate(message string) string {
 	return getLastMatch(`\d+\.\w*\/s`, message)
 }
 
+// rsyncFileProgressRegex matches the file counts logged at the end of the progress by Rsync,
+// to-chk once the file list is complete and ir-chk while it is being built incrementally
+const rsyncFileProgressRegex = `xfr#(\d+), (?:to|ir)-chk=(\d+)/(\d+)\)`
+
+// GetFileProgress given logs from Rsync Pod, returns logged number of files transferred,
+// remaining to check and found so far, found is false when no file counts are logged
+func GetFileProgress(message string) (transferred, remaining, total int64, found bool) {
+	r := regexp.MustCompile(rsyncFileProgressRegex)
+	matches := r.FindAllStringSubmatch(message, -1)
+	if len(matches) == 0 {
+		return 0, 0, 0, false
+	}
+	counts := [3]int64{}
+	for i, match := range matches[len(matches)-1][1:] {
+		v, err := strconv.ParseInt(match, 10, 64)
+		if err != nil {
+			return 0, 0, 0, false
+		}
+		counts[i] = v
+	}
+	return counts[0], counts[1], counts[2], true
+}
+
+// setFileProgress sets the last observed file counts of the status from the logs of Rsync Pod
+func setFileProgress(rsyncPodStatus *migapi.RsyncPodStatus, message string) {
+	transferred, remaining, total, found := GetFileProgress(message)
+	if !found {
+		return
+	}
+	rsyncPodStatus.LastObservedTransferredFiles = transferred
+	rsyncPodStatus.LastObservedRemainingFiles = remaining
+	rsyncPodStatus.LastObservedTotalFiles = total
+}
+
 // ProgressStringToValue parses string and returns percentage as a value
 func ProgressStringToValue(progressPercentage string) int64 {
 	value := int64(0)
@@ -523,6 +639,15 @@ func getLastMatch(regex string, message string) string {
 	return ""
 }
 
+func getLastSubmatch(regex string, message string) string {
+	r := regexp.MustCompile(regex)
+	matches := r.FindAllStringSubmatch(message, -1)
+	if len(matches) > 0 {
+		return matches[len(matches)-1][1]
+	}
+	return ""
+}
+
 func parseLogs(reader io.Reader) (string, error) {
 	buf := new(strings.Builder)
 	_, err := io.Copy(buf, reader)
@@ -530,6 +655,7 @@ func parseLogs(reader io.Reader) (string, error) {
 		return "", err
 	}
 	carriageReturnMatcher := regexp.MustCompile(`\\r.*$`)
+	fileProgressMatcher := regexp.MustCompile(rsyncFileProgressRegex)
 	data := strings.Split(buf.String(), "\n")
 	logLines := []string{}
 	for _, line := range data {
@@ -544,7 +670,12 @@ func parseLogs(reader io.Reader) (string, error) {
 			}
 		}
 		if len(l) > 60 {
-			l = l[:60]
+			// the file counts end the progress, they are never cut
+			limit := 60
+			if loc := fileProgressMatcher.FindStringIndex(l); loc != nil && loc[1] > limit {
+				limit = loc[1]
+			}
+			l = l[:limit]
 		}
 		logLines = append(logLines, l)
 	}
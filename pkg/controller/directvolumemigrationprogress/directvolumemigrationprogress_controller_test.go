@@ -152,6 +152,13 @@ func Test_parseLogs(t *testing.T) {
 			}, "\n"),
 			wantErr: false,
 		},
+		{
+			name: "when the file counts of the progress are long, should not cut them",
+			args: args{reader: bytes.NewBufferString(`
+          512.30G  47%  120.41MB/s    1:12:38 (xfr#1204567, ir-chk=1000/2450981)2020/11/04 01:49:42 [1] <f+++++++++ file66`)},
+			want:    "512.30G  47%  120.41MB/s    1:12:38 (xfr#1204567, ir-chk=1000/2450981)",
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -356,6 +363,120 @@ func Test_getLastMatch(t *testing.T) {
 	}
 }
 
+func Test_GetFileProgress(t *testing.T) {
+	tests := []struct {
+		name            string
+		message         string
+		wantTransferred int64
+		wantRemaining   int64
+		wantTotal       int64
+		wantFound       bool
+	}{
+		{
+			name:    "when no file counts are logged, should not be found",
+			message: "1.84G  83%   80.21MB/s    0:00:04",
+		},
+		{
+			name:            "when the file list is complete, should return the last counts",
+			message:         "1.60G  91%   40.91MB/s    0:00:37 (xfr#127, to-chk=35/163)20\n1.61G  92%   40.91MB/s    0:00:37 (xfr#129, to-chk=33/163)20",
+			wantTransferred: 129,
+			wantRemaining:   33,
+			wantTotal:       163,
+			wantFound:       true,
+		},
+		{
+			name:            "when the file list is incremental, should return the counts found so far",
+			message:         "512.30G  47%  120.41MB/s    1:12:38 (xfr#1204567, ir-chk=1000/2450981)",
+			wantTransferred: 1204567,
+			wantRemaining:   1000,
+			wantTotal:       2450981,
+			wantFound:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transferred, remaining, total, found := GetFileProgress(tt.message)
+			if found != tt.wantFound || transferred != tt.wantTransferred || remaining != tt.wantRemaining || total != tt.wantTotal {
+				t.Errorf("GetFileProgress() = %v, %v, %v, %v, want %v, %v, %v, %v",
+					transferred, remaining, total, found, tt.wantTransferred, tt.wantRemaining, tt.wantTotal, tt.wantFound)
+			}
+		})
+	}
+}
+
+func Test_ParseRsyncStats(t *testing.T) {
+	tests := []struct {
+		name string
+		logs string
+		want *migapi.RsyncStats
+	}{
+		{
+			name: "when logs don't contain stats, should return nil",
+			logs: "sending incremental file list\n1.23M 100%",
+			want: nil,
+		},
+		{
+			name: "when logs contain stats from rsync 3.1, should parse all fields",
+			logs: `2021/03/01 10:00:00 [12] Number of files: 1,234 (reg: 1,200, dir: 34)
+2021/03/01 10:00:00 [12] Number of created files: 1,234 (reg: 1,200, dir: 34)
+2021/03/01 10:00:00 [12] Number of deleted files: 0
+2021/03/01 10:00:00 [12] Number of regular files transferred: 1,200
+2021/03/01 10:00:00 [12] Total file size: 2.15G bytes
+2021/03/01 10:00:00 [12] Total transferred file size: 2.15G bytes
+2021/03/01 10:00:00 [12] sent 2.15G bytes  received 23.31K bytes  40.31M bytes/sec
+2021/03/01 10:00:00 [12] total size is 2.15G  speedup is 1.00`,
+			want: &migapi.RsyncStats{
+				NumberOfFiles:            1234,
+				NumberOfFilesTransferred: 1200,
+				TotalFileSize:            "2.15G",
+				TotalTransferredFileSize: "2.15G",
+				Speedup:                  "1.00",
+			},
+		},
+		{
+			name: "when logs contain the stats of a delta transfer, should parse the literal and matched data",
+			logs: `Number of files: 1 (reg: 1)
+Number of regular files transferred: 1
+Total file size: 10,737,418,240 bytes
+Total transferred file size: 10,737,418,240 bytes
+Literal data: 134,217,728 bytes
+Matched data: 10,603,200,512 bytes
+total size is 10,737,418,240  speedup is 78.13`,
+			want: &migapi.RsyncStats{
+				NumberOfFiles:            1,
+				NumberOfFilesTransferred: 1,
+				TotalFileSize:            "10,737,418,240",
+				TotalTransferredFileSize: "10,737,418,240",
+				LiteralData:              "134,217,728",
+				MatchedData:              "10,603,200,512",
+				Speedup:                  "78.13",
+			},
+		},
+		{
+			name: "when logs contain stats from rsync 3.0, should parse all fields",
+			logs: `Number of files: 52
+Number of files transferred: 12
+Total file size: 104857600 bytes
+Total transferred file size: 1048576 bytes
+total size is 104857600  speedup is 99.87`,
+			want: &migapi.RsyncStats{
+				NumberOfFiles:            52,
+				NumberOfFilesTransferred: 12,
+				TotalFileSize:            "104857600",
+				TotalTransferredFileSize: "1048576",
+				Speedup:                  "99.87",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseRsyncStats(tt.logs); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseRsyncStats() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 type fakeGetPodLogs struct {
 	podLogMessage string
 	err           error
@@ -36,7 +36,7 @@ func (r *ReconcileDirectVolumeMigrationProgress) initTracer(dvmp migapi.DirectVo
 	// Set tracer on reconciler if it's not already present.
 	// We will never close this, so the 'closer' is discarded.
 	if r.tracer == nil {
-		r.tracer, _ = migtrace.InitJaeger("DirectVolumeMigrationProgress")
+		r.tracer, _ = migtrace.InitTracer("DirectVolumeMigrationProgress")
 	}
 
 	// Go from dvmp -> dvm -> migration, use migration UID to get span
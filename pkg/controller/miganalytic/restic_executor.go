@@ -74,6 +74,22 @@ func (r *ResticDFCommandExecutor) DF(podRef *corev1.Pod, persistentVolumes []Mig
 	}
 	dfCmd.StdErr = podCommand.Err.String()
 	dfCmd.StdOut = podCommand.Out.String()
+
+	// the inodes are only informational, the usage is reported without them
+	inodesCmdString := dfCmd.PrepareDFInodesCommand(persistentVolumes)
+	inodesCommand := pods.PodCommand{
+		Pod:     podRef,
+		RestCfg: restCfg,
+		Args:    inodesCmdString,
+	}
+	err = inodesCommand.Run()
+	if err != nil {
+		log.Info("Failed running df -i command inside Restic Pod, used inodes unknown",
+			"pod", path.Join(podRef.Namespace, podRef.Name),
+			"command", inodesCmdString,
+			"error", err.Error())
+	}
+	dfCmd.InodesStdOut = inodesCommand.Out.String()
 	return dfCmd
 }
 
@@ -165,6 +181,7 @@ func (r *ResticDFCommandExecutor) Execute(pvcNodeMap map[string][]MigAnalyticPer
 				"pvcRequestedCapacity", pvc.RequestedCapacity,
 				"pvcProvisionedCapacity", pvc.ProvisionedCapacity,
 				"usagePercentage", pvcDFInfo.UsagePercentage,
+				"usedInodes", pvcDFInfo.UsedInodes,
 				"totalSize", pvcDFInfo.TotalSize)
 		}
 	}
@@ -33,7 +33,7 @@ func (r *ReconcileMigAnalytic) initTracer(miganalytic *migapi.MigAnalytic) opent
 	// Set tracer on reconciler if it's not already present.
 	// We will never close this, so the 'closer' is discarded.
 	if r.tracer == nil {
-		r.tracer, _ = migtrace.InitJaeger("MigAnalytic")
+		r.tracer, _ = migtrace.InitTracer("MigAnalytic")
 	}
 	// Begin reconcile span
 	reconcileSpan := r.tracer.StartSpan("miganalytic-reconcile-" + miganalytic.Name)
@@ -62,6 +62,8 @@ type DFCommand struct {
 	StdOut string
 	// stderr from df
 	StdErr string
+	// stdout from df -i
+	InodesStdOut string
 	// Base unit used for df
 	BlockSize DFBaseUnit
 	// BaseLocation defines path where volumes can be found
@@ -77,6 +79,7 @@ type DFOutput struct {
 	Name            string
 	Namespace       string
 	UsagePercentage int64
+	UsedInodes      int64
 	TotalSize       resource.Quantity
 	IsError         bool
 }
@@ -128,6 +131,7 @@ func (cmd *DFCommand) GetDFOutputForPV(volName string, podUID types.UID) (pv DFO
 				pv.UsagePercentage, err = strconv.ParseInt(matched[1], 10, 64)
 				pv.IsError = (err != nil)
 			}
+			pv.UsedInodes = cmd.getUsedInodes(lineMatcher)
 			return
 		}
 	}
@@ -142,12 +146,28 @@ func (cmd *DFCommand) GetDFOutputForPV(volName string, podUID types.UID) (pv DFO
 	return
 }
 
-// PrepareDFCommand given a list of volumes, creates a bulk df command for all volumes
-func (cmd *DFCommand) PrepareDFCommand(pvcs []MigAnalyticPersistentVolumeDetails) []string {
-	command := []string{
-		"/bin/bash",
-		"-c",
+// getUsedInodes returns the number of inodes used on the volume matched by the
+// line matcher in the output of df -i, 0 when unknown
+func (cmd *DFCommand) getUsedInodes(lineMatcher *regexp.Regexp) int64 {
+	for _, line := range strings.Split(cmd.InodesStdOut, "\n") {
+		if !lineMatcher.MatchString(line) {
+			continue
+		}
+		cols := strings.Fields(line)
+		if len(cols) != 6 {
+			return 0
+		}
+		used, err := strconv.ParseInt(cols[2], 10, 64)
+		if err != nil {
+			return 0
+		}
+		return used
 	}
+	return 0
+}
+
+// getVolumePaths returns the paths of the volumes on the Restic Pod
+func (cmd *DFCommand) getVolumePaths(pvcs []MigAnalyticPersistentVolumeDetails) string {
 	volPaths := []string{}
 	for _, pvc := range pvcs {
 		volPaths = append(volPaths,
@@ -157,7 +177,25 @@ func (cmd *DFCommand) PrepareDFCommand(pvcs []MigAnalyticPersistentVolumeDetails
 				pvc.PodUID,
 				pvc.VolumeName))
 	}
-	return append(command, fmt.Sprintf("df -B%s %s", cmd.BlockSize, strings.Join(volPaths, " ")))
+	return strings.Join(volPaths, " ")
+}
+
+// PrepareDFCommand given a list of volumes, creates a bulk df command for all volumes
+func (cmd *DFCommand) PrepareDFCommand(pvcs []MigAnalyticPersistentVolumeDetails) []string {
+	command := []string{
+		"/bin/bash",
+		"-c",
+	}
+	return append(command, fmt.Sprintf("df -B%s %s", cmd.BlockSize, cmd.getVolumePaths(pvcs)))
+}
+
+// PrepareDFInodesCommand given a list of volumes, creates a bulk df command reporting the inodes used on all volumes
+func (cmd *DFCommand) PrepareDFInodesCommand(pvcs []MigAnalyticPersistentVolumeDetails) []string {
+	command := []string{
+		"/bin/bash",
+		"-c",
+	}
+	return append(command, fmt.Sprintf("df -i %s", cmd.getVolumePaths(pvcs)))
 }
 
 // findOriginalPVDataMatchingDFOutput given a df output for a pv and nested map of nodeName->[]pvc, finds ref to matching object in the map
@@ -236,6 +274,7 @@ func (pva *PersistentVolumeAdjuster) Run(pvNodeMap map[string][]MigAnalyticPersi
 			erroredPVs = append(erroredPVs, &pvDfOutputs[i])
 		} else {
 			statusFieldUpdate.ActualCapacity = pvDfOutput.TotalSize
+			statusFieldUpdate.UsedInodes = pvDfOutput.UsedInodes
 			proposedCapacity, reason := pva.calculateProposedVolumeSize(pvDfOutput.UsagePercentage, pvDfOutput.TotalSize, originalData.RequestedCapacity)
 			// make sure we never set a value smaller than original provisioned capacity
 			if originalData.ProvisionedCapacity.Cmp(proposedCapacity) >= 1 {
@@ -56,10 +56,17 @@ tmpfs              7942M    1M     7942M   1% /credentials
 /dev/xvda2        51188M 7613M    43576M  15% /host_pods
 `
 
+var testDFInodesStdout = `
+Filesystem      Inodes   IUsed   IFree IUse% Mounted on
+tmpfs          2033152       9 2033143    1% /host_pods/280f7572-6590-11eb-b436-0a916cc7c396/volumes/kubernetes.io~secret/sock-shop-token-pn8n9
+/dev/xvda2    26213824 1480144 24733680    6% /host_pods
+`
+
 func TestDFCommand_GetPVUsage(t *testing.T) {
 	type fields struct {
 		StdOut       string
 		StdErr       string
+		InodesStdOut string
 		BlockSize    DFBaseUnit
 		BaseLocation string
 	}
@@ -91,6 +98,26 @@ func TestDFCommand_GetPVUsage(t *testing.T) {
 				UsagePercentage: 1,
 			},
 		},
+		{
+			name: "given a volume that we know exists in Stdout and in the inodes Stdout, the used inodes should be returned",
+			fields: fields{
+				BlockSize:    BinarySIMega,
+				BaseLocation: "/host_pods",
+				StdOut:       testDFStdout,
+				StdErr:       testDFStderr,
+				InodesStdOut: testDFInodesStdout,
+			},
+			args: args{
+				volName: "sock-shop-token-pn8n9",
+				podUID:  types.UID("280f7572-6590-11eb-b436-0a916cc7c396"),
+			},
+			wantPv: DFOutput{
+				IsError:         false,
+				TotalSize:       resource.MustParse("7942Mi"),
+				UsagePercentage: 1,
+				UsedInodes:      9,
+			},
+		},
 		{
 			name: "given a volume that we know exists in Stderr, correct pv usage info should be returned",
 			fields: fields{
@@ -129,6 +156,7 @@ func TestDFCommand_GetPVUsage(t *testing.T) {
 			cmd := &DFCommand{
 				StdOut:       tt.fields.StdOut,
 				StdErr:       tt.fields.StdErr,
+				InodesStdOut: tt.fields.InodesStdOut,
 				BlockSize:    tt.fields.BlockSize,
 				BaseLocation: tt.fields.BaseLocation,
 			}
@@ -33,7 +33,7 @@ func (r *ReconcileMigCluster) initTracer(migcluster *migapi.MigCluster) opentrac
 	// Set tracer on reconciler if it's not already present.
 	// We will never close this, so the 'closer' is discarded.
 	if r.tracer == nil {
-		r.tracer, _ = migtrace.InitJaeger("MigCluster")
+		r.tracer, _ = migtrace.InitTracer("MigCluster")
 	}
 	// Begin reconcile span
 	reconcileSpan := r.tracer.StartSpan("migcluster-reconcile-" + migcluster.Name)
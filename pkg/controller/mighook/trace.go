@@ -33,7 +33,7 @@ func (r *ReconcileMigHook) initTracer(mighook *migapi.MigHook) opentracing.Span
 	// Set tracer on reconciler if it's not already present.
 	// We will never close this, so the 'closer' is discarded.
 	if r.tracer == nil {
-		r.tracer, _ = migtrace.InitJaeger("MigHook")
+		r.tracer, _ = migtrace.InitTracer("MigHook")
 	}
 	// Begin reconcile span
 	reconcileSpan := r.tracer.StartSpan("mighook-reconcile-" + mighook.Name)
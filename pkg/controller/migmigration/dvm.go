@@ -104,12 +104,15 @@ func (t *Task) hasDirectVolumeMigrationCompleted(dvm *migapi.DirectVolumeMigrati
 	switch {
 	//case dvm.Status.Phase != "" && dvm.Status.Phase != dvmc.Completed:
 	//	// TODO: Update this to check on the associated dvmp resources and build up a progress indicator back to
-	case dvm.Status.Phase == dvmc.Completed && dvm.Status.Itinerary == "VolumeMigration" && dvm.Status.HasCondition(dvmc.Succeeded):
-		// completed successfully
+	case dvm.Status.Phase == dvmc.Completed && dvm.Status.HasCondition(dvmc.Succeeded):
+		// completed successfully, whatever the itinerary
 		completed = true
 	case (dvm.Status.Phase == dvmc.MigrationFailed || dvm.Status.Phase == dvmc.Completed) && dvm.Status.HasCondition(dvmc.Failed):
 		failureReasons = append(failureReasons, fmt.Sprintf("direct volume migration failed. %s", volumeProgress))
 		completed = true
+	case dvm.Status.Phase == dvmc.Canceled:
+		failureReasons = append(failureReasons, fmt.Sprintf("direct volume migration canceled. %s", volumeProgress))
+		completed = true
 	default:
 		progress = append(progress, volumeProgress)
 	}
@@ -178,7 +181,7 @@ func (t *Task) getDVMPodProgress(dvm migapi.DirectVolumeMigration) []string {
 					p += fmt.Sprintf(
 						" - Attempt %d of %d",
 						operation.CurrentAttempt,
-						dvmc.GetRsyncPodBackOffLimit(dvm))
+						dvmc.GetRsyncPodBackOffLimit(dvm)+operation.ToleratedAttempts)
 				}
 			} else {
 				p = fmt.Sprintf("Rsync Pod %s: %s", path.Join(pod.Namespace, pod.Name), state)
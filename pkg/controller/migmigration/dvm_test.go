@@ -1,6 +1,7 @@
 package migmigration
 
 import (
+	"fmt"
 	"reflect"
 	"testing"
 
@@ -143,6 +144,32 @@ func TestTask_hasDirectVolumeMigrationCompleted(t1 *testing.T) {
 			wantFailureReasons: nil,
 			wantCompleted:      true,
 		},
+		{
+			name:          "when the engine migration succeeded, should be completed",
+			args:          args{dvm: getCompletedDVM(dvmc.EngineMigration.Name, dvmc.Completed, dvmc.Succeeded, 1)},
+			wantCompleted: true,
+		},
+		{
+			name:          "when the verify only migration succeeded, should be completed",
+			args:          args{dvm: getCompletedDVM(dvmc.VerifyOnlyMigration.Name, dvmc.Completed, dvmc.Succeeded, 1)},
+			wantCompleted: true,
+		},
+		{
+			name:          "when the migration without volumes succeeded, should be completed",
+			args:          args{dvm: getCompletedDVM(dvmc.NoVolumesMigration.Name, dvmc.Completed, dvmc.Succeeded, 0)},
+			wantCompleted: true,
+		},
+		{
+			name:               "when the migration was canceled, should be completed with a failure",
+			args:               args{dvm: getCompletedDVM(dvmc.CanceledItinerary.Name, dvmc.Canceled, dvmc.CancelRequested, 1)},
+			wantFailureReasons: []string{"direct volume migration canceled. 1 total volumes; 0 successful; 0 running; 0 failed"},
+			wantCompleted:      true,
+		},
+		{
+			name:         "when the migration is running, should not be completed",
+			args:         args{dvm: getCompletedDVM(dvmc.VolumeMigration.Name, dvmc.WaitForRsyncClientPodsCompleted, dvmc.Running, 1)},
+			wantProgress: []string{"1 total volumes; 0 successful; 0 running; 0 failed"},
+		},
 	}
 	for _, tt := range tests {
 		t1.Run(tt.name, func(t1 *testing.T) {
@@ -160,3 +187,22 @@ func TestTask_hasDirectVolumeMigrationCompleted(t1 *testing.T) {
 		})
 	}
 }
+
+// Get a DVM of the itinerary in the phase with the condition.
+func getCompletedDVM(itinerary string, phase string, condition string, volumes int) *migapi.DirectVolumeMigration {
+	dvm := &migapi.DirectVolumeMigration{
+		Status: migapi.DirectVolumeMigrationStatus{
+			Conditions: migapi.Conditions{
+				List: []migapi.Condition{{Type: condition, Status: True}},
+			},
+			Itinerary: itinerary,
+			Phase:     phase,
+		},
+	}
+	for i := 0; i < volumes; i++ {
+		dvm.Spec.PersistentVolumeClaims = append(dvm.Spec.PersistentVolumeClaims, migapi.PVCToMigrate{
+			ObjectReference: &v1.ObjectReference{Namespace: "ns", Name: fmt.Sprintf("pvc-%d", i)},
+		})
+	}
+	return dvm
+}
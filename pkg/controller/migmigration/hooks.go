@@ -2,7 +2,6 @@ package migmigration
 
 import (
 	"context"
-	"encoding/base64"
 	"fmt"
 	"path"
 	"strings"
@@ -14,13 +13,9 @@ import (
 	migevent "github.com/konveyor/mig-controller/pkg/event"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-const HookJobFailedLimit = 6
-const BackoffLimitExceededError = "BackoffLimitExceeded"
-
 func (t *Task) runHooks(hookPhase string) (bool, error) {
 	hook := migapi.MigPlanHook{}
 	var client k8sclient.Client
@@ -92,20 +87,17 @@ func (t *Task) runHooks(hookPhase string) (bool, error) {
 }
 
 func (t *Task) stopHookJobs() (bool, error) {
-	var client k8sclient.Client
-	var err error
-
-	migHook := migapi.MigHook{}
 	for _, hook := range t.PlanResources.MigPlan.Spec.Hooks {
-		t.Log.Info("Found MigHook ref, stopping hook job(s).",
-			"migHook", path.Join(hook.Reference.Namespace, hook.Reference.Name))
 		if hook.Reference == nil {
 			continue
 		}
+		t.Log.Info("Found MigHook ref, stopping hook job(s).",
+			"migHook", path.Join(hook.Reference.Namespace, hook.Reference.Name))
 
 		t.Log.Info("Getting MigHook",
 			"migHook", path.Join(hook.Reference.Namespace, hook.Reference.Name))
-		err = t.Client.Get(
+		migHook := migapi.MigHook{}
+		err := t.Client.Get(
 			context.TODO(),
 			types.NamespacedName{
 				Name:      hook.Reference.Name,
@@ -118,7 +110,7 @@ func (t *Task) stopHookJobs() (bool, error) {
 
 		t.Log.Info("Getting k8s client for MigHook",
 			"migHook", path.Join(migHook.Namespace, migHook.Name))
-		client, err = t.getHookClient(migHook)
+		client, err := t.getHookClient(migHook)
 		if err != nil {
 			return false, liberr.Wrap(err)
 		}
@@ -127,27 +119,24 @@ func (t *Task) stopHookJobs() (bool, error) {
 		t.Log.Info("Attempting to kill job for MigHook",
 			"migHook", path.Join(hook.Reference.Namespace, hook.Reference.Name),
 			"migHookPhase", hook.Phase)
-		runningJob, err := migHook.GetPhaseJob(client, hook.Phase, string(t.Owner.UID))
-		if runningJob == nil && err == nil {
-			// No active Job for hook
-			t.Log.Info("No active job found for MigHook. Continuing.",
+		job, err := migHook.DeletePhaseJob(client, hook.Phase, string(t.Owner.UID))
+		if err != nil {
+			t.Log.Error(err, "Job could not be deleted",
 				"migHook", path.Join(hook.Reference.Namespace, hook.Reference.Name),
 				"migHookPhase", hook.Phase)
 			continue
-		} else {
-			// Job found
-			t.Log.Info("Deleting hook job found for MigHook. Continuing.",
-				"job", path.Join(runningJob.Namespace, runningJob.Name),
+		}
+		if job == nil {
+			// No active Job for hook
+			t.Log.Info("No active job found for MigHook. Continuing.",
 				"migHook", path.Join(hook.Reference.Namespace, hook.Reference.Name),
 				"migHookPhase", hook.Phase)
-			err = client.Delete(context.TODO(), runningJob,
-				k8sclient.PropagationPolicy(metav1.DeletePropagationForeground))
-			if err != nil {
-				t.Log.Error(err, "Job could not be deleted",
-					"job", path.Join(runningJob.Namespace, runningJob.Name))
-			}
+			continue
 		}
-
+		t.Log.Info("Deleted hook job found for MigHook. Continuing.",
+			"job", path.Join(job.Namespace, job.Name),
+			"migHook", path.Join(hook.Reference.Namespace, hook.Reference.Name),
+			"migHookPhase", hook.Phase)
 	}
 	return true, nil
 }
@@ -171,12 +160,12 @@ func (t *Task) ensureJob(job *batchv1.Job, hook migapi.MigPlanHook, migHook miga
 		return false, nil
 	} else if err != nil {
 		return false, err
-	} else if runningJob.Status.Failed >= HookJobFailedLimit {
+	} else if runningJob.Status.Failed >= migapi.HookJobFailedLimit {
 		err := fmt.Errorf("Hook job %s failed.", runningJob.Name)
 		t.setProgress([]string{
 			fmt.Sprintf("Job %s/%s: Failed", runningJob.Namespace, runningJob.Name)})
 		return false, err
-	} else if len(runningJob.Status.Conditions) > 0 && runningJob.Status.Conditions[0].Reason == BackoffLimitExceededError {
+	} else if len(runningJob.Status.Conditions) > 0 && runningJob.Status.Conditions[0].Reason == migapi.BackoffLimitExceededError {
 		err := fmt.Errorf("Hook job %s failed.", runningJob.Name)
 		t.setProgress([]string{
 			fmt.Sprintf("Job %s/%s: Failed", runningJob.Namespace, runningJob.Name)})
@@ -193,31 +182,20 @@ func (t *Task) ensureJob(job *batchv1.Job, hook migapi.MigPlanHook, migHook miga
 }
 
 func (t *Task) prepareJob(hook migapi.MigPlanHook, migHook migapi.MigHook, client k8sclient.Client) (*batchv1.Job, error) {
-	job := &batchv1.Job{}
-
-	if migHook.Spec.Custom {
-		job = t.baseJobTemplate(hook, migHook)
-	} else {
-
-		configMap, err := t.configMapTemplate(hook, migHook)
-		if err != nil {
-			return nil, err
-		}
-
-		phaseConfigMap, err := migHook.GetPhaseConfigMap(client, hook.Phase, string(t.Owner.UID))
-		if phaseConfigMap == nil && err == nil {
-
-			err = client.Create(context.TODO(), configMap)
-			if err != nil {
-				return nil, err
-			}
-		} else if err != nil {
-			return nil, err
-		}
-		job = t.playbookJobTemplate(hook, migHook, configMap.Name)
+	container := corev1.Container{
+		Name: strings.ToLower(t.PlanResources.MigPlan.Name + "-" + hook.Phase),
+		Env: []corev1.EnvVar{
+			{
+				Name:  "MIGRATION_NAMESPACES",
+				Value: strings.Join(t.PlanResources.MigPlan.Spec.Namespaces, ","),
+			},
+			{
+				Name:  "MIGRATION_PLAN_NAME",
+				Value: t.PlanResources.MigPlan.Name,
+			},
+		},
 	}
-
-	return job, nil
+	return migHook.PreparePhaseJob(client, hook, string(t.Owner.UID), t.PlanResources.MigPlan.Name, container)
 }
 
 func (t *Task) getHookClient(migHook migapi.MigHook) (k8sclient.Client, error) {
@@ -241,106 +219,3 @@ func (t *Task) getHookClient(migHook migapi.MigHook) (k8sclient.Client, error) {
 	}
 	return client, nil
 }
-
-func (t *Task) configMapTemplate(hook migapi.MigPlanHook, migHook migapi.MigHook) (*corev1.ConfigMap, error) {
-
-	labels := migHook.GetCorrelationLabels()
-	labels[migapi.HookPhaseLabel] = hook.Phase
-	labels[migapi.HookOwnerLabel] = string(t.Owner.UID)
-
-	playbookData, err := base64.StdEncoding.DecodeString(migHook.Spec.Playbook)
-	if err != nil {
-		return nil, err
-	}
-
-	return &corev1.ConfigMap{
-		ObjectMeta: metav1.ObjectMeta{
-			Namespace:    hook.ExecutionNamespace,
-			GenerateName: strings.ToLower(t.PlanResources.MigPlan.Name + "-" + hook.Phase + "-"),
-			Labels:       labels,
-		},
-		Data: map[string]string{
-			"playbook.yml": string(playbookData),
-		},
-	}, nil
-}
-
-func (t *Task) playbookJobTemplate(hook migapi.MigPlanHook, migHook migapi.MigHook, configMap string) *batchv1.Job {
-	jobTemplate := t.baseJobTemplate(hook, migHook)
-
-	jobTemplate.Spec.Template.Spec.Containers[0].Command = []string{
-		"/bin/entrypoint",
-		"ansible-runner",
-		"-p",
-		"/tmp/playbook/playbook.yml",
-		"run",
-		"/tmp/runner",
-	}
-
-	jobTemplate.Spec.Template.Spec.Containers[0].VolumeMounts = []corev1.VolumeMount{
-		{
-			Name:      "playbook",
-			MountPath: "/tmp/playbook",
-		},
-	}
-
-	jobTemplate.Spec.Template.Spec.Volumes = []corev1.Volume{
-		{
-			Name: "playbook",
-			VolumeSource: corev1.VolumeSource{
-				ConfigMap: &corev1.ConfigMapVolumeSource{
-					LocalObjectReference: corev1.LocalObjectReference{
-						Name: configMap,
-					},
-				},
-			},
-		},
-	}
-
-	return jobTemplate
-}
-
-func (t *Task) baseJobTemplate(hook migapi.MigPlanHook, migHook migapi.MigHook) *batchv1.Job {
-	deadlineSeconds := int64(1800)
-
-	if migHook.Spec.ActiveDeadlineSeconds != 0 {
-		deadlineSeconds = migHook.Spec.ActiveDeadlineSeconds
-	}
-
-	labels := migHook.GetCorrelationLabels()
-	labels[migapi.HookPhaseLabel] = hook.Phase
-	labels[migapi.HookOwnerLabel] = string(t.Owner.UID)
-
-	return &batchv1.Job{
-		ObjectMeta: metav1.ObjectMeta{
-			Namespace:    hook.ExecutionNamespace,
-			GenerateName: strings.ToLower(t.PlanResources.MigPlan.Name + "-" + hook.Phase + "-"),
-			Labels:       labels,
-		},
-		Spec: batchv1.JobSpec{
-			Template: corev1.PodTemplateSpec{
-				Spec: corev1.PodSpec{
-					Containers: []corev1.Container{
-						{
-							Name:  strings.ToLower(t.PlanResources.MigPlan.Name + "-" + hook.Phase),
-							Image: migHook.Spec.Image,
-							Env: []corev1.EnvVar{
-								{
-									Name:  "MIGRATION_NAMESPACES",
-									Value: strings.Join(t.PlanResources.MigPlan.Spec.Namespaces, ","),
-								},
-								{
-									Name:  "MIGRATION_PLAN_NAME",
-									Value: t.PlanResources.MigPlan.Name,
-								},
-							},
-						},
-					},
-					RestartPolicy:         "OnFailure",
-					ServiceAccountName:    hook.ServiceAccount,
-					ActiveDeadlineSeconds: &deadlineSeconds,
-				},
-			},
-		},
-	}
-}
@@ -179,6 +179,9 @@ func (r *ReconcileMigMigration) Reconcile(ctx context.Context, request reconcile
 		log.Trace(err)
 		return reconcile.Result{Requeue: true}, nil
 	}
+
+	// Set MigMigration UID key on logger to correlate logs across controllers
+	log.Real = log.WithValues("migrationUID", string(migration.UID))
 	// Get jaeger spans for migration and reconcile, add to ctx
 	_, reconcileSpan := r.initTracer(migration)
 	if reconcileSpan != nil {
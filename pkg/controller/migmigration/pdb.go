@@ -0,0 +1,263 @@
+package migmigration
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"time"
+
+	liberr "github.com/konveyor/controller/pkg/error"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	ocappsv1 "github.com/openshift/api/apps/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Messages of the QuiesceBlockedByPDB and QuiesceDisruptsPDB conditions
+const (
+	QuiesceBlockedByPDBMessage        = "The PodDisruptionBudgets of the workloads never allow their Pods to be disrupted, waiting for the budgets to be relaxed. Annotate the migration with `migration.openshift.io/quiesce-live-source` to migrate them running: []."
+	QuiesceBlockedByPDBTimeoutMessage = "The PodDisruptionBudgets of the workloads were not relaxed before the quiesce timed out, nothing was quiesced: []."
+	QuiesceLiveSourceMessage          = "The workloads protected by PodDisruptionBudgets were left running, their PVs are migrated from a live source: []."
+	QuiesceDisruptsPDBMessage         = "The workloads were quiesced below their PodDisruptionBudgets, which a scale down doesn't enforce: []."
+)
+
+// Time the quiesce waits for the PodDisruptionBudgets never allowing their Pods
+// to be disrupted to be relaxed, the migration fails once elapsed.
+const QuiesceBlockedByPDBTimeout = 10 * time.Minute
+
+// List the PodDisruptionBudgets of the namespaces selecting healthy Pods.
+func listQuiescePDBs(client k8sclient.Client, namespaces []string) ([]policyv1beta1.PodDisruptionBudget, error) {
+	pdbs := []policyv1beta1.PodDisruptionBudget{}
+	for _, ns := range namespaces {
+		list := policyv1beta1.PodDisruptionBudgetList{}
+		err := client.List(context.TODO(), &list, k8sclient.InNamespace(ns))
+		if err != nil && !meta.IsNoMatchError(err) && !runtime.IsNotRegisteredError(err) {
+			return nil, liberr.Wrap(err)
+		}
+		for _, pdb := range list.Items {
+			if pdb.Status.CurrentHealthy > 0 {
+				pdbs = append(pdbs, pdb)
+			}
+		}
+	}
+	return pdbs, nil
+}
+
+// Get whether the PodDisruptionBudget never allows its Pods to be disrupted:
+// no disruption is allowed while all the Pods required by the budget are
+// healthy, e.g. minAvailable set to 100%, or the budget requires more healthy
+// Pods than its workloads run. Waiting doesn't allow the quiesce, the budget
+// must be relaxed.
+func isQuiesceBlockingPDB(pdb *policyv1beta1.PodDisruptionBudget) bool {
+	status := pdb.Status
+	return status.DisruptionsAllowed == 0 &&
+		(status.CurrentHealthy >= status.DesiredHealthy || status.DesiredHealthy > status.ExpectedPods)
+}
+
+// Get the PodDisruptionBudgets of the namespaces which never allow their Pods
+// to be disrupted. The quiesce of the workloads of the Pods they select waits
+// for the budgets to be relaxed.
+func getQuiesceBlockingPDBs(client k8sclient.Client, namespaces []string) ([]policyv1beta1.PodDisruptionBudget, error) {
+	listed, err := listQuiescePDBs(client, namespaces)
+	if err != nil {
+		return nil, liberr.Wrap(err)
+	}
+	pdbs := []policyv1beta1.PodDisruptionBudget{}
+	for i := range listed {
+		if isQuiesceBlockingPDB(&listed[i]) {
+			pdbs = append(pdbs, listed[i])
+		}
+	}
+	return pdbs, nil
+}
+
+// Get the PodDisruptionBudgets of the namespaces which allow some of their
+// healthy Pods to be disrupted, not all of them. This is the steady state of
+// most budgets, the workloads of the Pods they select are quiesced below the
+// budget, which a scale down doesn't enforce.
+func getQuiesceDisruptedPDBs(client k8sclient.Client, namespaces []string) ([]policyv1beta1.PodDisruptionBudget, error) {
+	listed, err := listQuiescePDBs(client, namespaces)
+	if err != nil {
+		return nil, liberr.Wrap(err)
+	}
+	pdbs := []policyv1beta1.PodDisruptionBudget{}
+	for i := range listed {
+		pdb := &listed[i]
+		if pdb.Status.DisruptionsAllowed < pdb.Status.CurrentHealthy && !isQuiesceBlockingPDB(pdb) {
+			pdbs = append(pdbs, *pdb)
+		}
+	}
+	return pdbs, nil
+}
+
+// Get the PodDisruptionBudget selecting the Pods with the labels, nil when none.
+// An empty selector selects no Pod.
+func getSelectingPDB(namespace string, podLabels map[string]string, pdbs []policyv1beta1.PodDisruptionBudget) *policyv1beta1.PodDisruptionBudget {
+	for i := range pdbs {
+		pdb := &pdbs[i]
+		if pdb.Namespace != namespace || pdb.Spec.Selector == nil {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil || selector.Empty() {
+			continue
+		}
+		if selector.Matches(labels.Set(podLabels)) {
+			return pdb
+		}
+	}
+	return nil
+}
+
+// Get the workloads of the namespaces which quiesce the PodDisruptionBudgets
+// prevent, as "<namespace>/<kind>/<name>: PodDisruptionBudget <name>". Only the
+// workloads scaled down by their replicas are checked.
+func getQuiesceBlockedWorkloads(client k8sclient.Client, namespaces []string, pdbs []policyv1beta1.PodDisruptionBudget) ([]string, error) {
+	blocked := []string{}
+	if len(pdbs) == 0 {
+		return blocked, nil
+	}
+	add := func(ns string, kind string, name string, podLabels map[string]string) {
+		if pdb := getSelectingPDB(ns, podLabels, pdbs); pdb != nil {
+			blocked = append(blocked, fmt.Sprintf("%s: PodDisruptionBudget %s", path.Join(ns, kind, name), pdb.Name))
+		}
+	}
+	for _, ns := range namespaces {
+		dcList := ocappsv1.DeploymentConfigList{}
+		err := client.List(context.TODO(), &dcList, k8sclient.InNamespace(ns))
+		if err != nil && !meta.IsNoMatchError(err) && !runtime.IsNotRegisteredError(err) {
+			return nil, liberr.Wrap(err)
+		}
+		for _, dc := range dcList.Items {
+			if dc.Spec.Replicas > 0 && dc.Spec.Template != nil {
+				add(ns, "DeploymentConfig", dc.Name, dc.Spec.Template.Labels)
+			}
+		}
+		deploymentList := appsv1.DeploymentList{}
+		err = client.List(context.TODO(), &deploymentList, k8sclient.InNamespace(ns))
+		if err != nil {
+			return nil, liberr.Wrap(err)
+		}
+		for _, deployment := range deploymentList.Items {
+			if deployment.Spec.Replicas != nil && *deployment.Spec.Replicas > 0 {
+				add(ns, "Deployment", deployment.Name, deployment.Spec.Template.Labels)
+			}
+		}
+		setList := appsv1.StatefulSetList{}
+		err = client.List(context.TODO(), &setList, k8sclient.InNamespace(ns))
+		if err != nil {
+			return nil, liberr.Wrap(err)
+		}
+		for _, set := range setList.Items {
+			if set.Spec.Replicas != nil && *set.Spec.Replicas > 0 {
+				add(ns, "StatefulSet", set.Name, set.Spec.Template.Labels)
+			}
+		}
+		replicaSetList := appsv1.ReplicaSetList{}
+		err = client.List(context.TODO(), &replicaSetList, k8sclient.InNamespace(ns))
+		if err != nil {
+			return nil, liberr.Wrap(err)
+		}
+		for _, set := range replicaSetList.Items {
+			// the ReplicaSets of a Deployment are scaled by the Deployment
+			if len(set.OwnerReferences) == 0 && set.Spec.Replicas != nil && *set.Spec.Replicas > 0 {
+				add(ns, "ReplicaSet", set.Name, set.Spec.Template.Labels)
+			}
+		}
+	}
+	sort.Strings(blocked)
+	return blocked, nil
+}
+
+// Get whether the migration requests the workloads which quiesce the
+// PodDisruptionBudgets prevent to be left running.
+func (t *Task) quiesceLiveSource() bool {
+	_, found := t.Owner.Annotations[migapi.QuiesceLiveSourceAnnotation]
+	return found
+}
+
+// Check the PodDisruptionBudgets of the source namespaces before quiescing the
+// applications. Returns the blocking PodDisruptionBudgets, the workloads they
+// select are left running, the blocked workloads and whether the quiesce can
+// proceed. Without the live source annotation, the quiesce waits for the
+// blocking budgets to be relaxed. The workloads of the other budgets are
+// quiesced below their budget, which is reported.
+func (t *Task) checkQuiescePDBs() ([]policyv1beta1.PodDisruptionBudget, []string, bool, error) {
+	client, err := t.getSourceClient()
+	if err != nil {
+		return nil, nil, false, liberr.Wrap(err)
+	}
+	pdbs, err := getQuiesceBlockingPDBs(client, t.sourceNamespaces())
+	if err != nil {
+		return nil, nil, false, liberr.Wrap(err)
+	}
+	blocked, err := getQuiesceBlockedWorkloads(client, t.sourceNamespaces(), pdbs)
+	if err != nil {
+		return nil, nil, false, liberr.Wrap(err)
+	}
+	if len(blocked) > 0 && !t.quiesceLiveSource() {
+		t.Log.Info("The PodDisruptionBudgets of the workloads never allow their Pods to be disrupted. Waiting.",
+			"workloads", blocked)
+		t.Owner.Status.SetCondition(migapi.Condition{
+			Type:     QuiesceBlockedByPDB,
+			Status:   True,
+			Reason:   migapi.NotReady,
+			Category: migapi.Warn,
+			Message:  QuiesceBlockedByPDBMessage,
+			Items:    blocked,
+		})
+		return pdbs, blocked, false, nil
+	}
+	disruptedPDBs, err := getQuiesceDisruptedPDBs(client, t.sourceNamespaces())
+	if err != nil {
+		return nil, nil, false, liberr.Wrap(err)
+	}
+	disrupted, err := getQuiesceBlockedWorkloads(client, t.sourceNamespaces(), disruptedPDBs)
+	if err != nil {
+		return nil, nil, false, liberr.Wrap(err)
+	}
+	if len(disrupted) > 0 {
+		t.Log.Info("Quiescing the workloads below their PodDisruptionBudgets.",
+			"workloads", disrupted)
+		t.Owner.Status.SetCondition(migapi.Condition{
+			Type:     QuiesceDisruptsPDB,
+			Status:   True,
+			Reason:   Disrupted,
+			Category: migapi.Warn,
+			Message:  QuiesceDisruptsPDBMessage,
+			Items:    disrupted,
+			Durable:  true,
+		})
+	}
+	if len(blocked) == 0 {
+		return pdbs, blocked, true, nil
+	}
+	t.Log.Info("Leaving the workloads protected by PodDisruptionBudgets running, their PVs are migrated live.",
+		"workloads", blocked)
+	t.Owner.Status.SetCondition(migapi.Condition{
+		Type:     QuiesceBlockedByPDB,
+		Status:   True,
+		Reason:   LiveSource,
+		Category: migapi.Warn,
+		Message:  QuiesceLiveSourceMessage,
+		Items:    blocked,
+		Durable:  true,
+	})
+	return pdbs, blocked, true, nil
+}
+
+// Get whether the quiesce waited for the blocking PodDisruptionBudgets longer
+// than the timeout, since the QuiesceApplications phase started.
+func (t *Task) quiesceBlockedByPDBTimedOut() bool {
+	running := t.Owner.Status.FindCondition(migapi.Running)
+	if running == nil || running.Reason != QuiesceApplications {
+		return false
+	}
+	return time.Since(running.LastTransitionTime.Time) > QuiesceBlockedByPDBTimeout
+}
@@ -0,0 +1,125 @@
+package migmigration
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	fakecompat "github.com/konveyor/mig-controller/pkg/compat/fake"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_getQuiesceBlockedWorkloads(t *testing.T) {
+	replicas := int32(2)
+	deployment := func(name string, app string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns"},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &replicas,
+				Template: v1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": app}}},
+			},
+		}
+	}
+	pdb := func(name string, app string, healthy int32, desired int32, allowed int32) *policyv1beta1.PodDisruptionBudget {
+		return &policyv1beta1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns"},
+			Spec: policyv1beta1.PodDisruptionBudgetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": app}},
+			},
+			Status: policyv1beta1.PodDisruptionBudgetStatus{
+				CurrentHealthy:     healthy,
+				DesiredHealthy:     desired,
+				ExpectedPods:       healthy,
+				DisruptionsAllowed: allowed,
+			},
+		}
+	}
+	client := fakecompat.NewFakeClient(
+		deployment("db", "db"),
+		deployment("web", "web"),
+		deployment("cache", "cache"),
+		deployment("api", "api"),
+		pdb("db-pdb", "db", 2, 2, 0),
+		pdb("web-pdb", "web", 2, 1, 1),
+		pdb("api-pdb", "api", 2, 0, 2),
+	)
+	pdbs, err := getQuiesceBlockingPDBs(client, []string{"ns"})
+	if err != nil {
+		t.Fatalf("getQuiesceBlockingPDBs() error = %v", err)
+	}
+	if len(pdbs) != 1 || pdbs[0].Name != "db-pdb" {
+		t.Fatalf("getQuiesceBlockingPDBs() = %v, want db-pdb", pdbs)
+	}
+	got, err := getQuiesceBlockedWorkloads(client, []string{"ns"}, pdbs)
+	if err != nil {
+		t.Fatalf("getQuiesceBlockedWorkloads() error = %v", err)
+	}
+	want := []string{"ns/Deployment/db: PodDisruptionBudget db-pdb"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("getQuiesceBlockedWorkloads() = %v, want %v", got, want)
+	}
+	// the budgets allowing some disruptions don't block the quiesce
+	pdbs, err = getQuiesceDisruptedPDBs(client, []string{"ns"})
+	if err != nil {
+		t.Fatalf("getQuiesceDisruptedPDBs() error = %v", err)
+	}
+	if len(pdbs) != 1 || pdbs[0].Name != "web-pdb" {
+		t.Fatalf("getQuiesceDisruptedPDBs() = %v, want web-pdb", pdbs)
+	}
+}
+
+func Test_isQuiesceBlockingPDB(t *testing.T) {
+	tests := []struct {
+		name   string
+		status policyv1beta1.PodDisruptionBudgetStatus
+		want   bool
+	}{
+		{name: "when some disruptions are allowed, should not block", status: policyv1beta1.PodDisruptionBudgetStatus{CurrentHealthy: 3, DesiredHealthy: 2, ExpectedPods: 3, DisruptionsAllowed: 1}, want: false},
+		{name: "when the budget is degraded, should not block", status: policyv1beta1.PodDisruptionBudgetStatus{CurrentHealthy: 1, DesiredHealthy: 2, ExpectedPods: 3}, want: false},
+		{name: "when no disruption is allowed at full health, should block", status: policyv1beta1.PodDisruptionBudgetStatus{CurrentHealthy: 3, DesiredHealthy: 3, ExpectedPods: 3}, want: true},
+		{name: "when the budget requires more Pods than expected, should block", status: policyv1beta1.PodDisruptionBudgetStatus{CurrentHealthy: 1, DesiredHealthy: 3, ExpectedPods: 2}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pdb := &policyv1beta1.PodDisruptionBudget{Status: tt.status}
+			if got := isQuiesceBlockingPDB(pdb); got != tt.want {
+				t.Errorf("isQuiesceBlockingPDB() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTask_quiesceBlockedByPDBTimedOut(t *testing.T) {
+	running := func(phase string, since time.Duration) *migapi.MigMigration {
+		migration := &migapi.MigMigration{}
+		migration.Status.Conditions.List = []migapi.Condition{{
+			Type:               migapi.Running,
+			Status:             True,
+			Reason:             phase,
+			LastTransitionTime: metav1.NewTime(time.Now().Add(-since)),
+		}}
+		return migration
+	}
+	tests := []struct {
+		name      string
+		migration *migapi.MigMigration
+		want      bool
+	}{
+		{name: "when the phase just started, should wait", migration: running(QuiesceApplications, time.Minute), want: false},
+		{name: "when the phase ran past the timeout, should time out", migration: running(QuiesceApplications, QuiesceBlockedByPDBTimeout+time.Minute), want: true},
+		{name: "when another phase runs, should not time out", migration: running(EnsureQuiesced, QuiesceBlockedByPDBTimeout+time.Minute), want: false},
+		{name: "when not running, should not time out", migration: &migapi.MigMigration{}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &Task{Owner: tt.migration}
+			if got := task.quiesceBlockedByPDBTimedOut(); got != tt.want {
+				t.Errorf("Task.quiesceBlockedByPDBTimedOut() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
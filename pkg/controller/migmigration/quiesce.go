@@ -14,12 +14,14 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	batchv1beta "k8s.io/api/batch/v1beta1"
 	v1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	"k8s.io/utils/pointer"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-// Quiesce applications on source cluster
-func (t *Task) quiesceApplications() error {
+// Quiesce applications on source cluster, the workloads selected by the
+// PodDisruptionBudgets are left running.
+func (t *Task) quiesceApplications(pdbs []policyv1beta1.PodDisruptionBudget) error {
 	client, err := t.getSourceClient()
 	if err != nil {
 		return liberr.Wrap(err)
@@ -28,19 +30,19 @@ func (t *Task) quiesceApplications() error {
 	if err != nil {
 		return liberr.Wrap(err)
 	}
-	err = t.quiesceDeploymentConfigs(client)
+	err = t.quiesceDeploymentConfigs(client, pdbs)
 	if err != nil {
 		return liberr.Wrap(err)
 	}
-	err = t.quiesceDeployments(client)
+	err = t.quiesceDeployments(client, pdbs)
 	if err != nil {
 		return liberr.Wrap(err)
 	}
-	err = t.quiesceStatefulSets(client)
+	err = t.quiesceStatefulSets(client, pdbs)
 	if err != nil {
 		return liberr.Wrap(err)
 	}
-	err = t.quiesceReplicaSets(client)
+	err = t.quiesceReplicaSets(client, pdbs)
 	if err != nil {
 		return liberr.Wrap(err)
 	}
@@ -117,7 +119,7 @@ func (t *Task) unQuiesceApplications(client k8sclient.Client, namespaces []strin
 }
 
 // Scales down DeploymentConfig on source cluster
-func (t *Task) quiesceDeploymentConfigs(client k8sclient.Client) error {
+func (t *Task) quiesceDeploymentConfigs(client k8sclient.Client, pdbs []policyv1beta1.PodDisruptionBudget) error {
 	for _, ns := range t.sourceNamespaces() {
 		list := ocappsv1.DeploymentConfigList{}
 		options := k8sclient.InNamespace(ns)
@@ -135,6 +137,11 @@ func (t *Task) quiesceDeploymentConfigs(client k8sclient.Client) error {
 			if dc.Spec.Replicas == 0 {
 				continue
 			}
+			if dc.Spec.Template != nil && getSelectingPDB(ns, dc.Spec.Template.Labels, pdbs) != nil {
+				t.Log.Info("Quiesce skipping DeploymentConfig, protected by a PodDisruptionBudget",
+					"deploymentConfig", path.Join(dc.Namespace, dc.Name))
+				continue
+			}
 			dc.Annotations[migapi.ReplicasAnnotation] = strconv.FormatInt(int64(dc.Spec.Replicas), 10)
 			t.Log.Info(fmt.Sprintf("Quiescing DeploymentConfig. "+
 				"Changing .Spec.Replicas from [%v->0]. "+
@@ -200,7 +207,7 @@ func (t *Task) unQuiesceDeploymentConfigs(client k8sclient.Client, namespaces []
 }
 
 // Scales down all Deployments
-func (t *Task) quiesceDeployments(client k8sclient.Client) error {
+func (t *Task) quiesceDeployments(client k8sclient.Client, pdbs []policyv1beta1.PodDisruptionBudget) error {
 	zero := int32(0)
 	for _, ns := range t.sourceNamespaces() {
 		list := appsv1.DeploymentList{}
@@ -219,6 +226,11 @@ func (t *Task) quiesceDeployments(client k8sclient.Client) error {
 			if *deployment.Spec.Replicas == zero {
 				continue
 			}
+			if getSelectingPDB(ns, deployment.Spec.Template.Labels, pdbs) != nil {
+				t.Log.Info("Quiesce skipping Deployment, protected by a PodDisruptionBudget",
+					"deployment", path.Join(deployment.Namespace, deployment.Name))
+				continue
+			}
 			t.Log.Info(fmt.Sprintf("Quiescing Deployment. "+
 				"Changing spec.Replicas from [%v->0]. "+
 				"Annotating with [%v: %v]",
@@ -285,7 +297,7 @@ func (t *Task) unQuiesceDeployments(client k8sclient.Client, namespaces []string
 }
 
 // Scales down all StatefulSets.
-func (t *Task) quiesceStatefulSets(client k8sclient.Client) error {
+func (t *Task) quiesceStatefulSets(client k8sclient.Client, pdbs []policyv1beta1.PodDisruptionBudget) error {
 	zero := int32(0)
 	for _, ns := range t.sourceNamespaces() {
 		list := appsv1.StatefulSetList{}
@@ -298,6 +310,11 @@ func (t *Task) quiesceStatefulSets(client k8sclient.Client) error {
 			return liberr.Wrap(err)
 		}
 		for _, set := range list.Items {
+			if getSelectingPDB(ns, set.Spec.Template.Labels, pdbs) != nil {
+				t.Log.Info("Quiesce skipping StatefulSet, protected by a PodDisruptionBudget",
+					"statefulSet", path.Join(set.Namespace, set.Name))
+				continue
+			}
 			t.Log.Info(fmt.Sprintf("Quiescing StatefulSet. "+
 				"Changing Spec.Replicas from [%v->%v]. "+
 				"Annotating with [%v: %v]",
@@ -369,7 +386,7 @@ func (t *Task) unQuiesceStatefulSets(client k8sclient.Client, namespaces []strin
 }
 
 // Scales down all ReplicaSets.
-func (t *Task) quiesceReplicaSets(client k8sclient.Client) error {
+func (t *Task) quiesceReplicaSets(client k8sclient.Client, pdbs []policyv1beta1.PodDisruptionBudget) error {
 	zero := int32(0)
 	for _, ns := range t.sourceNamespaces() {
 		list := appsv1.ReplicaSetList{}
@@ -393,6 +410,11 @@ func (t *Task) quiesceReplicaSets(client k8sclient.Client) error {
 			if *set.Spec.Replicas == zero {
 				continue
 			}
+			if getSelectingPDB(ns, set.Spec.Template.Labels, pdbs) != nil {
+				t.Log.Info("Quiesce skipping ReplicaSet, protected by a PodDisruptionBudget",
+					"replicaSet", path.Join(set.Namespace, set.Name))
+				continue
+			}
 			set.Annotations[migapi.ReplicasAnnotation] = strconv.FormatInt(int64(*set.Spec.Replicas), 10)
 			t.Log.Info(fmt.Sprintf("Quiescing ReplicaSet. "+
 				"Changing Spec.Replicas from [%v->%v]. "+
@@ -692,7 +714,8 @@ func (t *Task) unQuiesceJobs(client k8sclient.Client, namespaces []string) error
 	return nil
 }
 
-// Ensure scaled down pods have terminated.
+// Ensure scaled down pods have terminated. The pods left running as selected by
+// the PodDisruptionBudgets are not waited for.
 // Returns: `true` when all pods terminated.
 func (t *Task) ensureQuiescedPodsTerminated() (bool, error) {
 	kinds := map[string]bool{
@@ -711,6 +734,13 @@ func (t *Task) ensureQuiescedPodsTerminated() (bool, error) {
 	if err != nil {
 		return false, liberr.Wrap(err)
 	}
+	pdbs := []policyv1beta1.PodDisruptionBudget{}
+	if t.quiesceLiveSource() {
+		pdbs, err = getQuiesceBlockingPDBs(client, t.sourceNamespaces())
+		if err != nil {
+			return false, liberr.Wrap(err)
+		}
+	}
 	for _, ns := range t.sourceNamespaces() {
 		list := v1.PodList{}
 		options := k8sclient.InNamespace(ns)
@@ -728,6 +758,9 @@ func (t *Task) ensureQuiescedPodsTerminated() (bool, error) {
 			if _, found := skippedPhases[pod.Status.Phase]; found {
 				continue
 			}
+			if getSelectingPDB(ns, pod.Labels, pdbs) != nil {
+				continue
+			}
 			for _, ref := range pod.OwnerReferences {
 				if _, found := kinds[ref.Kind]; found {
 					t.Log.Info("Found quiesced Pod on source cluster"+
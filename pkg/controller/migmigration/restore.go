@@ -100,7 +100,7 @@ func (t *Task) ensureStageRestore() (*velero.Restore, error) {
 	newRestore.Labels[migapi.MigPlanDebugLabel] = t.Owner.Spec.MigPlanRef.Name
 	newRestore.Labels[migapi.MigMigrationLabel] = string(t.Owner.UID)
 	newRestore.Labels[migapi.MigPlanLabel] = string(t.PlanResources.MigPlan.UID)
-	stagePodImage, err := t.getStagePodImage(client)
+	stagePodImage, err := t.getStagePodImage(t.PlanResources.DestMigCluster, client)
 	if err != nil {
 		return nil, liberr.Wrap(err)
 	}
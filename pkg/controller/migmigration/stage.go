@@ -165,14 +165,16 @@ func (t *Task) listStagePods(client k8sclient.Client) (StagePodList, error) {
 	if err != nil {
 		return nil, liberr.Wrap(err)
 	}
-	stagePodImage, err := t.getStagePodImage(client)
+	stagePodImage, err := t.getStagePodImage(t.PlanResources.SrcMigCluster, client)
 	if err != nil {
 		return nil, liberr.Wrap(err)
 	}
 	return BuildStagePods(t.stagePodLabels(), t.getPVCs(), &podList.Items, stagePodImage, resourceLimitMapping), nil
 }
 
-func (t *Task) getStagePodImage(client k8sclient.Client) (string, error) {
+// Get the Stage Pod image of the cluster, rewritten to its mirrored location
+// when a mirror policy of the cluster applies.
+func (t *Task) getStagePodImage(cluster *migapi.MigCluster, client k8sclient.Client) (string, error) {
 	clusterConfig := &corev1.ConfigMap{}
 	clusterConfigRef := types.NamespacedName{Name: migapi.ClusterConfigMapName, Namespace: migapi.VeleroNamespace}
 	err := client.Get(context.TODO(), clusterConfigRef, clusterConfig)
@@ -184,6 +186,10 @@ func (t *Task) getStagePodImage(client k8sclient.Client) (string, error) {
 		return "", liberr.Wrap(errors.Errorf("Key [%v] not found in ConfigMap [%v/%v]",
 			migapi.StagePodImageKey, clusterConfigRef.Namespace, clusterConfigRef.Name))
 	}
+	stagePodImage, err = cluster.GetMirroredImage(client, stagePodImage)
+	if err != nil {
+		return "", liberr.Wrap(err)
+	}
 	t.Log.Info("Got Stage Pod image from ConfigMap",
 		"stagePodImage", stagePodImage,
 		"configMap", path.Join(clusterConfigRef.Namespace, clusterConfigRef.Name))
@@ -228,7 +234,7 @@ func (t *Task) ensureStagePodsFromOrphanedPVCs() error {
 		return liberr.Wrap(err)
 	}
 	t.Log.Info("Getting Stage Pod image")
-	stagePodImage, err := t.getStagePodImage(client)
+	stagePodImage, err := t.getStagePodImage(t.PlanResources.SrcMigCluster, client)
 	if err != nil {
 		return liberr.Wrap(err)
 	}
@@ -305,7 +311,7 @@ func (t *Task) ensureStagePodsFromTemplates() error {
 	}
 
 	t.Log.Info("Getting Stage Pod image for source cluster")
-	stagePodImage, err := t.getStagePodImage(client)
+	stagePodImage, err := t.getStagePodImage(t.PlanResources.SrcMigCluster, client)
 	if err != nil {
 		return liberr.Wrap(err)
 	}
@@ -401,7 +407,7 @@ func (t *Task) ensureStagePodsFromRunning() error {
 		return liberr.Wrap(err)
 	}
 	t.Log.Info("Retrieving stage pod image")
-	stagePodImage, err := t.getStagePodImage(client)
+	stagePodImage, err := t.getStagePodImage(t.PlanResources.SrcMigCluster, client)
 	if err != nil {
 		return liberr.Wrap(err)
 	}
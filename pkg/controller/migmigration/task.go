@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"path"
+	"strings"
 	"time"
 
 	mapset "github.com/deckarep/golang-set"
@@ -664,7 +665,29 @@ func (t *Task) Run(ctx context.Context) error {
 			t.Requeue = PollReQ
 		}
 	case QuiesceApplications:
-		err := t.quiesceApplications()
+		pdbs, blocked, proceed, err := t.checkQuiescePDBs()
+		if err != nil {
+			return liberr.Wrap(err)
+		}
+		if !proceed && t.quiesceBlockedByPDBTimedOut() {
+			t.Owner.Status.SetCondition(migapi.Condition{
+				Type:     QuiesceBlockedByPDB,
+				Status:   True,
+				Reason:   TimedOut,
+				Category: Critical,
+				Message:  QuiesceBlockedByPDBTimeoutMessage,
+				Items:    blocked,
+				Durable:  true,
+			})
+			t.fail(MigrationFailed, []string{fmt.Sprintf("PodDisruptionBudgets blocked the quiesce for %s: [%s]",
+				QuiesceBlockedByPDBTimeout, strings.Join(blocked, ", "))})
+			break
+		}
+		if !proceed {
+			t.Requeue = PollReQ
+			break
+		}
+		err = t.quiesceApplications(pdbs)
 		if err != nil {
 			return liberr.Wrap(err)
 		}
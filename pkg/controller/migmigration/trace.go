@@ -38,7 +38,7 @@ func (r *ReconcileMigMigration) initTracer(migration *migapi.MigMigration) (open
 	// Set tracer on reconciler if it's not already present.
 	// We will never close this, so the 'closer' is discarded.
 	if r.tracer == nil {
-		r.tracer, _ = migtrace.InitJaeger("MigMigration")
+		r.tracer, _ = migtrace.InitTracer("MigMigration")
 	}
 	// Get migration span, add to global map if does not yet exist.
 	migrationUID := string(migration.GetUID())
@@ -41,6 +41,8 @@ const (
 	StaleDestVeleroCRsDeleted          = "StaleDestVeleroCRsDeleted"
 	StaleResticCRsDeleted              = "StaleResticCRsDeleted"
 	DirectVolumeMigrationBlocked       = "DirectVolumeMigrationBlocked"
+	QuiesceBlockedByPDB                = "QuiesceBlockedByPDB"
+	QuiesceDisruptsPDB                 = "QuiesceDisruptsPDB"
 )
 
 // Categories
@@ -55,6 +57,9 @@ const (
 	NotFound       = "NotFound"
 	Cancel         = "Cancel"
 	ErrorsDetected = "ErrorsDetected"
+	LiveSource     = "LiveSource"
+	Disrupted      = "Disrupted"
+	TimedOut       = "TimedOut"
 )
 
 // Statuses
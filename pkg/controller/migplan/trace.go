@@ -33,7 +33,7 @@ func (r *ReconcileMigPlan) initTracer(migplan *migapi.MigPlan) opentracing.Span
 	// Set tracer on reconciler if it's not already present.
 	// We will never close this, so the 'closer' is discarded.
 	if r.tracer == nil {
-		r.tracer, _ = migtrace.InitJaeger("MigPlan")
+		r.tracer, _ = migtrace.InitTracer("MigPlan")
 	}
 	// Begin reconcile span
 	reconcileSpan := r.tracer.StartSpan("migplan-reconcile-" + migplan.Name)
@@ -33,7 +33,7 @@ func (r *ReconcileMigStorage) initTracer(migstorage *migapi.MigStorage) opentrac
 	// Set tracer on reconciler if it's not already present.
 	// We will never close this, so the 'closer' is discarded.
 	if r.tracer == nil {
-		r.tracer, _ = migtrace.InitJaeger("MigStorage")
+		r.tracer, _ = migtrace.InitTracer("MigStorage")
 	}
 	// Begin reconcile span
 	reconcileSpan := r.tracer.StartSpan("migstorage-reconcile-" + migstorage.Name)
@@ -1,8 +1,11 @@
 package settings
 
 import (
+	"errors"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // DVM options
@@ -12,41 +15,104 @@ const (
 	RsyncOptArchive         = "RSYNC_OPT_ARCHIVE"
 	RsyncOptDelete          = "RSYNC_OPT_DELETE"
 	RsyncOptHardLinks       = "RSYNC_OPT_HARDLINKS"
+	RsyncOptAcls            = "RSYNC_OPT_ACLS"
+	RsyncOptXattrs          = "RSYNC_OPT_XATTRS"
+	RsyncOptSELinux         = "RSYNC_OPT_XATTRS_SELINUX"
 	RsyncOptInfo            = "RSYNC_OPT_INFO"
 	RsyncOptExtras          = "RSYNC_OPT_EXTRAS"
 	RsyncBackOffLimit       = "RSYNC_BACKOFF_LIMIT"
+	RsyncExitCodeOutcomes   = "RSYNC_EXIT_CODE_OUTCOMES"
 	EnablePVResizing        = "ENABLE_DVM_PV_RESIZING"
 	TCPProxyKey             = "STUNNEL_TCP_PROXY"
+	TCPProxySecretKey       = "STUNNEL_TCP_PROXY_SECRET"
 	StunnelVerifyCAKey      = "STUNNEL_VERIFY_CA"
 	StunnelVerifyCALevelKey = "STUNNEL_VERIFY_CA_LEVEL"
+	RsyncSourceReadOnly     = "RSYNC_SOURCE_READ_ONLY"
+	DvmEndpointType         = "DVM_ENDPOINT_TYPE"
+	DvmFlatNetwork          = "DVM_FLAT_NETWORK"
+	DvmCompletedTTL         = "DVM_COMPLETED_TTL"
+	DvmFailedTTL            = "DVM_FAILED_TTL"
+	DvmMaxConcurrent        = "DVM_MAX_CONCURRENT_RECONCILES"
+	DvmMaxTransfersPerNode  = "DVM_MAX_TRANSFERS_PER_NODE"
+	DvmFastRequeue          = "DVM_FAST_REQUEUE"
+	DvmPollRequeue          = "DVM_POLL_REQUEUE"
+	DvmAPICallMetrics       = "DVM_API_CALL_METRICS"
+	DvmOwnerReferences      = "DVM_OWNER_REFERENCES"
+	DvmAdmissionWebhook     = "DVM_ADMISSION_WEBHOOK"
 )
 
 // RsyncOpts Rsync Options
+//
 //	BwLimit: equivalent to --bwlimit=<integer>
 //	Archive: whether to set --archive option or not
 //	Partial: whether to set --partial option or not
 //	Delete:  whether to set --delete option or not
 //	HardLinks: whether to set --hard-links option or not
+//	Acls: whether to set --acls option or not, retried without it when the
+//	  destination filesystem does not support ACLs
+//	Xattrs: whether to set --xattrs option or not, retried without it when the
+//	  destination filesystem does not support extended attributes
+//	XattrsSELinux: whether to preserve the SELinux labels of the files along
+//	  with the extended attributes, off by default leaving them to the destination
 //	Extras: arbitrary rsync options provided by the user
 //	BackOffLimit: defines number of retries set on Rsync
+//	ExitCodeOutcomes: outcome of failed Rsync attempts per rsync exit code,
+//	  'Retry', 'Warn' or 'Fail', set as a list such as '23=Fail,24=Retry'
 type RsyncOpts struct {
-	BwLimit      int
-	Archive      bool
-	Partial      bool
-	Delete       bool
-	HardLinks    bool
-	Info         string
-	Extras       []string
-	BackOffLimit int
+	BwLimit          int
+	Archive          bool
+	Partial          bool
+	Delete           bool
+	HardLinks        bool
+	Acls             bool
+	Xattrs           bool
+	XattrsSELinux    bool
+	Info             string
+	Extras           []string
+	BackOffLimit     int
+	ExitCodeOutcomes map[int]string
 }
 
 // DvmOpts DVM settings
+//
+//	StunnelTCPProxy: proxy used by the stunnel client to reach the rsync endpoint
+//	StunnelTCPProxySecret: name of a Secret in the migration namespace
+//	  holding the proxy 'username' and 'password'
+//	SourceReadOnly: whether to mount source PVCs read-only in Rsync client Pods
+//	EndpointType: type of the rsync transfer endpoint, 'Route', 'ClusterIP' or 'auto'
+//	FlatNetwork: whether Service cluster IPs of the destination cluster are
+//	  routable from the source cluster, required by the 'ClusterIP' endpoint
+//	CompletedTTL: duration a DVM is kept once completed, kept indefinitely when 0
+//	FailedTTL: duration a DVM is kept once failed or canceled, CompletedTTL when 0
+//	MaxConcurrentReconciles: number of DVMs reconciled concurrently, 1 by default
+//	MaxTransfersPerNode: number of Rsync client Pods reading from a source node
+//	  at a time, across all DVMs, not limited when 0
+//	FastRequeue: interval a DVM is reconciled at once a phase progressed, 100ms when 0
+//	PollRequeue: interval a DVM is reconciled at while a phase waits, 3s when 0
+//	APICallMetrics: whether to count the API calls made by each DVM phase
+//	OwnerReferences: whether the resources created for a DVM on the host cluster
+//	  are owner-referenced by the DVM, managed by labels only otherwise
+//	AdmissionWebhook: whether to serve the DVM validating admission webhook,
+//	  requires the serving certificates and the webhook configuration
 type DvmOpts struct {
 	RsyncOpts
-	EnablePVResizing     bool
-	StunnelTCPProxy      string
-	StunnelVerifyCA      bool
-	StunnelVerifyCALevel string
+	EnablePVResizing        bool
+	StunnelTCPProxy         string
+	StunnelTCPProxySecret   string
+	StunnelVerifyCA         bool
+	StunnelVerifyCALevel    string
+	SourceReadOnly          bool
+	EndpointType            string
+	FlatNetwork             bool
+	CompletedTTL            time.Duration
+	FailedTTL               time.Duration
+	MaxConcurrentReconciles int
+	MaxTransfersPerNode     int
+	FastRequeue             time.Duration
+	PollRequeue             time.Duration
+	APICallMetrics          bool
+	OwnerReferences         bool
+	AdmissionWebhook        bool
 }
 
 // Load load rsync options
@@ -60,6 +126,9 @@ func (r *RsyncOpts) Load() error {
 	r.Partial = getEnvBool(RsyncOptPartial, true)
 	r.Delete = getEnvBool(RsyncOptDelete, true)
 	r.HardLinks = getEnvBool(RsyncOptHardLinks, true)
+	r.Acls = getEnvBool(RsyncOptAcls, true)
+	r.Xattrs = getEnvBool(RsyncOptXattrs, true)
+	r.XattrsSELinux = getEnvBool(RsyncOptSELinux, false)
 	infoOpts := os.Getenv(RsyncOptInfo)
 	if len(infoOpts) > 0 {
 		r.Info = infoOpts
@@ -72,19 +141,97 @@ func (r *RsyncOpts) Load() error {
 	if err != nil {
 		return err
 	}
+	r.ExitCodeOutcomes, err = getEnvExitCodeOutcomes(RsyncExitCodeOutcomes)
+	if err != nil {
+		return err
+	}
 	return err
 }
 
+// Get rsync exit code outcomes set as a comma separated list of <exit code>=<outcome>.
+func getEnvExitCodeOutcomes(name string) (map[int]string, error) {
+	outcomes := map[int]string{}
+	for _, entry := range strings.Split(os.Getenv(name), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			return nil, errors.New(name + " entries must be <exit code>=<outcome>")
+		}
+		code, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+		if err != nil {
+			return nil, errors.New(name + " exit codes must be integers")
+		}
+		outcome := strings.TrimSpace(parts[1])
+		switch outcome {
+		case "Retry", "Warn", "Fail":
+			outcomes[code] = outcome
+		default:
+			return nil, errors.New(name + " outcomes must be one of Retry, Warn, Fail")
+		}
+	}
+	return outcomes, nil
+}
+
+// Get a duration such as '72h', 0 when not set.
+func getEnvDuration(name string) (time.Duration, error) {
+	s, found := os.LookupEnv(name)
+	if !found || strings.TrimSpace(s) == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(s))
+	if err != nil || d < 0 {
+		return 0, errors.New(name + " must be a positive duration, e.g. 72h")
+	}
+	return d, nil
+}
+
 // Load loads DVM options
 func (r *DvmOpts) Load() error {
 	var err error
 	r.EnablePVResizing = getEnvBool(EnablePVResizing, false)
+	r.SourceReadOnly = getEnvBool(RsyncSourceReadOnly, true)
 	r.StunnelTCPProxy = os.Getenv(TCPProxyKey)
+	r.StunnelTCPProxySecret = os.Getenv(TCPProxySecretKey)
 	r.StunnelVerifyCA = getEnvBool(StunnelVerifyCAKey, true)
 	r.StunnelVerifyCALevel = os.Getenv(StunnelVerifyCALevelKey)
 	if r.StunnelVerifyCALevel == "" {
 		r.StunnelVerifyCALevel = "2"
 	}
+	r.EndpointType = os.Getenv(DvmEndpointType)
+	if r.EndpointType == "" {
+		r.EndpointType = "Route"
+	}
+	r.FlatNetwork = getEnvBool(DvmFlatNetwork, false)
+	r.CompletedTTL, err = getEnvDuration(DvmCompletedTTL)
+	if err != nil {
+		return err
+	}
+	r.FailedTTL, err = getEnvDuration(DvmFailedTTL)
+	if err != nil {
+		return err
+	}
+	r.MaxConcurrentReconciles, err = getEnvLimit(DvmMaxConcurrent, 1)
+	if err != nil {
+		return err
+	}
+	r.MaxTransfersPerNode, err = getEnvLimit(DvmMaxTransfersPerNode, 0)
+	if err != nil {
+		return err
+	}
+	r.FastRequeue, err = getEnvDuration(DvmFastRequeue)
+	if err != nil {
+		return err
+	}
+	r.PollRequeue, err = getEnvDuration(DvmPollRequeue)
+	if err != nil {
+		return err
+	}
+	r.APICallMetrics = getEnvBool(DvmAPICallMetrics, false)
+	r.OwnerReferences = getEnvBool(DvmOwnerReferences, true)
+	r.AdmissionWebhook = getEnvBool(DvmAdmissionWebhook, false)
 	err = r.RsyncOpts.Load()
 	if err != nil {
 		return err
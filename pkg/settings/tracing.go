@@ -1,19 +1,50 @@
 package settings
 
+import (
+	"errors"
+	"os"
+)
+
 // Jaeger options
 const (
-	JaegerEnabled = "JAEGER_ENABLED"
+	JaegerEnabled       = "JAEGER_ENABLED"
+	TracingExporter     = "TRACING_EXPORTER"
+	OTLPEndpoint        = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	OTLPInsecure        = "OTEL_EXPORTER_OTLP_INSECURE"
+	ExporterJaeger      = "jaeger"
+	ExporterOTLP        = "otlp"
+	DefaultOTLPEndpoint = "localhost:4317"
 )
 
 // Jaeger Options
+//
 //	Enabled: whether to emit jaeger spans from controller
+//	Exporter: exporter of the spans, 'jaeger' or 'otlp'
+//	OTLPEndpoint: host:port of the OTLP gRPC collector receiving the spans
+//	OTLPInsecure: whether to connect to the OTLP collector without TLS
 type JaegerOpts struct {
-	Enabled bool
+	Enabled      bool
+	Exporter     string
+	OTLPEndpoint string
+	OTLPInsecure bool
 }
 
 // Load load rsync options
 func (r *JaegerOpts) Load() error {
 	var err error
 	r.Enabled = getEnvBool(JaegerEnabled, false)
+	r.Exporter = os.Getenv(TracingExporter)
+	switch r.Exporter {
+	case "":
+		r.Exporter = ExporterJaeger
+	case ExporterJaeger, ExporterOTLP:
+	default:
+		return errors.New(TracingExporter + " must be one of jaeger, otlp")
+	}
+	r.OTLPEndpoint = os.Getenv(OTLPEndpoint)
+	if r.OTLPEndpoint == "" {
+		r.OTLPEndpoint = DefaultOTLPEndpoint
+	}
+	r.OTLPInsecure = getEnvBool(OTLPInsecure, false)
 	return err
 }
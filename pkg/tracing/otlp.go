@@ -0,0 +1,78 @@
+/*
+Copyright 2021 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/konveyor/mig-controller/pkg/settings"
+	opentracing "github.com/opentracing/opentracing-go"
+	otbridge "go.opentelemetry.io/otel/bridge/opentracing"
+	"go.opentelemetry.io/otel/exporters/otlp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpgrpc"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/semconv"
+)
+
+// InitTracer returns the tracer of the service with the exporter selected by the
+// tracing settings. The spans are created through the OpenTracing API for every
+// exporter, the OTLP exporter bridges them to OpenTelemetry.
+func InitTracer(service string) (opentracing.Tracer, io.Closer) {
+	if settings.Settings.JaegerOpts.Exporter == settings.ExporterOTLP {
+		return InitOTLP(service)
+	}
+	return InitJaeger(service)
+}
+
+// InitOTLP returns an instance of OpenTracing Tracer that samples 100% of traces
+// and exports all spans over OTLP gRPC to the collector of the tracing settings.
+func InitOTLP(service string) (opentracing.Tracer, io.Closer) {
+	ctx := context.Background()
+	options := []otlpgrpc.Option{
+		otlpgrpc.WithEndpoint(settings.Settings.JaegerOpts.OTLPEndpoint),
+	}
+	if settings.Settings.JaegerOpts.OTLPInsecure {
+		options = append(options, otlpgrpc.WithInsecure())
+	}
+	exporter, err := otlp.NewExporter(ctx, otlpgrpc.NewDriver(options...))
+	if err != nil {
+		panic(fmt.Sprintf("ERROR: cannot init OTLP exporter: %v\n", err))
+	}
+	res, err := resource.New(ctx, resource.WithAttributes(semconv.ServiceNameKey.String(service)))
+	if err != nil {
+		panic(fmt.Sprintf("ERROR: cannot init OTLP resource: %v\n", err))
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sdktrace.AlwaysSample()),
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	tracer, _ := otbridge.NewTracerPair(provider.Tracer(service))
+	return tracer, &otlpCloser{provider: provider}
+}
+
+// otlpCloser flushes and stops the OTLP exporter.
+type otlpCloser struct {
+	provider *sdktrace.TracerProvider
+}
+
+func (c *otlpCloser) Close() error {
+	return c.provider.Shutdown(context.Background())
+}
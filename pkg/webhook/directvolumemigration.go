@@ -0,0 +1,137 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	liberr "github.com/konveyor/controller/pkg/error"
+	"github.com/konveyor/controller/pkg/logging"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"github.com/konveyor/mig-controller/pkg/settings"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	crwebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var log = logging.WithName("webhook")
+
+// DVMValidatePath path the DVM validating admission webhook is served at.
+const DVMValidatePath = "/validate-migration-openshift-io-v1alpha1-directvolumemigration"
+
+// ValidateConnectivityAnnotation when "true" on a DVM, its creation is only
+// admitted when the API servers of its source and destination clusters are
+// reachable.
+const ValidateConnectivityAnnotation = "migration.openshift.io/validate-connectivity"
+
+// ConnectivityProbeTimeout timeout of the request checking the API server of
+// a cluster is reachable at admission. Both clusters are probed within the
+// timeout of the webhook.
+var ConnectivityProbeTimeout = time.Duration(time.Second * 3)
+
+// probeCluster checks the API server of the cluster of a REST config serves its version.
+var probeCluster = func(config *rest.Config) error {
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	return clientset.Discovery().RESTClient().Get().AbsPath("/version").Timeout(ConnectivityProbeTimeout).Do(context.TODO()).Error()
+}
+
+func init() {
+	AddToManagerFuncs = append(AddToManagerFuncs, addDVMValidator)
+}
+
+// addDVMValidator registers the DVM validating admission webhook, only when
+// enabled by the settings: the webhook server requires its serving certificates.
+func addDVMValidator(m manager.Manager) error {
+	if !settings.Settings.DvmOpts.AdmissionWebhook {
+		return nil
+	}
+	log.Info("Registering DVM validating admission webhook.", "path", DVMValidatePath)
+	m.GetWebhookServer().Register(DVMValidatePath, &crwebhook.Admission{
+		Handler: &DVMValidator{Client: m.GetClient()},
+	})
+	return nil
+}
+
+// DVMValidator admits the creation of a DVM requesting the connectivity check
+// only when both of its clusters are reachable. The other DVMs are validated
+// by the DVM controller.
+type DVMValidator struct {
+	Client  k8sclient.Client
+	decoder *admission.Decoder
+}
+
+// InjectDecoder injects the decoder of the admission requests.
+func (v *DVMValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
+
+// Handle admits or denies an admission request of a DVM.
+func (v *DVMValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1.Create {
+		return admission.Allowed("")
+	}
+	dvm := &migapi.DirectVolumeMigration{}
+	err := v.decoder.Decode(req, dvm)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if dvm.Annotations[ValidateConnectivityAnnotation] != "true" {
+		return admission.Allowed("")
+	}
+	problems := []string{}
+	clusters := []struct {
+		role string
+		ref  func(k8sclient.Client) (*migapi.MigCluster, error)
+	}{
+		{"source", dvm.GetSourceCluster},
+		{"destination", dvm.GetDestinationCluster},
+	}
+	for _, cluster := range clusters {
+		problem, err := v.checkCluster(cluster.role, cluster.ref)
+		if err != nil {
+			log.Trace(err)
+			return admission.Errored(http.StatusInternalServerError, err)
+		}
+		if problem != "" {
+			problems = append(problems, problem)
+		}
+	}
+	if len(problems) > 0 {
+		log.Info("Denying DVM with unreachable clusters.",
+			"dvm", req.Namespace+"/"+req.Name,
+			"problems", problems)
+		return admission.Denied(fmt.Sprintf("connectivity check failed: %v", problems))
+	}
+	return admission.Allowed("source and destination clusters are reachable")
+}
+
+// checkCluster checks a cluster of a DVM is found and its API server is
+// reachable. Returns the problem found, empty when the cluster is reachable.
+func (v *DVMValidator) checkCluster(role string, get func(k8sclient.Client) (*migapi.MigCluster, error)) (string, error) {
+	cluster, err := get(v.Client)
+	if err != nil {
+		return "", liberr.Wrap(err)
+	}
+	if cluster == nil {
+		return fmt.Sprintf("%s cluster not found", role), nil
+	}
+	config, err := cluster.BuildRestConfig(v.Client)
+	if err != nil {
+		return fmt.Sprintf("%s cluster %s: %s", role, cluster.Name, err.Error()), nil
+	}
+	config.Timeout = ConnectivityProbeTimeout
+	err = probeCluster(config)
+	if err != nil {
+		return fmt.Sprintf("%s cluster %s unreachable: %s", role, cluster.Name, err.Error()), nil
+	}
+	return "", nil
+}
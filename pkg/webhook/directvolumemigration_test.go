@@ -0,0 +1,112 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/konveyor/mig-controller/pkg/apis"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func getRemoteCluster(name string) (*migapi.MigCluster, *corev1.Secret) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name + "-sa", Namespace: migapi.OpenshiftMigrationNamespace},
+		Data:       map[string][]byte{migapi.SaToken: []byte("token")},
+	}
+	cluster := &migapi.MigCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: migapi.OpenshiftMigrationNamespace},
+		Spec: migapi.MigClusterSpec{
+			URL:                     "https://" + name + ".example.com:6443",
+			ServiceAccountSecretRef: &corev1.ObjectReference{Name: secret.Name, Namespace: secret.Namespace},
+		},
+	}
+	return cluster, secret
+}
+
+func TestDVMValidator_Handle(t *testing.T) {
+	probe := probeCluster
+	defer func() { probeCluster = probe }()
+	apis.AddToScheme(scheme.Scheme)
+	decoder, err := admission.NewDecoder(scheme.Scheme)
+	if err != nil {
+		t.Fatalf("NewDecoder() unexpected error = %v", err)
+	}
+	source, sourceSecret := getRemoteCluster("source")
+	destination, destinationSecret := getRemoteCluster("destination")
+	tests := []struct {
+		name        string
+		annotated   bool
+		destination string
+		probeErr    error
+		wantAllowed bool
+	}{
+		{
+			name:        "when not requested, should not check the clusters",
+			destination: "missing",
+			probeErr:    errors.New("dial tcp: i/o timeout"),
+			wantAllowed: true,
+		},
+		{
+			name:        "when requested and reachable, should admit",
+			annotated:   true,
+			destination: destination.Name,
+			wantAllowed: true,
+		},
+		{
+			name:        "when requested and unreachable, should deny",
+			annotated:   true,
+			destination: destination.Name,
+			probeErr:    errors.New("dial tcp: i/o timeout"),
+			wantAllowed: false,
+		},
+		{
+			name:        "when requested and a cluster is missing, should deny",
+			annotated:   true,
+			destination: "missing",
+			wantAllowed: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			probeCluster = func(*rest.Config) error { return tt.probeErr }
+			dvm := &migapi.DirectVolumeMigration{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "migration.openshift.io/v1alpha1", Kind: "DirectVolumeMigration"},
+				ObjectMeta: metav1.ObjectMeta{Name: "dvm", Namespace: migapi.OpenshiftMigrationNamespace},
+				Spec: migapi.DirectVolumeMigrationSpec{
+					SrcMigClusterRef:  &corev1.ObjectReference{Name: source.Name, Namespace: source.Namespace},
+					DestMigClusterRef: &corev1.ObjectReference{Name: tt.destination, Namespace: migapi.OpenshiftMigrationNamespace},
+				},
+			}
+			if tt.annotated {
+				dvm.Annotations = map[string]string{ValidateConnectivityAnnotation: "true"}
+			}
+			raw, err := json.Marshal(dvm)
+			if err != nil {
+				t.Fatalf("Marshal() unexpected error = %v", err)
+			}
+			validator := &DVMValidator{
+				Client: fake.NewFakeClient(source.DeepCopy(), sourceSecret.DeepCopy(), destination.DeepCopy(), destinationSecret.DeepCopy()),
+			}
+			validator.InjectDecoder(decoder)
+			response := validator.Handle(context.TODO(), admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					Operation: admissionv1.Create,
+					Object:    runtime.RawExtension{Raw: raw},
+				},
+			})
+			if response.Allowed != tt.wantAllowed {
+				t.Errorf("DVMValidator.Handle() allowed = %v, want %v, result %v", response.Allowed, tt.wantAllowed, response.Result)
+			}
+		})
+	}
+}